@@ -0,0 +1,101 @@
+package turnout
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/railway"
+	"github.com/railwayapp/turnout/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+var applyDryRun bool
+var applyYes bool
+var applyRepo string
+var applyWorkspace string
+var applyToken string
+
+var applyCmd = &cobra.Command{
+	Use:   "apply [source-path]",
+	Short: "Create a Railway project for the discovered services",
+	Long: `Apply discovers the services in a source tree and creates a Railway project
+with one service per discovered service, including volumes, environment variables,
+cron schedules and public domains. The plan is printed and confirmed before
+anything is created.
+
+Authenticate with an account or workspace token in RAILWAY_API_TOKEN or --token.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sourcePath := sourcePathArg(args)
+
+		if err := runApply(cmd.Context(), sourcePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Apply failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func runApply(ctx context.Context, sourcePath string) error {
+	project, source, err := loadProject(ctx, sourcePath)
+	if err != nil {
+		return err
+	}
+	if len(project.Services) == 0 {
+		return fmt.Errorf("no services found in %s", source)
+	}
+
+	repo := applyRepo
+	if repo == "" {
+		repo = githubRepo(source)
+	}
+	plan := railway.NewPlan(project, repo, applyWorkspace)
+
+	fmt.Println("Plan:")
+	plan.Write(os.Stdout)
+	if applyDryRun {
+		return nil
+	}
+
+	token := applyToken
+	if token == "" {
+		token = os.Getenv("RAILWAY_API_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("no Railway API token, set RAILWAY_API_TOKEN or pass --token")
+	}
+
+	if !applyYes {
+		fmt.Print("\nCreate this project in Railway? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Println("Aborted")
+			return nil
+		}
+	}
+
+	applied, err := railway.NewClient(token).Apply(ctx, plan, func(step string) {
+		fmt.Printf("  %s\n", step)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nCreated project %s\n", applied.ProjectID)
+	for name, domain := range applied.Domains {
+		fmt.Printf("  %s: https://%s\n", name, domain)
+	}
+	return nil
+}
+
+func init() {
+	applyCmd.Flags().StringVar(&snapshotIn, "plan", "", "apply a "+schema.SnapshotFile+" snapshot instead of discovering services")
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "print the plan without creating anything")
+	applyCmd.Flags().BoolVarP(&applyYes, "yes", "y", false, "skip the confirmation prompt")
+	applyCmd.Flags().StringVar(&applyRepo, "repo", "", "GitHub repository (owner/name) services built from source deploy from")
+	applyCmd.Flags().StringVar(&applyWorkspace, "workspace", "", "Railway workspace ID to create the project in")
+	applyCmd.Flags().StringVar(&applyToken, "token", "", "Railway API token (default $RAILWAY_API_TOKEN)")
+	rootCmd.AddCommand(applyCmd)
+}
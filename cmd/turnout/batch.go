@@ -0,0 +1,144 @@
+package turnout
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/schema"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+var batchOrg string
+var batchOutputDir string
+var batchConcurrency int
+var batchIncludeForks bool
+var batchIncludeArchived bool
+
+var batchCmd = &cobra.Command{
+	Use:   "batch --org <org>",
+	Short: "Discover services in every repository of a GitHub organization",
+	Long: `Batch lists the repositories of a GitHub organization (or user) and discovers
+the services in each one concurrently, writing a plan snapshot per repository to
+the output directory, e.g. results/api.plan.json.
+
+Repositories download as archives, authenticated with GITHUB_TOKEN when it's set.
+GitHub Enterprise Server is used when GITHUB_SERVER_URL and GITHUB_API_URL are set.
+Requests to the GitHub API wait for its rate limit to reset rather than fail.
+Forks and archived repositories are skipped unless asked for.
+
+Exits with status 1 when any repository fails.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		failed, err := runBatch(cmd.Context())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Batch failed: %v\n", err)
+			os.Exit(2)
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// runBatch discovers every repository of the organization, returning how many failed
+func runBatch(ctx context.Context) (int, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+
+	host := filesystems.DefaultGitHubHost()
+	repos, err := filesystems.ListGitHubRepos(ctx, host, batchOrg, token)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(batchOutputDir, 0o755); err != nil {
+		return 0, err
+	}
+
+	var selected []filesystems.GitHubRepo
+	for _, repo := range repos {
+		if (repo.Fork && !batchIncludeForks) || (repo.Archived && !batchIncludeArchived) {
+			continue
+		}
+		selected = append(selected, repo)
+	}
+	progressf("Discovering services in %d repositories of %s\n", len(selected), batchOrg)
+
+	var mu sync.Mutex
+	var failed int
+	var workers errgroup.Group
+	workers.SetLimit(max(batchConcurrency, 1))
+	for _, repo := range selected {
+		workers.Go(func() error {
+			path, services, err := batchRepo(ctx, host, repo, token)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				fmt.Fprintf(os.Stderr, "  ✗ %s: %v\n", repo.FullName, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "  ✓ %s: %d services → %s\n", repo.FullName, services, path)
+			}
+			return nil
+		})
+	}
+	_ = workers.Wait()
+
+	progressf("Discovered %d of %d repositories\n", len(selected)-failed, len(selected))
+	return failed, nil
+}
+
+// batchRepo discovers a repository and writes its plan snapshot, returning the
+// snapshot's path and how many services it holds
+func batchRepo(ctx context.Context, host filesystems.GitHubHost, repo filesystems.GitHubRepo, token string) (string, int, error) {
+	owner, _, _ := strings.Cut(repo.FullName, "/")
+	source := fmt.Sprintf("github://%s/%s/tree/%s", owner, repo.Name, repo.DefaultBranch)
+	if host != filesystems.GitHubDotCom {
+		source = fmt.Sprintf("github://%s/%s/%s/tree/%s", strings.TrimPrefix(host.ServerURL, "https://"), owner, repo.Name, repo.DefaultBranch)
+	}
+
+	filesystem := filesystems.NewGitHubFSWithHost(host, owner, repo.Name, repo.DefaultBranch, "", token)
+	defer filesystem.Cleanup()
+
+	serviceDiscovery := newServiceDiscovery(filesystem)
+	discover := serviceDiscovery.Discover
+	if perEnvironment {
+		discover = serviceDiscovery.DiscoverEnvironments
+	}
+	services, err := discover(ctx, source)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := checkPlatforms(services); err != nil {
+		return "", 0, err
+	}
+
+	project := normalizeProject(ctx, filesystem, source, services)
+	path := filepath.Join(batchOutputDir, repo.Name+".plan.json")
+	file, err := os.Create(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+	snapshot := schema.NewSnapshot(source, project)
+	snapshot.Revision = sourceRevision(filesystem)
+	if err := snapshot.Write(file); err != nil {
+		return "", 0, err
+	}
+	return path, len(project.Services), file.Close()
+}
+
+func init() {
+	batchCmd.Flags().StringVar(&batchOrg, "org", "", "GitHub organization or user whose repositories to discover")
+	batchCmd.Flags().StringVar(&batchOutputDir, "output-dir", "results", "directory to write a plan snapshot per repository to")
+	batchCmd.Flags().IntVarP(&batchConcurrency, "concurrency", "j", 4, "repositories to discover at once")
+	batchCmd.Flags().BoolVar(&batchIncludeForks, "include-forks", false, "discover forked repositories too")
+	batchCmd.Flags().BoolVar(&batchIncludeArchived, "include-archived", false, "discover archived repositories too")
+	cobra.CheckErr(batchCmd.MarkFlagRequired("org"))
+	rootCmd.AddCommand(batchCmd)
+}
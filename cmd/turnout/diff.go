@@ -0,0 +1,82 @@
+package turnout
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/railwayapp/turnout/internal/railway"
+	"github.com/railwayapp/turnout/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+var diffProject string
+var diffEnvironment string
+var diffRepo string
+var diffToken string
+var diffExitCode bool
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [source-path] --project <id>",
+	Short: "Report drift between the discovered services and a Railway project",
+	Long: `Diff discovers the services in a source tree and compares them with a deployed
+Railway project: services that aren't deployed or are no longer discovered, and
+settings, volumes and variables that differ. Secret values are never printed.
+
+Authenticate with an account or workspace token in RAILWAY_API_TOKEN or --token.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sourcePath := sourcePathArg(args)
+
+		changes, err := runDiff(cmd.Context(), sourcePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Diff failed: %v\n", err)
+			os.Exit(2)
+		}
+		if diffExitCode && len(changes) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func runDiff(ctx context.Context, sourcePath string) ([]railway.Change, error) {
+	token := diffToken
+	if token == "" {
+		token = os.Getenv("RAILWAY_API_TOKEN")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no Railway API token, set RAILWAY_API_TOKEN or pass --token")
+	}
+
+	project, source, err := loadProject(ctx, sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := diffRepo
+	if repo == "" {
+		repo = githubRepo(source)
+	}
+	plan := railway.NewPlan(project, repo, "")
+
+	live, err := railway.NewClient(token).FetchProject(ctx, diffProject, diffEnvironment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+
+	fmt.Printf("Comparing %s with project %s (%s)\n\n", source, live.Name, diffEnvironment)
+	changes := railway.Diff(plan, live)
+	railway.WriteChanges(os.Stdout, changes)
+	return changes, nil
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffProject, "project", "", "Railway project ID to compare with")
+	diffCmd.Flags().StringVar(&snapshotIn, "plan", "", "compare a "+schema.SnapshotFile+" snapshot instead of discovering services")
+	diffCmd.Flags().StringVar(&diffEnvironment, "environment", "production", "Railway environment to compare with")
+	diffCmd.Flags().StringVar(&diffRepo, "repo", "", "GitHub repository (owner/name) services built from source deploy from")
+	diffCmd.Flags().StringVar(&diffToken, "token", "", "Railway API token (default $RAILWAY_API_TOKEN)")
+	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", false, "exit with status 1 when there is drift")
+	diffCmd.MarkFlagRequired("project")
+	rootCmd.AddCommand(diffCmd)
+}
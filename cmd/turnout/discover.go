@@ -2,16 +2,25 @@ package turnout
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+
 	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/schema"
 	"github.com/spf13/cobra"
 )
 
+var discoverWatch bool
+
 var discoverCmd = &cobra.Command{
 	Use:   "discover [source-path]",
 	Short: "Discover services in a project without running the full conversion pipeline",
@@ -21,76 +30,149 @@ for understanding what services exist in a project before running the full
 turnout conversion process.`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		sourcePath := "."
-		if len(args) > 0 {
-			sourcePath = args[0]
+		sourcePath := sourcePathArg(args)
 
-			// If user provided a file path, use the parent directory
-			if stat, err := os.Stat(sourcePath); err == nil && !stat.IsDir() {
-				sourcePath = filepath.Dir(sourcePath)
-			}
+		if err := checkOutputFormat(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
 
-		fmt.Printf("Discovering services in: %s\n", sourcePath)
+		progressf("Discovering services in: %s\n", sourcePath)
 
-		if err := runServiceDiscovery(sourcePath); err != nil {
-			fmt.Printf("Service discovery failed: %v\n", err)
+		if discoverWatch {
+			if err := runDiscoveryWatch(cmd.Context(), sourcePath); err != nil {
+				fmt.Fprintf(os.Stderr, "Watch failed: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if err := runServiceDiscovery(cmd.Context(), sourcePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Service discovery failed: %v\n", err)
 			os.Exit(1)
 		}
 	},
 }
 
-func runServiceDiscovery(sourcePath string) error {
+func runServiceDiscovery(ctx context.Context, sourcePath string) error {
 	// Create filesystem from the sourcePath (supports file://, github://, git://)
-	filesystem, err := filesystems.NewFileSystem(sourcePath)
+	filesystem, err := newFileSystem(ctx, sourcePath)
 	if err != nil {
 		return fmt.Errorf("failed to create filesystem: %w", err)
 	}
 
 	// Clean up git filesystem if needed
-	if gitFS, ok := filesystem.(*filesystems.GitFS); ok {
+	if gitFS, ok := filesystems.Unwrap(filesystem).(*filesystems.GitFS); ok {
 		defer gitFS.Cleanup()
 	}
 
 	// Service discovery - find and triangulate services from multiple signals
-	serviceDiscovery := discovery.NewServiceDiscovery(filesystem)
-	services, err := serviceDiscovery.Discover(context.Background(), sourcePath)
+	serviceDiscovery := newServiceDiscovery(filesystem)
+	services, err := discoverServices(ctx, serviceDiscovery, sourcePath)
 	if err != nil {
 		return fmt.Errorf("service discovery failed: %w", err)
 	}
 
-	fmt.Printf("Discovered %d services:\n", len(services))
-	for _, service := range services {
-		fmt.Printf("  - %s: Network=%s, Runtime=%s, Build=%s\n",
-			service.Name,
-			networkToString(service.Network),
-			runtimeToString(service.Runtime),
-			buildToString(service.Build))
-
-		if service.BuildPath != "" {
-			fmt.Printf("    BuildPath: %s\n", service.BuildPath)
+	revision := sourceRevision(filesystem)
+	if outputFormat == outputTable {
+		if revision != "" {
+			progressf("Scanned %s at %s\n", sourcePath, revision)
 		}
-		if service.Image != "" {
-			fmt.Printf("    Image: %s\n", service.Image)
+		printServices(os.Stdout, services)
+		printWarnings(os.Stdout, serviceDiscovery.Warnings())
+	} else {
+		if err := writeStructured(os.Stdout, redactServices(services)); err != nil {
+			return err
 		}
+		// Structured output stays a list of services
+		printWarnings(os.Stderr, serviceDiscovery.Warnings())
+	}
 
-		fmt.Printf("    Config sources (%d):\n", len(service.Configs))
-		for _, config := range service.Configs {
-			fmt.Printf("      - %s: %s\n", config.Type, config.Path)
-		}
-		fmt.Println()
+	if snapshotOut != "" {
+		return writeSnapshot(sourcePath, revision, normalizeProject(ctx, filesystem, sourcePath, services))
 	}
+	return nil
+}
 
-	// Export to JSON
-	output, err := json.MarshalIndent(services, "", "  ")
+// runDiscoveryWatch rediscovers services whenever files change, printing what changed
+func runDiscoveryWatch(ctx context.Context, sourcePath string) error {
+	filesystem, err := newFileSystem(ctx, sourcePath)
 	if err != nil {
-		return fmt.Errorf("JSON export failed: %w", err)
+		return fmt.Errorf("failed to create filesystem: %w", err)
 	}
 
-	fmt.Printf("\nJSON Export:\n%s\n", string(output))
-	return nil
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serviceDiscovery := newServiceDiscovery(filesystem)
+	discover := serviceDiscovery.Discover
+	if perEnvironment {
+		discover = serviceDiscovery.DiscoverEnvironments
+	}
+
+	err = serviceDiscovery.Watch(ctx, sourcePath, discover, printWatchUpdate(os.Stdout, sourcePath))
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// printWatchUpdate prints the services a watch first discovers, and then what changed
+// in them, to w
+func printWatchUpdate(w io.Writer, sourcePath string) func(discovery.WatchUpdate) {
+	return func(update discovery.WatchUpdate) {
+		if update.Err != nil {
+			fmt.Fprintf(os.Stderr, "Service discovery failed: %v\n", update.Err)
+			return
+		}
+		if len(update.Paths) == 0 {
+			if outputFormat == outputTable {
+				printServices(w, update.Services)
+			} else {
+				_ = writeStructured(w, redactServices(update.Services))
+			}
+			progressf("Watching %s for changes, press Ctrl+C to stop\n", sourcePath)
+			return
+		}
+		if len(update.Changes) == 0 {
+			return
+		}
+
+		if outputFormat != outputTable {
+			type change struct {
+				Kind    discovery.ServiceChangeKind `json:"kind"`
+				Service types.Service               `json:"service"`
+				Fields  []string                    `json:"fields,omitempty"`
+			}
+			var changes []change
+			for _, c := range update.Changes {
+				changes = append(changes, change{c.Kind, redactServices([]types.Service{c.Service})[0], c.Fields})
+			}
+			_ = writeStructured(w, changes)
+			return
+		}
+
+		fmt.Fprintf(w, "[%s] %d files changed\n", time.Now().Format(time.TimeOnly), len(update.Paths))
+		for _, change := range update.Changes {
+			name := change.Service.Name
+			if change.Service.Environment != "" {
+				name += " (" + change.Service.Environment + ")"
+			}
+			switch change.Kind {
+			case discovery.ServiceAdded:
+				fmt.Fprintf(w, "  + %s\n", name)
+			case discovery.ServiceRemoved:
+				fmt.Fprintf(w, "  - %s\n", name)
+			case discovery.ServiceChanged:
+				fmt.Fprintf(w, "  ~ %s: %s\n", name, strings.Join(change.Fields, ", "))
+			}
+		}
+	}
 }
 
 func init() {
+	discoverCmd.Flags().BoolVarP(&discoverWatch, "watch", "w", false, "rediscover when files change and print what changed, for local sources")
+	discoverCmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, "output format: table, json or yaml")
+	discoverCmd.Flags().StringVar(&snapshotOut, "plan-out", "", "also write the normalized project to a snapshot, like "+schema.SnapshotFile)
 	rootCmd.AddCommand(discoverCmd)
 }
@@ -0,0 +1,60 @@
+package turnout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+)
+
+func TestPrintWatchUpdate_RedactsInitialOutput(t *testing.T) {
+	defer func(format string) { outputFormat = format }(outputFormat)
+	outputFormat = outputJSON
+
+	var buf bytes.Buffer
+	printWatchUpdate(&buf, ".")(discovery.WatchUpdate{Services: []types.Service{{
+		Name:      "web",
+		Variables: map[string]string{"STRIPE_SECRET_KEY": "sk_live_51HxQ2eKx9", "LOG_LEVEL": "debug"},
+	}}})
+
+	if strings.Contains(buf.String(), "sk_live_51HxQ2eKx9") {
+		t.Errorf("expected the secret to be masked, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "debug") {
+		t.Errorf("expected LOG_LEVEL to be shown, got:\n%s", buf.String())
+	}
+}
+
+func TestMCPDiscoverServices_Redacted(t *testing.T) {
+	dir := t.TempDir()
+	unit := "[Container]\nImage=nginx\nPublishPort=8080:80\nEnvironment=STRIPE_SECRET_KEY=sk_live_51HxQ2eKx9 LOG_LEVEL=debug\n"
+	if err := os.WriteFile(filepath.Join(dir, "web.container"), []byte(unit), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tool := range mcpTools() {
+		if tool.Name != "discover_services" {
+			continue
+		}
+		arguments, _ := json.Marshal(map[string]string{"source": dir})
+		result, err := tool.Handler(context.Background(), arguments)
+		if err != nil {
+			t.Fatalf("discover_services failed: %v", err)
+		}
+		output, _ := json.Marshal(result)
+		if !strings.Contains(string(output), "LOG_LEVEL") {
+			t.Fatalf("expected the unit's variables to be discovered, got:\n%s", output)
+		}
+		if strings.Contains(string(output), "sk_live_51HxQ2eKx9") {
+			t.Errorf("expected the secret to be masked, got:\n%s", output)
+		}
+		return
+	}
+	t.Fatal("no discover_services tool")
+}
@@ -4,128 +4,248 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
 
-	"github.com/railwayapp/turnout/internal/discovery"
+	discoveryTypes "github.com/railwayapp/turnout/internal/discovery/types"
 	"github.com/railwayapp/turnout/internal/environment"
 	"github.com/railwayapp/turnout/internal/environment/types"
+	"github.com/railwayapp/turnout/internal/export"
 	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/schema"
 	"github.com/spf13/cobra"
 )
 
+var (
+	envDotenvDir  string
+	envRailwayDir string
+	envForce      bool
+)
+
 var envCmd = &cobra.Command{
 	Use:   "env [source-path]",
 	Short: "Extract environment variables from discovered services",
 	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		sourcePath := "."
-		if len(args) > 0 {
-			sourcePath = args[0]
+		sourcePath := sourcePathArg(args)
 
-			if stat, err := os.Stat(sourcePath); err == nil && !stat.IsDir() {
-				sourcePath = filepath.Dir(sourcePath)
-			}
+		if err := checkOutputFormat(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
 
-		fmt.Printf("Extracting environment variables from: %s\n\n", sourcePath)
+		progressf("Extracting environment variables from: %s\n\n", sourcePath)
 
-		if err := runEnvExtraction(sourcePath); err != nil {
-			fmt.Printf("Environment extraction failed: %v\n", err)
+		if err := runEnvExtraction(cmd.Context(), sourcePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Environment extraction failed: %v\n", err)
 			os.Exit(1)
 		}
 	},
 }
 
-func runEnvExtraction(sourcePath string) error {
-	filesystem, err := filesystems.NewFileSystem(sourcePath)
+func runEnvExtraction(ctx context.Context, sourcePath string) error {
+	filesystem, err := newFileSystem(ctx, sourcePath)
 	if err != nil {
 		return fmt.Errorf("failed to create filesystem: %w", err)
 	}
 
-	if gitFS, ok := filesystem.(*filesystems.GitFS); ok {
+	if gitFS, ok := filesystems.Unwrap(filesystem).(*filesystems.GitFS); ok {
 		defer gitFS.Cleanup()
 	}
 
 	// First discover services
-	serviceDiscovery := discovery.NewServiceDiscovery(filesystem)
-	services, err := serviceDiscovery.Discover(context.Background(), sourcePath)
+	serviceDiscovery := newServiceDiscovery(filesystem)
+	stopProgress := showProgress(serviceDiscovery)
+	services, err := serviceDiscovery.Discover(ctx, sourcePath)
+	stopProgress()
 	if err != nil {
 		return fmt.Errorf("service discovery failed: %w", err)
 	}
 
 	if len(services) == 0 {
-		fmt.Println("No services found")
+		progressf("No services found\n")
 		return nil
 	}
 
-	// Collect all service BuildPaths to avoid crossing boundaries
-	servicePaths := make(map[string]bool)
-	for _, service := range services {
-		if service.BuildPath != "" {
-			servicePaths[service.BuildPath] = true
+	results := newEnvExtractor(filesystem, sourcePath).ExtractServiceResults(ctx, services)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	envVars := environment.ResolveReferences(environment.MergeServices(results))
+	references := environment.CrossReference(services, results)
+
+	if outputFormat != outputTable {
+		if err := writeStructured(os.Stdout, envOutput(services, envVars, references)); err != nil {
+			return err
 		}
+	} else {
+		printEnvVars(services, envVars, references)
 	}
 
-	// Create environment extractor
-	envExtractor := environment.NewExtractor(filesystem)
-	ctx := context.Background()
+	if snapshotOut == "" && envDotenvDir == "" && envRailwayDir == "" {
+		return nil
+	}
+	project := normalizeProject(ctx, filesystem, sourcePath, services)
+	if envDotenvDir != "" {
+		if err := writeExportFiles(export.NewDotenvExporter(), project, envDotenvDir, envForce); err != nil {
+			return err
+		}
+	}
+	if envRailwayDir != "" {
+		if err := writeExportFiles(export.NewRailwayVariablesExporter(), project, envRailwayDir, envForce); err != nil {
+			return err
+		}
+	}
+	return writeSnapshot(sourcePath, sourceRevision(filesystem), project)
+}
 
-	for _, service := range services {
-		fmt.Printf("=== %s ===\n", service.Name)
+// envVarOutput is an extracted variable in structured output, with sensitive values masked
+type envVarOutput struct {
+	Name      string            `json:"name"`
+	Value     string            `json:"value,omitempty"`
+	Sensitive bool              `json:"sensitive"`
+	Committed bool              `json:"committed,omitempty"` // the sensitive value is in a file committed to the repository
+	Type      string            `json:"type"`
+	Source    string            `json:"source"`
+	Line      int               `json:"line,omitempty"`   // where it's declared or used in the source's file
+	Column    int               `json:"column,omitempty"` // 1-based, in bytes
+	BuildTime bool              `json:"buildTime,omitempty"`
+	BuildOnly bool              `json:"buildOnly,omitempty"` // only read while building, like a Dockerfile ARG
+	DependsOn []string          `json:"dependsOn,omitempty"`
+	Sources   []envSourceOutput `json:"sources,omitempty"` // every place it was found, when there's more than one
 
-		envVars := make(map[string]types.EnvResult) // Deduplicate by variable name
+	RailwayProvided bool   `json:"railwayProvided,omitempty"` // Railway sets it, so it needn't be
+	RailwayValue    string `json:"railwayValue,omitempty"`    // what to set a variable another platform provided to
+	Platform        string `json:"platform,omitempty"`        // the platform that provided it
+}
 
-		if service.BuildPath != "" {
-			// Walk service directory recursively, avoiding other service paths
-			err := filesystem.Walk(service.BuildPath, func(path string, info filesystems.FileInfo, err error) error {
-				if err != nil {
-					return nil // Skip files we can't access
-				}
+type envSourceOutput struct {
+	Source     string `json:"source"`
+	Line       int    `json:"line,omitempty"`
+	Column     int    `json:"column,omitempty"`
+	Value      string `json:"value,omitempty"`
+	Confidence int    `json:"confidence"`
+	Winner     bool   `json:"winner,omitempty"`
+}
 
-				// Skip if this is another service's directory
-				if path != service.BuildPath && servicePaths[path] {
-					return filesystems.SkipDir
-				}
+// sourceLocation appends where in the source's file a variable is, like
+// dotenv:api/.env:3:1, so editors and terminals can jump to it
+func sourceLocation(source string, span types.Span) string {
+	if span.Line == 0 {
+		return source
+	}
+	return source + ":" + span.String()
+}
 
-				if !info.IsDir() {
-					// Read file and apply extractors
-					content, err := filesystem.ReadFile(path)
-					if err != nil {
-						return nil
-					}
+// newEnvVarOutput converts a variable for structured output, masking its values if
+// it's sensitive and redact is set
+func newEnvVarOutput(envVar types.EnvResult, redact bool) envVarOutput {
+	output := envVarOutput{
+		Name:            envVar.VarName,
+		Value:           types.Redact(envVar.Value, redact && envVar.Sensitive),
+		Sensitive:       envVar.Sensitive,
+		Committed:       envVar.Committed,
+		Type:            envTypeToString(envVar.Type),
+		Source:          envVar.Source,
+		Line:            envVar.Span.Line,
+		Column:          envVar.Span.Column,
+		BuildTime:       envVar.BuildTime,
+		BuildOnly:       envVar.BuildOnly,
+		DependsOn:       envVar.DependsOn,
+		RailwayProvided: types.IsRailwayProvided(envVar.VarName),
+	}
+	if translation, ok := types.TranslateToRailway(envVar.VarName); ok {
+		output.RailwayValue, output.Platform = translation.Value, translation.Platform
+	}
+	if len(envVar.Sources) > 1 {
+		for _, source := range envVar.Sources {
+			output.Sources = append(output.Sources, envSourceOutput{source.Source, source.Span.Line, source.Span.Column, types.Redact(source.Value, redact && envVar.Sensitive), source.Confidence, source.Winner})
+		}
+	}
+	return output
+}
 
-					for envVar := range envExtractor.Extract(ctx, path, content) {
-						// Deduplicate - keep highest confidence version
-						existing, exists := envVars[envVar.VarName]
-						if !exists || envVar.Confidence > existing.Confidence {
-							envVars[envVar.VarName] = envVar
-						}
-					}
-				}
-				return nil
-			})
+type serviceEnvOutput struct {
+	Service    string                  `json:"service"`
+	Variables  []envVarOutput          `json:"variables"`
+	Undeclared []environment.Reference `json:"undeclared"` // read in code but never declared
+}
 
-			if err != nil {
-				fmt.Printf("  Error walking directory: %v\n", err)
+func envOutput(services []discoveryTypes.Service, envVars map[string][]types.EnvResult, references map[string][]environment.Reference) []serviceEnvOutput {
+	output := make([]serviceEnvOutput, 0, len(services))
+	for _, service := range services {
+		serviceOutput := serviceEnvOutput{Service: service.Name, Variables: []envVarOutput{}, Undeclared: []environment.Reference{}}
+		for _, envVar := range envVars[service.Name] {
+			serviceOutput.Variables = append(serviceOutput.Variables, newEnvVarOutput(envVar, redacting(true)))
+		}
+		for _, ref := range references[service.Name] {
+			if ref.Undeclared() {
+				serviceOutput.Undeclared = append(serviceOutput.Undeclared, ref)
 			}
 		}
+		output = append(output, serviceOutput)
+	}
+	return output
+}
+
+func printEnvVars(services []discoveryTypes.Service, envVars map[string][]types.EnvResult, references map[string][]environment.Reference) {
+	for _, service := range services {
+		fmt.Printf("=== %s ===\n", service.Name)
 
-		if len(envVars) == 0 {
+		if len(envVars[service.Name]) == 0 {
 			fmt.Printf("  No environment variables found\n")
 		} else {
-			for _, envVar := range envVars {
+			for _, envVar := range envVars[service.Name] {
 				sensitiveMarker := ""
 				if envVar.Sensitive {
 					sensitiveMarker = " [SENSITIVE]"
 				}
-				fmt.Printf("  %s = %s\n", envVar.VarName, envVar.Value)
-				fmt.Printf("    Source: %s%s\n", envVar.Source, sensitiveMarker)
+				if envVar.Committed {
+					sensitiveMarker += " [COMMITTED]"
+				}
+				switch {
+				case envVar.BuildOnly:
+					sensitiveMarker += " [BUILD-ONLY]"
+				case envVar.BuildTime:
+					sensitiveMarker += " [BUILD-TIME]"
+				}
+				if types.IsRailwayProvided(envVar.VarName) {
+					sensitiveMarker += " [RAILWAY-PROVIDED]"
+				}
+				masked := redacting(false) && envVar.Sensitive
+				fmt.Printf("  %s = %s\n", envVar.VarName, types.Redact(envVar.Value, masked))
+				fmt.Printf("    Source: %s%s\n", sourceLocation(envVar.Source, envVar.Span), sensitiveMarker)
+				for _, source := range envVar.Sources {
+					switch {
+					case source.Winner:
+					case source.Value != "":
+						fmt.Printf("    Also in: %s = %s (confidence %d)\n", sourceLocation(source.Source, source.Span), types.Redact(source.Value, masked), source.Confidence)
+					default:
+						fmt.Printf("    Also in: %s (confidence %d)\n", sourceLocation(source.Source, source.Span), source.Confidence)
+					}
+				}
+				if len(envVar.DependsOn) > 0 {
+					fmt.Printf("    Depends on: %s\n", strings.Join(envVar.DependsOn, ", "))
+				}
+				if translation, ok := types.TranslateToRailway(envVar.VarName); ok {
+					fmt.Printf("    Railway: set to %s, %s provided it\n", translation.Value, translation.Platform)
+				}
+			}
+		}
+
+		// The migration checklist: set these on Railway or the service won't find them
+		first := true
+		for _, ref := range references[service.Name] {
+			if !ref.Undeclared() {
+				continue
+			}
+			if first {
+				fmt.Printf("  Used but never declared:\n")
+				first = false
 			}
+			fmt.Printf("    %s (read in %s)\n", ref.VarName, strings.Join(ref.UsedIn, ", "))
 		}
 		fmt.Println()
 	}
-
-	return nil
 }
 
 func envTypeToString(envType types.EnvType) string {
@@ -150,5 +270,10 @@ func envTypeToString(envType types.EnvType) string {
 }
 
 func init() {
+	envCmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, "output format: table, json or yaml")
+	envCmd.Flags().StringVar(&envDotenvDir, "write-dotenv", "", "write a .env.<service> file per service to a directory, with sensitive values left blank")
+	envCmd.Flags().StringVar(&envRailwayDir, "write-railway", "", "write a <service>.variables.json file per service to a directory, for Railway's raw variable editor")
+	envCmd.Flags().BoolVar(&envForce, "force", false, "overwrite existing files")
+	envCmd.Flags().StringVar(&snapshotOut, "plan-out", "", "also write the normalized project to a snapshot, like "+schema.SnapshotFile)
 	rootCmd.AddCommand(envCmd)
 }
@@ -0,0 +1,191 @@
+package turnout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/enrichment"
+	"github.com/railwayapp/turnout/internal/export"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+var exportOutputDir string
+var exportForce bool
+var exportRepo string
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export discovered services as deployment configuration",
+	Long: `Export discovers the services in a source tree, normalizes them and
+writes them in the target format.`,
+}
+
+var exportRailwayCmd = &cobra.Command{
+	Use:   "railway [source-path]",
+	Short: "Generate Railway config-as-code for each discovered service",
+	Long: `Generates a railway.json for each discovered service, in the service's root
+directory, and a railway.project.json mapping every service to its source, config
+file, public domain and volumes, and how Railpack builds it.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runExportCommand(cmd.Context(), args, export.NewRailwayExporter())
+	},
+}
+
+var exportTerraformCmd = &cobra.Command{
+	Use:   "terraform [source-path]",
+	Short: "Generate Terraform for the Railway provider",
+	Long: `Generates main.tf with a railway_project, a railway_service for each discovered
+service and their railway_variable and railway_service_domain resources, and
+variables.tf declaring an input for every secret or variable without a value.
+Deploy settings go in railway.json files the services point to.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repo := exportRepo
+		if repo == "" && len(args) > 0 && snapshotIn == "" {
+			repo = githubRepo(args[0])
+		}
+		runExportCommand(cmd.Context(), args, export.NewTerraformExporter(repo))
+	},
+}
+
+var exportJSONCmd = &cobra.Command{
+	Use:   "json [source-path]",
+	Short: "Print the normalized project as JSON",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runExportCommand(cmd.Context(), args, export.NewJSONExporter())
+	},
+}
+
+func runExportCommand(ctx context.Context, args []string, exporter export.Exporter) {
+	sourcePath := sourcePathArg(args)
+
+	if err := runExport(ctx, sourcePath, exporter); err != nil {
+		fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runExport(ctx context.Context, sourcePath string, exporter export.Exporter) error {
+	project, _, err := loadProject(ctx, sourcePath)
+	if err != nil {
+		return err
+	}
+
+	fileExporter, ok := exporter.(export.FileExporter)
+	if !ok {
+		if redacting(true) {
+			project = project.Redacted()
+		}
+		output, err := exporter.Export(project)
+		if err != nil {
+			return fmt.Errorf("%s export failed: %w", exporter.Name(), err)
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	return writeExportFiles(fileExporter, project, exportOutputDir, exportForce)
+}
+
+// writeExportFiles writes an exporter's files to a directory, refusing to overwrite
+// existing files unless force is set
+func writeExportFiles(exporter export.FileExporter, project *schema.Project, dir string, force bool) error {
+	files, err := exporter.ExportFiles(project)
+	if err != nil {
+		return fmt.Errorf("%s export failed: %w", exporter.Name(), err)
+	}
+	return writeFiles(files, dir, force)
+}
+
+// writeFiles writes exported files to a directory, refusing to overwrite existing files
+// unless force is set, and refusing any path that leaves the directory
+func writeFiles(files []export.File, dir string, force bool) error {
+	// Check everything up front so a conflict doesn't leave a partial export behind
+	for _, file := range files {
+		if !filepath.IsLocal(filepath.FromSlash(file.Path)) {
+			return fmt.Errorf("%s is outside %s, not writing it", file.Path, dir)
+		}
+	}
+	if !force {
+		for _, file := range files {
+			if _, err := os.Stat(filepath.Join(dir, file.Path)); !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("%s already exists, use --force to overwrite", filepath.Join(dir, file.Path))
+			}
+		}
+	}
+
+	for _, file := range files {
+		target := filepath.Join(dir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, file.Content, 0o644); err != nil {
+			return err
+		}
+		progressf("Wrote %s\n", target)
+	}
+	return nil
+}
+
+// projectName names the project after the source directory or repository
+func projectName(sourcePath string) string {
+	if repo := githubRepo(sourcePath); repo != "" {
+		return path.Base(repo)
+	}
+	if parsed, err := url.Parse(sourcePath); err == nil && strings.Contains(sourcePath, "://") {
+		return path.Base(filesystems.TrimArchiveExt(strings.TrimSuffix(parsed.Host+parsed.Path, ".git")))
+	}
+	if sourcePath != filesystems.Stdin {
+		sourcePath = filesystems.TrimArchiveExt(sourcePath)
+	} else {
+		sourcePath = "." // Named after the directory a tarball is piped in from
+	}
+	if abs, err := filepath.Abs(sourcePath); err == nil {
+		return filepath.Base(abs)
+	}
+	return filepath.Base(sourcePath)
+}
+
+// githubRepo returns the "owner/name" of a github:// or GitHub git:// source, or empty
+func githubRepo(sourcePath string) string {
+	parsed, err := url.Parse(sourcePath)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	switch {
+	case parsed.Scheme == "github" && parts[0] != "":
+		return parsed.Host + "/" + parts[0]
+	case parsed.Scheme == "git" && parsed.Host == "github.com" && len(parts) >= 2:
+		return parts[0] + "/" + strings.TrimSuffix(parts[1], ".git")
+	}
+	return ""
+}
+
+// normalizeProject converts discovered services into a project, with each service's
+// environment variables and databases mapped to their Railway equivalents, and
+// variables pointing at other services turned into references
+func normalizeProject(ctx context.Context, filesystem filesystems.FileSystem, sourcePath string, services []types.Service) *schema.Project {
+	return enrichment.NewProject(ctx, filesystem, filesystems.GetBasePath(sourcePath), projectName(sourcePath), services)
+}
+
+func init() {
+	exportCmd.PersistentFlags().StringVarP(&exportOutputDir, "output-dir", "d", ".", "directory to write exported files to")
+	exportCmd.PersistentFlags().StringVar(&snapshotIn, "plan", "", "export a "+schema.SnapshotFile+" snapshot instead of discovering services")
+	exportCmd.PersistentFlags().BoolVar(&exportForce, "force", false, "overwrite existing files")
+	exportTerraformCmd.Flags().StringVar(&exportRepo, "repo", "", "GitHub repository (owner/name) services built from source deploy from")
+	exportCmd.AddCommand(exportRailwayCmd, exportTerraformCmd, exportJSONCmd)
+	rootCmd.AddCommand(exportCmd)
+}
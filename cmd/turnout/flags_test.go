@@ -0,0 +1,33 @@
+package turnout
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestFlags_OutputIsTheFormat(t *testing.T) {
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		flag := cmd.Flags().ShorthandLookup("o")
+		if flag == nil {
+			flag = cmd.InheritedFlags().ShorthandLookup("o")
+		}
+		if flag != nil && (flag.Name != "output" || flag.DefValue != outputTable) {
+			t.Errorf("Expected -o on %q to be the output format, got --%s defaulting to %q", cmd.CommandPath(), flag.Name, flag.DefValue)
+		}
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(rootCmd)
+
+	for _, cmd := range []*cobra.Command{exportRailwayCmd, exportTerraformCmd, exportJSONCmd} {
+		if flag := cmd.InheritedFlags().ShorthandLookup("d"); flag == nil || flag.Name != "output-dir" {
+			t.Errorf("Expected %q to take its directory as -d/--output-dir, got %v", cmd.CommandPath(), flag)
+		}
+	}
+	if validateCmd.Flags().Lookup("output") == nil {
+		t.Errorf("Expected validate to take -o/--output")
+	}
+}
@@ -0,0 +1,58 @@
+package turnout
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/railwayapp/turnout/internal/export"
+	"github.com/railwayapp/turnout/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+var graphFormat string
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [source-path]",
+	Short: "Draw the discovered services and their dependencies",
+	Long: `Graph discovers the services in a source tree and prints who depends on whom,
+as Graphviz DOT or a Mermaid flowchart, to check the topology before applying it.
+Edges come from declared dependencies and from variables referencing another
+service, like a DATABASE_URL pointing at postgres, and are labelled with the
+variables. Railway databases are drawn as cylinders, and backing services implied by
+evidence like migrations rather than declared are dashed.
+
+  turnout graph . | dot -Tsvg > services.svg`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sourcePath := sourcePathArg(args)
+
+		if err := runGraph(cmd.Context(), sourcePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Graph failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func runGraph(ctx context.Context, sourcePath string) error {
+	exporter, err := export.NewGraphExporter(graphFormat)
+	if err != nil {
+		return err
+	}
+	project, _, err := loadProject(ctx, sourcePath)
+	if err != nil {
+		return err
+	}
+	output, err := exporter.Export(project)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(output)
+	return err
+}
+
+func init() {
+	graphCmd.Flags().StringVarP(&graphFormat, "format", "f", export.GraphDOT, "graph format: dot or mermaid")
+	graphCmd.Flags().StringVar(&snapshotIn, "plan", "", "draw a "+schema.SnapshotFile+" snapshot instead of discovering services")
+	rootCmd.AddCommand(graphCmd)
+}
@@ -0,0 +1,168 @@
+package turnout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/mcp"
+	"github.com/railwayapp/turnout/internal/validation"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve discovery and env extraction to agents over the Model Context Protocol",
+	Long: `Runs a Model Context Protocol server on stdin/stdout with tools agents can call:
+
+  discover_services  discover the deployable services in a source
+  extract_env        extract each service's environment variables
+  validate           check the discovered services for problems
+
+Sources are local paths or URLs like github://owner/repo and git://host/repo.git.
+Register it with an MCP client as the command "turnout mcp".`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		server := mcp.NewServer("turnout", "dev", mcpTools()...)
+		if err := server.Serve(ctx, os.Stdin, os.Stdout); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "MCP server failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+const mcpSourceSchema = `{
+  "type": "object",
+  "properties": {
+    "source": {"type": "string", "description": "Local path or URL, like github://owner/repo or git://github.com/owner/repo.git"}%s
+  },
+  "required": ["source"]
+}`
+
+func mcpTools() []mcp.Tool {
+	return []mcp.Tool{
+		{
+			Name:        "discover_services",
+			Description: "Discover the deployable services in a source tree: how each is built, where its code lives, whether it's public, private or a worker, its port, start command, schedule and the config files it was found in. Values of sensitive variables are masked.",
+			InputSchema: json.RawMessage(fmt.Sprintf(mcpSourceSchema, `,
+    "per_environment": {"type": "boolean", "description": "Discover services separately for each environment configs target"}`)),
+			Handler: func(ctx context.Context, arguments json.RawMessage) (any, error) {
+				var args struct {
+					Source         string `json:"source"`
+					PerEnvironment bool   `json:"per_environment"`
+				}
+				if err := json.Unmarshal(arguments, &args); err != nil {
+					return nil, err
+				}
+				services, _, cleanup, err := mcpDiscover(ctx, args.Source, args.PerEnvironment)
+				defer cleanup()
+				if err != nil {
+					return nil, err
+				}
+				return maskServices(services), nil
+			},
+		},
+		{
+			Name:        "extract_env",
+			Description: "Extract the environment variables each discovered service reads or declares, with where each was found. Values of sensitive variables are masked.",
+			InputSchema: json.RawMessage(fmt.Sprintf(mcpSourceSchema, `,
+    "service": {"type": "string", "description": "Only return variables for this service"}`)),
+			Handler: func(ctx context.Context, arguments json.RawMessage) (any, error) {
+				var args struct {
+					Source  string `json:"source"`
+					Service string `json:"service"`
+				}
+				if err := json.Unmarshal(arguments, &args); err != nil {
+					return nil, err
+				}
+				services, filesystem, cleanup, err := mcpDiscover(ctx, args.Source, false)
+				defer cleanup()
+				if err != nil {
+					return nil, err
+				}
+
+				result := make(map[string][]envVarOutput)
+				for name, results := range newEnvExtractor(filesystem, args.Source).ExtractServices(ctx, services) {
+					if args.Service != "" && name != args.Service {
+						continue
+					}
+					result[name] = []envVarOutput{}
+					for _, r := range results {
+						result[name] = append(result[name], newEnvVarOutput(r, true))
+					}
+				}
+				if args.Service != "" && result[args.Service] == nil {
+					return nil, fmt.Errorf("no service named %q", args.Service)
+				}
+				return result, nil
+			},
+		},
+		{
+			Name:        "validate",
+			Description: "Check the discovered services for problems that would break or surprise a deploy, like port conflicts, missing start commands and env vars read in code but never declared.",
+			InputSchema: json.RawMessage(fmt.Sprintf(mcpSourceSchema, "")),
+			Handler: func(ctx context.Context, arguments json.RawMessage) (any, error) {
+				var args struct {
+					Source string `json:"source"`
+				}
+				if err := json.Unmarshal(arguments, &args); err != nil {
+					return nil, err
+				}
+				services, filesystem, cleanup, err := mcpDiscover(ctx, args.Source, false)
+				defer cleanup()
+				if err != nil {
+					return nil, err
+				}
+				issues := validation.Validate(services, newEnvExtractor(filesystem, args.Source).ExtractServiceResults(ctx, services))
+				if issues == nil {
+					issues = []validation.Issue{}
+				}
+				return issues, nil
+			},
+		},
+	}
+}
+
+// mcpDiscover discovers the services in a tool call's source. The returned cleanup
+// must be called once the filesystem is no longer needed.
+func mcpDiscover(ctx context.Context, source string, perEnvironment bool) ([]types.Service, filesystems.FileSystem, func(), error) {
+	cleanup := func() {}
+	if source == "" {
+		return nil, nil, cleanup, fmt.Errorf("source is required")
+	}
+
+	filesystem, err := newFileSystem(ctx, source)
+	if err != nil {
+		return nil, nil, cleanup, fmt.Errorf("failed to create filesystem: %w", err)
+	}
+	if gitFS, ok := filesystems.Unwrap(filesystem).(*filesystems.GitFS); ok {
+		cleanup = func() { _ = gitFS.Cleanup() }
+	}
+
+	serviceDiscovery := newServiceDiscovery(filesystem)
+	var services []types.Service
+	if perEnvironment {
+		services, err = serviceDiscovery.DiscoverEnvironments(ctx, source)
+	} else {
+		services, err = serviceDiscovery.Discover(ctx, source)
+	}
+	if err != nil {
+		return nil, nil, cleanup, fmt.Errorf("service discovery failed: %w", err)
+	}
+	if services == nil {
+		services = []types.Service{}
+	}
+	return services, filesystem, cleanup, nil
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
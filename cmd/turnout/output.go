@@ -0,0 +1,193 @@
+package turnout
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	envTypes "github.com/railwayapp/turnout/internal/environment/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats for commands that print results
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormat string
+
+// redact is --redact, which masks sensitive values in output, and redactSet whether
+// it was given
+var redact, redactSet bool
+
+// redacting reports whether to mask sensitive values: as --redact says, or by default
+// for structured output, which tends to be saved or passed on
+func redacting(structured bool) bool {
+	if redactSet {
+		return redact
+	}
+	return structured
+}
+
+// redactServices copies services for structured output, with the values of sensitive
+// variables masked unless --redact=false
+func redactServices(services []types.Service) []types.Service {
+	if !redacting(true) {
+		return services
+	}
+	return maskServices(services)
+}
+
+// maskServices copies services with the values of sensitive variables masked
+func maskServices(services []types.Service) []types.Service {
+	redacted := slices.Clone(services)
+	for i, service := range redacted {
+		if len(service.Variables) == 0 {
+			continue
+		}
+		redacted[i].Variables = make(map[string]string, len(service.Variables))
+		for name, value := range service.Variables {
+			_, sensitive := envTypes.ClassifyEnvVar(name, value)
+			redacted[i].Variables[name] = envTypes.Redact(value, sensitive)
+		}
+	}
+	return redacted
+}
+
+// checkOutputFormat rejects unknown --output values before any work is done
+func checkOutputFormat() error {
+	switch outputFormat {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, expected table, json or yaml", outputFormat)
+}
+
+// progressf prints progress text to stderr so stdout only carries results
+func progressf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// writeStructured prints a value as JSON or YAML for the json and yaml output formats
+func writeStructured(w io.Writer, value any) error {
+	if outputFormat == outputYAML {
+		// Encode through JSON so both formats share field names
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		var generic any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(generic); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+
+	output, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(output))
+	return err
+}
+
+// printWarnings prints what discovery skipped, so missing services aren't a mystery
+func printWarnings(w io.Writer, warnings []types.Warning) {
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Warnings, skipped while discovering:")
+	for _, warning := range warnings {
+		location := warning.File
+		if location == "" {
+			location = warning.Signal
+		}
+		fmt.Fprintf(w, "  - %s: %s\n", location, warning.Message)
+	}
+}
+
+// printServices prints discovered services for the table output format
+func printServices(w io.Writer, services []types.Service) {
+	fmt.Fprintf(w, "Discovered %d services:\n", len(services))
+	for _, service := range services {
+		fmt.Fprintf(w, "  - %s: Network=%s, Runtime=%s, Build=%s\n",
+			service.Name,
+			service.Network,
+			service.Runtime,
+			service.Build)
+
+		if service.Environment != "" {
+			fmt.Fprintf(w, "    Environment: %s\n", service.Environment)
+		}
+		if service.BuildPath != "" {
+			fmt.Fprintf(w, "    BuildPath: %s\n", service.BuildPath)
+		}
+		if service.Image != "" {
+			fmt.Fprintf(w, "    Image: %s\n", service.Image)
+		}
+		if service.OutputDir != "" {
+			fmt.Fprintf(w, "    OutputDir: %s\n", service.OutputDir)
+		}
+		if service.StartCommand != "" {
+			fmt.Fprintf(w, "    StartCommand: %s\n", service.StartCommand)
+		}
+		if service.BaseImage != "" {
+			fmt.Fprintf(w, "    BaseImage: %s\n", service.BaseImage)
+		}
+		if service.Port != 0 {
+			fmt.Fprintf(w, "    Port: %d\n", service.Port)
+		}
+		if service.HealthcheckPath != "" {
+			fmt.Fprintf(w, "    HealthcheckPath: %s\n", service.HealthcheckPath)
+		}
+		if service.PackageManager != "" {
+			fmt.Fprintf(w, "    PackageManager: %s\n", service.PackageManager)
+		}
+		if api := service.API; api != nil {
+			fmt.Fprintf(w, "    API: %s %s\n", strings.TrimSpace(api.Type+" "+api.Version), api.SpecPath)
+		}
+		if service.Schedule != "" {
+			fmt.Fprintf(w, "    Schedule: %s\n", service.Schedule)
+		}
+		if service.PreDeployCommand != "" {
+			fmt.Fprintf(w, "    PreDeployCommand: %s\n", service.PreDeployCommand)
+		}
+		for _, volume := range service.Volumes {
+			fmt.Fprintf(w, "    Volume: %s -> %s\n", volume.Name, volume.MountPath)
+		}
+		if len(service.Networks) > 0 {
+			fmt.Fprintf(w, "    Networks: %s\n", strings.Join(service.Networks, ", "))
+		}
+		if ingress := service.Ingress; ingress != nil {
+			if len(ingress.From) == 0 {
+				fmt.Fprintf(w, "    Ingress: denied to every service\n")
+			} else {
+				fmt.Fprintf(w, "    Ingress: only from %s\n", strings.Join(ingress.From, ", "))
+			}
+		}
+		if service.Derived {
+			fmt.Fprintf(w, "    Derived: implied by project files, not declared\n")
+		}
+
+		fmt.Fprintf(w, "    Config sources (%d):\n", len(service.Configs))
+		for _, config := range service.Configs {
+			if config.Environment != "" {
+				fmt.Fprintf(w, "      - %s: %s (%s)\n", config.Type, config.Path, config.Environment)
+			} else {
+				fmt.Fprintf(w, "      - %s: %s\n", config.Type, config.Path)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,96 @@
+package turnout
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+)
+
+var noProgress bool
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// showProgress renders discovery progress as a spinner on stderr while discovery
+// runs, returning a func that stops and clears it. Nothing is rendered unless
+// stderr is a terminal, so piped and redirected output stays clean.
+func showProgress(serviceDiscovery *discovery.ServiceDiscovery) func() {
+	if noProgress || !isTerminal(os.Stderr) {
+		return func() {}
+	}
+
+	var mu sync.Mutex
+	var latest discovery.Progress
+	serviceDiscovery.OnProgress(func(progress discovery.Progress) {
+		mu.Lock()
+		latest = progress
+		mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			select {
+			case <-done:
+				fmt.Fprint(os.Stderr, "\r\033[K")
+				return
+			case <-ticker.C:
+				mu.Lock()
+				progress := latest
+				mu.Unlock()
+				fmt.Fprintf(os.Stderr, "\r\033[K%s %s", spinnerFrames[frame%len(spinnerFrames)], formatProgress(progress))
+			}
+		}
+	}()
+
+	return func() {
+		serviceDiscovery.OnProgress(nil)
+		close(done)
+		<-stopped
+	}
+}
+
+// formatProgress describes discovery progress in one line
+func formatProgress(progress discovery.Progress) string {
+	var details []string
+	if progress.BytesDownloaded > 0 {
+		details = append(details, formatBytes(progress.BytesDownloaded)+" downloaded")
+	}
+	if progress.EntriesVisited > 0 {
+		details = append(details, fmt.Sprintf("%d entries", progress.EntriesVisited))
+	}
+	if progress.Signal != "" {
+		details = append(details, progress.Signal)
+	}
+	if len(details) == 0 {
+		return "Discovering services..."
+	}
+	return "Discovering services: " + strings.Join(details, ", ")
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. 1.5 MB
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// isTerminal reports whether a file is a terminal rather than a pipe or regular file
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
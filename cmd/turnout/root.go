@@ -2,16 +2,24 @@ package turnout
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"strings"
+	"time"
 
 	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/signals"
 	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/environment"
+	envTypes "github.com/railwayapp/turnout/internal/environment/types"
+	"github.com/railwayapp/turnout/internal/export"
 	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/railway"
+	"github.com/railwayapp/turnout/internal/validation"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -19,6 +27,15 @@ import (
 var cfgFile string
 var cpuprofile string
 var memprofile string
+var helmValues []string
+var skaffoldProfiles []string
+var perEnvironment bool
+var submodules bool
+var fsAuditFile string
+var fsAudits []*filesystems.AuditFS
+var timeout time.Duration
+var pipelineDryRun bool
+var cancelTimeout context.CancelFunc = func() {}
 
 var rootCmd = &cobra.Command{
 	Use:   "turnout [source-path]",
@@ -27,8 +44,27 @@ var rootCmd = &cobra.Command{
 1. Parse - Find and parse deployment configs (Docker Compose, Kubernetes, etc.)
 2. Normalize - Convert to unified intermediate representation
 3. Validate/Enrich - Add semantic information and validate consistency
-4. Export - Generate Railway deployment configuration`,
+4. Export - Generate Railway deployment configuration
+
+The Railway config is written to --output-dir, after a report of the services found,
+the issues with them and the Railway project they'd become. Nothing is written when
+validation finds errors, unless --force is set. --dry-run prints the report and the
+files that would be written instead.`,
 	Args: cobra.MaximumNArgs(1),
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Clones, downloads, walks and extraction all stop when the deadline passes
+		if timeout > 0 {
+			var ctx context.Context
+			ctx, cancelTimeout = context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(ctx)
+		}
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		cancelTimeout()
+		if err := writeFSAudit(); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not write filesystem audit: %v\n", err)
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Start CPU profiling if requested
 		if cpuprofile != "" {
@@ -45,20 +81,17 @@ var rootCmd = &cobra.Command{
 			defer pprof.StopCPUProfile()
 		}
 
-		sourcePath := "."
-		if len(args) > 0 {
-			sourcePath = args[0]
+		sourcePath := sourcePathArg(args)
 
-			// If user provided a file path, use the parent directory
-			if stat, err := os.Stat(sourcePath); err == nil && !stat.IsDir() {
-				sourcePath = filepath.Dir(sourcePath)
-			}
+		if err := checkOutputFormat(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
 
-		fmt.Printf("Processing source tree: %s\n", sourcePath)
+		progressf("Processing source tree: %s\n", sourcePath)
 
-		if err := runPipeline(sourcePath); err != nil {
-			fmt.Printf("Pipeline failed: %v\n", err)
+		if err := runPipeline(cmd.Context(), sourcePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Pipeline failed: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -91,6 +124,29 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.turnout/config.json)")
 	rootCmd.PersistentFlags().StringVar(&cpuprofile, "cpuprofile", "", "write cpu profile to file")
 	rootCmd.PersistentFlags().StringVar(&memprofile, "memprofile", "", "write memory profile to file")
+	rootCmd.PersistentFlags().StringVar(&fsAuditFile, "fs-audit", "", "write every path read from the source, with byte counts and the component that read it, to file (- for stderr)")
+	rootCmd.PersistentFlags().StringSliceVar(&skaffoldProfiles, "skaffold-profile", nil, "Skaffold profiles to activate when reading skaffold.yaml")
+	rootCmd.PersistentFlags().String("compose-env", signals.ComposeProduction, "compose environment layered over base compose files (production or development)")
+	cobra.CheckErr(viper.BindPFlag("compose-env", rootCmd.PersistentFlags().Lookup("compose-env")))
+	rootCmd.PersistentFlags().BoolVar(&perEnvironment, "per-environment", false, "discover services separately for each environment configs target, like compose.prod.yaml")
+	rootCmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, "output format: table, json or yaml")
+	rootCmd.Flags().BoolVar(&pipelineDryRun, "dry-run", false, "print the migration report and the Railway config without writing anything")
+	rootCmd.Flags().StringVar(&exportOutputDir, "output-dir", ".", "directory to write the Railway config to")
+	rootCmd.Flags().BoolVar(&exportForce, "force", false, "overwrite existing files, and export despite validation errors")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "don't show a progress spinner on stderr while discovering services")
+	rootCmd.PersistentFlags().StringSliceVar(&helmValues, "helm-values", nil, "extra values files applied when rendering Helm charts, relative to each chart")
+	rootCmd.PersistentFlags().Int64Var(&filesystems.MaxTextFileSize, "max-file-size", filesystems.MaxTextFileSize, "largest file in bytes scanned for environment variables and config, bigger files are skipped")
+	rootCmd.PersistentFlags().BoolVar(&redact, "redact", false, "mask the values of sensitive variables in output (default true for json and yaml output)")
+	rootCmd.PersistentFlags().StringSlice("sensitive-pattern", nil, "regular expression matched against variable names, case-insensitively, to mark more variables sensitive")
+	cobra.CheckErr(viper.BindPFlag("sensitive-pattern", rootCmd.PersistentFlags().Lookup("sensitive-pattern")))
+	rootCmd.PersistentFlags().StringSlice("safe-variable", nil, "variable name that's never sensitive, whatever it looks like")
+	cobra.CheckErr(viper.BindPFlag("safe-variable", rootCmd.PersistentFlags().Lookup("safe-variable")))
+	rootCmd.PersistentFlags().Int("entropy-min-length", envTypes.Entropy.MinLength, "shortest value without a known token format that's considered randomly generated, and so sensitive")
+	cobra.CheckErr(viper.BindPFlag("entropy-min-length", rootCmd.PersistentFlags().Lookup("entropy-min-length")))
+	rootCmd.PersistentFlags().Float64("entropy-min-bits", envTypes.Entropy.MinEntropy, "fewest bits of entropy per character a value without a known token format needs to be considered randomly generated")
+	cobra.CheckErr(viper.BindPFlag("entropy-min-bits", rootCmd.PersistentFlags().Lookup("entropy-min-bits")))
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "give up on the source after this long, like 30s or 5m (default no limit)")
+	rootCmd.PersistentFlags().BoolVar(&submodules, "submodules", false, "clone the submodules of git sources so services vendored as submodules are discovered")
 }
 
 func initConfig() {
@@ -106,94 +162,212 @@ func initConfig() {
 	}
 
 	viper.AutomaticEnv()
+	redactSet = rootCmd.PersistentFlags().Changed("redact")
 
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
+
+	policy, err := envTypes.NewSensitivityPolicy(viper.GetStringSlice("sensitive-pattern"), viper.GetStringSlice("safe-variable"))
+	cobra.CheckErr(err)
+	envTypes.Sensitivity = policy
+	envTypes.Entropy = envTypes.EntropyThresholds{MinLength: viper.GetInt("entropy-min-length"), MinEntropy: viper.GetFloat64("entropy-min-bits")}
 }
 
-func runPipeline(sourcePath string) error {
+// sourcePathArg returns the source path argument, "." if none. Paths to files use
+// their parent directory, except archives which are sources themselves.
+func sourcePathArg(args []string) string {
+	if len(args) == 0 {
+		return "."
+	}
+	sourcePath := args[0]
+	if filesystems.IsArchive(sourcePath) {
+		return sourcePath
+	}
+	if stat, err := os.Stat(sourcePath); err == nil && !stat.IsDir() {
+		return filepath.Dir(sourcePath)
+	}
+	return sourcePath
+}
+
+// newFileSystem creates the filesystem for a source, configured from flags
+func newFileSystem(ctx context.Context, sourcePath string) (filesystems.FileSystem, error) {
+	filesystem, err := filesystems.NewFileSystemContext(ctx, sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	if gitFS, ok := filesystem.(*filesystems.GitFS); ok {
+		gitFS.Submodules = submodules
+	}
+	if fsAuditFile != "" {
+		audit := filesystems.NewAuditFS(filesystem)
+		fsAudits = append(fsAudits, audit)
+		filesystem = audit
+	}
+	// Signals and the env walk read many of the same files
+	return filesystems.NewCachedFS(filesystem), nil
+}
+
+// writeFSAudit writes the reads of every filesystem opened during the command to --fs-audit
+func writeFSAudit() error {
+	if fsAuditFile == "" {
+		return nil
+	}
+	w := os.Stderr
+	if fsAuditFile != "-" {
+		f, err := os.Create(fsAuditFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	for _, audit := range fsAudits {
+		if err := audit.WriteReport(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newServiceDiscovery creates service discovery with the default signals configured from flags
+func newServiceDiscovery(filesystem filesystems.FileSystem) *discovery.ServiceDiscovery {
+	defaultSignals := discovery.DefaultSignals(filesystem)
+	for _, signal := range defaultSignals {
+		switch signal := signal.(type) {
+		case *signals.HelmSignal:
+			signal.ValuesFiles = helmValues
+		case *signals.SkaffoldSignal:
+			signal.Profiles = skaffoldProfiles
+		case *signals.DockerComposeSignal:
+			signal.Environment = viper.GetString("compose-env")
+		}
+	}
+	return discovery.NewServiceDiscovery(filesystem, defaultSignals...)
+}
+
+// newEnvExtractor creates an env extractor that applies the shared env files in the
+// scanned directory to the services below it
+func newEnvExtractor(filesystem filesystems.FileSystem, sourcePath string) *environment.Extractor {
+	extractor := environment.NewExtractor(filesystem)
+	extractor.SetRoot(filesystems.GetBasePath(sourcePath))
+	return extractor
+}
+
+// discoverServices runs discovery, per environment if requested
+func discoverServices(ctx context.Context, serviceDiscovery *discovery.ServiceDiscovery, sourcePath string) ([]types.Service, error) {
+	defer showProgress(serviceDiscovery)()
+	if perEnvironment {
+		return serviceDiscovery.DiscoverEnvironments(ctx, sourcePath)
+	}
+	return serviceDiscovery.Discover(ctx, sourcePath)
+}
+
+func runPipeline(ctx context.Context, sourcePath string) error {
 	// Create filesystem from the sourcePath (supports file://, github://, git://)
-	filesystem, err := filesystems.NewFileSystem(sourcePath)
+	filesystem, err := newFileSystem(ctx, sourcePath)
 	if err != nil {
 		return fmt.Errorf("failed to create filesystem: %w", err)
 	}
 
 	// Clean up git filesystem if needed
-	if gitFS, ok := filesystem.(*filesystems.GitFS); ok {
+	if gitFS, ok := filesystems.Unwrap(filesystem).(*filesystems.GitFS); ok {
 		defer gitFS.Cleanup()
 	}
 
 	// Service discovery - find and triangulate services from multiple signals
-	serviceDiscovery := discovery.NewServiceDiscovery(filesystem)
-	services, err := serviceDiscovery.Discover(context.Background(), sourcePath)
+	serviceDiscovery := newServiceDiscovery(filesystem)
+	services, err := discoverServices(ctx, serviceDiscovery, sourcePath)
 	if err != nil {
 		return fmt.Errorf("service discovery failed: %w", err)
 	}
 
-	fmt.Printf("Discovered %d services:\n", len(services))
-	for _, service := range services {
-		fmt.Printf("  - %s: Network=%s, Runtime=%s, Build=%s\n",
-			service.Name,
-			networkToString(service.Network),
-			runtimeToString(service.Runtime),
-			buildToString(service.Build))
+	issues, err := validateServices(ctx, filesystem, sourcePath, services)
+	if err != nil {
+		return err
+	}
+	revision := sourceRevision(filesystem)
+
+	// Normalize and export - what would be deployed to Railway
+	project := normalizeProject(ctx, filesystem, sourcePath, services)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	files, err := export.NewRailwayExporter().ExportFiles(project)
+	if err != nil {
+		return fmt.Errorf("railway export failed: %w", err)
+	}
+	plan := railway.NewPlan(project, githubRepo(sourcePath), "")
 
-		if service.BuildPath != "" {
-			fmt.Printf("    BuildPath: %s\n", service.BuildPath)
+	if outputFormat != outputTable {
+		if issues == nil {
+			issues = []validation.Issue{} // [] rather than null for consumers
 		}
-		if service.Image != "" {
-			fmt.Printf("    Image: %s\n", service.Image)
+		warnings := serviceDiscovery.Warnings()
+		if warnings == nil {
+			warnings = []types.Warning{}
 		}
-
-		fmt.Printf("    Config sources (%d):\n", len(service.Configs))
-		for _, config := range service.Configs {
-			fmt.Printf("      - %s: %s\n", config.Type, config.Path)
+		exported := make([]pipelineFile, 0, len(files))
+		for _, file := range files {
+			exported = append(exported, pipelineFile{Path: file.Path, Content: string(file.Content)})
 		}
-		fmt.Println()
-	}
+		if err := writeStructured(os.Stdout, struct {
+			Revision string             `json:"revision,omitempty"`
+			DryRun   bool               `json:"dryRun"`
+			Services []types.Service    `json:"services"`
+			Warnings []types.Warning    `json:"warnings"`
+			Issues   []validation.Issue `json:"issues"`
+			Files    []pipelineFile     `json:"files"`
+		}{revision, pipelineDryRun, redactServices(services), warnings, issues, exported}); err != nil {
+			return err
+		}
+	} else {
+		if revision != "" {
+			progressf("Scanned %s at %s\n", sourcePath, revision)
+		}
+		printServices(os.Stdout, services)
+		printWarnings(os.Stdout, serviceDiscovery.Warnings())
 
-	// Export to JSON
-	output, err := json.MarshalIndent(services, "", "  ")
-	if err != nil {
-		return fmt.Errorf("JSON export failed: %w", err)
+		// Validate/Enrich - report problems before anything is exported
+		printIssues(issues)
+
+		fmt.Println("\nPlan:")
+		plan.Write(os.Stdout)
 	}
 
-	fmt.Printf("\nJSON Export:\n%s\n", string(output))
-	return nil
+	if pipelineDryRun {
+		if outputFormat == outputTable {
+			printDryRunFiles(os.Stdout, files, exportOutputDir)
+		}
+		return nil
+	}
+	if failing := countErrors(issues); failing > 0 && !exportForce {
+		return fmt.Errorf("not exporting, %d issues would break the deploy, fix them or use --force", failing)
+	}
+	return writeFiles(files, exportOutputDir, exportForce)
 }
 
-func networkToString(n types.Network) string {
-	switch n {
-	case types.NetworkNone:
-		return "none"
-	case types.NetworkPrivate:
-		return "private"
-	case types.NetworkPublic:
-		return "public"
-	default:
-		return "unknown"
-	}
+// pipelineFile is an exported file in the pipeline's structured output
+type pipelineFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
 }
 
-func runtimeToString(r types.Runtime) string {
-	switch r {
-	case types.RuntimeContinuous:
-		return "continuous"
-	case types.RuntimeScheduled:
-		return "scheduled"
-	default:
-		return "unknown"
+// printDryRunFiles prints the files a pipeline run would write, with their contents
+func printDryRunFiles(w io.Writer, files []export.File, dir string) {
+	fmt.Fprintf(w, "\nWould write %d files to %s:\n", len(files), dir)
+	for _, file := range files {
+		fmt.Fprintf(w, "\n--- %s\n%s\n", filepath.Join(dir, filepath.FromSlash(file.Path)), strings.TrimRight(string(file.Content), "\n"))
 	}
 }
 
-func buildToString(b types.Build) string {
-	switch b {
-	case types.BuildFromSource:
-		return "source"
-	case types.BuildFromImage:
-		return "image"
-	default:
-		return "unknown"
+// countErrors counts the issues that would stop a service deploying
+func countErrors(issues []validation.Issue) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.Severity == validation.SeverityError {
+			count++
+		}
 	}
+	return count
 }
@@ -0,0 +1,75 @@
+package turnout
+
+import (
+	"cmp"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunPipeline_Export(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string // railway.json unless set
+		config   string
+		dryRun   bool
+		force    bool
+		existing bool // a railway.json is already in the output directory
+		written  bool
+		fails    bool
+	}{
+		{name: "writes the config", config: `{"deploy": {"startCommand": "node server.js"}}`, written: true},
+		{name: "dry run writes nothing", config: `{"deploy": {"startCommand": "node server.js"}}`, dryRun: true},
+		{name: "validation errors stop the export", config: `{"deploy": {"startCommand": "node jobs.js", "cronSchedule": "every day"}}`, fails: true},
+		{name: "force exports despite validation errors", config: `{"deploy": {"startCommand": "node jobs.js", "cronSchedule": "every day"}}`, force: true, written: true},
+		{name: "existing files aren't overwritten", config: `{"deploy": {"startCommand": "node server.js"}}`, existing: true, fails: true},
+		{name: "a root directory outside the repo stays in the export", filename: "render.yaml", config: "services:\n  - type: web\n    name: web\n    runtime: node\n    rootDir: ../../escape\n    startCommand: node server.js\n", written: true},
+	}
+
+	defer func(stdout *os.File) { os.Stdout = stdout }(os.Stdout)
+	defer func(dryRun, force bool, dir string) {
+		pipelineDryRun, exportForce, exportOutputDir = dryRun, force, dir
+	}(pipelineDryRun, exportForce, exportOutputDir)
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Stdout = devNull
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := t.TempDir()
+			if err := os.WriteFile(filepath.Join(source, cmp.Or(tt.filename, "railway.json")), []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			// Nested, so anything escaping it two levels up still lands in the test's directory
+			base := t.TempDir()
+			output := filepath.Join(base, "out", "export")
+			existing := filepath.Join(output, "railway.project.json")
+			if tt.existing {
+				if err := os.MkdirAll(output, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(existing, []byte("{}"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			pipelineDryRun, exportForce, exportOutputDir = tt.dryRun, tt.force, output
+			err := runPipeline(context.Background(), source)
+			if (err != nil) != tt.fails {
+				t.Fatalf("Expected failure to be %v, got %v", tt.fails, err)
+			}
+
+			content, _ := os.ReadFile(existing)
+			if written := len(content) > len("{}"); written != tt.written {
+				t.Errorf("Expected the Railway config written to be %v, got %q", tt.written, content)
+			}
+			if _, err := os.Stat(filepath.Join(base, "escape")); err == nil {
+				t.Errorf("Expected nothing written outside %s", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package turnout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/schema"
+	"github.com/railwayapp/turnout/internal/validation"
+)
+
+var snapshotOut string // written by discover and env
+var snapshotIn string  // read by export, apply and diff instead of discovering
+
+// loadProject reads the project from the --plan snapshot, or discovers and normalizes
+// it from the source path. It returns the source the project came from.
+func loadProject(ctx context.Context, sourcePath string) (*schema.Project, string, error) {
+	if snapshotIn != "" {
+		file, err := os.Open(snapshotIn)
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+
+		snapshot, err := schema.ReadSnapshot(file)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", snapshotIn, err)
+		}
+		return snapshot.Project, snapshot.Source, nil
+	}
+
+	filesystem, err := newFileSystem(ctx, sourcePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create filesystem: %w", err)
+	}
+
+	if gitFS, ok := filesystems.Unwrap(filesystem).(*filesystems.GitFS); ok {
+		defer gitFS.Cleanup()
+	}
+
+	services, err := discoverServices(ctx, newServiceDiscovery(filesystem), sourcePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("service discovery failed: %w", err)
+	}
+	if err := checkPlatforms(services); err != nil {
+		return nil, "", err
+	}
+	project := normalizeProject(ctx, filesystem, sourcePath, services)
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	return project, sourcePath, nil
+}
+
+// checkPlatforms refuses services that can't run as Linux containers, rather than
+// planning deploys that would fail
+func checkPlatforms(services []types.Service) error {
+	var errs []error
+	for _, issue := range validation.UnsupportedPlatforms(services) {
+		errs = append(errs, fmt.Errorf("%s %s", issue.Service, issue.Message))
+	}
+	return errors.Join(errs...)
+}
+
+// writeSnapshot saves a project to the --plan-out snapshot, if requested
+func writeSnapshot(source, revision string, project *schema.Project) error {
+	if snapshotOut == "" {
+		return nil
+	}
+
+	file, err := os.Create(snapshotOut)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	snapshot := schema.NewSnapshot(source, project)
+	snapshot.Revision = revision
+	if err := snapshot.Write(file); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", snapshotOut)
+	return file.Close()
+}
+
+// sourceRevision returns the commit SHA a filesystem was read at, or empty for sources
+// that aren't a revision of a repository
+func sourceRevision(filesystem filesystems.FileSystem) string {
+	reporter, ok := filesystems.Unwrap(filesystem).(filesystems.RevisionReporter)
+	if !ok {
+		return ""
+	}
+	revision, err := reporter.Revision()
+	if err != nil {
+		progressf("Warning: could not resolve the revision scanned: %v\n", err)
+		return ""
+	}
+	return revision
+}
@@ -0,0 +1,119 @@
+package turnout
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	envTypes "github.com/railwayapp/turnout/internal/environment/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/validation"
+	"github.com/spf13/cobra"
+)
+
+var validateJSON bool // --json, from before validate took --output
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [source-path]",
+	Short: "Check discovered services for problems before exporting",
+	Long: `Validate discovers the services in a source tree and reports problems that
+would break or surprise a deploy: port conflicts, missing start commands, public
+services with nothing to route to, environment variables read in code but never
+declared, cron schedules that don't parse, and services that need Windows
+containers.
+
+Exits with status 1 when any issue is an error.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sourcePath := sourcePathArg(args)
+
+		if validateJSON {
+			outputFormat = outputJSON
+		}
+		if err := checkOutputFormat(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		issues, err := runValidate(cmd.Context(), sourcePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Validation failed: %v\n", err)
+			os.Exit(2)
+		}
+		for _, issue := range issues {
+			if issue.Severity == validation.SeverityError {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func runValidate(ctx context.Context, sourcePath string) ([]validation.Issue, error) {
+	filesystem, err := newFileSystem(ctx, sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create filesystem: %w", err)
+	}
+
+	if gitFS, ok := filesystems.Unwrap(filesystem).(*filesystems.GitFS); ok {
+		defer gitFS.Cleanup()
+	}
+
+	services, err := discoverServices(ctx, newServiceDiscovery(filesystem), sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("service discovery failed: %w", err)
+	}
+
+	issues, err := validateServices(ctx, filesystem, sourcePath, services)
+	if err != nil {
+		return nil, err
+	}
+	if outputFormat != outputTable {
+		if issues == nil {
+			issues = []validation.Issue{}
+		}
+		if err := writeStructured(os.Stdout, issues); err != nil {
+			return nil, err
+		}
+	} else {
+		printIssues(issues)
+	}
+	return issues, nil
+}
+
+// validateServices runs the validation stage over discovered services
+func validateServices(ctx context.Context, filesystem filesystems.FileSystem, sourcePath string, services []types.Service) ([]validation.Issue, error) {
+	envVars := newEnvExtractor(filesystem, sourcePath).ExtractServiceResults(ctx, services)
+	if err := ctx.Err(); err != nil {
+		return nil, err // Extraction stopped partway, so its variables can't be trusted
+	}
+	return validation.Validate(services, envVars), nil
+}
+
+func printIssues(issues []validation.Issue) {
+	if len(issues) == 0 {
+		fmt.Println("No issues found")
+		return
+	}
+	fmt.Printf("Found %d issues:\n", len(issues))
+	for _, issue := range issues {
+		if issue.Service != "" {
+			fmt.Printf("  [%s] %s: %s (%s)\n", issue.Severity, issue.Service, issue.Message, issue.Code)
+		} else {
+			fmt.Printf("  [%s] %s (%s)\n", issue.Severity, issue.Message, issue.Code)
+		}
+		if issue.File != "" {
+			fmt.Printf("    at %s\n", sourceLocation(issue.File, envTypes.Span{Line: issue.Line}))
+		}
+		if issue.Suggestion != "" {
+			fmt.Printf("    suggestion: %s\n", issue.Suggestion)
+		}
+	}
+}
+
+func init() {
+	validateCmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, "output format: table, json or yaml")
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "print issues as JSON")
+	_ = validateCmd.Flags().MarkDeprecated("json", "use --output json")
+	rootCmd.AddCommand(validateCmd)
+}
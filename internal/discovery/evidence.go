@@ -0,0 +1,165 @@
+package discovery
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+)
+
+// EvidenceModel scores a value signals found for a service field, like a start command,
+// from the weight of every signal that found it. Triangulation keeps the value scoring
+// highest when signals disagree.
+type EvidenceModel interface {
+	Score(weights []int) int
+}
+
+// AdditiveEvidence sums the weights, so several signals agreeing, like a Dockerfile,
+// package.json and Procfile with the same start command, outweigh one more trusted
+// config that disagrees, like a stale render.yaml
+type AdditiveEvidence struct{}
+
+func (AdditiveEvidence) Score(weights []int) int {
+	score := 0
+	for _, weight := range weights {
+		score += weight
+	}
+	return score
+}
+
+// StrongestEvidence scores a value by its most trusted signal alone, however many
+// others agree with it
+type StrongestEvidence struct{}
+
+func (StrongestEvidence) Score(weights []int) int {
+	if len(weights) == 0 {
+		return 0
+	}
+	return slices.Max(weights)
+}
+
+// SetEvidenceModel changes how values signals disagree on are chosen, AdditiveEvidence
+// unless set
+func (sd *ServiceDiscovery) SetEvidenceModel(model EvidenceModel) {
+	sd.evidence = model
+}
+
+// weighedField is a service field signals commonly disagree on, as a string so values
+// of any type can be compared
+type weighedField struct {
+	name string
+	get  func(types.Service) string
+	set  func(*types.Service, string)
+}
+
+var weighedFields = []weighedField{
+	{
+		name: "StartCommand",
+		get:  func(s types.Service) string { return s.StartCommand },
+		set:  func(s *types.Service, v string) { s.StartCommand = v },
+	},
+	{
+		name: "Port",
+		get: func(s types.Service) string {
+			if s.Port == 0 {
+				return ""
+			}
+			return strconv.Itoa(s.Port)
+		},
+		set: func(s *types.Service, v string) { s.Port, _ = strconv.Atoi(v) },
+	},
+	{
+		name: "HealthcheckPath",
+		get:  func(s types.Service) string { return s.HealthcheckPath },
+		set:  func(s *types.Service, v string) { s.HealthcheckPath = v },
+	},
+	{
+		name: "Platform",
+		get:  func(s types.Service) string { return s.Platform },
+		set:  func(s *types.Service, v string) { s.Platform = v },
+	},
+}
+
+// candidate is a value found for a field, with the evidence for it
+type candidate struct {
+	value      string
+	weights    []int
+	sources    []string
+	explicit   bool             // whether a config set the value outright
+	provenance types.Provenance // the most trusted inference of the value otherwise
+}
+
+// weighFields settles each field the group's signals disagree on by scoring the
+// values they found. A value's evidence from each source counts once, weighted by its
+// provenance's confidence, or its signal's confidence when a config set it outright.
+// Ties keep the base's value.
+func weighFields(base *types.Service, group []serviceWithSignal, model EvidenceModel) {
+	for _, field := range weighedFields {
+		if base.IsPinned(field.name) {
+			continue
+		}
+
+		var candidates []*candidate
+		for _, sws := range group {
+			value := field.get(sws.service)
+			if value == "" {
+				continue
+			}
+			provenance, inferred := sws.service.ProvenanceOf(field.name)
+			weight, source := sws.confidence, sws.signal
+			if inferred {
+				weight, source = provenance.Confidence, provenance.Source
+			}
+
+			index := slices.IndexFunc(candidates, func(c *candidate) bool { return c.value == value })
+			if index < 0 {
+				candidates = append(candidates, &candidate{value: value})
+				index = len(candidates) - 1
+			}
+			c := candidates[index]
+			if slices.Contains(c.sources, source) {
+				continue
+			}
+			c.sources = append(c.sources, source)
+			c.weights = append(c.weights, weight)
+			if !inferred {
+				c.explicit = true
+			} else if provenance.Confidence > c.provenance.Confidence {
+				c.provenance = provenance
+			}
+		}
+		if len(candidates) < 2 {
+			continue // Nothing to disagree about, the merge already took the value
+		}
+
+		current := field.get(*base)
+		best := slices.MaxFunc(candidates, func(a, b *candidate) int {
+			return cmp.Or(
+				cmp.Compare(model.Score(a.weights), model.Score(b.weights)),
+				compareBool(a.value == current, b.value == current),
+				cmp.Compare(slices.Max(a.weights), slices.Max(b.weights)),
+				cmp.Compare(b.value, a.value),
+			)
+		})
+		if best.value == current {
+			continue
+		}
+		field.set(base, best.value)
+		if best.explicit {
+			base.ClearProvenance(field.name)
+		} else {
+			base.SetProvenance(field.name, best.provenance.Source, best.provenance.Confidence)
+		}
+	}
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
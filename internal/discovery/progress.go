@@ -0,0 +1,52 @@
+package discovery
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// Progress is how far discovery has got, reported as it walks and generates services
+type Progress struct {
+	EntriesVisited  int    // directory entries observed by the signals so far
+	BytesDownloaded int64  // bytes downloaded by remote filesystems so far
+	Signal          string // the signal most recently asked to generate or refine services
+}
+
+// ProgressFunc receives progress updates. It may be called from multiple goroutines
+// but never concurrently.
+type ProgressFunc func(Progress)
+
+// OnProgress registers a callback for progress updates, including downloads by
+// filesystems that report them
+func (sd *ServiceDiscovery) OnProgress(progress ProgressFunc) {
+	sd.progressMu.Lock()
+	sd.progress = progress
+	sd.progressMu.Unlock()
+
+	if reporter, ok := filesystems.Unwrap(sd.filesystem).(filesystems.DownloadReporter); ok {
+		reporter.OnDownload(func(bytes int64) {
+			sd.reportProgress(func(p *Progress) { p.BytesDownloaded = bytes })
+		})
+	}
+}
+
+// reportProgress applies an update to the current progress and reports it
+func (sd *ServiceDiscovery) reportProgress(update func(*Progress)) {
+	sd.progressMu.Lock()
+	defer sd.progressMu.Unlock()
+	update(&sd.state)
+	if sd.progress != nil {
+		sd.progress(sd.state)
+	}
+}
+
+// signalName names a signal for progress, e.g. "DockerCompose" for *signals.DockerComposeSignal
+func signalName(signal ServiceSignal) string {
+	t := reflect.TypeOf(signal)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return strings.TrimSuffix(t.Name(), "Signal")
+}
@@ -1,10 +1,13 @@
 package discovery
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/railwayapp/turnout/internal/discovery/signals"
 	"github.com/railwayapp/turnout/internal/discovery/types"
@@ -15,6 +18,15 @@ import (
 type ServiceDiscovery struct {
 	signals    []ServiceSignal
 	filesystem filesystems.FileSystem
+
+	progress   ProgressFunc
+	state      Progress
+	progressMu sync.Mutex
+
+	warnings   []types.Warning
+	warningsMu sync.Mutex
+
+	evidence EvidenceModel
 }
 
 type ServiceSignal interface {
@@ -31,6 +43,34 @@ type ServiceSignal interface {
 	Confidence() int // 0-100, for conflict resolution
 }
 
+// ServiceConfidenceSignal is implemented by signals whose confidence depends on
+// what they found for a particular service rather than being fixed
+type ServiceConfidenceSignal interface {
+	ServiceConfidence(service types.Service) int
+}
+
+// ServiceRefiner is implemented by signals that learn how services relate to each
+// other and adjust them after every signal's services have been merged
+type ServiceRefiner interface {
+	RefineServices(ctx context.Context, services []types.Service) []types.Service
+}
+
+// DiagnosticSignal is implemented by signals that skip configs they can't use, like
+// a render.yaml that won't parse, so discovery can warn that something is missing
+type DiagnosticSignal interface {
+	Warnings() []types.Warning
+}
+
+// EnvironmentSignal is implemented by signals whose configs can target specific
+// environments, like compose.prod.yaml or values-staging.yaml
+type EnvironmentSignal interface {
+	// Environments lists the environments the observed configs target
+	Environments() []string
+
+	// SetEnvironment selects the configs for an environment, or the signal's default if empty
+	SetEnvironment(environment string)
+}
+
 func NewServiceDiscovery(filesystem filesystems.FileSystem, signals ...ServiceSignal) *ServiceDiscovery {
 	if len(signals) == 0 {
 		signals = DefaultSignals(filesystem)
@@ -39,6 +79,7 @@ func NewServiceDiscovery(filesystem filesystems.FileSystem, signals ...ServiceSi
 	return &ServiceDiscovery{
 		signals:    signals,
 		filesystem: filesystem,
+		evidence:   AdditiveEvidence{},
 	}
 }
 
@@ -46,6 +87,7 @@ func DefaultSignals(filesystem filesystems.FileSystem) []ServiceSignal {
 	return []ServiceSignal{
 		signals.NewDockerComposeSignal(filesystem),
 		signals.NewDockerfileSignal(filesystem),
+		signals.NewBakeSignal(filesystem),
 		signals.NewRailwaySignal(filesystem),
 		signals.NewFlySignal(filesystem),
 		signals.NewRenderSignal(filesystem),
@@ -55,9 +97,19 @@ func DefaultSignals(filesystem filesystems.FileSystem) []ServiceSignal {
 		signals.NewHerokuProcfileSignal(filesystem),
 		signals.NewHerokuAppJsonSignal(filesystem),
 		signals.NewSkaffoldSignal(filesystem),
+		signals.NewHelmSignal(filesystem),
+		signals.NewQuadletSignal(filesystem),
 		signals.NewServerlessSignal(filesystem),
 		signals.NewFrameworkSignal(filesystem),
+		signals.NewElixirSignal(filesystem),
+		signals.NewSpringBootSignal(filesystem),
 		signals.NewPackageSignal(filesystem),
+		signals.NewProxySignal(filesystem),
+		signals.NewMigrationSignal(filesystem),
+		signals.NewDependencySignal(filesystem),
+		signals.NewAPISignal(filesystem),
+		signals.NewPythonEntrypointSignal(filesystem),
+		signals.NewOverrideSignal(filesystem), // last, so overrides win over every other refiner
 	}
 }
 
@@ -70,12 +122,69 @@ type signalResult struct {
 type serviceWithSignal struct {
 	service    types.Service
 	confidence int
+	signal     string
 }
 
 func (sd *ServiceDiscovery) Discover(ctx context.Context, rootPath string) ([]types.Service, error) {
-	// Use the filesystem from the struct
-	filesystem := sd.filesystem
+	var lastCriticalError error
+	if err := sd.observe(ctx, rootPath, &lastCriticalError); err != nil {
+		return nil, err
+	}
+	return sd.generate(ctx, lastCriticalError)
+}
 
+// DiscoverEnvironments discovers services separately for each environment the project's
+// configs target, tagging each service with its environment. Projects without
+// environment-specific configs get the same untagged services as Discover.
+func (sd *ServiceDiscovery) DiscoverEnvironments(ctx context.Context, rootPath string) ([]types.Service, error) {
+	var lastCriticalError error
+	if err := sd.observe(ctx, rootPath, &lastCriticalError); err != nil {
+		return nil, err
+	}
+
+	var environments []string
+	var environmentSignals []EnvironmentSignal
+	for _, signal := range sd.signals {
+		if environmentSignal, ok := signal.(EnvironmentSignal); ok {
+			environmentSignals = append(environmentSignals, environmentSignal)
+			for _, environment := range environmentSignal.Environments() {
+				if !slices.Contains(environments, environment) {
+					environments = append(environments, environment)
+				}
+			}
+		}
+	}
+	if len(environments) == 0 {
+		return sd.generate(ctx, lastCriticalError)
+	}
+	slices.Sort(environments)
+
+	defer func() {
+		for _, signal := range environmentSignals {
+			signal.SetEnvironment("")
+		}
+	}()
+
+	var services []types.Service
+	for _, environment := range environments {
+		for _, signal := range environmentSignals {
+			signal.SetEnvironment(environment)
+		}
+		environmentServices, err := sd.generate(ctx, lastCriticalError)
+		if err != nil {
+			return nil, fmt.Errorf("environment %s: %w", environment, err)
+		}
+		for _, service := range environmentServices {
+			service.Environment = environment
+			services = append(services, service)
+		}
+	}
+	return services, nil
+}
+
+// observe walks the repo once, letting every signal observe every entry. Critical errors
+// seen while walking are recorded and only fail discovery if nothing is found.
+func (sd *ServiceDiscovery) observe(ctx context.Context, rootPath string, lastCriticalError *error) error {
 	// Get the base path for the filesystem
 	basePath := filesystems.GetBasePath(rootPath)
 
@@ -83,22 +192,37 @@ func (sd *ServiceDiscovery) Discover(ctx context.Context, rootPath string) ([]ty
 	for _, signal := range sd.signals {
 		signal.Reset()
 	}
-
-	var lastCriticalError error
+	sd.warningsMu.Lock()
+	sd.warnings = nil
+	sd.warningsMu.Unlock()
+	sd.reportProgress(func(p *Progress) { p.EntriesVisited = 0 })
 
 	// Walk the entire repo using a stack instead of recursion
-	err := sd.walkRepoIterative(ctx, filesystem, basePath, 4, &lastCriticalError)
-	if err != nil {
-		return nil, fmt.Errorf("filesystem walk failed: %w", err)
+	if err := sd.walkRepoIterative(ctx, sd.filesystem, basePath, 4, lastCriticalError); err != nil {
+		return fmt.Errorf("filesystem walk failed: %w", err)
 	}
+	return nil
+}
 
-	// NOW generate services from all signals with their full accumulated context
+// generate asks every signal for services with their full accumulated context, then
+// triangulates and refines them
+func (sd *ServiceDiscovery) generate(ctx context.Context, lastCriticalError error) ([]types.Service, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	resultsChan := make(chan signalResult, len(sd.signals))
 	var wg errgroup.Group
 
 	for _, signal := range sd.signals {
 		wg.Go(func() error {
+			sd.reportProgress(func(p *Progress) { p.Signal = signalName(signal) })
 			services, err := signal.GenerateServices(ctx)
+			if diagnostic, ok := signal.(DiagnosticSignal); ok {
+				for _, warning := range diagnostic.Warnings() {
+					warning.Signal = signalName(signal)
+					sd.warn(warning)
+				}
+			}
 			if err != nil {
 				if isCriticalError(err) {
 					return err
@@ -128,14 +252,28 @@ func (sd *ServiceDiscovery) Discover(ctx context.Context, rootPath string) ([]ty
 
 	// If we found no services but had critical errors, surface the error
 	if len(results) == 0 && lastCriticalError != nil {
-		return nil, fmt.Errorf("service discovery failed with authentication or permission error: %w", lastCriticalError)
+		return nil, fmt.Errorf("service discovery couldn't read the repository: %w", lastCriticalError)
+	}
+
+	// Signals that stopped early for a cancellation found only some services
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Merge services, weighing the evidence for values signals disagree on
+	services := triangulateServices(results, sd.evidence)
+
+	for _, signal := range sd.signals {
+		if refiner, ok := signal.(ServiceRefiner); ok {
+			sd.reportProgress(func(p *Progress) { p.Signal = signalName(signal) })
+			services = refiner.RefineServices(ctx, services)
+		}
 	}
 
-	// Merge services with confidence-based triangulation
-	return triangulateServices(results), nil
+	return services, nil
 }
 
-func triangulateServices(results []signalResult) []types.Service {
+func triangulateServices(results []signalResult, model EvidenceModel) []types.Service {
 	// Group services by build path first
 	buildPathGroups := make(map[string][]serviceWithSignal)
 
@@ -145,7 +283,8 @@ func triangulateServices(results []signalResult) []types.Service {
 			if service.BuildPath != "" {
 				buildPathGroups[service.BuildPath] = append(buildPathGroups[service.BuildPath], serviceWithSignal{
 					service:    service,
-					confidence: result.confidence,
+					confidence: serviceConfidence(result, service),
+					signal:     signalName(result.signal),
 				})
 			}
 		}
@@ -155,7 +294,7 @@ func triangulateServices(results []signalResult) []types.Service {
 
 	// Process each BuildPath group
 	for _, serviceList := range buildPathGroups {
-		merged := triangulateServiceGroup(serviceList)
+		merged := triangulateServiceGroup(serviceList, model)
 		mergedServices = append(mergedServices, merged...)
 	}
 
@@ -171,8 +310,15 @@ func triangulateServices(results []signalResult) []types.Service {
 	return mergedServices
 }
 
+func serviceConfidence(result signalResult, service types.Service) int {
+	if signal, ok := result.signal.(ServiceConfidenceSignal); ok {
+		return signal.ServiceConfidence(service)
+	}
+	return result.confidence
+}
+
 // triangulateServiceGroup processes services within a single BuildPath group
-func triangulateServiceGroup(serviceList []serviceWithSignal) []types.Service {
+func triangulateServiceGroup(serviceList []serviceWithSignal, model EvidenceModel) []types.Service {
 	// Find the highest confidence level
 	maxConfidence := 0
 	for _, sws := range serviceList {
@@ -197,15 +343,42 @@ func triangulateServiceGroup(serviceList []serviceWithSignal) []types.Service {
 
 	// If we have high-confidence explicit services, use those as base
 	if len(highConfidenceServices) > 0 {
-		return mergeExplicitServices(highConfidenceServices, lowConfidenceServices)
+		return applyPinnedFields(mergeExplicitServices(highConfidenceServices, lowConfidenceServices, model), serviceList)
 	}
 
 	// Otherwise, fall back to merging generic services
-	return mergeGenericServices(serviceList)
+	return applyPinnedFields(mergeGenericServices(serviceList, model), serviceList)
+}
+
+// applyPinnedFields copies fields the user set explicitly onto the merged services,
+// whichever signal won. Pins apply to the service of the same name, or to the only one.
+func applyPinnedFields(merged []types.Service, serviceList []serviceWithSignal) []types.Service {
+	for _, sws := range serviceList {
+		pinned := sws.service
+		for i := range merged {
+			if len(merged) > 1 && merged[i].Name != pinned.Name {
+				continue
+			}
+			for _, field := range pinned.Pinned {
+				switch field {
+				case "Name":
+					merged[i].Name = pinned.Name
+				case "Network":
+					merged[i].Network = pinned.Network
+				case "Runtime":
+					merged[i].Runtime = pinned.Runtime
+				case "Schedule":
+					merged[i].Schedule = pinned.Schedule
+				}
+				merged[i].Pin(field)
+			}
+		}
+	}
+	return merged
 }
 
 // mergeExplicitServices uses high-confidence services as base and merges configs from low-confidence ones
-func mergeExplicitServices(explicitServices []serviceWithSignal, genericServices []serviceWithSignal) []types.Service {
+func mergeExplicitServices(explicitServices []serviceWithSignal, genericServices []serviceWithSignal, model EvidenceModel) []types.Service {
 	// Collect all configs from generic services
 	var allGenericConfigs []types.ConfigRef
 	configSet := make(map[string]bool)
@@ -239,9 +412,24 @@ func mergeExplicitServices(explicitServices []serviceWithSignal, genericServices
 		// For the first explicit service, add all generic configs
 		// This represents that the generic detection found the same codebase
 		if i == 0 {
-			service.Configs = append(service.Configs, allGenericConfigs...)
+			for _, config := range allGenericConfigs {
+				if !slices.Contains(service.Configs, config) {
+					service.Configs = append(service.Configs, config)
+				}
+			}
 		}
 
+		// Generic signals still know things about the codebase the explicit config doesn't
+		// say, but only speak for the service they name when configs define several
+		evidence := []serviceWithSignal{sws}
+		for _, generic := range genericServices {
+			if speaksFor(generic.service, service, explicitByName) {
+				mergeServiceMetadata(&service, generic.service)
+				evidence = append(evidence, generic)
+			}
+		}
+		weighFields(&service, evidence, model)
+
 		result = append(result, service)
 		i++
 	}
@@ -249,8 +437,20 @@ func mergeExplicitServices(explicitServices []serviceWithSignal, genericServices
 	return result
 }
 
+// speaksFor reports whether a generic service's evidence is about an explicit service.
+// Generic signals only see the codebase, so when configs define several services
+// built from it, they speak for the one they name, or, naming none, for those
+// serving traffic, since what they detect is how the codebase listens.
+func speaksFor(generic, service types.Service, explicitByName map[string]serviceWithSignal) bool {
+	if len(explicitByName) == 1 || generic.Name == service.Name {
+		return true
+	}
+	_, named := explicitByName[generic.Name]
+	return !named && service.Network == types.NetworkPublic
+}
+
 // mergeGenericServices handles the case where we only have generic/low-confidence services
-func mergeGenericServices(serviceList []serviceWithSignal) []types.Service {
+func mergeGenericServices(serviceList []serviceWithSignal, model EvidenceModel) []types.Service {
 	// Group by service name to merge identical services
 	nameGroups := make(map[string][]serviceWithSignal)
 
@@ -281,6 +481,11 @@ func mergeGenericServices(serviceList []serviceWithSignal) []types.Service {
 			}
 		}
 
+		for _, sws := range serviceGroup {
+			mergeServiceMetadata(&bestService, sws.service)
+		}
+		weighFields(&bestService, serviceGroup, model)
+
 		bestService.Configs = allConfigs
 		result = append(result, bestService)
 	}
@@ -288,6 +493,97 @@ func mergeGenericServices(serviceList []serviceWithSignal) []types.Service {
 	return result
 }
 
+// mergeServiceMetadata fills metadata the base service left empty with values
+// another signal detected for the same codebase
+func mergeServiceMetadata(base *types.Service, other types.Service) {
+	if base.PackageManager == "" {
+		base.PackageManager = other.PackageManager
+	}
+	if base.Build == types.BuildStatic && base.OutputDir == "" {
+		base.OutputDir = other.OutputDir
+	}
+	if preferInferredField(*base, other, "Port", base.Port == 0, other.Port == 0) {
+		base.Port = other.Port
+		copyProvenance(base, other, "Port")
+	}
+	if preferInferredField(*base, other, "StartCommand", base.StartCommand == "", other.StartCommand == "") {
+		base.StartCommand = other.StartCommand
+		copyProvenance(base, other, "StartCommand")
+	}
+	if base.BaseImage == "" {
+		base.BaseImage = other.BaseImage
+	}
+	if base.Platform == "" && other.Platform != "" {
+		base.Platform = other.Platform
+		copyProvenance(base, other, "Platform")
+	}
+	if base.Runtime == types.RuntimeScheduled && base.Schedule == "" {
+		base.Schedule = other.Schedule
+	}
+	if base.PreDeployCommand == "" {
+		base.PreDeployCommand = other.PreDeployCommand
+	}
+	if len(base.Volumes) == 0 {
+		base.Volumes = other.Volumes
+	}
+	if len(base.PublishedPorts) == 0 {
+		base.PublishedPorts = other.PublishedPorts
+	}
+	if base.Replicas == 0 {
+		base.Replicas = other.Replicas
+	}
+	if base.Resources == nil {
+		base.Resources = other.Resources
+	}
+	if base.Region == "" {
+		base.Region = other.Region
+	}
+	if len(base.Networks) == 0 {
+		base.Networks = other.Networks
+	}
+	if base.Ingress == nil {
+		base.Ingress = other.Ingress
+	}
+	if base.BuildArgs == nil {
+		base.BuildArgs = other.BuildArgs
+	}
+	for _, missing := range other.Missing {
+		if !slices.Contains(base.Missing, missing) {
+			base.Missing = append(slices.Clip(base.Missing), missing)
+		}
+	}
+	if preferInferredField(*base, other, "HealthcheckPath", base.HealthcheckPath == "", other.HealthcheckPath == "") {
+		base.HealthcheckPath = other.HealthcheckPath
+		copyProvenance(base, other, "HealthcheckPath")
+	}
+}
+
+// preferInferredField reports whether other's value for a field should replace base's.
+// Values without provenance are explicit and always beat inferred ones.
+func preferInferredField(base, other types.Service, field string, baseEmpty, otherEmpty bool) bool {
+	if otherEmpty {
+		return false
+	}
+	if baseEmpty {
+		return true
+	}
+
+	baseProvenance, inferred := base.ProvenanceOf(field)
+	if !inferred {
+		return false
+	}
+	otherProvenance, inferred := other.ProvenanceOf(field)
+	return !inferred || otherProvenance.Confidence > baseProvenance.Confidence
+}
+
+func copyProvenance(base *types.Service, other types.Service, field string) {
+	if p, ok := other.ProvenanceOf(field); ok {
+		base.SetProvenance(field, p.Source, p.Confidence)
+	} else {
+		base.ClearProvenance(field)
+	}
+}
+
 var excludePatterns = []string{
 	// Dependencies
 	"node_modules", "vendor", "bower_components",
@@ -340,6 +636,10 @@ func (sd *ServiceDiscovery) walkRepoIterative(ctx context.Context, filesystem fi
 	stack := []walkItem{{path: rootPath, depth: 0}}
 
 	for len(stack) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		// Pop from stack
 		current := stack[len(stack)-1]
 		stack = stack[:len(stack)-1]
@@ -359,9 +659,12 @@ func (sd *ServiceDiscovery) walkRepoIterative(ctx context.Context, filesystem fi
 			if err != nil {
 				if isCriticalError(err) {
 					*lastCriticalError = err
+				} else {
+					sd.warn(types.Warning{File: current.path, Message: err.Error()})
 				}
 				continue
 			}
+			sd.reportProgress(func(p *Progress) { p.EntriesVisited++ })
 
 			// Let all signals observe this entry in parallel - they build up global repo state
 			var wg errgroup.Group
@@ -372,6 +675,7 @@ func (sd *ServiceDiscovery) walkRepoIterative(ctx context.Context, filesystem fi
 						if isCriticalError(err) {
 							return err
 						}
+						sd.warn(types.Warning{Signal: signalName(signal), File: filesystem.Join(current.path, entry.Name()), Message: err.Error()})
 					}
 					return nil
 				})
@@ -391,35 +695,32 @@ func (sd *ServiceDiscovery) walkRepoIterative(ctx context.Context, filesystem fi
 	return nil
 }
 
-// isCriticalError determines if an error is critical (auth/permission) vs expected (not found)
-func isCriticalError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errMsg := strings.ToLower(err.Error())
-
-	// GitHub API authentication errors
-	if strings.Contains(errMsg, "401 unauthorized") ||
-		strings.Contains(errMsg, "403 forbidden") ||
-		strings.Contains(errMsg, "bad credentials") ||
-		strings.Contains(errMsg, "token") ||
-		strings.Contains(errMsg, "authentication") {
-		return true
-	}
-
-	// Rate limiting
-	if strings.Contains(errMsg, "rate limit") ||
-		strings.Contains(errMsg, "api rate limit exceeded") {
-		return true
-	}
+// Warnings lists what the last discovery skipped, like configs that won't parse, so
+// its services can be trusted to be complete only when there are none
+func (sd *ServiceDiscovery) Warnings() []types.Warning {
+	sd.warningsMu.Lock()
+	defer sd.warningsMu.Unlock()
+	warnings := slices.Clone(sd.warnings)
+	slices.SortFunc(warnings, func(a, b types.Warning) int {
+		return cmp.Or(strings.Compare(a.File, b.File), strings.Compare(a.Signal, b.Signal), strings.Compare(a.Message, b.Message))
+	})
+	return warnings
+}
 
-	// Network/connection issues
-	if strings.Contains(errMsg, "connection refused") ||
-		strings.Contains(errMsg, "timeout") ||
-		strings.Contains(errMsg, "network") {
-		return true
+// warn records a warning, once however many environments it's found in
+func (sd *ServiceDiscovery) warn(warning types.Warning) {
+	sd.warningsMu.Lock()
+	defer sd.warningsMu.Unlock()
+	if !slices.Contains(sd.warnings, warning) {
+		sd.warnings = append(sd.warnings, warning)
 	}
+}
 
-	return false
+// isCriticalError reports whether an error means the repository can't be read, like
+// failed authentication, rate limits and network failures, rather than a file that's
+// missing or won't parse
+func isCriticalError(err error) bool {
+	return errors.Is(err, filesystems.ErrAuth) ||
+		errors.Is(err, filesystems.ErrRateLimited) ||
+		errors.Is(err, filesystems.ErrNetwork)
 }
@@ -0,0 +1,182 @@
+package signals
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"gopkg.in/yaml.v3"
+)
+
+// Confidence for healthchecks read from the health endpoints an OpenAPI spec declares,
+// above framework defaults but below any config that sets one
+const apiSpecConfidence = 50
+
+// healthPaths are the endpoints an OpenAPI spec may declare for health checks, most
+// specific first
+var healthPaths = []string{"/health", "/healthz", "/healthcheck", "/health/live", "/livez", "/ready", "/readyz", "/ping", "/status"}
+
+// graphQLRootTypePattern matches the root types only a GraphQL schema defines, rather
+// than the queries clients keep in .graphql files
+var graphQLRootTypePattern = regexp.MustCompile(`(?m)^\s*(?:schema\s*\{|(?:extend\s+)?type\s+(?:Query|Mutation|Subscription)\b)`)
+
+// APISignal finds OpenAPI, Swagger and GraphQL specs and attaches them to the services
+// whose build paths they're in, with a healthcheck from the spec's health endpoint.
+// It doesn't generate services itself, it refines the ones other signals found.
+type APISignal struct {
+	filesystem filesystems.FileSystem
+	specs      []apiSpec
+}
+
+type apiSpec struct {
+	api         types.API
+	dir         string
+	healthcheck string // the spec's health endpoint, empty if it has none
+}
+
+func NewAPISignal(filesystem filesystems.FileSystem) *APISignal {
+	return &APISignal{filesystem: filesystem}
+}
+
+func (a *APISignal) Confidence() int {
+	return apiSpecConfidence
+}
+
+func (a *APISignal) Reset() {
+	a.specs = nil
+}
+
+func (a *APISignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
+	if entry.IsDir() {
+		return nil
+	}
+
+	name := strings.ToLower(entry.Name())
+	fullPath := a.filesystem.Join(rootPath, entry.Name())
+	switch ext := path.Ext(name); {
+	case (ext == ".yaml" || ext == ".yml" || ext == ".json") && isOpenAPIName(strings.TrimSuffix(name, ext)):
+		if spec, ok := a.parseOpenAPI(fullPath); ok {
+			spec.dir = rootPath
+			a.specs = append(a.specs, spec)
+		}
+	case ext == ".graphql" || ext == ".graphqls" || ext == ".gql":
+		content, err := filesystems.ReadTextFile(a.filesystem, fullPath)
+		if err == nil && graphQLRootTypePattern.Match(content) {
+			a.specs = append(a.specs, apiSpec{api: types.API{Type: "graphql", SpecPath: fullPath}, dir: rootPath})
+		}
+	}
+	return nil
+}
+
+// isOpenAPIName reports whether a spec file's name, without its extension, is one
+// OpenAPI and Swagger specs conventionally have, like openapi or api.swagger
+func isOpenAPIName(name string) bool {
+	return name == "openapi" || name == "swagger" || strings.HasSuffix(name, ".openapi") || strings.HasSuffix(name, ".swagger")
+}
+
+// parseOpenAPI reads an OpenAPI or Swagger spec, which YAML parses in either format
+func (a *APISignal) parseOpenAPI(specPath string) (apiSpec, bool) {
+	content, err := filesystems.ReadTextFile(a.filesystem, specPath)
+	if err != nil {
+		return apiSpec{}, false
+	}
+	var document struct {
+		OpenAPI  string `yaml:"openapi"`
+		Swagger  string `yaml:"swagger"`
+		BasePath string `yaml:"basePath"` // Swagger's prefix for every path
+		Servers  []struct {
+			URL string `yaml:"url"`
+		} `yaml:"servers"`
+		Paths map[string]any `yaml:"paths"`
+	}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return apiSpec{}, false
+	}
+
+	spec := apiSpec{api: types.API{SpecPath: specPath}}
+	basePath := document.BasePath
+	switch {
+	case document.OpenAPI != "":
+		spec.api.Type, spec.api.Version = "openapi", document.OpenAPI
+		if len(document.Servers) > 0 {
+			if server, err := url.Parse(document.Servers[0].URL); err == nil {
+				basePath = server.Path
+			}
+		}
+	case document.Swagger != "":
+		spec.api.Type, spec.api.Version = "swagger", document.Swagger
+	default:
+		return apiSpec{}, false
+	}
+
+	for _, healthPath := range healthPaths {
+		if _, ok := document.Paths[healthPath]; ok {
+			spec.healthcheck = path.Join("/", basePath, healthPath)
+			break
+		}
+	}
+	return spec, true
+}
+
+func (a *APISignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	return nil, nil
+}
+
+// RefineServices attaches each spec to the services built from the closest directory
+// above it. Services with more than one spec get the REST one closest to their build
+// path, since that's the one a healthcheck can come from.
+func (a *APISignal) RefineServices(ctx context.Context, services []types.Service) []types.Service {
+	specs := slices.Clone(a.specs)
+	slices.SortStableFunc(specs, func(x, y apiSpec) int {
+		if (x.api.Type == "graphql") != (y.api.Type == "graphql") {
+			if x.api.Type == "graphql" {
+				return 1
+			}
+			return -1
+		}
+		return strings.Count(x.dir, "/") - strings.Count(y.dir, "/")
+	})
+
+	for _, spec := range specs {
+		for i := range services {
+			service := &services[i]
+			if service.API != nil || service.BuildPath == "" || a.owner(services, spec.dir) != service.BuildPath {
+				continue
+			}
+			api := spec.api
+			service.API = &api
+
+			// A spec's health endpoint beats a framework's conventional path
+			if spec.healthcheck != "" && service.Network != types.NetworkNone && !service.IsPinned("HealthcheckPath") {
+				provenance, inferred := service.ProvenanceOf("HealthcheckPath")
+				if service.HealthcheckPath == "" || inferred && provenance.Confidence < apiSpecConfidence {
+					service.HealthcheckPath = spec.healthcheck
+					service.SetProvenance("HealthcheckPath", "openapi:"+spec.api.SpecPath, apiSpecConfidence)
+				}
+			}
+		}
+	}
+	return services
+}
+
+// owner finds the build path closest above a directory, empty if no service is built
+// from one
+func (a *APISignal) owner(services []types.Service, dir string) string {
+	for {
+		for _, service := range services {
+			if service.BuildPath != "" && service.BuildPath == dir {
+				return dir
+			}
+		}
+		parent := a.filesystem.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
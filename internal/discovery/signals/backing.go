@@ -0,0 +1,102 @@
+package signals
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+)
+
+// backingService describes infrastructure an application depends on but doesn't build
+type backingService struct {
+	Image    string
+	Port     int
+	Families []string // image families that already provide it
+}
+
+// Backing services we can imply from indirect evidence, keyed by kind
+var backingServices = map[string]backingService{
+	"postgres":      {"postgres:16", 5432, []string{"postgres", "postgresql", "postgis", "timescaledb", "pgvector", "postgres-ssl"}},
+	"mysql":         {"mysql:8", 3306, []string{"mysql", "mariadb", "percona", "percona-server"}},
+	"redis":         {"redis:7", 6379, []string{"redis", "redis-stack", "redis-stack-server", "valkey", "keydb", "dragonfly"}},
+	"rabbitmq":      {"rabbitmq:3-management", 5672, []string{"rabbitmq"}},
+	"kafka":         {"apache/kafka:3.7.0", 9092, []string{"kafka", "cp-kafka", "redpanda"}},
+	"elasticsearch": {"elasticsearch:8.13.0", 9200, []string{"elasticsearch", "opensearch"}},
+	"mongodb":       {"mongo:7", 27017, []string{"mongo", "mongodb", "mongodb-community-server"}},
+	"memcached":     {"memcached:1.6", 11211, []string{"memcached"}},
+}
+
+// backingServiceKind maps a package or chart name like "postgresql" or "redis-cluster"
+// to the backing service it runs
+func backingServiceKind(name string) (string, bool) {
+	name = strings.ToLower(name)
+	for _, suffix := range []string{"-ha", "-cluster", "-sharded"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	for kind, backing := range backingServices {
+		if kind == name || slices.Contains(backing.Families, name) {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// providesBackingService reports whether a discovered service already runs the given kind
+func providesBackingService(services []types.Service, kind string) bool {
+	for _, service := range services {
+		for _, family := range backingServices[kind].Families {
+			if imageFamily(service.Image) == family || service.BaseImage == family || strings.EqualFold(service.Name, family) {
+				return true
+			}
+		}
+		if strings.EqualFold(service.Name, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// impliedBackingServices collects derived services for evidence of kinds no discovered
+// service provides, returning them in the order the evidence was found
+type impliedBackingServices struct {
+	byKind map[string]*types.Service
+	order  []string
+}
+
+func (i *impliedBackingServices) add(services []types.Service, kind string, evidence types.ConfigRef) {
+	backing, ok := backingServices[kind]
+	if !ok || providesBackingService(services, kind) {
+		return
+	}
+	if i.byKind == nil {
+		i.byKind = make(map[string]*types.Service)
+	}
+
+	service, ok := i.byKind[kind]
+	if !ok {
+		service = &types.Service{
+			Name:    kind,
+			Network: types.NetworkPrivate,
+			Runtime: types.RuntimeContinuous,
+			Build:   types.BuildFromImage,
+			Image:   backing.Image,
+			Port:    backing.Port,
+			Derived: true,
+		}
+		i.byKind[kind] = service
+		i.order = append(i.order, kind)
+	}
+	for _, config := range service.Configs {
+		if config == evidence {
+			return
+		}
+	}
+	service.Configs = append(service.Configs, evidence)
+}
+
+func (i *impliedBackingServices) appendTo(services []types.Service) []types.Service {
+	for _, kind := range i.order {
+		services = append(services, *i.byKind[kind])
+	}
+	return services
+}
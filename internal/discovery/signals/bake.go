@@ -0,0 +1,268 @@
+package signals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// bakeFiles are the files `docker buildx bake` reads by default, in the order it layers them
+var bakeFiles = []string{"docker-bake.json", "docker-bake.hcl", "docker-bake.override.json", "docker-bake.override.hcl"}
+
+// BakeSignal finds docker-bake.hcl and docker-bake.json files and emits a service for
+// each target they build, from its context and Dockerfile, with its build args
+type BakeSignal struct {
+	filesystem filesystems.FileSystem
+	dirs       []string            // directories with bake files, in walk order
+	files      map[string][]string // directory -> its bake files
+
+	skipped
+}
+
+func NewBakeSignal(filesystem filesystems.FileSystem) *BakeSignal {
+	return &BakeSignal{filesystem: filesystem}
+}
+
+func (b *BakeSignal) Confidence() int {
+	return 80 // High confidence - explicit about what's built, but not how it runs
+}
+
+func (b *BakeSignal) Reset() {
+	b.skipped = skipped{}
+	b.dirs = nil
+	b.files = make(map[string][]string)
+}
+
+func (b *BakeSignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
+	if entry.IsDir() || !slices.Contains(bakeFiles, strings.ToLower(entry.Name())) {
+		return nil
+	}
+	if _, ok := b.files[rootPath]; !ok {
+		b.dirs = append(b.dirs, rootPath)
+	}
+	b.files[rootPath] = append(b.files[rootPath], b.filesystem.Join(rootPath, entry.Name()))
+	return nil
+}
+
+// bakeConfig is a bake file in its JSON form, which HCL files are read into too
+type bakeConfig struct {
+	Targets   map[string]bakeTarget `json:"target"`
+	Groups    map[string]bakeGroup  `json:"group"`
+	Variables map[string]struct {
+		Default any `json:"default"`
+	} `json:"variable"`
+}
+
+type bakeTarget struct {
+	Context    string         `json:"context"`
+	Dockerfile string         `json:"dockerfile"`
+	Args       map[string]any `json:"args"`
+	Inherits   []string       `json:"inherits"`
+}
+
+type bakeGroup struct {
+	Targets []string `json:"targets"`
+}
+
+func (b *BakeSignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	var services []types.Service
+	for _, dir := range b.dirs {
+		files := b.files[dir]
+		slices.SortFunc(files, func(x, y string) int {
+			return slices.Index(bakeFiles, strings.ToLower(b.filesystem.Base(x))) - slices.Index(bakeFiles, strings.ToLower(b.filesystem.Base(y)))
+		})
+
+		config := bakeConfig{Targets: make(map[string]bakeTarget), Groups: make(map[string]bakeGroup)}
+		var parsed []string
+		for _, path := range files {
+			layer, err := b.parseBakeFile(path)
+			if err != nil {
+				b.skip(path, err)
+				continue
+			}
+			config.layer(layer)
+			parsed = append(parsed, path)
+		}
+		if len(parsed) == 0 {
+			continue
+		}
+		configPath := parsed[0]
+
+		variables := make(map[string]string)
+		for name, variable := range config.Variables {
+			if variable.Default != nil {
+				variables[name] = fmt.Sprint(variable.Default)
+			}
+		}
+
+		for _, name := range config.buildTargets() {
+			target := config.resolve(name, nil)
+			buildContext := interpolateBake(target.Context, variables)
+			if strings.Contains(buildContext, "://") || strings.HasPrefix(buildContext, "target:") || strings.HasPrefix(buildContext, "git@") {
+				continue // Remote contexts, or another target's output
+			}
+			buildPath := b.filesystem.Join(dir, buildContext)
+			dockerfile := interpolateBake(target.Dockerfile, variables)
+			if dockerfile == "" {
+				dockerfile = "Dockerfile"
+			}
+
+			service := types.Service{
+				Name:      name,
+				Network:   types.NetworkPrivate, // Conservative default, bake doesn't say how it runs
+				Runtime:   types.RuntimeContinuous,
+				Build:     types.BuildFromSource,
+				BuildPath: buildPath,
+				Configs:   []types.ConfigRef{{Type: "bake", Path: configPath}},
+			}
+			if dockerfilePath := b.filesystem.Join(buildPath, dockerfile); b.filesystem.Exists(dockerfilePath) {
+				service.Configs = append(service.Configs, types.ConfigRef{Type: "dockerfile", Path: dockerfilePath})
+			}
+			for arg, value := range target.Args {
+				if value == nil {
+					continue // Taken from the environment bake runs in
+				}
+				if service.BuildArgs == nil {
+					service.BuildArgs = make(map[string]string)
+				}
+				service.BuildArgs[arg] = interpolateBake(fmt.Sprint(value), variables)
+			}
+			services = append(services, service)
+		}
+	}
+	return services, nil
+}
+
+func (b *BakeSignal) parseBakeFile(path string) (*bakeConfig, error) {
+	content, err := filesystems.ReadTextFile(b.filesystem, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(strings.ToLower(path), ".hcl") {
+		body, err := parseBakeHCL(content)
+		if err != nil {
+			return nil, err
+		}
+		if content, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+	var config bakeConfig
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// layer applies a later bake file over the config, as bake does: targets merge
+// attribute by attribute, while groups and variables are replaced
+func (c *bakeConfig) layer(other *bakeConfig) {
+	for name, target := range other.Targets {
+		c.Targets[name] = c.Targets[name].overlay(target)
+	}
+	maps.Copy(c.Groups, other.Groups)
+	if c.Variables == nil {
+		c.Variables = other.Variables
+	} else {
+		maps.Copy(c.Variables, other.Variables)
+	}
+}
+
+// overlay sets the attributes another definition of a target sets
+func (t bakeTarget) overlay(other bakeTarget) bakeTarget {
+	if other.Context != "" {
+		t.Context = other.Context
+	}
+	if other.Dockerfile != "" {
+		t.Dockerfile = other.Dockerfile
+	}
+	if len(other.Inherits) > 0 {
+		t.Inherits = other.Inherits
+	}
+	if len(other.Args) > 0 {
+		args := maps.Clone(t.Args)
+		if args == nil {
+			args = make(map[string]any)
+		}
+		maps.Copy(args, other.Args)
+		t.Args = args
+	}
+	return t
+}
+
+// resolve merges a target over the targets it inherits from, in order
+func (c *bakeConfig) resolve(name string, seen []string) bakeTarget {
+	if slices.Contains(seen, name) {
+		return bakeTarget{} // An inheritance cycle
+	}
+	target := c.Targets[name]
+	var resolved bakeTarget
+	for _, parent := range target.Inherits {
+		resolved = resolved.overlay(c.resolve(parent, append(seen, name)))
+	}
+	resolved = resolved.overlay(target)
+	resolved.Inherits = nil
+	return resolved
+}
+
+// buildTargets lists the targets `docker buildx bake` builds: the default group's, or
+// without one every target no other target inherits from, since those are usually
+// bases like _common or docker-metadata-action
+func (c *bakeConfig) buildTargets() []string {
+	if group, ok := c.Groups["default"]; ok {
+		var targets []string
+		c.expandGroup(group, &targets, nil)
+		return targets
+	}
+
+	inherited := make(map[string]bool)
+	for _, target := range c.Targets {
+		for _, parent := range target.Inherits {
+			inherited[parent] = true
+		}
+	}
+	var targets []string
+	for _, name := range slices.Sorted(maps.Keys(c.Targets)) {
+		if !inherited[name] && !strings.HasPrefix(name, "_") {
+			targets = append(targets, name)
+		}
+	}
+	return targets
+}
+
+// expandGroup lists a group's targets, expanding the groups it names
+func (c *bakeConfig) expandGroup(group bakeGroup, targets *[]string, seen []string) {
+	for _, name := range group.Targets {
+		if nested, ok := c.Groups[name]; ok {
+			if !slices.Contains(seen, name) {
+				c.expandGroup(nested, targets, append(seen, name))
+			}
+			continue
+		}
+		if _, ok := c.Targets[name]; ok && !slices.Contains(*targets, name) {
+			*targets = append(*targets, name)
+		}
+	}
+}
+
+var bakeInterpolationPattern = regexp.MustCompile(`\$\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}`)
+
+// interpolateBake substitutes ${NAME} with variables' defaults, leaving anything it
+// can't resolve as written
+func interpolateBake(value string, variables map[string]string) string {
+	return bakeInterpolationPattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := bakeInterpolationPattern.FindStringSubmatch(match)[1]
+		if resolved, ok := variables[name]; ok {
+			return resolved
+		}
+		return match
+	})
+}
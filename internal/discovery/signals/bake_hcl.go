@@ -0,0 +1,312 @@
+package signals
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parseBakeHCL reads the subset of HCL bake files are written in into the shape of
+// their JSON form, like {"target": {"api": {"context": "./api"}}}, so both decode the
+// same way. Expressions beyond literals, lists and objects, like function calls and
+// conditionals, are left out.
+func parseBakeHCL(content []byte) (map[string]any, error) {
+	p := &hclParser{tokens: lexHCL(string(content))}
+	body, err := p.body(false)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+type hclToken struct {
+	kind byte // 'i' identifier, 's' string, 'n' number, '\n' newline, or the punctuation itself
+	text string
+	line int
+}
+
+func lexHCL(src string) []hclToken {
+	var tokens []hclToken
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			tokens = append(tokens, hclToken{kind: '\n', line: line})
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#' || strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src) - i - 2
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"':
+			text, n := lexHCLString(src[i:])
+			tokens = append(tokens, hclToken{kind: 's', text: text, line: line})
+			i += n
+		case strings.HasPrefix(src[i:], "<<"):
+			text, n := lexHCLHeredoc(src[i:])
+			tokens = append(tokens, hclToken{kind: 's', text: text, line: line})
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+		case unicode.IsLetter(rune(c)) || c == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || src[j] == '_' || src[j] == '-') {
+				j++
+			}
+			tokens = append(tokens, hclToken{kind: 'i', text: src[i:j], line: line})
+			i = j
+		case unicode.IsDigit(rune(c)):
+			j := i
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, hclToken{kind: 'n', text: src[i:j], line: line})
+			i = j
+		default:
+			tokens = append(tokens, hclToken{kind: c, text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens
+}
+
+// lexHCLString reads a quoted string, keeping ${...} interpolations as written
+func lexHCLString(src string) (string, int) {
+	var text strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '"':
+			return text.String(), i + 1
+		case c == '\n':
+			return text.String(), i // Never closed
+		case c == '\\' && i+1 < len(src):
+			i++
+			switch src[i] {
+			case 'n':
+				text.WriteByte('\n')
+			case 't':
+				text.WriteByte('\t')
+			default:
+				text.WriteByte(src[i])
+			}
+		case c == '$' && strings.HasPrefix(src[i:], "${"):
+			// Interpolations may hold quotes of their own, like ${lower("A")}
+			depth := 0
+			for ; i < len(src); i++ {
+				text.WriteByte(src[i])
+				if src[i] == '{' {
+					depth++
+				} else if src[i] == '}' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+		default:
+			text.WriteByte(c)
+		}
+	}
+	return text.String(), len(src)
+}
+
+// lexHCLHeredoc reads a <<EOT or indented <<-EOT heredoc
+func lexHCLHeredoc(src string) (string, int) {
+	header, rest, _ := strings.Cut(src, "\n")
+	indented := strings.HasPrefix(header, "<<-")
+	marker := strings.TrimSpace(strings.TrimLeft(header, "<-"))
+	var lines []string
+	n := len(header) + 1
+	for rest != "" {
+		var line string
+		line, rest, _ = strings.Cut(rest, "\n")
+		n += len(line) + 1
+		if strings.TrimSpace(line) == marker {
+			break
+		}
+		if indented {
+			line = strings.TrimLeft(line, " \t")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), min(n, len(src))
+}
+
+type hclParser struct {
+	tokens []hclToken
+	pos    int
+}
+
+func (p *hclParser) peek() hclToken {
+	if p.pos >= len(p.tokens) {
+		return hclToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *hclParser) next() hclToken {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *hclParser) skipNewlines() {
+	for p.peek().kind == '\n' {
+		p.pos++
+	}
+}
+
+// body reads attributes and blocks up to a closing brace, or the end of the file at the
+// top level. Blocks nest by type and then label, as bake's JSON does.
+func (p *hclParser) body(nested bool) (map[string]any, error) {
+	body := make(map[string]any)
+	for {
+		p.skipNewlines()
+		token := p.next()
+		switch {
+		case token.kind == 0 && !nested:
+			return body, nil
+		case token.kind == '}' && nested:
+			return body, nil
+		case token.kind != 'i':
+			return nil, fmt.Errorf("line %d: unexpected %q", token.line, token.text)
+		}
+
+		if p.peek().kind == '=' {
+			p.next()
+			body[token.text] = p.expression()
+			continue
+		}
+
+		var labels []string
+		for p.peek().kind == 's' || p.peek().kind == 'i' {
+			labels = append(labels, p.next().text)
+		}
+		if open := p.next(); open.kind != '{' {
+			return nil, fmt.Errorf("line %d: expected a block after %s", open.line, token.text)
+		}
+		block, err := p.body(true)
+		if err != nil {
+			return nil, err
+		}
+		parent := body
+		key := token.text
+		for _, label := range labels {
+			child, ok := parent[key].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				parent[key] = child
+			}
+			parent, key = child, label
+		}
+		parent[key] = block
+	}
+}
+
+// expression reads a value, or skips an expression it can't evaluate and returns nil
+func (p *hclParser) expression() any {
+	token := p.peek()
+	var value any
+	switch token.kind {
+	case 's':
+		p.next()
+		value = token.text
+	case 'n':
+		p.next()
+		value, _ = strconv.ParseFloat(token.text, 64)
+	case 'i':
+		p.next()
+		switch token.text {
+		case "true", "false":
+			value = token.text == "true"
+		case "null":
+			value = nil
+		default:
+			p.skipExpression()
+			return nil
+		}
+	case '[':
+		p.next()
+		var list []any
+		for {
+			p.skipNewlines()
+			if p.peek().kind == ']' || p.peek().kind == 0 {
+				p.next()
+				break
+			}
+			start := p.pos
+			list = append(list, p.expression())
+			if p.pos == start {
+				p.next() // A stray token, like a closing parenthesis
+			}
+			p.skipNewlines()
+			if p.peek().kind == ',' {
+				p.next()
+			}
+		}
+		value = list
+	case '{':
+		p.next()
+		object := make(map[string]any)
+		for {
+			p.skipNewlines()
+			key := p.next()
+			if key.kind == '}' || key.kind == 0 {
+				break
+			}
+			if separator := p.peek().kind; separator == '=' || separator == ':' {
+				p.next()
+			}
+			object[key.text] = p.expression()
+			p.skipNewlines()
+			if p.peek().kind == ',' {
+				p.next()
+			}
+		}
+		value = object
+	default:
+		p.skipExpression()
+		return nil
+	}
+
+	// Operators and conditionals after a literal make an expression this can't evaluate
+	switch p.peek().kind {
+	case '\n', ',', ']', '}', 0:
+		return value
+	}
+	p.skipExpression()
+	return nil
+}
+
+// skipExpression skips to the end of an expression: the end of its line, or a comma or
+// closing bracket outside any brackets it opened
+func (p *hclParser) skipExpression() {
+	depth := 0
+	for {
+		switch p.peek().kind {
+		case 0:
+			return
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return
+			}
+			depth--
+		case '\n', ',':
+			if depth == 0 {
+				return
+			}
+		}
+		p.next()
+	}
+}
@@ -0,0 +1,70 @@
+package signals
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+var (
+	celeryDependencyPattern = pythonDependencyPattern("celery")
+	celeryBeatPattern       = regexp.MustCompile(`(?i)django-celery-beat|beat_schedule`)
+)
+
+// celeryApp finds the Celery app a Django project defines in one of its packages, like
+// proj/celery.py, when the project depends on celery. It also reports whether the
+// project schedules tasks for beat to send.
+func celeryApp(filesystem filesystems.FileSystem, dir string) (app, path string, beat bool) {
+	dependencies, _ := pythonDependencies(filesystem, dir)
+	if !celeryDependencyPattern.MatchString(dependencies) {
+		return "", "", false
+	}
+
+	for entry, err := range filesystem.ReadDir(dir) {
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		path := filesystem.Join(dir, entry.Name(), "celery.py")
+		if !filesystem.Exists(path) {
+			continue
+		}
+		beat = celeryBeatPattern.MatchString(dependencies)
+		for _, name := range []string{"celery.py", "settings.py"} {
+			if data, err := filesystem.ReadFile(filesystem.Join(dir, entry.Name(), name)); err == nil && celeryBeatPattern.Match(data) {
+				beat = true
+			}
+		}
+		return entry.Name(), path, beat
+	}
+	return "", "", false
+}
+
+// celeryServices splits a Celery app's worker, and beat when tasks are scheduled, from
+// the Django web service, since each runs as its own process
+func celeryServices(web types.Service, app, path string, beat bool) []types.Service {
+	processes := []string{"worker"}
+	if beat {
+		processes = append(processes, "beat")
+	}
+
+	var services []types.Service
+	for _, process := range processes {
+		runtime := types.RuntimeContinuous
+		if process == "beat" {
+			runtime = types.RuntimeScheduled // It only wakes to send tasks on their schedule
+		}
+		service := types.Service{
+			Name:         web.Name + "-" + process,
+			Network:      types.NetworkNone,
+			Runtime:      runtime,
+			Build:        web.Build,
+			BuildPath:    web.BuildPath,
+			StartCommand: "celery -A " + app + " " + process + " --loglevel=info",
+			Configs:      append(slices.Clip(web.Configs), types.ConfigRef{Type: "framework", Path: path}),
+		}
+		services = append(services, service)
+	}
+	return services
+}
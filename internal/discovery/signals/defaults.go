@@ -0,0 +1,133 @@
+package signals
+
+import "github.com/railwayapp/turnout/internal/discovery/types"
+
+// Confidence for values we only know from framework conventions
+const frameworkDefaultConfidence = 30
+
+type frameworkDefault struct {
+	Port            int
+	HealthcheckPath string
+}
+
+// Conventional listen ports and health endpoints for detected frameworks
+var frameworkDefaults = map[string]frameworkDefault{
+	// JavaScript/TypeScript
+	"Next.js":               {3000, "/"},
+	"Nuxt.js":               {3000, "/"},
+	"Remix":                 {3000, "/"},
+	"SvelteKit":             {3000, "/"},
+	"Astro":                 {4321, "/"},
+	"SolidStart":            {3000, "/"},
+	"TanStack Start":        {3000, "/"},
+	"Qwik":                  {3000, "/"},
+	"React Router":          {3000, "/"},
+	"Express.js":            {3000, "/"},
+	"Fastify":               {3000, "/"},
+	"Hono":                  {3000, "/"},
+	"Elysia":                {3000, "/"},
+	"Koa.js":                {3000, "/"},
+	"Hapi.js":               {3000, "/"},
+	"NestJS":                {3000, "/"},
+	"Sails":                 {1337, "/"},
+	"Meteor":                {3000, "/"},
+	"Apollo GraphQL":        {4000, "/.well-known/apollo/server-health"},
+	"Apollo GraphQL Server": {4000, "/"},
+	"Strapi CMS":            {1337, "/_health"},
+	"Keystone.js":           {3000, "/"},
+
+	// Python
+	"Django":             {8000, "/"},
+	"Flask":              {5000, "/"},
+	"FastAPI":            {8000, "/docs"},
+	"Starlette":          {8000, "/"},
+	"Quart":              {5000, "/"},
+	"Sanic":              {8000, "/"},
+	"Tornado":            {8888, "/"},
+	"Pyramid":            {6543, "/"},
+	"Bottle":             {8080, "/"},
+	"CherryPy":           {8080, "/"},
+	"Streamlit":          {8501, "/_stcore/health"},
+	"Dash":               {8050, "/"},
+	"Gradio":             {7860, "/"},
+	"Python Web Service": {8000, "/"},
+
+	// Ruby
+	"Rails":         {3000, "/up"},
+	"Ruby on Rails": {3000, "/up"},
+	"Sinatra":       {4567, "/"},
+	"Hanami":        {2300, "/"},
+	"Roda":          {9292, "/"},
+	"Grape API":     {9292, "/"},
+	"Rack":          {9292, "/"},
+
+	// PHP
+	"Laravel":     {8000, "/up"},
+	"Symfony":     {8000, "/"},
+	"PHP Service": {8000, "/"},
+
+	// JVM
+	"Spring Boot":      {8080, "/actuator/health"},
+	"Spring Framework": {8080, "/"},
+	"Quarkus":          {8080, "/q/health"},
+	"Micronaut":        {8080, "/health"},
+	"Vert.x":           {8080, "/"},
+	"Dropwizard":       {8080, "/"},
+	"Ktor":             {8080, "/"},
+	"Java Service":     {8080, "/"},
+	"Play Framework":   {9000, "/"},
+	"Akka HTTP":        {8080, "/"},
+	"Luminus":          {3000, "/"},
+	"Compojure":        {3000, "/"},
+
+	// Go
+	"Gin":         {8080, "/"},
+	"Echo":        {8080, "/"},
+	"Fiber":       {3000, "/"},
+	"Chi":         {8080, "/"},
+	"Gorilla Mux": {8080, "/"},
+	"Beego":       {8080, "/"},
+	"Iris":        {8080, "/"},
+	"Revel":       {9000, "/"},
+
+	// Rust
+	"Actix Web": {8080, "/"},
+	"Axum":      {3000, "/"},
+	"Rocket":    {8000, "/"},
+	"Warp":      {3030, "/"},
+
+	// Elixir
+	"Phoenix": {4000, "/"},
+
+	// .NET
+	"ASP.NET Core": {8080, "/"},
+	".NET Web App": {8080, "/"},
+	"Blazor":       {8080, "/"},
+
+	// Swift
+	"Vapor": {8080, "/"},
+}
+
+// applyFrameworkDefaults fills in the conventional port and healthcheck for a framework.
+// Values are tagged with low-confidence provenance so explicit configs can take precedence.
+func applyFrameworkDefaults(service *types.Service, framework string) {
+	// Static sites are served by a file server, and workers don't listen at all
+	if service.Build == types.BuildStatic || service.Network == types.NetworkNone {
+		return
+	}
+
+	defaults, ok := frameworkDefaults[framework]
+	if !ok {
+		return
+	}
+
+	source := "framework-default:" + framework
+	if service.Port == 0 && defaults.Port != 0 {
+		service.Port = defaults.Port
+		service.SetProvenance("Port", source, frameworkDefaultConfidence)
+	}
+	if service.HealthcheckPath == "" && defaults.HealthcheckPath != "" && service.Network == types.NetworkPublic {
+		service.HealthcheckPath = defaults.HealthcheckPath
+		service.SetProvenance("HealthcheckPath", source, frameworkDefaultConfidence)
+	}
+}
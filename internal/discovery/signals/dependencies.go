@@ -0,0 +1,272 @@
+package signals
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/environment"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// Client libraries that imply a backing service, keyed by package name
+var clientLibraries = map[string]string{
+	// Redis
+	"redis": "redis", "ioredis": "redis", "bullmq": "redis", "bull": "redis", "bee-queue": "redis",
+	"rq": "redis", "celery[redis]": "redis", "django-redis": "redis", "sidekiq": "redis",
+	"predis/predis": "redis", "github.com/redis/go-redis": "redis", "github.com/go-redis/redis": "redis",
+	"github.com/gomodule/redigo": "redis", "github.com/hibiken/asynq": "redis",
+
+	// RabbitMQ
+	"amqplib": "rabbitmq", "amqp-connection-manager": "rabbitmq", "pika": "rabbitmq", "aio-pika": "rabbitmq",
+	"bunny": "rabbitmq", "php-amqplib/php-amqplib": "rabbitmq", "lapin": "rabbitmq",
+	"github.com/rabbitmq/amqp091-go": "rabbitmq", "github.com/streadway/amqp": "rabbitmq",
+
+	// Kafka
+	"kafkajs": "kafka", "node-rdkafka": "kafka", "kafka-python": "kafka", "confluent-kafka": "kafka",
+	"aiokafka": "kafka", "ruby-kafka": "kafka", "rdkafka": "kafka", "karafka": "kafka",
+	"github.com/segmentio/kafka-go": "kafka", "github.com/ibm/sarama": "kafka", "github.com/shopify/sarama": "kafka",
+	"github.com/confluentinc/confluent-kafka-go": "kafka", "github.com/twmb/franz-go": "kafka",
+
+	// Elasticsearch
+	"@elastic/elasticsearch": "elasticsearch", "elasticsearch": "elasticsearch", "elasticsearch-dsl": "elasticsearch",
+	"searchkick": "elasticsearch", "elasticsearch/elasticsearch": "elasticsearch",
+	"github.com/elastic/go-elasticsearch": "elasticsearch", "github.com/olivere/elastic": "elasticsearch",
+
+	// MongoDB
+	"mongodb": "mongodb", "mongoose": "mongodb", "pymongo": "mongodb", "motor": "mongodb", "mongoengine": "mongodb",
+	"mongoid": "mongodb", "mongodb/mongodb": "mongodb", "go.mongodb.org/mongo-driver": "mongodb",
+
+	// Memcached
+	"memjs": "memcached", "memcached": "memcached", "pymemcache": "memcached", "python-memcached": "memcached",
+	"pylibmc": "memcached", "dalli": "memcached", "github.com/bradfitz/gomemcache": "memcached",
+}
+
+// Environment variable prefixes that imply a backing service
+var backingServiceEnvPrefixes = []struct {
+	prefix string
+	kind   string
+}{
+	{"REDIS_", "redis"},
+	{"RABBITMQ_", "rabbitmq"},
+	{"AMQP_", "rabbitmq"},
+	{"CLOUDAMQP_", "rabbitmq"},
+	{"KAFKA_", "kafka"},
+	{"ELASTICSEARCH_", "elasticsearch"},
+	{"ELASTIC_URL", "elasticsearch"},
+	{"MONGODB_", "mongodb"},
+	{"MONGO_", "mongodb"},
+	{"MEMCACHED_", "memcached"},
+	{"MEMCACHE_", "memcached"},
+	{"MEMCACHIER_", "memcached"},
+}
+
+var (
+	requirementNamePattern = regexp.MustCompile(`^\s*([A-Za-z0-9_.\-]+(?:\[[^\]]+\])?)`)
+	quotedNamePattern      = regexp.MustCompile(`["']([A-Za-z0-9_.\-]+(?:\[[^\]]+\])?)\s*[<>=~!;"' ]`)
+	gemNamePattern         = regexp.MustCompile(`^\s*gem\s+["']([^"']+)["']`)
+	tomlKeyPattern         = regexp.MustCompile(`^\s*([A-Za-z0-9_\-]+)\s*=`)
+)
+
+// DependencySignal implies backing services like Redis or Kafka from client
+// libraries in package manifests and connection variables in env files
+type DependencySignal struct {
+	filesystem filesystems.FileSystem
+	extractor  *environment.Extractor
+	manifests  []string // package manifest paths
+	envFiles   []string // dotenv file paths
+
+	environment string // only read env files for this environment, or all if empty
+}
+
+func NewDependencySignal(filesystem filesystems.FileSystem) *DependencySignal {
+	return &DependencySignal{filesystem: filesystem, extractor: environment.NewExtractor(filesystem)}
+}
+
+func (d *DependencySignal) Confidence() int {
+	return 20 // Very low confidence - a client library doesn't mean the service must be provisioned
+}
+
+func (d *DependencySignal) Reset() {
+	d.manifests = nil
+	d.envFiles = nil
+}
+
+// Environments lists the environments env files are suffixed with, e.g. .env.production
+func (d *DependencySignal) Environments() []string {
+	var environments []string
+	for _, path := range d.envFiles {
+		if environment := dotenvEnvironment(d.filesystem.Base(path)); environment != "" && !slices.Contains(environments, environment) {
+			environments = append(environments, environment)
+		}
+	}
+	return environments
+}
+
+func (d *DependencySignal) SetEnvironment(environment string) {
+	d.environment = environment
+}
+
+// dotenvEnvironment returns the environment an env file is for, empty for files that apply
+// to every environment like .env, .env.local and .env.example
+func dotenvEnvironment(name string) string {
+	suffix, ok := strings.CutPrefix(strings.ToLower(name), ".env")
+	if !ok || (suffix != "" && !strings.HasPrefix(suffix, ".")) {
+		return "" // .envrc and friends
+	}
+	suffix = strings.TrimSuffix(strings.TrimPrefix(suffix, "."), ".local")
+	switch suffix {
+	case "", "local", "example", "sample", "template", "dist", "defaults":
+		return ""
+	}
+	return types.NormalizeEnvironment(suffix)
+}
+
+func (d *DependencySignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
+	if entry.IsDir() {
+		return nil
+	}
+
+	name := entry.Name()
+	fullPath := d.filesystem.Join(rootPath, name)
+	switch {
+	case matchesAny(name, "package.json", "requirements.txt", "pyproject.toml", "Pipfile", "go.mod", "Gemfile", "composer.json", "Cargo.toml"):
+		d.manifests = append(d.manifests, fullPath)
+	case strings.HasPrefix(strings.ToLower(name), ".env"):
+		d.envFiles = append(d.envFiles, fullPath)
+	}
+	return nil
+}
+
+func (d *DependencySignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	return nil, nil
+}
+
+// RefineServices adds derived services for the backing services the project
+// references but none of the discovered services provide
+func (d *DependencySignal) RefineServices(ctx context.Context, services []types.Service) []types.Service {
+	var implied impliedBackingServices
+
+	for _, path := range d.manifests {
+		content, err := filesystems.ReadTextFile(d.filesystem, path)
+		if err != nil {
+			continue
+		}
+		for _, dependency := range manifestDependencies(d.filesystem.Base(path), content) {
+			if kind, ok := clientLibraryKind(dependency); ok {
+				implied.add(services, kind, types.ConfigRef{Type: "dependency", Path: path})
+			}
+		}
+	}
+
+	for _, path := range d.envFiles {
+		if environment := dotenvEnvironment(d.filesystem.Base(path)); d.environment != "" && environment != "" && environment != d.environment {
+			continue
+		}
+		content, err := filesystems.ReadTextFile(d.filesystem, path)
+		if err != nil {
+			continue
+		}
+		for result := range d.extractor.Extract(ctx, path, content) {
+			for _, env := range backingServiceEnvPrefixes {
+				if strings.HasPrefix(result.VarName, env.prefix) {
+					implied.add(services, env.kind, types.ConfigRef{Type: "env", Path: path})
+					break
+				}
+			}
+		}
+	}
+
+	return implied.appendTo(services)
+}
+
+// clientLibraryKind looks up the backing service a dependency is a client for.
+// Go modules match by prefix so major versions like /v9 are covered.
+func clientLibraryKind(dependency string) (string, bool) {
+	dependency = strings.ToLower(dependency)
+	if kind, ok := clientLibraries[dependency]; ok {
+		return kind, true
+	}
+	if strings.Contains(dependency, "/") {
+		for library, kind := range clientLibraries {
+			if strings.HasPrefix(dependency, library+"/") {
+				return kind, true
+			}
+		}
+	}
+	return "", false
+}
+
+// manifestDependencies lists the dependency names declared in a package manifest
+func manifestDependencies(name string, content []byte) []string {
+	switch name {
+	case "package.json", "composer.json":
+		var manifest struct {
+			Dependencies map[string]any `json:"dependencies"`
+			Require      map[string]any `json:"require"`
+		}
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return nil
+		}
+		var dependencies []string
+		for _, deps := range []map[string]any{manifest.Dependencies, manifest.Require} {
+			for dependency := range deps {
+				dependencies = append(dependencies, dependency)
+			}
+		}
+		slices.Sort(dependencies) // Map order would make derived service order random
+		return dependencies
+	case "go.mod":
+		return scanLines(content, func(line string) []string {
+			fields := strings.Fields(strings.TrimPrefix(line, "require "))
+			if len(fields) >= 2 && strings.Contains(fields[0], ".") {
+				return fields[:1]
+			}
+			return nil
+		})
+	case "Gemfile":
+		return scanLines(content, func(line string) []string {
+			if match := gemNamePattern.FindStringSubmatch(line); match != nil {
+				return match[1:]
+			}
+			return nil
+		})
+	case "requirements.txt":
+		return scanLines(content, func(line string) []string {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") || strings.HasPrefix(strings.TrimSpace(line), "-") {
+				return nil
+			}
+			if match := requirementNamePattern.FindStringSubmatch(line); match != nil {
+				return match[1:]
+			}
+			return nil
+		})
+	case "pyproject.toml", "Pipfile", "Cargo.toml":
+		// Dependencies are either quoted PEP 508 strings or table keys
+		return scanLines(content, func(line string) []string {
+			var names []string
+			for _, match := range quotedNamePattern.FindAllStringSubmatch(line, -1) {
+				names = append(names, match[1])
+			}
+			if match := tomlKeyPattern.FindStringSubmatch(line); match != nil {
+				names = append(names, match[1])
+			}
+			return names
+		})
+	}
+	return nil
+}
+
+func scanLines(content []byte, parse func(line string) []string) []string {
+	var names []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		names = append(names, parse(scanner.Text())...)
+	}
+	return names
+}
@@ -2,6 +2,7 @@ package signals
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/railwayapp/turnout/internal/discovery/types"
@@ -13,6 +14,8 @@ type DigitalOceanAppSignal struct {
 	filesystem  filesystems.FileSystem
 	configPaths []string          // all found DigitalOcean app spec files
 	configDirs  map[string]string // config path -> directory path
+
+	skipped
 }
 
 func NewDigitalOceanAppSignal(filesystem filesystems.FileSystem) *DigitalOceanAppSignal {
@@ -24,6 +27,7 @@ func (d *DigitalOceanAppSignal) Confidence() int {
 }
 
 func (d *DigitalOceanAppSignal) Reset() {
+	d.skipped = skipped{}
 	d.configPaths = nil
 	d.configDirs = make(map[string]string)
 }
@@ -32,7 +36,7 @@ func (d *DigitalOceanAppSignal) ObserveEntry(ctx context.Context, rootPath strin
 	if entry.IsDir() && entry.Name() == ".do" {
 		// Check for .do/app.yaml
 		appPath := d.filesystem.Join(rootPath, ".do", "app.yaml")
-		if _, err := d.filesystem.ReadFile(appPath); err == nil {
+		if d.filesystem.Exists(appPath) {
 			d.configPaths = append(d.configPaths, appPath)
 			// BuildPath should be the directory containing .do (repo root)
 			d.configDirs[appPath] = rootPath
@@ -59,7 +63,8 @@ func (d *DigitalOceanAppSignal) GenerateServices(ctx context.Context) ([]types.S
 	for _, configPath := range d.configPaths {
 		config, err := d.parseAppSpec(configPath)
 		if err != nil {
-			continue // Skip broken configs
+			d.skip(configPath, err)
+			continue
 		}
 
 		buildPath := d.configDirs[configPath]
@@ -72,14 +77,21 @@ func (d *DigitalOceanAppSignal) GenerateServices(ctx context.Context) ([]types.S
 			}
 		}
 
+		// Components build from the repo root unless they set source_dir
 		// Add HTTP services
 		for _, appService := range config.Services {
+			sourcePath, err := repoDir(d.filesystem, buildPath, "source_dir", appService.SourceDir)
+			if err != nil {
+				d.skip(configPath, fmt.Errorf("component %s: %w", appService.Name, err))
+				continue
+			}
 			service := types.Service{
 				Name:      appService.Name,
 				Network:   types.NetworkPublic, // Services are publicly accessible
 				Runtime:   types.RuntimeContinuous,
 				Build:     determineBuildFromDOApp(appService),
-				BuildPath: buildPath, // DigitalOcean builds from repo root by default
+				BuildPath: sourcePath,
+				Resources: doInstanceResources(appService.InstanceSizeSlug),
 				Configs: []types.ConfigRef{
 					{Type: "digitalocean-app", Path: configPath},
 				},
@@ -95,12 +107,18 @@ func (d *DigitalOceanAppSignal) GenerateServices(ctx context.Context) ([]types.S
 
 		// Add static sites
 		for _, site := range config.StaticSites {
+			sourcePath, err := repoDir(d.filesystem, buildPath, "source_dir", site.SourceDir)
+			if err != nil {
+				d.skip(configPath, fmt.Errorf("component %s: %w", site.Name, err))
+				continue
+			}
 			service := types.Service{
 				Name:      site.Name,
 				Network:   types.NetworkPublic, // Static sites are public
 				Runtime:   types.RuntimeContinuous,
-				Build:     types.BuildFromSource,
-				BuildPath: buildPath, // DigitalOcean builds from repo root by default
+				Build:     types.BuildStatic,
+				BuildPath: sourcePath,
+				OutputDir: site.OutputDir,
 				Configs: []types.ConfigRef{
 					{Type: "digitalocean-app", Path: configPath},
 				},
@@ -110,12 +128,18 @@ func (d *DigitalOceanAppSignal) GenerateServices(ctx context.Context) ([]types.S
 
 		// Add workers
 		for _, worker := range config.Workers {
+			sourcePath, err := repoDir(d.filesystem, buildPath, "source_dir", worker.SourceDir)
+			if err != nil {
+				d.skip(configPath, fmt.Errorf("component %s: %w", worker.Name, err))
+				continue
+			}
 			service := types.Service{
 				Name:      worker.Name,
 				Network:   types.NetworkNone, // Workers are background processes
 				Runtime:   types.RuntimeContinuous,
 				Build:     determineBuildFromDOWorker(worker),
-				BuildPath: buildPath, // DigitalOcean builds from repo root by default
+				BuildPath: sourcePath,
+				Resources: doInstanceResources(worker.InstanceSizeSlug),
 				Configs: []types.ConfigRef{
 					{Type: "digitalocean-app", Path: configPath},
 				},
@@ -131,12 +155,17 @@ func (d *DigitalOceanAppSignal) GenerateServices(ctx context.Context) ([]types.S
 
 		// Add jobs
 		for _, job := range config.Jobs {
+			sourcePath, err := repoDir(d.filesystem, buildPath, "source_dir", job.SourceDir)
+			if err != nil {
+				d.skip(configPath, fmt.Errorf("component %s: %w", job.Name, err))
+				continue
+			}
 			service := types.Service{
 				Name:      job.Name,
 				Network:   types.NetworkNone, // Jobs are background tasks
 				Runtime:   types.RuntimeScheduled,
 				Build:     determineBuildFromDOJob(job),
-				BuildPath: buildPath, // DigitalOcean builds from repo root by default
+				BuildPath: sourcePath,
 				Configs: []types.ConfigRef{
 					{Type: "digitalocean-app", Path: configPath},
 				},
@@ -150,6 +179,31 @@ func (d *DigitalOceanAppSignal) GenerateServices(ctx context.Context) ([]types.S
 			allServices = append(allServices, service)
 		}
 
+		// Add functions
+		for _, function := range config.Functions {
+			sourcePath, err := repoDir(d.filesystem, buildPath, "source_dir", function.SourceDir)
+			if err != nil {
+				d.skip(configPath, fmt.Errorf("component %s: %w", function.Name, err))
+				continue
+			}
+			network := types.NetworkPrivate
+			if len(function.Routes) > 0 {
+				network = types.NetworkPublic // Routed functions serve HTTP traffic
+			}
+
+			service := types.Service{
+				Name:      function.Name,
+				Network:   network,
+				Runtime:   types.RuntimeContinuous,
+				Build:     types.BuildFromSource,
+				BuildPath: sourcePath,
+				Configs: []types.ConfigRef{
+					{Type: "digitalocean-app", Path: configPath},
+				},
+			}
+			allServices = append(allServices, service)
+		}
+
 		// Add databases as services
 		for _, db := range config.Databases {
 			service := types.Service{
@@ -187,6 +241,7 @@ type DOAppService struct {
 	InstanceSizeSlug string          `yaml:"instance_size_slug,omitempty"`
 	GitHub           *DOGitHubSource `yaml:"github,omitempty"`
 	GitLab           *DOGitLabSource `yaml:"gitlab,omitempty"`
+	SourceDir        string          `yaml:"source_dir,omitempty"`
 	Image            *DOImageSource  `yaml:"image,omitempty"`
 	EnvironmentSlug  string          `yaml:"environment_slug,omitempty"`
 	BuildCommand     string          `yaml:"build_command,omitempty"`
@@ -201,6 +256,7 @@ type DOStaticSite struct {
 	Name          string          `yaml:"name"`
 	GitHub        *DOGitHubSource `yaml:"github,omitempty"`
 	GitLab        *DOGitLabSource `yaml:"gitlab,omitempty"`
+	SourceDir     string          `yaml:"source_dir,omitempty"`
 	BuildCommand  string          `yaml:"build_command,omitempty"`
 	OutputDir     string          `yaml:"output_dir,omitempty"`
 	IndexDocument string          `yaml:"index_document,omitempty"`
@@ -215,6 +271,7 @@ type DOWorker struct {
 	InstanceSizeSlug string          `yaml:"instance_size_slug,omitempty"`
 	GitHub           *DOGitHubSource `yaml:"github,omitempty"`
 	GitLab           *DOGitLabSource `yaml:"gitlab,omitempty"`
+	SourceDir        string          `yaml:"source_dir,omitempty"`
 	Image            *DOImageSource  `yaml:"image,omitempty"`
 	EnvironmentSlug  string          `yaml:"environment_slug,omitempty"`
 	BuildCommand     string          `yaml:"build_command,omitempty"`
@@ -227,6 +284,7 @@ type DOJob struct {
 	Kind            string          `yaml:"kind,omitempty"` // PRE_DEPLOY, POST_DEPLOY, FAILED_DEPLOY
 	GitHub          *DOGitHubSource `yaml:"github,omitempty"`
 	GitLab          *DOGitLabSource `yaml:"gitlab,omitempty"`
+	SourceDir       string          `yaml:"source_dir,omitempty"`
 	Image           *DOImageSource  `yaml:"image,omitempty"`
 	EnvironmentSlug string          `yaml:"environment_slug,omitempty"`
 	BuildCommand    string          `yaml:"build_command,omitempty"`
@@ -238,6 +296,7 @@ type DOFunction struct {
 	Name            string          `yaml:"name"`
 	GitHub          *DOGitHubSource `yaml:"github,omitempty"`
 	GitLab          *DOGitLabSource `yaml:"gitlab,omitempty"`
+	SourceDir       string          `yaml:"source_dir,omitempty"`
 	EnvironmentSlug string          `yaml:"environment_slug,omitempty"`
 	Routes          []DORoute       `yaml:"routes,omitempty"`
 	EnvVars         []DOEnvVar      `yaml:"envs,omitempty"`
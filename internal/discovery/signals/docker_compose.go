@@ -3,22 +3,39 @@ package signals
 import (
 	"context"
 	"fmt"
+	"math"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/compose-spec/compose-go/v2/loader"
 	composeTypes "github.com/compose-spec/compose-go/v2/types"
 	"github.com/railwayapp/turnout/internal/discovery/types"
 	"github.com/railwayapp/turnout/internal/filesystems"
+	"gopkg.in/yaml.v3"
+)
+
+// Compose environments a policy can prefer when a directory has several compose files
+const (
+	ComposeProduction  = "production"
+	ComposeDevelopment = "development"
 )
 
 type DockerComposeSignal struct {
 	filesystem   filesystems.FileSystem
 	composeFiles []string          // all found compose files
 	composeDirs  map[string]string // compose file path -> directory path
+
+	// Environment is the compose environment layered over a directory's base compose file,
+	// ComposeProduction unless set. Files for other environments are ignored.
+	Environment string
+	environment string // set while discovering per environment
+
+	skipped
 }
 
 func NewDockerComposeSignal(filesystem filesystems.FileSystem) *DockerComposeSignal {
-	return &DockerComposeSignal{filesystem: filesystem}
+	return &DockerComposeSignal{filesystem: filesystem, Environment: ComposeProduction}
 }
 
 func (d *DockerComposeSignal) Confidence() int {
@@ -26,63 +43,147 @@ func (d *DockerComposeSignal) Confidence() int {
 }
 
 func (d *DockerComposeSignal) Reset() {
+	d.skipped = skipped{}
 	d.composeFiles = nil
 	d.composeDirs = make(map[string]string)
 }
 
-var composeFiles = []string{
-	"docker-compose.yml",
-	"docker-compose.yaml",
-	"compose.yml",
-	"compose.yaml",
-	"docker-compose.prod.yml",
-	"docker-compose.prod.yaml",
-	"docker-compose.production.yml",
-	"docker-compose.production.yaml",
-	"compose.prod.yml",
-	"compose.prod.yaml",
-	"compose.production.yml",
-	"compose.production.yaml",
+// Environments lists the environments compose files are suffixed with
+func (d *DockerComposeSignal) Environments() []string {
+	var environments []string
+	for _, composePath := range d.composeFiles {
+		environment, _ := composeFileEnvironment(d.filesystem.Base(composePath))
+		if environment != "" && !slices.Contains(environments, environment) {
+			environments = append(environments, environment)
+		}
+	}
+	return environments
+}
+
+func (d *DockerComposeSignal) SetEnvironment(environment string) {
+	d.environment = environment
+}
+
+// Compose files, optionally suffixed with the environment they're for, e.g. compose.prod.yaml
+var composeFilePattern = regexp.MustCompile(`(?i)^(?:docker-)?compose(?:\.([\w-]+))?\.ya?ml$`)
+
+// composeFileEnvironment returns the environment a compose file is for, empty for base
+// files, and whether it's a compose file at all
+func composeFileEnvironment(name string) (string, bool) {
+	match := composeFilePattern.FindStringSubmatch(name)
+	if match == nil {
+		return "", false
+	}
+	if match[1] == "" {
+		return "", true
+	}
+	return types.NormalizeEnvironment(match[1]), true
 }
 
 func (d *DockerComposeSignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
-	if !entry.IsDir() {
-		for _, filename := range composeFiles {
-			if strings.EqualFold(entry.Name(), filename) {
-				composePath := d.filesystem.Join(rootPath, entry.Name())
-				d.composeFiles = append(d.composeFiles, composePath)
-				d.composeDirs[composePath] = rootPath
-				break // Only take the first match per priority
-			}
-		}
+	if entry.IsDir() {
+		return nil
+	}
+	if _, ok := composeFileEnvironment(entry.Name()); ok {
+		composePath := d.filesystem.Join(rootPath, entry.Name())
+		d.composeFiles = append(d.composeFiles, composePath)
+		d.composeDirs[composePath] = rootPath
 	}
 
 	return nil
 }
 
+// composeLayer is a compose file loaded as part of a project, like `docker compose -f`
+type composeLayer struct {
+	path        string
+	environment string
+	services    map[string]bool // services the file defines or overrides
+}
+
 func (d *DockerComposeSignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
 	if len(d.composeFiles) == 0 {
 		return nil, nil
 	}
 
-	// Process the first compose file found (highest priority)
-	composePath := d.composeFiles[0]
-	workingDir := d.composeDirs[composePath]
+	var services []types.Service
+	var firstErr error
+	for _, layers := range d.selectLayers() {
+		projectServices, err := d.loadProject(ctx, layers)
+		if err != nil {
+			d.skip(layers[0].path, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		services = append(services, projectServices...)
+	}
+
+	if len(services) == 0 {
+		return nil, firstErr
+	}
+	return services, nil
+}
 
-	// Read compose file content through filesystem
-	content, err := d.filesystem.ReadFile(composePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read compose file %s: %w", composePath, err)
+// selectLayers picks each directory's base compose file and the file for the chosen
+// environment layered over it, in the order the directories were found
+func (d *DockerComposeSignal) selectLayers() [][]composeLayer {
+	var dirs []string
+	byDir := make(map[string]map[string]string) // dir -> environment -> first file
+	for _, composePath := range d.composeFiles {
+		dir := d.composeDirs[composePath]
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+			byDir[dir] = make(map[string]string)
+		}
+		environment, _ := composeFileEnvironment(d.filesystem.Base(composePath))
+		if _, ok := byDir[dir][environment]; !ok {
+			byDir[dir][environment] = composePath
+		}
 	}
 
-	configDetails := composeTypes.ConfigDetails{
-		WorkingDir: workingDir,
-		ConfigFiles: []composeTypes.ConfigFile{
-			{
-				Filename: composePath,
-				Content:  content,
-			},
-		},
+	var projects [][]composeLayer
+	for _, dir := range dirs {
+		var layers []composeLayer
+		selected := d.Environment
+		if d.environment != "" {
+			selected = d.environment
+		}
+		for _, environment := range []string{"", selected} {
+			if composePath, ok := byDir[dir][environment]; ok {
+				layers = append(layers, composeLayer{path: composePath, environment: environment})
+			}
+		}
+		if len(layers) > 0 {
+			projects = append(projects, layers)
+		}
+	}
+	return projects
+}
+
+func (d *DockerComposeSignal) loadProject(ctx context.Context, layers []composeLayer) ([]types.Service, error) {
+	workingDir := d.composeDirs[layers[0].path]
+
+	configDetails := composeTypes.ConfigDetails{WorkingDir: workingDir}
+	for i := range layers {
+		// Read compose file content through filesystem
+		content, err := d.filesystem.ReadFile(layers[i].path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read compose file %s: %w", layers[i].path, err)
+		}
+		configDetails.ConfigFiles = append(configDetails.ConfigFiles, composeTypes.ConfigFile{
+			Filename: layers[i].path,
+			Content:  content,
+		})
+
+		var file struct {
+			Services map[string]any `yaml:"services"`
+		}
+		_ = yaml.Unmarshal(content, &file)
+		layers[i].services = make(map[string]bool)
+		for name := range file.Services {
+			layers[i].services[name] = true
+		}
 	}
 
 	project, err := loader.LoadWithContext(ctx, configDetails, func(options *loader.Options) {
@@ -99,29 +200,128 @@ func (d *DockerComposeSignal) GenerateServices(ctx context.Context) ([]types.Ser
 			Network: determineNetwork(composeService),
 			Runtime: determineRuntime(composeService),
 			Build:   determineBuild(composeService),
-			Configs: []types.ConfigRef{
-				{Type: "docker-compose", Path: composePath},
-			},
+		}
+
+		// Record which files, and so which environments, the definition came from
+		for _, layer := range layers {
+			if layer.services[name] {
+				service.Configs = append(service.Configs, types.ConfigRef{
+					Type:        "docker-compose",
+					Path:        layer.path,
+					Environment: layer.environment,
+				})
+			}
 		}
 
 		// Set build path or image
 		if service.Build == types.BuildFromSource && composeService.Build != nil {
-			// Build context is relative to the compose file directory
-			if composeService.Build.Context == "." {
-				service.BuildPath = workingDir
-			} else {
-				service.BuildPath = d.filesystem.Join(workingDir, composeService.Build.Context)
-			}
+			// The loader already resolved the build context against the compose file directory
+			service.BuildPath = composeService.Build.Context
 		} else if service.Build == types.BuildFromImage {
 			service.Image = composeService.Image
 		}
 
+		if platform := composeServicePlatform(composeService); platform != "" {
+			service.Platform = platform
+			service.SetProvenance("Platform", "docker-compose:"+layers[0].path, 90)
+		}
+		service.Resources = composeResources(composeService)
+		service.Networks = composeNetworks(project, composeService)
+		for _, port := range composeService.Ports {
+			service.PublishedPorts = append(service.PublishedPorts, types.PortMapping{Published: port.Published, Target: int(port.Target)})
+		}
+
+		applyComposeOverrides(&service, composeService)
 		services = append(services, service)
 	}
 
 	return services, nil
 }
 
+// composeNetworks lists the networks a service joins by their full names, like
+// shop_default, so services from different compose projects don't share them
+func composeNetworks(project *composeTypes.Project, service composeTypes.ServiceConfig) []string {
+	var networks []string
+	for key := range service.Networks {
+		name := key
+		if network, ok := project.Networks[key]; ok && network.Name != "" {
+			name = network.Name
+		}
+		networks = append(networks, name)
+	}
+	slices.Sort(networks)
+	return networks
+}
+
+// composeServicePlatform is the OS a service's platform, like windows/amd64, or its
+// image needs
+func composeServicePlatform(service composeTypes.ServiceConfig) string {
+	if os := platformOS(service.Platform); os != "" {
+		return os
+	}
+	if service.Build != nil {
+		return "" // the image is what the build is tagged, not what it's built from
+	}
+	return imagePlatform(service.Image)
+}
+
+// Label prefixes users annotate compose services with, lowest precedence first.
+// An x-railway extension block takes precedence over all of them.
+var composeOverrideLabelPrefixes = []string{"turnout.", "railway."}
+
+// applyComposeOverrides pins the name, network, runtime and schedule users set with
+// labels or an x-railway block, e.g. `railway.network: public`
+func applyComposeOverrides(service *types.Service, composeService composeTypes.ServiceConfig) {
+	overrides := make(map[string]string)
+	for _, prefix := range composeOverrideLabelPrefixes {
+		for label, value := range composeService.Labels {
+			if key, ok := strings.CutPrefix(label, prefix); ok {
+				overrides[key] = value
+			}
+		}
+	}
+	if extension, ok := composeService.Extensions["x-railway"].(map[string]any); ok {
+		for key, value := range extension {
+			overrides[key] = fmt.Sprint(value)
+		}
+	}
+
+	if name := overrides["name"]; name != "" {
+		service.Name = name
+		service.Pin("Name")
+	}
+	if network, ok := parseNetworkHint(overrides["network"]); ok {
+		service.Network = network
+		service.Pin("Network")
+	}
+	if schedule := overrides["schedule"]; schedule != "" {
+		service.Runtime = types.RuntimeScheduled
+		service.Schedule = schedule
+		service.Pin("Runtime")
+		service.Pin("Schedule")
+	}
+	switch strings.ToLower(overrides["runtime"]) {
+	case "continuous":
+		service.Runtime = types.RuntimeContinuous
+		service.Pin("Runtime")
+	case "scheduled", "cron":
+		service.Runtime = types.RuntimeScheduled
+		service.Pin("Runtime")
+	}
+}
+
+func parseNetworkHint(value string) (types.Network, bool) {
+	switch strings.ToLower(value) {
+	case "public":
+		return types.NetworkPublic, true
+	case "private", "internal":
+		return types.NetworkPrivate, true
+	case "none":
+		return types.NetworkNone, true
+	}
+	return types.NetworkNone, false
+}
+
 func determineNetwork(service composeTypes.ServiceConfig) types.Network {
 	// No ports at all = background worker
 	if len(service.Ports) == 0 && len(service.Expose) == 0 {
@@ -170,3 +370,32 @@ func determineBuild(service composeTypes.ServiceConfig) types.Build {
 	// Uses pre-built image
 	return types.BuildFromImage
 }
+
+// composeResources reads a service's deploy limits, falling back to its reservations
+// and the older cpus and mem_limit keys
+func composeResources(service composeTypes.ServiceConfig) *types.Resources {
+	resources := types.Resources{CPU: composeCPUs(service.CPUS), MemoryMB: int(service.MemLimit / (1 << 20))}
+	if deploy := service.Deploy; deploy != nil {
+		for _, resource := range []*composeTypes.Resource{deploy.Resources.Reservations, deploy.Resources.Limits} {
+			if resource == nil {
+				continue
+			}
+			if resource.NanoCPUs > 0 {
+				resources.CPU = composeCPUs(float32(resource.NanoCPUs))
+			}
+			if resource.MemoryBytes > 0 {
+				resources.MemoryMB = int(resource.MemoryBytes / (1 << 20))
+			}
+		}
+	}
+	if resources == (types.Resources{}) {
+		return nil
+	}
+	return &resources
+}
+
+// composeCPUs converts compose's float32 CPU counts without their rounding error,
+// like 0.3 rather than 0.30000001192092896
+func composeCPUs(cpus float32) float64 {
+	return math.Round(float64(cpus)*1000) / 1000
+}
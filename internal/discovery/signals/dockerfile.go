@@ -3,8 +3,11 @@ package signals
 import (
 	"context"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
 	"github.com/railwayapp/turnout/internal/discovery/types"
 	"github.com/railwayapp/turnout/internal/filesystems"
 )
@@ -13,6 +16,7 @@ type DockerfileSignal struct {
 	filesystem     filesystems.FileSystem
 	dockerfiles    []string          // all found Dockerfiles
 	dockerfileDirs map[string]string // dockerfile path -> directory path
+	conclusive     map[string]bool   // dockerfile path -> content fully describes how to run it
 }
 
 func NewDockerfileSignal(filesystem filesystems.FileSystem) *DockerfileSignal {
@@ -23,9 +27,24 @@ func (d *DockerfileSignal) Confidence() int {
 	return 50 // Poor confidence - just indicates buildable service, not deployment config
 }
 
+// ServiceConfidence raises confidence for Dockerfiles that declare both what to run and where it listens
+func (d *DockerfileSignal) ServiceConfidence(service types.Service) int {
+	// The first Dockerfile config is the variant the service was built from
+	for _, config := range service.Configs {
+		if config.Type == "dockerfile" {
+			if d.conclusive[config.Path] {
+				return 70
+			}
+			break
+		}
+	}
+	return d.Confidence()
+}
+
 func (d *DockerfileSignal) Reset() {
 	d.dockerfiles = nil
 	d.dockerfileDirs = make(map[string]string)
+	d.conclusive = make(map[string]bool)
 }
 
 func (d *DockerfileSignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
@@ -53,25 +72,315 @@ func (d *DockerfileSignal) GenerateServices(ctx context.Context) ([]types.Servic
 		return nil, nil
 	}
 
-	var services []types.Service
+	// Variants in one directory (Dockerfile, Dockerfile.dev, Dockerfile.prod) describe the
+	// same service, but named ones like Dockerfile.web and Dockerfile.worker each build a
+	// service of their own
+	type group struct{ dir, role string }
+	var groups []group
+	variants := make(map[group][]string)
+	roles := make(map[string]int) // directory -> how many services its Dockerfiles build
 	for _, dockerfilePath := range d.dockerfiles {
+		key := group{dir: d.filesystem.Dir(dockerfilePath)}
+		if name := d.filesystem.Base(dockerfilePath); dockerfileVariantRank(name) == 2 {
+			key.role = dockerfileVariant(name)
+		}
+		if _, ok := variants[key]; !ok {
+			groups = append(groups, key)
+			roles[key.dir]++
+		}
+		variants[key] = append(variants[key], dockerfilePath)
+	}
+
+	var services []types.Service
+	for _, key := range groups {
+		dockerfiles := variants[key]
+		slices.SortStableFunc(dockerfiles, func(a, b string) int {
+			return dockerfileVariantRank(d.filesystem.Base(a)) - dockerfileVariantRank(d.filesystem.Base(b))
+		})
+
+		dockerfilePath := dockerfiles[0]
 		rootPath := d.dockerfileDirs[dockerfilePath]
 		service := types.Service{
 			Name:      d.inferServiceName(dockerfilePath, rootPath),
 			Network:   types.NetworkPrivate, // Conservative default
 			Runtime:   types.RuntimeContinuous,
 			Build:     types.BuildFromSource,
-			BuildPath: d.filesystem.Dir(dockerfilePath),
-			Configs: []types.ConfigRef{
-				{Type: "dockerfile", Path: dockerfilePath},
-			},
+			BuildPath: key.dir,
+		}
+		if key.role != "" && roles[key.dir] > 1 {
+			service.Name = key.role
+		}
+		for _, path := range dockerfiles {
+			service.Configs = append(service.Configs, types.ConfigRef{Type: "dockerfile", Path: path})
+		}
+
+		if info, err := d.analyzeDockerfile(dockerfilePath); err == nil {
+			d.applyDockerfileInfo(&service, dockerfilePath, info)
 		}
+
 		services = append(services, service)
 	}
 
 	return services, nil
 }
 
+// dockerfileVariantRank orders Dockerfile variants by how likely they are to be
+// the one deployed: production first, then the default, then anything else, and
+// development or test variants last
+func dockerfileVariantRank(name string) int {
+	switch dockerfileVariant(name) {
+	case "":
+		return 1
+	case "prod", "production", "release":
+		return 0
+	case "dev", "development", "local", "test", "testing", "ci", "debug":
+		return 3
+	}
+	return 2
+}
+
+// dockerfileVariant is what a Dockerfile's name adds to "Dockerfile", like "prod" for
+// Dockerfile.prod or "worker" for worker.Dockerfile
+func dockerfileVariant(name string) string {
+	name = strings.ToLower(name)
+	variant := strings.TrimSuffix(strings.TrimPrefix(name, "dockerfile"), ".dockerfile")
+	return strings.Trim(variant, ".-_")
+}
+
+// DockerfileInfo is what we can learn about the final stage of a Dockerfile
+type DockerfileInfo struct {
+	BaseImage    string // image reference of the final stage, with stage aliases resolved
+	BaseFamily   string // e.g. "node", "python", "nginx"
+	Platform     string // OS the final stage needs, like "windows", empty for Linux
+	FinalStage   string // name of the final stage, empty if unnamed
+	ExposedPorts []int
+	Entrypoint   string
+	Cmd          string
+}
+
+// StartCommand combines ENTRYPOINT and CMD the way the container runtime does
+func (i *DockerfileInfo) StartCommand() string {
+	return strings.TrimSpace(i.Entrypoint + " " + i.Cmd)
+}
+
+type dockerfileStage struct {
+	name       string
+	baseImage  string
+	platform   string
+	ports      []int
+	entrypoint string
+	cmd        string
+
+	cmdInherited bool
+}
+
+func (d *DockerfileSignal) analyzeDockerfile(dockerfilePath string) (*DockerfileInfo, error) {
+	content, err := d.filesystem.ReadFile(dockerfilePath)
+	if err != nil {
+		return nil, err
+	}
+	return parseDockerfile(content)
+}
+
+func parseDockerfile(content []byte) (*DockerfileInfo, error) {
+	result, err := parser.Parse(strings.NewReader(string(content)))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make(map[string]string) // global ARGs usable in FROM
+	var stages []*dockerfileStage
+	stagesByName := make(map[string]*dockerfileStage)
+
+	for _, node := range result.AST.Children {
+		var current *dockerfileStage
+		if len(stages) > 0 {
+			current = stages[len(stages)-1]
+		}
+
+		switch strings.ToLower(node.Value) {
+		case "arg":
+			if current == nil && node.Next != nil {
+				name, value, _ := strings.Cut(node.Next.Value, "=")
+				args[name] = value
+			}
+		case "from":
+			if node.Next == nil {
+				continue
+			}
+			stage := &dockerfileStage{baseImage: expandDockerfileArgs(node.Next.Value, args)}
+			stage.platform = imagePlatform(stage.baseImage)
+			for _, flag := range node.Flags {
+				if platform, ok := strings.CutPrefix(flag, "--platform="); ok {
+					if os := platformOS(expandDockerfileArgs(platform, args)); os != "" {
+						stage.platform = os
+					}
+				}
+			}
+			if n := node.Next.Next; n != nil && strings.EqualFold(n.Value, "as") && n.Next != nil {
+				stage.name = strings.ToLower(n.Next.Value)
+			}
+
+			// Inherit runtime settings when building on top of an earlier stage
+			if parent, ok := stagesByName[strings.ToLower(stage.baseImage)]; ok {
+				stage.baseImage = parent.baseImage
+				stage.platform = parent.platform
+				stage.ports = append(stage.ports, parent.ports...)
+				stage.entrypoint = parent.entrypoint
+				stage.cmd = parent.cmd
+				stage.cmdInherited = true
+			}
+
+			stages = append(stages, stage)
+			if stage.name != "" {
+				stagesByName[stage.name] = stage
+			}
+		case "expose":
+			if current == nil {
+				continue
+			}
+			for n := node.Next; n != nil; n = n.Next {
+				port, _, _ := strings.Cut(n.Value, "/")
+				if p, err := strconv.Atoi(port); err == nil {
+					current.ports = append(current.ports, p)
+				}
+			}
+		case "cmd":
+			if current != nil {
+				current.cmd = dockerfileCommand(node)
+				current.cmdInherited = false
+			}
+		case "entrypoint":
+			if current != nil {
+				current.entrypoint = dockerfileCommand(node)
+				if current.cmdInherited {
+					current.cmd = "" // ENTRYPOINT resets a CMD inherited from the base stage
+				}
+			}
+		}
+	}
+
+	if len(stages) == 0 {
+		return &DockerfileInfo{}, nil
+	}
+
+	final := stages[len(stages)-1]
+	return &DockerfileInfo{
+		BaseImage:    final.baseImage,
+		BaseFamily:   imageFamily(final.baseImage),
+		Platform:     final.platform,
+		FinalStage:   final.name,
+		ExposedPorts: final.ports,
+		Entrypoint:   final.entrypoint,
+		Cmd:          final.cmd,
+	}, nil
+}
+
+func dockerfileCommand(node *parser.Node) string {
+	var parts []string
+	for n := node.Next; n != nil; n = n.Next {
+		parts = append(parts, n.Value)
+	}
+	return strings.Join(parts, " ")
+}
+
+func expandDockerfileArgs(value string, args map[string]string) string {
+	return strings.TrimSpace(expandVars(value, func(name string) string {
+		return args[name]
+	}))
+}
+
+// expandVars replaces $VAR, ${VAR} and ${VAR:-default} references
+func expandVars(value string, lookup func(string) string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 >= len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		if value[i+1] == '{' {
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				b.WriteString(value[i:])
+				break
+			}
+			expr := value[i+2 : i+end]
+			name, fallback, hasFallback := strings.Cut(expr, ":-")
+			if v := lookup(name); v != "" || !hasFallback {
+				b.WriteString(v)
+			} else {
+				b.WriteString(fallback)
+			}
+			i += end
+			continue
+		}
+
+		j := i + 1
+		for j < len(value) && (value[j] == '_' || value[j] >= 'A' && value[j] <= 'Z' || value[j] >= 'a' && value[j] <= 'z' || value[j] >= '0' && value[j] <= '9') {
+			j++
+		}
+		if j == i+1 {
+			b.WriteByte(value[i])
+			continue
+		}
+		b.WriteString(lookup(value[i+1 : j]))
+		i = j - 1
+	}
+	return b.String()
+}
+
+// imageFamily reduces an image reference to its repository name, e.g.
+// "docker.io/library/node:20-alpine" -> "node"
+func imageFamily(image string) string {
+	if image == "" {
+		return ""
+	}
+	ref, _, _ := strings.Cut(image, "@")
+	parts := strings.Split(ref, "/")
+	name := parts[len(parts)-1]
+	name, _, _ = strings.Cut(name, ":")
+	return strings.ToLower(name)
+}
+
+// Base images that exist to serve HTTP traffic
+var webServerImages = []string{"nginx", "caddy", "httpd", "traefik", "haproxy", "envoy"}
+
+func (d *DockerfileSignal) applyDockerfileInfo(service *types.Service, dockerfilePath string, info *DockerfileInfo) {
+	source := "dockerfile:" + dockerfilePath
+
+	if info.BaseFamily != "" {
+		service.BaseImage = info.BaseFamily
+	}
+	if info.Platform != "" {
+		service.Platform = info.Platform
+		service.SetProvenance("Platform", source, 90)
+	}
+	if start := info.StartCommand(); start != "" {
+		service.StartCommand = start
+		service.SetProvenance("StartCommand", source, 70)
+	}
+	if len(info.ExposedPorts) > 0 {
+		service.Port = info.ExposedPorts[0]
+		service.SetProvenance("Port", source, 70)
+	}
+
+	for _, port := range info.ExposedPorts {
+		if port == 80 || port == 443 {
+			service.Network = types.NetworkPublic
+		}
+	}
+	for _, image := range webServerImages {
+		if info.BaseFamily == image {
+			service.Network = types.NetworkPublic
+		}
+	}
+
+	// Knowing both the command and the port (or a web server image) leaves little to guess
+	d.conclusive[dockerfilePath] = (len(info.ExposedPorts) > 0 && info.StartCommand() != "") ||
+		(service.Network == types.NetworkPublic && service.BaseImage != "")
+}
+
 func (d *DockerfileSignal) inferServiceName(dockerfilePath, rootPath string) string {
 	dir := d.filesystem.Dir(dockerfilePath)
 
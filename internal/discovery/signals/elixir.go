@@ -0,0 +1,307 @@
+package signals
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// ElixirSignal reads mix.exs projects for what they run. Umbrella projects, with their
+// apps under apps/, build and release from the umbrella root, so each app that serves
+// a Phoenix endpoint or runs Oban or Broadway workers becomes a service built there.
+// Releases, from mix.exs or Distillery's rel/config.exs, give the start command.
+type ElixirSignal struct {
+	filesystem filesystems.FileSystem
+	mixPaths   []string
+
+	skipped
+}
+
+func NewElixirSignal(filesystem filesystems.FileSystem) *ElixirSignal {
+	return &ElixirSignal{filesystem: filesystem}
+}
+
+func (e *ElixirSignal) Confidence() int {
+	return 90 // High confidence - releases and dependencies say what each app runs and how it starts
+}
+
+func (e *ElixirSignal) Reset() {
+	e.skipped = skipped{}
+	e.mixPaths = nil
+}
+
+func (e *ElixirSignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
+	if !entry.IsDir() && entry.Name() == "mix.exs" {
+		e.mixPaths = append(e.mixPaths, e.filesystem.Join(rootPath, entry.Name()))
+	}
+	return nil
+}
+
+// mixProject is what a mix.exs says about a project
+type mixProject struct {
+	path     string
+	dir      string
+	app      string   // the OTP application, from app: :name
+	appsPath string   // where an umbrella keeps its apps, empty otherwise
+	deps     []string // dependency names, like phoenix
+	content  string
+}
+
+var (
+	mixAppPattern      = regexp.MustCompile(`\bapp:\s*:(\w+)`)
+	mixAppsPathPattern = regexp.MustCompile(`\bapps_path:\s*"([^"]+)"`)
+	mixDepPattern      = regexp.MustCompile(`\{\s*:(\w+)\s*,`)
+)
+
+func (e *ElixirSignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	projects := make(map[string]*mixProject)
+	for _, mixPath := range e.mixPaths {
+		content, err := filesystems.ReadTextFile(e.filesystem, mixPath)
+		if err != nil {
+			e.skip(mixPath, err)
+			continue
+		}
+		project := &mixProject{path: mixPath, dir: e.filesystem.Dir(mixPath), content: string(content)}
+		if match := mixAppPattern.FindStringSubmatch(project.content); match != nil {
+			project.app = match[1]
+		}
+		if match := mixAppsPathPattern.FindStringSubmatch(project.content); match != nil {
+			project.appsPath = match[1]
+		}
+		for _, match := range mixDepPattern.FindAllStringSubmatch(project.content, -1) {
+			project.deps = append(project.deps, match[1])
+		}
+		projects[project.dir] = project
+	}
+
+	var services []types.Service
+	for _, mixPath := range e.mixPaths {
+		project := projects[e.filesystem.Dir(mixPath)]
+		if project == nil {
+			continue
+		}
+		if project.appsPath != "" {
+			services = append(services, e.umbrellaServices(project, projects)...)
+			continue
+		}
+		if e.umbrellaOf(project, projects) != nil {
+			continue // Built with its umbrella
+		}
+
+		// A single project only tells the other signals how it starts, when it's released
+		releases := e.releases(project)
+		if len(releases) == 0 {
+			continue
+		}
+		if service, ok := e.appService(project, project, releases); ok {
+			service.Name = e.filesystem.Base(project.dir)
+			services = append(services, service)
+		}
+	}
+	return services, nil
+}
+
+// umbrellaServices emits a service for each of an umbrella's apps that runs something
+func (e *ElixirSignal) umbrellaServices(umbrella *mixProject, projects map[string]*mixProject) []types.Service {
+	releases := e.releases(umbrella)
+	var services []types.Service
+	for _, mixPath := range e.mixPaths {
+		app := projects[e.filesystem.Dir(mixPath)]
+		if app == nil || e.umbrellaOf(app, projects) != umbrella {
+			continue
+		}
+		if service, ok := e.appService(umbrella, app, releases); ok {
+			services = append(services, service)
+		}
+	}
+	return services
+}
+
+// umbrellaOf returns the umbrella a project is one of the apps of, if any
+func (e *ElixirSignal) umbrellaOf(project *mixProject, projects map[string]*mixProject) *mixProject {
+	for _, umbrella := range projects {
+		if umbrella.appsPath != "" && e.filesystem.Dir(project.dir) == e.filesystem.Join(umbrella.dir, umbrella.appsPath) {
+			return umbrella
+		}
+	}
+	return nil
+}
+
+// appService maps an app onto a service built from root, the umbrella or the project
+// itself: a public web service for a Phoenix endpoint, a worker for Oban or Broadway,
+// or nothing for a library
+func (e *ElixirSignal) appService(root, app *mixProject, releases []mixRelease) (types.Service, bool) {
+	name := app.app
+	if name == "" {
+		name = e.filesystem.Base(app.dir)
+	}
+	service := types.Service{
+		Name:      name,
+		Runtime:   types.RuntimeContinuous,
+		Build:     types.BuildFromSource,
+		BuildPath: root.dir,
+		Configs:   []types.ConfigRef{{Type: "mix", Path: app.path}},
+	}
+
+	web := slices.Contains(app.deps, "phoenix")
+	switch {
+	case web:
+		service.Network = types.NetworkPublic
+		service.StartCommand = "mix phx.server"
+	case slices.Contains(app.deps, "oban") || slices.Contains(app.deps, "broadway"):
+		service.Network = types.NetworkNone
+		service.StartCommand = "mix run --no-halt"
+	default:
+		return types.Service{}, false
+	}
+
+	if root != app {
+		service.Configs = append(service.Configs, types.ConfigRef{Type: "mix", Path: root.path})
+	}
+	if release, ok := releaseFor(releases, name); ok {
+		service.StartCommand = release.startCommand()
+		if release.configPath != root.path {
+			service.Configs = append(service.Configs, types.ConfigRef{Type: "mix", Path: release.configPath})
+		}
+	}
+	if web {
+		applyFrameworkDefaults(&service, "Phoenix")
+	}
+	return service, true
+}
+
+// mixRelease is a release a project defines, and the text of its definition, to find
+// which apps it includes
+type mixRelease struct {
+	name       string
+	definition string
+	configPath string
+	distillery bool
+}
+
+// startCommand runs the release from where `mix release` builds it
+func (r mixRelease) startCommand() string {
+	command := "start"
+	if r.distillery {
+		command = "foreground"
+	}
+	return "_build/prod/rel/" + r.name + "/bin/" + r.name + " " + command
+}
+
+// releaseFor finds the release running an app: the one named after it, the one
+// listing it, or the only release
+func releaseFor(releases []mixRelease, app string) (mixRelease, bool) {
+	if index := slices.IndexFunc(releases, func(r mixRelease) bool { return r.name == app }); index >= 0 {
+		return releases[index], true
+	}
+	pattern := regexp.MustCompile(`(\b|:)` + regexp.QuoteMeta(app) + `\b`)
+	for _, release := range releases {
+		if pattern.MatchString(release.definition) {
+			return release, true
+		}
+	}
+	if len(releases) == 1 {
+		return releases[0], true
+	}
+	return mixRelease{}, false
+}
+
+var (
+	mixReleasesPattern       = regexp.MustCompile(`\breleases:\s*(\w+\(\)|\[)`)
+	mixKeyPattern            = regexp.MustCompile(`^(\w+):\s`)
+	distilleryReleasePattern = regexp.MustCompile(`(?m)^\s*release\s+:(\w+)\s+do`)
+)
+
+// releases lists the releases a project defines, in its mix.exs or Distillery's
+// rel/config.exs. A project with a rel/ directory and no releases of its own has the
+// default release, named after its app, unless it's an umbrella, which must name them.
+func (e *ElixirSignal) releases(project *mixProject) []mixRelease {
+	if releases := mixReleases(project); len(releases) > 0 {
+		return releases
+	}
+
+	configPath := e.filesystem.Join(project.dir, "rel", "config.exs")
+	if content, err := filesystems.ReadTextFile(e.filesystem, configPath); err == nil {
+		var releases []mixRelease
+		text := string(content)
+		matches := distilleryReleasePattern.FindAllStringSubmatchIndex(text, -1)
+		for i, match := range matches {
+			end := len(text)
+			if i+1 < len(matches) {
+				end = matches[i+1][0]
+			}
+			releases = append(releases, mixRelease{
+				name:       text[match[2]:match[3]],
+				definition: text[match[1]:end],
+				configPath: configPath,
+				distillery: true,
+			})
+		}
+		return releases
+	}
+
+	if project.appsPath == "" && project.app != "" && e.filesystem.Exists(e.filesystem.Join(project.dir, "rel")) {
+		return []mixRelease{{name: project.app, configPath: project.path}}
+	}
+	return nil
+}
+
+// mixReleases reads the releases: keyword list of a mix.exs project, written inline
+// or in a function, like releases: releases()
+func mixReleases(project *mixProject) []mixRelease {
+	content := project.content
+	match := mixReleasesPattern.FindStringSubmatchIndex(content)
+	if match == nil {
+		return nil
+	}
+	start := match[2]
+	if function, ok := strings.CutSuffix(content[match[2]:match[3]], "()"); ok {
+		definition := regexp.MustCompile(`\bdefp?\s+` + function + `\b[^\[]*\[`).FindStringIndex(content)
+		if definition == nil {
+			return nil
+		}
+		start = definition[1] - 1
+	}
+
+	// Releases are the keys at the top level of the keyword list
+	var releases []mixRelease
+	var starts []int
+	depth, end := 0, len(content)
+	for i := start; i < len(content); i++ {
+		switch content[i] {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		}
+		if depth == 0 {
+			end = i
+			break
+		}
+		if depth == 1 && (content[i] == '[' || content[i] == ',') {
+			rest := strings.TrimLeft(content[i+1:], " \t\r\n")
+			if key := mixKeyPattern.FindStringSubmatch(rest); key != nil {
+				releases = append(releases, mixRelease{name: key[1], configPath: project.path})
+				starts = append(starts, i+1)
+			}
+		}
+	}
+	for i := range releases {
+		stop := end
+		if i+1 < len(starts) {
+			stop = starts[i+1]
+		}
+		releases[i].definition = content[starts[i]:stop]
+	}
+	return releases
+}
+
+// inUmbrella reports whether a mix.exs is an umbrella or one of its apps, which
+// share the umbrella's build, deps and lockfile
+func inUmbrella(content string) bool {
+	return mixAppsPathPattern.MatchString(content) || strings.Contains(content, `"../../_build"`) || strings.Contains(content, `"../../deps"`)
+}
@@ -2,6 +2,9 @@ package signals
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -13,6 +16,8 @@ type FlySignal struct {
 	filesystem  filesystems.FileSystem
 	configPaths []string          // all found fly.toml files
 	configDirs  map[string]string // config path -> directory path
+
+	skipped
 }
 
 func NewFlySignal(filesystem filesystems.FileSystem) *FlySignal {
@@ -23,7 +28,13 @@ func (f *FlySignal) Confidence() int {
 	return 95 // Highest confidence - Fly configs are explicit production deployment specs
 }
 
+// ServiceConfidence distrusts fly.toml files naming a Dockerfile that isn't there
+func (f *FlySignal) ServiceConfidence(service types.Service) int {
+	return staleConfidence(service, f.Confidence())
+}
+
 func (f *FlySignal) Reset() {
+	f.skipped = skipped{}
 	f.configPaths = nil
 	f.configDirs = make(map[string]string)
 }
@@ -47,22 +58,64 @@ func (f *FlySignal) GenerateServices(ctx context.Context) ([]types.Service, erro
 	for _, configPath := range f.configPaths {
 		config, err := f.parseFlyConfig(configPath)
 		if err != nil {
-			continue // Skip broken configs
+			f.skip(configPath, err)
+			continue
 		}
 
 		buildPath := f.configDirs[configPath]
-		// Fly.io apps are typically single-service (like Railway)
-		service := types.Service{
-			Name:      f.filesystem.Base(buildPath), // Use directory name for consistency
-			Network:   determineNetworkFromFly(config),
-			Runtime:   types.RuntimeContinuous, // Fly services are continuous
-			Build:     determineBuildFromFly(config),
-			BuildPath: buildPath, // Fly builds from the directory containing fly.toml
-			Configs: []types.ConfigRef{
-				{Type: "fly", Path: configPath},
-			},
+		name := f.filesystem.Base(buildPath) // Use directory name for consistency
+
+		// Without [processes] the app runs a single process group, "app"
+		processes := config.Processes
+		if len(processes) == 0 {
+			processes = map[string]string{"app": ""}
+		}
+
+		sorted := slices.Sorted(maps.Keys(processes))
+		primary := flyPrimaryProcess(config, sorted)
+		for _, process := range sorted {
+			service := types.Service{
+				Name:         name,
+				Network:      determineNetworkFromFly(config, process),
+				Runtime:      types.RuntimeContinuous, // Fly services are continuous
+				Build:        determineBuildFromFly(config),
+				BuildPath:    buildPath, // Fly builds from the directory containing fly.toml
+				StartCommand: processes[process],
+				Configs: []types.ConfigRef{
+					{Type: "fly", Path: configPath},
+				},
+			}
+			service.Port, _ = flyInternalPort(config, process)
+
+			if config.Build != nil && config.Build.Dockerfile != "" {
+				dockerfile := f.filesystem.Join(buildPath, config.Build.Dockerfile)
+				if pathExists(f.filesystem, &service, configPath, "build.dockerfile", dockerfile) {
+					service.Configs = append(service.Configs, types.ConfigRef{Type: "dockerfile", Path: dockerfile})
+				}
+			}
+			// The release command runs once a deploy, not once for each process group
+			if config.Deploy != nil && process == primary {
+				service.PreDeployCommand = config.Deploy.ReleaseCommand
+			}
+			for _, vm := range config.VM {
+				if appliesToProcess(vm.Processes, process) {
+					service.Resources = flyVMResources(vm)
+				}
+			}
+			// A volume attaches to a single service
+			for _, mount := range config.Mounts {
+				if flyMountOwner(mount, sorted, primary) == process {
+					service.Volumes = append(service.Volumes, types.Volume{Name: mount.Source, MountPath: mount.Destination})
+				}
+			}
+
+			// Each process group runs as its own service
+			if len(processes) > 1 {
+				service.Name = name + "-" + process
+			}
+
+			services = append(services, service)
 		}
-		services = append(services, service)
 	}
 
 	return services, nil
@@ -78,6 +131,8 @@ type FlyConfig struct {
 	Services      []FlyService      `toml:"services,omitempty"`
 	HTTPService   *FlyHTTPService   `toml:"http_service,omitempty"`
 	VM            []FlyVM           `toml:"vm,omitempty"`
+	Processes     map[string]string `toml:"processes,omitempty"`
+	Mounts        FlyMounts         `toml:"mounts,omitempty"`
 }
 
 type FlyBuild struct {
@@ -91,9 +146,54 @@ type FlyDeploy struct {
 	Strategy       string `toml:"strategy,omitempty"`
 }
 
+type FlyMount struct {
+	Source      string   `toml:"source"`
+	Destination string   `toml:"destination"`
+	Processes   []string `toml:"processes,omitempty"`
+}
+
+// FlyMounts accepts both a single [mounts] table and [[mounts]] arrays
+type FlyMounts []FlyMount
+
+func (m *FlyMounts) UnmarshalTOML(data any) error {
+	tables, ok := data.([]map[string]any)
+	if !ok {
+		switch value := data.(type) {
+		case map[string]any:
+			tables = []map[string]any{value}
+		case []any:
+			for _, item := range value {
+				table, ok := item.(map[string]any)
+				if !ok {
+					return fmt.Errorf("mounts entries must be tables, got %T", item)
+				}
+				tables = append(tables, table)
+			}
+		default:
+			return fmt.Errorf("mounts must be a table or array of tables, got %T", data)
+		}
+	}
+
+	for _, table := range tables {
+		mount := FlyMount{}
+		mount.Source, _ = table["source"].(string)
+		mount.Destination, _ = table["destination"].(string)
+		if processes, ok := table["processes"].([]any); ok {
+			for _, process := range processes {
+				if name, ok := process.(string); ok {
+					mount.Processes = append(mount.Processes, name)
+				}
+			}
+		}
+		*m = append(*m, mount)
+	}
+	return nil
+}
+
 type FlyService struct {
-	InternalPort int    `toml:"internal_port"`
-	Protocol     string `toml:"protocol,omitempty"`
+	InternalPort int      `toml:"internal_port"`
+	Protocol     string   `toml:"protocol,omitempty"`
+	Processes    []string `toml:"processes,omitempty"`
 }
 
 type FlyHTTPService struct {
@@ -106,9 +206,12 @@ type FlyHTTPService struct {
 }
 
 type FlyVM struct {
-	CPUKind  string `toml:"cpu_kind,omitempty"`
-	CPUs     int    `toml:"cpus,omitempty"`
-	MemoryMB int    `toml:"memory_mb,omitempty"`
+	Size      string   `toml:"size,omitempty"` // a preset, like shared-cpu-1x
+	Memory    string   `toml:"memory,omitempty"`
+	CPUKind   string   `toml:"cpu_kind,omitempty"`
+	CPUs      int      `toml:"cpus,omitempty"`
+	MemoryMB  int      `toml:"memory_mb,omitempty"`
+	Processes []string `toml:"processes,omitempty"`
 }
 
 func (f *FlySignal) parseFlyConfig(configPath string) (*FlyConfig, error) {
@@ -124,14 +227,9 @@ func (f *FlySignal) parseFlyConfig(configPath string) (*FlyConfig, error) {
 	return &config, nil
 }
 
-func determineNetworkFromFly(config *FlyConfig) types.Network {
-	// If there's an http_service, it's definitely public
-	if config.HTTPService != nil {
-		return types.NetworkPublic
-	}
-
-	// If there are services configured, likely public
-	if len(config.Services) > 0 {
+func determineNetworkFromFly(config *FlyConfig, process string) types.Network {
+	// Only process groups an http_service or [[services]] routes to are public
+	if _, routed := flyInternalPort(config, process); routed {
 		return types.NetworkPublic
 	}
 
@@ -139,6 +237,50 @@ func determineNetworkFromFly(config *FlyConfig) types.Network {
 	return types.NetworkPrivate
 }
 
+// flyInternalPort returns the port traffic is routed to for a process group and
+// whether any service routes to it at all
+func flyInternalPort(config *FlyConfig, process string) (int, bool) {
+	// Services without a processes list apply to every process group
+	if config.HTTPService != nil && appliesToProcess(config.HTTPService.Processes, process) {
+		return config.HTTPService.InternalPort, true
+	}
+	for _, service := range config.Services {
+		if appliesToProcess(service.Processes, process) {
+			return service.InternalPort, true
+		}
+	}
+	return 0, false
+}
+
+// flyPrimaryProcess is the process group deploys are run for: the first one traffic
+// is routed to, or the first one
+func flyPrimaryProcess(config *FlyConfig, processes []string) string {
+	for _, process := range processes {
+		if _, routed := flyInternalPort(config, process); routed {
+			return process
+		}
+	}
+	return processes[0]
+}
+
+// flyMountOwner is the process group a mount is attached to: the primary one if the
+// mount applies to it, or else the first it applies to
+func flyMountOwner(mount FlyMount, processes []string, primary string) string {
+	if appliesToProcess(mount.Processes, primary) {
+		return primary
+	}
+	for _, process := range processes {
+		if appliesToProcess(mount.Processes, process) {
+			return process
+		}
+	}
+	return ""
+}
+
+func appliesToProcess(processes []string, process string) bool {
+	return len(processes) == 0 || slices.Contains(processes, process)
+}
+
 func determineBuildFromFly(config *FlyConfig) types.Build {
 	// If there's a pre-built image specified, use that
 	if config.Build != nil && config.Build.Image != "" {
@@ -2,6 +2,7 @@ package signals
 
 import (
 	"context"
+	"slices"
 	"strings"
 
 	"github.com/railwayapp/turnout/internal/discovery/types"
@@ -43,7 +44,7 @@ func (f *FrameworkSignal) ObserveEntry(ctx context.Context, rootPath string, ent
 	case matchesAny(name, "nuxt.config.js", "nuxt.config.ts", "nuxt.config.mjs", "nuxt.config.cjs"):
 		framework = Framework{Name: "Nuxt.js", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	case matchesAny(name, "vite.config.js", "vite.config.ts", "vite.config.mjs", "vite.config.cjs"):
-		framework = Framework{Name: "Vite", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		framework = Framework{Name: "Vite", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
 	case matchesAny(name, "webpack.config.js", "webpack.config.ts", "webpack.config.mjs", "webpack.config.cjs"):
 		framework = Framework{Name: "Webpack", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	case matchesAny(name, "angular.json", ".angular-cli.json"):
@@ -55,13 +56,16 @@ func (f *FrameworkSignal) ObserveEntry(ctx context.Context, rootPath string, ent
 	case matchesAny(name, "remix.config.js", "remix.config.ts", "remix.config.mjs", "remix.config.cjs"):
 		framework = Framework{Name: "Remix", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	case matchesAny(name, "astro.config.js", "astro.config.ts", "astro.config.mjs", "astro.config.cjs"):
-		framework = Framework{Name: "Astro", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		framework = Framework{Name: "Astro", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
 	case matchesAny(name, "gatsby-config.js", "gatsby-config.ts", "gatsby-config.mjs", "gatsby-config.cjs"):
-		framework = Framework{Name: "Gatsby", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		framework = Framework{Name: "Gatsby", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
 	case name == "manage.py":
 		framework = Framework{Name: "Django", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	case name == "config.ru":
+		framework = Framework{Name: "Rack", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+	case name == "application.rb" && f.filesystem.Base(rootPath) == "config" && isRailsApp(f.filesystem, f.filesystem.Dir(rootPath)):
 		framework = Framework{Name: "Rails", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		rootPath = f.filesystem.Dir(rootPath) // The app is built from above config/
 	case name == "mix.exs":
 		framework = Framework{Name: "Phoenix", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	case name == "nest-cli.json":
@@ -69,9 +73,9 @@ func (f *FrameworkSignal) ObserveEntry(ctx context.Context, rootPath string, ent
 	case name == "artisan":
 		framework = Framework{Name: "Laravel", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	case matchesAny(name, "hugo.toml", "hugo.yaml"):
-		framework = Framework{Name: "Hugo", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		framework = Framework{Name: "Hugo", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
 	case matchesAny(name, ".eleventy.js", "eleventy.config.js", ".eleventy.config.js"):
-		framework = Framework{Name: "Eleventy", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		framework = Framework{Name: "Eleventy", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
 	case name == "Caddyfile":
 		framework = Framework{Name: "Caddy", ConfigPath: fullPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	default:
@@ -84,9 +88,37 @@ func (f *FrameworkSignal) ObserveEntry(ctx context.Context, rootPath string, ent
 }
 
 func (f *FrameworkSignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	frameworksPerDir := make(map[string]int)
+	for _, fw := range f.frameworks {
+		frameworksPerDir[f.configDirs[fw.ConfigPath]]++
+	}
+
 	var services []types.Service
 	for _, fw := range f.frameworks {
 		buildPath := f.configDirs[fw.ConfigPath]
+
+		// Vite next to another framework is just its bundler, not a standalone SPA
+		if fw.Name == "Vite" && frameworksPerDir[buildPath] > 1 {
+			continue
+		}
+		// On its own it may still be bundling a server framework through its plugin, like Remix
+		if fw.Name == "Vite" {
+			fw.Name, fw.Build = viteFramework(f.filesystem, buildPath)
+		}
+		// config.ru next to a Rails app is just how Rails boots under Rack
+		if fw.Name == "Rack" && slices.ContainsFunc(f.frameworks, func(other Framework) bool {
+			return other.Name == "Rails" && f.configDirs[other.ConfigPath] == buildPath
+		}) {
+			continue
+		}
+
+		// Umbrella apps build and release together, and ElixirSignal finds what each runs
+		if fw.Name == "Phoenix" {
+			if content, err := f.filesystem.ReadFile(fw.ConfigPath); err == nil && inUmbrella(string(content)) {
+				continue
+			}
+		}
+
 		service := types.Service{
 			Name:      f.filesystem.Base(buildPath),
 			Network:   fw.Network,
@@ -97,7 +129,27 @@ func (f *FrameworkSignal) GenerateServices(ctx context.Context) ([]types.Service
 				{Type: "framework", Path: fw.ConfigPath},
 			},
 		}
+
+		if service.Build == types.BuildStatic {
+			if outputDir, ok := staticOutputDir(f.filesystem, fw.Name, buildPath); ok {
+				service.OutputDir = outputDir
+			} else {
+				service.Build = types.BuildFromSource
+			}
+		}
+
+		applyFrameworkDefaults(&service, fw.Name)
 		services = append(services, service)
+
+		// Railway runs background job processes as services of their own
+		switch fw.Name {
+		case "Rails":
+			services = append(services, railsProcesses(f.filesystem, service)...)
+		case "Django":
+			if app, path, beat := celeryApp(f.filesystem, buildPath); app != "" {
+				services = append(services, celeryServices(service, app, path, beat)...)
+			}
+		}
 	}
 
 	return services, nil
@@ -0,0 +1,262 @@
+package signals
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+// Values files for an environment, e.g. values.prod.yaml or values-staging.yaml
+var helmValuesFilePattern = regexp.MustCompile(`^values[.-]([\w-]+)\.ya?ml$`)
+
+type HelmSignal struct {
+	filesystem filesystems.FileSystem
+	chartDirs  []string // directories containing Chart.yaml
+
+	// ValuesFiles are extra values files, relative to each chart, applied
+	// after the chart's defaults like `helm template -f`
+	ValuesFiles []string
+
+	environment string // values files layered over values.yaml, production unless set
+
+	skipped
+}
+
+func NewHelmSignal(filesystem filesystems.FileSystem) *HelmSignal {
+	return &HelmSignal{filesystem: filesystem}
+}
+
+func (h *HelmSignal) Confidence() int {
+	return 90 // High confidence - charts are production deployment specs, but values vary per environment
+}
+
+func (h *HelmSignal) Reset() {
+	h.skipped = skipped{}
+	h.chartDirs = nil
+}
+
+// Environments lists the environments charts have values files for
+func (h *HelmSignal) Environments() []string {
+	var environments []string
+	for _, chartDir := range h.chartDirs {
+		if h.isSubchart(chartDir) {
+			continue
+		}
+		for _, valuesFile := range h.environmentValuesFiles(chartDir) {
+			if !slices.Contains(environments, valuesFile.environment) {
+				environments = append(environments, valuesFile.environment)
+			}
+		}
+	}
+	return environments
+}
+
+func (h *HelmSignal) SetEnvironment(environment string) {
+	h.environment = environment
+}
+
+func (h *HelmSignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
+	if !entry.IsDir() && entry.Name() == "Chart.yaml" {
+		h.chartDirs = append(h.chartDirs, rootPath)
+	}
+	return nil
+}
+
+func (h *HelmSignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	var services []types.Service
+	for _, chartDir := range h.chartDirs {
+		// Subcharts are rendered as part of their parent
+		if h.isSubchart(chartDir) {
+			continue
+		}
+
+		rendered, err := h.renderChart(chartDir)
+		if err != nil {
+			h.skip(h.filesystem.Join(chartDir, "Chart.yaml"), err)
+			continue
+		}
+
+		chartPath := h.filesystem.Join(chartDir, "Chart.yaml")
+
+		// Well-known dependency charts become backing services rather than their rendered workloads
+		var implied impliedBackingServices
+		var backingPrefixes []string
+		for _, dependency := range rendered.dependencies {
+			if kind, ok := backingServiceKind(dependency.chart); ok {
+				implied.add(services, kind, types.ConfigRef{Type: "helm", Path: chartPath})
+				backingPrefixes = append(backingPrefixes, dependency.templatePrefix)
+			}
+		}
+
+		var objects []kubernetesObject
+		for _, name := range slices.Sorted(maps.Keys(rendered.manifests)) {
+			if !strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml") {
+				continue
+			}
+			if slices.ContainsFunc(backingPrefixes, func(prefix string) bool { return strings.HasPrefix(name, prefix) }) {
+				continue
+			}
+			objects = append(objects, parseKubernetesManifests([]byte(rendered.manifests[name]))...)
+		}
+
+		for _, workload := range analyzeKubernetesWorkloads(objects) {
+			services = append(services, kubernetesWorkloadService(workload, types.ConfigRef{Type: "helm", Path: chartPath}))
+		}
+		services = implied.appendTo(services)
+	}
+
+	return services, nil
+}
+
+// isSubchart reports whether a chart is vendored into another chart's charts/ directory
+func (h *HelmSignal) isSubchart(chartDir string) bool {
+	parent := h.filesystem.Dir(chartDir)
+	return h.filesystem.Base(parent) == "charts" && slices.Contains(h.chartDirs, h.filesystem.Dir(parent))
+}
+
+// renderedChart is the output of `helm template` for a chart
+type renderedChart struct {
+	manifests    map[string]string // keyed by template path
+	dependencies []helmDependency  // enabled dependencies, including those of subcharts
+}
+
+type helmDependency struct {
+	chart          string
+	templatePrefix string // where the dependency's templates are rendered, if vendored
+}
+
+// renderChart renders a chart's templates like `helm template`
+func (h *HelmSignal) renderChart(chartDir string) (*renderedChart, error) {
+	environment := h.environment
+	if environment == "" {
+		environment = "production"
+	}
+	var valuesFiles []string
+	for _, valuesFile := range h.environmentValuesFiles(chartDir) {
+		if valuesFile.environment == environment {
+			valuesFiles = append(valuesFiles, valuesFile.name)
+		}
+	}
+	return h.renderChartWithValues(chartDir, append(valuesFiles, h.ValuesFiles...), nil)
+}
+
+type helmValuesFile struct {
+	name        string // relative to the chart
+	environment string
+}
+
+// environmentValuesFiles lists a chart's values files for environments, sorted by name
+func (h *HelmSignal) environmentValuesFiles(chartDir string) []helmValuesFile {
+	paths, err := h.filesystem.Glob(h.filesystem.Join(chartDir, "values*"))
+	if err != nil {
+		return nil
+	}
+	var valuesFiles []helmValuesFile
+	for _, path := range paths {
+		name := h.filesystem.Base(path)
+		if match := helmValuesFilePattern.FindStringSubmatch(name); match != nil {
+			valuesFiles = append(valuesFiles, helmValuesFile{name: name, environment: types.NormalizeEnvironment(match[1])})
+		}
+	}
+	return valuesFiles
+}
+
+// renderChartWithValues renders a chart with values files, relative to the chart, and
+// values like `--set` layered over its defaults
+func (h *HelmSignal) renderChartWithValues(chartDir string, valuesFiles []string, setValues map[string]any) (*renderedChart, error) {
+	ch, err := h.loadChart(chartDir)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := map[string]any{}
+	for _, name := range valuesFiles {
+		data, err := h.filesystem.ReadFile(h.filesystem.Join(chartDir, name))
+		if err != nil {
+			continue
+		}
+		values, err := chartutil.ReadValues(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid values file %s: %w", name, err)
+		}
+		overrides = chartutil.CoalesceTables(values.AsMap(), overrides)
+	}
+	overrides = chartutil.CoalesceTables(setValues, overrides)
+
+	// Processing dependencies replaces their names with aliases, so remember the charts first
+	chartNames := make(map[*chart.Dependency]string)
+	recordDependencyCharts(ch, chartNames)
+	if err := chartutil.ProcessDependenciesWithMerge(ch, overrides); err != nil {
+		return nil, err
+	}
+
+	options := chartutil.ReleaseOptions{Name: ch.Name(), Namespace: "default", Revision: 1, IsInstall: true}
+	values, err := chartutil.ToRenderValues(ch, overrides, options, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, err := engine.Render(ch, values)
+	if err != nil {
+		return nil, err
+	}
+	return &renderedChart{manifests: manifests, dependencies: enabledDependencies(ch, chartNames, ch.Name())}, nil
+}
+
+func recordDependencyCharts(ch *chart.Chart, chartNames map[*chart.Dependency]string) {
+	for _, dependency := range ch.Metadata.Dependencies {
+		chartNames[dependency] = dependency.Name
+	}
+	for _, subchart := range ch.Dependencies() {
+		recordDependencyCharts(subchart, chartNames)
+	}
+}
+
+// enabledDependencies lists the dependencies left after conditions and tags are applied
+func enabledDependencies(ch *chart.Chart, chartNames map[*chart.Dependency]string, templatePath string) []helmDependency {
+	var dependencies []helmDependency
+	for _, dependency := range ch.Metadata.Dependencies {
+		dependencies = append(dependencies, helmDependency{
+			chart:          chartNames[dependency],
+			templatePrefix: templatePath + "/charts/" + dependency.Name + "/",
+		})
+	}
+	for _, subchart := range ch.Dependencies() {
+		dependencies = append(dependencies, enabledDependencies(subchart, chartNames, templatePath+"/charts/"+subchart.Name())...)
+	}
+	return dependencies
+}
+
+// loadChart reads every file of a chart through the filesystem so remote sources work too
+func (h *HelmSignal) loadChart(chartDir string) (*chart.Chart, error) {
+	var files []*loader.BufferedFile
+	err := h.filesystem.Walk(chartDir, func(path string, info filesystems.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := h.filesystem.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := h.filesystem.Rel(chartDir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, &loader.BufferedFile{Name: strings.ReplaceAll(rel, "\\", "/"), Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loader.LoadFiles(files)
+}
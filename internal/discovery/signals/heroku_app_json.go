@@ -13,6 +13,8 @@ type HerokuAppJsonSignal struct {
 	filesystem  filesystems.FileSystem
 	configPaths []string          // all found app.json files
 	configDirs  map[string]string // config path -> directory path
+
+	skipped
 }
 
 func NewHerokuAppJsonSignal(filesystem filesystems.FileSystem) *HerokuAppJsonSignal {
@@ -24,6 +26,7 @@ func (h *HerokuAppJsonSignal) Confidence() int {
 }
 
 func (h *HerokuAppJsonSignal) Reset() {
+	h.skipped = skipped{}
 	h.configPaths = nil
 	h.configDirs = make(map[string]string)
 }
@@ -46,6 +49,7 @@ func (h *HerokuAppJsonSignal) GenerateServices(ctx context.Context) ([]types.Ser
 	configPath := h.configPaths[0]
 	config, err := h.parseAppJson(configPath)
 	if err != nil {
+		h.skip(configPath, err)
 		return nil, err
 	}
 
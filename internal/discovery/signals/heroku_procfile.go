@@ -13,6 +13,8 @@ type HerokuProcfileSignal struct {
 	filesystem  filesystems.FileSystem
 	configPaths []string          // all found Procfile files
 	configDirs  map[string]string // config path -> directory path
+
+	skipped
 }
 
 func NewHerokuProcfileSignal(filesystem filesystems.FileSystem) *HerokuProcfileSignal {
@@ -24,6 +26,7 @@ func (h *HerokuProcfileSignal) Confidence() int {
 }
 
 func (h *HerokuProcfileSignal) Reset() {
+	h.skipped = skipped{}
 	h.configPaths = nil
 	h.configDirs = make(map[string]string)
 }
@@ -43,38 +46,65 @@ func (h *HerokuProcfileSignal) GenerateServices(ctx context.Context) ([]types.Se
 		return nil, nil
 	}
 
-	configPath := h.configPaths[0]
-	processes, err := h.parseProcfile(configPath)
-	if err != nil {
-		return nil, err
-	}
-
-	buildPath := h.configDirs[configPath]
 	var services []types.Service
-	for processType, command := range processes {
-		service := types.Service{
-			Name:      processType,
-			Network:   determineNetworkFromProcfile(processType),
-			Runtime:   determineRuntimeFromProcfile(processType, command),
-			Build:     types.BuildFromSource, // Heroku builds from source
-			BuildPath: buildPath,
-			Configs: []types.ConfigRef{
-				{Type: "procfile", Path: configPath},
-			},
+	for _, configPath := range h.configPaths {
+		processes, err := h.parseProcfile(configPath)
+		if err != nil {
+			h.skip(configPath, err)
+			continue
+		}
+
+		// release runs once before each deploy rather than as its own process
+		var release string
+		for _, process := range processes {
+			if process.Type == "release" {
+				release = process.Command
+			}
+		}
+
+		buildPath := h.configDirs[configPath]
+		for _, process := range processes {
+			if process.Type == "release" {
+				continue
+			}
+
+			service := types.Service{
+				Name:             process.Type,
+				Network:          determineNetworkFromProcfile(process.Type),
+				Runtime:          determineRuntimeFromProcfile(process.Type, process.Command),
+				Build:            types.BuildFromSource, // Heroku builds from source
+				BuildPath:        buildPath,
+				StartCommand:     process.Command,
+				PreDeployCommand: release,
+				Configs: []types.ConfigRef{
+					{Type: "procfile", Path: configPath},
+				},
+			}
+
+			// Monorepos have a Procfile per app, so qualify process names with the app directory
+			if len(h.configPaths) > 1 {
+				service.Name = h.filesystem.Base(buildPath) + "-" + process.Type
+			}
+
+			services = append(services, service)
 		}
-		services = append(services, service)
 	}
 
 	return services, nil
 }
 
-func (h *HerokuProcfileSignal) parseProcfile(configPath string) (map[string]string, error) {
+type procfileProcess struct {
+	Type    string
+	Command string
+}
+
+func (h *HerokuProcfileSignal) parseProcfile(configPath string) ([]procfileProcess, error) {
 	content, err := h.filesystem.ReadFile(configPath)
 	if err != nil {
 		return nil, err
 	}
 
-	processes := make(map[string]string)
+	var processes []procfileProcess
 	scanner := bufio.NewScanner(strings.NewReader(string(content)))
 
 	for scanner.Scan() {
@@ -88,9 +118,10 @@ func (h *HerokuProcfileSignal) parseProcfile(configPath string) (map[string]stri
 			continue
 		}
 
-		processType := strings.TrimSpace(parts[0])
-		command := strings.TrimSpace(parts[1])
-		processes[processType] = command
+		processes = append(processes, procfileProcess{
+			Type:    strings.TrimSpace(parts[0]),
+			Command: strings.TrimSpace(parts[1]),
+		})
 	}
 
 	return processes, scanner.Err()
@@ -0,0 +1,384 @@
+package signals
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"gopkg.in/yaml.v3"
+)
+
+// kubernetesObject is the subset of a Kubernetes manifest needed to find workloads and how they're exposed
+type kubernetesObject struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name   string            `yaml:"name"`
+		Labels map[string]string `yaml:"labels"`
+	} `yaml:"metadata"`
+	Spec kubernetesSpec `yaml:"spec"`
+}
+
+type kubernetesSpec struct {
+	// Workloads
+	Template    *kubernetesPodTemplate `yaml:"template"`
+	JobTemplate *struct {
+		Spec struct {
+			Template *kubernetesPodTemplate `yaml:"template"`
+		} `yaml:"spec"`
+	} `yaml:"jobTemplate"`
+	Schedule string `yaml:"schedule"`
+
+	// Pods, like those `podman kube play` runs
+	Containers []kubernetesContainer `yaml:"containers"`
+
+	// Services
+	Type     string           `yaml:"type"`
+	Selector yaml.Node        `yaml:"selector"` // map for Services, matchLabels for workloads
+	Ports    []kubernetesPort `yaml:"ports"`
+
+	// Ingresses and Gateway API routes
+	Rules          []kubernetesIngressRule `yaml:"rules"`
+	DefaultBackend *kubernetesBackend      `yaml:"defaultBackend"`
+
+	// NetworkPolicies
+	PodSelector  kubernetesLabelSelector `yaml:"podSelector"`
+	PolicyTypes  []string                `yaml:"policyTypes"`
+	IngressRules []struct {
+		From []struct {
+			PodSelector *kubernetesLabelSelector `yaml:"podSelector"`
+		} `yaml:"from"`
+	} `yaml:"ingress"`
+}
+
+// kubernetesLabelSelector selects pods by their labels. Only matchLabels is read;
+// selectors that only use matchExpressions match every pod.
+type kubernetesLabelSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+type kubernetesPodTemplate struct {
+	Metadata struct {
+		Labels map[string]string `yaml:"labels"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Containers []kubernetesContainer `yaml:"containers"`
+	} `yaml:"spec"`
+}
+
+type kubernetesContainer struct {
+	Name    string           `yaml:"name"`
+	Image   string           `yaml:"image"`
+	Command []string         `yaml:"command"`
+	Args    []string         `yaml:"args"`
+	Ports   []kubernetesPort `yaml:"ports"`
+
+	Resources struct {
+		Limits   map[string]string `yaml:"limits"`
+		Requests map[string]string `yaml:"requests"`
+	} `yaml:"resources"`
+}
+
+type kubernetesPort struct {
+	Port          int       `yaml:"port"`
+	ContainerPort int       `yaml:"containerPort"`
+	TargetPort    yaml.Node `yaml:"targetPort"`
+}
+
+type kubernetesIngressRule struct {
+	HTTP *struct {
+		Paths []struct {
+			Backend kubernetesBackend `yaml:"backend"`
+		} `yaml:"paths"`
+	} `yaml:"http"`
+
+	// HTTPRoute rules reference services directly
+	BackendRefs []struct {
+		Name string `yaml:"name"`
+	} `yaml:"backendRefs"`
+}
+
+type kubernetesBackend struct {
+	Service *struct {
+		Name string `yaml:"name"`
+	} `yaml:"service"`
+	ServiceName string `yaml:"serviceName"` // networking.k8s.io/v1beta1
+}
+
+// kubernetesWorkload is a pod-running object and what we learned about it from the other manifests
+type kubernetesWorkload struct {
+	Name     string
+	Kind     string
+	Image    string // image of the first container
+	Command  string
+	Port     int
+	Schedule string // only for CronJobs
+	Public   bool   // exposed by an Ingress, HTTPRoute, LoadBalancer or NodePort
+	Exposed  bool   // selected by any Service
+
+	Resources *types.Resources // the first container's
+	Labels    map[string]string
+	Ingress   *types.IngressPolicy // set when a NetworkPolicy restricts who connects
+}
+
+var workloadKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+	"ReplicaSet":  true,
+	"Job":         true,
+	"CronJob":     true,
+	"Pod":         true,
+}
+
+// parseKubernetesManifests decodes every document in a multi-document YAML stream,
+// skipping documents that aren't objects
+func parseKubernetesManifests(content []byte) []kubernetesObject {
+	var objects []kubernetesObject
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var object kubernetesObject
+		err := decoder.Decode(&object)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// A broken document ends the stream; keep what we decoded so far
+			break
+		}
+		if object.Kind != "" {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+// analyzeKubernetesWorkloads finds workloads and works out which are exposed by Services and Ingresses
+func analyzeKubernetesWorkloads(objects []kubernetesObject) []kubernetesWorkload {
+	// Services routed to from outside the cluster
+	publicServices := make(map[string]bool)
+	for _, object := range objects {
+		switch object.Kind {
+		case "Ingress":
+			if backend := object.Spec.DefaultBackend; backend != nil {
+				publicServices[backend.serviceName()] = true
+			}
+			for _, rule := range object.Spec.Rules {
+				if rule.HTTP == nil {
+					continue
+				}
+				for _, path := range rule.HTTP.Paths {
+					publicServices[path.Backend.serviceName()] = true
+				}
+			}
+		case "HTTPRoute", "GRPCRoute", "TLSRoute":
+			for _, rule := range object.Spec.Rules {
+				for _, ref := range rule.BackendRefs {
+					publicServices[ref.Name] = true
+				}
+			}
+		case "Service":
+			if object.Spec.Type == "LoadBalancer" || object.Spec.Type == "NodePort" {
+				publicServices[object.Metadata.Name] = true
+			}
+		}
+	}
+
+	var workloads []kubernetesWorkload
+	for _, object := range objects {
+		if !workloadKinds[object.Kind] {
+			continue
+		}
+
+		template := object.Spec.Template
+		switch {
+		case object.Kind == "CronJob" && object.Spec.JobTemplate != nil:
+			template = object.Spec.JobTemplate.Spec.Template
+		case object.Kind == "Pod":
+			template = &kubernetesPodTemplate{}
+			template.Metadata.Labels = object.Metadata.Labels
+			template.Spec.Containers = object.Spec.Containers
+		}
+		if template == nil || len(template.Spec.Containers) == 0 {
+			continue
+		}
+
+		container := template.Spec.Containers[0]
+		workload := kubernetesWorkload{
+			Name:    object.Metadata.Name,
+			Kind:    object.Kind,
+			Image:   container.Image,
+			Command: strings.Join(append(append([]string{}, container.Command...), container.Args...), " "),
+
+			Resources: kubernetesResources(container.Resources.Limits, container.Resources.Requests),
+			Labels:    template.Metadata.Labels,
+		}
+		if object.Kind == "CronJob" {
+			workload.Schedule = object.Spec.Schedule
+		}
+		if len(container.Ports) > 0 {
+			workload.Port = container.Ports[0].ContainerPort
+		}
+
+		for _, service := range objects {
+			if service.Kind != "Service" || !selects(service.Spec.Selector, template.Metadata.Labels) {
+				continue
+			}
+			workload.Exposed = true
+			if publicServices[service.Metadata.Name] {
+				workload.Public = true
+			}
+			if workload.Port == 0 && len(service.Spec.Ports) > 0 {
+				workload.Port = service.Spec.Ports[0].targetPort()
+			}
+		}
+
+		workloads = append(workloads, workload)
+	}
+
+	for _, object := range objects {
+		if object.Kind == "NetworkPolicy" {
+			applyNetworkPolicy(object.Spec, workloads)
+		}
+	}
+	return workloads
+}
+
+// applyNetworkPolicy restricts which workloads may connect to the ones a NetworkPolicy
+// selects. Policies are additive, so each one adds to the workloads allowed in. Peers
+// outside the manifests, like IP blocks and other namespaces, aren't services to allow.
+func applyNetworkPolicy(policy kubernetesSpec, workloads []kubernetesWorkload) {
+	// Policies without policyTypes always restrict ingress
+	if len(policy.PolicyTypes) > 0 && !slices.Contains(policy.PolicyTypes, "Ingress") {
+		return
+	}
+
+	var allowed []string
+	for _, rule := range policy.IngressRules {
+		for _, workload := range workloads {
+			// A rule without peers allows every source
+			if len(rule.From) == 0 {
+				allowed = append(allowed, workload.Name)
+				continue
+			}
+			for _, peer := range rule.From {
+				if peer.PodSelector != nil && matchesLabelSelector(*peer.PodSelector, workload.Labels) {
+					allowed = append(allowed, workload.Name)
+					break
+				}
+			}
+		}
+	}
+
+	for i := range workloads {
+		workload := &workloads[i]
+		if !matchesLabelSelector(policy.PodSelector, workload.Labels) {
+			continue
+		}
+		if workload.Ingress == nil {
+			workload.Ingress = &types.IngressPolicy{}
+		}
+		for _, name := range allowed {
+			if name != workload.Name && !slices.Contains(workload.Ingress.From, name) {
+				workload.Ingress.From = append(workload.Ingress.From, name)
+			}
+		}
+		slices.Sort(workload.Ingress.From)
+	}
+}
+
+// kubernetesWorkloadService converts a workload into a service
+func kubernetesWorkloadService(workload kubernetesWorkload, config types.ConfigRef) types.Service {
+	service := types.Service{
+		Name:         workload.Name,
+		Network:      types.NetworkNone,
+		Runtime:      types.RuntimeContinuous,
+		Build:        types.BuildFromImage,
+		Image:        workload.Image,
+		Port:         workload.Port,
+		StartCommand: workload.Command,
+		Schedule:     workload.Schedule,
+		Resources:    workload.Resources,
+		Ingress:      workload.Ingress,
+		Configs:      []types.ConfigRef{config},
+	}
+
+	switch {
+	case workload.Public:
+		service.Network = types.NetworkPublic
+	case workload.Exposed:
+		service.Network = types.NetworkPrivate
+	}
+
+	// Jobs run to completion, CronJobs on a schedule
+	if workload.Kind == "CronJob" || workload.Kind == "Job" {
+		service.Runtime = types.RuntimeScheduled
+	}
+
+	return service
+}
+
+// workloadForImage finds the workload running an image, ignoring tags and digests
+func workloadForImage(workloads []kubernetesWorkload, image string) (kubernetesWorkload, bool) {
+	for _, workload := range workloads {
+		if imageRepository(workload.Image) == imageRepository(image) {
+			return workload, true
+		}
+	}
+	return kubernetesWorkload{}, false
+}
+
+// imageRepository strips the tag and digest from an image reference
+func imageRepository(image string) string {
+	ref, _, _ := strings.Cut(image, "@")
+	if slash, colon := strings.LastIndex(ref, "/"), strings.LastIndex(ref, ":"); colon > slash {
+		ref = ref[:colon]
+	}
+	return ref
+}
+
+func (b kubernetesBackend) serviceName() string {
+	if b.Service != nil {
+		return b.Service.Name
+	}
+	return b.ServiceName
+}
+
+// targetPort returns the numeric port a Service forwards to
+func (p kubernetesPort) targetPort() int {
+	if port, err := strconv.Atoi(p.TargetPort.Value); err == nil {
+		return port
+	}
+	return p.Port
+}
+
+// matchesLabelSelector reports whether a label selector, like a NetworkPolicy's
+// podSelector, matches a pod's labels. An empty selector matches every pod.
+func matchesLabelSelector(selector kubernetesLabelSelector, labels map[string]string) bool {
+	for key, value := range selector.MatchLabels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// selects reports whether a Service selector matches a pod's labels
+func selects(selector yaml.Node, labels map[string]string) bool {
+	if selector.Kind == 0 {
+		return false
+	}
+	var matchLabels map[string]string
+	if err := selector.Decode(&matchLabels); err != nil || len(matchLabels) == 0 {
+		return false
+	}
+	for key, value := range matchLabels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
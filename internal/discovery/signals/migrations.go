@@ -0,0 +1,122 @@
+package signals
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+var (
+	prismaProviderPattern    = regexp.MustCompile(`(?s)datasource\s+\w+\s*\{[^}]*provider\s*=\s*"(\w+)"`)
+	drizzleDialectPattern    = regexp.MustCompile(`(?:dialect|driver)\s*:\s*['"](\w+)['"]`)
+	railsAdapterPattern      = regexp.MustCompile(`adapter:\s*(\w+)`)
+	databaseURLSchemePattern = regexp.MustCompile(`(?:jdbc:)?(postgres(?:ql)?|mysql|mariadb|sqlite)\b`)
+)
+
+// MigrationSignal implies a database service from schema migrations when the
+// project doesn't declare one. Implied services are marked Derived.
+type MigrationSignal struct {
+	filesystem filesystems.FileSystem
+	evidence   []migrationEvidence
+}
+
+type migrationEvidence struct {
+	tool     string // "prisma", "drizzle", "alembic", "rails", "flyway", "golang-migrate"
+	path     string
+	database string // "postgres", "mysql", or empty if unknown
+}
+
+func NewMigrationSignal(filesystem filesystems.FileSystem) *MigrationSignal {
+	return &MigrationSignal{filesystem: filesystem}
+}
+
+func (m *MigrationSignal) Confidence() int {
+	return 40 // Low confidence - the database is implied, not declared
+}
+
+func (m *MigrationSignal) Reset() {
+	m.evidence = nil
+}
+
+func (m *MigrationSignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
+	name := entry.Name()
+	fullPath := m.filesystem.Join(rootPath, name)
+	dirName := m.filesystem.Base(rootPath)
+
+	var evidence migrationEvidence
+	switch {
+	case !entry.IsDir() && name == "schema.prisma":
+		evidence = migrationEvidence{tool: "prisma", path: fullPath, database: m.databaseFromPattern(fullPath, prismaProviderPattern)}
+	case !entry.IsDir() && matchesAny(name, "drizzle.config.ts", "drizzle.config.js", "drizzle.config.mjs"):
+		evidence = migrationEvidence{tool: "drizzle", path: fullPath, database: m.databaseFromPattern(fullPath, drizzleDialectPattern)}
+	case !entry.IsDir() && name == "alembic.ini":
+		evidence = migrationEvidence{tool: "alembic", path: fullPath, database: m.databaseFromPattern(fullPath, databaseURLSchemePattern)}
+	case !entry.IsDir() && matchesAny(name, "flyway.conf", "flyway.toml"):
+		evidence = migrationEvidence{tool: "flyway", path: fullPath, database: m.databaseFromPattern(fullPath, databaseURLSchemePattern)}
+	case entry.IsDir() && name == "migrate" && dirName == "db":
+		// Rails keeps migrations in db/migrate and the adapter in config/database.yml
+		databaseYml := m.filesystem.Join(m.filesystem.Dir(rootPath), "config", "database.yml")
+		evidence = migrationEvidence{tool: "rails", path: fullPath, database: m.databaseFromPattern(databaseYml, railsAdapterPattern)}
+	case entry.IsDir() && name == "migration" && dirName == "db":
+		// Flyway's default location, src/main/resources/db/migration
+		evidence = migrationEvidence{tool: "flyway", path: fullPath}
+	case entry.IsDir() && name == "migrations" && m.hasUpDownMigrations(fullPath):
+		evidence = migrationEvidence{tool: "golang-migrate", path: fullPath}
+	default:
+		return nil
+	}
+
+	m.evidence = append(m.evidence, evidence)
+	return nil
+}
+
+func (m *MigrationSignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	return nil, nil
+}
+
+// RefineServices adds a private database service for migrations that target a
+// database none of the discovered services provide
+func (m *MigrationSignal) RefineServices(ctx context.Context, services []types.Service) []types.Service {
+	var implied impliedBackingServices
+	for _, evidence := range m.evidence {
+		database := evidence.database
+		if database == "" {
+			database = "postgres" // The common case when the migrations don't say
+		}
+		implied.add(services, database, types.ConfigRef{Type: evidence.tool, Path: evidence.path})
+	}
+	return implied.appendTo(services)
+}
+
+func (m *MigrationSignal) databaseFromPattern(path string, pattern *regexp.Regexp) string {
+	content, err := filesystems.ReadTextFile(m.filesystem, path)
+	if err != nil {
+		return ""
+	}
+	match := pattern.FindSubmatch(content)
+	if match == nil {
+		return ""
+	}
+	return normalizeDatabase(string(match[1]))
+}
+
+// hasUpDownMigrations reports whether a directory holds golang-migrate style *.up.sql files
+func (m *MigrationSignal) hasUpDownMigrations(dir string) bool {
+	migrations, err := m.filesystem.Glob(m.filesystem.Join(dir, "*.up.sql"))
+	return err == nil && len(migrations) > 0
+}
+
+// normalizeDatabase maps provider and adapter names to the database they run on.
+// Providers without a database service image, like SQLite, are returned as-is.
+func normalizeDatabase(provider string) string {
+	switch provider = strings.ToLower(provider); provider {
+	case "postgresql", "postgres", "pg", "postgis":
+		return "postgres"
+	case "mysql", "mysql2", "mariadb", "trilogy":
+		return "mysql"
+	}
+	return provider
+}
@@ -1,6 +1,7 @@
 package signals
 
 import (
+	"cmp"
 	"context"
 	"strings"
 
@@ -13,6 +14,8 @@ type NetlifySignal struct {
 	filesystem  filesystems.FileSystem
 	configPaths []string          // all found netlify.toml files
 	configDirs  map[string]string // config path -> directory path
+
+	skipped
 }
 
 func NewNetlifySignal(filesystem filesystems.FileSystem) *NetlifySignal {
@@ -24,6 +27,7 @@ func (n *NetlifySignal) Confidence() int {
 }
 
 func (n *NetlifySignal) Reset() {
+	n.skipped = skipped{}
 	n.configPaths = nil
 	n.configDirs = make(map[string]string)
 }
@@ -43,26 +47,83 @@ func (n *NetlifySignal) GenerateServices(ctx context.Context) ([]types.Service,
 		return nil, nil
 	}
 
-	configPath := n.configPaths[0]
-	_, err := n.parseNetlifyConfig(configPath)
-	if err != nil {
-		return nil, err
+	var services []types.Service
+	for _, configPath := range n.configPaths {
+		config, err := n.parseNetlifyConfig(configPath)
+		if err != nil {
+			n.skip(configPath, err)
+			continue
+		}
+
+		build := config.Build
+		if build == nil {
+			build = &NetlifyBuild{}
+		}
+
+		// build.base is where Netlify runs the build; publish and functions are relative to it
+		buildPath, err := repoDir(n.filesystem, n.configDirs[configPath], "build.base", build.Base)
+		if err != nil {
+			n.skip(configPath, err)
+			continue
+		}
+		name := n.filesystem.Base(buildPath)
+		configs := []types.ConfigRef{{Type: "netlify", Path: configPath}}
+
+		// Netlify deploys are typically static sites
+		service := types.Service{
+			Name:      name,
+			Network:   types.NetworkPublic,     // Static sites are web-facing
+			Runtime:   types.RuntimeContinuous, // CDN serves continuously
+			Build:     types.BuildFromSource,   // Netlify builds from source
+			BuildPath: buildPath,
+			Configs:   configs,
+		}
+		if publish := strings.Trim(build.Publish, "/"); publish != "" {
+			service.Build = types.BuildStatic
+			service.OutputDir = publish
+		}
+		services = append(services, service)
+
+		functionDirs := []struct {
+			suffix string
+			dir    string
+		}{
+			{"functions", n.functionsDir(buildPath, build.Functions, "netlify/functions")},
+			{"edge-functions", n.functionsDir(buildPath, build.EdgeFunctions, "netlify/edge-functions")},
+		}
+		for _, functions := range functionDirs {
+			if functions.dir == "" {
+				continue
+			}
+			services = append(services, types.Service{
+				Name:      name + "-" + functions.suffix,
+				Network:   types.NetworkPublic, // Functions are invoked over HTTP
+				Runtime:   types.RuntimeContinuous,
+				Build:     types.BuildFromSource,
+				BuildPath: functions.dir,
+				Configs:   configs,
+			})
+		}
 	}
 
-	buildPath := n.configDirs[configPath]
-	// Netlify deploys are typically single-service static sites
-	service := types.Service{
-		Name:      n.filesystem.Base(buildPath),
-		Network:   types.NetworkPublic,     // Static sites are web-facing
-		Runtime:   types.RuntimeContinuous, // CDN serves continuously
-		Build:     types.BuildFromSource,   // Netlify builds from source
-		BuildPath: buildPath,
-		Configs: []types.ConfigRef{
-			{Type: "netlify", Path: configPath},
-		},
+	return services, nil
+}
+
+// functionsDir resolves a functions directory, returning "" when it doesn't exist or is
+// outside the repository
+func (n *NetlifySignal) functionsDir(buildPath, configured, fallback string) string {
+	dir, err := repoDir(n.filesystem, buildPath, "functions", cmp.Or(strings.Trim(configured, "/"), fallback))
+	if err != nil {
+		return ""
 	}
 
-	return []types.Service{service}, nil
+	for _, err := range n.filesystem.ReadDir(dir) {
+		if err != nil {
+			return ""
+		}
+		return dir
+	}
+	return ""
 }
 
 // NetlifyConfig represents the netlify.toml configuration structure
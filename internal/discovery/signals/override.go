@@ -0,0 +1,198 @@
+package signals
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"gopkg.in/yaml.v3"
+)
+
+// Confidence for values the user set in turnout.yaml, which beat every signal
+const overrideConfidence = 100
+
+// OverrideSignal applies a turnout.yaml in the repo root to the discovered services:
+// fields it sets win over every signal, and services it lists that weren't discovered
+// are added. It must be the last refiner so nothing changes the services after it.
+type OverrideSignal struct {
+	filesystem filesystems.FileSystem
+	rootPath   string // first directory observed, where turnout.yaml lives
+	path       string // turnout.yaml path, if found
+}
+
+// turnoutFile is the turnout.yaml schema
+type turnoutFile struct {
+	// Services are keyed by discovered service name, or the name of a service to add
+	Services map[string]serviceOverride `yaml:"services"`
+}
+
+type serviceOverride struct {
+	Ignore bool `yaml:"ignore"` // drop the discovered service
+
+	Name             string            `yaml:"name"`
+	BuildPath        string            `yaml:"buildPath"` // relative to the repo root
+	Image            string            `yaml:"image"`
+	Network          string            `yaml:"network"` // public, private or none
+	Runtime          string            `yaml:"runtime"` // continuous or scheduled
+	Schedule         string            `yaml:"schedule"`
+	Port             int               `yaml:"port"`
+	StartCommand     string            `yaml:"startCommand"`
+	PreDeployCommand string            `yaml:"preDeployCommand"`
+	HealthcheckPath  string            `yaml:"healthcheckPath"`
+	Replicas         int               `yaml:"replicas"`
+	Region           string            `yaml:"region"`
+	Env              map[string]string `yaml:"env"`
+}
+
+func NewOverrideSignal(filesystem filesystems.FileSystem) *OverrideSignal {
+	return &OverrideSignal{filesystem: filesystem}
+}
+
+func (o *OverrideSignal) Confidence() int {
+	return overrideConfidence
+}
+
+func (o *OverrideSignal) Reset() {
+	o.rootPath = ""
+	o.path = ""
+}
+
+func (o *OverrideSignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
+	if o.rootPath == "" {
+		o.rootPath = rootPath
+	}
+	if rootPath == o.rootPath && !entry.IsDir() && matchesAny(entry.Name(), "turnout.yaml", "turnout.yml") {
+		o.path = o.filesystem.Join(rootPath, entry.Name())
+	}
+	return nil
+}
+
+func (o *OverrideSignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	return nil, nil
+}
+
+// RefineServices applies the overrides to the services they name and adds the rest
+func (o *OverrideSignal) RefineServices(ctx context.Context, services []types.Service) []types.Service {
+	if o.path == "" {
+		return services
+	}
+	content, err := o.filesystem.ReadFile(o.path)
+	if err != nil {
+		return services
+	}
+	var file turnoutFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return services
+	}
+
+	matched := make(map[string]bool)
+	var refined []types.Service
+	for _, service := range services {
+		override, ok := file.Services[service.Name]
+		if !ok {
+			refined = append(refined, service)
+			continue
+		}
+		matched[service.Name] = true
+		if override.Ignore {
+			continue
+		}
+		o.apply(&service, override)
+		refined = append(refined, service)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(file.Services)) {
+		override := file.Services[name]
+		if matched[name] || override.Ignore || (override.BuildPath == "" && override.Image == "") {
+			continue
+		}
+		service := types.Service{Name: name, Network: types.NetworkNone, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		if override.Image != "" {
+			service.Build = types.BuildFromImage
+		}
+		if override.Port != 0 {
+			service.Network = types.NetworkPrivate
+		}
+		o.apply(&service, override)
+		refined = append(refined, service)
+	}
+
+	return refined
+}
+
+func (o *OverrideSignal) apply(service *types.Service, override serviceOverride) {
+	source := "override:" + o.path
+	set := func(field string) {
+		service.Pin(field)
+		service.SetProvenance(field, source, overrideConfidence)
+	}
+
+	if override.Name != "" {
+		service.Name = override.Name
+		set("Name")
+	}
+	if override.BuildPath != "" {
+		service.BuildPath = o.filesystem.Join(o.rootPath, strings.TrimPrefix(override.BuildPath, "./"))
+		set("BuildPath")
+	}
+	if override.Image != "" {
+		service.Image = override.Image
+		service.Build = types.BuildFromImage
+		set("Image")
+	}
+	if network, ok := parseNetworkHint(override.Network); ok {
+		service.Network = network
+		set("Network")
+	}
+	switch strings.ToLower(override.Runtime) {
+	case "scheduled", "cron":
+		service.Runtime = types.RuntimeScheduled
+		set("Runtime")
+	case "continuous":
+		service.Runtime = types.RuntimeContinuous
+		service.Schedule = ""
+		set("Runtime")
+	}
+	if override.Schedule != "" {
+		service.Runtime = types.RuntimeScheduled
+		service.Schedule = override.Schedule
+		set("Schedule")
+	}
+	if override.Port != 0 {
+		service.Port = override.Port
+		set("Port")
+	}
+	if override.StartCommand != "" {
+		service.StartCommand = override.StartCommand
+		set("StartCommand")
+	}
+	if override.PreDeployCommand != "" {
+		service.PreDeployCommand = override.PreDeployCommand
+		set("PreDeployCommand")
+	}
+	if override.HealthcheckPath != "" {
+		service.HealthcheckPath = override.HealthcheckPath
+		set("HealthcheckPath")
+	}
+	if override.Replicas != 0 {
+		service.Replicas = override.Replicas
+		set("Replicas")
+	}
+	if override.Region != "" {
+		service.Region = override.Region
+		set("Region")
+	}
+	if len(override.Env) > 0 {
+		service.Variables = maps.Clone(service.Variables)
+		if service.Variables == nil {
+			service.Variables = make(map[string]string)
+		}
+		maps.Copy(service.Variables, override.Env)
+		set("Variables")
+	}
+
+	service.Configs = append(slices.Clone(service.Configs), types.ConfigRef{Type: "override", Path: o.path})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"maps"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/railwayapp/turnout/internal/discovery/types"
@@ -12,8 +14,9 @@ import (
 
 type PackageSignal struct {
 	filesystem   filesystems.FileSystem
-	packagePaths []string          // all found package files
-	configDirs   map[string]string // config path -> directory path
+	packagePaths []string            // all found package files
+	configDirs   map[string]string   // config path -> directory path
+	lockfiles    map[string][]string // directory path -> lockfile names
 }
 
 func NewPackageSignal(filesystem filesystems.FileSystem) *PackageSignal {
@@ -27,6 +30,7 @@ func (p *PackageSignal) Confidence() int {
 func (p *PackageSignal) Reset() {
 	p.packagePaths = nil
 	p.configDirs = make(map[string]string)
+	p.lockfiles = make(map[string][]string)
 }
 
 var packageFiles = []string{
@@ -36,6 +40,28 @@ var packageFiles = []string{
 	"project.clj", "deps.edn", "build.sbt",
 }
 
+type lockfile struct {
+	name    string
+	manager types.PackageManager
+}
+
+// Ordered by precedence when several lockfiles live in the same directory
+var jsLockfiles = []lockfile{
+	{"bun.lockb", types.PackageManagerBun},
+	{"bun.lock", types.PackageManagerBun},
+	{"pnpm-lock.yaml", types.PackageManagerPnpm},
+	{"yarn.lock", types.PackageManagerYarn},
+	{"package-lock.json", types.PackageManagerNpm},
+	{"npm-shrinkwrap.json", types.PackageManagerNpm},
+}
+
+var pythonLockfiles = []lockfile{
+	{"uv.lock", types.PackageManagerUv},
+	{"poetry.lock", types.PackageManagerPoetry},
+	{"Pipfile.lock", types.PackageManagerPipenv},
+	{"Pipfile", types.PackageManagerPipenv},
+}
+
 func (p *PackageSignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
 	if !entry.IsDir() {
 		// Check for all package manager files
@@ -55,6 +81,11 @@ func (p *PackageSignal) ObserveEntry(ctx context.Context, rootPath string, entry
 			p.packagePaths = append(p.packagePaths, fullPath)
 			p.configDirs[fullPath] = rootPath
 		}
+
+		// Remember lockfiles so services can report their package manager
+		if isLockfile(entry.Name()) {
+			p.lockfiles[rootPath] = append(p.lockfiles[rootPath], entry.Name())
+		}
 	}
 
 	return nil
@@ -70,16 +101,39 @@ func (p *PackageSignal) GenerateServices(ctx context.Context) ([]types.Service,
 	var services []types.Service
 	for _, fw := range frameworks {
 		buildPath := p.configDirs[fw.ConfigPath]
+		name, build := fw.Name, fw.Build
+		switch name {
+		case "Vite", "Remix", "React Router":
+			// Server frameworks on Vite build an SPA instead with ssr: false
+			if viteName, viteBuild := viteFramework(p.filesystem, buildPath); viteName != "Vite" {
+				name, build = viteName, viteBuild
+			}
+		}
 		service := types.Service{
 			Name:      p.filesystem.Base(buildPath),
 			Network:   fw.Network,
 			Runtime:   fw.Runtime,
-			Build:     fw.Build,
+			Build:     build,
 			BuildPath: buildPath,
 			Configs: []types.ConfigRef{
 				{Type: "package", Path: fw.ConfigPath},
 			},
+			PackageManager: p.detectPackageManager(fw.ConfigPath),
+			Platform:       fw.Platform,
+		}
+		if fw.Platform != "" {
+			service.SetProvenance("Platform", "package:"+fw.ConfigPath, 80)
+		}
+
+		if service.Build == types.BuildStatic {
+			if outputDir, ok := staticOutputDir(p.filesystem, name, buildPath); ok {
+				service.OutputDir = outputDir
+			} else {
+				service.Build = types.BuildFromSource
+			}
 		}
+
+		applyFrameworkDefaults(&service, name)
 		services = append(services, service)
 	}
 
@@ -92,6 +146,7 @@ type PackageFramework struct {
 	Network    types.Network
 	Runtime    types.Runtime
 	Build      types.Build
+	Platform   string // OS the framework needs, empty for Linux
 }
 
 func (p *PackageSignal) detectFrameworksFromPackages() []PackageFramework {
@@ -147,6 +202,93 @@ func (p *PackageSignal) detectFrameworksFromPackages() []PackageFramework {
 	return frameworks
 }
 
+func isLockfile(name string) bool {
+	for _, lf := range slices.Concat(jsLockfiles, pythonLockfiles) {
+		if strings.EqualFold(name, lf.name) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectPackageManager determines which package manager installs the dependencies
+// declared by the given manifest. Returns "" for ecosystems we don't track.
+func (p *PackageSignal) detectPackageManager(packagePath string) types.PackageManager {
+	dir := p.configDirs[packagePath]
+
+	switch strings.ToLower(p.filesystem.Base(packagePath)) {
+	case "package.json":
+		// An explicit packageManager field (corepack) beats lockfile heuristics
+		if pm := p.packageManagerField(packagePath); pm != "" {
+			return pm
+		}
+		if pm := p.findLockfile(dir, jsLockfiles); pm != "" {
+			return pm
+		}
+		return types.PackageManagerNpm
+	case "requirements.txt", "pyproject.toml":
+		if pm := p.findLockfile(dir, pythonLockfiles); pm != "" {
+			return pm
+		}
+		if data, err := p.filesystem.ReadFile(p.filesystem.Join(dir, "pyproject.toml")); err == nil {
+			if strings.Contains(string(data), "[tool.poetry]") {
+				return types.PackageManagerPoetry
+			}
+		}
+		return types.PackageManagerPip
+	}
+
+	return ""
+}
+
+// packageManagerField reads the corepack "packageManager" field (e.g. "pnpm@9.1.0")
+func (p *PackageSignal) packageManagerField(packagePath string) types.PackageManager {
+	data, err := p.filesystem.ReadFile(packagePath)
+	if err != nil {
+		return ""
+	}
+
+	var pkg struct {
+		PackageManager string `json:"packageManager"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(pkg.PackageManager, "@")
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "npm":
+		return types.PackageManagerNpm
+	case "yarn":
+		return types.PackageManagerYarn
+	case "pnpm":
+		return types.PackageManagerPnpm
+	case "bun":
+		return types.PackageManagerBun
+	}
+	return ""
+}
+
+// findLockfile looks for a lockfile in dir and then its parents, since
+// workspaces usually keep a single lockfile at the monorepo root
+func (p *PackageSignal) findLockfile(dir string, candidates []lockfile) types.PackageManager {
+	for {
+		for _, lf := range candidates {
+			for _, name := range p.lockfiles[dir] {
+				if strings.EqualFold(name, lf.name) {
+					return lf.manager
+				}
+			}
+		}
+
+		parent := p.filesystem.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
 func (p *PackageSignal) analyzePackageJson(packagePath string) *PackageFramework {
 	data, err := p.filesystem.ReadFile(packagePath)
 	if err != nil {
@@ -183,7 +325,13 @@ func (p *PackageSignal) analyzePackageJson(packagePath string) *PackageFramework
 		return &PackageFramework{Name: "SvelteKit", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	}
 	if _, found := deps["astro"]; found {
-		return &PackageFramework{Name: "Astro", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		// Astro only runs a server when an SSR adapter is installed
+		for _, adapter := range []string{"@astrojs/node", "@astrojs/vercel", "@astrojs/netlify", "@astrojs/cloudflare", "@deno/astro-adapter"} {
+			if _, found := deps[adapter]; found {
+				return &PackageFramework{Name: "Astro", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+			}
+		}
+		return &PackageFramework{Name: "Astro", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
 	}
 	if _, found := deps["solid-start"]; found {
 		return &PackageFramework{Name: "SolidStart", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
@@ -206,19 +354,19 @@ func (p *PackageSignal) analyzePackageJson(packagePath string) *PackageFramework
 
 	// Static site generators
 	if _, found := deps["gatsby"]; found {
-		return &PackageFramework{Name: "Gatsby", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		return &PackageFramework{Name: "Gatsby", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
 	}
 	if _, found := deps["@docusaurus/core"]; found {
-		return &PackageFramework{Name: "Docusaurus", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		return &PackageFramework{Name: "Docusaurus", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
 	}
 	if _, found := allDeps["vuepress"]; found {
-		return &PackageFramework{Name: "VuePress", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		return &PackageFramework{Name: "VuePress", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
 	}
 	if _, found := allDeps["vitepress"]; found {
-		return &PackageFramework{Name: "Vitepress", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		return &PackageFramework{Name: "Vitepress", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
 	}
 	if _, found := allDeps["@gridsome/cli"]; found {
-		return &PackageFramework{Name: "Gridsome", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
+		return &PackageFramework{Name: "Gridsome", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
 	}
 
 	// Backend Node.js frameworks
@@ -265,6 +413,11 @@ func (p *PackageSignal) analyzePackageJson(packagePath string) *PackageFramework
 		return &PackageFramework{Name: "Angular", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	}
 
+	// Client-side SPA bundled by Vite with no server framework
+	if _, found := allDeps["vite"]; found {
+		return &PackageFramework{Name: "Vite", ConfigPath: packagePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildStatic}
+	}
+
 	// High-confidence separate workers (lowest priority - only when no web framework detected)
 	// Infrastructure services
 	if _, found := deps["@temporalio/worker"]; found {
@@ -575,7 +728,7 @@ func (p *PackageSignal) analyzeGemfile(gemfilePath string) *PackageFramework {
 	content := string(data)
 
 	// Ruby frameworks
-	if strings.Contains(content, "rails") {
+	if dir := p.configDirs[gemfilePath]; isRailsApp(p.filesystem, dir) {
 		return &PackageFramework{Name: "Ruby on Rails", ConfigPath: gemfilePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	}
 	if strings.Contains(content, "sinatra") {
@@ -610,11 +763,11 @@ func (p *PackageSignal) analyzePom(pomPath string) *PackageFramework {
 	}
 
 	content := string(data)
+	if springBootPattern.MatchString(content) {
+		return nil // SpringBootSignal finds which modules are apps, and how they run
+	}
 
 	// Java frameworks
-	if strings.Contains(content, "spring-boot") {
-		return &PackageFramework{Name: "Spring Boot", ConfigPath: pomPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
-	}
 	if strings.Contains(content, "spring-framework") {
 		return &PackageFramework{Name: "Spring Framework", ConfigPath: pomPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	}
@@ -642,11 +795,11 @@ func (p *PackageSignal) analyzeGradle(gradlePath string) *PackageFramework {
 	}
 
 	content := string(data)
+	if springBootPattern.MatchString(content) {
+		return nil // SpringBootSignal finds which modules are apps, and how they run
+	}
 
 	// Java/Kotlin frameworks
-	if strings.Contains(content, "spring-boot") {
-		return &PackageFramework{Name: "Spring Boot", ConfigPath: gradlePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
-	}
 	if strings.Contains(content, "quarkus") {
 		return &PackageFramework{Name: "Quarkus", ConfigPath: gradlePath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
 	}
@@ -674,6 +827,15 @@ func (p *PackageSignal) analyzeCsproj(csprojPath string) *PackageFramework {
 
 	content := string(data)
 
+	// .NET Framework only runs on Windows, unlike .NET Core and .NET 5 onwards
+	if dotNetFrameworkOnly(content) {
+		network := types.NetworkNone
+		if strings.Contains(content, "System.Web") || strings.Contains(content, "WebApplication.targets") {
+			network = types.NetworkPublic
+		}
+		return &PackageFramework{Name: ".NET Framework", ConfigPath: csprojPath, Network: network, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource, Platform: "windows"}
+	}
+
 	// .NET frameworks
 	if strings.Contains(content, "Microsoft.AspNetCore") {
 		return &PackageFramework{Name: "ASP.NET Core", ConfigPath: csprojPath, Network: types.NetworkPublic, Runtime: types.RuntimeContinuous, Build: types.BuildFromSource}
@@ -689,6 +851,31 @@ func (p *PackageSignal) analyzeCsproj(csprojPath string) *PackageFramework {
 	return nil
 }
 
+var (
+	targetFrameworkVersionPattern = regexp.MustCompile(`<TargetFrameworkVersion>\s*v[1-4]\.`)
+	targetFrameworksPattern       = regexp.MustCompile(`<TargetFrameworks?>([^<]*)</TargetFrameworks?>`)
+	dotNetFrameworkMonikerPattern = regexp.MustCompile(`^net[1-4]\d*$`) // net48, but not net8.0 or netcoreapp3.1
+)
+
+// dotNetFrameworkOnly reports whether a project targets only .NET Framework: an old-style
+// project's TargetFrameworkVersion, or SDK-style target framework monikers like net48.
+// Projects also targeting .NET Core or later build for Linux too.
+func dotNetFrameworkOnly(csproj string) bool {
+	if targetFrameworkVersionPattern.MatchString(csproj) {
+		return true
+	}
+	match := targetFrameworksPattern.FindStringSubmatch(csproj)
+	if match == nil {
+		return false
+	}
+	for _, moniker := range strings.Split(match[1], ";") {
+		if moniker = strings.ToLower(strings.TrimSpace(moniker)); moniker != "" && !dotNetFrameworkMonikerPattern.MatchString(moniker) {
+			return false
+		}
+	}
+	return strings.TrimSpace(match[1]) != ""
+}
+
 func (p *PackageSignal) analyzeSwiftPackage(swiftPath string) *PackageFramework {
 	data, err := p.filesystem.ReadFile(swiftPath)
 	if err != nil {
@@ -719,6 +906,9 @@ func (p *PackageSignal) analyzeMix(mixPath string) *PackageFramework {
 	}
 
 	content := string(data)
+	if inUmbrella(content) {
+		return nil // Built from the umbrella, as ElixirSignal finds
+	}
 
 	// Elixir frameworks
 	if strings.Contains(content, "phoenix") {
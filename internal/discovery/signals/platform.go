@@ -0,0 +1,35 @@
+package signals
+
+import "strings"
+
+// Image prefixes of Windows base images, which only run on Windows hosts
+var windowsImagePrefixes = []string{
+	"mcr.microsoft.com/windows",
+	"mcr.microsoft.com/dotnet/framework/",
+	"microsoft/windowsservercore",
+	"microsoft/nanoserver",
+	"microsoft/dotnet-framework",
+	"microsoft/aspnet",
+	"microsoft/iis",
+}
+
+// imagePlatform is the OS an image needs: "windows" for Windows base images, and empty
+// for everything else, which runs on Linux
+func imagePlatform(image string) string {
+	image = strings.ToLower(image)
+	for _, prefix := range windowsImagePrefixes {
+		if strings.HasPrefix(image, prefix) {
+			return "windows"
+		}
+	}
+	return ""
+}
+
+// platformOS reduces a platform, like windows/amd64, to the OS it needs, empty for Linux
+func platformOS(platform string) string {
+	os, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(platform)), "/")
+	if os == "linux" || strings.Contains(os, "$") {
+		return "" // Linux, or a build argument we can't resolve
+	}
+	return os
+}
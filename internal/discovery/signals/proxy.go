@@ -0,0 +1,395 @@
+package signals
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// Confidence for ports and network exposure read from reverse proxy configs
+const proxyConfidence = 75
+
+// ProxySignal reads nginx and Caddy configs to learn which services sit behind
+// a reverse proxy. It doesn't generate services itself, it refines the ones
+// other signals found.
+type ProxySignal struct {
+	filesystem filesystems.FileSystem
+	configs    []string          // nginx/Caddy config paths
+	configDirs map[string]string // config path -> directory path
+}
+
+func NewProxySignal(filesystem filesystems.FileSystem) *ProxySignal {
+	return &ProxySignal{filesystem: filesystem}
+}
+
+func (p *ProxySignal) Confidence() int {
+	return proxyConfidence
+}
+
+func (p *ProxySignal) Reset() {
+	p.configs = nil
+	p.configDirs = make(map[string]string)
+}
+
+func (p *ProxySignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
+	if entry.IsDir() || proxyKind(p.filesystem.Base(rootPath), entry.Name()) == "" {
+		return nil
+	}
+
+	fullPath := p.filesystem.Join(rootPath, entry.Name())
+	p.configs = append(p.configs, fullPath)
+	p.configDirs[fullPath] = rootPath
+	return nil
+}
+
+func (p *ProxySignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	return nil, nil
+}
+
+// proxyKind reports whether a file is an nginx or Caddy config
+func proxyKind(dirName, name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case name == "Caddyfile" || strings.HasSuffix(lower, ".caddyfile"):
+		return "caddy"
+	case lower == "nginx.conf" || lower == "default.conf" || strings.HasSuffix(lower, ".nginx.conf"):
+		return "nginx"
+	case strings.HasSuffix(lower, ".conf") && (strings.EqualFold(dirName, "nginx") || strings.EqualFold(dirName, "conf.d")):
+		return "nginx"
+	}
+	return ""
+}
+
+// ProxyConfig is what a reverse proxy config says about the proxy and what it forwards to
+type ProxyConfig struct {
+	Kind        string // "nginx" or "caddy"
+	ListenPorts []int
+	Upstreams   []ProxyUpstream
+}
+
+// ProxyUpstream is a host the proxy forwards requests to
+type ProxyUpstream struct {
+	Host string
+	Port int // 0 if not specified
+}
+
+// RefineServices marks proxies public, the services they forward to private, and
+// recovers listen ports from both sides of the proxy
+func (p *ProxySignal) RefineServices(ctx context.Context, services []types.Service) []types.Service {
+	for _, configPath := range p.configs {
+		content, err := filesystems.ReadTextFile(p.filesystem, configPath)
+		if err != nil {
+			continue
+		}
+
+		kind := proxyKind(p.filesystem.Base(p.configDirs[configPath]), p.filesystem.Base(configPath))
+		var config ProxyConfig
+		if kind == "caddy" {
+			config = ParseCaddyfile(string(content))
+		} else {
+			config = ParseNginxConfig(string(content))
+		}
+		if len(config.ListenPorts) == 0 && len(config.Upstreams) == 0 {
+			continue
+		}
+
+		source := "proxy:" + configPath
+		proxy := p.findProxyService(services, configPath, kind)
+		if proxy >= 0 {
+			setInferredNetwork(&services[proxy], types.NetworkPublic)
+			if len(config.ListenPorts) > 0 {
+				setInferredPort(&services[proxy], config.ListenPorts[0], source)
+			}
+		}
+
+		for _, upstream := range config.Upstreams {
+			for i := range services {
+				if i == proxy || !strings.EqualFold(services[i].Name, upstream.Host) {
+					continue
+				}
+				setInferredNetwork(&services[i], types.NetworkPrivate)
+				if upstream.Port != 0 {
+					setInferredPort(&services[i], upstream.Port, source)
+				}
+			}
+		}
+	}
+
+	return services
+}
+
+// findProxyService finds the service that runs a proxy config, returning -1 if none does
+func (p *ProxySignal) findProxyService(services []types.Service, configPath, kind string) int {
+	dir := p.configDirs[configPath]
+
+	for i, service := range services {
+		for _, config := range service.Configs {
+			if config.Path == configPath {
+				return i
+			}
+		}
+	}
+	for i, service := range services {
+		if service.BuildPath != "" && service.BuildPath == dir {
+			return i
+		}
+	}
+
+	// Fall back to the only service running the proxy's image
+	match := -1
+	for i, service := range services {
+		if service.BaseImage == kind || imageFamily(service.Image) == kind {
+			if match >= 0 {
+				return -1
+			}
+			match = i
+		}
+	}
+	return match
+}
+
+// setInferredPort sets a port unless a more trustworthy source already provided one
+func setInferredPort(service *types.Service, port int, source string) {
+	if service.Port != 0 {
+		existing, inferred := service.ProvenanceOf("Port")
+		if !inferred || existing.Confidence >= proxyConfidence {
+			return
+		}
+	}
+	service.Port = port
+	service.SetProvenance("Port", source, proxyConfidence)
+}
+
+// setInferredNetwork sets a network unless the user pinned one
+func setInferredNetwork(service *types.Service, network types.Network) {
+	if !service.IsPinned("Network") {
+		service.Network = network
+	}
+}
+
+// ParseNginxConfig extracts listen ports and upstream hosts from an nginx config
+func ParseNginxConfig(content string) ProxyConfig {
+	config := ProxyConfig{Kind: "nginx"}
+	upstreamBlocks := make(map[string][]ProxyUpstream)
+
+	var blocks []string     // names of the enclosing blocks
+	var upstreamName string // name of the enclosing upstream block
+	var targets []string    // proxy_pass targets, resolved after all upstream blocks are known
+
+	for _, statement := range nginxStatements(content) {
+		fields := strings.Fields(statement.text)
+		switch {
+		case statement.opensBlock:
+			if len(fields) > 0 {
+				blocks = append(blocks, fields[0])
+				if fields[0] == "upstream" && len(fields) > 1 {
+					upstreamName = strings.ToLower(fields[1])
+				}
+			}
+			continue
+		case statement.closesBlock:
+			if len(blocks) > 0 {
+				if blocks[len(blocks)-1] == "upstream" {
+					upstreamName = ""
+				}
+				blocks = blocks[:len(blocks)-1]
+			}
+			continue
+		case len(fields) < 2:
+			continue
+		}
+
+		switch fields[0] {
+		case "listen":
+			if port := nginxListenPort(fields[1]); port != 0 {
+				config.ListenPorts = appendUniqueInt(config.ListenPorts, port)
+			}
+		case "server":
+			if upstreamName != "" {
+				if upstream, ok := parseUpstream(fields[1]); ok {
+					upstreamBlocks[upstreamName] = append(upstreamBlocks[upstreamName], upstream)
+				}
+			}
+		case "proxy_pass", "fastcgi_pass", "uwsgi_pass", "grpc_pass", "scgi_pass":
+			targets = append(targets, fields[1])
+		}
+	}
+
+	for _, target := range targets {
+		upstream, ok := parseUpstream(target)
+		if !ok {
+			continue
+		}
+		if servers, ok := upstreamBlocks[upstream.Host]; ok {
+			config.Upstreams = append(config.Upstreams, servers...)
+		} else {
+			config.Upstreams = append(config.Upstreams, upstream)
+		}
+	}
+
+	return config
+}
+
+type nginxStatement struct {
+	text        string
+	opensBlock  bool
+	closesBlock bool
+}
+
+// nginxStatements splits an nginx config into directives and block boundaries
+func nginxStatements(content string) []nginxStatement {
+	var statements []nginxStatement
+	var current strings.Builder
+
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		for _, r := range line {
+			switch r {
+			case ';':
+				statements = append(statements, nginxStatement{text: strings.TrimSpace(current.String())})
+				current.Reset()
+			case '{':
+				statements = append(statements, nginxStatement{text: strings.TrimSpace(current.String()), opensBlock: true})
+				current.Reset()
+			case '}':
+				statements = append(statements, nginxStatement{closesBlock: true})
+				current.Reset()
+			default:
+				current.WriteRune(r)
+			}
+		}
+		current.WriteByte(' ')
+	}
+
+	return statements
+}
+
+// nginxListenPort parses "80", "0.0.0.0:8080", "[::]:443" and "unix:/path" listen values
+func nginxListenPort(value string) int {
+	if strings.HasPrefix(value, "unix:") {
+		return 0
+	}
+	if i := strings.LastIndexByte(value, ':'); i >= 0 {
+		value = value[i+1:]
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return port
+}
+
+// ParseCaddyfile extracts site listen ports and reverse_proxy upstreams from a Caddyfile
+func ParseCaddyfile(content string) ProxyConfig {
+	config := ProxyConfig{Kind: "caddy"}
+	depth := 0
+	first := true
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		opens := strings.Count(line, "{")
+		closes := strings.Count(line, "}")
+		fields := strings.Fields(strings.NewReplacer("{", " ", "}", " ").Replace(line))
+
+		switch {
+		case len(fields) > 1 && (fields[0] == "reverse_proxy" || fields[0] == "php_fastcgi"):
+			for _, target := range fields[1:] {
+				// Skip request matchers like /api/* and @name
+				if strings.HasPrefix(target, "/") || strings.HasPrefix(target, "@") || target == "*" {
+					continue
+				}
+				if upstream, ok := parseUpstream(target); ok {
+					config.Upstreams = append(config.Upstreams, upstream)
+				}
+			}
+		case depth == 0 && (opens > 0 || first):
+			// Site block addresses; a single-site Caddyfile puts them on the first line
+			for _, address := range fields {
+				if port := caddyAddressPort(strings.TrimSuffix(address, ",")); port != 0 {
+					config.ListenPorts = appendUniqueInt(config.ListenPorts, port)
+				}
+			}
+		}
+		first = false
+
+		depth += opens - closes
+		if depth < 0 {
+			depth = 0
+		}
+	}
+
+	return config
+}
+
+// caddyAddressPort returns the port a Caddy site address listens on
+func caddyAddressPort(address string) int {
+	if address == "" || strings.HasPrefix(address, "(") || strings.HasPrefix(address, "import") {
+		return 0
+	}
+
+	defaultPort := 443
+	if after, ok := strings.CutPrefix(address, "http://"); ok {
+		address, defaultPort = after, 80
+	} else if after, ok := strings.CutPrefix(address, "https://"); ok {
+		address = after
+	} else if !strings.Contains(address, ".") && !strings.Contains(address, ":") && address != "localhost" {
+		// Not an address, e.g. a directive in a single-site Caddyfile
+		return 0
+	}
+
+	address, _, _ = strings.Cut(address, "/")
+	if i := strings.LastIndexByte(address, ':'); i >= 0 {
+		if port, err := strconv.Atoi(address[i+1:]); err == nil {
+			return port
+		}
+	}
+	return defaultPort
+}
+
+// parseUpstream parses proxy targets like "http://api:3000/path", "api:3000" and "api"
+func parseUpstream(target string) (ProxyUpstream, bool) {
+	target = strings.TrimSuffix(target, ";")
+	if strings.Contains(target, "$") || strings.HasPrefix(target, "unix:") {
+		return ProxyUpstream{}, false
+	}
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || u.Hostname() == "" {
+		return ProxyUpstream{}, false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	switch host {
+	case "localhost", "127.0.0.1", "0.0.0.0", "::1":
+		// Same container, not another service
+		return ProxyUpstream{}, false
+	}
+
+	port, _ := strconv.Atoi(u.Port())
+	return ProxyUpstream{Host: host, Port: port}, true
+}
+
+func appendUniqueInt(values []int, value int) []int {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,209 @@
+package signals
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// Confidence for start commands and ports worked out from a Python project's
+// entrypoint, above framework conventions but below anything a config states
+const pythonEntrypointConfidence = 60
+
+// Python dependency files a project's dependencies may be declared in
+var pythonDependencyFiles = []string{"requirements.txt", "requirements/*.txt", "pyproject.toml", "Pipfile"}
+
+// Gunicorn reads gunicorn.conf.py from the directory it starts in; the others are
+// common names passed with -c
+var gunicornConfigFiles = []string{"gunicorn.conf.py", "gunicorn_config.py", "gunicorn.py", "config/gunicorn.py"}
+
+// Modules web apps are commonly defined in, for `module:app` entrypoints
+var pythonAppModules = []string{"main.py", "app.py", "server.py", "application.py", "app/main.py", "src/main.py"}
+
+var (
+	// An app object, like app = FastAPI() or application = Flask(__name__)
+	pythonAppPattern = regexp.MustCompile(`(?m)^(\w+)\s*(?::\s*\w+\s*)?=\s*(FastAPI|Starlette|Quart|Litestar|Sanic|Flask|Bottle|Falcon|falcon\.App|falcon\.asgi\.App)\(`)
+
+	gunicornBindPattern = regexp.MustCompile(`(?m)^bind\s*=\s*\[?\s*["']([^"']+)["']`)
+	gunicornAppPattern  = regexp.MustCompile(`(?m)^wsgi_app\s*=\s*["']([^"']+)["']`)
+
+	uvicornDependency   = pythonDependencyPattern("uvicorn")
+	hypercornDependency = pythonDependencyPattern("hypercorn")
+	daphneDependency    = pythonDependencyPattern("daphne")
+	asgiDependency      = pythonDependencyPattern("(channels|uvicorn|daphne|hypercorn)")
+)
+
+// PythonEntrypointSignal works out how Python web services start: the Django project's
+// wsgi.py or asgi.py, or a module defining an app, like main:app, served by the server
+// the project depends on, on the port a gunicorn config binds
+type PythonEntrypointSignal struct {
+	filesystem filesystems.FileSystem
+}
+
+func NewPythonEntrypointSignal(filesystem filesystems.FileSystem) *PythonEntrypointSignal {
+	return &PythonEntrypointSignal{filesystem: filesystem}
+}
+
+func (p *PythonEntrypointSignal) Confidence() int {
+	return pythonEntrypointConfidence
+}
+
+func (p *PythonEntrypointSignal) Reset() {}
+
+func (p *PythonEntrypointSignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
+	return nil
+}
+
+func (p *PythonEntrypointSignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	return nil, nil
+}
+
+// RefineServices fills in the start command and port of Python services that listen,
+// unless a config already set them
+func (p *PythonEntrypointSignal) RefineServices(ctx context.Context, services []types.Service) []types.Service {
+	for i := range services {
+		service := &services[i]
+		if service.Build != types.BuildFromSource || service.Network == types.NetworkNone || service.BuildPath == "" {
+			continue
+		}
+		dependencies, ok := pythonDependencies(p.filesystem, service.BuildPath)
+		if !ok {
+			continue
+		}
+		command, port, source := p.entrypoint(service.BuildPath, dependencies)
+		if command == "" {
+			continue
+		}
+
+		if replaceable(*service, "StartCommand", service.StartCommand == "") {
+			service.StartCommand = command
+			service.SetProvenance("StartCommand", "python-entrypoint:"+source, pythonEntrypointConfidence)
+		}
+		if replaceable(*service, "Port", service.Port == 0) {
+			service.Port = port
+			service.SetProvenance("Port", "python-entrypoint:"+source, pythonEntrypointConfidence)
+		}
+	}
+	return services
+}
+
+// replaceable reports whether a field is empty, or only inferred less surely than an
+// entrypoint tells
+func replaceable(service types.Service, field string, empty bool) bool {
+	if service.IsPinned(field) {
+		return false
+	}
+	provenance, inferred := service.ProvenanceOf(field)
+	return empty || inferred && provenance.Confidence < pythonEntrypointConfidence
+}
+
+// entrypoint finds the app a project serves and the command serving it, with the file
+// it learned that from
+func (p *PythonEntrypointSignal) entrypoint(dir, dependencies string) (command string, port int, source string) {
+	port = 8000 // What gunicorn, uvicorn, hypercorn and daphne all bind by default
+	var config string
+	for _, name := range gunicornConfigFiles {
+		path := p.filesystem.Join(dir, name)
+		data, err := p.filesystem.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		config, source = name, path
+		if match := gunicornBindPattern.FindSubmatch(data); match != nil {
+			bind := string(match[1])
+			if bound, err := strconv.Atoi(bind[strings.LastIndex(bind, ":")+1:]); err == nil {
+				port = bound
+			}
+		}
+		if gunicornAppPattern.Match(data) {
+			return "gunicorn -c " + config, port, source // The config names the app too
+		}
+		break
+	}
+
+	app, asgi, appSource := p.app(dir, dependencies)
+	if app == "" {
+		return "", 0, ""
+	}
+	if source == "" {
+		source = appSource
+	}
+
+	switch {
+	case config != "":
+		return "gunicorn -c " + config + " " + app, port, source // Its worker_class decides WSGI or ASGI
+	case asgi && hypercornDependency.MatchString(dependencies) && !uvicornDependency.MatchString(dependencies):
+		return "hypercorn " + app + " --bind 0.0.0.0:8000", port, source
+	case asgi && daphneDependency.MatchString(dependencies) && !uvicornDependency.MatchString(dependencies):
+		return "daphne -b 0.0.0.0 -p 8000 " + app, port, source
+	case asgi:
+		return "uvicorn " + app + " --host 0.0.0.0 --port 8000", port, source
+	default:
+		return "gunicorn " + app + " --bind 0.0.0.0:8000", port, source
+	}
+}
+
+// app finds the app a project serves, as module:object, and whether it's ASGI. Django
+// projects serve their project package's asgi.py when they depend on an ASGI server or
+// Channels, and wsgi.py otherwise.
+func (p *PythonEntrypointSignal) app(dir, dependencies string) (app string, asgi bool, source string) {
+	for entry, err := range p.filesystem.ReadDir(dir) {
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		wsgi := p.filesystem.Join(dir, entry.Name(), "wsgi.py")
+		asgiPath := p.filesystem.Join(dir, entry.Name(), "asgi.py")
+		if p.filesystem.Exists(asgiPath) && asgiDependency.MatchString(dependencies) {
+			return entry.Name() + ".asgi:application", true, asgiPath
+		}
+		if p.filesystem.Exists(wsgi) {
+			return entry.Name() + ".wsgi:application", false, wsgi
+		}
+	}
+
+	for _, name := range pythonAppModules {
+		path := p.filesystem.Join(dir, name)
+		data, err := p.filesystem.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		match := pythonAppPattern.FindSubmatch(data)
+		if match == nil {
+			continue
+		}
+		module := strings.ReplaceAll(strings.TrimSuffix(name, ".py"), "/", ".")
+		framework := string(match[2])
+		wsgi := framework == "Flask" || framework == "Bottle" || framework == "Falcon" || framework == "falcon.App"
+		return module + ":" + string(match[1]), !wsgi, path
+	}
+	return "", false, ""
+}
+
+// pythonDependencies reads the dependencies a Python project in dir declares, or
+// returns false if it isn't one
+func pythonDependencies(filesystem filesystems.FileSystem, dir string) (string, bool) {
+	var dependencies strings.Builder
+	found := false
+	for _, pattern := range pythonDependencyFiles {
+		matches, _ := filesystem.Glob(filesystem.Join(dir, pattern))
+		for _, match := range matches {
+			if data, err := filesystem.ReadFile(match); err == nil {
+				dependencies.Write(data)
+				dependencies.WriteByte('\n')
+				found = true
+			}
+		}
+	}
+	return dependencies.String(), found
+}
+
+// pythonDependencyPattern matches a dependency on a package in requirements.txt,
+// pyproject.toml or Pipfile, but not on packages merely named after it, like
+// django-celery-beat for celery
+func pythonDependencyPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?im)(^|["'\s])` + name + `\b`)
+}
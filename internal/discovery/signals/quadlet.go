@@ -0,0 +1,195 @@
+package signals
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// QuadletSignal finds Podman Quadlet units, *.container and *.kube files, and systemd
+// services whose ExecStart= runs a container with `podman run` or `docker run`
+type QuadletSignal struct {
+	filesystem filesystems.FileSystem
+	unitPaths  []string
+
+	skipped
+}
+
+func NewQuadletSignal(filesystem filesystems.FileSystem) *QuadletSignal {
+	return &QuadletSignal{filesystem: filesystem}
+}
+
+func (q *QuadletSignal) Confidence() int {
+	return 90 // High confidence - units are what a host actually runs, but written per host
+}
+
+func (q *QuadletSignal) Reset() {
+	q.skipped = skipped{}
+	q.unitPaths = nil
+}
+
+func (q *QuadletSignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
+	if entry.IsDir() {
+		return nil
+	}
+	switch unitExtension(entry.Name()) {
+	case ".container", ".kube", ".service":
+		q.unitPaths = append(q.unitPaths, q.filesystem.Join(rootPath, entry.Name()))
+	}
+	return nil
+}
+
+func (q *QuadletSignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	var services []types.Service
+	for _, unitPath := range q.unitPaths {
+		content, err := filesystems.ReadTextFile(q.filesystem, unitPath)
+		if err != nil {
+			q.skip(unitPath, err)
+			continue
+		}
+		unit := parseSystemdUnit(content)
+		config := types.ConfigRef{Type: "quadlet", Path: unitPath}
+
+		switch unitExtension(unitPath) {
+		case ".container":
+			if unit["Container"] == nil {
+				continue
+			}
+			services = append(services, q.containerService(unitPath, unit, config))
+		case ".kube":
+			services = append(services, q.kubeServices(unitPath, unit, config)...)
+		case ".service":
+			spec, ok := parseContainerRun(unit.get("Service", "ExecStart"))
+			if !ok {
+				continue // Not a container, or nothing we can deploy
+			}
+			if spec.name == "" || strings.Contains(spec.name, "%") {
+				spec.name = q.unitName(unitPath)
+			}
+			config.Type = "systemd"
+			services = append(services, spec.service(config))
+		}
+	}
+	return services, nil
+}
+
+// containerService maps a .container unit's [Container] section onto a service
+func (q *QuadletSignal) containerService(unitPath string, unit systemdUnit, config types.ConfigRef) types.Service {
+	section := unit["Container"]
+	spec := containerSpec{
+		name:    unit.get("Container", "ContainerName"),
+		image:   unit.get("Container", "Image"),
+		command: unit.get("Container", "Exec"),
+		publish: section["PublishPort"],
+		volumes: section["Volume"],
+	}
+	if spec.name == "" || strings.Contains(spec.name, "%") {
+		spec.name = q.unitName(unitPath)
+	}
+	for _, environment := range section["Environment"] {
+		spec.environment = append(spec.environment, splitSystemdWords(environment)...)
+	}
+
+	// Images may name another Quadlet unit: an .image unit pulling it, or a .build
+	// unit building it
+	dir := q.filesystem.Dir(unitPath)
+	switch unitExtension(spec.image) {
+	case ".image":
+		imageUnit, err := q.readUnit(q.filesystem.Join(dir, spec.image))
+		if err != nil {
+			q.skip(unitPath, err)
+			break
+		}
+		spec.image = imageUnit.get("Image", "Image")
+	case ".build":
+		buildPath := q.filesystem.Join(dir, spec.image)
+		buildUnit, err := q.readUnit(buildPath)
+		if err != nil {
+			q.skip(unitPath, err)
+			break
+		}
+		service := spec.service(config)
+		service.Image = ""
+		service.Build = types.BuildFromSource
+		service.BuildPath = q.buildContext(dir, buildUnit)
+		service.Configs = append(service.Configs, types.ConfigRef{Type: "quadlet", Path: buildPath})
+		if file := buildUnit.get("Build", "File"); file != "" && !strings.Contains(file, "://") {
+			if dockerfile := q.filesystem.Join(dir, file); q.filesystem.Exists(dockerfile) {
+				service.Configs = append(service.Configs, types.ConfigRef{Type: "dockerfile", Path: dockerfile})
+			}
+		}
+		return service
+	}
+	return spec.service(config)
+}
+
+// buildContext is the directory a .build unit builds in: one it names, the unit's own
+// with SetWorkingDirectory=unit, or its Containerfile's
+func (q *QuadletSignal) buildContext(dir string, unit systemdUnit) string {
+	file := unit.get("Build", "File")
+	switch workingDirectory := unit.get("Build", "SetWorkingDirectory"); workingDirectory {
+	case "unit":
+		return dir
+	case "file", "":
+		if file == "" {
+			return dir
+		}
+		return q.filesystem.Dir(q.filesystem.Join(dir, file))
+	default:
+		return q.filesystem.Join(dir, workingDirectory)
+	}
+}
+
+// kubeServices maps the workloads a .kube unit plays, publishing the ports it lists
+// on the workloads listening on them
+func (q *QuadletSignal) kubeServices(unitPath string, unit systemdUnit, config types.ConfigRef) []types.Service {
+	manifest := unit.get("Kube", "Yaml")
+	if manifest == "" {
+		return nil
+	}
+	manifestPath := q.filesystem.Join(q.filesystem.Dir(unitPath), manifest)
+	content, err := filesystems.ReadTextFile(q.filesystem, manifestPath)
+	if err != nil {
+		q.skip(unitPath, err)
+		return nil
+	}
+
+	workloads := analyzeKubernetesWorkloads(parseKubernetesManifests(content))
+	var services []types.Service
+	for _, workload := range workloads {
+		service := kubernetesWorkloadService(workload, config)
+		for _, port := range unit["Kube"]["PublishPort"] {
+			mapping, _, _ := strings.Cut(port, "/")
+			if len(workloads) == 1 || strings.HasSuffix(mapping, fmt.Sprintf(":%d", service.Port)) {
+				publishPorts(&service, []string{port})
+			}
+		}
+		services = append(services, service)
+	}
+	return services
+}
+
+func (q *QuadletSignal) readUnit(path string) (systemdUnit, error) {
+	content, err := filesystems.ReadTextFile(q.filesystem, path)
+	if err != nil {
+		return nil, err
+	}
+	return parseSystemdUnit(content), nil
+}
+
+// unitExtension is a unit's type, like .container
+func unitExtension(name string) string {
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		return strings.ToLower(name[dot:])
+	}
+	return ""
+}
+
+// unitName is a unit's name without its type, like web for web.container
+func (q *QuadletSignal) unitName(path string) string {
+	name := q.filesystem.Base(path)
+	return name[:len(name)-len(unitExtension(name))]
+}
@@ -0,0 +1,206 @@
+package signals
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// gemPattern matches the gems a Gemfile requires, like gem "rails", "~> 7.1"
+var gemPattern = regexp.MustCompile(`(?m)^\s*gem\s*\(?\s*["']([\w-]+)["']`)
+
+// gems lists the gems the Gemfile in dir requires
+func gems(filesystem filesystems.FileSystem, dir string) []string {
+	data, err := filesystem.ReadFile(filesystem.Join(dir, "Gemfile"))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, match := range gemPattern.FindAllSubmatch(data, -1) {
+		names = append(names, string(match[1]))
+	}
+	return names
+}
+
+// isRailsApp reports whether dir holds a Rails app: a config/application.rb, and a
+// Gemfile requiring Rails, rather than just a Rack app's config.ru
+func isRailsApp(filesystem filesystems.FileSystem, dir string) bool {
+	required := gems(filesystem, dir)
+	return filesystem.Exists(filesystem.Join(dir, "config", "application.rb")) &&
+		(slices.Contains(required, "rails") || slices.Contains(required, "railties"))
+}
+
+// railsProcesses splits a Rails app's background processes from its web service, since
+// each runs as its own service: a Sidekiq or GoodJob worker, and a cron service for
+// each job a whenever schedule runs
+func railsProcesses(filesystem filesystems.FileSystem, web types.Service) []types.Service {
+	required := gems(filesystem, web.BuildPath)
+	process := func(name, command string, runtime types.Runtime, config string) types.Service {
+		return types.Service{
+			Name:         web.Name + "-" + name,
+			Network:      types.NetworkNone,
+			Runtime:      runtime,
+			Build:        web.Build,
+			BuildPath:    web.BuildPath,
+			StartCommand: command,
+			Configs:      append(slices.Clip(web.Configs), types.ConfigRef{Type: "framework", Path: config}),
+		}
+	}
+
+	var services []types.Service
+	gemfile := filesystem.Join(web.BuildPath, "Gemfile")
+	switch {
+	case slices.Contains(required, "sidekiq"):
+		command := "bundle exec sidekiq"
+		if config := filesystem.Join(web.BuildPath, "config", "sidekiq.yml"); filesystem.Exists(config) {
+			command += " -C config/sidekiq.yml"
+		}
+		services = append(services, process("worker", command, types.RuntimeContinuous, gemfile))
+	case slices.Contains(required, "good_job"):
+		services = append(services, process("worker", "bundle exec good_job start", types.RuntimeContinuous, gemfile))
+	}
+
+	if slices.Contains(required, "whenever") {
+		schedulePath := filesystem.Join(web.BuildPath, "config", "schedule.rb")
+		if data, err := filesystem.ReadFile(schedulePath); err == nil {
+			jobs := parseWheneverSchedule(string(data))
+			for i, job := range jobs {
+				name := "cron"
+				if len(jobs) > 1 {
+					name = fmt.Sprintf("cron-%d", i+1)
+				}
+				service := process(name, job.command, types.RuntimeScheduled, schedulePath)
+				service.Schedule = job.schedule
+				services = append(services, service)
+			}
+		}
+	}
+	return services
+}
+
+// wheneverJob is a job in a whenever config/schedule.rb
+type wheneverJob struct {
+	schedule string // cron expression, empty when it can't be expressed as one
+	command  string
+}
+
+var (
+	wheneverEveryPattern = regexp.MustCompile(`(?m)^\s*every\s+(.+?)\s+do\s*$`)
+	wheneverJobPattern   = regexp.MustCompile(`(?m)^\s*(runner|rake|command)\s*\(?\s*["'](.+?)["']`)
+	wheneverAtPattern    = regexp.MustCompile(`\bat:\s*["']([^"']+)["']`)
+)
+
+// parseWheneverSchedule reads the jobs in a schedule.rb, one for each job type a block
+// runs, like runner "Report.send" in every 1.day, at: "4:30 am" do
+func parseWheneverSchedule(content string) []wheneverJob {
+	var jobs []wheneverJob
+	blocks := wheneverEveryPattern.FindAllStringSubmatchIndex(content, -1)
+	for i, block := range blocks {
+		end := len(content)
+		if i+1 < len(blocks) {
+			end = blocks[i+1][0]
+		}
+		schedule := wheneverCron(content[block[2]:block[3]])
+		for _, job := range wheneverJobPattern.FindAllStringSubmatch(content[block[1]:end], -1) {
+			command := job[2]
+			switch job[1] {
+			case "runner":
+				command = `bin/rails runner "` + command + `"`
+			case "rake":
+				command = "bundle exec rake " + command
+			}
+			jobs = append(jobs, wheneverJob{schedule: schedule, command: command})
+		}
+	}
+	return jobs
+}
+
+var (
+	wheneverIntervalPattern = regexp.MustCompile(`^(\d+)\.(minutes?|hours?|days?|months?)$`)
+	wheneverWeekdays        = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+)
+
+// wheneverCron converts an every clause, like 10.minutes, :hour, :monday, at: "9am" or
+// a cron string, into a cron expression, or returns "" if it can't
+func wheneverCron(clause string) string {
+	when, options, _ := strings.Cut(clause, ",")
+	when = strings.TrimSpace(when)
+	if cron, ok := strings.CutPrefix(when, `"`); ok {
+		return strings.TrimSuffix(cron, `"`)
+	}
+	if cron, ok := strings.CutPrefix(when, `'`); ok {
+		return strings.TrimSuffix(cron, `'`)
+	}
+
+	minute, hour := "0", "0"
+	if match := wheneverAtPattern.FindStringSubmatch(options); match != nil {
+		var ok bool
+		if minute, hour, ok = wheneverTime(match[1]); !ok {
+			return ""
+		}
+	}
+
+	if match := wheneverIntervalPattern.FindStringSubmatch(when); match != nil {
+		n, unit := match[1], strings.TrimSuffix(match[2], "s")
+		step := "*"
+		if n != "1" {
+			step = "*/" + n
+		}
+		switch unit {
+		case "minute":
+			return step + " * * * *"
+		case "hour":
+			return "0 " + step + " * * *"
+		case "day":
+			return minute + " " + hour + " " + step + " * *"
+		case "month":
+			return minute + " " + hour + " 1 " + step + " *"
+		}
+	}
+
+	switch name := strings.TrimPrefix(when, ":"); {
+	case name == "hour":
+		return "0 * * * *"
+	case name == "day":
+		return minute + " " + hour + " * * *"
+	case name == "week":
+		return minute + " " + hour + " * * 0"
+	case name == "month":
+		return minute + " " + hour + " 1 * *"
+	case name == "weekday":
+		return minute + " " + hour + " * * 1-5"
+	case name == "weekend":
+		return minute + " " + hour + " * * 0,6"
+	case slices.Contains(wheneverWeekdays, name):
+		return minute + " " + hour + " * * " + strconv.Itoa(slices.Index(wheneverWeekdays, name))
+	}
+	return ""
+}
+
+var wheneverTimePattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+
+// wheneverTime reads a time of day, like 4:30 am, 12pm or 16:00, into cron's minute and
+// hour fields
+func wheneverTime(at string) (minute, hour string, ok bool) {
+	match := wheneverTimePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(at)))
+	if match == nil {
+		return "", "", false
+	}
+	h, _ := strconv.Atoi(match[1])
+	m, _ := strconv.Atoi(match[2])
+	switch {
+	case match[3] == "am" && h == 12:
+		h = 0
+	case match[3] == "pm" && h < 12:
+		h += 12
+	}
+	if h > 23 || m > 59 {
+		return "", "", false
+	}
+	return strconv.Itoa(m), strconv.Itoa(h), true
+}
@@ -3,6 +3,7 @@ package signals
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -14,6 +15,8 @@ type RailwaySignal struct {
 	filesystem  filesystems.FileSystem
 	configPaths []string          // all found railway config files
 	configDirs  map[string]string // config path -> directory path
+
+	skipped
 }
 
 func NewRailwaySignal(filesystem filesystems.FileSystem) *RailwaySignal {
@@ -24,20 +27,20 @@ func (r *RailwaySignal) Confidence() int {
 	return 95 // Highest confidence - Railway configs are explicit production deployment specs
 }
 
+// ServiceConfidence distrusts Railway configs naming a Dockerfile that isn't there
+func (r *RailwaySignal) ServiceConfidence(service types.Service) int {
+	return staleConfidence(service, r.Confidence())
+}
+
 func (r *RailwaySignal) Reset() {
+	r.skipped = skipped{}
 	r.configPaths = nil
 	r.configDirs = make(map[string]string)
 }
 
 func (r *RailwaySignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
 	if !entry.IsDir() {
-		// Check for railway.json first (higher precedence), then railway.toml
-		if strings.EqualFold(entry.Name(), "railway.json") {
-			configPath := r.filesystem.Join(rootPath, entry.Name())
-			r.configPaths = append(r.configPaths, configPath)
-			r.configDirs[configPath] = rootPath
-		} else if strings.EqualFold(entry.Name(), "railway.toml") && len(r.configPaths) == 0 {
-			// Only set if we haven't already found railway.json
+		if strings.EqualFold(entry.Name(), "railway.json") || strings.EqualFold(entry.Name(), "railway.toml") {
 			configPath := r.filesystem.Join(rootPath, entry.Name())
 			r.configPaths = append(r.configPaths, configPath)
 			r.configDirs[configPath] = rootPath
@@ -52,44 +55,134 @@ func (r *RailwaySignal) GenerateServices(ctx context.Context) ([]types.Service,
 		return nil, nil
 	}
 
-	// Use first config found
-	configPath := r.configPaths[0]
-	config, err := r.parseRailwayConfig(configPath)
-	if err != nil {
-		return nil, err
+	var services []types.Service
+	for _, configPath := range r.selectConfigs() {
+		config, err := r.parseRailwayConfig(configPath)
+		if err != nil {
+			r.skip(configPath, err)
+			continue
+		}
+
+		buildPath := r.configDirs[configPath]
+		// Each Railway config defines a single service (unlike compose which can have multiple)
+		service := types.Service{
+			Name:      r.inferServiceNameFromPath(buildPath),
+			Network:   determineNetworkFromRailway(config),
+			Runtime:   determineRuntimeFromRailway(config),
+			Build:     determineBuildFromRailway(config),
+			BuildPath: buildPath, // Railway builds from the directory containing the config
+			Configs: []types.ConfigRef{
+				{Type: "railway", Path: configPath},
+			},
+		}
+
+		if config.Build != nil && config.Build.DockerfilePath != "" {
+			dockerfile := r.filesystem.Join(buildPath, config.Build.DockerfilePath)
+			if pathExists(r.filesystem, &service, configPath, "build.dockerfilePath", dockerfile) {
+				service.Configs = append(service.Configs, types.ConfigRef{Type: "dockerfile", Path: dockerfile})
+			}
+		}
+		if config.Deploy != nil {
+			service.StartCommand = config.Deploy.StartCommand
+			service.HealthcheckPath = config.Deploy.HealthcheckPath
+			service.PreDeployCommand = strings.Join(config.Deploy.PreDeployCommand, " && ")
+			service.Schedule = config.Deploy.CronSchedule
+			service.Replicas = config.Deploy.NumReplicas
+			service.Region = config.Deploy.Region
+		}
+
+		services = append(services, service)
 	}
 
-	buildPath := r.configDirs[configPath]
-	// Railway config defines a single service (unlike compose which can have multiple)
-	service := types.Service{
-		Name:      r.inferServiceNameFromPath(buildPath),
-		Network:   determineNetworkFromRailway(config),
-		Runtime:   types.RuntimeContinuous, // Railway services are continuous
-		Build:     determineBuildFromRailway(config),
-		BuildPath: buildPath, // Railway builds from the directory containing the config
-		Configs: []types.ConfigRef{
-			{Type: "railway", Path: configPath},
-		},
+	return services, nil
+}
+
+// selectConfigs picks one config per directory, preferring railway.json over railway.toml
+func (r *RailwaySignal) selectConfigs() []string {
+	var dirs []string
+	selected := make(map[string]string)
+	for _, configPath := range r.configPaths {
+		dir := r.configDirs[configPath]
+		current, ok := selected[dir]
+		if !ok {
+			dirs = append(dirs, dir)
+		}
+		if !ok || strings.HasSuffix(strings.ToLower(current), ".toml") {
+			selected[dir] = configPath
+		}
 	}
 
-	return []types.Service{service}, nil
+	configs := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		configs = append(configs, selected[dir])
+	}
+	return configs
 }
 
 // RailwayConfig represents the Railway config-as-code schema
 type RailwayConfig struct {
+	Schema string         `json:"$schema,omitempty" toml:"$schema,omitempty"`
 	Build  *RailwayBuild  `json:"build,omitempty" toml:"build,omitempty"`
 	Deploy *RailwayDeploy `json:"deploy,omitempty" toml:"deploy,omitempty"`
 }
 
 type RailwayBuild struct {
-	Builder      string `json:"builder,omitempty" toml:"builder,omitempty"`
-	BuildCommand string `json:"buildCommand,omitempty" toml:"buildCommand,omitempty"`
+	Builder            string         `json:"builder,omitempty" toml:"builder,omitempty"`
+	BuildCommand       string         `json:"buildCommand,omitempty" toml:"buildCommand,omitempty"`
+	WatchPatterns      []string       `json:"watchPatterns,omitempty" toml:"watchPatterns,omitempty"`
+	DockerfilePath     string         `json:"dockerfilePath,omitempty" toml:"dockerfilePath,omitempty"`
+	NixpacksPlan       map[string]any `json:"nixpacksPlan,omitempty" toml:"nixpacksPlan,omitempty"`
+	NixpacksConfigPath string         `json:"nixpacksConfigPath,omitempty" toml:"nixpacksConfigPath,omitempty"`
+	NixpacksVersion    string         `json:"nixpacksVersion,omitempty" toml:"nixpacksVersion,omitempty"`
 }
 
 type RailwayDeploy struct {
-	StartCommand      string `json:"startCommand,omitempty" toml:"startCommand,omitempty"`
-	HealthcheckPath   string `json:"healthcheckPath,omitempty" toml:"healthcheckPath,omitempty"`
-	RestartPolicyType string `json:"restartPolicyType,omitempty" toml:"restartPolicyType,omitempty"`
+	StartCommand            string         `json:"startCommand,omitempty" toml:"startCommand,omitempty"`
+	PreDeployCommand        RailwayCommand `json:"preDeployCommand,omitempty" toml:"preDeployCommand,omitempty"`
+	NumReplicas             int            `json:"numReplicas,omitempty" toml:"numReplicas,omitempty"`
+	CronSchedule            string         `json:"cronSchedule,omitempty" toml:"cronSchedule,omitempty"`
+	Region                  string         `json:"region,omitempty" toml:"region,omitempty"`
+	HealthcheckPath         string         `json:"healthcheckPath,omitempty" toml:"healthcheckPath,omitempty"`
+	HealthcheckTimeout      int            `json:"healthcheckTimeout,omitempty" toml:"healthcheckTimeout,omitempty"`
+	RestartPolicyType       string         `json:"restartPolicyType,omitempty" toml:"restartPolicyType,omitempty"`
+	RestartPolicyMaxRetries int            `json:"restartPolicyMaxRetries,omitempty" toml:"restartPolicyMaxRetries,omitempty"`
+	SleepApplication        bool           `json:"sleepApplication,omitempty" toml:"sleepApplication,omitempty"`
+}
+
+// RailwayCommand is a command given either as a single string or a list of commands
+type RailwayCommand []string
+
+func (c *RailwayCommand) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*c = RailwayCommand{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*c = list
+	return nil
+}
+
+func (c *RailwayCommand) UnmarshalTOML(data any) error {
+	switch value := data.(type) {
+	case string:
+		*c = RailwayCommand{value}
+	case []any:
+		for _, item := range value {
+			command, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("preDeployCommand entries must be strings, got %T", item)
+			}
+			*c = append(*c, command)
+		}
+	default:
+		return fmt.Errorf("preDeployCommand must be a string or list of strings, got %T", data)
+	}
+	return nil
 }
 
 func (r *RailwaySignal) parseRailwayConfig(configPath string) (*RailwayConfig, error) {
@@ -115,6 +208,11 @@ func (r *RailwaySignal) parseRailwayConfig(configPath string) (*RailwayConfig, e
 }
 
 func determineNetworkFromRailway(config *RailwayConfig) types.Network {
+	// Cron jobs run to completion and don't receive traffic
+	if determineRuntimeFromRailway(config) == types.RuntimeScheduled && config.Deploy.HealthcheckPath == "" {
+		return types.NetworkNone
+	}
+
 	// If there's a health check path, it's likely web-facing
 	if config.Deploy != nil && config.Deploy.HealthcheckPath != "" {
 		return types.NetworkPublic
@@ -129,6 +227,13 @@ func determineNetworkFromRailway(config *RailwayConfig) types.Network {
 	return types.NetworkPrivate
 }
 
+func determineRuntimeFromRailway(config *RailwayConfig) types.Runtime {
+	if config.Deploy != nil && config.Deploy.CronSchedule != "" {
+		return types.RuntimeScheduled
+	}
+	return types.RuntimeContinuous
+}
+
 func determineBuildFromRailway(config *RailwayConfig) types.Build {
 	// Railway services are built from source (that's the primary use case)
 	return types.BuildFromSource
@@ -2,6 +2,7 @@ package signals
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/railwayapp/turnout/internal/discovery/types"
@@ -13,6 +14,8 @@ type RenderSignal struct {
 	filesystem  filesystems.FileSystem
 	configPaths []string          // all found render.yaml files
 	configDirs  map[string]string // config path -> directory path
+
+	skipped
 }
 
 func NewRenderSignal(filesystem filesystems.FileSystem) *RenderSignal {
@@ -23,7 +26,13 @@ func (r *RenderSignal) Confidence() int {
 	return 95 // Highest confidence - Render Blueprints are explicit production deployment specs
 }
 
+// ServiceConfidence distrusts services whose rootDir or Dockerfile isn't there
+func (r *RenderSignal) ServiceConfidence(service types.Service) int {
+	return staleConfidence(service, r.Confidence())
+}
+
 func (r *RenderSignal) Reset() {
+	r.skipped = skipped{}
 	r.configPaths = nil
 	r.configDirs = make(map[string]string)
 }
@@ -48,18 +57,34 @@ func (r *RenderSignal) GenerateServices(ctx context.Context) ([]types.Service, e
 	for _, configPath := range r.configPaths {
 		config, err := r.parseRenderConfig(configPath)
 		if err != nil {
-			continue // Skip broken configs
+			r.skip(configPath, err)
+			continue
 		}
 
-		buildPath := r.configDirs[configPath]
+		configDir := r.configDirs[configPath]
 		// Add regular services
 		for _, renderService := range config.Services {
+			if isRenderKeyValue(renderService) {
+				allServices = append(allServices, renderKeyValueService(renderService, configPath))
+				continue
+			}
+
+			// Render builds from the repo root unless the service sets rootDir
+			buildPath, err := repoDir(r.filesystem, configDir, "rootDir", renderService.RootDir)
+			if err != nil {
+				r.skip(configPath, fmt.Errorf("service %s: %w", renderService.Name, err))
+				continue
+			}
+
 			service := types.Service{
-				Name:      renderService.Name,
-				Network:   determineNetworkFromRender(renderService),
-				Runtime:   determineRuntimeFromRender(renderService),
-				Build:     determineBuildFromRender(renderService),
-				BuildPath: buildPath, // Render builds from repo root by default
+				Name:            renderService.Name,
+				Network:         determineNetworkFromRender(renderService),
+				Runtime:         determineRuntimeFromRender(renderService),
+				Build:           determineBuildFromRender(renderService),
+				BuildPath:       buildPath,
+				Schedule:        renderService.Schedule,
+				StartCommand:    renderService.StartCommand,
+				HealthcheckPath: renderService.HealthCheckPath,
 				Configs: []types.ConfigRef{
 					{Type: "render", Path: configPath},
 				},
@@ -70,6 +95,20 @@ func (r *RenderSignal) GenerateServices(ctx context.Context) ([]types.Service, e
 				service.Image = renderService.Image.URL
 			}
 
+			if buildPath != configDir {
+				pathExists(r.filesystem, &service, configPath, "rootDir", buildPath)
+			}
+			if renderService.DockerfilePath != "" && len(service.Missing) == 0 {
+				// Blueprints write it relative to rootDir or to the repository, so either will do
+				dockerfile := r.filesystem.Join(buildPath, renderService.DockerfilePath)
+				if !r.filesystem.Exists(dockerfile) {
+					dockerfile = r.filesystem.Join(configDir, renderService.DockerfilePath)
+				}
+				if pathExists(r.filesystem, &service, configPath, "dockerfilePath", dockerfile) {
+					service.Configs = append(service.Configs, types.ConfigRef{Type: "dockerfile", Path: dockerfile})
+				}
+			}
+
 			allServices = append(allServices, service)
 		}
 
@@ -92,6 +131,25 @@ func (r *RenderSignal) GenerateServices(ctx context.Context) ([]types.Service, e
 	return allServices, nil
 }
 
+// isRenderKeyValue reports whether a service is a managed Redis-compatible key value store
+func isRenderKeyValue(service RenderService) bool {
+	return service.Type == "redis" || service.Type == "keyvalue"
+}
+
+func renderKeyValueService(renderService RenderService, configPath string) types.Service {
+	return types.Service{
+		Name:    renderService.Name,
+		Network: types.NetworkPrivate, // Key value stores are only reachable by other services
+		Runtime: types.RuntimeContinuous,
+		Build:   types.BuildFromImage,
+		Image:   backingServices["redis"].Image,
+		Port:    backingServices["redis"].Port,
+		Configs: []types.ConfigRef{
+			{Type: "render", Path: configPath},
+		},
+	}
+}
+
 // RenderConfig represents the render.yaml blueprint structure
 type RenderConfig struct {
 	Services     []RenderService     `yaml:"services"`
@@ -107,9 +165,11 @@ type RenderService struct {
 	Plan            string         `yaml:"plan,omitempty"`
 	Region          string         `yaml:"region,omitempty"`
 	Repo            string         `yaml:"repo,omitempty"`
+	RootDir         string         `yaml:"rootDir,omitempty"`
 	Branch          string         `yaml:"branch,omitempty"`
 	BuildCommand    string         `yaml:"buildCommand,omitempty"`
 	StartCommand    string         `yaml:"startCommand,omitempty"`
+	DockerfilePath  string         `yaml:"dockerfilePath,omitempty"`
 	Schedule        string         `yaml:"schedule,omitempty"`
 	Domains         []string       `yaml:"domains,omitempty"`
 	HealthCheckPath string         `yaml:"healthCheckPath,omitempty"`
@@ -0,0 +1,141 @@
+package signals
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+)
+
+// Fly machine presets, in vCPUs and MB of memory
+var flyVMSizes = map[string]types.Resources{
+	"shared-cpu-1x":   {CPU: 1, MemoryMB: 256},
+	"shared-cpu-2x":   {CPU: 2, MemoryMB: 512},
+	"shared-cpu-4x":   {CPU: 4, MemoryMB: 1024},
+	"shared-cpu-8x":   {CPU: 8, MemoryMB: 2048},
+	"performance-1x":  {CPU: 1, MemoryMB: 2048},
+	"performance-2x":  {CPU: 2, MemoryMB: 4096},
+	"performance-4x":  {CPU: 4, MemoryMB: 8192},
+	"performance-8x":  {CPU: 8, MemoryMB: 16384},
+	"performance-16x": {CPU: 16, MemoryMB: 32768},
+}
+
+// flyVMResources reads a [[vm]] section: a preset size, with cpus and memory
+// overriding it
+func flyVMResources(vm FlyVM) *types.Resources {
+	resources := flyVMSizes[strings.ToLower(vm.Size)]
+	if vm.CPUs > 0 {
+		resources.CPU = float64(vm.CPUs)
+	}
+	if memory := parseMemoryMB(vm.Memory); memory > 0 {
+		resources.MemoryMB = memory
+	}
+	if vm.MemoryMB > 0 {
+		resources.MemoryMB = vm.MemoryMB
+	}
+	if resources == (types.Resources{}) {
+		return nil
+	}
+	return &resources
+}
+
+// DigitalOcean's legacy App Platform instance sizes. Current slugs, like
+// apps-s-1vcpu-2gb, spell their size out.
+var doInstanceSizes = map[string]types.Resources{
+	"basic-xxs":       {CPU: 1, MemoryMB: 512},
+	"basic-xs":        {CPU: 1, MemoryMB: 1024},
+	"basic-s":         {CPU: 1, MemoryMB: 2048},
+	"basic-m":         {CPU: 2, MemoryMB: 4096},
+	"professional-xs": {CPU: 1, MemoryMB: 1024},
+	"professional-s":  {CPU: 1, MemoryMB: 2048},
+	"professional-m":  {CPU: 2, MemoryMB: 4096},
+	"professional-1l": {CPU: 1, MemoryMB: 4096},
+	"professional-l":  {CPU: 2, MemoryMB: 8192},
+	"professional-xl": {CPU: 4, MemoryMB: 16384},
+}
+
+var doInstanceSlugPattern = regexp.MustCompile(`(\d+)vcpu-(\d+(?:\.\d+)?)gb`)
+
+// doInstanceResources reads an instance_size_slug, or returns nil if it's unknown
+func doInstanceResources(slug string) *types.Resources {
+	slug = strings.ToLower(slug)
+	if resources, ok := doInstanceSizes[slug]; ok {
+		return &resources
+	}
+	match := doInstanceSlugPattern.FindStringSubmatch(slug)
+	if match == nil {
+		return nil
+	}
+	cpu, _ := strconv.ParseFloat(match[1], 64)
+	memory, _ := strconv.ParseFloat(match[2], 64)
+	return &types.Resources{CPU: cpu, MemoryMB: int(memory * 1024)}
+}
+
+// kubernetesResources reads a container's resources, preferring its limits, the most
+// it may use, to its requests
+func kubernetesResources(limits, requests map[string]string) *types.Resources {
+	var resources types.Resources
+	for _, quantities := range []map[string]string{requests, limits} {
+		if cpu := parseCPU(quantities["cpu"]); cpu > 0 {
+			resources.CPU = cpu
+		}
+		if memory := parseMemoryMB(quantities["memory"]); memory > 0 {
+			resources.MemoryMB = memory
+		}
+	}
+	if resources == (types.Resources{}) {
+		return nil
+	}
+	return &resources
+}
+
+// parseCPU parses a CPU quantity, like 2, 0.5 or Kubernetes' 500m
+func parseCPU(quantity string) float64 {
+	quantity = strings.TrimSpace(quantity)
+	if millis, ok := strings.CutSuffix(quantity, "m"); ok {
+		cpu, err := strconv.ParseFloat(millis, 64)
+		if err != nil {
+			return 0
+		}
+		return cpu / 1000
+	}
+	cpu, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return 0
+	}
+	return cpu
+}
+
+// Memory unit suffixes, in MB, as Kubernetes, compose and Fly write them
+var memoryUnits = []struct {
+	suffix string
+	mb     float64
+}{
+	{"gib", 1024}, {"gi", 1024}, {"gb", 1024}, {"g", 1024},
+	{"mib", 1}, {"mi", 1}, {"mb", 1}, {"m", 1},
+	{"kib", 1.0 / 1024}, {"ki", 1.0 / 1024}, {"kb", 1.0 / 1024}, {"k", 1.0 / 1024},
+}
+
+// parseMemoryMB parses a memory quantity, like 512Mi, 1gb or 2G, into MB. Bare
+// numbers are bytes.
+func parseMemoryMB(quantity string) int {
+	quantity = strings.ToLower(strings.TrimSpace(quantity))
+	if quantity == "" {
+		return 0
+	}
+	for _, unit := range memoryUnits {
+		if number, ok := strings.CutSuffix(quantity, unit.suffix); ok {
+			value, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+			if err != nil {
+				return 0
+			}
+			return int(value * unit.mb)
+		}
+	}
+	bytes, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return 0
+	}
+	return int(bytes / (1 << 20))
+}
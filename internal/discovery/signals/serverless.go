@@ -13,6 +13,8 @@ type ServerlessSignal struct {
 	filesystem  filesystems.FileSystem
 	configPaths []string          // all found serverless.yml files
 	configDirs  map[string]string // config path -> directory path
+
+	skipped
 }
 
 func NewServerlessSignal(filesystem filesystems.FileSystem) *ServerlessSignal {
@@ -24,6 +26,7 @@ func (s *ServerlessSignal) Confidence() int {
 }
 
 func (s *ServerlessSignal) Reset() {
+	s.skipped = skipped{}
 	s.configPaths = nil
 	s.configDirs = make(map[string]string)
 }
@@ -47,7 +50,8 @@ func (s *ServerlessSignal) GenerateServices(ctx context.Context) ([]types.Servic
 	for _, configPath := range s.configPaths {
 		config, err := s.parseServerlessConfig(configPath)
 		if err != nil {
-			continue // Skip broken configs
+			s.skip(configPath, err)
+			continue
 		}
 
 		buildPath := s.configDirs[configPath]
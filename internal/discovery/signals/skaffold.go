@@ -2,18 +2,27 @@ package signals
 
 import (
 	"context"
+	"maps"
+	"path"
+	"slices"
 	"strings"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest"
 	"github.com/railwayapp/turnout/internal/discovery/types"
 	"github.com/railwayapp/turnout/internal/filesystems"
 	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/strvals"
 )
 
 type SkaffoldSignal struct {
 	filesystem  filesystems.FileSystem
 	configPaths []string          // all found skaffold.yaml files
 	configDirs  map[string]string // config path -> directory path
+
+	// Profiles are applied in order over each config like `skaffold -p`
+	Profiles []string
+
+	skipped
 }
 
 func NewSkaffoldSignal(filesystem filesystems.FileSystem) *SkaffoldSignal {
@@ -25,6 +34,7 @@ func (s *SkaffoldSignal) Confidence() int {
 }
 
 func (s *SkaffoldSignal) Reset() {
+	s.skipped = skipped{}
 	s.configPaths = nil
 	s.configDirs = make(map[string]string)
 }
@@ -48,17 +58,20 @@ func (s *SkaffoldSignal) GenerateServices(ctx context.Context) ([]types.Service,
 	for _, configPath := range s.configPaths {
 		config, err := s.parseSkaffoldConfig(configPath)
 		if err != nil {
-			continue // Skip broken configs
+			s.skip(configPath, err)
+			continue
 		}
 
 		buildPath := s.configDirs[configPath]
+		s.applyProfiles(config)
+		workloads := analyzeKubernetesWorkloads(s.deployedObjects(config, buildPath))
 
 		// Skaffold can define multiple services in one config
 		if len(config.Build.Artifacts) > 0 {
 			for _, artifact := range config.Build.Artifacts {
 				service := types.Service{
 					Name:      s.deriveServiceName(artifact.ImageName, buildPath),
-					Network:   s.determineNetworkFromSkaffold(config),
+					Network:   types.NetworkPrivate,    // Conservative default when no manifest runs the image
 					Runtime:   types.RuntimeContinuous, // Skaffold services are typically continuous
 					Build:     s.determineBuildFromSkaffold(artifact),
 					BuildPath: s.determineBuildPath(artifact, buildPath),
@@ -72,6 +85,15 @@ func (s *SkaffoldSignal) GenerateServices(ctx context.Context) ([]types.Service,
 					service.Image = artifact.ImageName
 				}
 
+				if workload, ok := workloadForImage(workloads, artifact.ImageName); ok {
+					deployed := kubernetesWorkloadService(workload, types.ConfigRef{})
+					service.Network = deployed.Network
+					service.Runtime = deployed.Runtime
+					service.Schedule = deployed.Schedule
+					service.Port = deployed.Port
+					service.StartCommand = deployed.StartCommand
+				}
+
 				services = append(services, service)
 			}
 		} else {
@@ -117,10 +139,156 @@ func (s *SkaffoldSignal) deriveServiceName(imageName, buildPath string) string {
 	return s.filesystem.Base(buildPath)
 }
 
-func (s *SkaffoldSignal) determineNetworkFromSkaffold(config *latest.SkaffoldConfig) types.Network {
-	// Skaffold deploys to Kubernetes, but we can't easily determine if services are public
-	// without parsing the Kubernetes manifests. Conservative default.
-	return types.NetworkPrivate
+// applyProfiles overlays the selected profiles' build and deploy sections on the config.
+// JSON patches aren't applied.
+func (s *SkaffoldSignal) applyProfiles(config *latest.SkaffoldConfig) {
+	for _, name := range s.Profiles {
+		for _, profile := range config.Profiles {
+			if profile.Name != name {
+				continue
+			}
+			if len(profile.Build.Artifacts) > 0 {
+				config.Build.Artifacts = profile.Build.Artifacts
+			}
+			if profile.Deploy.KubectlDeploy != nil {
+				config.Deploy.KubectlDeploy = profile.Deploy.KubectlDeploy
+			}
+			if profile.Deploy.HelmDeploy != nil {
+				config.Deploy.HelmDeploy = profile.Deploy.HelmDeploy
+			}
+			if profile.Deploy.KustomizeDeploy != nil {
+				config.Deploy.KustomizeDeploy = profile.Deploy.KustomizeDeploy
+			}
+		}
+	}
+}
+
+// deployedObjects loads the Kubernetes objects a config deploys with kubectl, Helm and kustomize
+func (s *SkaffoldSignal) deployedObjects(config *latest.SkaffoldConfig, configDir string) []kubernetesObject {
+	var objects []kubernetesObject
+
+	if kubectl := config.Deploy.KubectlDeploy; kubectl != nil {
+		for _, path := range s.matchManifests(configDir, kubectl.Manifests) {
+			objects = append(objects, s.readManifests(path)...)
+		}
+	}
+
+	if kustomize := config.Deploy.KustomizeDeploy; kustomize != nil {
+		paths := kustomize.KustomizePaths
+		if len(paths) == 0 {
+			paths = []string{"."} // Skaffold's default
+		}
+		for _, path := range paths {
+			objects = append(objects, s.kustomizeObjects(s.filesystem.Join(configDir, path), 0)...)
+		}
+	}
+
+	if helm := config.Deploy.HelmDeploy; helm != nil {
+		renderer := &HelmSignal{filesystem: s.filesystem}
+		for _, release := range helm.Releases {
+			if release.ChartPath == "" {
+				continue // Remote charts can't be rendered offline
+			}
+			chartDir := s.filesystem.Join(configDir, release.ChartPath)
+
+			var valuesFiles []string
+			for _, valuesFile := range release.ValuesFiles {
+				if rel, err := s.filesystem.Rel(chartDir, s.filesystem.Join(configDir, valuesFile)); err == nil {
+					valuesFiles = append(valuesFiles, rel)
+				}
+			}
+
+			// Artifact overrides point values at the images Skaffold builds
+			setValues := map[string]any{}
+			for _, values := range []map[string]string{release.ArtifactOverrides, release.SetValues} {
+				for key, value := range values {
+					_ = strvals.ParseInto(key+"="+value, setValues)
+				}
+			}
+
+			rendered, err := renderer.renderChartWithValues(chartDir, valuesFiles, setValues)
+			if err != nil {
+				continue
+			}
+			for _, name := range slices.Sorted(maps.Keys(rendered.manifests)) {
+				objects = append(objects, parseKubernetesManifests([]byte(rendered.manifests[name]))...)
+			}
+		}
+	}
+
+	return objects
+}
+
+// matchManifests resolves manifest globs relative to the config directory
+func (s *SkaffoldSignal) matchManifests(configDir string, patterns []string) []string {
+	var matches []string
+	_ = s.filesystem.Walk(configDir, func(filePath string, info filesystems.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := s.filesystem.Rel(configDir, filePath)
+		if err != nil {
+			return nil
+		}
+		rel = strings.ReplaceAll(rel, "\\", "/")
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(strings.TrimPrefix(pattern, "./"), rel); matched {
+				matches = append(matches, filePath)
+				break
+			}
+		}
+		return nil
+	})
+	return matches
+}
+
+func (s *SkaffoldSignal) readManifests(path string) []kubernetesObject {
+	content, err := s.filesystem.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return parseKubernetesManifests(content)
+}
+
+// kustomizeObjects follows a kustomization's resources, ignoring patches and transformers
+func (s *SkaffoldSignal) kustomizeObjects(dir string, depth int) []kubernetesObject {
+	if depth > 10 {
+		return nil // Guard against cyclic bases
+	}
+
+	var kustomization struct {
+		Resources []string `yaml:"resources"`
+		Bases     []string `yaml:"bases"`
+	}
+	found := false
+	for _, name := range []string{"kustomization.yaml", "kustomization.yml", "Kustomization"} {
+		content, err := s.filesystem.ReadFile(s.filesystem.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if err := yaml.Unmarshal(content, &kustomization); err != nil {
+			return nil
+		}
+		found = true
+		break
+	}
+	if !found {
+		return nil
+	}
+
+	var objects []kubernetesObject
+	for _, resource := range append(kustomization.Resources, kustomization.Bases...) {
+		if strings.Contains(resource, "://") || strings.HasPrefix(resource, "github.com/") {
+			continue // Remote resources
+		}
+		resourcePath := s.filesystem.Join(dir, resource)
+		if strings.HasSuffix(resource, ".yaml") || strings.HasSuffix(resource, ".yml") || strings.HasSuffix(resource, ".json") {
+			objects = append(objects, s.readManifests(resourcePath)...)
+		} else {
+			objects = append(objects, s.kustomizeObjects(resourcePath, depth+1)...)
+		}
+	}
+	return objects
 }
 
 func (s *SkaffoldSignal) determineBuildFromSkaffold(artifact *latest.Artifact) types.Build {
@@ -0,0 +1,522 @@
+package signals
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+const (
+	// Confidence for what an app's application config says it listens on, above a
+	// Dockerfile's EXPOSE, which only documents it
+	springConfigConfidence = 80
+
+	// Confidence for the jar a build packages, below a Dockerfile's CMD, which may run
+	// it from elsewhere
+	springJarConfidence = 60
+)
+
+// Profiles an app is deployed with, when its config doesn't activate one itself
+var springDeployProfiles = []string{"prod", "production"}
+
+var (
+	springBootPattern = regexp.MustCompile(`spring-boot|org\.springframework\.boot`)
+
+	// The Maven plugin configured in <pluginManagement> only applies to modules using it
+	pomPluginManagementPattern = regexp.MustCompile(`(?s)<pluginManagement>.*?</pluginManagement>`)
+
+	// plugins { id 'org.springframework.boot' version '3.2.0' }, or apply plugin: ...
+	gradleBootPluginPattern = regexp.MustCompile(`(?m)(?:\bid\s*\(?\s*|apply\s*\(?\s*plugin\s*[:=]\s*)["']org\.springframework\.boot["']\)?(.*)$`)
+	gradleIncludePattern    = regexp.MustCompile(`(?m)^\s*include\s*\(?(.+)$`)
+	gradleProjectPattern    = regexp.MustCompile(`["']:?([\w.:-]+)["']`)
+	gradleVersionPattern    = regexp.MustCompile(`(?m)^\s*version\s*=?\s*["']?([\w.+-]+)["']?\s*$`)
+	gradleRootNamePattern   = regexp.MustCompile(`rootProject\.name\s*=\s*["']([^"']+)["']`)
+
+	// A placeholder for an environment variable or property, like ${PORT:8080}
+	springPlaceholderPattern = regexp.MustCompile(`^\$\{([^:}]+)(?::([^}]*))?\}$`)
+)
+
+// SpringBootSignal reads Spring Boot builds, Maven or Gradle, for the apps they run.
+// Multi-module builds build from their root, so each module that applies the Spring
+// Boot plugin, or has an application config, becomes a service built there. The
+// application.properties or .yml of each app, with the profile it deploys with, gives
+// its port and actuator healthcheck.
+type SpringBootSignal struct {
+	filesystem filesystems.FileSystem
+	buildPaths []string // pom.xml, build.gradle and settings.gradle files
+
+	skipped
+}
+
+func NewSpringBootSignal(filesystem filesystems.FileSystem) *SpringBootSignal {
+	return &SpringBootSignal{filesystem: filesystem}
+}
+
+func (s *SpringBootSignal) Confidence() int {
+	return 90 // High confidence - builds say which modules are apps, and their config where they listen
+}
+
+func (s *SpringBootSignal) Reset() {
+	s.skipped = skipped{}
+	s.buildPaths = nil
+}
+
+func (s *SpringBootSignal) ObserveEntry(ctx context.Context, rootPath string, entry filesystems.DirEntry) error {
+	if entry.IsDir() {
+		return nil
+	}
+	switch entry.Name() {
+	case "pom.xml", "build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts":
+		s.buildPaths = append(s.buildPaths, s.filesystem.Join(rootPath, entry.Name()))
+	}
+	return nil
+}
+
+// springBuild is a Maven or Gradle project, an app, a library, or a build of modules
+type springBuild struct {
+	path    string
+	dir     string
+	gradle  bool
+	content string
+	pom     pomProject
+	modules []string // directories of the modules a root builds
+}
+
+type pomProject struct {
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Packaging  string `xml:"packaging"`
+	FinalName  string `xml:"build>finalName"`
+	Parent     struct {
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Modules []string `xml:"modules>module"`
+}
+
+func (s *SpringBootSignal) GenerateServices(ctx context.Context) ([]types.Service, error) {
+	builds := make(map[string]*springBuild)
+	settings := make(map[string]string) // directory -> settings.gradle content
+	for _, buildPath := range s.buildPaths {
+		content, err := filesystems.ReadTextFile(s.filesystem, buildPath)
+		if err != nil {
+			s.skip(buildPath, err)
+			continue
+		}
+		dir := s.filesystem.Dir(buildPath)
+		if strings.HasPrefix(s.filesystem.Base(buildPath), "settings.gradle") {
+			settings[dir] = string(content)
+			continue
+		}
+
+		build := &springBuild{path: buildPath, dir: dir, content: string(content)}
+		if build.gradle = strings.HasPrefix(s.filesystem.Base(buildPath), "build.gradle"); !build.gradle {
+			if err := xml.Unmarshal(content, &build.pom); err != nil {
+				s.skip(buildPath, err)
+				continue
+			}
+			for _, module := range build.pom.Modules {
+				build.modules = append(build.modules, s.filesystem.Join(dir, strings.TrimSpace(module)))
+			}
+		}
+		if existing := builds[dir]; existing == nil || existing.gradle {
+			builds[dir] = build // Maven wins where a directory has both
+		}
+	}
+
+	// Gradle roots list their modules in settings.gradle, which may have no build of its own
+	for dir, content := range settings {
+		build := builds[dir]
+		if build == nil {
+			build = &springBuild{path: s.filesystem.Join(dir, "settings.gradle"), dir: dir, gradle: true}
+			builds[dir] = build
+		}
+		for _, include := range gradleIncludePattern.FindAllStringSubmatch(content, -1) {
+			for _, project := range gradleProjectPattern.FindAllStringSubmatch(include[1], -1) {
+				build.modules = append(build.modules, s.filesystem.Join(dir, strings.ReplaceAll(project[1], ":", "/")))
+			}
+		}
+	}
+
+	parents := make(map[string]*springBuild)
+	for _, build := range builds {
+		for _, module := range build.modules {
+			parents[module] = build
+		}
+	}
+
+	var services []types.Service
+	for _, buildPath := range s.buildPaths {
+		build := builds[s.filesystem.Dir(buildPath)]
+		if build == nil || build.path != buildPath {
+			continue
+		}
+		if service, ok := s.appService(build, parents, settings); ok {
+			services = append(services, service)
+		}
+	}
+	return services, nil
+}
+
+// appService maps a Spring Boot app onto a service built from its build's root, or
+// reports false for libraries and builds of modules
+func (s *SpringBootSignal) appService(build *springBuild, parents map[string]*springBuild, settings map[string]string) (types.Service, bool) {
+	// The build and the builds it's a module of, up to its root
+	chain := []*springBuild{build}
+	for parent := parents[build.dir]; parent != nil && !slices.Contains(chain, parent); parent = parents[parent.dir] {
+		chain = append(chain, parent)
+	}
+	root := chain[len(chain)-1]
+
+	if build.content == "" || build.pom.Packaging == "pom" || !slices.ContainsFunc(chain, func(b *springBuild) bool {
+		return springBootPattern.MatchString(b.content)
+	}) {
+		return types.Service{}, false
+	}
+	config := s.applicationConfig(build.dir)
+	plugin := s.appliesBootPlugin(build)
+	if (len(chain) > 1 || len(build.modules) > 0) && !plugin && len(config.paths) == 0 {
+		return types.Service{}, false // A library the apps of the build depend on
+	}
+
+	service := types.Service{
+		Name:      s.filesystem.Base(build.dir),
+		Network:   types.NetworkPublic,
+		Runtime:   types.RuntimeContinuous,
+		Build:     types.BuildFromSource,
+		BuildPath: root.dir,
+		Configs:   []types.ConfigRef{{Type: "spring-boot", Path: build.path}},
+	}
+	if root != build && root.content != "" {
+		service.Configs = append(service.Configs, types.ConfigRef{Type: "spring-boot", Path: root.path})
+	}
+	for _, path := range config.paths {
+		service.Configs = append(service.Configs, types.ConfigRef{Type: "spring-boot", Path: path})
+	}
+	if config.deployProfile != "" {
+		service.Variables = map[string]string{"SPRING_PROFILES_ACTIVE": config.deployProfile}
+	}
+
+	if plugin {
+		if jar := s.jarPath(build, chain, settings); jar != "" {
+			if rel, err := s.filesystem.Rel(root.dir, build.dir); err == nil {
+				service.StartCommand = "java -jar " + s.filesystem.Join(rel, jar)
+				service.SetProvenance("StartCommand", "spring-boot:"+build.path, springJarConfidence)
+			}
+		}
+	}
+
+	if config.value("spring.main.web-application-type") == "none" {
+		service.Network = types.NetworkNone
+		return service, true
+	}
+
+	port := frameworkDefaults["Spring Boot"].Port
+	if value, ok := config.properties["server.port"]; ok {
+		if configured, err := strconv.Atoi(config.value("server.port")); err == nil && configured > 0 {
+			port = configured
+			service.Port = port
+			service.SetProvenance("Port", "spring-boot:"+value.path, springConfigConfidence)
+		}
+	}
+	if service.Port == 0 {
+		service.Port = port
+		service.SetProvenance("Port", "framework-default:Spring Boot", frameworkDefaultConfidence)
+	}
+
+	actuator := slices.ContainsFunc(chain, func(b *springBuild) bool {
+		return strings.Contains(b.content, "spring-boot-starter-actuator")
+	})
+	if path, ok := config.healthcheckPath(port); actuator && ok {
+		service.HealthcheckPath = path
+		service.SetProvenance("HealthcheckPath", "spring-boot:"+build.path, springConfigConfidence)
+	}
+	return service, true
+}
+
+// appliesBootPlugin reports whether a build packages an executable jar with the
+// spring-boot-maven-plugin, or Gradle's org.springframework.boot plugin
+func (s *SpringBootSignal) appliesBootPlugin(build *springBuild) bool {
+	if !build.gradle {
+		return strings.Contains(pomPluginManagementPattern.ReplaceAllString(build.content, ""), "spring-boot-maven-plugin")
+	}
+	for _, match := range gradleBootPluginPattern.FindAllStringSubmatch(build.content, -1) {
+		if !strings.Contains(match[1], "apply false") {
+			return true
+		}
+	}
+	return false
+}
+
+// jarPath is where a build packages its executable jar, relative to the build, or ""
+// if its name can't be worked out
+func (s *SpringBootSignal) jarPath(build *springBuild, chain []*springBuild, settings map[string]string) string {
+	if !build.gradle {
+		name := build.pom.FinalName
+		if name == "" {
+			version := build.pom.Version
+			if version == "" {
+				version = build.pom.Parent.Version
+			}
+			name = build.pom.ArtifactID + "-" + version
+		}
+		name = strings.NewReplacer("${project.artifactId}", build.pom.ArtifactID, "${project.version}", build.pom.Version).Replace(name)
+		if strings.Contains(name, "${") || strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-") {
+			return "target/*.jar"
+		}
+		return "target/" + name + ".jar"
+	}
+
+	// Gradle names the jar after the project and its version, set by it, its root, or
+	// the root's gradle.properties
+	root := chain[len(chain)-1]
+	name := s.filesystem.Base(build.dir)
+	if match := gradleRootNamePattern.FindStringSubmatch(settings[build.dir]); match != nil && build == root {
+		name = match[1]
+	}
+	sources := []string{build.content, root.content}
+	if properties, err := s.filesystem.ReadFile(s.filesystem.Join(root.dir, "gradle.properties")); err == nil {
+		sources = append(sources, string(properties))
+	}
+	for _, source := range sources {
+		if match := gradleVersionPattern.FindStringSubmatch(source); match != nil {
+			return "build/libs/" + name + "-" + match[1] + ".jar"
+		}
+	}
+	return ""
+}
+
+// springProperty is a property's value, and the config file setting it
+type springProperty struct {
+	value string
+	path  string
+}
+
+// springConfig is the properties an app runs with: its application config, overlaid
+// with the profiles it deploys with
+type springConfig struct {
+	properties    map[string]springProperty
+	paths         []string
+	deployProfile string // the profile SPRING_PROFILES_ACTIVE must activate, if the config doesn't
+}
+
+// value is a property's value, with a placeholder resolved to its default
+func (c springConfig) value(key string) string {
+	value := c.properties[key].value
+	if match := springPlaceholderPattern.FindStringSubmatch(value); match != nil {
+		return match[2]
+	}
+	return value
+}
+
+// healthcheckPath is where the actuator serves health, under the app's context path,
+// or false if the config hides it or moves the actuator to a port other than the app's
+func (c springConfig) healthcheckPath(port int) (string, bool) {
+	exclude := strings.Split(c.value("management.endpoints.web.exposure.exclude"), ",")
+	if slices.ContainsFunc(exclude, func(endpoint string) bool {
+		endpoint = strings.TrimSpace(endpoint)
+		return endpoint == "health" || endpoint == "*"
+	}) {
+		return "", false
+	}
+	if c.value("management.endpoint.health.enabled") == "false" || c.value("management.endpoint.health.access") == "none" {
+		return "", false
+	}
+	if management := c.value("management.server.port"); management != "" && management != strconv.Itoa(port) {
+		return "", false
+	}
+
+	contextPath := c.value("server.servlet.context-path")
+	if contextPath == "" {
+		contextPath = c.value("spring.webflux.base-path")
+	}
+	basePath := "/actuator"
+	if _, ok := c.properties["management.endpoints.web.base-path"]; ok {
+		basePath = c.value("management.endpoints.web.base-path")
+	}
+	health := "health"
+	if mapping := c.value("management.endpoints.web.path-mapping.health"); mapping != "" {
+		health = mapping
+	}
+	return "/" + strings.Trim(strings.Join([]string{strings.Trim(contextPath, "/"), strings.Trim(basePath, "/"), health}, "/"), "/"), true
+}
+
+// springDocument is a document of an application config, with the profiles it's
+// activated on, if any
+type springDocument struct {
+	properties map[string]string
+	profiles   []string
+}
+
+// applicationConfig reads an app's application.properties or .yml under
+// src/main/resources, and the config of the profiles it runs with: those it
+// activates, or the production profile it has config for
+func (s *SpringBootSignal) applicationConfig(dir string) springConfig {
+	config := springConfig{properties: make(map[string]springProperty)}
+	resources := s.filesystem.Join(dir, "src", "main", "resources")
+
+	type file struct {
+		path      string
+		documents []springDocument
+	}
+	read := func(name string) []file {
+		var files []file
+		for _, extension := range []string{".properties", ".yml", ".yaml"} {
+			path := s.filesystem.Join(resources, name+extension)
+			content, err := filesystems.ReadTextFile(s.filesystem, path)
+			if err != nil {
+				continue
+			}
+			documents, err := parseSpringConfig(path, content)
+			if err != nil {
+				s.skip(path, err)
+				continue
+			}
+			files = append(files, file{path: path, documents: documents})
+		}
+		return files
+	}
+	apply := func(path string, document springDocument) {
+		for key, value := range document.properties {
+			config.properties[key] = springProperty{value: value, path: path}
+		}
+		if !slices.Contains(config.paths, path) {
+			config.paths = append(config.paths, path)
+		}
+	}
+
+	base := read("application")
+	for _, f := range base {
+		for _, document := range f.documents {
+			if len(document.profiles) == 0 {
+				apply(f.path, document)
+			}
+		}
+	}
+
+	var profiles []string
+	for _, profile := range strings.Split(config.value("spring.profiles.active"), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+	if len(profiles) == 0 {
+		for _, profile := range springDeployProfiles {
+			hasDocument := slices.ContainsFunc(base, func(f file) bool {
+				return slices.ContainsFunc(f.documents, func(d springDocument) bool { return slices.Contains(d.profiles, profile) })
+			})
+			if hasDocument || len(read("application-"+profile)) > 0 {
+				profiles, config.deployProfile = []string{profile}, profile
+				break
+			}
+		}
+	}
+
+	// Profile documents and files override the base config, in the order they're active
+	for _, profile := range profiles {
+		for _, f := range base {
+			for _, document := range f.documents {
+				if slices.Contains(document.profiles, profile) {
+					apply(f.path, document)
+				}
+			}
+		}
+		for _, f := range read("application-" + profile) {
+			for _, document := range f.documents {
+				if len(document.profiles) == 0 || slices.Contains(document.profiles, profile) {
+					apply(f.path, document)
+				}
+			}
+		}
+	}
+	return config
+}
+
+// parseSpringConfig reads the documents of an application.properties or .yml, split
+// by --- in YAML and #--- in properties, as flat properties like server.port
+func parseSpringConfig(path string, content []byte) ([]springDocument, error) {
+	var documents []springDocument
+	if strings.HasSuffix(path, ".properties") {
+		properties := make(map[string]string)
+		for line := range strings.Lines(string(content)) {
+			line = strings.TrimSpace(line)
+			if line == "#---" || line == "!---" {
+				documents = append(documents, springDocument{properties: properties})
+				properties = make(map[string]string)
+				continue
+			}
+			if line == "" || line[0] == '#' || line[0] == '!' {
+				continue
+			}
+			separator := strings.IndexAny(line, "=:")
+			if separator < 0 {
+				continue
+			}
+			properties[strings.TrimSpace(line[:separator])] = strings.TrimSpace(line[separator+1:])
+		}
+		documents = append(documents, springDocument{properties: properties})
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		for {
+			var document map[string]any
+			err := decoder.Decode(&document)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return documents, err
+			}
+			properties := make(map[string]string)
+			flattenSpringConfig("", document, properties)
+			documents = append(documents, springDocument{properties: properties})
+		}
+	}
+
+	for i, document := range documents {
+		profiles := document.properties["spring.config.activate.on-profile"]
+		if profiles == "" {
+			profiles = document.properties["spring.profiles"] // Before Spring Boot 2.4
+		}
+		for _, profile := range strings.Split(profiles, ",") {
+			if profile = strings.TrimSpace(profile); profile != "" {
+				documents[i].profiles = append(documents[i].profiles, profile)
+			}
+		}
+	}
+	return documents, nil
+}
+
+// flattenSpringConfig flattens nested YAML into properties, joining keys with dots and
+// list items with commas, the way Spring binds them
+func flattenSpringConfig(prefix string, value any, properties map[string]string) {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenSpringConfig(key, child, properties)
+		}
+	case []any:
+		var items []string
+		for _, item := range value {
+			items = append(items, fmt.Sprint(item))
+		}
+		properties[prefix] = strings.Join(items, ",")
+	case nil:
+		properties[prefix] = ""
+	default:
+		properties[prefix] = fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,48 @@
+package signals
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// Confidence for services whose config names paths that aren't in the repository. The
+// config has likely gone stale, so it's the least an explicit config gets: its services
+// still stand, but configs that match the repository, and evidence that agrees, outweigh it.
+const staleConfigConfidence = 80
+
+// pathExists reports whether a path a config names is in the repository, recording it
+// on the service as missing if not
+func pathExists(filesystem filesystems.FileSystem, service *types.Service, config, setting, path string) bool {
+	if filesystem.Exists(path) {
+		return true
+	}
+	service.Missing = append(service.Missing, types.MissingPath{Config: config, Setting: setting, Path: path})
+	return false
+}
+
+// staleConfidence is a platform config's confidence for a service, lowered when the
+// config names paths that aren't in the repository
+func staleConfidence(service types.Service, confidence int) int {
+	if len(service.Missing) > 0 {
+		return staleConfigConfidence
+	}
+	return confidence
+}
+
+// repoDir joins a directory a config names onto the config's own, which platform configs
+// keep at the repository root. One climbing out of it would leave the repository, so
+// it's refused.
+func repoDir(filesystem filesystems.FileSystem, configDir, setting, dir string) (string, error) {
+	dir = path.Clean(strings.Trim(dir, "/"))
+	if dir == ".." || strings.HasPrefix(dir, "../") {
+		return "", fmt.Errorf("%s %s is outside the repository", setting, dir)
+	}
+	if dir == "." {
+		return configDir, nil
+	}
+	return filesystem.Join(configDir, dir), nil
+}
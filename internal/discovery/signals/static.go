@@ -0,0 +1,141 @@
+package signals
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// Default output directories for static site generators, relative to the project directory
+var staticOutputDirs = map[string]string{
+	"Gatsby":     "public",
+	"Hugo":       "public",
+	"Eleventy":   "_site",
+	"Astro":      "dist",
+	"Vite":       "dist",
+	"Docusaurus": "build",
+	"VuePress":   "docs/.vuepress/dist",
+	"Vitepress":  "docs/.vitepress/dist",
+	"Gridsome":   "dist",
+
+	// SPA mode of the server frameworks below, built with ssr: false
+	"Remix":        "build/client",
+	"React Router": "build/client",
+}
+
+// Config files that can override the output directory or switch a generator to SSR
+var staticConfigFiles = map[string][]string{
+	"Astro":    {"astro.config.mjs", "astro.config.ts", "astro.config.js", "astro.config.cjs"},
+	"Vite":     {"vite.config.ts", "vite.config.js", "vite.config.mjs", "vite.config.cjs"},
+	"Hugo":     {"hugo.toml", "hugo.yaml", "config.toml", "config.yaml"},
+	"Eleventy": {"eleventy.config.js", ".eleventy.js", ".eleventy.config.js"},
+}
+
+var (
+	astroServerOutputPattern = regexp.MustCompile(`output\s*:\s*['"](server|hybrid)['"]`)
+	jsOutDirPattern          = regexp.MustCompile(`outDir\s*:\s*['"]([^'"]+)['"]`)
+	hugoPublishDirPattern    = regexp.MustCompile(`publishDir\s*[:=]\s*['"]?([^'"\s]+)['"]?`)
+	eleventyOutputPattern    = regexp.MustCompile(`output\s*:\s*['"]([^'"]+)['"]`)
+	ssrDisabledPattern       = regexp.MustCompile(`\bssr\s*:\s*false\b`)
+)
+
+// Frameworks that render on a server through a Vite plugin, by the package it's in
+var viteServerFrameworks = []struct{ pkg, name string }{
+	{"@remix-run/dev", "Remix"},
+	{"@react-router/dev", "React Router"},
+	{"@tanstack/react-start", "TanStack Start"},
+	{"@tanstack/start", "TanStack Start"},
+	{"@solidjs/start", "SolidStart"},
+	{"@builder.io/qwik-city", "Qwik"},
+}
+
+// Config files of Vite server frameworks that can turn SSR off
+var ssrConfigFiles = []string{"react-router.config.ts", "react-router.config.js", "react-router.config.mjs"}
+
+// viteFramework names the framework a Vite app in dir is built with, and how. A plain
+// Vite app is an SPA, but Remix, React Router and the like render on a server, unless
+// they're set to ssr: false and build an SPA of their own.
+func viteFramework(filesystem filesystems.FileSystem, dir string) (string, types.Build) {
+	var config string
+	for _, name := range staticConfigFiles["Vite"] {
+		if data, err := filesystems.ReadTextFile(filesystem, filesystem.Join(dir, name)); err == nil {
+			config = string(data)
+			break
+		}
+	}
+
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if data, err := filesystem.ReadFile(filesystem.Join(dir, "package.json")); err == nil {
+		_ = json.Unmarshal(data, &pkg)
+	}
+
+	for _, framework := range viteServerFrameworks {
+		_, dep := pkg.Dependencies[framework.pkg]
+		_, devDep := pkg.DevDependencies[framework.pkg]
+		if !dep && !devDep && !strings.Contains(config, framework.pkg) {
+			continue
+		}
+
+		if _, ok := staticOutputDirs[framework.name]; ok {
+			ssrConfig := config
+			for _, name := range ssrConfigFiles {
+				if data, err := filesystems.ReadTextFile(filesystem, filesystem.Join(dir, name)); err == nil {
+					ssrConfig += "\n" + string(data)
+				}
+			}
+			if ssrDisabledPattern.MatchString(ssrConfig) {
+				return framework.name, types.BuildStatic
+			}
+		}
+		return framework.name, types.BuildFromSource
+	}
+	return "Vite", types.BuildStatic
+}
+
+// staticOutputDir resolves where a static site generator writes its build output.
+// Returns false when the project is configured to run as a server instead.
+func staticOutputDir(filesystem filesystems.FileSystem, framework, dir string) (string, bool) {
+	outputDir, ok := staticOutputDirs[framework]
+	if !ok {
+		return "", false
+	}
+
+	for _, name := range staticConfigFiles[framework] {
+		data, err := filesystems.ReadTextFile(filesystem, filesystem.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		content := string(data)
+
+		switch framework {
+		case "Astro":
+			if astroServerOutputPattern.MatchString(content) {
+				return "", false
+			}
+			if match := jsOutDirPattern.FindStringSubmatch(content); match != nil {
+				outputDir = match[1]
+			}
+		case "Vite":
+			if match := jsOutDirPattern.FindStringSubmatch(content); match != nil {
+				outputDir = match[1]
+			}
+		case "Hugo":
+			if match := hugoPublishDirPattern.FindStringSubmatch(content); match != nil {
+				outputDir = match[1]
+			}
+		case "Eleventy":
+			if match := eleventyOutputPattern.FindStringSubmatch(content); match != nil {
+				outputDir = match[1]
+			}
+		}
+		break
+	}
+
+	return outputDir, true
+}
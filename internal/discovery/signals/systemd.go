@@ -0,0 +1,250 @@
+package signals
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+)
+
+// systemdUnit is a unit file's settings by section and key. Keys keep every value
+// they're given, since settings like Environment= and PublishPort= repeat.
+type systemdUnit map[string]map[string][]string
+
+// parseSystemdUnit reads a systemd unit file, or a Quadlet unit, which is one too
+func parseSystemdUnit(content []byte) systemdUnit {
+	unit := make(systemdUnit)
+	var section string
+	var continued string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if continued != "" {
+			line = continued + " " + line
+			continued = ""
+		}
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasSuffix(line, `\`) {
+			continued = strings.TrimSuffix(line, `\`)
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section == "" {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if unit[section] == nil {
+			unit[section] = make(map[string][]string)
+		}
+		if value == "" {
+			delete(unit[section], key) // An empty assignment resets a list
+			continue
+		}
+		unit[section][key] = append(unit[section][key], value)
+	}
+	return unit
+}
+
+// get returns a setting's last value, which is the one systemd uses
+func (u systemdUnit) get(section, key string) string {
+	values := u[section][key]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// splitSystemdWords splits a setting into words as systemd does, on whitespace outside
+// single or double quotes
+func splitSystemdWords(value string) []string {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	inWord := false
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// containerSpec is what a Quadlet unit or a `podman run` command says about a container
+type containerSpec struct {
+	name        string
+	image       string
+	command     string
+	publish     []string // like 8080:80, 127.0.0.1:8080:80/tcp or just 80
+	environment []string // KEY=VALUE
+	volumes     []string // like data:/var/lib/data:Z
+}
+
+// service maps the container onto a service run from its image
+func (c containerSpec) service(config types.ConfigRef) types.Service {
+	service := types.Service{
+		Name:         c.name,
+		Network:      types.NetworkNone,
+		Runtime:      types.RuntimeContinuous,
+		Build:        types.BuildFromImage,
+		Image:        c.image,
+		StartCommand: c.command,
+		Configs:      []types.ConfigRef{config},
+	}
+	publishPorts(&service, c.publish)
+
+	for _, variable := range c.environment {
+		name, value, ok := strings.Cut(variable, "=")
+		if !ok || name == "" {
+			continue // Passed through from the host, like `-e TOKEN`
+		}
+		if service.Variables == nil {
+			service.Variables = make(map[string]string)
+		}
+		service.Variables[name] = value
+	}
+
+	for _, volume := range c.volumes {
+		source, rest, ok := strings.Cut(volume, ":")
+		if !ok || source == "" || strings.ContainsAny(source[:1], "/.~%") {
+			continue // Anonymous volumes and bind mounts of host paths don't persist anything to provision
+		}
+		target, _, _ := strings.Cut(rest, ":")
+		service.Volumes = append(service.Volumes, types.Volume{Name: strings.TrimSuffix(source, ".volume"), MountPath: target})
+	}
+	return service
+}
+
+// publishPorts records ports published on the host. Published services are private,
+// or public on the standard web ports, as compose services are.
+func publishPorts(service *types.Service, ports []string) {
+	for _, port := range ports {
+		mapping, _, _ := strings.Cut(port, "/") // Drop the protocol
+		// The container port is last, after an optional host IP and port
+		separator := strings.LastIndex(mapping, ":")
+		target, err := strconv.Atoi(mapping[separator+1:])
+		if err != nil {
+			continue // A range, like 8000-8010
+		}
+		var published string
+		if separator > 0 {
+			host := mapping[:separator]
+			published = host[strings.LastIndex(host, ":")+1:]
+		}
+		service.PublishedPorts = append(service.PublishedPorts, types.PortMapping{Published: published, Target: target})
+		if service.Port == 0 {
+			service.Port = target
+		}
+
+		switch {
+		case published == "80" || published == "443":
+			service.Network = types.NetworkPublic
+		case service.Network == types.NetworkNone:
+			service.Network = types.NetworkPrivate
+		}
+	}
+}
+
+// Flags of `podman run` and `docker run` that take a value, so the image isn't mistaken
+// for one's value. Others are assumed to be switches, like --rm.
+var containerRunValueFlags = []string{
+	"-a", "--attach", "--add-host", "--cap-add", "--cap-drop", "--cgroupns", "--cidfile",
+	"--conmon-pidfile", "--cpus", "--cpu-shares", "-c", "--device", "--dns", "-e", "--env",
+	"--env-file", "--entrypoint", "--expose", "--health-cmd", "--health-interval", "-h",
+	"--hostname", "--ip", "-l", "--label", "--label-file", "--log-driver", "--log-opt", "-m",
+	"--memory", "--mount", "--name", "--net", "--network", "--platform", "--pod", "-p",
+	"--publish", "--pull", "--restart", "--sdnotify", "--secret", "--security-opt",
+	"--stop-signal", "--stop-timeout", "--tmpfs", "-u", "--user", "--ulimit", "-v",
+	"--volume", "--volumes-from", "-w", "--workdir",
+}
+
+// parseContainerRun reads a `podman run` or `docker run` command line, like a systemd
+// unit's ExecStart=, or returns false if it doesn't run a container
+func parseContainerRun(command string) (containerSpec, bool) {
+	words := splitSystemdWords(command)
+	if len(words) == 0 {
+		return containerSpec{}, false
+	}
+	// ExecStart= prefixes change how systemd runs the command, like - to ignore failure
+	binary := path.Base(strings.TrimLeft(words[0], "-@+!:"))
+	run := slices.Index(words, "run")
+	if (binary != "podman" && binary != "docker") || run < 0 {
+		return containerSpec{}, false
+	}
+
+	var spec containerSpec
+	args := words[run+1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			if arg == "--" {
+				i++
+			}
+			if i < len(args) {
+				spec.image = args[i]
+				spec.command = strings.Join(args[i+1:], " ")
+			}
+			break
+		}
+
+		flag, value, hasValue := strings.Cut(arg, "=")
+		if !strings.HasPrefix(arg, "--") && len(arg) > 2 {
+			flag, value, hasValue = arg[:2], strings.TrimPrefix(arg[2:], "="), true // Like -p8080:80
+		}
+		if !slices.Contains(containerRunValueFlags, flag) {
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		switch flag {
+		case "--name":
+			spec.name = value
+		case "-p", "--publish":
+			spec.publish = append(spec.publish, value)
+		case "-e", "--env":
+			spec.environment = append(spec.environment, value)
+		case "-v", "--volume":
+			spec.volumes = append(spec.volumes, value)
+		}
+	}
+	return spec, spec.image != ""
+}
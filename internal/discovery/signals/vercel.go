@@ -3,6 +3,8 @@ package signals
 import (
 	"context"
 	"encoding/json"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/railwayapp/turnout/internal/discovery/types"
@@ -13,6 +15,8 @@ type VercelSignal struct {
 	filesystem  filesystems.FileSystem
 	configPaths []string          // all found vercel.json files
 	configDirs  map[string]string // config path -> directory path
+
+	skipped
 }
 
 func NewVercelSignal(filesystem filesystems.FileSystem) *VercelSignal {
@@ -24,6 +28,7 @@ func (v *VercelSignal) Confidence() int {
 }
 
 func (v *VercelSignal) Reset() {
+	v.skipped = skipped{}
 	v.configPaths = nil
 	v.configDirs = make(map[string]string)
 }
@@ -43,29 +48,93 @@ func (v *VercelSignal) GenerateServices(ctx context.Context) ([]types.Service, e
 		return nil, nil
 	}
 
-	// Process first config found
-	configPath := v.configPaths[0]
-
-	_, err := v.parseVercelConfig(configPath)
-	if err != nil {
-		return nil, err
+	var services []types.Service
+	for _, configPath := range v.configPaths {
+		config, err := v.parseVercelConfig(configPath)
+		if err != nil {
+			v.skip(configPath, err)
+			continue
+		}
+
+		buildPath := v.configDirs[configPath]
+		name := v.filesystem.Base(buildPath)
+		configs := []types.ConfigRef{{Type: "vercel", Path: configPath}}
+
+		// The deployment itself: the static site or framework app
+		services = append(services, types.Service{
+			Name:      name,
+			Network:   types.NetworkPublic,     // Vercel deployments are web-facing
+			Runtime:   types.RuntimeContinuous, // Web deployments run continuously
+			Build:     types.BuildFromSource,   // Vercel builds from source
+			BuildPath: buildPath,
+			Configs:   configs,
+		})
+
+		// Serverless functions, grouped by the directory they're served from
+		seen := make(map[string]bool)
+		for _, pattern := range vercelFunctionPatterns(config) {
+			functionName := name + "-" + vercelSlug(strings.SplitN(pattern, "/", 2)[0])
+			if seen[functionName] {
+				continue
+			}
+			seen[functionName] = true
+
+			services = append(services, types.Service{
+				Name:      functionName,
+				Network:   types.NetworkPublic, // Functions are invoked over HTTP
+				Runtime:   types.RuntimeContinuous,
+				Build:     types.BuildFromSource,
+				BuildPath: buildPath,
+				Configs:   configs,
+			})
+		}
+
+		// Cron jobs call a path of the deployment on a schedule
+		for _, cron := range config.Crons {
+			services = append(services, types.Service{
+				Name:         name + "-cron-" + vercelSlug(cron.Path),
+				Network:      types.NetworkNone,
+				Runtime:      types.RuntimeScheduled,
+				Build:        types.BuildFromSource,
+				BuildPath:    buildPath,
+				Schedule:     cron.Schedule,
+				StartCommand: cron.Path,
+				Configs:      configs,
+			})
+		}
 	}
 
-	buildPath := v.configDirs[configPath]
-	// Vercel deploys are typically single-service (static site + serverless functions)
-	// but we model it as one service representing the deployment
-	service := types.Service{
-		Name:      v.filesystem.Base(buildPath),
-		Network:   types.NetworkPublic,     // Vercel deployments are web-facing
-		Runtime:   types.RuntimeContinuous, // Web deployments run continuously
-		Build:     types.BuildFromSource,   // Vercel builds from source
-		BuildPath: buildPath,
-		Configs: []types.ConfigRef{
-			{Type: "vercel", Path: configPath},
-		},
-	}
+	return services, nil
+}
 
-	return []types.Service{service}, nil
+// vercelFunctionPatterns lists the source patterns of functions and non-static legacy builds
+func vercelFunctionPatterns(config *VercelConfig) []string {
+	patterns := slices.Sorted(maps.Keys(config.Functions))
+	for _, build := range config.Builds {
+		if build.Use == "@vercel/static" || build.Use == "@vercel/static-build" || build.Use == "@now/static" {
+			continue
+		}
+		patterns = append(patterns, build.Src)
+	}
+	return patterns
+}
+
+// vercelSlug turns a path or glob into something usable in a service name
+func vercelSlug(path string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.Trim(path, "/")) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "root"
+	}
+	return slug
 }
 
 // VercelConfig represents the vercel.json configuration structure
@@ -81,6 +150,12 @@ type VercelConfig struct {
 	Build     *VercelBuildConfig    `json:"build,omitempty"`
 	Git       *VercelGit            `json:"git,omitempty"`
 	CleanUrls bool                  `json:"cleanUrls,omitempty"`
+	Crons     []VercelCron          `json:"crons,omitempty"`
+}
+
+type VercelCron struct {
+	Path     string `json:"path"`
+	Schedule string `json:"schedule"`
 }
 
 type VercelBuild struct {
@@ -0,0 +1,18 @@
+package signals
+
+import "github.com/railwayapp/turnout/internal/discovery/types"
+
+// skipped records the configs a signal couldn't use, and why. Signals embed it to
+// implement discovery's DiagnosticSignal.
+type skipped struct {
+	warnings []types.Warning
+}
+
+func (s *skipped) skip(file string, err error) {
+	s.warnings = append(s.warnings, types.Warning{File: file, Message: err.Error()})
+}
+
+// Warnings lists the configs the signal skipped
+func (s *skipped) Warnings() []types.Warning {
+	return s.warnings
+}
@@ -1,5 +1,11 @@
 package types
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
 type Service struct {
 	Name    string
 	Network Network
@@ -9,6 +15,137 @@ type Service struct {
 	BuildPath string
 	Image     string
 	Configs   []ConfigRef
+	OutputDir string // static build output relative to BuildPath, only for BuildStatic
+
+	PackageManager PackageManager // detected from lockfiles/manifests, empty if unknown
+
+	Port            int           // port the service listens on, 0 if unknown
+	PublishedPorts  []PortMapping // ports published on the host, like compose's ports
+	HealthcheckPath string        // HTTP path used for health checks
+	StartCommand    string        // command that starts the service
+	BaseImage       string        // base image family of the final build stage, e.g. "node"
+	Platform        string        // OS the service's containers need, like "windows", empty for Linux or unknown
+
+	PreDeployCommand string     // one-shot command run before each deploy, e.g. migrations
+	Schedule         string     // cron expression, only for RuntimeScheduled
+	Volumes          []Volume   // persistent storage the service needs
+	Replicas         int        // number of instances, 0 if unspecified
+	Resources        *Resources // CPU and memory per instance, nil if unspecified
+	Region           string     // Railway deploy region, e.g. "us-west2", empty if unspecified
+
+	Networks []string       // private networks the service joins, like compose's networks, empty if the platform didn't say
+	Ingress  *IngressPolicy // which services may connect to this one, nil if nothing restricts them
+
+	Variables map[string]string // environment variables set explicitly, which win over extracted ones
+	BuildArgs map[string]string // build arguments set explicitly, like a bake target's args
+
+	API *API // the API the service serves, when it ships a spec for it
+
+	Missing []MissingPath // paths its configs name that aren't in the repository, a sign they're stale
+
+	Derived     bool   // implied by indirect evidence, e.g. migrations, rather than declared in a config
+	Environment string // environment the service was discovered for, empty unless discovering per environment
+
+	Provenance []Provenance // where inferred field values came from
+	Pinned     []string     // fields the user set explicitly, e.g. "Network", which no other signal may change
+}
+
+// Provenance records which source supplied an inferred field value and how much to trust it
+type Provenance struct {
+	Field      string // service field name, e.g. "Port"
+	Source     string // e.g. "framework-default:Next.js", "dockerfile:/path/Dockerfile"
+	Confidence int    // 0-100
+}
+
+// ProvenanceOf returns the provenance recorded for the given field
+func (s Service) ProvenanceOf(field string) (Provenance, bool) {
+	for _, p := range s.Provenance {
+		if p.Field == field {
+			return p, true
+		}
+	}
+	return Provenance{}, false
+}
+
+// ClearProvenance removes the provenance recorded for a field, marking its value as explicit
+func (s *Service) ClearProvenance(field string) {
+	// Clone so services copied during triangulation don't share entries
+	s.Provenance = slices.DeleteFunc(slices.Clone(s.Provenance), func(p Provenance) bool {
+		return p.Field == field
+	})
+}
+
+// SetProvenance records the provenance of a field, replacing any previous entry
+func (s *Service) SetProvenance(field, source string, confidence int) {
+	s.ClearProvenance(field)
+	s.Provenance = append(s.Provenance, Provenance{Field: field, Source: source, Confidence: confidence})
+}
+
+// IsPinned reports whether the user set a field explicitly
+func (s Service) IsPinned(field string) bool {
+	return slices.Contains(s.Pinned, field)
+}
+
+// Pin marks a field as set explicitly by the user
+func (s *Service) Pin(field string) {
+	if !s.IsPinned(field) {
+		s.Pinned = append(slices.Clone(s.Pinned), field)
+	}
+}
+
+// NormalizeEnvironment maps the environment names used in config filenames, like
+// "prod" or "override", to a canonical name
+func NormalizeEnvironment(name string) string {
+	switch name = strings.ToLower(name); name {
+	case "prod", "production", "live":
+		return "production"
+	case "dev", "development", "override", "local":
+		return "development"
+	case "stage", "staging":
+		return "staging"
+	case "test", "testing", "ci":
+		return "test"
+	}
+	return name
+}
+
+// API is a spec for the API a service serves, for healthchecks and documentation
+type API struct {
+	Type     string // "openapi", "swagger" or "graphql"
+	Version  string // the spec's OpenAPI or Swagger version, like "3.1.0", empty for GraphQL
+	SpecPath string
+}
+
+// MissingPath is a path a config names that isn't in the repository, like a rootDir or
+// Dockerfile that has since been moved or deleted
+type MissingPath struct {
+	Config  string // the config naming it
+	Setting string // what the config calls it, like "rootDir"
+	Path    string
+}
+
+// IngressPolicy restricts which services may connect to a service over the private
+// network, like a Kubernetes NetworkPolicy
+type IngressPolicy struct {
+	From []string // services allowed to connect, empty when none may
+}
+
+// Resources is the CPU and memory a service was given on the platform it came from
+type Resources struct {
+	CPU      float64 // vCPUs, 0 if unknown
+	MemoryMB int     // 0 if unknown
+}
+
+// PortMapping is a container port published on the host, like compose's 8080:80
+type PortMapping struct {
+	Published string // host port, or a range like 9000-9005, empty when the host picks one
+	Target    int    // port in the container
+}
+
+// Volume is persistent storage mounted into a service
+type Volume struct {
+	Name      string // volume name, empty if the platform names it
+	MountPath string // where the volume is mounted in the container
 }
 
 type Network int
@@ -31,9 +168,68 @@ type Build int
 const (
 	BuildFromSource Build = iota // build from Dockerfile/source
 	BuildFromImage               // use pre-built image
+	BuildStatic                  // build from source, serve the generated static files
+)
+
+var (
+	networkNames = []string{"none", "private", "public"}
+	runtimeNames = []string{"continuous", "scheduled"}
+	buildNames   = []string{"source", "image", "static"}
+)
+
+func (n Network) String() string { return enumName(networkNames, int(n)) }
+func (r Runtime) String() string { return enumName(runtimeNames, int(r)) }
+func (b Build) String() string   { return enumName(buildNames, int(b)) }
+
+// Enums encode as their names, so JSON output is readable without this package
+
+func (n Network) MarshalText() ([]byte, error) { return []byte(n.String()), nil }
+func (r Runtime) MarshalText() ([]byte, error) { return []byte(r.String()), nil }
+func (b Build) MarshalText() ([]byte, error)   { return []byte(b.String()), nil }
+
+func (n *Network) UnmarshalText(text []byte) error { return enumValue(networkNames, text, (*int)(n)) }
+func (r *Runtime) UnmarshalText(text []byte) error { return enumValue(runtimeNames, text, (*int)(r)) }
+func (b *Build) UnmarshalText(text []byte) error   { return enumValue(buildNames, text, (*int)(b)) }
+
+func enumName(names []string, value int) string {
+	if value < 0 || value >= len(names) {
+		return "unknown"
+	}
+	return names[value]
+}
+
+func enumValue(names []string, text []byte, value *int) error {
+	index := slices.Index(names, string(text))
+	if index < 0 {
+		return fmt.Errorf("unknown value %q, expected one of %s", text, strings.Join(names, ", "))
+	}
+	*value = index
+	return nil
+}
+
+type PackageManager string
+
+const (
+	PackageManagerNpm    PackageManager = "npm"
+	PackageManagerYarn   PackageManager = "yarn"
+	PackageManagerPnpm   PackageManager = "pnpm"
+	PackageManagerBun    PackageManager = "bun"
+	PackageManagerPip    PackageManager = "pip"
+	PackageManagerPoetry PackageManager = "poetry"
+	PackageManagerUv     PackageManager = "uv"
+	PackageManagerPipenv PackageManager = "pipenv"
 )
 
 type ConfigRef struct {
-	Type string // "docker-compose", "railway", "dockerfile", etc.
-	Path string // file path
+	Type        string // "docker-compose", "railway", "dockerfile", etc.
+	Path        string // file path
+	Environment string // environment the file is for, e.g. "production", empty if it applies to all
+}
+
+// Warning is a file discovery had to skip, like a config that won't parse, so a
+// project missing the services it defines doesn't look complete
+type Warning struct {
+	Signal  string `json:"signal,omitempty"`
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
 }
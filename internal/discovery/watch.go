@@ -0,0 +1,121 @@
+package discovery
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// ServiceChangeKind is how a service changed between two discoveries
+type ServiceChangeKind string
+
+const (
+	ServiceAdded   ServiceChangeKind = "added"
+	ServiceRemoved ServiceChangeKind = "removed"
+	ServiceChanged ServiceChangeKind = "changed"
+)
+
+// ServiceChange is a service that differs between two discoveries
+type ServiceChange struct {
+	Kind    ServiceChangeKind
+	Service types.Service // the new service, or the old one if removed
+	Fields  []string      // fields that changed, only for ServiceChanged
+}
+
+// DiffServices compares two discoveries of the same project. Services are matched by
+// name and environment; the order services and their configs come back in is ignored.
+func DiffServices(before, after []types.Service) []ServiceChange {
+	key := func(service types.Service) string { return service.Name + "\x00" + service.Environment }
+	previous := make(map[string]types.Service)
+	for _, service := range before {
+		previous[key(service)] = service
+	}
+
+	var changes []ServiceChange
+	for _, service := range after {
+		old, ok := previous[key(service)]
+		if !ok {
+			changes = append(changes, ServiceChange{Kind: ServiceAdded, Service: service})
+			continue
+		}
+		delete(previous, key(service))
+		if fields := changedFields(old, service); len(fields) > 0 {
+			changes = append(changes, ServiceChange{Kind: ServiceChanged, Service: service, Fields: fields})
+		}
+	}
+	for _, service := range before {
+		if _, ok := previous[key(service)]; ok {
+			changes = append(changes, ServiceChange{Kind: ServiceRemoved, Service: service})
+		}
+	}
+
+	slices.SortStableFunc(changes, func(a, b ServiceChange) int { return cmp.Compare(a.Service.Name, b.Service.Name) })
+	return changes
+}
+
+func changedFields(before, after types.Service) []string {
+	beforeValue := reflect.ValueOf(sortedService(before))
+	afterValue := reflect.ValueOf(sortedService(after))
+
+	var fields []string
+	for i := range beforeValue.NumField() {
+		if !reflect.DeepEqual(beforeValue.Field(i).Interface(), afterValue.Field(i).Interface()) {
+			fields = append(fields, beforeValue.Type().Field(i).Name)
+		}
+	}
+	return fields
+}
+
+// sortedService sorts a service's lists whose order depends on which signal finished first
+func sortedService(service types.Service) types.Service {
+	byString := func(a, b any) int { return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b)) }
+	service.Configs = slices.SortedFunc(slices.Values(service.Configs), func(a, b types.ConfigRef) int { return byString(a, b) })
+	service.Provenance = slices.SortedFunc(slices.Values(service.Provenance), func(a, b types.Provenance) int { return byString(a, b) })
+	service.Pinned = slices.Sorted(slices.Values(service.Pinned))
+	return service
+}
+
+// WatchUpdate is the result of rediscovering after files changed
+type WatchUpdate struct {
+	Services []types.Service
+	Changes  []ServiceChange // compared with the previous successful discovery
+	Paths    []string        // files that changed, empty for the initial discovery
+	Err      error
+}
+
+// Watch discovers services with discover, typically sd.Discover or sd.DiscoverEnvironments,
+// then again whenever files under rootPath change, until ctx is done. Only local
+// filesystems can be watched.
+func (sd *ServiceDiscovery) Watch(ctx context.Context, rootPath string, discover func(context.Context, string) ([]types.Service, error), update func(WatchUpdate)) error {
+	localFS, ok := filesystems.Unwrap(sd.filesystem).(*filesystems.LocalFS)
+	if !ok {
+		return fmt.Errorf("watching requires a local source, not %T", sd.filesystem)
+	}
+
+	batches, err := localFS.Watch(ctx, filesystems.GetBasePath(rootPath), sd.shouldIgnoreDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", rootPath, err)
+	}
+
+	services, err := discover(ctx, rootPath)
+	update(WatchUpdate{Services: services, Changes: DiffServices(nil, services), Err: err})
+
+	for paths := range batches {
+		if cached, ok := sd.filesystem.(*filesystems.CachedFS); ok {
+			cached.Reset() // Reads from before the change are stale
+		}
+		latest, err := discover(ctx, rootPath)
+		if err != nil {
+			update(WatchUpdate{Paths: paths, Err: err})
+			continue
+		}
+		update(WatchUpdate{Services: latest, Changes: DiffServices(services, latest), Paths: paths})
+		services = latest
+	}
+	return ctx.Err()
+}
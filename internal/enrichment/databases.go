@@ -0,0 +1,145 @@
+package enrichment
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+// managedDatabase is a Railway database or template that replaces a raw container
+type managedDatabase struct {
+	kind     string
+	template string   // Railway template that provisions it
+	families []string // image names that run it, replaced by Railway's image
+	variants []string // image names that run a variant with extensions, kept as they are
+	image    string   // image Railway deploys, with %s for the version
+	version  string   // default version when the tag doesn't say
+	volume   string   // where the data lives
+	start    string   // start command, when the image doesn't have a useful default
+	env      map[string]string
+
+	// Linking other services' variables to the database
+	urlVariable string   // variable Railway's database provides its connection URL in
+	schemes     []string // URL schemes of connection strings for it
+	envNames    []string // variables apps conventionally read its connection URL from
+}
+
+var managedDatabases = []managedDatabase{
+	{
+		kind: "postgres", template: "postgres",
+		families: []string{"postgres", "postgresql", "postgres-ssl"},
+		variants: []string{"postgis", "timescaledb", "timescaledb-ha", "pgvector"},
+		image:    "ghcr.io/railwayapp-templates/postgres-ssl:%s", version: "16",
+		volume: "/var/lib/postgresql/data",
+		// The volume's root holds lost+found, which initdb refuses to use
+		env:         map[string]string{"PGDATA": "/var/lib/postgresql/data/pgdata"},
+		urlVariable: "DATABASE_URL", schemes: []string{"postgres", "postgresql"},
+		envNames: []string{"DATABASE_URL", "POSTGRES_URL", "POSTGRESQL_URL", "PG_URL"},
+	},
+	{
+		kind: "mysql", template: "mysql",
+		families: []string{"mysql"},
+		variants: []string{"mariadb", "percona", "percona-server"},
+		image:    "mysql:%s", version: "8",
+		volume:      "/var/lib/mysql",
+		urlVariable: "MYSQL_URL", schemes: []string{"mysql"},
+		envNames: []string{"DATABASE_URL", "MYSQL_URL"},
+	},
+	{
+		kind: "redis", template: "redis",
+		families: []string{"redis"},
+		variants: []string{"redis-stack", "redis-stack-server", "valkey", "keydb"},
+		image:    "redis:%s", version: "7",
+		volume:      "/data",
+		urlVariable: "REDIS_URL", schemes: []string{"redis", "rediss"},
+		envNames: []string{"REDIS_URL", "CACHE_URL"},
+	},
+	{
+		kind: "mongodb", template: "mongodb",
+		families: []string{"mongo", "mongodb", "mongodb-community-server"},
+		image:    "mongo:%s", version: "7",
+		volume:      "/data/db",
+		urlVariable: "MONGO_URL", schemes: []string{"mongodb", "mongodb+srv"},
+		envNames: []string{"MONGO_URL", "MONGODB_URL", "MONGO_URI", "MONGODB_URI"},
+	},
+	{
+		kind: "clickhouse", template: "clickhouse",
+		families: []string{"clickhouse-server", "clickhouse"},
+		image:    "clickhouse/clickhouse-server:%s", version: "24",
+		volume: "/var/lib/clickhouse",
+	},
+	{
+		kind: "minio", template: "minio",
+		families: []string{"minio"},
+		image:    "minio/minio:%s", version: "latest",
+		volume: "/data", start: "minio server /data --console-address :9001",
+	},
+	{
+		kind: "rabbitmq", template: "rabbitmq",
+		families: []string{"rabbitmq"},
+		image:    "rabbitmq:%s-management", version: "3",
+		volume: "/var/lib/rabbitmq",
+	},
+}
+
+// Leading version numbers of a tag, e.g. 16.2 in 16.2-alpine
+var imageVersionPattern = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)`)
+
+// ManagedDatabases maps services running a recognized database image to the Railway
+// database or template that provisions it, so exports deploy Railway's image with a
+// volume and its required settings rather than the raw container. Variants like
+// MariaDB or PostGIS keep their own image.
+func ManagedDatabases(project *schema.Project) {
+	for i := range project.Services {
+		service := &project.Services[i]
+		if service.Image == "" || service.Managed != nil {
+			continue
+		}
+
+		family, tag := imageFamilyAndTag(service.Image)
+		for _, database := range managedDatabases {
+			replace := slices.Contains(database.families, family)
+			if !replace && !slices.Contains(database.variants, family) {
+				continue
+			}
+
+			version := ""
+			if match := imageVersionPattern.FindStringSubmatch(tag); match != nil {
+				version = match[1]
+			}
+			service.Managed = &schema.Managed{Kind: database.kind, Template: database.template, Version: version}
+
+			if replace {
+				major, _, _ := strings.Cut(version, ".")
+				if major == "" {
+					major = database.version
+				}
+				service.Image = strings.Replace(database.image, "%s", major, 1)
+			}
+
+			if len(service.Volumes) == 0 {
+				service.Volumes = append(service.Volumes, schema.Volume{MountPath: database.volume})
+			}
+			if service.StartCommand == "" {
+				service.StartCommand = database.start
+			}
+			for name, value := range database.env {
+				if _, ok := service.Environment[name]; !ok {
+					service.Environment[name] = schema.NewEnvVar(value, false)
+				}
+			}
+			break
+		}
+	}
+}
+
+// imageFamilyAndTag splits an image reference into its name, without registry or
+// namespace, and its tag
+func imageFamilyAndTag(image string) (string, string) {
+	ref, _, _ := strings.Cut(image, "@")
+	parts := strings.Split(ref, "/")
+	name, tag, _ := strings.Cut(parts[len(parts)-1], ":")
+	return strings.ToLower(name), tag
+}
@@ -0,0 +1,52 @@
+package enrichment
+
+import (
+	"context"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/environment"
+	envTypes "github.com/railwayapp/turnout/internal/environment/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+// NewProject converts services discovered in rootPath into a project, with each
+// service's environment variables and databases mapped to their Railway equivalents,
+// variables pointing at other services turned into references, and the private
+// network the old platform allowed mapped out
+func NewProject(ctx context.Context, filesystem filesystems.FileSystem, rootPath, name string, services []types.Service) *schema.Project {
+	project := schema.NewProjectFromServices(name, filesystem, rootPath, services)
+	ManagedDatabases(project)
+	ServiceSizes(project)
+
+	extractor := environment.NewExtractor(filesystem)
+	extractor.SetRoot(rootPath)
+	envVars := extractor.ExtractServices(ctx, services)
+	for i := range project.Services {
+		for _, envVar := range envVars[project.Services[i].Name] {
+			variable := schema.NewEnvVar(envVar.Value, envVar.Sensitive)
+			variable.BuildOnly = envVar.BuildOnly
+			if ref := envVar.Reference; ref != nil {
+				variable.Reference = &schema.EnvReference{Service: ref.Service, Variable: ref.Variable, Prefix: ref.Prefix, Suffix: ref.Suffix}
+			}
+			project.Services[i].Environment[envVar.VarName] = variable
+		}
+		for name, value := range services[i].Variables {
+			_, sensitive := envTypes.ClassifyEnvVar(name, value)
+			project.Services[i].Environment[name] = schema.NewEnvVar(value, sensitive)
+		}
+		for name, value := range services[i].BuildArgs {
+			variable, ok := project.Services[i].Environment[name]
+			if !ok {
+				_, sensitive := envTypes.ClassifyEnvVar(name, value)
+				variable = schema.NewEnvVar(value, sensitive)
+				variable.BuildOnly = true
+			}
+			variable.Value = value
+			project.Services[i].Environment[name] = variable
+		}
+	}
+	ServiceReferences(project)
+	NetworkTopology(project)
+	return project
+}
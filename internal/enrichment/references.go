@@ -0,0 +1,134 @@
+package enrichment
+
+import (
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+// ServiceReferences links variables that point at other services in the project to
+// them, so exports emit Railway reference variables rather than values that only
+// resolved in the old deployment: a compose hostname in a URL becomes the service's
+// private domain, and a database URL becomes the managed database's own. Referenced
+// services are added to the service's dependencies. Run it after ManagedDatabases.
+func ServiceReferences(project *schema.Project) {
+	services := make(map[string]*schema.Service)
+	for i := range project.Services {
+		services[strings.ToLower(project.Services[i].Name)] = &project.Services[i]
+	}
+
+	for i := range project.Services {
+		service := &project.Services[i]
+		for _, name := range slices.Sorted(maps.Keys(service.Environment)) {
+			envVar := service.Environment[name]
+			if envVar.Reference == nil {
+				envVar.Reference = serviceReference(project, services, service.Name, name, envVar.Value)
+			}
+			if envVar.Reference == nil {
+				continue
+			}
+			service.Environment[name] = envVar
+			if target := envVar.Reference.Service; target != service.Name && !slices.Contains(service.Dependencies, target) {
+				service.Dependencies = append(service.Dependencies, target)
+			}
+		}
+	}
+}
+
+// serviceReference finds the service a variable's value or name points at, if any
+func serviceReference(project *schema.Project, services map[string]*schema.Service, self, name, value string) *schema.EnvReference {
+	host, hostStart, hostEnd, scheme := valueHost(value)
+	if !strings.Contains(value, ":") && !hostVariable(name) {
+		host = "" // A bare word only names a host in a variable meant for one
+	}
+	if target := services[strings.ToLower(host)]; target != nil && target.Name != self {
+		// A managed database's credentials change, so the whole URL is replaced
+		if database := managedDatabaseFor(target); database != nil && database.urlVariable != "" && slices.Contains(database.schemes, scheme) {
+			return &schema.EnvReference{Service: target.Name, Variable: database.urlVariable}
+		}
+		return &schema.EnvReference{
+			Service:  target.Name,
+			Variable: "RAILWAY_PRIVATE_DOMAIN",
+			Prefix:   value[:hostStart],
+			Suffix:   value[hostEnd:],
+		}
+	}
+
+	// Unset or pointing at a local database during development, a conventional name
+	// like DATABASE_URL is the project's only database of a kind that reads it
+	if value != "" && !isLocalHost(host) {
+		return nil
+	}
+	var candidates []*schema.Service
+	for i := range project.Services {
+		target := &project.Services[i]
+		database := managedDatabaseFor(target)
+		if target.Name != self && database != nil && slices.Contains(database.envNames, name) {
+			candidates = append(candidates, target)
+		}
+	}
+	if len(candidates) != 1 {
+		return nil
+	}
+	return &schema.EnvReference{Service: candidates[0].Name, Variable: managedDatabaseFor(candidates[0]).urlVariable}
+}
+
+// valueHost finds the host in a URL or a bare host[:port] value, with its position in
+// the value and the URL's scheme
+func valueHost(value string) (host string, start, end int, scheme string) {
+	if prefix, rest, ok := strings.Cut(value, "://"); ok {
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Hostname() == "" {
+			return "", 0, 0, ""
+		}
+		start = len(prefix) + len("://")
+		authority, _, _ := strings.Cut(rest, "/")
+		if at := strings.LastIndex(authority, "@"); at != -1 {
+			start += at + 1
+		}
+		host = parsed.Hostname()
+		if !strings.HasPrefix(value[start:], host) {
+			return "", 0, 0, "" // Bracketed IPv6, which is never a service name
+		}
+		return host, start, start + len(host), strings.ToLower(parsed.Scheme)
+	}
+
+	host = value
+	if h, port, err := net.SplitHostPort(value); err == nil && port != "" {
+		host = h
+	}
+	if strings.ContainsAny(host, " /:") {
+		return "", 0, 0, ""
+	}
+	return host, 0, len(host), ""
+}
+
+// hostVariable reports whether a variable is named for a host, like REDIS_HOST
+func hostVariable(name string) bool {
+	for _, suffix := range []string{"HOST", "HOSTNAME", "ADDR", "ADDRESS", "SERVER"} {
+		if strings.HasSuffix(strings.ToUpper(name), suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLocalHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "0.0.0.0" || host == "host.docker.internal"
+}
+
+func managedDatabaseFor(service *schema.Service) *managedDatabase {
+	if service.Managed == nil {
+		return nil
+	}
+	for i := range managedDatabases {
+		if managedDatabases[i].kind == service.Managed.Kind {
+			return &managedDatabases[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package enrichment
+
+import (
+	"math"
+
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+// RailwayPlan is the most CPU and memory a Railway plan allows a service
+type RailwayPlan struct {
+	Name     string
+	VCPU     int
+	MemoryGB int
+}
+
+// RailwayPlans are Railway's plans, smallest first, with the per-service limits
+// published on railway.com/pricing
+var RailwayPlans = []RailwayPlan{
+	{Name: "Hobby", VCPU: 8, MemoryGB: 8},
+	{Name: "Pro", VCPU: 32, MemoryGB: 32},
+}
+
+// ServiceSizes suggests Railway resource limits for services whose old platform said
+// how much CPU and memory they had, rounded up to whole vCPUs and GB, with the smallest
+// plan that allows them. Services too big for every plan are left without one.
+func ServiceSizes(project *schema.Project) {
+	for i := range project.Services {
+		resources := project.Services[i].Resources
+		if resources == nil {
+			continue
+		}
+		if resources.CPU > 0 {
+			resources.VCPU = int(math.Ceil(resources.CPU))
+		}
+		if resources.MemoryMB > 0 {
+			resources.MemoryGB = int(math.Ceil(float64(resources.MemoryMB) / 1024))
+		}
+
+		resources.Plan = ""
+		for _, plan := range RailwayPlans {
+			if resources.VCPU <= plan.VCPU && resources.MemoryGB <= plan.MemoryGB {
+				resources.Plan = plan.Name
+				break
+			}
+		}
+	}
+}
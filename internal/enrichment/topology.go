@@ -0,0 +1,42 @@
+package enrichment
+
+import (
+	"slices"
+
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+// NetworkTopology maps which services could reach which over the old platform's private
+// network, from the networks they joined and the policies restricting who connects to
+// them. Projects whose platform said nothing about either get no topology, since every
+// service could reach every other.
+func NetworkTopology(project *schema.Project) {
+	project.Topology = nil
+	if !slices.ContainsFunc(project.Services, func(service schema.Service) bool {
+		return len(service.Networks) > 0 || service.Ingress != nil
+	}) {
+		return
+	}
+
+	for _, to := range project.Services {
+		for _, from := range project.Services {
+			if from.Name != to.Name && reachable(from, to) {
+				project.Topology = append(project.Topology, schema.Link{From: from.Name, To: to.Name})
+			}
+		}
+	}
+}
+
+// reachable reports whether one service could connect to another: they share a network,
+// or one of them didn't say which it joined, and nothing keeps the first out
+func reachable(from, to schema.Service) bool {
+	if to.Ingress != nil && !slices.Contains(to.Ingress.From, from.Name) {
+		return false
+	}
+	if len(from.Networks) == 0 || len(to.Networks) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(from.Networks, func(network string) bool {
+		return slices.Contains(to.Networks, network)
+	})
+}
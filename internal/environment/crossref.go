@@ -0,0 +1,96 @@
+package environment
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	discoveryTypes "github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// Reference is where a service's variable is read in code and where it's declared,
+// in an env file, compose file, platform config or the service itself
+type Reference struct {
+	VarName    string   `json:"name"`
+	UsedIn     []string `json:"usedIn,omitempty"`
+	DeclaredIn []string `json:"declaredIn,omitempty"`
+}
+
+// Undeclared reports whether the variable is read in code but declared nowhere
+func (r Reference) Undeclared() bool {
+	return len(r.UsedIn) > 0 && len(r.DeclaredIn) == 0
+}
+
+// CrossReference joins the variables services read in code with those declared for
+// them, given every result from ExtractServiceResults. A variable declared for
+// another service counts as declared too, since it's most likely shared. Variables
+// Railway provides are left out. References are sorted by name.
+func CrossReference(services []discoveryTypes.Service, results map[string][]types.EnvResult) map[string][]Reference {
+	declaredAnywhere := make(map[string]string)
+	for _, service := range services {
+		for name := range service.Variables {
+			declaredAnywhere[name] = "service:" + service.Name
+		}
+		for _, result := range results[service.Name] {
+			if !isUsage(result) {
+				declaredAnywhere[result.VarName] = result.Source
+			}
+		}
+	}
+
+	references := make(map[string][]Reference)
+	for _, service := range services {
+		byName := make(map[string]*Reference)
+		reference := func(name string) *Reference {
+			if byName[name] == nil {
+				byName[name] = &Reference{VarName: name}
+			}
+			return byName[name]
+		}
+
+		for name := range service.Variables {
+			if types.IsRailwayProvided(name) {
+				continue
+			}
+			reference(name).DeclaredIn = append(reference(name).DeclaredIn, "service:"+service.Name)
+		}
+		for _, result := range results[service.Name] {
+			if types.IsRailwayProvided(result.VarName) {
+				continue
+			}
+			ref := reference(result.VarName)
+			if path, ok := strings.CutPrefix(result.Source, "usage:"); ok {
+				if !slices.Contains(ref.UsedIn, path) {
+					ref.UsedIn = append(ref.UsedIn, path)
+				}
+			} else if !slices.Contains(ref.DeclaredIn, result.Source) {
+				ref.DeclaredIn = append(ref.DeclaredIn, result.Source)
+			}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(byName)) {
+			ref := byName[name]
+			if source, ok := declaredAnywhere[name]; ok && len(ref.DeclaredIn) == 0 {
+				ref.DeclaredIn = append(ref.DeclaredIn, source)
+			}
+			references[service.Name] = append(references[service.Name], *ref)
+		}
+	}
+	return references
+}
+
+// Undeclared lists the names of the variables a service reads but never declares
+func Undeclared(references []Reference) []string {
+	var names []string
+	for _, ref := range references {
+		if ref.Undeclared() {
+			names = append(names, ref.VarName)
+		}
+	}
+	return names
+}
+
+func isUsage(result types.EnvResult) bool {
+	return strings.HasPrefix(result.Source, "usage:")
+}
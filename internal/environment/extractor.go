@@ -2,6 +2,7 @@ package environment
 
 import (
 	"context"
+	"strings"
 
 	"github.com/railwayapp/turnout/internal/environment/extractors"
 	"github.com/railwayapp/turnout/internal/environment/types"
@@ -11,27 +12,109 @@ import (
 type Extractor struct {
 	filesystem filesystems.FileSystem
 	extractors []extractors.ContentExtractor
+	root       string
 }
 
 func NewExtractor(filesystem filesystems.FileSystem) *Extractor {
 	return &Extractor{
 		filesystem: filesystem,
 		extractors: []extractors.ContentExtractor{
-			extractors.NewDockerComposeExtractor(),
+			extractors.NewDockerComposeExtractor().WithFileSystem(filesystem),
 			extractors.NewDockerfileExtractor(),
 			extractors.NewDotEnvExtractor(),
+			extractors.NewShellExportExtractor(),
+			extractors.NewStartCommandExtractor(),
 			extractors.NewStructuredConfigExtractor(),
 			extractors.NewLibraryCallExtractor(),
+			extractors.NewRenderExtractor(),
+			extractors.NewFlyExtractor(),
+			extractors.NewDigitalOceanAppExtractor(),
+			extractors.NewHerokuAppJSONExtractor(),
+			extractors.NewVercelExtractor(),
+			extractors.NewNetlifyExtractor(),
+			extractors.NewRailsConfigExtractor(),
+			extractors.NewLaravelConfigExtractor(),
+			extractors.NewSpringConfigExtractor(),
 		},
 	}
 }
 
+// SetRoot sets the directory the project was scanned from. Shared env files in it, and
+// in the directories between it and a service, apply to the service too. Without a
+// root, the filesystem's root is used, which is the project for git, GitHub, archive
+// and in-memory sources. A LocalFS spans the whole disk, so local sources must set it.
+func (e *Extractor) SetRoot(root string) {
+	e.root = root
+}
+
+// Lockfiles are generated, and big enough to be slow to scan for nothing
+var lockfiles = map[string]bool{
+	"package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true, "bun.lockb": true, "bun.lock": true,
+	"poetry.lock": true, "pipfile.lock": true, "uv.lock": true, "cargo.lock": true, "gemfile.lock": true,
+	"composer.lock": true, "go.sum": true, "mix.lock": true, "pubspec.lock": true, "packages.lock.json": true,
+}
+
+// ExtractFile extracts environment variables from a file, only reading files an
+// extractor handles. Files only line-scanning extractors handle are streamed.
+// Lockfiles, binary files and files over filesystems.MaxTextFileSize are skipped.
+func (e *Extractor) ExtractFile(ctx context.Context, path string) <-chan types.EnvResult {
+	var handlers []extractors.ContentExtractor
+	for _, extractor := range e.extractors {
+		if extractor.CanHandle(path) && !lockfiles[strings.ToLower(e.filesystem.Base(path))] {
+			handlers = append(handlers, extractor)
+		}
+	}
+
+	results := make(chan types.EnvResult, 32)
+	if len(handlers) == 0 {
+		close(results)
+		return results
+	}
+
+	if readerExtractor, ok := handlers[0].(extractors.ReaderExtractor); ok && len(handlers) == 1 {
+		go func() {
+			defer close(results)
+
+			file, err := filesystems.OpenTextFile(e.filesystem, path)
+			if err != nil {
+				return
+			}
+			defer file.Close()
+
+			envResults, err := readerExtractor.ExtractReader(ctx, path, file)
+			if err != nil {
+				return
+			}
+			for _, result := range envResults {
+				results <- withConventions(result)
+			}
+		}()
+		return results
+	}
+
+	content, err := filesystems.ReadTextFile(e.filesystem, path)
+	if err != nil {
+		close(results)
+		return results
+	}
+	return e.Extract(ctx, path, content)
+}
+
+// withConventions marks what a variable's name implies, whichever extractor found it
+func withConventions(result types.EnvResult) types.EnvResult {
+	if types.PublicEnvPrefix(result.VarName) != "" {
+		result.BuildTime = true
+	}
+	return result
+}
+
 // Extract environment variables from file content
 func (e *Extractor) Extract(ctx context.Context, filename string, content []byte) <-chan types.EnvResult {
 	results := make(chan types.EnvResult, 32)
 
 	go func() {
 		defer close(results)
+		text := string(content)
 
 		// Apply all extractors that can handle this file
 		for _, extractor := range e.extractors {
@@ -42,7 +125,11 @@ func (e *Extractor) Extract(ctx context.Context, filename string, content []byte
 				}
 
 				for _, result := range envResults {
-					results <- result
+					if result.Span.Line == 0 {
+						// Extractors that parse a whole file don't know where each key was
+						result.Span = types.Locate(text, result.VarName)
+					}
+					results <- withConventions(result)
 				}
 			}
 		}
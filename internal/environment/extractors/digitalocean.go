@@ -0,0 +1,83 @@
+package extractors
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+	"gopkg.in/yaml.v3"
+)
+
+// DigitalOceanAppExtractor reads the envs of DigitalOcean App Platform specs, at
+// the app level and on each component
+type DigitalOceanAppExtractor struct{}
+
+func NewDigitalOceanAppExtractor() *DigitalOceanAppExtractor {
+	return &DigitalOceanAppExtractor{}
+}
+
+func (d *DigitalOceanAppExtractor) CanHandle(filename string) bool {
+	base := strings.ToLower(filepath.Base(filename))
+	return base == "digitalocean-app.yaml" || (base == "app.yaml" && filepath.Base(filepath.Dir(filename)) == ".do")
+}
+
+func (d *DigitalOceanAppExtractor) Confidence() int {
+	return 90 // App specs are the env the app is deployed with
+}
+
+type doEnvVar struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value"`
+	Scope string `yaml:"scope"` // RUN_TIME, BUILD_TIME or RUN_AND_BUILD_TIME
+	Type  string `yaml:"type"`  // GENERAL or SECRET
+}
+
+type doComponent struct {
+	Name string     `yaml:"name"`
+	Envs []doEnvVar `yaml:"envs"`
+}
+
+func (d *DigitalOceanAppExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
+	var spec struct {
+		Envs        []doEnvVar    `yaml:"envs"`
+		Services    []doComponent `yaml:"services"`
+		StaticSites []doComponent `yaml:"static_sites"`
+		Workers     []doComponent `yaml:"workers"`
+		Jobs        []doComponent `yaml:"jobs"`
+		Functions   []doComponent `yaml:"functions"`
+	}
+	if err := yaml.Unmarshal(content, &spec); err != nil {
+		return nil, err
+	}
+
+	var results []types.EnvResult
+	add := func(envVars []doEnvVar, source string) {
+		for _, envVar := range envVars {
+			if envVar.Key == "" || types.ShouldIgnore(envVar.Key) {
+				continue
+			}
+			value := envVar.Value
+			if strings.EqualFold(envVar.Type, "SECRET") {
+				value = "" // Committed secrets are encrypted for the app, so useless elsewhere
+			}
+			result := declaredEnvResult(envVar.Key, value, source, d.Confidence())
+			if strings.EqualFold(envVar.Type, "SECRET") {
+				result.Type, result.Sensitive = types.EnvTypeSecret, true
+			}
+			// Unscoped variables are available at build time too
+			result.BuildTime = !strings.EqualFold(envVar.Scope, "RUN_TIME")
+			result.BuildOnly = strings.EqualFold(envVar.Scope, "BUILD_TIME")
+			results = append(results, result)
+		}
+	}
+
+	add(spec.Envs, fmt.Sprintf("digitalocean:%s", filename))
+	for _, components := range [][]doComponent{spec.Services, spec.StaticSites, spec.Workers, spec.Jobs, spec.Functions} {
+		for _, component := range components {
+			add(component.Envs, fmt.Sprintf("digitalocean:%s#%s", filename, component.Name))
+		}
+	}
+	return results, nil
+}
@@ -3,19 +3,31 @@ package extractors
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/compose-spec/compose-go/v2/loader"
 	composeTypes "github.com/compose-spec/compose-go/v2/types"
 	"github.com/railwayapp/turnout/internal/environment/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"gopkg.in/yaml.v3"
 )
 
-type DockerComposeExtractor struct{}
+type DockerComposeExtractor struct {
+	filesystem filesystems.FileSystem // reads env_file files, which are skipped without one
+}
 
 func NewDockerComposeExtractor() *DockerComposeExtractor {
 	return &DockerComposeExtractor{}
 }
 
+// WithFileSystem reads the env files services load with env_file from filesystem,
+// relative to the compose file
+func (d *DockerComposeExtractor) WithFileSystem(filesystem filesystems.FileSystem) *DockerComposeExtractor {
+	d.filesystem = filesystem
+	return d
+}
+
 func (d *DockerComposeExtractor) CanHandle(filename string) bool {
 	name := strings.ToLower(filename)
 	return strings.Contains(name, "compose") && (strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml"))
@@ -38,20 +50,36 @@ func (d *DockerComposeExtractor) Extract(ctx context.Context, filename string, c
 
 	project, err := loader.LoadWithContext(ctx, configDetails, func(options *loader.Options) {
 		options.SetProjectName("temp", true)
+		// env_file paths are kept as written and read through the filesystem here,
+		// rather than by the loader from the local disk
+		options.ResolvePaths = false
+		options.SkipResolveEnvironment = true
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	var results []types.EnvResult
+	raw := rawComposeEnvironment(content)
 
-	// Extract environment variables from all services
+	// Extract environment variables from all services, with the env files they load
+	// first, since environment overrides them
+	envFiles := make(map[string]bool)
 	for _, service := range project.Services {
+		for _, envFile := range service.EnvFiles {
+			if !envFiles[envFile.Path] {
+				envFiles[envFile.Path] = true
+				results = append(results, d.envFileResults(filename, envFile.Path)...)
+			}
+		}
 		for key, value := range service.Environment {
 			val := ""
 			if value != nil {
 				val = *value
 			}
+			if written := raw[service.Name][key]; len(types.VarReferences(written)) > 0 {
+				val = written // Resolved against the service's other variables later
+			}
 
 			if types.ShouldIgnore(key) {
 				continue
@@ -69,5 +97,94 @@ func (d *DockerComposeExtractor) Extract(ctx context.Context, filename string, c
 		}
 	}
 
-	return results, nil
+	return append(results, d.interpolatedResults(filename, content)...), nil
+}
+
+// envFileResults reads an env file a service loads. Missing files are skipped, as
+// they're often generated or kept out of the repository.
+func (d *DockerComposeExtractor) envFileResults(filename, envFile string) []types.EnvResult {
+	if d.filesystem == nil {
+		return nil
+	}
+	if !filepath.IsAbs(envFile) {
+		envFile = d.filesystem.Join(d.filesystem.Dir(filename), envFile)
+	}
+	content, err := filesystems.ReadTextFile(d.filesystem, envFile)
+	if err != nil {
+		return nil
+	}
+	results, err := dotenvResults(envFile, content, d.Confidence()-1)
+	if err != nil {
+		return nil
+	}
+	return results
+}
+
+// interpolatedResults reports the variables compose interpolates into the file, like
+// TAG in image: app:${TAG:-latest}, as usages of the compose file with their
+// defaults. They have to be set wherever the services are deployed.
+func (d *DockerComposeExtractor) interpolatedResults(filename string, content []byte) []types.EnvResult {
+	var results []types.EnvResult
+	found := make(map[string]bool)
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		defaults := types.VarDefaults(line)
+		for _, varName := range types.VarReferences(line) {
+			if found[varName] || types.ShouldIgnore(varName) {
+				continue
+			}
+			found[varName] = true
+
+			column := strings.Index(line, "${"+varName) + 2
+			if column == 1 {
+				column = strings.Index(line, "$"+varName) + 1
+			}
+			envType, sensitive := types.ClassifyEnvVar(varName, defaults[varName])
+			results = append(results, types.EnvResult{
+				VarName:    varName,
+				Value:      defaults[varName],
+				Type:       envType,
+				Sensitive:  sensitive,
+				Source:     fmt.Sprintf("usage:%s", filename),
+				Span:       types.Span{Line: i + 1, Column: column + 1, EndColumn: column + 1 + len(varName)},
+				Confidence: d.Confidence() / 2,
+			})
+		}
+	}
+	return results
+}
+
+// rawComposeEnvironment reads each service's environment as written. Compose
+// interpolates values against the shell environment, which isn't there when
+// scanning, so ${VAR} and ${VAR:-default} would otherwise be lost.
+func rawComposeEnvironment(content []byte) map[string]map[string]string {
+	var file struct {
+		Services map[string]struct {
+			Environment yaml.Node `yaml:"environment"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil
+	}
+
+	environments := make(map[string]map[string]string)
+	for name, service := range file.Services {
+		environment := make(map[string]string)
+		switch nodes := service.Environment.Content; service.Environment.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(nodes); i += 2 {
+				environment[nodes[i].Value] = nodes[i+1].Value
+			}
+		case yaml.SequenceNode:
+			for _, node := range nodes {
+				if key, value, ok := strings.Cut(node.Value, "="); ok {
+					environment[key] = value
+				}
+			}
+		}
+		environments[name] = environment
+	}
+	return environments
 }
@@ -3,6 +3,7 @@ package extractors
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
@@ -32,17 +33,51 @@ func (d *DockerfileExtractor) Extract(ctx context.Context, filename string, cont
 
 	var results []types.EnvResult
 
-	// Walk the AST looking for ENV instructions
+	// Walk the AST looking for ENV and ARG instructions
 	for _, child := range ast.AST.Children {
-		if strings.ToUpper(child.Value) == "ENV" {
-			envVars := d.parseEnvNode(child, filename)
-			results = append(results, envVars...)
+		switch strings.ToUpper(child.Value) {
+		case "ENV":
+			results = append(results, d.parseEnvNode(child, filename)...)
+		case "ARG":
+			results = append(results, d.parseArgNode(child, filename)...)
 		}
 	}
 
 	return results, nil
 }
 
+// Build args BuildKit sets itself, which are never passed in
+var automaticBuildArgs = []string{
+	"TARGETPLATFORM", "TARGETOS", "TARGETARCH", "TARGETVARIANT",
+	"BUILDPLATFORM", "BUILDOS", "BUILDARCH", "BUILDVARIANT",
+}
+
+// parseArgNode reports an ARG's build args, with their defaults as values. They're
+// only set while building, unless an ENV carries them into the image, which Merge
+// sorts out when the same Dockerfile sets the variable with ENV too.
+func (d *DockerfileExtractor) parseArgNode(node *parser.Node, dockerfilePath string) []types.EnvResult {
+	var results []types.EnvResult
+	for n := node.Next; n != nil; n = n.Next {
+		varName, value, _ := strings.Cut(strings.Trim(n.Value, `"`), "=")
+		if varName == "" || types.ShouldIgnore(varName) || slices.Contains(automaticBuildArgs, varName) {
+			continue
+		}
+
+		envType, sensitive := types.ClassifyEnvVar(varName, value)
+		results = append(results, types.EnvResult{
+			VarName:    varName,
+			Value:      value,
+			Type:       envType,
+			Sensitive:  sensitive,
+			Source:     fmt.Sprintf("dockerfile-arg:%s", dockerfilePath),
+			Confidence: d.Confidence() - 5, // A default, which the build is expected to override
+			BuildTime:  true,
+			BuildOnly:  true,
+		})
+	}
+	return results
+}
+
 func (d *DockerfileExtractor) parseEnvNode(node *parser.Node, dockerfilePath string) []types.EnvResult {
 	if node.Next == nil {
 		return nil
@@ -18,7 +18,7 @@ func NewDotEnvExtractor() *DotEnvExtractor {
 
 func (d *DotEnvExtractor) CanHandle(filename string) bool {
 	base := strings.ToLower(filepath.Base(filename))
-	return strings.HasPrefix(base, ".env")
+	return strings.HasPrefix(base, ".env") && base != ".envrc" // .envrc is a shell script
 }
 
 func (d *DotEnvExtractor) Confidence() int {
@@ -26,14 +26,19 @@ func (d *DotEnvExtractor) Confidence() int {
 }
 
 func (d *DotEnvExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
-	// Parse the dotenv content
-	env, err := godotenv.Unmarshal(string(content))
+	return dotenvResults(filename, content, d.getFileConfidence(filepath.Base(filename)))
+}
+
+// dotenvResults parses a dotenv file's variables, for env files found by name and
+// those other configs load, like compose's env_file
+func dotenvResults(filename string, content []byte, confidence int) ([]types.EnvResult, error) {
+	env, err := godotenv.Unmarshal(escapeExpansion(string(content)))
 	if err != nil {
 		return nil, err
 	}
 
 	var results []types.EnvResult
-	confidence := d.getFileConfidence(filepath.Base(filename))
+	spans := dotenvSpans(strings.Split(string(content), "\n"))
 
 	for key, value := range env {
 		if types.ShouldIgnore(key) {
@@ -47,6 +52,7 @@ func (d *DotEnvExtractor) Extract(ctx context.Context, filename string, content
 			Type:       envType,
 			Sensitive:  sensitive,
 			Source:     fmt.Sprintf("dotenv:%s", filename),
+			Span:       spans[key],
 			Confidence: confidence,
 		})
 	}
@@ -54,6 +60,41 @@ func (d *DotEnvExtractor) Extract(ctx context.Context, filename string, content
 	return results, nil
 }
 
+// escapeExpansion escapes the $ in values godotenv would expand. It only expands
+// against the same file and blanks references it can't find, while references are
+// resolved against all of a service's variables later. Single-quoted values aren't
+// expanded, so they're left alone.
+func escapeExpansion(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.HasPrefix(strings.TrimSpace(key), "#") || strings.HasPrefix(strings.TrimSpace(value), "'") {
+			continue
+		}
+		lines[i] = key + "=" + strings.ReplaceAll(value, "$", `\$`)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// dotenvSpans finds the line that assigns each key, where godotenv's last assignment
+// wins, rather than the first mention, which may be in a comment
+func dotenvSpans(lines []string) map[string]types.Span {
+	spans := make(map[string]types.Span)
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := len(line) - len(trimmed)
+		if rest, ok := strings.CutPrefix(trimmed, "export "); ok {
+			indent += len(trimmed) - len(strings.TrimLeft(rest, " \t"))
+			trimmed = strings.TrimLeft(rest, " \t")
+		}
+		if name, _, ok := strings.Cut(trimmed, "="); ok {
+			key := strings.TrimSpace(name)
+			spans[key] = types.Span{Line: i + 1, Column: indent + 1, EndColumn: indent + 1 + len(key)}
+		}
+	}
+	return spans
+}
+
 func (d *DotEnvExtractor) getFileConfidence(filename string) int {
 	switch {
 	case filename == ".env":
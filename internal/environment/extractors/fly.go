@@ -0,0 +1,57 @@
+package extractors
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// FlyExtractor reads the [env] and [build.args] of fly.toml. Secrets set with
+// `fly secrets` aren't in the file.
+type FlyExtractor struct{}
+
+func NewFlyExtractor() *FlyExtractor {
+	return &FlyExtractor{}
+}
+
+func (f *FlyExtractor) CanHandle(filename string) bool {
+	return strings.EqualFold(filepath.Base(filename), "fly.toml")
+}
+
+func (f *FlyExtractor) Confidence() int {
+	return 90 // The env the app is deployed with
+}
+
+func (f *FlyExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
+	var config struct {
+		Env   map[string]string `toml:"env"`
+		Build struct {
+			Args map[string]string `toml:"args"`
+		} `toml:"build"`
+	}
+	if _, err := toml.Decode(string(content), &config); err != nil {
+		return nil, err
+	}
+
+	source := fmt.Sprintf("fly:%s", filename)
+	var results []types.EnvResult
+	for _, name := range slices.Sorted(maps.Keys(config.Env)) {
+		if !types.ShouldIgnore(name) {
+			results = append(results, declaredEnvResult(name, config.Env[name], source, f.Confidence()))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(config.Build.Args)) {
+		if !types.ShouldIgnore(name) {
+			result := declaredEnvResult(name, config.Build.Args[name], source, f.Confidence())
+			result.BuildTime = true
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,68 @@
+package extractors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// HerokuAppJSONExtractor reads the env of Heroku's app.json, where variables are
+// either values or objects with a value, a generator and whether they're required
+type HerokuAppJSONExtractor struct{}
+
+func NewHerokuAppJSONExtractor() *HerokuAppJSONExtractor {
+	return &HerokuAppJSONExtractor{}
+}
+
+func (h *HerokuAppJSONExtractor) CanHandle(filename string) bool {
+	return strings.EqualFold(filepath.Base(filename), "app.json")
+}
+
+func (h *HerokuAppJSONExtractor) Confidence() int {
+	return 85 // Declared for review apps and buttons, usually matching production
+}
+
+func (h *HerokuAppJSONExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
+	var manifest struct {
+		Env map[string]json.RawMessage `json:"env"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, err
+	}
+
+	source := fmt.Sprintf("heroku:%s", filename)
+	var results []types.EnvResult
+	for _, name := range slices.Sorted(maps.Keys(manifest.Env)) {
+		if types.ShouldIgnore(name) {
+			continue
+		}
+
+		var envVar struct {
+			Value     any    `json:"value"`
+			Generator string `json:"generator"`
+		}
+		if err := json.Unmarshal(manifest.Env[name], &envVar); err != nil {
+			// Variables can be plain values too
+			if json.Unmarshal(manifest.Env[name], &envVar.Value) != nil {
+				continue
+			}
+		}
+
+		var stringValue string
+		if envVar.Value != nil {
+			stringValue = fmt.Sprint(envVar.Value)
+		}
+		result := declaredEnvResult(name, stringValue, source, h.Confidence())
+		if envVar.Generator == "secret" {
+			result.Type, result.Sensitive = types.EnvTypeGenerated, true
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -2,6 +2,7 @@ package extractors
 
 import (
 	"context"
+	"io"
 
 	"github.com/railwayapp/turnout/internal/environment/types"
 )
@@ -17,3 +18,12 @@ type ContentExtractor interface {
 	// Confidence returns the confidence level for this extractor (0-100)
 	Confidence() int
 }
+
+// ReaderExtractor is implemented by extractors that scan files line by line, so
+// large files like minified bundles are streamed rather than read into memory
+type ReaderExtractor interface {
+	ContentExtractor
+
+	// ExtractReader extracts environment variables from a file as it's read
+	ExtractReader(ctx context.Context, filename string, r io.Reader) ([]types.EnvResult, error)
+}
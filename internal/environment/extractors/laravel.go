@@ -0,0 +1,59 @@
+package extractors
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// LaravelConfigExtractor reads env('VAR', default) calls in Laravel's config/*.php files
+type LaravelConfigExtractor struct{}
+
+func NewLaravelConfigExtractor() *LaravelConfigExtractor {
+	return &LaravelConfigExtractor{}
+}
+
+func (l *LaravelConfigExtractor) CanHandle(filename string) bool {
+	name := strings.ToLower(filepath.ToSlash(filename))
+	return strings.HasSuffix(name, ".php") && filepath.Base(filepath.Dir(name)) == "config"
+}
+
+func (l *LaravelConfigExtractor) Confidence() int {
+	return 80 // Config files declare every variable the app reads, with defaults
+}
+
+// env('VAR'), env("VAR", 'default') or env('VAR', false), but not getenv() or $this->env()
+var laravelEnvPattern = regexp.MustCompile(`(?:^|[^\w$>:])env\(\s*['"]([A-Z_][A-Z0-9_]*)['"]\s*(?:,\s*(?:'([^']*)'|"([^"]*)"|([\w.\-]+))\s*)?\)`)
+
+func (l *LaravelConfigExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
+	var results []types.EnvResult
+	found := make(map[string]bool)
+
+	for _, match := range laravelEnvPattern.FindAllStringSubmatch(string(content), -1) {
+		varName := match[1]
+		if found[varName] || types.ShouldIgnore(varName) {
+			continue
+		}
+		found[varName] = true
+
+		value := firstNonEmpty(match[2:]...)
+		if strings.EqualFold(value, "null") {
+			value = ""
+		}
+
+		envType, sensitive := types.ClassifyEnvVar(varName, value)
+		results = append(results, types.EnvResult{
+			VarName:    varName,
+			Value:      value,
+			Type:       envType,
+			Sensitive:  sensitive,
+			Source:     fmt.Sprintf("laravel:%s", filename),
+			Confidence: l.Confidence(),
+		})
+	}
+	return results, nil
+}
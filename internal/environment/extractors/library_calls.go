@@ -1,8 +1,11 @@
 package extractors
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -19,6 +22,7 @@ func NewLibraryCallExtractor() *LibraryCallExtractor {
 // Common source code extensions
 var sourceExts = []string{
 	".js", ".ts", ".jsx", ".tsx", ".mjs",
+	".vue", ".svelte", ".astro",
 	".py", ".rb", ".php", ".java", ".kt",
 	".go", ".rs", ".cpp", ".c", ".cs",
 	".sh", ".bash", ".zsh", ".fish",
@@ -39,25 +43,14 @@ func (l *LibraryCallExtractor) Confidence() int {
 	return 50 // Medium confidence - these are usage patterns, not declarations
 }
 
+// libraryCallPatterns match usages in the languages newUsageMatcher doesn't lex
 var libraryCallPatterns = []*regexp.Regexp{
-	// process.env.VAR_NAME (JavaScript/TypeScript)
-	regexp.MustCompile(`process\.env\.([A-Z_][A-Z0-9_]*)`),
-
-	// os.getenv('VAR_NAME') or os.getenv("VAR_NAME") (Python)
-	regexp.MustCompile(`os\.getenv\(['"]([A-Z_][A-Z0-9_]*)['"]\)`),
-
-	// ENV['VAR_NAME'] or ENV["VAR_NAME"] (Ruby)
-	regexp.MustCompile(`ENV\[['"]([A-Z_][A-Z0-9_]*)['"]\]`),
-
 	// $_ENV['VAR_NAME'] or $_ENV["VAR_NAME"] (PHP)
 	regexp.MustCompile(`\$_ENV\[['"]([A-Z_][A-Z0-9_]*)['"]\]`),
 
 	// System.getenv("VAR_NAME") (Java)
 	regexp.MustCompile(`System\.getenv\("([A-Z_][A-Z0-9_]*)"\)`),
 
-	// os.Getenv("VAR_NAME") or os.LookupEnv("VAR_NAME") (Go)
-	regexp.MustCompile(`os\.(?:Getenv|LookupEnv)\("([A-Z_][A-Z0-9_]*)"\)`),
-
 	// std::env::var("VAR_NAME") (Rust)
 	regexp.MustCompile(`std::env::var\("([A-Z_][A-Z0-9_]*)"\)`),
 
@@ -69,7 +62,10 @@ var libraryCallPatterns = []*regexp.Regexp{
 }
 
 func (l *LibraryCallExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
-	contentStr := string(content)
+	return l.ExtractReader(ctx, filename, bytes.NewReader(content))
+}
+
+func (l *LibraryCallExtractor) ExtractReader(ctx context.Context, filename string, r io.Reader) ([]types.EnvResult, error) {
 	var results []types.EnvResult
 	found := make(map[string]bool) // Deduplicate within this file
 
@@ -78,34 +74,117 @@ func (l *LibraryCallExtractor) Extract(ctx context.Context, filename string, con
 		return results, nil
 	}
 
+	// JavaScript, Python, Go and Ruby are lexed, so usages in comments and strings
+	// aren't matched and destructuring and aliased imports are
+	syntax, usages := newUsageMatcher(filename)
+	if usages == nil {
+		err := scanLines(r, func(line string, _ int, at lineStart) {
+			results = append(results, l.extractLine(filename, line, at, found)...)
+		})
+		return results, err
+	}
+
+	lexer := newSourceLexer(syntax)
+	err := scanLines(r, func(line string, overlap int, at lineStart) {
+		usages.match(line, lexer.mask(line, overlap), func(varName, value string) {
+			if result, ok := l.usageResult(filename, varName, value, at.locate(line, varName), found); ok {
+				results = append(results, result)
+			}
+		})
+	})
+	return results, err
+}
+
+// extractLine finds the variables used on a line that haven't been found yet
+func (l *LibraryCallExtractor) extractLine(filename, line string, at lineStart, found map[string]bool) []types.EnvResult {
+	var results []types.EnvResult
 	for _, pattern := range libraryCallPatterns {
-		matches := pattern.FindAllStringSubmatch(contentStr, -1)
+		matches := pattern.FindAllStringSubmatch(line, -1)
 		for _, match := range matches {
 			if len(match) < 2 {
 				continue
 			}
 
-			varName := match[1]
-
-			if found[varName] || types.ShouldIgnore(varName) {
-				continue
+			if result, ok := l.usageResult(filename, match[1], "", at.locate(line, match[1]), found); ok {
+				results = append(results, result)
 			}
+		}
+	}
+	return results
+}
+
+// usageResult builds the result for a variable used in a file, unless it was already found
+func (l *LibraryCallExtractor) usageResult(filename, varName, value string, span types.Span, found map[string]bool) (types.EnvResult, bool) {
+	if found[varName] || types.ShouldIgnore(varName) {
+		return types.EnvResult{}, false
+	}
+
+	found[varName] = true
+
+	envType, sensitive := types.ClassifyEnvVar(varName, value)
+	return types.EnvResult{
+		VarName:    varName,
+		Value:      value, // A default at the usage, if there is one
+		Type:       envType,
+		Sensitive:  sensitive,
+		Source:     fmt.Sprintf("usage:%s", filename),
+		Span:       span,
+		Confidence: l.Confidence(),
+	}, true
+}
+
+const (
+	maxLineChunk = 64 << 10 // longer lines, like minified bundles, are scanned in chunks
+	chunkOverlap = 256      // repeated between chunks so matches across a boundary aren't lost
+)
 
-			found[varName] = true
-
-			envType, sensitive := types.ClassifyEnvVar(varName, "")
-			results = append(results, types.EnvResult{
-				VarName:    varName,
-				Value:      "", // We don't know the value from usage
-				Type:       envType,
-				Sensitive:  sensitive,
-				Source:     fmt.Sprintf("usage:%s", filename),
-				Confidence: l.Confidence(),
-			})
+// scanLines calls fn for each line read from r. Lines longer than maxLineChunk are
+// split into overlapping chunks, so memory stays bounded however long a line is;
+// overlap is how many bytes at the start of a chunk repeat the end of the last, and
+// at is where the chunk starts.
+func scanLines(r io.Reader, fn func(line string, overlap int, at lineStart)) error {
+	reader := bufio.NewReaderSize(r, maxLineChunk)
+	var carry []byte
+	at := lineStart{line: 1}
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(chunk) > 0 {
+			line := append(carry, chunk...)
+			fn(string(line), len(carry), at)
+			carry = nil
+			if err == bufio.ErrBufferFull {
+				carry = bytes.Clone(line[max(0, len(line)-chunkOverlap):])
+				at.offset += len(line) - len(carry)
+			} else {
+				at = lineStart{line: at.line + 1}
+			}
+		}
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return err
 		}
 	}
+}
 
-	return results, nil
+// lineStart is where a chunk from scanLines starts: its line, and how many bytes into
+// the line it is
+type lineStart struct {
+	line   int
+	offset int
+}
+
+// locate finds where a variable named in the chunk is in the file. Matches that
+// spanned lines, like a multi-line destructure, only have the line they ended on.
+func (s lineStart) locate(chunk, name string) types.Span {
+	span := types.Locate(chunk, name)
+	if span.Line != 1 {
+		return types.Span{Line: s.line}
+	}
+	return types.Span{Line: s.line, Column: s.offset + span.Column, EndColumn: s.offset + span.EndColumn}
 }
 
 func isTestFile(filename string) bool {
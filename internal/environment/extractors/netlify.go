@@ -0,0 +1,61 @@
+package extractors
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// NetlifyExtractor reads the [build.environment] of netlify.toml and the
+// environment of each deploy context, like [context.production.environment]
+type NetlifyExtractor struct{}
+
+func NewNetlifyExtractor() *NetlifyExtractor {
+	return &NetlifyExtractor{}
+}
+
+func (n *NetlifyExtractor) CanHandle(filename string) bool {
+	return strings.EqualFold(filepath.Base(filename), "netlify.toml")
+}
+
+func (n *NetlifyExtractor) Confidence() int {
+	return 85
+}
+
+type netlifyEnvironment struct {
+	Environment map[string]string `toml:"environment"`
+}
+
+func (n *NetlifyExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
+	var config struct {
+		Build   netlifyEnvironment            `toml:"build"`
+		Context map[string]netlifyEnvironment `toml:"context"`
+	}
+	if _, err := toml.Decode(string(content), &config); err != nil {
+		return nil, err
+	}
+
+	var results []types.EnvResult
+	add := func(env map[string]string, source string) {
+		for _, name := range slices.Sorted(maps.Keys(env)) {
+			if types.ShouldIgnore(name) {
+				continue
+			}
+			// Netlify only sets file-based variables for builds and functions
+			result := declaredEnvResult(name, env[name], source, n.Confidence())
+			result.BuildTime = true
+			results = append(results, result)
+		}
+	}
+	add(config.Build.Environment, fmt.Sprintf("netlify:%s", filename))
+	for _, name := range slices.Sorted(maps.Keys(config.Context)) {
+		add(config.Context[name].Environment, fmt.Sprintf("netlify:%s#%s", filename, name))
+	}
+	return results, nil
+}
@@ -0,0 +1,16 @@
+package extractors
+
+import "github.com/railwayapp/turnout/internal/environment/types"
+
+// declaredEnvResult is a variable a platform config declares, classified by its name and value
+func declaredEnvResult(varName, value, source string, confidence int) types.EnvResult {
+	envType, sensitive := types.ClassifyEnvVar(varName, value)
+	return types.EnvResult{
+		VarName:    varName,
+		Value:      value,
+		Type:       envType,
+		Sensitive:  sensitive,
+		Source:     source,
+		Confidence: confidence,
+	}
+}
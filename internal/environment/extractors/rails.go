@@ -0,0 +1,104 @@
+package extractors
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// RailsConfigExtractor reads the ERB in Rails' config/*.yml files, like
+// database.yml, and flags the master key encrypted credentials need
+type RailsConfigExtractor struct{}
+
+func NewRailsConfigExtractor() *RailsConfigExtractor {
+	return &RailsConfigExtractor{}
+}
+
+// railsMasterKey decrypts config/credentials.yml.enc, or the per-environment credentials
+const railsMasterKey = "RAILS_MASTER_KEY"
+
+func (r *RailsConfigExtractor) CanHandle(filename string) bool {
+	name := strings.ToLower(filepath.ToSlash(filename))
+	if isRailsCredentials(name) {
+		return true
+	}
+	return (strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yml.erb")) && filepath.Base(filepath.Dir(name)) == "config"
+}
+
+// isRailsCredentials reports whether a file is config/credentials.yml.enc or
+// config/credentials/<environment>.yml.enc
+func isRailsCredentials(name string) bool {
+	if !strings.HasSuffix(name, ".yml.enc") {
+		return false
+	}
+	return strings.HasSuffix(name, "config/credentials.yml.enc") || filepath.Base(filepath.Dir(name)) == "credentials"
+}
+
+func (r *RailsConfigExtractor) Confidence() int {
+	return 75 // ERB lookups in config are what the app boots with
+}
+
+var (
+	// ENV['VAR'] or ENV["VAR"]
+	erbEnvIndexPattern = regexp.MustCompile(`ENV\[\s*['"]([A-Z_][A-Z0-9_]*)['"]\s*\]`)
+
+	// ENV.fetch("VAR", default) or ENV.fetch("VAR") { default }
+	erbEnvFetchPattern = regexp.MustCompile(`ENV\.fetch\(\s*['"]([A-Z_][A-Z0-9_]*)['"]\s*(?:,\s*(?:['"]([^'"]*)['"]|([\w.]+))\s*)?\)(?:\s*\{\s*(?:['"]([^'"]*)['"]|([\w.]+))\s*\})?`)
+)
+
+func (r *RailsConfigExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
+	if isRailsCredentials(strings.ToLower(filepath.ToSlash(filename))) {
+		// The credentials are encrypted, but booting in production needs the key
+		return []types.EnvResult{{
+			VarName:    railsMasterKey,
+			Type:       types.EnvTypeSecret,
+			Sensitive:  true,
+			Source:     fmt.Sprintf("rails:%s", filename),
+			Confidence: 90,
+		}}, nil
+	}
+
+	var results []types.EnvResult
+	found := make(map[string]bool)
+	add := func(varName, value string) {
+		if found[varName] || types.ShouldIgnore(varName) {
+			return
+		}
+		found[varName] = true
+		if value == "nil" {
+			value = ""
+		}
+
+		envType, sensitive := types.ClassifyEnvVar(varName, value)
+		results = append(results, types.EnvResult{
+			VarName:    varName,
+			Value:      value,
+			Type:       envType,
+			Sensitive:  sensitive,
+			Source:     fmt.Sprintf("rails:%s", filename),
+			Confidence: r.Confidence(),
+		})
+	}
+
+	// Fetches first, so their defaults are kept when a variable is also indexed
+	for _, match := range erbEnvFetchPattern.FindAllStringSubmatch(string(content), -1) {
+		add(match[1], firstNonEmpty(match[2:]...))
+	}
+	for _, match := range erbEnvIndexPattern.FindAllStringSubmatch(string(content), -1) {
+		add(match[1], "")
+	}
+	return results, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package extractors
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+	"gopkg.in/yaml.v3"
+)
+
+// RenderExtractor extracts env vars from render.yaml blueprints, resolving
+// fromGroup references against the blueprint's envVarGroups
+type RenderExtractor struct{}
+
+func NewRenderExtractor() *RenderExtractor {
+	return &RenderExtractor{}
+}
+
+func (r *RenderExtractor) CanHandle(filename string) bool {
+	return strings.EqualFold(filepath.Base(filename), "render.yaml")
+}
+
+func (r *RenderExtractor) Confidence() int {
+	return 90 // Blueprints are the env vars the app is deployed with
+}
+
+type renderBlueprint struct {
+	Services     []renderEnvHolder `yaml:"services"`
+	EnvVarGroups []renderEnvHolder `yaml:"envVarGroups"`
+}
+
+type renderEnvHolder struct {
+	Name    string         `yaml:"name"`
+	EnvVars []renderEnvVar `yaml:"envVars"`
+}
+
+type renderEnvVar struct {
+	Key           string      `yaml:"key"`
+	Value         yaml.Node   `yaml:"value"`
+	GenerateValue bool        `yaml:"generateValue"`
+	Sync          *bool       `yaml:"sync"`
+	FromDatabase  *renderFrom `yaml:"fromDatabase"`
+	FromService   *renderFrom `yaml:"fromService"`
+	FromGroup     string      `yaml:"fromGroup"`
+}
+
+type renderFrom struct {
+	Name      string `yaml:"name"`
+	Property  string `yaml:"property"`
+	EnvVarKey string `yaml:"envVarKey"`
+}
+
+// renderDatabaseVariables are the variables Railway's Postgres provides each of a
+// Render database's properties in
+var renderDatabaseVariables = map[string]string{
+	"connectionString": "DATABASE_URL",
+	"host":             "PGHOST",
+	"port":             "PGPORT",
+	"user":             "PGUSER",
+	"password":         "PGPASSWORD",
+	"database":         "PGDATABASE",
+}
+
+// reference maps a fromService or fromDatabase value to the Railway variable holding it
+func (from *renderFrom) reference(database bool) *types.ServiceReference {
+	ref := &types.ServiceReference{Service: from.Name}
+	switch {
+	case from.EnvVarKey != "":
+		ref.Variable = from.EnvVarKey
+	case database:
+		ref.Variable = renderDatabaseVariables[from.Property]
+	case from.Property == "host":
+		ref.Variable = "RAILWAY_PRIVATE_DOMAIN"
+	case from.Property == "hostport":
+		ref.Variable, ref.Suffix = "RAILWAY_PRIVATE_DOMAIN", ":${{"+from.Name+".PORT}}"
+	case from.Property == "port":
+		ref.Variable = "PORT"
+	case from.Property == "connectionString":
+		ref.Variable = "REDIS_URL" // Only key value stores have connection strings
+	}
+	if ref.Service == "" || ref.Variable == "" {
+		return nil
+	}
+	return ref
+}
+
+func (r *RenderExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
+	var blueprint renderBlueprint
+	if err := yaml.Unmarshal(content, &blueprint); err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]renderEnvVar)
+	for _, group := range blueprint.EnvVarGroups {
+		groups[group.Name] = group.EnvVars
+	}
+
+	var results []types.EnvResult
+	seen := make(map[string]bool)
+	add := func(envVar renderEnvVar, source string) {
+		if envVar.Key == "" || types.ShouldIgnore(envVar.Key) || seen[envVar.Key+"\x00"+source] {
+			return
+		}
+		seen[envVar.Key+"\x00"+source] = true
+
+		value := envVar.Value.Value
+		envType, sensitive := types.ClassifyEnvVar(envVar.Key, value)
+		var reference *types.ServiceReference
+		switch {
+		case envVar.FromDatabase != nil:
+			envType, sensitive = types.EnvTypeDatabase, true
+			reference = envVar.FromDatabase.reference(true)
+		case envVar.FromService != nil:
+			reference = envVar.FromService.reference(false)
+		case envVar.GenerateValue:
+			envType, sensitive = types.EnvTypeGenerated, true
+		case envVar.Sync != nil && !*envVar.Sync:
+			// sync: false values are set in the dashboard, never committed
+			sensitive = true
+		}
+
+		results = append(results, types.EnvResult{
+			VarName:    envVar.Key,
+			Value:      value,
+			Type:       envType,
+			Sensitive:  sensitive,
+			Source:     source,
+			Confidence: r.Confidence(),
+			Reference:  reference,
+		})
+	}
+
+	for _, service := range blueprint.Services {
+		source := fmt.Sprintf("render:%s#%s", filename, service.Name)
+		for _, envVar := range service.EnvVars {
+			if envVar.FromGroup == "" {
+				add(envVar, source)
+				continue
+			}
+			for _, groupVar := range groups[envVar.FromGroup] {
+				add(groupVar, fmt.Sprintf("render:%s#%s", filename, envVar.FromGroup))
+			}
+		}
+	}
+
+	return results, nil
+}
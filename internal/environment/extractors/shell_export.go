@@ -0,0 +1,75 @@
+package extractors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// ShellExportExtractor reads `export VAR=value` statements in direnv's .envrc
+// and scripts/*.sh, which set up the environment for development and deploys
+type ShellExportExtractor struct{}
+
+func NewShellExportExtractor() *ShellExportExtractor {
+	return &ShellExportExtractor{}
+}
+
+func (s *ShellExportExtractor) CanHandle(filename string) bool {
+	base := strings.ToLower(filepath.Base(filename))
+	if base == ".envrc" {
+		return true
+	}
+	return strings.HasSuffix(base, ".sh") && filepath.Base(filepath.Dir(filename)) == "scripts"
+}
+
+func (s *ShellExportExtractor) Confidence() int {
+	return 60 // Medium confidence - scripts export for their own commands too
+}
+
+// export VAR=value, export VAR="value" or export VAR='value', with an optional comment
+var shellExportPattern = regexp.MustCompile(`^\s*export\s+([A-Za-z_][A-Za-z0-9_]*)=("[^"]*"|'[^']*'|[^\s#;]*)`)
+
+func (s *ShellExportExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
+	return s.ExtractReader(ctx, filename, bytes.NewReader(content))
+}
+
+func (s *ShellExportExtractor) ExtractReader(ctx context.Context, filename string, r io.Reader) ([]types.EnvResult, error) {
+	var results []types.EnvResult
+	found := make(map[string]bool)
+
+	err := scanLines(r, func(line string, _ int, at lineStart) {
+		match := shellExportPattern.FindStringSubmatch(line)
+		if match == nil || found[match[1]] || types.ShouldIgnore(match[1]) {
+			return
+		}
+		found[match[1]] = true
+
+		value := match[2]
+		switch {
+		case strings.HasPrefix(value, "'"):
+			value = strings.Trim(value, "'") // Single quotes are literal
+		case strings.Contains(value, "$"):
+			value = "" // Computed from other variables or commands when the script runs
+		default:
+			value = strings.Trim(value, `"`)
+		}
+
+		envType, sensitive := types.ClassifyEnvVar(match[1], value)
+		results = append(results, types.EnvResult{
+			VarName:    match[1],
+			Value:      value,
+			Type:       envType,
+			Sensitive:  sensitive,
+			Source:     fmt.Sprintf("shell:%s", filename),
+			Span:       at.locate(line, match[1]),
+			Confidence: s.Confidence(),
+		})
+	})
+	return results, err
+}
@@ -0,0 +1,175 @@
+package extractors
+
+import "strings"
+
+// sourceSyntax is what a language's lexer needs to tell code from comments and strings
+type sourceSyntax struct {
+	lineComment  string
+	blockComment [2]string // start and end, empty if the language has none
+	atLineStart  bool      // block comments only open and close at the start of a line, like Ruby's =begin
+	strings      []stringSyntax
+}
+
+type stringSyntax struct {
+	delimiter   string
+	multiline   bool
+	raw         bool   // backslashes don't escape
+	interpolate string // opens code inside the string, like ${ in JavaScript templates
+}
+
+var (
+	jsSyntax = &sourceSyntax{
+		lineComment:  "//",
+		blockComment: [2]string{"/*", "*/"},
+		strings: []stringSyntax{
+			{delimiter: "`", multiline: true, interpolate: "${"},
+			{delimiter: `"`},
+			{delimiter: "'"},
+		},
+	}
+	pythonSyntax = &sourceSyntax{
+		lineComment: "#",
+		strings: []stringSyntax{
+			{delimiter: `"""`, multiline: true},
+			{delimiter: "'''", multiline: true},
+			{delimiter: `"`},
+			{delimiter: "'"},
+		},
+	}
+	goSyntax = &sourceSyntax{
+		lineComment:  "//",
+		blockComment: [2]string{"/*", "*/"},
+		strings: []stringSyntax{
+			{delimiter: "`", multiline: true, raw: true},
+			{delimiter: `"`},
+			{delimiter: "'"},
+		},
+	}
+	rubySyntax = &sourceSyntax{
+		lineComment:  "#",
+		blockComment: [2]string{"=begin", "=end"},
+		atLineStart:  true,
+		strings: []stringSyntax{
+			{delimiter: `"`, multiline: true, interpolate: "#{"},
+			{delimiter: "'", multiline: true},
+		},
+	}
+)
+
+type lexMode struct {
+	str          *stringSyntax // the string being lexed, nil in comments and interpolations
+	comment      bool
+	interpolated bool // code inside a string, ended by an unbalanced }
+	depth        int  // braces opened in an interpolation
+}
+
+// sourceLexer masks comments and the contents of string literals, line by line,
+// keeping state across lines for block comments and multiline strings. Masked lines
+// keep their length, so what a pattern matches in the masked line can be read
+// from the same position in the original: a string's contents are masked with _,
+// so a pattern can match a string literal and read its value from the original.
+type sourceLexer struct {
+	syntax *sourceSyntax
+	modes  []lexMode
+	tail   string // the masked end of the last line, repeated when long lines are chunked
+}
+
+func newSourceLexer(syntax *sourceSyntax) *sourceLexer {
+	return &sourceLexer{syntax: syntax}
+}
+
+// mask masks a line, the first overlap bytes of which repeat the end of the last
+// line and were already lexed
+func (l *sourceLexer) mask(line string, overlap int) string {
+	out := []byte(line)
+	start := 0
+	if overlap > 0 && overlap <= len(l.tail) && overlap <= len(line) {
+		copy(out, l.tail[len(l.tail)-overlap:])
+		start = overlap
+	}
+	for i := start; i < len(line); {
+		i += l.step(line, i, out)
+	}
+	l.tail = string(out[max(0, len(out)-chunkOverlap):])
+	return string(out)
+}
+
+// step lexes the token at line[i], writing it masked to out, and returns its length
+func (l *sourceLexer) step(line string, i int, out []byte) int {
+	rest := line[i:]
+	if len(l.modes) > 0 {
+		mode := &l.modes[len(l.modes)-1]
+		switch {
+		case mode.comment:
+			end := l.syntax.blockComment[1]
+			if strings.HasPrefix(rest, end) && (!l.syntax.atLineStart || i == 0) {
+				l.modes = l.modes[:len(l.modes)-1]
+				return maskRange(out, i, len(end), ' ')
+			}
+			if line[i] == '\n' {
+				return 1
+			}
+			return maskRange(out, i, 1, ' ')
+		case mode.str != nil:
+			return l.stepString(mode.str, line, i, out)
+		case mode.interpolated:
+			switch line[i] {
+			case '{':
+				mode.depth++
+			case '}':
+				if mode.depth == 0 {
+					l.modes = l.modes[:len(l.modes)-1]
+					return 1
+				}
+				mode.depth--
+			}
+		}
+	}
+
+	// Code
+	if l.syntax.lineComment != "" && strings.HasPrefix(rest, l.syntax.lineComment) {
+		return maskRange(out, i, len(strings.TrimSuffix(rest, "\n")), ' ')
+	}
+	if start := l.syntax.blockComment[0]; start != "" && strings.HasPrefix(rest, start) && (!l.syntax.atLineStart || i == 0) {
+		l.modes = append(l.modes, lexMode{comment: true})
+		return maskRange(out, i, len(start), ' ')
+	}
+	for s := range l.syntax.strings {
+		str := &l.syntax.strings[s]
+		if strings.HasPrefix(rest, str.delimiter) {
+			l.modes = append(l.modes, lexMode{str: str})
+			return len(str.delimiter)
+		}
+	}
+	return 1
+}
+
+func (l *sourceLexer) stepString(str *stringSyntax, line string, i int, out []byte) int {
+	rest := line[i:]
+	switch {
+	case line[i] == '\\' && !str.raw:
+		if len(rest) > 1 && rest[1] != '\n' {
+			return maskRange(out, i, 2, '_')
+		}
+		return maskRange(out, i, 1, '_')
+	case strings.HasPrefix(rest, str.delimiter):
+		l.modes = l.modes[:len(l.modes)-1]
+		return len(str.delimiter)
+	case str.interpolate != "" && strings.HasPrefix(rest, str.interpolate):
+		l.modes = append(l.modes, lexMode{interpolated: true})
+		return len(str.interpolate)
+	case line[i] == '\n':
+		if !str.multiline {
+			l.modes = l.modes[:len(l.modes)-1] // Unterminated, most likely a quote that wasn't a string
+		}
+		return 1
+	}
+	return maskRange(out, i, 1, '_')
+}
+
+func maskRange(out []byte, i, n int, mask byte) int {
+	for j := i; j < i+n && j < len(out); j++ {
+		out[j] = mask
+	}
+	return n
+}
@@ -0,0 +1,276 @@
+package extractors
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// usageMatcher finds the variables a language reads on a line, given the line and
+// the line with comments and string contents masked
+type usageMatcher interface {
+	match(line, masked string, emit func(varName, value string))
+}
+
+// newUsageMatcher returns the lexer syntax and matcher for a source file's
+// language, or nil for languages only matched by libraryCallPatterns
+func newUsageMatcher(filename string) (*sourceSyntax, usageMatcher) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts", ".vue", ".svelte", ".astro":
+		return jsSyntax, newJSUsages()
+	case ".py":
+		return pythonSyntax, newPythonUsages()
+	case ".go":
+		return goSyntax, newGoUsages()
+	case ".rb":
+		return rubySyntax, rubyUsages{}
+	}
+	return nil, nil
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// emitMatches emits the variable named by each match's nameGroup, read from the
+// original line, with the default value in valueGroup when there is one
+func emitMatches(pattern *regexp.Regexp, line, masked string, nameGroup, valueGroup int, emit func(varName, value string)) {
+	for _, match := range pattern.FindAllStringSubmatchIndex(masked, -1) {
+		name := submatch(line, match, nameGroup)
+		if !envNamePattern.MatchString(name) {
+			continue
+		}
+		emit(name, submatch(line, match, valueGroup))
+	}
+}
+
+func submatch(line string, match []int, group int) string {
+	if group <= 0 || 2*group+1 >= len(match) || match[2*group] < 0 {
+		return ""
+	}
+	return line[match[2*group]:match[2*group+1]]
+}
+
+func alternation(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return strings.Join(quoted, "|")
+}
+
+// literalValue returns the value of a string, number or boolean literal, or "" for
+// expressions and identifiers like None and nil
+func literalValue(literal string) string {
+	literal = strings.TrimSpace(literal)
+	if len(literal) >= 2 && strings.ContainsRune(`"'`+"`", rune(literal[0])) && literal[len(literal)-1] == literal[0] {
+		return literal[1 : len(literal)-1]
+	}
+	if _, err := strconv.ParseFloat(literal, 64); err == nil || literal == "true" || literal == "false" {
+		return literal
+	}
+	return ""
+}
+
+// jsWindowSize is how much of the preceding lines is kept to match destructuring
+// and aliases that span lines
+const jsWindowSize = 2 << 10
+
+var (
+	// import { env } from "process" or import { env as e } from "node:process"
+	jsEnvImportPattern = regexp.MustCompile(`import\s*\{([^}]*)\}\s*from\s*["'](?:node:)?process["']`)
+
+	// const { env } = process or const { env: e } = require("node:process")
+	jsEnvRequirePattern = regexp.MustCompile(`\{([^{}]*)\}\s*=\s*(?:process\b|require\(\s*["'](?:node:)?process["']\s*\))[ \t]*(?:[;,)\n]|$)`)
+)
+
+// jsUsages finds process.env.VAR, process.env["VAR"], import.meta.env.VAR and
+// destructuring like const { PORT = 3000 } = process.env, through aliases too
+type jsUsages struct {
+	objects []string // expressions for the env object, like process.env and aliases of it
+
+	dot, bracket, destructure, alias *regexp.Regexp
+
+	window, maskedWindow string
+}
+
+// jsEnvObjects are the env objects every JavaScript file can read
+var jsEnvObjects = []string{"process.env", "import.meta.env"}
+
+func newJSUsages() *jsUsages {
+	j := &jsUsages{objects: append([]string{}, jsEnvObjects...)}
+	j.compile()
+	return j
+}
+
+func (j *jsUsages) compile() {
+	objects := alternation(j.objects)
+	// Minifiers run process.env up against whatever precedes it, so only aliases,
+	// which are plain identifiers, need to start a word
+	object := `(?:(?:^|[^$.])(?:` + alternation(jsEnvObjects) + `)|(?:^|[^\w$.])(?:` + objects + `))`
+	j.dot = regexp.MustCompile(object + `\.([A-Za-z_$][\w$]*)`)
+	j.bracket = regexp.MustCompile(object + `\[\s*["'` + "`" + `](_*)["'` + "`" + `]\s*\]`)
+	j.destructure = regexp.MustCompile(`\{([^{}]*)\}\s*=\s*(?:` + objects + `)[ \t]*(?:[;,)\n]|$)`)
+	j.alias = regexp.MustCompile(`\b(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*(?:` + objects + `)[ \t]*(?:[;,)\n]|$)`)
+}
+
+func (j *jsUsages) addObject(name string) {
+	for _, object := range j.objects {
+		if object == name {
+			return
+		}
+	}
+	j.objects = append(j.objects, name)
+	j.compile()
+}
+
+func (j *jsUsages) match(line, masked string, emit func(varName, value string)) {
+	window, maskedWindow := j.window+line, j.maskedWindow+masked
+
+	// Aliases first, so they're known for the rest of the line
+	for _, match := range j.alias.FindAllStringSubmatchIndex(maskedWindow, -1) {
+		j.addObject(submatch(window, match, 1))
+	}
+	for _, pattern := range []*regexp.Regexp{jsEnvImportPattern, jsEnvRequirePattern} {
+		for _, match := range pattern.FindAllStringSubmatch(window, -1) {
+			for _, item := range strings.Split(match[1], ",") {
+				key, local := destructuredItem(item, " as ")
+				if key == "env" {
+					j.addObject(local)
+				}
+			}
+		}
+	}
+
+	emitMatches(j.dot, line, masked, 1, 0, emit)
+	emitMatches(j.bracket, line, masked, 1, 0, emit)
+	for _, match := range j.destructure.FindAllStringSubmatchIndex(maskedWindow, -1) {
+		for _, item := range strings.Split(submatch(window, match, 1), ",") {
+			name, value, _ := strings.Cut(item, "=")
+			name, _ = destructuredItem(name, ":")
+			if envNamePattern.MatchString(name) {
+				emit(name, literalValue(value))
+			}
+		}
+	}
+
+	cut := max(0, len(window)-jsWindowSize)
+	j.window, j.maskedWindow = window[cut:], maskedWindow[cut:]
+}
+
+// destructuredItem splits a destructured or imported item like "env: e" or
+// "env as e" into the key and the local name it's bound to
+func destructuredItem(item, separator string) (string, string) {
+	item = strings.TrimSpace(item)
+	if strings.HasPrefix(item, "...") {
+		return "", ""
+	}
+	key, local, ok := strings.Cut(item, separator)
+	key = strings.Trim(strings.TrimSpace(key), `"'`)
+	if !ok {
+		return key, key
+	}
+	return key, strings.TrimSpace(local)
+}
+
+var (
+	// import os as o
+	pythonImportOSPattern = regexp.MustCompile(`^\s*import\s+os\s+as\s+(\w+)`)
+
+	// from os import environ, getenv as ge
+	pythonFromOSPattern = regexp.MustCompile(`^\s*from\s+os\s+import\s+\(?([\w\s,]+)`)
+)
+
+// pythonUsages finds os.environ["VAR"], os.environ.get("VAR", default) and
+// os.getenv("VAR", default), through aliased imports too
+type pythonUsages struct {
+	environs []string // names for os.environ
+	getters  []string // names for os.getenv
+
+	subscript, call *regexp.Regexp
+}
+
+func newPythonUsages() *pythonUsages {
+	p := &pythonUsages{environs: []string{"os.environ"}, getters: []string{"os.getenv"}}
+	p.compile()
+	return p
+}
+
+func (p *pythonUsages) compile() {
+	getters := append([]string{}, p.getters...)
+	for _, environ := range p.environs {
+		getters = append(getters, environ+".get")
+	}
+	p.subscript = regexp.MustCompile(`(?:^|[^\w.])(?:` + alternation(p.environs) + `)\[\s*["'](_*)["']\s*\]`)
+	p.call = regexp.MustCompile(`(?:^|[^\w.])(?:` + alternation(getters) + `)\(\s*["'](_*)["'](?:\s*,\s*(["'](?:_*)["']|[\w.]+))?`)
+}
+
+func (p *pythonUsages) match(line, masked string, emit func(varName, value string)) {
+	if match := pythonImportOSPattern.FindStringSubmatch(line); match != nil {
+		p.environs = append(p.environs, match[1]+".environ")
+		p.getters = append(p.getters, match[1]+".getenv")
+		p.compile()
+	}
+	if match := pythonFromOSPattern.FindStringSubmatch(line); match != nil {
+		for _, item := range strings.Split(match[1], ",") {
+			name, local := destructuredItem(item, " as ")
+			switch name {
+			case "environ":
+				p.environs = append(p.environs, local)
+			case "getenv":
+				p.getters = append(p.getters, local)
+			}
+		}
+		p.compile()
+	}
+
+	emitMatches(p.subscript, line, masked, 1, 0, emit)
+	for _, match := range p.call.FindAllStringSubmatchIndex(masked, -1) {
+		if name := submatch(line, match, 1); envNamePattern.MatchString(name) {
+			emit(name, literalValue(submatch(line, match, 2)))
+		}
+	}
+}
+
+// import xos "os", alone or in an import block
+var goImportOSPattern = regexp.MustCompile(`^\s*(?:import\s+)?([A-Za-z_]\w*)\s+"os"\s*$`)
+
+// goUsages finds os.Getenv("VAR") and os.LookupEnv("VAR"), through aliased imports too
+type goUsages struct {
+	packages []string
+	call     *regexp.Regexp
+}
+
+func newGoUsages() *goUsages {
+	g := &goUsages{packages: []string{"os", "syscall"}}
+	g.compile()
+	return g
+}
+
+func (g *goUsages) compile() {
+	g.call = regexp.MustCompile(`(?:^|[^\w.])(?:` + alternation(g.packages) + `)\.(?:Getenv|LookupEnv)\(\s*"(_*)"\s*\)`)
+}
+
+func (g *goUsages) match(line, masked string, emit func(varName, value string)) {
+	if match := goImportOSPattern.FindStringSubmatch(line); match != nil && match[1] != "_" {
+		g.packages = append(g.packages, match[1])
+		g.compile()
+	}
+	emitMatches(g.call, line, masked, 1, 0, emit)
+}
+
+var (
+	rubyEnvIndexPattern = regexp.MustCompile(`\bENV\[\s*["'](_*)["']\s*\]`)
+	rubyEnvFetchPattern = regexp.MustCompile(`\bENV\.fetch\(\s*["'](_*)["'](?:\s*,\s*(["'](?:_*)["']|[\w.]+))?`)
+)
+
+// rubyUsages finds ENV["VAR"] and ENV.fetch("VAR", default)
+type rubyUsages struct{}
+
+func (rubyUsages) match(line, masked string, emit func(varName, value string)) {
+	emitMatches(rubyEnvIndexPattern, line, masked, 1, 0, emit)
+	for _, match := range rubyEnvFetchPattern.FindAllStringSubmatchIndex(masked, -1) {
+		if name := submatch(line, match, 1); envNamePattern.MatchString(name) {
+			emit(name, literalValue(submatch(line, match, 2)))
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package extractors
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// SpringConfigExtractor reads the placeholders in Spring Boot's application.properties
+// and .yml, and in each profile's application-{profile} config. Profiles for running
+// locally or in tests, and documents activated only on them, aren't deployed, so the
+// variables they need aren't either.
+type SpringConfigExtractor struct{}
+
+func NewSpringConfigExtractor() *SpringConfigExtractor {
+	return &SpringConfigExtractor{}
+}
+
+// Profiles only used off the deployment
+var springLocalProfiles = []string{"dev", "development", "local", "test"}
+
+var (
+	// application.yml, application-prod.properties, or bootstrap.yml for Spring Cloud
+	springConfigPattern = regexp.MustCompile(`^(?:application|bootstrap)(?:-([\w.-]+))?\.(?:properties|ya?ml)$`)
+
+	// ${VAR} or ${VAR:default}, but not a property like ${server.port}. A default
+	// holding another placeholder, like ${A:${B:x}}, leaves A without one, and B is
+	// matched on its own.
+	springVariablePattern = regexp.MustCompile(`\$\{([A-Z_][A-Z0-9_]*)(?::([^${}]*)\})?`)
+
+	// The profiles a document of a multi-document config is activated on
+	springOnProfilePattern = regexp.MustCompile(`(?m)^[ \t]*(?:spring\.config\.activate\.on-profile|spring\.profiles|on-profile|profiles)[ \t]*[:=][ \t]*["']?([\w., -]+?)["']?[ \t]*$`)
+	springDocumentPattern  = regexp.MustCompile(`(?m)^(?:---|#---|!---)\s*$`)
+)
+
+func (s *SpringConfigExtractor) CanHandle(filename string) bool {
+	match := springConfigPattern.FindStringSubmatch(strings.ToLower(filepath.Base(filename)))
+	return match != nil && !slices.Contains(springLocalProfiles, match[1])
+}
+
+func (s *SpringConfigExtractor) Confidence() int {
+	return 75 // Placeholders in config are what the app binds on startup
+}
+
+func (s *SpringConfigExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
+	var results []types.EnvResult
+	found := make(map[string]bool)
+	for _, document := range springDocumentPattern.Split(string(content), -1) {
+		if match := springOnProfilePattern.FindStringSubmatch(document); match != nil && springLocalOnly(match[1]) {
+			continue
+		}
+		for _, match := range springVariablePattern.FindAllStringSubmatch(document, -1) {
+			varName, value := match[1], match[2]
+			if found[varName] || types.ShouldIgnore(varName) {
+				continue
+			}
+			found[varName] = true
+
+			envType, sensitive := types.ClassifyEnvVar(varName, value)
+			results = append(results, types.EnvResult{
+				VarName:    varName,
+				Value:      value,
+				Type:       envType,
+				Sensitive:  sensitive,
+				Source:     fmt.Sprintf("spring:%s", filename),
+				Confidence: s.Confidence(),
+			})
+		}
+	}
+	return results, nil
+}
+
+// springLocalOnly reports whether a document's profiles, like dev,local, are all ones
+// that aren't deployed
+func springLocalOnly(profiles string) bool {
+	for _, profile := range strings.Split(profiles, ",") {
+		if !slices.Contains(springLocalProfiles, strings.ToLower(strings.TrimSpace(profile))) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,235 @@
+package extractors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// StartCommandExtractor reads the commands in Procfiles and package.json scripts for
+// the variables they set inline, like NODE_ENV=production node server.js, and the
+// ones they expand, like gunicorn -b 0.0.0.0:$PORT. Each process or script is its own
+// source, like procfile:Procfile#worker, so it's clear which process needs what.
+type StartCommandExtractor struct{}
+
+func NewStartCommandExtractor() *StartCommandExtractor {
+	return &StartCommandExtractor{}
+}
+
+func (s *StartCommandExtractor) CanHandle(filename string) bool {
+	if strings.Contains(filepath.ToSlash(filename), "node_modules/") {
+		return false // Dependencies' scripts don't run
+	}
+	base := filepath.Base(filename)
+	return strings.EqualFold(base, "Procfile") || base == "package.json"
+}
+
+func (s *StartCommandExtractor) Confidence() int {
+	return 65 // Set for the process itself, though other processes may not see them
+}
+
+func (s *StartCommandExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
+	if filepath.Base(filename) == "package.json" {
+		return s.extractScripts(filename, content)
+	}
+
+	var results []types.EnvResult
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		process, command, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(process) == "" {
+			continue
+		}
+		source := fmt.Sprintf("%s#%s", filename, strings.TrimSpace(process))
+		results = append(results, s.commandResults("procfile:"+source, "usage:"+source, command, line, i+1, s.Confidence(), false)...)
+	}
+	return results, nil
+}
+
+// extractScripts reads package.json's scripts. Start and build scripts are trusted
+// more than development and test ones, which set up their own environments.
+func (s *StartCommandExtractor) extractScripts(filename string, content []byte) ([]types.EnvResult, error) {
+	var manifest struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, err
+	}
+
+	text := string(content)
+	scripts := strings.Index(text, `"scripts"`)
+	var results []types.EnvResult
+	for _, script := range slices.Sorted(maps.Keys(manifest.Scripts)) {
+		// Scripts are one line each, found after the scripts key
+		var line string
+		var lineNumber int
+		if at := strings.Index(text[max(scripts, 0):], fmt.Sprintf("%q", script)); scripts != -1 && at != -1 {
+			at += scripts
+			start := strings.LastIndexByte(text[:at], '\n') + 1
+			end := strings.IndexByte(text[at:], '\n')
+			if end == -1 {
+				end = len(text) - at
+			}
+			line, lineNumber = text[start:at+end], strings.Count(text[:at], "\n")+1
+		}
+
+		source := fmt.Sprintf("%s#%s", filename, script)
+		confidence, buildTime := scriptConfidence(script)
+		results = append(results, s.commandResults("package-json:"+source, "usage:"+source, manifest.Scripts[script], line, lineNumber, confidence, buildTime)...)
+	}
+	return results, nil
+}
+
+// scriptConfidence is how much to trust what a package.json script sets, and whether
+// it runs while building
+func scriptConfidence(script string) (int, bool) {
+	name := strings.ToLower(script)
+	switch {
+	case name == "build" || name == "prebuild" || name == "postbuild" || strings.HasPrefix(name, "build:"):
+		return 60, true
+	case name == "start" || name == "serve" || strings.HasPrefix(name, "start:") || strings.Contains(name, "prod"):
+		return 60, false
+	case strings.Contains(name, "dev") || strings.Contains(name, "test") || strings.Contains(name, "watch") || strings.Contains(name, "lint"):
+		return 30, false
+	default:
+		return 45, false
+	}
+}
+
+// shellAssignment matches VAR=value, the way a command sets a variable for itself
+var shellAssignment = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+
+// commandResults reports the variables a command line sets inline and those it
+// expands. line is the text of the file's line the command is on, to locate the
+// variables in, and lineNumber its number, or 0 if it wasn't found.
+func (s *StartCommandExtractor) commandResults(source, usageSource, command, line string, lineNumber, confidence int, buildTime bool) []types.EnvResult {
+	var results []types.EnvResult
+	assigned := make(map[string]bool)
+	used := make(map[string]bool)
+	span := func(name string) types.Span {
+		span := types.Locate(line, name)
+		if span.Line == 0 || lineNumber == 0 {
+			return types.Span{}
+		}
+		span.Line = lineNumber
+		return span
+	}
+	result := func(name, value, source string, confidence int) types.EnvResult {
+		envType, sensitive := types.ClassifyEnvVar(name, value)
+		return types.EnvResult{
+			VarName:    name,
+			Value:      value,
+			Type:       envType,
+			Sensitive:  sensitive,
+			Source:     source,
+			Span:       span(name),
+			Confidence: confidence,
+			BuildTime:  buildTime,
+		}
+	}
+
+	for _, words := range shellCommands(command) {
+		inline := true // assignments only come before the command they're for
+		for i, word := range words {
+			// Variables the word expands, which are read before any it assigns
+			defaults := types.VarDefaults(word.expanded)
+			for _, name := range types.VarReferences(word.expanded) {
+				if !assigned[name] && !used[name] && !types.ShouldIgnore(name) {
+					used[name] = true
+					results = append(results, result(name, defaults[name], usageSource, 50))
+				}
+			}
+
+			match := shellAssignment.FindStringSubmatch(word.value)
+			switch {
+			case inline && match != nil:
+				if !assigned[match[1]] && !types.ShouldIgnore(match[1]) {
+					assigned[match[1]] = true
+					results = append(results, result(match[1], match[2], source, confidence))
+				}
+			case inline && i == 0 && (word.value == "env" || word.value == "export" || word.value == "cross-env"):
+				// env NODE_ENV=production node server.js sets variables the same way
+			case inline && strings.HasPrefix(word.value, "-") && i > 0 && words[0].value == "env":
+				// env's flags, like -u
+			default:
+				inline = false
+			}
+		}
+	}
+	return results
+}
+
+// shellWord is a word of a shell command, with its quotes removed
+type shellWord struct {
+	value    string
+	expanded string // the parts of the word the shell expands variables in, outside single quotes
+}
+
+// shellCommands splits a command line into its commands' words, at &&, ||, ;, |
+// and &. It only understands quotes and escapes, which is all start commands use.
+func shellCommands(line string) [][]shellWord {
+	var commands [][]shellWord
+	var words []shellWord
+	var word, expanded strings.Builder
+	inWord := false
+	endWord := func() {
+		if inWord {
+			words = append(words, shellWord{word.String(), expanded.String()})
+		}
+		word.Reset()
+		expanded.Reset()
+		inWord = false
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+		}
+		words = nil
+	}
+
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				word.WriteByte(c)
+			}
+		case c == '\\' && i+1 < len(line):
+			i++
+			word.WriteByte(line[i])
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				word.WriteByte(c)
+				expanded.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote, inWord = c, true
+		case c == ' ' || c == '\t':
+			endWord()
+		case c == ';' || c == '|' || c == '&':
+			endCommand()
+		default:
+			word.WriteByte(c)
+			expanded.WriteByte(c)
+			inWord = true
+		}
+	}
+	endCommand()
+	return commands
+}
@@ -64,6 +64,10 @@ func (s *StructuredConfigExtractor) Extract(ctx context.Context, filename string
 	var results []types.EnvResult
 	found := make(map[string]bool) // Deduplicate within this file
 
+	if strings.EqualFold(filepath.Ext(filename), ".go") {
+		results = append(results, s.extractGo(filename, contentStr, found)...)
+	}
+
 	for _, pattern := range structuredPatterns {
 		matches := pattern.FindAllStringSubmatch(contentStr, -1)
 		for _, match := range matches {
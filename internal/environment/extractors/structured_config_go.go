@@ -0,0 +1,125 @@
+package extractors
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+var (
+	goStructTagPattern = regexp.MustCompile("`([^`\n]*)`")
+
+	// viper.SetEnvPrefix("app"), v.BindEnv("key", "VAR") and v.SetDefault("key", value)
+	viperEnvPrefixPattern  = regexp.MustCompile(`\.SetEnvPrefix\(\s*"([^"]*)"\s*\)`)
+	viperBindEnvPattern    = regexp.MustCompile(`\.BindEnv\(\s*"([^"]+)"((?:\s*,\s*"[^"]+")*)\s*\)`)
+	viperSetDefaultPattern = regexp.MustCompile(`\.SetDefault\(\s*"([^"]+)"\s*,\s*("[^"]*"|[\w.\-]+)`)
+	goStringArgPattern     = regexp.MustCompile(`"([^"]+)"`)
+
+	// env.Provider("APP_", ".", ...) in koanf v1, env.Provider(".", env.Opt{Prefix: "APP_"}) in v2
+	koanfProviderPattern  = regexp.MustCompile(`env\.Provider(?:WithValue)?\(\s*"([A-Za-z0-9_]*)"`)
+	koanfOptPrefixPattern = regexp.MustCompile(`env\.Opt\{[^}]*Prefix:\s*"([A-Za-z0-9_]*)"`)
+	// k.String("database.url"), on the conventionally named instance
+	koanfGetPattern = regexp.MustCompile(`\bk\.(?:Must)?(?:String|Strings|Int|Int64|Float64|Bool|Duration|Bytes)\(\s*"([A-Za-z0-9_.]+)"\s*\)`)
+)
+
+// extractGo finds the variables Go config libraries read: envconfig and env struct
+// tags with their defaults, viper's bound and defaulted keys, and the keys read
+// through koanf's env provider
+func (s *StructuredConfigExtractor) extractGo(filename, content string, found map[string]bool) []types.EnvResult {
+	var results []types.EnvResult
+	add := func(varName, value string, confidence int) {
+		if varName == "" || found[varName] || types.ShouldIgnore(varName) {
+			return
+		}
+		found[varName] = true
+		envType, sensitive := types.ClassifyEnvVar(varName, value)
+		results = append(results, types.EnvResult{
+			VarName:    varName,
+			Value:      value,
+			Type:       envType,
+			Sensitive:  sensitive,
+			Source:     fmt.Sprintf("config:%s", filename),
+			Confidence: confidence,
+		})
+	}
+
+	// Struct tags: `envconfig:"VAR" default:"value"` and `env:"VAR,required" envDefault:"value"`
+	for _, match := range goStructTagPattern.FindAllStringSubmatch(content, -1) {
+		tag := reflect.StructTag(match[1])
+		if name, ok := tag.Lookup("envconfig"); ok {
+			name, _, _ = strings.Cut(name, ",")
+			add(strings.ToUpper(name), tag.Get("default"), s.Confidence())
+		}
+		if name, ok := tag.Lookup("env"); ok {
+			name, _, _ = strings.Cut(name, ",")
+			add(name, tag.Get("envDefault"), s.Confidence())
+		}
+	}
+
+	if strings.Contains(content, "spf13/viper") {
+		var prefix string
+		if match := viperEnvPrefixPattern.FindStringSubmatch(content); match != nil {
+			prefix = strings.ToUpper(match[1]) + "_"
+		}
+		defaults := make(map[string]string)
+		for _, match := range viperSetDefaultPattern.FindAllStringSubmatch(content, -1) {
+			defaults[match[1]] = goLiteral(match[2])
+		}
+
+		for _, match := range viperBindEnvPattern.FindAllStringSubmatch(content, -1) {
+			key, names := match[1], goStringArgPattern.FindAllStringSubmatch(match[2], -1)
+			if len(names) == 0 {
+				add(viperEnvName(prefix, key), defaults[key], 70)
+			}
+			// Explicit names are used as they are, without the prefix
+			for _, name := range names {
+				add(name[1], defaults[key], s.Confidence())
+			}
+		}
+		// Defaults are only read from the environment with AutomaticEnv
+		if strings.Contains(content, ".AutomaticEnv()") {
+			for _, match := range viperSetDefaultPattern.FindAllStringSubmatch(content, -1) {
+				add(viperEnvName(prefix, match[1]), defaults[match[1]], 70)
+			}
+		}
+	}
+
+	if strings.Contains(content, "knadh/koanf") {
+		prefix, ok := "", false
+		for _, pattern := range []*regexp.Regexp{koanfOptPrefixPattern, koanfProviderPattern} {
+			if match := pattern.FindStringSubmatch(content); match != nil {
+				prefix, ok = match[1], true
+				break
+			}
+		}
+		if ok {
+			// The usual callback strips the prefix, lowercases and maps _ to ., so invert it
+			for _, match := range koanfGetPattern.FindAllStringSubmatch(content, -1) {
+				add(prefix+strings.ToUpper(strings.ReplaceAll(match[1], ".", "_")), "", 70)
+			}
+		}
+	}
+
+	return results
+}
+
+// viperEnvName is the variable AutomaticEnv reads for a key, assuming the common
+// replacer of . and - with _
+func viperEnvName(prefix, key string) string {
+	return prefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// goLiteral returns the value of a string, number or bool literal, or "" for expressions
+func goLiteral(literal string) string {
+	if value, err := strconv.Unquote(literal); err == nil {
+		return value
+	}
+	if _, err := strconv.ParseFloat(literal, 64); err == nil || literal == "true" || literal == "false" {
+		return literal
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package extractors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// VercelExtractor reads the env and build.env of vercel.json
+type VercelExtractor struct{}
+
+func NewVercelExtractor() *VercelExtractor {
+	return &VercelExtractor{}
+}
+
+func (v *VercelExtractor) CanHandle(filename string) bool {
+	return strings.EqualFold(filepath.Base(filename), "vercel.json")
+}
+
+func (v *VercelExtractor) Confidence() int {
+	return 85 // Most Vercel env lives in the dashboard, so this is rarely all of it
+}
+
+func (v *VercelExtractor) Extract(ctx context.Context, filename string, content []byte) ([]types.EnvResult, error) {
+	var config struct {
+		Env   map[string]string `json:"env"`
+		Build struct {
+			Env map[string]string `json:"env"`
+		} `json:"build"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+
+	source := fmt.Sprintf("vercel:%s", filename)
+	var results []types.EnvResult
+	add := func(env map[string]string, buildTime bool) {
+		for _, name := range slices.Sorted(maps.Keys(env)) {
+			if types.ShouldIgnore(name) {
+				continue
+			}
+			value := env[name]
+			result := declaredEnvResult(name, value, source, v.Confidence())
+			if strings.HasPrefix(value, "@") {
+				// @name references a secret stored with the Vercel CLI
+				result.Value, result.Type, result.Sensitive = "", types.EnvTypeSecret, true
+			}
+			result.BuildTime = buildTime
+			results = append(results, result)
+		}
+	}
+	add(config.Env, false)
+	add(config.Build.Env, true)
+	return results, nil
+}
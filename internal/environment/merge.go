@@ -0,0 +1,54 @@
+package environment
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// Merge reduces a service's results to one per variable, sorted by name. The result
+// with the highest confidence wins, the first found on a tie, and keeps every result
+// for the variable in Sources, in the order they were found, with the winner marked.
+// A variable is build time if any result says so, but only build only if all do, as
+// an ARG that's also set with ENV or read at runtime is needed when running too.
+func Merge(results []types.EnvResult) []types.EnvResult {
+	merged := make(map[string]types.EnvResult)
+	sources := make(map[string][]types.EnvSource)
+	winners := make(map[string]int) // index of the winner in sources
+	buildTime := make(map[string]bool)
+	runtime := make(map[string]bool)
+	for _, envVar := range results {
+		buildTime[envVar.VarName] = buildTime[envVar.VarName] || envVar.BuildTime
+		runtime[envVar.VarName] = runtime[envVar.VarName] || !envVar.BuildOnly
+		if existing, exists := merged[envVar.VarName]; !exists || envVar.Confidence > existing.Confidence {
+			merged[envVar.VarName] = envVar
+			winners[envVar.VarName] = len(sources[envVar.VarName])
+		}
+		sources[envVar.VarName] = append(sources[envVar.VarName], types.EnvSource{
+			Source:     envVar.Source,
+			Span:       envVar.Span,
+			Value:      envVar.Value,
+			Confidence: envVar.Confidence,
+		})
+	}
+
+	var mergedResults []types.EnvResult
+	for _, varName := range slices.Sorted(maps.Keys(merged)) {
+		envVar := merged[varName]
+		envVar.Sources = sources[varName]
+		envVar.Sources[winners[varName]].Winner = true
+		envVar.BuildTime, envVar.BuildOnly = buildTime[varName], !runtime[varName]
+		mergedResults = append(mergedResults, envVar)
+	}
+	return mergedResults
+}
+
+// MergeServices merges each service's results, as from ExtractServiceResults, with Merge
+func MergeServices(results map[string][]types.EnvResult) map[string][]types.EnvResult {
+	merged := make(map[string][]types.EnvResult)
+	for name, serviceResults := range results {
+		merged[name] = Merge(serviceResults)
+	}
+	return merged
+}
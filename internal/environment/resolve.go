@@ -0,0 +1,94 @@
+package environment
+
+import "github.com/railwayapp/turnout/internal/environment/types"
+
+// ResolveReferences expands ${VAR} and $VAR references in each service's values with
+// the values declared for the variables they name, so reported values are concrete
+// where possible, and records the variables each value depends on. References to
+// undeclared variables, or in a cycle, are left as written, and values that take in a
+// sensitive variable's value become sensitive themselves. Values taken from another
+// service, like render.yaml's fromService envVarKey, are filled in when that service
+// declares the variable. results are merged, as from MergeServices.
+func ResolveReferences(results map[string][]types.EnvResult) map[string][]types.EnvResult {
+	resolvers := make(map[string]*resolver)
+	for name, serviceResults := range results {
+		resolvers[name] = newResolver(serviceResults)
+	}
+
+	resolved := make(map[string][]types.EnvResult)
+	for name, serviceResults := range results {
+		for _, envVar := range serviceResults {
+			if dependsOn := types.VarReferences(envVar.Value); len(dependsOn) > 0 && !isUsage(envVar) {
+				envVar.DependsOn = dependsOn
+				envVar.Value, _ = resolvers[name].resolve(envVar.VarName)
+				for _, dependency := range dependsOn {
+					envVar.Sensitive = envVar.Sensitive || resolvers[name].sensitive[dependency]
+				}
+			}
+			if ref := envVar.Reference; ref != nil && envVar.Value == "" && ref.Prefix == "" && ref.Suffix == "" {
+				if target := resolvers[ref.Service]; target != nil {
+					if value, concrete := target.resolve(ref.Variable); concrete {
+						envVar.Value = value
+						envVar.Sensitive = envVar.Sensitive || target.sensitive[ref.Variable]
+					}
+				}
+			}
+			resolved[name] = append(resolved[name], envVar)
+		}
+	}
+	return resolved
+}
+
+// resolver expands a service's values, memoizing each variable's expansion
+type resolver struct {
+	values    map[string]string // declared values as written
+	sensitive map[string]bool
+	resolved  map[string]string
+	concrete  map[string]bool
+	visiting  map[string]bool // being expanded, to stop at cycles
+}
+
+func newResolver(results []types.EnvResult) *resolver {
+	r := &resolver{
+		values:    make(map[string]string),
+		sensitive: make(map[string]bool),
+		resolved:  make(map[string]string),
+		concrete:  make(map[string]bool),
+		visiting:  make(map[string]bool),
+	}
+	for _, envVar := range results {
+		// Usages only have defaults, which don't say what the variable is set to
+		if !isUsage(envVar) && envVar.Reference == nil {
+			r.values[envVar.VarName] = envVar.Value
+			r.sensitive[envVar.VarName] = envVar.Sensitive
+		}
+	}
+	return r
+}
+
+// resolve returns a variable's expanded value and whether it's concrete
+func (r *resolver) resolve(name string) (string, bool) {
+	if value, ok := r.resolved[name]; ok {
+		return value, r.concrete[name]
+	}
+	raw, ok := r.values[name]
+	if !ok {
+		return "", false
+	}
+
+	r.visiting[name] = true
+	value, concrete := types.Interpolate(raw, r.lookup)
+	delete(r.visiting, name)
+
+	r.resolved[name], r.concrete[name] = value, concrete
+	return value, concrete
+}
+
+func (r *resolver) lookup(name string) (string, bool, bool) {
+	// A variable referencing itself, like ${LOG_LEVEL:-info}, means the one set outside
+	if _, ok := r.values[name]; !ok || r.visiting[name] {
+		return "", false, false
+	}
+	value, concrete := r.resolve(name)
+	return value, true, concrete
+}
@@ -0,0 +1,190 @@
+package environment
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+
+	discoveryTypes "github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/environment/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"golang.org/x/sync/errgroup"
+)
+
+// ExtractServices extracts the environment variables of each service from the files under
+// its build path, without crossing into other services' directories, and from the
+// shared env files above it, as described on ExtractServiceResults. Variables are
+// merged by name, as by Merge, and have references to other variables in their
+// values resolved.
+func (e *Extractor) ExtractServices(ctx context.Context, services []discoveryTypes.Service) map[string][]types.EnvResult {
+	return ResolveReferences(MergeServices(e.ExtractServiceResults(ctx, services)))
+}
+
+// ExtractServiceResults is ExtractServices without deduplication: every result from
+// every file, in walk order, for passes that care where each variable appears.
+//
+// Shared env files, like .env and .envrc, in the root and each directory between it
+// and a service's build path apply to the service as well, and come first. The root is
+// the filesystem's, ".", unless SetRoot says otherwise. They're
+// one point less confident for each directory they are above the service, so of two
+// equally trusted files the one closest to the service wins: api/.env overrides a
+// root .env, while a root .env still beats api/.env.example.
+func (e *Extractor) ExtractServiceResults(ctx context.Context, services []discoveryTypes.Service) map[string][]types.EnvResult {
+	// Collect all service BuildPaths to avoid crossing boundaries
+	servicePaths := make(map[string]bool)
+	for _, service := range services {
+		if service.BuildPath != "" {
+			servicePaths[service.BuildPath] = true
+		}
+	}
+
+	root := "."
+	if e.root != "" {
+		root = e.filesystem.Join(e.root)
+	}
+
+	// List each directory once, however many services share it, so every file is
+	// extracted once
+	var paths []string            // every file to extract
+	index := make(map[string]int) // each file's position in paths
+	add := func(files []string) {
+		for _, path := range files {
+			if _, ok := index[path]; !ok {
+				index[path] = len(paths)
+				paths = append(paths, path)
+			}
+		}
+	}
+	walked := make(map[string][]string) // the files under each build path, in walk order
+	shared := make(map[string][]string) // the shared env files in each directory above a service
+	for _, service := range services {
+		if service.BuildPath == "" {
+			continue
+		}
+		for _, dir := range e.dirsAbove(root, service.BuildPath) {
+			if _, ok := shared[dir]; !ok {
+				shared[dir] = e.sharedEnvFiles(dir)
+				add(shared[dir])
+			}
+		}
+		if _, ok := walked[service.BuildPath]; !ok {
+			walked[service.BuildPath] = e.walkFiles(service.BuildPath, servicePaths)
+			add(walked[service.BuildPath])
+		}
+	}
+
+	fileResults := e.extractFiles(ctx, paths)
+
+	results := make(map[string][]types.EnvResult)
+	for _, service := range services {
+		if service.BuildPath == "" {
+			continue
+		}
+		dirs := e.dirsAbove(root, service.BuildPath)
+		for i, dir := range dirs {
+			for _, path := range shared[dir] {
+				for _, envVar := range fileResults[index[path]] {
+					envVar.Confidence -= len(dirs) - i
+					results[service.Name] = append(results[service.Name], envVar)
+				}
+			}
+		}
+		for _, path := range walked[service.BuildPath] {
+			results[service.Name] = append(results[service.Name], fileResults[index[path]]...)
+		}
+	}
+	return results
+}
+
+// walkFiles lists the files under a build path that could be extracted, without
+// crossing into other services' directories
+func (e *Extractor) walkFiles(buildPath string, servicePaths map[string]bool) []string {
+	var files []string
+	_ = e.filesystem.Walk(buildPath, func(path string, info filesystems.FileInfo, err error) error {
+		if err != nil {
+			return nil // Skip files we can't access
+		}
+
+		// Skip if this is another service's directory
+		if path != buildPath && servicePaths[path] {
+			return filesystems.SkipDir
+		}
+		if info.IsDir() || info.Size() > filesystems.MaxTextFileSize {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files
+}
+
+// extractWorkers is how many files are extracted at once. Extraction mostly waits on
+// reads, so it's worth running more workers than there are CPUs.
+var extractWorkers = 4 * runtime.GOMAXPROCS(0)
+
+// extractFiles extracts files across a pool of workers, returning each file's results
+// at its index in paths
+func (e *Extractor) extractFiles(ctx context.Context, paths []string) [][]types.EnvResult {
+	results := make([][]types.EnvResult, len(paths))
+	var workers errgroup.Group
+	workers.SetLimit(extractWorkers)
+	for i, path := range paths {
+		workers.Go(func() error {
+			if ctx.Err() == nil {
+				results[i] = e.extractServiceFile(ctx, path)
+			}
+			return nil
+		})
+	}
+	_ = workers.Wait()
+	return results
+}
+
+// extractServiceFile extracts a file's variables, marking sensitive values Committed
+// when the file is tracked by git. Only files with sensitive values are looked up.
+func (e *Extractor) extractServiceFile(ctx context.Context, path string) []types.EnvResult {
+	var results []types.EnvResult
+	tracked := sync.OnceValue(func() bool { return filesystems.IsTracked(e.filesystem, path) })
+	for envVar := range e.ExtractFile(ctx, path) {
+		envVar.Committed = envVar.Sensitive && envVar.Value != "" && tracked()
+		results = append(results, envVar)
+	}
+	return results
+}
+
+// sharedEnvFile reports whether a file applies to the services below its directory,
+// like .env, .env.production and .envrc
+func sharedEnvFile(name string) bool {
+	return strings.HasPrefix(strings.ToLower(name), ".env")
+}
+
+// sharedEnvFiles lists the shared env files directly in a directory
+func (e *Extractor) sharedEnvFiles(dir string) []string {
+	var files []string
+	for entry, err := range e.filesystem.ReadDir(dir) {
+		if err != nil {
+			break
+		}
+		if !entry.IsDir() && sharedEnvFile(entry.Name()) {
+			files = append(files, e.filesystem.Join(dir, entry.Name()))
+		}
+	}
+	return files
+}
+
+// dirsAbove lists the directories from root down to path's parent, or none if path
+// isn't under root
+func (e *Extractor) dirsAbove(root, path string) []string {
+	if rel, err := e.filesystem.Rel(root, path); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+
+	var dirs []string
+	for dir := e.filesystem.Dir(path); ; dir = e.filesystem.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+		if dir == root || e.filesystem.Dir(dir) == dir {
+			return dirs
+		}
+	}
+}
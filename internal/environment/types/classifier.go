@@ -3,7 +3,6 @@ package types
 import (
 	"strconv"
 	"strings"
-	"unicode"
 )
 
 var secretPatterns = []string{
@@ -41,11 +40,23 @@ func ShouldIgnore(name string) bool {
 	return false
 }
 
+// ClassifyEnvVar returns a variable's type and whether it's sensitive, from patterns
+// in its name and value, adjusted by the Sensitivity policy
 func ClassifyEnvVar(name, value string) (EnvType, bool) {
+	// Public variables are inlined into client bundles, so they're never sensitive
+	if prefix := PublicEnvPrefix(name); prefix != "" {
+		envType, _ := classifyEnvVar(strings.TrimPrefix(name, prefix), value)
+		return envType, false
+	}
+	envType, sensitive := classifyEnvVar(name, value)
+	return Sensitivity.apply(name, envType, sensitive)
+}
+
+func classifyEnvVar(name, value string) (EnvType, bool) {
 	nameLower := strings.ToLower(name)
 
 	// Check if value looks generated first
-	if looksGenerated(value) {
+	if LooksGenerated(value) {
 		return EnvTypeGenerated, true
 	}
 
@@ -83,72 +94,19 @@ func ClassifyEnvVar(name, value string) (EnvType, bool) {
 	return EnvTypeConfig, false
 }
 
-func looksGenerated(value string) bool {
-	if len(value) < 8 {
-		return false
-	}
-
-	// UUID pattern (36 chars with dashes)
-	if len(value) == 36 && strings.Count(value, "-") == 4 {
-		return true
-	}
-
-	// Nanoid pattern (URL-safe base64, typically 21 chars but can vary)
-	if isURLSafeBase64(value) && len(value) >= 16 {
-		return true
-	}
-
-	// JWT tokens (3 base64 parts separated by dots)
-	if strings.Count(value, ".") == 2 && len(value) > 50 {
-		return true
-	}
+// Words in values that are stand-ins for a real one, like your-api-key or changeme
+var placeholderWords = []string{"your", "change", "example", "placeholder", "replace", "xxx", "todo", "dummy", "sample", "fake", "<"}
 
-	// General high-entropy check for other generated values
-	if len(value) >= 20 && hasHighEntropy(value) && containsMixedCase(value) {
-		return true
-	}
-
-	return false
-}
-
-func isURLSafeBase64(s string) bool {
-	// Check if string only contains URL-safe base64 characters
-	for _, r := range s {
-		if !((r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') ||
-			(r >= '0' && r <= '9') || r == '-' || r == '_') {
+// HighEntropy reports whether a value looks random enough to be a real credential,
+// rather than a word or a placeholder like the ones in example files
+func HighEntropy(value string) bool {
+	valueLower := strings.ToLower(value)
+	for _, word := range placeholderWords {
+		if strings.Contains(valueLower, word) {
 			return false
 		}
 	}
-	return true
-}
-
-func hasHighEntropy(value string) bool {
-	// Count unique characters
-	charCount := make(map[rune]int)
-	for _, r := range value {
-		charCount[r]++
-	}
-
-	// High entropy if more than 50% unique characters
-	uniqueRatio := float64(len(charCount)) / float64(len(value))
-	return uniqueRatio > 0.5
-}
-
-func containsMixedCase(value string) bool {
-	hasUpper := false
-	hasLower := false
-	for _, r := range value {
-		if unicode.IsUpper(r) {
-			hasUpper = true
-		}
-		if unicode.IsLower(r) {
-			hasLower = true
-		}
-		if hasUpper && hasLower {
-			return true
-		}
-	}
-	return false
+	return LooksGenerated(value)
 }
 
 func isNumeric(value string) bool {
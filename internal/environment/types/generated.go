@@ -0,0 +1,109 @@
+package types
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// EntropyThresholds tune the generic detector, which catches random values no known
+// format matches
+type EntropyThresholds struct {
+	MinLength  int     // shortest value that can look random
+	MinEntropy float64 // fewest bits of entropy per character
+}
+
+// Entropy is the thresholds LooksGenerated applies
+var Entropy = EntropyThresholds{MinLength: 16, MinEntropy: 3.5}
+
+// generatedDetector recognizes one format of generated value
+type generatedDetector struct {
+	name   string
+	detect func(value string) bool
+}
+
+var (
+	uuidPattern      = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	jwtPattern       = regexp.MustCompile(`^eyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]*$`)
+	githubPattern    = regexp.MustCompile(`^(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})$`)
+	awsKeyIDPattern  = regexp.MustCompile(`^(?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[A-Z0-9]{16}$`)
+	awsSecretPattern = regexp.MustCompile(`^[A-Za-z0-9/+]{40}$`)
+	hexPattern       = regexp.MustCompile(`^(?:[0-9a-f]{16,}|[0-9A-F]{16,})$`)
+	base58Pattern    = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,}$`)
+	base64Pattern    = regexp.MustCompile(`^[A-Za-z0-9_+/-]+={0,2}$`)
+)
+
+// generatedDetectors are tried in order, most specific first
+var generatedDetectors = []generatedDetector{
+	{"uuid", uuidPattern.MatchString},
+	{"jwt", jwtPattern.MatchString},
+	{"github-token", githubPattern.MatchString},
+	{"aws-access-key-id", awsKeyIDPattern.MatchString},
+	{"hex", func(value string) bool {
+		// A hash or key, with digits and letters so it isn't a number or a word
+		return hexPattern.MatchString(value) && hasDigit(value) && strings.ContainsAny(strings.ToLower(value), "abcdef")
+	}},
+	{"aws-secret-access-key", func(value string) bool {
+		return awsSecretPattern.MatchString(value) && hasDigit(value) && !wordLike(value)
+	}},
+	{"base58", func(value string) bool {
+		return base58Pattern.MatchString(value) && hasDigit(value) && !wordLike(value)
+	}},
+	{"random", func(value string) bool {
+		return len(value) >= Entropy.MinLength && base64Pattern.MatchString(value) &&
+			shannonEntropy(value) >= Entropy.MinEntropy && !wordLike(value)
+	}},
+}
+
+// DetectGenerated names the format of a generated value, like uuid, jwt or hex, or
+// returns "" if the value doesn't look generated
+func DetectGenerated(value string) string {
+	for _, detector := range generatedDetectors {
+		if detector.detect(value) {
+			return detector.name
+		}
+	}
+	return ""
+}
+
+// LooksGenerated reports whether a value looks generated, like a token or key
+func LooksGenerated(value string) bool {
+	return DetectGenerated(value) != ""
+}
+
+// wordLike reports whether a value reads like words, such as a camelCase identifier,
+// rather than random characters: random letters are rarely vowels and random values
+// have digits
+func wordLike(value string) bool {
+	var letters, vowels, digits int
+	for _, r := range value {
+		switch {
+		case strings.ContainsRune("aeiouAEIOU", r):
+			letters++
+			vowels++
+		case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			letters++
+		case r >= '0' && r <= '9':
+			digits++
+		}
+	}
+	return letters > 0 && float64(vowels)/float64(letters) >= 0.3 && digits <= len(value)/10
+}
+
+func hasDigit(value string) bool {
+	return strings.ContainsAny(value, "0123456789")
+}
+
+// shannonEntropy is the bits of information per byte of a value
+func shannonEntropy(value string) float64 {
+	counts := make(map[byte]int)
+	for i := 0; i < len(value); i++ {
+		counts[value[i]]++
+	}
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(len(value))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
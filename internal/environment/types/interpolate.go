@@ -0,0 +1,89 @@
+package types
+
+import (
+	"regexp"
+	"slices"
+)
+
+// varReferencePattern matches the references shells, dotenv and compose expand in
+// values: $VAR, ${VAR} and ${VAR} with a modifier like ${VAR:-default}. $$ is an
+// escaped $ in compose files and is matched so it's skipped.
+var varReferencePattern = regexp.MustCompile(`\$(?:\$|\{([A-Za-z_][A-Za-z0-9_]*)(?:(:?[-?+])([^}]*))?\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// VarReferences lists the variables a value references, in order of first reference
+func VarReferences(value string) []string {
+	var names []string
+	for _, match := range varReferencePattern.FindAllStringSubmatch(value, -1) {
+		name := match[1] + match[4]
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+		// Defaults can reference variables too
+		for _, name := range VarReferences(match[3]) {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// VarDefaults returns the defaults a value gives the variables it references, like
+// info for ${LOG_LEVEL:-info}. Variables referenced without one are left out, and the
+// first default given wins.
+func VarDefaults(value string) map[string]string {
+	defaults := make(map[string]string)
+	for _, match := range varReferencePattern.FindAllStringSubmatch(value, -1) {
+		name, modifier, word := match[1], match[2], match[3]
+		if _, ok := defaults[name]; !ok && name != "" && (modifier == "-" || modifier == ":-") {
+			defaults[name] = word
+		}
+	}
+	return defaults
+}
+
+// VarLookup returns a variable's value, whether it's set, and whether the value is
+// concrete rather than depending on something unknown
+type VarLookup func(name string) (value string, set, concrete bool)
+
+// Interpolate expands the references in a value with lookup, applying defaults like
+// ${VAR:-default} when a variable is unset. References that can't be expanded are
+// left as written, and the result is only concrete if every reference was expanded.
+func Interpolate(value string, lookup VarLookup) (string, bool) {
+	concrete := true
+	expanded := varReferencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		match := varReferencePattern.FindStringSubmatch(reference)
+		name, modifier, word := match[1]+match[4], match[2], match[3]
+		if name == "" {
+			return reference // $$
+		}
+
+		v, set, known := lookup(name)
+		if set && !known {
+			concrete = false
+			return reference
+		}
+		empty := !set || (v == "" && modifier != "" && modifier[0] == ':')
+		switch modifier {
+		case ":-", "-":
+			if empty {
+				w, ok := Interpolate(word, lookup)
+				concrete = concrete && ok
+				return w
+			}
+		case ":+", "+":
+			if empty {
+				return ""
+			}
+			w, ok := Interpolate(word, lookup)
+			concrete = concrete && ok
+			return w
+		}
+		if !set {
+			concrete = false
+			return reference
+		}
+		return v
+	})
+	return expanded, concrete
+}
@@ -0,0 +1,46 @@
+package types
+
+import "strings"
+
+// Prefixes frameworks inline into client bundles at build time, so the values
+// are public whatever the variables are named
+var publicEnvPrefixes = []string{
+	"NEXT_PUBLIC_", // Next.js
+	"NUXT_PUBLIC_", // Nuxt
+	"EXPO_PUBLIC_", // Expo
+	"REACT_APP_",   // Create React App
+	"VITE_",        // Vite
+	"GATSBY_",      // Gatsby
+	"PUBLIC_",      // Astro and SvelteKit
+}
+
+// Names of values meant to be public, even though they look like secrets
+var publicCredentialPatterns = []string{"publishable", "anon_key", "public_key", "site_key", "client_id", "dsn", "measurement_id"}
+
+// PublicEnvPrefix returns the public prefix a variable is named with, or "" if none
+func PublicEnvPrefix(name string) string {
+	for _, prefix := range publicEnvPrefixes {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// IsPublicSecret reports whether a variable with a public prefix is named like a
+// secret, which would leak it into the client bundle
+func IsPublicSecret(name string) bool {
+	prefix := PublicEnvPrefix(name)
+	if prefix == "" {
+		return false
+	}
+	unprefixed := strings.TrimPrefix(name, prefix)
+	lower := strings.ToLower(unprefixed)
+	for _, pattern := range publicCredentialPatterns {
+		if strings.Contains(lower, pattern) {
+			return false
+		}
+	}
+	_, sensitive := classifyEnvVar(unprefixed, "")
+	return sensitive
+}
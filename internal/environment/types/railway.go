@@ -0,0 +1,64 @@
+package types
+
+import "strings"
+
+// Variables Railway sets on every deployment, beyond the RAILWAY_ prefixed ones
+var railwayProvidedVariables = []string{"PORT"}
+
+// IsRailwayProvided reports whether Railway sets a variable itself, like PORT or
+// RAILWAY_PUBLIC_DOMAIN, so it never needs to be declared
+func IsRailwayProvided(name string) bool {
+	for _, provided := range railwayProvidedVariables {
+		if name == provided {
+			return true
+		}
+	}
+	return strings.HasPrefix(name, "RAILWAY_")
+}
+
+// RailwayTranslation is what to set a variable another platform provides to on Railway
+type RailwayTranslation struct {
+	Platform string // the platform that provides the variable, like Render
+	Value    string // a Railway reference variable to set it to, like https://${{RAILWAY_PUBLIC_DOMAIN}}
+}
+
+// Variables other platforms set on their deployments, with the Railway variables
+// that provide the same value
+var platformVariables = map[string]RailwayTranslation{
+	// Render
+	"RENDER_EXTERNAL_URL":      {"Render", "https://${{RAILWAY_PUBLIC_DOMAIN}}"},
+	"RENDER_EXTERNAL_HOSTNAME": {"Render", "${{RAILWAY_PUBLIC_DOMAIN}}"},
+	"RENDER_SERVICE_NAME":      {"Render", "${{RAILWAY_SERVICE_NAME}}"},
+	"RENDER_SERVICE_ID":        {"Render", "${{RAILWAY_SERVICE_ID}}"},
+	"RENDER_INSTANCE_ID":       {"Render", "${{RAILWAY_REPLICA_ID}}"},
+	"RENDER_GIT_COMMIT":        {"Render", "${{RAILWAY_GIT_COMMIT_SHA}}"},
+	"RENDER_GIT_BRANCH":        {"Render", "${{RAILWAY_GIT_BRANCH}}"},
+
+	// Fly.io
+	"FLY_APP_NAME":   {"Fly.io", "${{RAILWAY_SERVICE_NAME}}"},
+	"FLY_REGION":     {"Fly.io", "${{RAILWAY_REPLICA_REGION}}"},
+	"FLY_ALLOC_ID":   {"Fly.io", "${{RAILWAY_REPLICA_ID}}"},
+	"FLY_MACHINE_ID": {"Fly.io", "${{RAILWAY_REPLICA_ID}}"},
+
+	// Vercel
+	"VERCEL_URL":                    {"Vercel", "${{RAILWAY_PUBLIC_DOMAIN}}"},
+	"VERCEL_PROJECT_PRODUCTION_URL": {"Vercel", "${{RAILWAY_PUBLIC_DOMAIN}}"},
+	"VERCEL_BRANCH_URL":             {"Vercel", "${{RAILWAY_PUBLIC_DOMAIN}}"},
+	"VERCEL_ENV":                    {"Vercel", "${{RAILWAY_ENVIRONMENT_NAME}}"},
+	"VERCEL_REGION":                 {"Vercel", "${{RAILWAY_REPLICA_REGION}}"},
+	"VERCEL_GIT_COMMIT_SHA":         {"Vercel", "${{RAILWAY_GIT_COMMIT_SHA}}"},
+	"VERCEL_GIT_COMMIT_REF":         {"Vercel", "${{RAILWAY_GIT_BRANCH}}"},
+
+	// Heroku, with dyno metadata enabled
+	"HEROKU_APP_NAME":    {"Heroku", "${{RAILWAY_SERVICE_NAME}}"},
+	"HEROKU_APP_ID":      {"Heroku", "${{RAILWAY_SERVICE_ID}}"},
+	"HEROKU_SLUG_COMMIT": {"Heroku", "${{RAILWAY_GIT_COMMIT_SHA}}"},
+	"DYNO":               {"Heroku", "${{RAILWAY_REPLICA_ID}}"},
+}
+
+// TranslateToRailway returns the Railway equivalent of a variable another platform
+// provides, like RENDER_EXTERNAL_URL or VERCEL_URL
+func TranslateToRailway(name string) (RailwayTranslation, bool) {
+	translation, ok := platformVariables[name]
+	return translation, ok
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// SensitivityPolicy adjusts which variables ClassifyEnvVar marks sensitive, on top of
+// the built-in name and value patterns
+type SensitivityPolicy struct {
+	Patterns []*regexp.Regexp // names matching any of these are sensitive
+	Safe     []string         // names that are never sensitive, whatever they look like
+}
+
+// Sensitivity is the policy ClassifyEnvVar applies, empty unless configured
+var Sensitivity SensitivityPolicy
+
+// NewSensitivityPolicy compiles a policy from regular expressions matched against
+// variable names, case-insensitively, and a list of names known to be safe
+func NewSensitivityPolicy(patterns, safe []string) (SensitivityPolicy, error) {
+	policy := SensitivityPolicy{Safe: safe}
+	for _, pattern := range patterns {
+		compiled, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return SensitivityPolicy{}, fmt.Errorf("invalid sensitive pattern %q: %w", pattern, err)
+		}
+		policy.Patterns = append(policy.Patterns, compiled)
+	}
+	return policy, nil
+}
+
+// apply adjusts a classification by the policy. Safe names win over patterns.
+func (p SensitivityPolicy) apply(name string, envType EnvType, sensitive bool) (EnvType, bool) {
+	if slices.Contains(p.Safe, name) {
+		if envType == EnvTypeSecret {
+			envType = EnvTypeConfig
+		}
+		return envType, false
+	}
+	for _, pattern := range p.Patterns {
+		if pattern.MatchString(name) {
+			if !sensitive {
+				envType = EnvTypeSecret
+			}
+			return envType, true
+		}
+	}
+	return envType, sensitive
+}
+
+// RedactedValue replaces sensitive values in output
+const RedactedValue = "********"
+
+// Redact masks a value if it's sensitive. Empty values are left empty, so it's
+// still clear the variable has no value.
+func Redact(value string, sensitive bool) string {
+	if sensitive && value != "" {
+		return RedactedValue
+	}
+	return value
+}
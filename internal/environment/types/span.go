@@ -0,0 +1,52 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Span is where a variable's name appears in a file: on Line, from Column up to but
+// not including EndColumn. Lines and columns are 1-based, and columns count bytes. A
+// zero Line means the position isn't known, and a zero Column that only the line is.
+type Span struct {
+	Line      int
+	Column    int
+	EndColumn int
+}
+
+// String formats the span as line:column, the way editors and compilers do
+func (s Span) String() string {
+	switch {
+	case s.Line == 0:
+		return ""
+	case s.Column == 0:
+		return fmt.Sprint(s.Line)
+	default:
+		return fmt.Sprintf("%d:%d", s.Line, s.Column)
+	}
+}
+
+// Locate finds the first place name appears in text as a whole word, like DB_HOST
+// but not DB_HOSTNAME, or returns a zero Span if it doesn't
+func Locate(text, name string) Span {
+	if name == "" {
+		return Span{}
+	}
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], name)
+		if i == -1 {
+			return Span{}
+		}
+		start, end := offset+i, offset+i+len(name)
+		if (start == 0 || !isWordByte(text[start-1])) && (end == len(text) || !isWordByte(text[end])) {
+			lineStart := strings.LastIndexByte(text[:start], '\n') + 1
+			column := start - lineStart + 1
+			return Span{Line: strings.Count(text[:start], "\n") + 1, Column: column, EndColumn: column + len(name)}
+		}
+		offset = end
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z'
+}
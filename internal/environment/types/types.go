@@ -1,5 +1,7 @@
 package types
 
+import "strings"
+
 type EnvType int
 
 const (
@@ -19,5 +21,40 @@ type EnvResult struct {
 	Type       EnvType
 	Sensitive  bool
 	Source     string // e.g., "docker-compose:/path/to/file"
+	Span       Span   // where in Source's file the variable is declared or used
 	Confidence int
+	BuildTime  bool              // inlined at build time, like variables with a public prefix such as NEXT_PUBLIC_
+	BuildOnly  bool              // only read while building, like a Dockerfile ARG
+	Reference  *ServiceReference // set when the value comes from another service
+	DependsOn  []string          // variables the value references, like DB_HOST in postgres://${DB_HOST}/app
+	Sources    []EnvSource       // every place a merged variable was found
+	Committed  bool              // the sensitive value is in a file committed to the repository
+}
+
+// File returns the path of the file a result came from, without Source's prefix
+func (r EnvResult) File() string {
+	_, file, ok := strings.Cut(r.Source, ":")
+	if !ok {
+		return ""
+	}
+	file, _, _ = strings.Cut(file, "#") // render:render.yaml#web
+	return file
+}
+
+// EnvSource is one of the places a merged variable was found
+type EnvSource struct {
+	Source     string
+	Span       Span
+	Value      string
+	Confidence int
+	Winner     bool // the merged variable's value came from here
+}
+
+// ServiceReference is a value a config takes from another service, like render.yaml's
+// fromService, in Railway's terms: the other service's variable, with any text around it
+type ServiceReference struct {
+	Service  string
+	Variable string
+	Prefix   string
+	Suffix   string
 }
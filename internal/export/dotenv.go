@@ -0,0 +1,131 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+// DotenvExporter writes a .env.<service> file per service with the values found for
+// local development. Sensitive values are left blank to be filled in.
+type DotenvExporter struct{}
+
+func NewDotenvExporter() FileExporter {
+	return &DotenvExporter{}
+}
+
+func (e *DotenvExporter) Name() string {
+	return "dotenv"
+}
+
+// Export returns every service's variables, each under a comment naming the service
+func (e *DotenvExporter) Export(project *schema.Project) ([]byte, error) {
+	var b strings.Builder
+	for i, service := range project.Services {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# %s\n", service.Name)
+		b.Write(e.dotenv(service))
+	}
+	return []byte(b.String()), nil
+}
+
+func (e *DotenvExporter) ExportFiles(project *schema.Project) ([]File, error) {
+	var files []File
+	for _, service := range project.Services {
+		if len(service.Environment) > 0 {
+			files = append(files, File{Path: ".env." + configFileName(service.Name), Content: e.dotenv(service)})
+		}
+	}
+	return files, nil
+}
+
+func (e *DotenvExporter) dotenv(service schema.Service) []byte {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(service.Environment)) {
+		envVar := service.Environment[name]
+		if envVar.Sensitive {
+			fmt.Fprintf(&b, "# %s is sensitive, set it before use\n%s=\n", name, name)
+			continue
+		}
+		fmt.Fprintf(&b, "%s=%s\n", name, dotenvValue(envVar.Value))
+	}
+	return []byte(b.String())
+}
+
+// dotenvValue quotes a value if it needs it. Values with a $ are single-quoted so
+// loaders don't expand them, and other values with spaces, quotes, # or newlines are
+// double-quoted with escapes.
+func dotenvValue(value string) string {
+	switch {
+	case value == "" || !strings.ContainsAny(value, " \t\n\r\"'#$\\`"):
+		return value
+	case strings.Contains(value, "$") && !strings.ContainsAny(value, "'\n\r"):
+		return "'" + value + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`).Replace(value) + `"`
+}
+
+// RailwayVariablesExporter writes a <service>.variables.json file per service in the
+// JSON format Railway's raw variable editor imports. Variables pointing at other
+// services are Railway references, variables other platforms provided are mapped to
+// Railway's, and the ones Railway provides itself are left out. Sensitive values are
+// left blank to be filled in.
+type RailwayVariablesExporter struct{}
+
+func NewRailwayVariablesExporter() FileExporter {
+	return &RailwayVariablesExporter{}
+}
+
+func (e *RailwayVariablesExporter) Name() string {
+	return "railway-variables"
+}
+
+// Export returns every service's variables keyed by service name
+func (e *RailwayVariablesExporter) Export(project *schema.Project) ([]byte, error) {
+	variables := make(map[string]map[string]string)
+	for _, service := range project.Services {
+		variables[service.Name] = RailwayVariables(service)
+	}
+	return json.MarshalIndent(variables, "", "  ")
+}
+
+func (e *RailwayVariablesExporter) ExportFiles(project *schema.Project) ([]File, error) {
+	var files []File
+	for _, service := range project.Services {
+		variables := RailwayVariables(service)
+		if len(variables) == 0 {
+			continue
+		}
+		content, err := json.MarshalIndent(variables, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", service.Name, err)
+		}
+		files = append(files, File{Path: configFileName(service.Name) + ".variables.json", Content: append(content, '\n')})
+	}
+	return files, nil
+}
+
+// RailwayVariables returns the variables to set on a service on Railway
+func RailwayVariables(service schema.Service) map[string]string {
+	variables := make(map[string]string)
+	for name, envVar := range service.Environment {
+		if types.IsRailwayProvided(name) {
+			continue
+		}
+		value := envVar.RailwayValue()
+		if translation, ok := types.TranslateToRailway(name); ok {
+			value = translation.Value
+		} else if envVar.Sensitive && envVar.Reference == nil {
+			value = ""
+		}
+		variables[name] = value
+	}
+	return variables
+}
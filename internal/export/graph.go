@@ -0,0 +1,178 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+// Graph formats
+const (
+	GraphDOT     = "dot"
+	GraphMermaid = "mermaid"
+)
+
+// GraphExporter draws the project's services and who depends on whom, as Graphviz DOT
+// or a Mermaid flowchart. Edges come from declared dependencies and from variables
+// referencing another service, labelled with the variables.
+type GraphExporter struct {
+	format string
+}
+
+func NewGraphExporter(format string) (Exporter, error) {
+	if format != GraphDOT && format != GraphMermaid {
+		return nil, fmt.Errorf("unknown graph format %q, expected dot or mermaid", format)
+	}
+	return &GraphExporter{format: format}, nil
+}
+
+func (e *GraphExporter) Name() string {
+	return e.format
+}
+
+// graphEdge is a service depending on another, through the variables listed, if any
+type graphEdge struct {
+	from, to  string
+	variables []string
+}
+
+func (e *GraphExporter) Export(project *schema.Project) ([]byte, error) {
+	// Sorted, so the same project always draws the same graph
+	sorted := *project
+	sorted.Services = slices.SortedFunc(slices.Values(project.Services), func(a, b schema.Service) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	project = &sorted
+
+	if e.format == GraphMermaid {
+		return e.mermaid(project), nil
+	}
+	return e.dot(project), nil
+}
+
+func (e *GraphExporter) dot(project *schema.Project) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "digraph %s {\n", strconv.Quote(project.Name))
+	buf.WriteString("  rankdir=LR;\n  node [shape=box];\n")
+	for _, service := range project.Services {
+		attributes := []string{"label=" + strconv.Quote(strings.Join(nodeLabel(service), "\n"))}
+		if service.Managed != nil {
+			attributes = append(attributes, "shape=cylinder")
+		}
+		if service.Derived {
+			attributes = append(attributes, "style=dashed")
+		}
+		fmt.Fprintf(&buf, "  %s [%s];\n", strconv.Quote(service.Name), strings.Join(attributes, ", "))
+	}
+	for _, edge := range graphEdges(project) {
+		fmt.Fprintf(&buf, "  %s -> %s", strconv.Quote(edge.from), strconv.Quote(edge.to))
+		if len(edge.variables) > 0 {
+			fmt.Fprintf(&buf, " [label=%s]", strconv.Quote(strings.Join(edge.variables, "\n")))
+		}
+		buf.WriteString(";\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+func (e *GraphExporter) mermaid(project *schema.Project) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("flowchart LR\n")
+	ids := mermaidIDs(project)
+	var implied []string
+	for _, service := range project.Services {
+		label := mermaidText(strings.Join(nodeLabel(service), "<br/>"))
+		if service.Managed != nil {
+			fmt.Fprintf(&buf, "  %s[(\"%s\")]\n", ids[service.Name], label)
+		} else {
+			fmt.Fprintf(&buf, "  %s[\"%s\"]\n", ids[service.Name], label)
+		}
+		if service.Derived {
+			implied = append(implied, ids[service.Name])
+		}
+	}
+	for _, edge := range graphEdges(project) {
+		if len(edge.variables) > 0 {
+			fmt.Fprintf(&buf, "  %s -->|\"%s\"| %s\n", ids[edge.from], mermaidText(strings.Join(edge.variables, "<br/>")), ids[edge.to])
+		} else {
+			fmt.Fprintf(&buf, "  %s --> %s\n", ids[edge.from], ids[edge.to])
+		}
+	}
+	if len(implied) > 0 {
+		buf.WriteString("  classDef implied stroke-dasharray: 5 5\n")
+		fmt.Fprintf(&buf, "  class %s implied\n", strings.Join(implied, ","))
+	}
+	return buf.Bytes()
+}
+
+// nodeLabel is the lines labelling a service: its name, and what it's provisioned
+// from when that's a Railway database or it was implied rather than declared
+func nodeLabel(service schema.Service) []string {
+	label := []string{service.Name}
+	if service.Managed != nil {
+		label = append(label, "Railway "+service.Managed.Template)
+	}
+	if service.Derived {
+		label = append(label, "(implied)")
+	}
+	return label
+}
+
+// graphEdges collects every dependency between services, once per pair, with the
+// variables behind it sorted
+func graphEdges(project *schema.Project) []graphEdge {
+	known := make(map[string]bool, len(project.Services))
+	for _, service := range project.Services {
+		known[service.Name] = true
+	}
+
+	var edges []graphEdge
+	for _, service := range project.Services {
+		variables := make(map[string][]string) // by the service they reference
+		for _, dependency := range service.Dependencies {
+			if known[dependency] && dependency != service.Name {
+				variables[dependency] = variables[dependency] // an edge even without variables
+			}
+		}
+		for name, variable := range service.Environment {
+			if ref := variable.Reference; ref != nil && known[ref.Service] && ref.Service != service.Name {
+				variables[ref.Service] = append(variables[ref.Service], name)
+			}
+		}
+		for _, to := range slices.Sorted(maps.Keys(variables)) {
+			slices.Sort(variables[to])
+			edges = append(edges, graphEdge{from: service.Name, to: to, variables: variables[to]})
+		}
+	}
+	return edges
+}
+
+var mermaidUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// mermaidIDs gives every service a node ID Mermaid accepts, since service names may
+// hold dashes and dots Mermaid would read as syntax
+func mermaidIDs(project *schema.Project) map[string]string {
+	ids := make(map[string]string, len(project.Services))
+	taken := make(map[string]bool)
+	for _, service := range project.Services {
+		base := "svc_" + mermaidUnsafe.ReplaceAllString(service.Name, "_")
+		id := base
+		for n := 2; taken[id]; n++ {
+			id = fmt.Sprintf("%s_%d", base, n)
+		}
+		taken[id] = true
+		ids[service.Name] = id
+	}
+	return ids
+}
+
+// mermaidText escapes a label's quotes, which would end it early
+func mermaidText(text string) string {
+	return strings.ReplaceAll(text, `"`, "#quot;")
+}
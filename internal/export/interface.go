@@ -10,3 +10,17 @@ type Exporter interface {
 	// Name returns the exporter name (e.g., "railway", "json", "kubernetes")
 	Name() string
 }
+
+// File is a file an exporter generates, relative to the output directory
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// FileExporter is implemented by exporters whose output spans several files
+type FileExporter interface {
+	Exporter
+
+	// ExportFiles converts a project to the files of the target format
+	ExportFiles(project *schema.Project) ([]File, error)
+}
@@ -0,0 +1,200 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+// RailwayExporter generates Railway config-as-code: a railway.json per service and a
+// project-level document mapping services to their source and config file
+type RailwayExporter struct{}
+
+func NewRailwayExporter() FileExporter {
+	return &RailwayExporter{}
+}
+
+func (e *RailwayExporter) Name() string {
+	return "railway"
+}
+
+// RailwayConfig is the railway.json config-as-code schema
+type RailwayConfig struct {
+	Schema string         `json:"$schema"`
+	Build  *RailwayBuild  `json:"build,omitempty"`
+	Deploy *RailwayDeploy `json:"deploy,omitempty"`
+}
+
+type RailwayBuild struct {
+	Builder        string `json:"builder"`
+	DockerfilePath string `json:"dockerfilePath,omitempty"`
+}
+
+type RailwayDeploy struct {
+	StartCommand      string   `json:"startCommand,omitempty"`
+	PreDeployCommand  []string `json:"preDeployCommand,omitempty"`
+	HealthcheckPath   string   `json:"healthcheckPath,omitempty"`
+	CronSchedule      string   `json:"cronSchedule,omitempty"`
+	Region            string   `json:"region,omitempty"`
+	NumReplicas       int      `json:"numReplicas,omitempty"`
+	RestartPolicyType string   `json:"restartPolicyType,omitempty"`
+}
+
+// RailwayProject maps each service to what Railway needs beyond its railway.json
+type RailwayProject struct {
+	Name     string                  `json:"name"`
+	Services []RailwayProjectService `json:"services"`
+
+	// Who could reach whom on the old platform. Railway's private network lets every
+	// service in a project reach every other, so this records what isolation is lost.
+	PrivateNetwork []schema.Link `json:"privateNetwork,omitempty"`
+}
+
+type RailwayProjectService struct {
+	Name          string          `json:"name"`
+	RootDirectory string          `json:"rootDirectory,omitempty"`
+	Image         string          `json:"image,omitempty"`
+	ConfigFile    string          `json:"configFile,omitempty"`
+	PublicDomain  bool            `json:"publicDomain"`
+	Port          int             `json:"port,omitempty"`
+	Volumes       []schema.Volume `json:"volumes,omitempty"`
+	Managed       *schema.Managed `json:"managed,omitempty"`
+
+	// How Railpack should build the source, which it otherwise detects itself
+	PackageManager string `json:"packageManager,omitempty"`
+	Static         bool   `json:"static,omitempty"`
+	OutputDir      string `json:"outputDir,omitempty"`
+}
+
+const (
+	railwaySchema      = "https://railway.com/railway.schema.json"
+	railwayProjectFile = "railway.project.json"
+)
+
+// Export returns the project-level mapping document
+func (e *RailwayExporter) Export(project *schema.Project) ([]byte, error) {
+	return json.MarshalIndent(e.project(project), "", "  ")
+}
+
+// ExportFiles returns every service's railway.json and the mapping document
+func (e *RailwayExporter) ExportFiles(project *schema.Project) ([]File, error) {
+	var files []File
+	mapping := e.project(project)
+	for i, service := range project.Services {
+		configFile := mapping.Services[i].ConfigFile
+		if configFile == "" {
+			continue
+		}
+		if !filepath.IsLocal(filepath.FromSlash(configFile)) {
+			return nil, fmt.Errorf("service %s: config file %s is outside the export", service.Name, configFile)
+		}
+		content, err := json.MarshalIndent(NewRailwayConfig(service), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", service.Name, err)
+		}
+		files = append(files, File{Path: configFile, Content: append(content, '\n')})
+	}
+
+	content, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(files, File{Path: railwayProjectFile, Content: append(content, '\n')}), nil
+}
+
+func (e *RailwayExporter) project(project *schema.Project) RailwayProject {
+	// railway.json lives in the service's root directory unless services share one, or
+	// the directory is outside the repository
+	sourcePaths := make(map[string]int)
+	for _, service := range project.Services {
+		if service.Image == "" {
+			sourcePaths[service.SourcePath]++
+		}
+	}
+
+	mapping := RailwayProject{Name: project.Name, Services: make([]RailwayProjectService, 0, len(project.Services)), PrivateNetwork: project.Topology}
+	for _, service := range project.Services {
+		entry := RailwayProjectService{
+			Name:          service.Name,
+			RootDirectory: service.SourcePath,
+			Image:         service.Image,
+			Volumes:       service.Volumes,
+			Managed:       service.Managed,
+		}
+		if service.Image == "" && service.Dockerfile == "" {
+			entry.PackageManager, entry.Static, entry.OutputDir = service.PackageManager, service.Static, service.OutputDir
+		}
+		for _, port := range service.Ports {
+			entry.PublicDomain = entry.PublicDomain || port.IsPublic
+			if entry.Port == 0 {
+				entry.Port = port.Number
+			}
+		}
+
+		switch {
+		case service.Image != "" && NewRailwayConfig(service).Deploy == nil:
+			// Image services without deploy settings need no config file
+		case service.Image == "" && sourcePaths[service.SourcePath] == 1 && filepath.IsLocal(filepath.FromSlash(path.Join(service.SourcePath, "railway.json"))):
+			entry.ConfigFile = path.Join(service.SourcePath, "railway.json")
+		default:
+			entry.ConfigFile = path.Join("railway", configFileName(service.Name)+".json")
+		}
+
+		mapping.Services = append(mapping.Services, entry)
+	}
+	return mapping
+}
+
+// NewRailwayConfig builds the railway.json for a service
+func NewRailwayConfig(service schema.Service) RailwayConfig {
+	config := RailwayConfig{Schema: railwaySchema}
+
+	if service.Image == "" {
+		config.Build = &RailwayBuild{Builder: "RAILPACK"}
+		if service.Dockerfile != "" {
+			config.Build.Builder = "DOCKERFILE"
+			// Railway resolves the Dockerfile against the service's root directory
+			dockerfile := service.Dockerfile
+			if rel, ok := strings.CutPrefix(dockerfile, path.Clean(service.SourcePath)+"/"); ok && service.SourcePath != "." {
+				dockerfile = rel
+			}
+			if dockerfile != "Dockerfile" {
+				config.Build.DockerfilePath = dockerfile
+			}
+		}
+	}
+
+	if service.StartCommand == "" && service.PreDeployCommand == "" && service.HealthcheckPath == "" &&
+		service.Schedule == "" && service.Region == "" && service.Replicas == 0 {
+		return config
+	}
+
+	config.Deploy = &RailwayDeploy{
+		StartCommand:    service.StartCommand,
+		HealthcheckPath: service.HealthcheckPath,
+		CronSchedule:    service.Schedule,
+		Region:          service.Region,
+		NumReplicas:     service.Replicas,
+	}
+	if service.PreDeployCommand != "" {
+		config.Deploy.PreDeployCommand = []string{service.PreDeployCommand}
+	}
+	if service.Schedule != "" {
+		config.Deploy.RestartPolicyType = "NEVER" // Cron runs must exit rather than be restarted
+	}
+	return config
+}
+
+// configFileName makes a service name safe to use as a filename
+func configFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' {
+			return '-'
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,241 @@
+package export
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+// TerraformExporter generates HCL for the community Railway Terraform provider: a
+// railway_project, a railway_service per service with its variables and domain,
+// and the railway.json files the services' deploy settings live in
+type TerraformExporter struct {
+	repo string // "owner/name" GitHub repository services built from source deploy from
+}
+
+func NewTerraformExporter(repo string) FileExporter {
+	return &TerraformExporter{repo: repo}
+}
+
+func (e *TerraformExporter) Name() string {
+	return "terraform"
+}
+
+const terraformProvider = "terraform-community-providers/railway"
+
+// Export returns main.tf
+func (e *TerraformExporter) Export(project *schema.Project) ([]byte, error) {
+	main, _ := e.render(project)
+	return main, nil
+}
+
+// ExportFiles returns main.tf, variables.tf and the services' railway.json files
+func (e *TerraformExporter) ExportFiles(project *schema.Project) ([]File, error) {
+	main, variables := e.render(project)
+	files := []File{{Path: "main.tf", Content: main}, {Path: "variables.tf", Content: variables}}
+
+	configs, err := NewRailwayExporter().ExportFiles(project)
+	if err != nil {
+		return nil, err
+	}
+	for _, config := range configs {
+		if config.Path != railwayProjectFile {
+			files = append(files, config)
+		}
+	}
+	return files, nil
+}
+
+func (e *TerraformExporter) render(project *schema.Project) (main, variables []byte) {
+	var hcl, vars bytes.Buffer
+
+	fmt.Fprintf(&hcl, "terraform {\n  required_providers {\n    railway = {\n      source = %s\n    }\n  }\n}\n\n", hclString(terraformProvider))
+	fmt.Fprintf(&hcl, "provider \"railway\" {}\n")
+	writeHCLBlock(&hcl, `resource "railway_project" "project"`, []hclAttribute{{"name", hclString(project.Name)}})
+
+	sourceRepo := []hclAttribute{
+		{"description", hclString("GitHub repository (owner/name) services built from source deploy from")},
+		{"type", "string"},
+	}
+	if e.repo != "" {
+		sourceRepo = append(sourceRepo, hclAttribute{"default", hclString(e.repo)})
+	}
+	writeHCLBlock(&vars, `variable "source_repo"`, sourceRepo)
+
+	mapping := NewRailwayExporter().(*RailwayExporter).project(project)
+	identifiers := make(map[string]bool)
+	// Variable IDs join the service's and the variable's, so web's DB_URL and web_db's
+	// URL would share one
+	variableIDs := map[string]bool{"source_repo": true}
+	for i, service := range project.Services {
+		id := uniqueIdentifier(terraformIdentifier(service.Name), identifiers)
+		entry := mapping.Services[i]
+
+		attributes := []hclAttribute{
+			{"name", hclString(service.Name)},
+			{"project_id", "railway_project.project.id"},
+		}
+		if service.Image != "" {
+			attributes = append(attributes, hclAttribute{"source_image", hclString(service.Image)})
+		} else {
+			attributes = append(attributes, hclAttribute{"source_repo", "var.source_repo"})
+			if service.SourcePath != "." && service.SourcePath != "" {
+				attributes = append(attributes, hclAttribute{"root_directory", hclString(service.SourcePath)})
+			}
+			if entry.ConfigFile != "" {
+				// Config-as-code paths are absolute from the repository root
+				attributes = append(attributes, hclAttribute{"config_path", hclString("/" + entry.ConfigFile)})
+			}
+		}
+		if service.Schedule != "" {
+			attributes = append(attributes, hclAttribute{"cron_schedule", hclString(service.Schedule)})
+		}
+		if len(service.Volumes) > 0 {
+			// Railway services mount at most one volume
+			volume := service.Volumes[0]
+			name := volume.Name
+			if name == "" {
+				name = service.Name + "-" + strings.Trim(strings.ReplaceAll(volume.MountPath, "/", "-"), "-")
+			}
+			attributes = append(attributes, hclAttribute{"volume", fmt.Sprintf("{\n    name       = %s\n    mount_path = %s\n  }", hclString(name), hclString(volume.MountPath))})
+		}
+		// The provider has no build settings, so say what Railpack has to detect
+		header := `resource "railway_service" ` + hclString(id)
+		if entry.Static {
+			header = "# Static site, served from " + cmp.Or(entry.OutputDir, "its build output") + "\n" + header
+		}
+		if entry.PackageManager != "" {
+			header = "# Built with " + entry.PackageManager + "\n" + header
+		}
+		writeHCLBlock(&hcl, header, attributes)
+
+		if entry.PublicDomain {
+			writeHCLBlock(&hcl, `resource "railway_service_domain" `+hclString(id), []hclAttribute{
+				{"subdomain", hclString(terraformSubdomain(project.Name, service.Name))},
+				{"environment_id", "railway_project.project.default_environment.id"},
+				{"service_id", "railway_service." + id + ".id"},
+			})
+		}
+
+		// Secrets and variables without a value become input variables rather than being
+		// committed, unless they reference another service
+		for _, name := range slices.Sorted(maps.Keys(service.Environment)) {
+			envVar := service.Environment[name]
+			variableID := uniqueIdentifier(terraformIdentifier(id+"_"+strings.ToLower(name)), variableIDs)
+			value := hclString(envVar.RailwayValue())
+			if envVar.Reference == nil && (envVar.Sensitive || envVar.Value == "") {
+				value = "var." + variableID
+				description := name + " for " + service.Name
+				if envVar.BuildOnly {
+					description = name + " build arg for " + service.Name
+				}
+				variable := []hclAttribute{
+					{"description", hclString(description)},
+					{"type", "string"},
+				}
+				if envVar.Sensitive {
+					variable = append(variable, hclAttribute{"sensitive", "true"})
+				}
+				writeHCLBlock(&vars, "variable "+hclString(variableID), variable)
+			}
+
+			writeHCLBlock(&hcl, `resource "railway_variable" `+hclString(variableID), []hclAttribute{
+				{"name", hclString(name)},
+				{"value", value},
+				{"environment_id", "railway_project.project.default_environment.id"},
+				{"service_id", "railway_service." + id + ".id"},
+			})
+		}
+	}
+
+	return hcl.Bytes(), vars.Bytes()
+}
+
+type hclAttribute struct {
+	name, value string
+}
+
+// writeHCLBlock writes a block with its attributes aligned like `terraform fmt`,
+// separated from what came before by a blank line
+func writeHCLBlock(buf *bytes.Buffer, header string, attributes []hclAttribute) {
+	if buf.Len() > 0 {
+		buf.WriteByte('\n')
+	}
+	width := 0
+	for _, attribute := range attributes {
+		width = max(width, len(attribute.name))
+	}
+	fmt.Fprintf(buf, "%s {\n", header)
+	for _, attribute := range attributes {
+		fmt.Fprintf(buf, "  %-*s = %s\n", width, attribute.name, attribute.value)
+	}
+	buf.WriteString("}\n")
+}
+
+// hclString quotes a string as an HCL literal, escaping interpolation sequences
+func hclString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '$', '%':
+			// ${ and %{ start templates; doubling the first character escapes them
+			if i+1 < len(s) && s[i+1] == '{' {
+				b.WriteByte(c)
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// terraformIdentifier makes a name safe to use as a resource or variable name
+func terraformIdentifier(name string) string {
+	identifier := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, name)
+	if identifier == "" || identifier[0] >= '0' && identifier[0] <= '9' || identifier[0] == '-' {
+		identifier = "_" + identifier
+	}
+	return identifier
+}
+
+func uniqueIdentifier(identifier string, taken map[string]bool) string {
+	unique := identifier
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", identifier, i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// terraformSubdomain picks a *.up.railway.app subdomain for a public service
+func terraformSubdomain(project, service string) string {
+	subdomain := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(project+"-"+service))
+	return strings.Trim(subdomain, "-")
+}
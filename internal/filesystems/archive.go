@@ -0,0 +1,556 @@
+package filesystems
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// archiveFS implements FileSystem over a repository zip archive that's downloaded
+// on first use, like GitHub zipballs and Bitbucket archives
+type archiveFS struct {
+	slashPaths
+	ctx        context.Context
+	name       string // names the temp file the archive downloads to
+	basePath   string
+	repoPrefix string
+	initErr    error
+
+	// download writes the archive to w
+	download func(ctx context.Context, w io.Writer) error
+
+	zipReader *zip.ReadCloser
+	pathIndex map[string][]string
+	pathMeta  map[string]pathMeta // from the zip's central directory, keyed like pathIndex
+
+	onDownload func(bytes int64)
+
+	once sync.Once
+}
+
+func newArchiveFS(name, basePath string, download func(ctx context.Context, w io.Writer) error) *archiveFS {
+	return &archiveFS{
+		ctx:       context.Background(),
+		name:      name,
+		basePath:  basePath,
+		download:  download,
+		pathIndex: make(map[string][]string),
+		pathMeta:  make(map[string]pathMeta),
+	}
+}
+
+// setContext makes the download use ctx, so cancelling it stops the download
+func (afs *archiveFS) setContext(ctx context.Context) {
+	afs.ctx = ctx
+}
+
+// ensureInitialized downloads the repository archive once and indexes it
+func (afs *archiveFS) ensureInitialized() error {
+	afs.once.Do(func() {
+		afs.initErr = afs.downloadAndIndex()
+	})
+	return afs.initErr
+}
+
+// downloadAndIndex downloads the repository archive and indexes its contents
+func (afs *archiveFS) downloadAndIndex() error {
+	// Create temporary file for zip
+	tempFile, err := os.CreateTemp("", fmt.Sprintf("turnout-%s-*.zip", afs.name))
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	var w io.Writer = tempFile
+	if afs.onDownload != nil {
+		w = &countingWriter{writer: tempFile, progress: afs.onDownload}
+	}
+	if err := afs.download(afs.ctx, w); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to download repository: %w", err)
+	}
+
+	// Close the temp file
+	tempFile.Close()
+
+	// Open zip reader
+	zipReader, err := zip.OpenReader(tempFile.Name())
+	if err != nil {
+		return fmt.Errorf("failed to open zip: %w", err)
+	}
+	afs.zipReader = zipReader
+
+	// Find the repo prefix and build lightweight path index
+	afs.findRepoPrefix()
+	afs.buildPathIndex()
+
+	return nil
+}
+
+// OnDownload reports progress while the repository archive downloads
+func (afs *archiveFS) OnDownload(progress func(bytes int64)) {
+	afs.onDownload = progress
+}
+
+// countingWriter reports the running total of bytes written
+type countingWriter struct {
+	writer   io.Writer
+	total    int64
+	progress func(bytes int64)
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	if n > 0 {
+		w.total += int64(n)
+		w.progress(w.total)
+	}
+	return n, err
+}
+
+// downloadArchive downloads an archive over HTTP, returning the response headers.
+// Conditional requests return errNotModified when the archive hasn't changed.
+// safeURL is used in errors so credentials in the request URL aren't leaked.
+func downloadArchive(client *http.Client, req *http.Request, safeURL string, w io.Writer) (http.Header, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, networkError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return resp.Header, errNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp.Header, fmt.Errorf("failed to download archive: %w for %s", &StatusError{resp.StatusCode}, safeURL)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return resp.Header, err
+}
+
+// findRepoPrefix determines the top-level directory hosts wrap archive entries in
+func (afs *archiveFS) findRepoPrefix() {
+	for _, f := range afs.zipReader.File {
+		if f.FileInfo().IsDir() {
+			parts := strings.Split(strings.Trim(f.Name, "/"), "/")
+			if len(parts) > 0 {
+				afs.repoPrefix = parts[0] + "/"
+				break
+			}
+		}
+	}
+}
+
+// buildPathIndex creates minimal directory index (just strings, not zip entries)
+func (afs *archiveFS) buildPathIndex() {
+	for _, f := range afs.zipReader.File {
+		// Remove repo prefix to get clean path
+		cleanPath := strings.TrimPrefix(f.Name, afs.repoPrefix)
+		cleanPath = strings.Trim(cleanPath, "/")
+
+		if cleanPath == "" {
+			continue // Skip root
+		}
+
+		// Convert to forward slashes for consistency
+		cleanPath = filepath.ToSlash(cleanPath)
+
+		// Get parent directory and child name
+		parentDir := path.Dir(cleanPath)
+		if parentDir == "." {
+			parentDir = ""
+		}
+		childName := path.Base(cleanPath)
+
+		afs.pathMeta[cleanPath] = pathMeta{
+			size:    int64(f.UncompressedSize64),
+			modTime: f.Modified,
+			isDir:   f.FileInfo().IsDir(),
+		}
+
+		// Add child name to parent's list if not already present (just strings)
+		children := afs.pathIndex[parentDir]
+		found := false
+		for _, existing := range children {
+			if existing == childName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			afs.pathIndex[parentDir] = append(children, childName)
+		}
+	}
+}
+
+// comment returns the archive's comment, which hosts like GitHub set to the commit SHA
+func (afs *archiveFS) comment() (string, error) {
+	if err := afs.ensureInitialized(); err != nil {
+		return "", err
+	}
+	return afs.zipReader.Comment, nil
+}
+
+// Cleanup closes the downloaded archive
+func (afs *archiveFS) Cleanup() error {
+	if afs.zipReader != nil {
+		return afs.zipReader.Close()
+	}
+	return nil
+}
+
+// validatePath ensures the path is safe and within bounds
+func validatePath(path string) error {
+	// Clean and normalize path
+	path = strings.TrimPrefix(path, "/")
+	path = filepath.Clean(path)
+
+	// Reject dangerous paths
+	if strings.Contains(path, "..") {
+		return fmt.Errorf("path traversal detected: %s", path)
+	}
+	if filepath.IsAbs(path) {
+		return fmt.Errorf("absolute path not allowed: %s", path)
+	}
+	if strings.HasPrefix(path, "../") || path == ".." {
+		return fmt.Errorf("parent directory access not allowed: %s", path)
+	}
+
+	return nil
+}
+
+func (afs *archiveFS) ReadFile(name string) ([]byte, error) {
+	file, err := afs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+func (afs *archiveFS) Open(name string) (io.ReadCloser, error) {
+	if err := afs.ensureInitialized(); err != nil {
+		return nil, err
+	}
+
+	// Validate and resolve path
+	if err := validatePath(name); err != nil {
+		return nil, err
+	}
+
+	name = afs.resolvePath(name)
+
+	// Use zip reader's built-in Open method (has internal indexing)
+	targetPath := afs.repoPrefix + name
+	file, err := afs.zipReader.Open(targetPath)
+	if err != nil {
+		return nil, fmt.Errorf("file %w: %s", ErrNotFound, name)
+	}
+	return file, nil
+}
+
+func (afs *archiveFS) ReadDir(name string) iter.Seq2[DirEntry, error] {
+	return func(yield func(DirEntry, error) bool) {
+		if err := afs.ensureInitialized(); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// Validate and resolve path
+		if err := validatePath(name); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		name = afs.resolvePath(name)
+
+		// Handle root directory special case
+		if name == "." {
+			name = ""
+		}
+
+		// Get children from minimal path index (just strings)
+		children, exists := afs.pathIndex[name]
+		if !exists {
+			yield(nil, fmt.Errorf("directory %w: %s", ErrNotFound, name))
+			return
+		}
+
+		// Yield lightweight DirEntry objects without allocating slice
+		for _, childName := range children {
+			childPath := name
+			if childPath != "" {
+				childPath += "/"
+			}
+			childPath += childName
+
+			// Empty directories have no children, but still have a directory entry
+			_, isDir := afs.pathIndex[childPath]
+			meta := afs.pathMeta[childPath]
+
+			entry := &lightweightDirEntry{
+				name:       childName,
+				isDir:      isDir || meta.isDir,
+				parentPath: name,
+				size:       meta.size,
+				modTime:    meta.modTime,
+			}
+			if !yield(entry, nil) {
+				return // Consumer stopped iteration
+			}
+		}
+	}
+}
+
+// pathMeta is what the path index keeps about an archive entry
+type pathMeta struct {
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+// lightweightDirEntry implements DirEntry without holding zip.File references
+type lightweightDirEntry struct {
+	name       string
+	parentPath string
+	isDir      bool
+	size       int64
+	modTime    time.Time
+}
+
+func (e *lightweightDirEntry) Name() string {
+	return e.name
+}
+
+func (e *lightweightDirEntry) IsDir() bool {
+	return e.isDir
+}
+
+func (e *lightweightDirEntry) Type() fs.FileMode {
+	if e.IsDir() {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+func (e *lightweightDirEntry) Info() (FileInfo, error) {
+	return &lightweightFileInfo{
+		name:    e.name,
+		isDir:   e.isDir,
+		size:    e.size,
+		modTime: e.modTime,
+	}, nil
+}
+
+// lightweightFileInfo implements FileInfo without zip.File references
+type lightweightFileInfo struct {
+	name    string
+	isDir   bool
+	size    int64
+	modTime time.Time
+}
+
+func (fi *lightweightFileInfo) Name() string { return fi.name }
+func (fi *lightweightFileInfo) Size() int64  { return fi.size }
+func (fi *lightweightFileInfo) Mode() fs.FileMode {
+	if fi.isDir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+func (fi *lightweightFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *lightweightFileInfo) IsDir() bool        { return fi.isDir }
+func (fi *lightweightFileInfo) Sys() interface{}   { return nil }
+
+// zipFileInfo wraps zip.File to implement FileInfo
+type zipFileInfo struct {
+	*zip.File
+	name  string
+	isDir bool
+}
+
+func (fi *zipFileInfo) Name() string {
+	if fi.name != "" {
+		return fi.name
+	}
+	if fi.File != nil {
+		return filepath.Base(fi.File.Name)
+	}
+	return ""
+}
+
+func (fi *zipFileInfo) Size() int64 {
+	if fi.File != nil {
+		return int64(fi.File.UncompressedSize64)
+	}
+	return 0
+}
+
+func (fi *zipFileInfo) Mode() fs.FileMode {
+	if fi.isDir {
+		return fs.ModeDir | 0755
+	}
+	if fi.File != nil {
+		return fi.File.FileInfo().Mode()
+	}
+	return 0644
+}
+
+func (fi *zipFileInfo) ModTime() time.Time {
+	if fi.File != nil {
+		return fi.File.FileInfo().ModTime()
+	}
+	return time.Time{}
+}
+
+func (fi *zipFileInfo) IsDir() bool {
+	return fi.isDir
+}
+
+func (fi *zipFileInfo) Sys() interface{} {
+	return fi.File
+}
+
+func (afs *archiveFS) resolvePath(path string) string {
+	return resolveBasePath(afs.basePath, path)
+}
+
+// resolveBasePath resolves a path relative to a repository subdirectory
+func resolveBasePath(basePath, path string) string {
+	// Clean the path - remove leading slash if present
+	path = strings.TrimPrefix(path, "/")
+
+	// If we have a basePath, prepend it to the path
+	if basePath != "" {
+		if path == "" || path == "." {
+			return basePath
+		}
+		return basePath + "/" + path
+	}
+
+	return path
+}
+
+func (afs *archiveFS) Walk(root string, fn WalkFunc) error {
+	if err := afs.ensureInitialized(); err != nil {
+		return err
+	}
+
+	// Create root directory info
+	rootInfo := &lightweightFileInfo{name: root, isDir: true}
+
+	return walkReadDir(afs, root, rootInfo, fn, 0, 10)
+}
+
+func (afs *archiveFS) Glob(pattern string) ([]string, error) {
+	if err := afs.ensureInitialized(); err != nil {
+		return nil, err
+	}
+	return globReadDir(afs, pattern)
+}
+
+// Exists looks a path up in the index, without reading the archive
+func (afs *archiveFS) Exists(name string) bool {
+	if afs.ensureInitialized() != nil || validatePath(name) != nil {
+		return false
+	}
+	name = afs.resolvePath(name)
+	if name == "." || name == "" {
+		return true
+	}
+	_, isEntry := afs.pathMeta[name]
+	_, isDir := afs.pathIndex[name] // Parent directories without their own entry
+	return isEntry || isDir
+}
+
+// walkReadDir walks a filesystem with ReadDir, for filesystems without a native walk
+func walkReadDir(filesystem FileSystem, dir string, info FileInfo, fn WalkFunc, depth, maxDepth int) error {
+	if depth > maxDepth {
+		return nil
+	}
+
+	// Call fn for the directory itself
+	if err := fn(dir, info, nil); err != nil {
+		if err == SkipDir && info.IsDir() {
+			return nil
+		}
+		return err
+	}
+
+	// If it's not a directory, we're done
+	if !info.IsDir() {
+		return nil
+	}
+
+	// Process directory contents using iterator
+	for entry, err := range filesystem.ReadDir(dir) {
+		if err != nil {
+			return fn(dir, info, err)
+		}
+		entryPath := filesystem.Join(dir, entry.Name())
+		entryInfo, err := entry.Info()
+		if err != nil {
+			if err := fn(entryPath, nil, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fn(entryPath, entryInfo, nil); err != nil {
+			if err == SkipDir && entry.IsDir() {
+				continue
+			}
+			return err
+		}
+
+		// Recurse into subdirectories using the info we already have
+		if entry.IsDir() {
+			if err := walkReadDir(filesystem, entryPath, entryInfo, fn, depth+1, maxDepth); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// slashPaths implements FileSystem's path methods for forward slash paths, as
+// used inside archives and by hosting APIs
+type slashPaths struct{}
+
+func (slashPaths) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (slashPaths) Base(p string) string {
+	return path.Base(p)
+}
+
+func (slashPaths) Dir(p string) string {
+	return path.Dir(p)
+}
+
+func (slashPaths) Rel(basepath, targpath string) (string, error) {
+	// Simple implementation for URL paths
+	if strings.HasPrefix(targpath, basepath) {
+		rel := strings.TrimPrefix(targpath, basepath)
+		rel = strings.TrimPrefix(rel, "/")
+		if rel == "" {
+			return ".", nil
+		}
+		return rel, nil
+	}
+	return targpath, nil
+}
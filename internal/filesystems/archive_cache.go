@@ -0,0 +1,83 @@
+package filesystems
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// errNotModified is returned by downloadArchive when a conditional request's archive hasn't changed
+var errNotModified = errors.New("archive not modified")
+
+// DefaultCacheDir is where downloaded archives are cached, e.g. ~/.cache/turnout on
+// Linux. It's empty, disabling the cache, when TURNOUT_NO_CACHE is set or the
+// platform has no cache directory.
+func DefaultCacheDir() string {
+	if os.Getenv("TURNOUT_NO_CACHE") != "" {
+		return ""
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "turnout")
+}
+
+// archiveCache keeps a downloaded archive with the ETag it was served with, so later
+// downloads only transfer the archive when it has changed
+type archiveCache struct {
+	path string // the cached archive; its ETag is stored next to it
+}
+
+func (c archiveCache) etagPath() string {
+	return c.path + ".etag"
+}
+
+// download performs an archive request, revalidating the cached archive with
+// If-None-Match and serving it from disk when the server says it hasn't changed
+func (c archiveCache) download(client *http.Client, req *http.Request, safeURL string, w io.Writer) (http.Header, error) {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return downloadArchive(client, req, safeURL, w) // Uncacheable, but still downloadable
+	}
+
+	if etag, err := os.ReadFile(c.etagPath()); err == nil {
+		if _, err := os.Stat(c.path); err == nil {
+			req.Header.Set("If-None-Match", strings.TrimSpace(string(etag)))
+		}
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return downloadArchive(client, req, safeURL, w)
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	header, err := downloadArchive(client, req, safeURL, io.MultiWriter(w, temp))
+	if errors.Is(err, errNotModified) {
+		cached, err := os.Open(c.path)
+		if err != nil {
+			return header, err
+		}
+		defer cached.Close()
+		_, err = io.Copy(w, cached)
+		return header, err
+	}
+	if err != nil {
+		return header, err
+	}
+
+	// Caching is best effort; the download already succeeded
+	if temp.Close() != nil || os.Rename(temp.Name(), c.path) != nil {
+		return header, nil
+	}
+	if etag := header.Get("ETag"); etag != "" {
+		_ = os.WriteFile(c.etagPath(), []byte(etag), 0o644)
+	} else {
+		_ = os.Remove(c.etagPath())
+	}
+	return header, nil
+}
@@ -0,0 +1,154 @@
+package filesystems
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// Stdin is the source path that reads a tarball from standard input
+const Stdin = "-"
+
+var archiveExtensions = []string{".tar.gz", ".tgz", ".zip"}
+
+// IsArchive reports whether a source path is a local archive or stdin
+func IsArchive(sourcePath string) bool {
+	return sourcePath == Stdin || TrimArchiveExt(sourcePath) != sourcePath
+}
+
+// TrimArchiveExt removes a recognized archive extension from a path
+func TrimArchiveExt(sourcePath string) string {
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(strings.ToLower(sourcePath), ext) {
+			return sourcePath[:len(sourcePath)-len(ext)]
+		}
+	}
+	return sourcePath
+}
+
+// ArchiveFS implements a read-only FileSystem over a local .zip, .tar.gz or .tgz
+// archive, read into memory and rooted at the archive's root
+type ArchiveFS struct {
+	*MemoryFS
+}
+
+// NewArchiveFS reads the archive at path, or a gzipped tarball from stdin for "-"
+func NewArchiveFS(path string) (*ArchiveFS, error) {
+	if path == Stdin {
+		return ReadTarball(os.Stdin)
+	}
+
+	if strings.HasSuffix(strings.ToLower(path), ".zip") {
+		reader, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open zip: %w", err)
+		}
+		defer reader.Close()
+		return readZip(&reader.Reader)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadTarball(file)
+}
+
+// ReadTarball reads a gzipped tarball
+func ReadTarball(r io.Reader) (*ArchiveFS, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tarball: %w", err)
+	}
+	defer gz.Close()
+
+	var entries []archiveEntry
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tarball: %w", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			entries = append(entries, archiveEntry{name: header.Name, isDir: true})
+		case tar.TypeReg:
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+			}
+			entries = append(entries, archiveEntry{name: header.Name, content: content})
+		}
+	}
+	return newArchiveFSFromEntries(entries), nil
+}
+
+// ReadZip reads a zip archive
+func ReadZip(r io.ReaderAt, size int64) (*ArchiveFS, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip: %w", err)
+	}
+	return readZip(reader)
+}
+
+func readZip(reader *zip.Reader) (*ArchiveFS, error) {
+	var entries []archiveEntry
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			entries = append(entries, archiveEntry{name: file.Name, isDir: true})
+			continue
+		}
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		var content bytes.Buffer
+		_, err = io.Copy(&content, rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		entries = append(entries, archiveEntry{name: file.Name, content: content.Bytes()})
+	}
+	return newArchiveFSFromEntries(entries), nil
+}
+
+type archiveEntry struct {
+	name    string
+	content []byte
+	isDir   bool
+}
+
+func newArchiveFSFromEntries(entries []archiveEntry) *ArchiveFS {
+	mfs := NewMemoryFS()
+	for _, entry := range entries {
+		// Clean names, dropping ./ prefixes and anything escaping the archive
+		name := path.Clean(strings.TrimPrefix(entry.name, "/"))
+		if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+			continue
+		}
+		if entry.isDir {
+			mfs.AddDir(name)
+		} else {
+			mfs.AddFile(name, entry.content)
+		}
+	}
+	return &ArchiveFS{MemoryFS: mfs}
+}
@@ -0,0 +1,208 @@
+package filesystems
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// AuditRead is a read that reached a filesystem
+type AuditRead struct {
+	Op        string // read, open, readdir, walk, glob or exists
+	Path      string
+	Bytes     int64 // bytes read, or entries listed for readdir and glob
+	Duration  time.Duration
+	Component string // the function that read it, like signals.(*DockerfileSignal).ObserveEntry
+	Err       error
+}
+
+// AuditFS wraps a filesystem, recording every read with its size, time and the
+// component that made it, to debug slow scans and reads of unexpected paths.
+// Wrapped by CachedFS, it records the reads that reach the source.
+type AuditFS struct {
+	FileSystem
+
+	mu    sync.Mutex
+	reads []AuditRead
+}
+
+// NewAuditFS wraps a filesystem with an audit log
+func NewAuditFS(filesystem FileSystem) *AuditFS {
+	return &AuditFS{FileSystem: filesystem}
+}
+
+// Unwrap returns the wrapped filesystem
+func (a *AuditFS) Unwrap() FileSystem {
+	return a.FileSystem
+}
+
+// Reads returns the reads recorded so far, in order
+func (a *AuditFS) Reads() []AuditRead {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.reads)
+}
+
+func (a *AuditFS) record(op, name string, bytes int64, start time.Time, component string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.reads = append(a.reads, AuditRead{Op: op, Path: name, Bytes: bytes, Duration: time.Since(start), Component: component, Err: err})
+}
+
+func (a *AuditFS) ReadFile(name string) ([]byte, error) {
+	start, component := time.Now(), auditComponent()
+	content, err := a.FileSystem.ReadFile(name)
+	a.record("read", name, int64(len(content)), start, component, err)
+	return content, err
+}
+
+// Open records the read when the file is closed, with the bytes read from it
+func (a *AuditFS) Open(name string) (io.ReadCloser, error) {
+	start, component := time.Now(), auditComponent()
+	reader, err := a.FileSystem.Open(name)
+	if err != nil {
+		a.record("open", name, 0, start, component, err)
+		return nil, err
+	}
+	return &auditReader{ReadCloser: reader, done: func(bytes int64, err error) {
+		a.record("open", name, bytes, start, component, err)
+	}}, nil
+}
+
+type auditReader struct {
+	io.ReadCloser
+	bytes int64
+	err   error
+	done  func(int64, error)
+}
+
+func (r *auditReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.bytes += int64(n)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+func (r *auditReader) Close() error {
+	err := r.ReadCloser.Close()
+	if r.done != nil {
+		r.done(r.bytes, r.err)
+		r.done = nil
+	}
+	return err
+}
+
+func (a *AuditFS) ReadDir(name string) iter.Seq2[DirEntry, error] {
+	return func(yield func(DirEntry, error) bool) {
+		start, component := time.Now(), auditComponent()
+		var entries int64
+		var readErr error
+		defer func() { a.record("readdir", name, entries, start, component, readErr) }()
+
+		for entry, err := range a.FileSystem.ReadDir(name) {
+			if err != nil {
+				readErr = err
+			} else {
+				entries++
+			}
+			if !yield(entry, err) {
+				return
+			}
+		}
+	}
+}
+
+// Walk records the walk as a whole, since the wrapped filesystem lists directories
+// itself. Its time includes fn's, whose own reads are recorded separately.
+func (a *AuditFS) Walk(root string, fn WalkFunc) error {
+	start, component := time.Now(), auditComponent()
+	err := a.FileSystem.Walk(root, fn)
+	a.record("walk", root, 0, start, component, err)
+	return err
+}
+
+func (a *AuditFS) Glob(pattern string) ([]string, error) {
+	start, component := time.Now(), auditComponent()
+	matches, err := a.FileSystem.Glob(pattern)
+	a.record("glob", pattern, int64(len(matches)), start, component, err)
+	return matches, err
+}
+
+func (a *AuditFS) Exists(name string) bool {
+	start, component := time.Now(), auditComponent()
+	exists := a.FileSystem.Exists(name)
+	a.record("exists", name, 0, start, component, nil)
+	return exists
+}
+
+// auditComponent names the first function outside this package on the stack,
+// which is the component reading the filesystem
+func auditComponent() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !strings.Contains(frame.Function, "/internal/filesystems.") &&
+			strings.Contains(frame.Function, "/turnout/") {
+			return path.Base(frame.Function)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// WriteReport writes the totals per component, largest first, then every read in order
+func (a *AuditFS) WriteReport(w io.Writer) error {
+	reads := a.Reads()
+
+	type total struct {
+		component string
+		reads     int
+		bytes     int64
+		duration  time.Duration
+	}
+	totals := map[string]*total{}
+	for _, read := range reads {
+		t, ok := totals[read.Component]
+		if !ok {
+			t = &total{component: read.Component}
+			totals[read.Component] = t
+		}
+		t.reads++
+		if read.Op == "read" || read.Op == "open" {
+			t.bytes += read.Bytes
+		}
+		t.duration += read.Duration
+	}
+	sorted := slices.SortedFunc(maps.Values(totals), func(a, b *total) int {
+		return cmp.Or(cmp.Compare(b.bytes, a.bytes), cmp.Compare(a.component, b.component))
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMPONENT\tREADS\tBYTES\tTIME")
+	for _, t := range sorted {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", t.component, t.reads, t.bytes, t.duration.Round(time.Microsecond))
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "OP\tPATH\tBYTES\tTIME\tCOMPONENT\tERROR")
+	for _, read := range reads {
+		var errText string
+		if read.Err != nil {
+			errText = read.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", read.Op, read.Path, read.Bytes, read.Duration.Round(time.Microsecond), read.Component, errText)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,103 @@
+package filesystems
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// BitbucketCredentials authenticate Bitbucket Cloud requests with either an
+// access token or a username and app password
+type BitbucketCredentials struct {
+	Token       string
+	Username    string
+	AppPassword string
+}
+
+func (c BitbucketCredentials) authorize(req *http.Request) {
+	switch {
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case c.Username != "" && c.AppPassword != "":
+		req.SetBasicAuth(c.Username, c.AppPassword)
+	}
+}
+
+// BitbucketFS implements FileSystem using a downloaded Bitbucket Cloud repository archive
+type BitbucketFS struct {
+	*archiveFS
+	workspace   string
+	repo        string
+	ref         string
+	credentials BitbucketCredentials
+}
+
+// NewBitbucketFS creates a new BitbucketFS instance. The repository's main branch
+// is used when ref is empty.
+func NewBitbucketFS(workspace, repo, ref, basePath string, credentials BitbucketCredentials) *BitbucketFS {
+	bfs := &BitbucketFS{
+		workspace:   workspace,
+		repo:        repo,
+		ref:         ref,
+		credentials: credentials,
+	}
+	bfs.archiveFS = newArchiveFS(fmt.Sprintf("bitbucket-%s-%s", workspace, repo), basePath, bfs.downloadArchive)
+	return bfs
+}
+
+// downloadArchive downloads the repository archive for the ref
+func (bfs *BitbucketFS) downloadArchive(ctx context.Context, w io.Writer) error {
+	ref := bfs.ref
+	if ref == "" {
+		var err error
+		if ref, err = bfs.mainBranch(ctx); err != nil {
+			return err
+		}
+	}
+
+	archiveURL := fmt.Sprintf("https://bitbucket.org/%s/%s/get/%s.zip", bfs.workspace, bfs.repo, url.PathEscape(ref))
+	req, err := http.NewRequestWithContext(ctx, "GET", archiveURL, nil)
+	if err != nil {
+		return err
+	}
+	bfs.credentials.authorize(req)
+
+	_, err = downloadArchive(http.DefaultClient, req, archiveURL, w)
+	return err
+}
+
+// mainBranch looks up the repository's main branch
+func (bfs *BitbucketFS) mainBranch(ctx context.Context) (string, error) {
+	apiURL := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/%s", bfs.workspace, bfs.repo)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return "", err
+	}
+	bfs.credentials.authorize(req)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", networkError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to look up repository: %w for %s", &StatusError{resp.StatusCode}, apiURL)
+	}
+
+	var repository struct {
+		MainBranch struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repository); err != nil {
+		return "", err
+	}
+	if repository.MainBranch.Name == "" {
+		return "main", nil
+	}
+	return repository.MainBranch.Name, nil
+}
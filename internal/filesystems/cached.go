@@ -0,0 +1,153 @@
+package filesystems
+
+import (
+	"bytes"
+	"io"
+	"iter"
+	"sync"
+)
+
+// maxCachedFileSize is the largest file CachedFS keeps. Bigger files are usually
+// bundles or data read once by the env walk, not configs signals read repeatedly.
+const maxCachedFileSize = 1 << 20
+
+// CachedFS wraps a filesystem, memoizing file contents, directory listings and
+// existence checks, so files read by several signals and again by the env walk
+// are only read once per run. Call Reset when the underlying files change.
+type CachedFS struct {
+	FileSystem
+
+	mu     sync.Mutex
+	files  map[string]cachedFile
+	dirs   map[string]cachedDir
+	exists map[string]bool
+}
+
+type cachedFile struct {
+	content []byte
+	err     error
+}
+
+type cachedDir struct {
+	entries []DirEntry
+	err     error
+}
+
+// NewCachedFS wraps a filesystem with a cache
+func NewCachedFS(filesystem FileSystem) *CachedFS {
+	c := &CachedFS{FileSystem: filesystem}
+	c.Reset()
+	return c
+}
+
+// Unwrap returns the wrapped filesystem
+func (c *CachedFS) Unwrap() FileSystem {
+	return c.FileSystem
+}
+
+// Reset empties the cache
+func (c *CachedFS) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files = make(map[string]cachedFile)
+	c.dirs = make(map[string]cachedDir)
+	c.exists = make(map[string]bool)
+}
+
+func (c *CachedFS) ReadFile(name string) ([]byte, error) {
+	c.mu.Lock()
+	cached, ok := c.files[name]
+	c.mu.Unlock()
+	if ok {
+		return cached.content, cached.err
+	}
+
+	content, err := c.FileSystem.ReadFile(name)
+	if len(content) <= maxCachedFileSize {
+		c.mu.Lock()
+		c.files[name] = cachedFile{content, err}
+		c.mu.Unlock()
+	}
+	return content, err
+}
+
+// Open serves cached files from memory, and streams the rest without caching them
+func (c *CachedFS) Open(name string) (io.ReadCloser, error) {
+	c.mu.Lock()
+	cached, ok := c.files[name]
+	c.mu.Unlock()
+	if ok {
+		if cached.err != nil {
+			return nil, cached.err
+		}
+		return io.NopCloser(bytes.NewReader(cached.content)), nil
+	}
+	return c.FileSystem.Open(name)
+}
+
+func (c *CachedFS) ReadDir(name string) iter.Seq2[DirEntry, error] {
+	return func(yield func(DirEntry, error) bool) {
+		c.mu.Lock()
+		cached, ok := c.dirs[name]
+		c.mu.Unlock()
+
+		if !ok {
+			// Listings are read in full so they can be replayed, even if the caller stops early
+			for entry, err := range c.FileSystem.ReadDir(name) {
+				if err != nil {
+					cached.err = err
+					break
+				}
+				cached.entries = append(cached.entries, entry)
+			}
+			c.mu.Lock()
+			c.dirs[name] = cached
+			c.mu.Unlock()
+		}
+
+		for _, entry := range cached.entries {
+			if !yield(entry, nil) {
+				return
+			}
+		}
+		if cached.err != nil {
+			yield(nil, cached.err)
+		}
+	}
+}
+
+func (c *CachedFS) Walk(root string, fn WalkFunc) error {
+	return c.FileSystem.Walk(root, fn)
+}
+
+// Glob matches against cached directory listings
+func (c *CachedFS) Glob(pattern string) ([]string, error) {
+	return globReadDir(c, pattern)
+}
+
+func (c *CachedFS) Exists(name string) bool {
+	c.mu.Lock()
+	exists, ok := c.exists[name]
+	c.mu.Unlock()
+	if ok {
+		return exists
+	}
+
+	exists = c.FileSystem.Exists(name)
+	c.mu.Lock()
+	c.exists[name] = exists
+	c.mu.Unlock()
+	return exists
+}
+
+// Unwrap returns the filesystem at the bottom of a stack of wrappers like CachedFS,
+// for type assertions on the filesystem a source was opened with
+func Unwrap(filesystem FileSystem) FileSystem {
+	for {
+		wrapper, ok := filesystem.(interface{ Unwrap() FileSystem })
+		if !ok {
+			return filesystem
+		}
+		filesystem = wrapper.Unwrap()
+	}
+}
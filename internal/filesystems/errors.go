@@ -0,0 +1,86 @@
+package filesystems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+)
+
+// The kinds of failure every filesystem reports, so callers can tell a file that
+// isn't there from a repository they can't read at all. Check for them with errors.Is.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrAuth        = errors.New("authentication failed")
+	ErrRateLimited = errors.New("rate limited")
+	ErrNetwork     = errors.New("network error")
+)
+
+// kindError marks an error as one of the kinds above without changing its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// withKind marks an error as a kind of failure, unless it's already marked as one
+func withKind(kind, err error) error {
+	if err == nil || errors.Is(err, kind) {
+		return err
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// StatusError is an unsuccessful HTTP response from a repository host. It unwraps to
+// the kind of failure its status means.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuth
+	case e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrNetwork
+	}
+	return nil
+}
+
+// notFoundError marks an os or io/fs error for a missing file as ErrNotFound
+func notFoundError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return withKind(ErrNotFound, err)
+	}
+	return err
+}
+
+// networkError marks a failed request as ErrNetwork. Rate limits and cancellations
+// keep their own meaning.
+func networkError(err error) error {
+	if err == nil || errors.Is(err, ErrRateLimited) || errors.Is(err, context.Canceled) {
+		return err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return withKind(ErrNetwork, err)
+	}
+	return err
+}
@@ -1,10 +1,12 @@
 package filesystems
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -13,7 +15,39 @@ import (
 // - file:///path/to/local/dir
 // - github://owner/repo/tree/branch
 // - git://github.com/owner/repo
+// - ssh://git@host/owner/repo and git@host:owner/repo
+// - bitbucket://workspace/repo/src/branch
+// - gitea://host/owner/repo/src/branch/branch (also forgejo:// and gogs://)
+// - path/to/source.zip, .tar.gz or .tgz, or - for a tarball on stdin
+// - s3://bucket/key.tar.gz and gs://bucket/key.zip
 func NewFileSystem(uri string) (FileSystem, error) {
+	return NewFileSystemContext(context.Background(), uri)
+}
+
+// NewFileSystemContext creates a filesystem like NewFileSystem, cloning and
+// downloading the source with ctx, so cancelling it stops them
+func NewFileSystemContext(ctx context.Context, uri string) (FileSystem, error) {
+	filesystem, err := newFileSystem(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	if contextual, ok := filesystem.(interface{ setContext(context.Context) }); ok {
+		contextual.setContext(ctx)
+	}
+	return filesystem, nil
+}
+
+func newFileSystem(ctx context.Context, uri string) (FileSystem, error) {
+	// Local archives are read into memory
+	if IsArchive(uri) && !strings.Contains(uri, "://") {
+		return NewArchiveFS(uri)
+	}
+
+	// scp-like ssh remotes, git@host:owner/repo
+	if isSCPLikeRemote(uri) {
+		return newGitFSFromRemote(ctx, uri)
+	}
+
 	// Handle local paths without scheme
 	if !strings.Contains(uri, "://") {
 		// Convert to absolute path for validation
@@ -37,17 +71,32 @@ func NewFileSystem(uri string) (FileSystem, error) {
 		return parseGitHubURL(parsedURL)
 
 	case "git":
-		return parseGitURL(parsedURL)
+		return parseGitURL(ctx, parsedURL)
+
+	case "ssh", "git+ssh":
+		return newGitFSFromRemote(ctx, strings.TrimPrefix(uri, "git+"))
+
+	case "bitbucket":
+		return parseBitbucketURL(parsedURL)
+
+	case "gitea", "forgejo", "gogs":
+		return parseGitHostingURL(parsedURL)
+
+	case "s3", "gs":
+		return NewObjectStorageFS(ctx, uri)
 
 	default:
 		return nil, fmt.Errorf("unsupported scheme: %s", parsedURL.Scheme)
 	}
 }
 
-// parseGitHubURL parses github://owner/repo/tree/branch URLs
+// parseGitHubURL parses github://owner/repo/tree/branch URLs, and
+// github://host/owner/repo/tree/branch for GitHub Enterprise Server
 func parseGitHubURL(u *url.URL) (FileSystem, error) {
 	// Format: github://owner/repo/tree/branch
 	// Or: github://owner/repo (defaults to main branch)
+	// Or: github://ghe.example.com/owner/repo/tree/branch
+	// Or: github://owner/repo?strategy=tree (lists and reads through the API)
 
 	// The host should be the owner for github:// URLs
 	owner := u.Host
@@ -55,31 +104,98 @@ func parseGitHubURL(u *url.URL) (FileSystem, error) {
 	path := strings.Trim(u.Path, "/")
 	parts := strings.Split(path, "/")
 
+	// GitHub owners can't contain dots, so a dotted host is an Enterprise Server instance
+	host := DefaultGitHubHost()
+	if strings.Contains(u.Host, ".") {
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid GitHub Enterprise URL format, expected: github://host/owner/repo[/tree/branch]")
+		}
+		host = GitHubEnterpriseHost(u.Host)
+		owner, parts = parts[0], parts[1:]
+	}
+
 	if len(parts) < 1 || parts[0] == "" {
 		return nil, fmt.Errorf("invalid GitHub URL format, expected: github://owner/repo[/tree/branch]")
 	}
 
 	repo := parts[0]
 	ref := ""
+	subpath := ""
 
 	// Check if tree/branch is specified
 	if len(parts) >= 3 && parts[1] == "tree" {
 		ref = parts[2]
 		// Check if there's a subpath after the branch
-		if len(parts) > 3 {
-			subpath := strings.Join(parts[3:], "/")
-			return NewGitHubFSWithPath(owner, repo, ref, subpath, os.Getenv("GITHUB_TOKEN")), nil
-		}
+		subpath = strings.Join(parts[3:], "/")
 	}
 
 	// Get GitHub token from environment
 	token := os.Getenv("GITHUB_TOKEN")
 
-	return NewGitHubFS(owner, repo, ref, token), nil
+	// ?strategy=tree reads through the API instead of downloading the whole repository
+	switch strategy := u.Query().Get("strategy"); strategy {
+	case "", "archive":
+		return NewGitHubFSWithHost(host, owner, repo, ref, subpath, token), nil
+	case "tree":
+		return NewGitHubTreeFS(host, owner, repo, ref, subpath, token), nil
+	default:
+		return nil, fmt.Errorf("unknown GitHub strategy %q, expected archive or tree", strategy)
+	}
+}
+
+// parseBitbucketURL parses bitbucket://workspace/repo/src/branch URLs. Private
+// repositories authenticate with BITBUCKET_TOKEN, or BITBUCKET_USERNAME and
+// BITBUCKET_APP_PASSWORD.
+func parseBitbucketURL(u *url.URL) (FileSystem, error) {
+	// Format: bitbucket://workspace/repo/src/branch/subpath
+	// Or: bitbucket://workspace/repo (defaults to the main branch)
+	workspace := u.Host
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if workspace == "" || parts[0] == "" {
+		return nil, fmt.Errorf("invalid Bitbucket URL format, expected: bitbucket://workspace/repo[/src/branch]")
+	}
+
+	repo := parts[0]
+	ref, subpath := "", ""
+	if len(parts) >= 3 && (parts[1] == "src" || parts[1] == "tree") {
+		ref = parts[2]
+		subpath = strings.Join(parts[3:], "/")
+	}
+
+	credentials := BitbucketCredentials{
+		Token:       os.Getenv("BITBUCKET_TOKEN"),
+		Username:    os.Getenv("BITBUCKET_USERNAME"),
+		AppPassword: os.Getenv("BITBUCKET_APP_PASSWORD"),
+	}
+	return NewBitbucketFS(workspace, repo, ref, subpath, credentials), nil
+}
+
+// parseGitHostingURL parses gitea://host/owner/repo/src/branch/branch URLs for
+// self-hosted Gitea, Forgejo and Gogs, served over HTTPS. Private repositories
+// authenticate with GITEA_TOKEN.
+func parseGitHostingURL(u *url.URL) (FileSystem, error) {
+	// Format: gitea://host/owner/repo/src/branch/branch/subpath
+	// Or: gitea://host/owner/repo/tree/branch/subpath
+	// Or: gitea://host/owner/repo (defaults to the default branch)
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if u.Host == "" || len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid %s URL format, expected: %s://host/owner/repo[/src/branch/branch]", u.Scheme, u.Scheme)
+	}
+
+	owner, repo := parts[0], parts[1]
+	ref, subpath := "", ""
+	switch rest := parts[2:]; {
+	case len(rest) >= 3 && rest[0] == "src" && rest[1] == "branch":
+		ref, subpath = rest[2], strings.Join(rest[3:], "/")
+	case len(rest) >= 2 && (rest[0] == "src" || rest[0] == "tree"):
+		ref, subpath = rest[1], strings.Join(rest[2:], "/")
+	}
+
+	return NewGitHostingFS("https://"+u.Host, owner, repo, ref, subpath, os.Getenv("GITEA_TOKEN")), nil
 }
 
 // parseGitURL parses git://owner/repo or git://github.com/owner/repo URLs
-func parseGitURL(u *url.URL) (FileSystem, error) {
+func parseGitURL(ctx context.Context, u *url.URL) (FileSystem, error) {
 	// Format: git://github.com/owner/repo
 	// Or: git://owner/repo (shorthand, assumes github.com)
 	// Or: git://github.com/owner/repo#branch
@@ -115,7 +231,7 @@ func parseGitURL(u *url.URL) (FileSystem, error) {
 		ref = u.Fragment
 	}
 
-	gitFS, err := NewGitFS(gitURL, ref)
+	gitFS, err := NewGitFSContext(ctx, gitURL, ref, gitCredentials(gitURL))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create git filesystem: %w", err)
 	}
@@ -123,10 +239,49 @@ func parseGitURL(u *url.URL) (FileSystem, error) {
 	return gitFS, nil
 }
 
+// scpLikeRemotePattern matches git's scp-like syntax for ssh remotes, user@host:path
+var scpLikeRemotePattern = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/\\]`)
+
+func isSCPLikeRemote(uri string) bool {
+	return !strings.Contains(uri, "://") && scpLikeRemotePattern.MatchString(uri)
+}
+
+// newGitFSFromRemote clones an ssh remote, with the ref after a #
+func newGitFSFromRemote(ctx context.Context, remote string) (FileSystem, error) {
+	remote, ref, _ := strings.Cut(remote, "#")
+	gitFS, err := NewGitFSContext(ctx, remote, ref, gitCredentials(remote))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create git filesystem: %w", err)
+	}
+	return gitFS, nil
+}
+
+// gitCredentials reads clone credentials from the environment. GIT_TOKEN is sent to
+// any HTTPS remote, GITHUB_TOKEN only to the GitHub host it's for.
+func gitCredentials(remote string) GitCredentials {
+	credentials := GitCredentials{
+		Token:    os.Getenv("GIT_TOKEN"),
+		Username: os.Getenv("GIT_USERNAME"),
+		SSHKey:   os.Getenv("GIT_SSH_KEY"),
+	}
+	if credentials.Token == "" {
+		if u, err := url.Parse(remote); err == nil && "https://"+u.Host == DefaultGitHubHost().ServerURL {
+			credentials.Token = os.Getenv("GITHUB_TOKEN")
+		}
+	}
+	return credentials
+}
+
 // GetBasePath returns the base path for the given URI
 // This is useful for resolving relative paths in the CLI
 func GetBasePath(uri string) string {
+	if isSCPLikeRemote(uri) {
+		return "."
+	}
 	if !strings.Contains(uri, "://") {
+		if IsArchive(uri) {
+			return "." // Archives are rooted at their contents
+		}
 		return uri
 	}
 
@@ -143,10 +298,18 @@ func GetBasePath(uri string) string {
 		// For GitHub URLs, the filesystem handles subpaths internally
 		return "."
 
-	case "git":
+	case "git", "ssh", "git+ssh":
 		// For git URLs, the base path is "."
 		return "."
 
+	case "bitbucket", "gitea", "forgejo", "gogs":
+		// Like GitHub, the filesystem handles subpaths internally
+		return "."
+
+	case "s3", "gs":
+		// Archives are rooted at their contents
+		return "."
+
 	default:
 		return uri
 	}
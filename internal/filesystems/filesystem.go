@@ -1,6 +1,7 @@
 package filesystems
 
 import (
+	"io"
 	"io/fs"
 	"iter"
 	"time"
@@ -11,12 +12,23 @@ type FileSystem interface {
 	// ReadFile reads the named file and returns its contents
 	ReadFile(name string) ([]byte, error)
 
+	// Open opens the named file for streaming reads, so large files can be scanned
+	// without reading them into memory. The caller must close it.
+	Open(name string) (io.ReadCloser, error)
+
 	// ReadDir reads the named directory and returns an iterator over directory entries
 	ReadDir(name string) iter.Seq2[DirEntry, error]
 
 	// Walk walks the file tree rooted at root, calling fn for each file or directory
 	Walk(root string, fn WalkFunc) error
 
+	// Glob returns the sorted paths matching a pattern, with path.Match syntax in
+	// each path segment, or nil if none match
+	Glob(pattern string) ([]string, error)
+
+	// Exists reports whether a file or directory exists
+	Exists(name string) bool
+
 	// Join joins path elements into a single path
 	Join(elem ...string) string
 
@@ -54,3 +66,36 @@ type WalkFunc func(path string, info FileInfo, err error) error
 // SkipDir is used as a return value from WalkFunc to indicate that
 // the directory named in the call is to be skipped
 var SkipDir = fs.SkipDir
+
+// DownloadReporter is implemented by filesystems that download their source
+// before it can be read, like GitHub archives
+type DownloadReporter interface {
+	// OnDownload registers a callback called with the total bytes downloaded so far
+	OnDownload(progress func(bytes int64))
+}
+
+// TrackedReporter is implemented by filesystems that can tell files committed to a
+// repository from untracked ones, like a local checkout
+type TrackedReporter interface {
+	// Tracked reports whether a file is tracked by the repository it's in
+	Tracked(name string) bool
+}
+
+// IsTracked reports whether a file is committed to a repository, so anything in it
+// has been shared: every file is when the filesystem reads a revision, like a clone
+func IsTracked(filesystem FileSystem, name string) bool {
+	switch filesystem := Unwrap(filesystem).(type) {
+	case TrackedReporter:
+		return filesystem.Tracked(name)
+	case RevisionReporter:
+		return true
+	}
+	return false
+}
+
+// RevisionReporter is implemented by filesystems that read a specific revision of a
+// repository, so results can be tied to the commit they came from
+type RevisionReporter interface {
+	// Revision returns the commit SHA the filesystem's contents are from
+	Revision() (string, error)
+}
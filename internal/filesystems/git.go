@@ -1,191 +1,541 @@
 package filesystems
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"iter"
+	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
-// GitFS implements FileSystem for git repositories (cloned locally)
+// GitCredentials authenticate clones of private repositories. The token is sent
+// to HTTPS remotes and the SSH key used for ssh remotes. Without them, git's
+// credential helpers are asked for HTTPS remotes and the SSH agent is used for ssh.
+type GitCredentials struct {
+	Token    string
+	Username string // sent with the token, x-access-token unless set
+	SSHKey   string // path to a private key
+}
+
+// auth returns how to authenticate with a remote, or nil to try anonymously
+func (c GitCredentials) auth(endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	switch endpoint.Protocol {
+	case "https", "http":
+		if c.Token == "" {
+			return nil, nil
+		}
+		username := c.Username
+		if username == "" {
+			username = "x-access-token"
+		}
+		return &githttp.BasicAuth{Username: username, Password: c.Token}, nil
+	case "ssh":
+		user := endpoint.User
+		if user == "" {
+			user = "git"
+		}
+		if c.SSHKey != "" {
+			return gitssh.NewPublicKeysFromFile(user, c.SSHKey, "")
+		}
+		if os.Getenv("SSH_AUTH_SOCK") == "" {
+			return nil, nil
+		}
+		return gitssh.NewSSHAgentAuth(user)
+	}
+	return nil, nil
+}
+
+// GitFS implements FileSystem for git repositories, cloned into memory or opened
+// in place for local repositories. Files are read from the commit's tree, so no
+// worktree is checked out and no git binary is needed.
 type GitFS struct {
-	repoURL   string
-	ref       string
-	localPath string
-	localFS   *LocalFS
-	mu        sync.RWMutex // protects clone operations
-	cloned    bool
+	slashPaths
+	ctx         context.Context // for cloning submodules
+	repoURL     string
+	ref         string
+	credentials GitCredentials
+	repo        *git.Repository
+	commit      *object.Commit
+	tree        *object.Tree
+
+	// Submodules clones submodules at their recorded commits the first time
+	// they're read, recursively, like `git clone --recurse-submodules`
+	Submodules bool
+
+	mu         sync.Mutex
+	modules    map[string]string // submodule URLs by path, from .gitmodules
+	submodules map[string]submoduleClone
 }
 
-// NewGitFS creates a new GitFS instance
-func NewGitFS(repoURL, ref string) (*GitFS, error) {
-	if ref == "" {
-		// Detect the actual default branch using git ls-remote
-		ref = detectDefaultBranch(repoURL)
+type submoduleClone struct {
+	gfs *GitFS
+	err error
+}
+
+// NewGitFS clones a repository at a branch, tag or commit SHA, or its default
+// branch when ref is empty
+func NewGitFS(repoURL, ref string, credentials GitCredentials) (*GitFS, error) {
+	return NewGitFSContext(context.Background(), repoURL, ref, credentials)
+}
+
+// NewGitFSContext clones a repository like NewGitFS, stopping if ctx is cancelled
+func NewGitFSContext(ctx context.Context, repoURL, ref string, credentials GitCredentials) (*GitFS, error) {
+	gfs := &GitFS{ctx: ctx, repoURL: repoURL, ref: ref, credentials: credentials}
+
+	repo, err := openOrClone(ctx, repoURL, ref, credentials)
+	if err != nil {
+		return nil, err
 	}
+	gfs.repo = repo
 
-	// Create temporary directory for cloning
-	tempDir, err := os.MkdirTemp("", "turnout-git-*")
+	revision := plumbing.Revision("HEAD")
+	if ref != "" {
+		revision = plumbing.Revision(ref)
+	}
+	hash, err := repo.ResolveRevision(revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s in %s: %w", revision, repoURL, err)
+	}
+	if gfs.commit, err = repo.CommitObject(*hash); err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	if gfs.tree, err = gfs.commit.Tree(); err != nil {
+		return nil, fmt.Errorf("failed to read tree of %s: %w", hash, err)
+	}
+	return gfs, nil
+}
+
+// openOrClone opens a local repository in place, or clones a remote one into memory
+func openOrClone(ctx context.Context, repoURL, ref string, credentials GitCredentials) (*git.Repository, error) {
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid git remote %s: %w", repoURL, err)
+	}
+	if endpoint.Protocol == "file" {
+		repo, err := git.PlainOpenWithOptions(endpoint.Path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open repository %s: %w", repoURL, err)
+		}
+		return repo, nil
+	}
+
+	auth, err := credentials.auth(endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+		return nil, fmt.Errorf("invalid credentials for %s: %w", repoURL, withKind(ErrAuth, err))
 	}
 
-	gfs := &GitFS{
-		repoURL:   repoURL,
-		ref:       ref,
-		localPath: tempDir,
-		localFS:   NewLocalFS(),
+	repo, err := cloneRef(ctx, repoURL, ref, auth)
+	if isGitAuthFailure(err) && auth == nil {
+		// Credentials from a credential helper, like those `git clone` would use
+		if auth = credentialHelperAuth(endpoint); auth != nil {
+			repo, err = cloneRef(ctx, repoURL, ref, auth)
+		}
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, transport.ErrRepositoryNotFound) && auth != nil:
+			err = withKind(ErrNotFound, err)
+		case isGitAuthFailure(err):
+			err = withKind(ErrAuth, fmt.Errorf("%w; set GIT_TOKEN for HTTPS remotes, or GIT_SSH_KEY or an SSH agent for ssh remotes", err))
+		default:
+			err = networkError(err)
+		}
+		return nil, fmt.Errorf("failed to clone repository %s: %w", repoURL, err)
 	}
+	return repo, nil
+}
 
-	// Clone the repository
-	if err := gfs.clone(); err != nil {
-		os.RemoveAll(tempDir) // cleanup on error
+// cloneRef makes a shallow, single branch clone of a ref into memory. Refs that
+// aren't a branch or tag are taken to be commits, fetched directly where the
+// server allows it and from the full history otherwise.
+func cloneRef(ctx context.Context, repoURL, ref string, auth transport.AuthMethod) (*git.Repository, error) {
+	options := &git.CloneOptions{URL: repoURL, Auth: auth, Depth: 1, SingleBranch: true, Tags: git.NoTags, NoCheckout: true}
+	if ref == "" {
+		return git.CloneContext(ctx, memory.NewStorage(), nil, options)
+	}
+
+	var err error
+	for _, name := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)} {
+		options.ReferenceName = name
+		var repo *git.Repository
+		if repo, err = git.CloneContext(ctx, memory.NewStorage(), nil, options); err == nil {
+			return repo, nil
+		}
+		if !isMissingRef(err) {
+			return nil, err
+		}
+	}
+	if !isCommitPrefix(ref) {
+		return nil, fmt.Errorf("no branch, tag or commit named %s: %w", ref, ErrNotFound)
+	}
+
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{repoURL}})
+	if err != nil {
 		return nil, err
 	}
+	if isCommitSHA(ref) {
+		spec := config.RefSpec(ref + ":refs/heads/" + ref)
+		if err := remote.FetchContext(ctx, &git.FetchOptions{RefSpecs: []config.RefSpec{spec}, Auth: auth, Depth: 1, Tags: git.NoTags}); err == nil {
+			return repo, nil
+		}
+	}
+	// Abbreviated SHAs, and servers that don't serve commits by SHA, need the history to search
+	spec := config.RefSpec("+refs/heads/*:refs/remotes/origin/*")
+	if err := remote.FetchContext(ctx, &git.FetchOptions{RefSpecs: []config.RefSpec{spec}, Auth: auth, Tags: git.AllTags}); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, err
+	}
+	return repo, nil
+}
 
-	return gfs, nil
+// isMissingRef reports whether a clone failed because the ref doesn't exist
+func isMissingRef(err error) bool {
+	var noMatch git.NoMatchingRefSpecError
+	return errors.As(err, &noMatch) || errors.Is(err, plumbing.ErrReferenceNotFound)
+}
+
+// isCommitPrefix reports whether a ref could be an abbreviated or full commit SHA
+func isCommitPrefix(ref string) bool {
+	if len(ref) < 4 || len(ref) > 64 {
+		return false
+	}
+	return strings.Trim(strings.ToLower(ref), "0123456789abcdef") == ""
+}
+
+// isGitAuthFailure reports whether the remote needs credentials. Hosts answer
+// "not found" for private repositories to anonymous users.
+func isGitAuthFailure(err error) bool {
+	return errors.Is(err, transport.ErrAuthenticationRequired) ||
+		errors.Is(err, transport.ErrAuthorizationFailed) ||
+		errors.Is(err, transport.ErrRepositoryNotFound)
+}
+
+// credentialHelperAuth asks git's credential helpers for an HTTPS remote's
+// credentials, when git is installed
+func credentialHelperAuth(endpoint *transport.Endpoint) transport.AuthMethod {
+	if endpoint.Protocol != "https" && endpoint.Protocol != "http" {
+		return nil
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil
+	}
+
+	host := endpoint.Host
+	if endpoint.Port != 0 {
+		host = fmt.Sprintf("%s:%d", host, endpoint.Port)
+	}
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", endpoint.Protocol, host, strings.TrimPrefix(endpoint.Path, "/")))
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	auth := &githttp.BasicAuth{}
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		switch key {
+		case "username":
+			auth.Username = value
+		case "password":
+			auth.Password = value
+		}
+	}
+	if auth.Password == "" {
+		return nil
+	}
+	return auth
+}
+
+// Revision returns the commit SHA being read
+func (gfs *GitFS) Revision() (string, error) {
+	return gfs.commit.Hash.String(), nil
 }
 
-func (gfs *GitFS) clone() error {
+// Cleanup releases the clone. Clones live in memory, so there's nothing to remove.
+func (gfs *GitFS) Cleanup() error {
+	return nil
+}
+
+// resolve cleans a path into the form tree lookups expect, empty for the root
+func (gfs *GitFS) resolve(name string) (string, error) {
+	if err := validatePath(name); err != nil {
+		return "", err
+	}
+	name = path.Clean(strings.TrimPrefix(strings.ReplaceAll(name, "\\", "/"), "/"))
+	if name == "." {
+		return "", nil
+	}
+	return name, nil
+}
+
+// submoduleFor finds the submodule a path is in, returning the submodule and the
+// path within it, or nil when the path is in this repository
+func (gfs *GitFS) submoduleFor(name string) (*GitFS, string, error) {
+	if !gfs.Submodules || name == "" {
+		return nil, "", nil
+	}
+
 	gfs.mu.Lock()
 	defer gfs.mu.Unlock()
 
-	if gfs.cloned {
-		return nil
+	if gfs.modules == nil {
+		gfs.modules = make(map[string]string)
+		gfs.submodules = make(map[string]submoduleClone)
+		if file, err := gfs.tree.File(".gitmodules"); err == nil {
+			content, err := file.Contents()
+			modules := config.NewModules()
+			if err == nil && modules.Unmarshal([]byte(content)) == nil {
+				for _, module := range modules.Submodules {
+					gfs.modules[path.Clean(module.Path)] = module.URL
+				}
+			}
+		}
 	}
 
-	// Clone with depth 1 for performance
-	cmd := exec.Command("git", "clone", "--depth", "1", "--branch", gfs.ref, gfs.repoURL, gfs.localPath)
-	if err := cmd.Run(); err != nil {
-		// If branch clone fails, try without branch specification
-		cmd = exec.Command("git", "clone", "--depth", "1", gfs.repoURL, gfs.localPath)
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to clone repository %s: %w", gfs.repoURL, err)
+	for modulePath, moduleURL := range gfs.modules {
+		if name != modulePath && !strings.HasPrefix(name, modulePath+"/") {
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, modulePath), "/")
+		if rel == "" {
+			rel = "."
 		}
 
-		// Try to checkout the specific ref
-		cmd = exec.Command("git", "checkout", gfs.ref)
-		cmd.Dir = gfs.localPath
-		if err := cmd.Run(); err != nil {
-			// If checkout fails, continue with default branch
-			// This handles cases where ref doesn't exist
+		clone, ok := gfs.submodules[modulePath]
+		if !ok {
+			clone.gfs, clone.err = gfs.cloneSubmodule(modulePath, moduleURL)
+			gfs.submodules[modulePath] = clone // Failures are remembered so they're only tried once
 		}
+		return clone.gfs, rel, clone.err
+	}
+	return nil, "", nil
+}
+
+// cloneSubmodule clones a submodule at the commit this repository records for it
+func (gfs *GitFS) cloneSubmodule(modulePath, moduleURL string) (*GitFS, error) {
+	entry, err := gfs.tree.FindEntry(modulePath)
+	if err != nil || entry.Mode != filemode.Submodule {
+		return nil, fmt.Errorf("submodule %s has no commit recorded", modulePath)
 	}
 
-	gfs.cloned = true
-	return nil
+	moduleURL = resolveSubmoduleURL(gfs.repoURL, moduleURL)
+	credentials, err := submoduleCredentials(gfs.repoURL, moduleURL, gfs.credentials)
+	if err != nil {
+		return nil, fmt.Errorf("submodule %s: %w", modulePath, err)
+	}
+	submodule, err := NewGitFSContext(gfs.ctx, moduleURL, entry.Hash.String(), credentials)
+	if err != nil {
+		return nil, fmt.Errorf("submodule %s: %w", modulePath, err)
+	}
+	submodule.Submodules = true
+	return submodule, nil
 }
 
-// Cleanup removes the temporary git repository
-func (gfs *GitFS) Cleanup() error {
-	if gfs.localPath != "" {
-		return os.RemoveAll(gfs.localPath)
+// submoduleCredentials returns the credentials to clone a submodule with. A cloned
+// repository chooses its submodules' URLs, so credentials are only sent to the host
+// they're for, over the same scheme, and a remote repository can't have a
+// submodule read from the local disk.
+func submoduleCredentials(repoURL, moduleURL string, credentials GitCredentials) (GitCredentials, error) {
+	parent, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return GitCredentials{}, fmt.Errorf("invalid git remote %s: %w", repoURL, err)
 	}
-	return nil
+	module, err := transport.NewEndpoint(moduleURL)
+	if err != nil {
+		return GitCredentials{}, fmt.Errorf("invalid git remote %s: %w", moduleURL, err)
+	}
+	if module.Protocol == "file" && parent.Protocol != "file" {
+		return GitCredentials{}, fmt.Errorf("refusing to open local path %s for a submodule of remote %s", moduleURL, repoURL)
+	}
+	if module.Protocol != parent.Protocol || !strings.EqualFold(module.Host, parent.Host) || module.Port != parent.Port {
+		return GitCredentials{}, nil
+	}
+	return credentials, nil
+}
+
+// resolveSubmoduleURL resolves a submodule URL like ../lib.git against the URL of
+// the repository it's in, the way git does
+func resolveSubmoduleURL(repoURL, moduleURL string) string {
+	if !strings.HasPrefix(moduleURL, "./") && !strings.HasPrefix(moduleURL, "../") {
+		return moduleURL
+	}
+	if u, err := url.Parse(repoURL); err == nil && u.Scheme != "" {
+		u.Path = path.Join(u.Path, moduleURL)
+		return u.String()
+	}
+	if isSCPLikeRemote(repoURL) {
+		host, repoPath, _ := strings.Cut(repoURL, ":")
+		return host + ":" + path.Join(repoPath, moduleURL)
+	}
+	return filepath.Join(repoURL, moduleURL)
 }
 
 func (gfs *GitFS) ReadFile(name string) ([]byte, error) {
-	if err := gfs.ensureCloned(); err != nil {
+	reader, err := gfs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func (gfs *GitFS) Open(name string) (io.ReadCloser, error) {
+	name, err := gfs.resolve(name)
+	if err != nil {
 		return nil, err
 	}
+	submodule, rel, err := gfs.submoduleFor(name)
+	if err != nil {
+		return nil, err
+	}
+	if submodule != nil {
+		return submodule.Open(rel)
+	}
 
-	fullPath := gfs.localFS.Join(gfs.localPath, name)
-	return gfs.localFS.ReadFile(fullPath)
+	file, err := gfs.tree.File(name)
+	if err != nil {
+		return nil, fmt.Errorf("file %w: %s", ErrNotFound, name)
+	}
+	return file.Reader()
 }
 
 func (gfs *GitFS) ReadDir(name string) iter.Seq2[DirEntry, error] {
 	return func(yield func(DirEntry, error) bool) {
-		if err := gfs.ensureCloned(); err != nil {
+		name, err := gfs.resolve(name)
+		if err != nil {
 			yield(nil, err)
 			return
 		}
+		submodule, rel, err := gfs.submoduleFor(name)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if submodule != nil {
+			for entry, err := range submodule.ReadDir(rel) {
+				if !yield(entry, err) {
+					return
+				}
+			}
+			return
+		}
 
-		fullPath := gfs.localFS.Join(gfs.localPath, name)
-		for entry, err := range gfs.localFS.ReadDir(fullPath) {
-			if !yield(entry, err) {
+		tree := gfs.tree
+		if name != "" {
+			entry, err := gfs.tree.FindEntry(name)
+			if err != nil || entry.Mode != filemode.Dir {
+				if err == nil && entry.Mode == filemode.Submodule {
+					return // Submodules are empty unless cloned, like `git clone` without --recurse-submodules
+				}
+				yield(nil, fmt.Errorf("directory %w: %s", ErrNotFound, name))
+				return
+			}
+			if tree, err = gfs.tree.Tree(name); err != nil {
+				yield(nil, err)
 				return
 			}
 		}
-	}
-}
-
-func (gfs *GitFS) Walk(root string, fn WalkFunc) error {
-	if err := gfs.ensureCloned(); err != nil {
-		return err
-	}
-
-	fullRoot := gfs.localFS.Join(gfs.localPath, root)
 
-	// Wrap the walk function to adjust paths
-	wrappedFn := func(path string, info FileInfo, err error) error {
-		// Convert absolute path back to relative path
-		if strings.HasPrefix(path, gfs.localPath) {
-			relPath := strings.TrimPrefix(path, gfs.localPath)
-			relPath = strings.TrimPrefix(relPath, string(filepath.Separator))
-			if relPath == "" {
-				relPath = "."
+		for _, entry := range tree.Entries {
+			isDir := entry.Mode == filemode.Dir || entry.Mode == filemode.Submodule
+			var size int64
+			if !isDir {
+				size, _ = tree.Size(entry.Name)
+			}
+			dirEntry := &lightweightDirEntry{
+				name:       entry.Name,
+				isDir:      isDir,
+				parentPath: name,
+				size:       size,
+				modTime:    gfs.modTime(),
+			}
+			if !yield(dirEntry, nil) {
+				return
 			}
-			return fn(relPath, info, err)
 		}
-		return fn(path, info, err)
 	}
-
-	return gfs.localFS.Walk(fullRoot, wrappedFn)
 }
 
-func (gfs *GitFS) Join(elem ...string) string {
-	return gfs.localFS.Join(elem...)
+func (gfs *GitFS) Glob(pattern string) ([]string, error) {
+	return globReadDir(gfs, pattern)
 }
 
-func (gfs *GitFS) Base(path string) string {
-	return gfs.localFS.Base(path)
+func (gfs *GitFS) Exists(name string) bool {
+	name, err := gfs.resolve(name)
+	if err != nil {
+		return false
+	}
+	if name == "" {
+		return true
+	}
+	if submodule, rel, err := gfs.submoduleFor(name); submodule != nil || err != nil {
+		return err == nil && submodule.Exists(rel)
+	}
+	_, err = gfs.tree.FindEntry(name)
+	return err == nil
 }
 
-func (gfs *GitFS) Dir(path string) string {
-	return gfs.localFS.Dir(path)
+// modTime is the commit time, which git's trees don't record per file
+func (gfs *GitFS) modTime() time.Time {
+	return gfs.commit.Committer.When
 }
 
-func (gfs *GitFS) Rel(basepath, targpath string) (string, error) {
-	return gfs.localFS.Rel(basepath, targpath)
+func (gfs *GitFS) Walk(root string, fn WalkFunc) error {
+	rootInfo := &lightweightFileInfo{name: root, isDir: true, modTime: gfs.modTime()}
+	return walkReadDir(gfs, root, rootInfo, fn, 0, 10)
 }
 
-func (gfs *GitFS) ensureCloned() error {
-	gfs.mu.RLock()
-	cloned := gfs.cloned
-	gfs.mu.RUnlock()
-
-	if !cloned {
-		return gfs.clone()
+// detectDefaultBranch asks a remote which branch HEAD points to
+func detectDefaultBranch(ctx context.Context, repoURL string, credentials GitCredentials) (string, error) {
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid git remote %s: %w", repoURL, err)
 	}
-	return nil
-}
-
-// detectDefaultBranch tries to detect the default branch using git ls-remote
-func detectDefaultBranch(repoURL string) string {
-	// Try to get the default branch using git ls-remote HEAD
-	cmd := exec.Command("git", "ls-remote", "--symref", repoURL, "HEAD")
-	output, err := cmd.Output()
+	auth, err := credentials.auth(endpoint)
 	if err != nil {
-		// Fallback to "main"
-		return "main"
+		return "", fmt.Errorf("invalid credentials for %s: %w", repoURL, withKind(ErrAuth, err))
 	}
 
-	// Parse output like: "ref: refs/heads/master\tHEAD"
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) > 0 {
-		line := lines[0]
-		if strings.HasPrefix(line, "ref: refs/heads/") {
-			branch := strings.TrimPrefix(line, "ref: refs/heads/")
-			if idx := strings.Index(branch, "\t"); idx > 0 {
-				branch = branch[:idx]
-			}
-			return branch
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{repoURL}})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		switch {
+		case errors.Is(err, transport.ErrRepositoryNotFound):
+			err = withKind(ErrNotFound, err)
+		case isGitAuthFailure(err):
+			err = withKind(ErrAuth, err)
+		default:
+			err = networkError(err)
 		}
+		return "", fmt.Errorf("failed to find the default branch of %s: %w", repoURL, err)
 	}
-
-	// Fallback to "main"
-	return "main"
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference && ref.Target().IsBranch() {
+			return ref.Target().Short(), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no default branch: %w", repoURL, ErrNotFound)
 }
@@ -0,0 +1,100 @@
+package filesystems
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GitHostingFS implements FileSystem using repository archives downloaded from a
+// self-hosted Git service with Gitea's API, which Forgejo and Gogs share
+type GitHostingFS struct {
+	*archiveFS
+	baseURL string
+	owner   string
+	repo    string
+	ref     string
+	token   string
+}
+
+// NewGitHostingFS creates a new GitHostingFS instance for the service at baseURL,
+// e.g. https://gitea.example.com. The default branch is used when ref is empty.
+func NewGitHostingFS(baseURL, owner, repo, ref, basePath, token string) *GitHostingFS {
+	hfs := &GitHostingFS{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		owner:   owner,
+		repo:    repo,
+		ref:     ref,
+		token:   token,
+	}
+	hfs.archiveFS = newArchiveFS(fmt.Sprintf("gitea-%s-%s", owner, repo), basePath, hfs.downloadArchive)
+	return hfs
+}
+
+// downloadArchive downloads the repository archive for the ref
+func (hfs *GitHostingFS) downloadArchive(ctx context.Context, w io.Writer) error {
+	ref := hfs.ref
+	if ref == "" {
+		var err error
+		if ref, err = hfs.defaultBranch(ctx); err != nil {
+			return err
+		}
+	}
+
+	archiveURL := fmt.Sprintf("%s/archive/%s.zip", hfs.repoURL(), url.PathEscape(ref))
+	req, err := hfs.newRequest(ctx, archiveURL)
+	if err != nil {
+		return err
+	}
+
+	_, err = downloadArchive(http.DefaultClient, req, archiveURL, w)
+	return err
+}
+
+// defaultBranch looks up the repository's default branch
+func (hfs *GitHostingFS) defaultBranch(ctx context.Context) (string, error) {
+	req, err := hfs.newRequest(ctx, hfs.repoURL())
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", networkError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to look up repository: %w for %s", &StatusError{resp.StatusCode}, hfs.repoURL())
+	}
+
+	var repository struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repository); err != nil {
+		return "", err
+	}
+	if repository.DefaultBranch == "" {
+		return "main", nil
+	}
+	return repository.DefaultBranch, nil
+}
+
+func (hfs *GitHostingFS) repoURL() string {
+	return fmt.Sprintf("%s/api/v1/repos/%s/%s", hfs.baseURL, url.PathEscape(hfs.owner), url.PathEscape(hfs.repo))
+}
+
+func (hfs *GitHostingFS) newRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if hfs.token != "" {
+		req.Header.Set("Authorization", "token "+hfs.token)
+	}
+	return req, nil
+}
@@ -0,0 +1,187 @@
+package filesystems
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	githubMaxRetries = 5
+	githubMaxWait    = 5 * time.Minute // longer waits fail with a RateLimitError instead
+)
+
+// githubClient sends every GitHub request, retrying those that are rate limited
+var githubClient = &http.Client{Transport: &githubTransport{base: http.DefaultTransport}}
+
+// RateLimitError is returned when GitHub's rate limit won't reset soon enough to wait for
+type RateLimitError struct {
+	Reset     time.Time
+	Secondary bool // a secondary limit on request bursts rather than the hourly quota
+}
+
+func (e *RateLimitError) Error() string {
+	limit := "rate limit"
+	if e.Secondary {
+		limit = "secondary rate limit"
+	}
+	message := fmt.Sprintf("GitHub API %s exceeded, resets at %s (in %s)",
+		limit, e.Reset.Local().Format(time.Kitchen), time.Until(e.Reset).Round(time.Second))
+	if !e.Secondary {
+		message += "; set GITHUB_TOKEN for a higher limit"
+	}
+	return message
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// githubRateLimit is the latest rate limit a GitHub API host reported
+type githubRateLimit struct {
+	remaining int
+	reset     time.Time
+	secondary bool
+}
+
+// githubRateLimits tracks each host's rate limit so concurrent requests wait for
+// the limit to reset instead of failing
+var githubRateLimits = struct {
+	sync.Mutex
+	hosts map[string]*githubRateLimit
+}{hosts: make(map[string]*githubRateLimit)}
+
+// recordGitHubRateLimit remembers the rate limit reported by a GitHub API response
+func recordGitHubRateLimit(host string, header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	githubRateLimits.Lock()
+	defer githubRateLimits.Unlock()
+	githubRateLimits.hosts[host] = &githubRateLimit{remaining: remaining, reset: time.Unix(reset, 0)}
+}
+
+// pauseGitHubRequests holds a host's requests until a rate limit lifts
+func pauseGitHubRequests(host string, until time.Time, secondary bool) {
+	githubRateLimits.Lock()
+	defer githubRateLimits.Unlock()
+	// A reset the host reported is exact, so only extend it for secondary limits
+	if limit := githubRateLimits.hosts[host]; limit == nil || limit.remaining > 0 || (secondary && until.After(limit.reset)) {
+		githubRateLimits.hosts[host] = &githubRateLimit{reset: until, secondary: secondary}
+	}
+}
+
+// waitForGitHubRateLimit blocks until a host's rate limit allows another request,
+// or returns a RateLimitError if that's too far away
+func waitForGitHubRateLimit(ctx context.Context, host string) error {
+	githubRateLimits.Lock()
+	limit := githubRateLimits.hosts[host]
+	githubRateLimits.Unlock()
+	if limit == nil || limit.remaining > 0 {
+		return nil
+	}
+
+	wait := time.Until(limit.reset)
+	if wait > githubMaxWait {
+		return &RateLimitError{Reset: limit.reset, Secondary: limit.secondary}
+	}
+	return sleep(ctx, wait)
+}
+
+// githubTransport retries requests GitHub rate limits. It waits for Retry-After, the
+// limit's reset, or an exponential backoff for secondary limits that give neither.
+type githubTransport struct {
+	base http.RoundTripper
+}
+
+func (t *githubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := waitForGitHubRateLimit(req.Context(), req.URL.Host); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		recordGitHubRateLimit(req.URL.Host, resp.Header)
+
+		limited, secondary := githubRateLimited(resp)
+		if !limited {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		wait := githubRetryWait(resp.Header, attempt)
+		if attempt == githubMaxRetries {
+			return nil, &RateLimitError{Reset: time.Now().Add(wait), Secondary: secondary}
+		}
+		// Waiting for the pause, or failing if it's too long, happens before the retry
+		pauseGitHubRequests(req.URL.Host, time.Now().Add(wait), secondary)
+	}
+}
+
+// githubRateLimited reports whether a response was rejected by a rate limit, and
+// whether the limit is a secondary one
+func githubRateLimited(resp *http.Response) (limited, secondary bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false, false
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return true, false
+	}
+	if resp.Header.Get("Retry-After") != "" {
+		return true, true
+	}
+
+	// Otherwise only the message says, so peek at it and leave the body readable
+	peek, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), resp.Body), resp.Body}
+	if strings.Contains(strings.ToLower(string(peek)), "rate limit") {
+		return true, true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests, true
+}
+
+// githubRetryWait is how long to wait before retrying a rate limited request
+func githubRetryWait(header http.Header, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Until(time.Unix(reset, 0)), time.Second)
+		}
+	}
+	// GitHub asks for at least a minute between retries of secondary limits
+	return time.Minute << attempt
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,68 @@
+package filesystems
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// GitHubRepo is a repository listed by the GitHub API
+type GitHubRepo struct {
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+	Archived      bool   `json:"archived"`
+	Fork          bool   `json:"fork"`
+}
+
+// ListGitHubRepos lists every repository of a GitHub organization, or of a user if
+// no organization has the name
+func ListGitHubRepos(ctx context.Context, host GitHubHost, owner, token string) ([]GitHubRepo, error) {
+	repos, err := listGitHubRepos(ctx, fmt.Sprintf("%s/orgs/%s/repos?type=all", host.APIURL, owner), token)
+	if errors.Is(err, ErrNotFound) {
+		repos, err = listGitHubRepos(ctx, fmt.Sprintf("%s/users/%s/repos?type=owner", host.APIURL, owner), token)
+	}
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("no GitHub organization or user named %s: %w", owner, ErrNotFound)
+	}
+	return repos, err
+}
+
+func listGitHubRepos(ctx context.Context, url, token string) ([]GitHubRepo, error) {
+	const perPage = 100
+
+	var repos []GitHubRepo
+	for page := 1; ; page++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s&per_page=%d&page=%d", url, perPage, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := githubClient.Do(req)
+		if err != nil {
+			return nil, networkError(err)
+		}
+
+		var pageRepos []GitHubRepo
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("failed to list repositories: %w", &StatusError{resp.StatusCode})
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&pageRepos)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		repos = append(repos, pageRepos...)
+		if len(pageRepos) < perPage {
+			return repos, nil
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package filesystems
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+// GitHubTreeFS implements FileSystem for a GitHub repository through the API, listing
+// paths with the Git Trees API and fetching only the files that are read with the
+// contents API. Each read is a request, but nothing else is downloaded, which suits
+// monorepos too large to fetch as a zipball with GitHubFS.
+type GitHubTreeFS struct {
+	slashPaths
+	ctx      context.Context
+	host     GitHubHost
+	owner    string
+	repo     string
+	ref      string
+	basePath string
+	token    string
+
+	mu   sync.Mutex
+	dirs map[string]*githubTreeDir // keyed by path in the repository, "" for the root
+}
+
+type githubTreeDir struct {
+	sha     string
+	listed  bool
+	entries []githubTreeEntry // named relative to the directory once listed
+}
+
+type githubTreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"` // "blob", "tree", or "commit" for submodules
+	SHA  string `json:"sha"`
+	Size int64  `json:"size"` // blobs only
+}
+
+// NewGitHubTreeFS creates a new GitHubTreeFS instance. The default branch is used
+// when ref is empty.
+func NewGitHubTreeFS(host GitHubHost, owner, repo, ref, basePath, token string) *GitHubTreeFS {
+	rootRef := ref
+	if rootRef == "" {
+		rootRef = "HEAD"
+	}
+	return &GitHubTreeFS{
+		ctx:      context.Background(),
+		host:     host,
+		owner:    owner,
+		repo:     repo,
+		ref:      ref,
+		basePath: basePath,
+		token:    token,
+		dirs:     map[string]*githubTreeDir{"": {sha: rootRef}},
+	}
+}
+
+// setContext makes API requests use ctx, so cancelling it stops them
+func (t *GitHubTreeFS) setContext(ctx context.Context) {
+	t.ctx = ctx
+}
+
+func (t *GitHubTreeFS) ReadFile(name string) ([]byte, error) {
+	body, err := t.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// Open streams a file's contents from the API as they're read
+func (t *GitHubTreeFS) Open(name string) (io.ReadCloser, error) {
+	if err := validatePath(name); err != nil {
+		return nil, err
+	}
+	name = resolveBasePath(t.basePath, name)
+
+	contentsURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s", t.host.APIURL, t.owner, t.repo, awsURIEncode(name, false))
+	if t.ref != "" {
+		contentsURL += "?ref=" + url.QueryEscape(t.ref)
+	}
+	resp, err := t.get(contentsURL, "application/vnd.github.raw")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("file %w: %s", ErrNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", name, &StatusError{resp.StatusCode})
+	}
+	return resp.Body, nil
+}
+
+// Glob lists only the directories the pattern's wildcards range over, which are
+// cached once listed
+func (t *GitHubTreeFS) Glob(pattern string) ([]string, error) {
+	return globReadDir(t, pattern)
+}
+
+// Exists checks the listing of the path's directory
+func (t *GitHubTreeFS) Exists(name string) bool {
+	return existsReadDir(t, name)
+}
+
+func (t *GitHubTreeFS) ReadDir(name string) iter.Seq2[DirEntry, error] {
+	return func(yield func(DirEntry, error) bool) {
+		if err := validatePath(name); err != nil {
+			yield(nil, err)
+			return
+		}
+		name = resolveBasePath(t.basePath, name)
+		if name == "." {
+			name = ""
+		}
+
+		t.mu.Lock()
+		dir, err := t.dir(name)
+		t.mu.Unlock()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for _, entry := range dir.entries {
+			if entry.Type == "commit" {
+				continue // Submodules aren't in the repository's tree
+			}
+			if !yield(&lightweightDirEntry{name: entry.Path, isDir: entry.Type == "tree", parentPath: name, size: entry.Size}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (t *GitHubTreeFS) Walk(root string, fn WalkFunc) error {
+	return walkReadDir(t, root, &lightweightFileInfo{name: root, isDir: true}, fn, 0, 10)
+}
+
+// Revision resolves the ref being read to its commit SHA
+func (t *GitHubTreeFS) Revision() (string, error) {
+	return resolveGitHubCommit(t.ctx, t.host, t.owner, t.repo, t.ref, t.token)
+}
+
+// dir returns a listed directory, listing it and its parents as needed. Callers hold mu.
+func (t *GitHubTreeFS) dir(name string) (*githubTreeDir, error) {
+	dir, ok := t.dirs[name]
+	if !ok {
+		if name == "" {
+			return nil, fmt.Errorf("directory %w: %s", ErrNotFound, name)
+		}
+		// Listing the parent registers its subdirectories
+		parent := path.Dir(name)
+		if parent == "." {
+			parent = ""
+		}
+		if _, err := t.dir(parent); err != nil {
+			return nil, err
+		}
+		if dir, ok = t.dirs[name]; !ok {
+			return nil, fmt.Errorf("directory %w: %s", ErrNotFound, name)
+		}
+	}
+
+	if !dir.listed {
+		if err := t.list(name, dir); err != nil {
+			return nil, err
+		}
+	}
+	return dir, nil
+}
+
+// list fetches a directory's tree. The root is fetched recursively, which lists the
+// whole repository in one request unless GitHub truncates it for being too large.
+func (t *GitHubTreeFS) list(name string, dir *githubTreeDir) error {
+	treeURL := fmt.Sprintf("%s/repos/%s/%s/git/trees/%s", t.host.APIURL, t.owner, t.repo, url.PathEscape(dir.sha))
+	recursive := name == ""
+	if recursive {
+		treeURL += "?recursive=1"
+	}
+
+	resp, err := t.get(treeURL, "application/vnd.github+json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to list %s: %w", treeURL, &StatusError{resp.StatusCode})
+	}
+
+	var tree struct {
+		Tree      []githubTreeEntry `json:"tree"`
+		Truncated bool              `json:"truncated"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tree); err != nil {
+		return err
+	}
+
+	for _, entry := range tree.Tree {
+		if recursive && tree.Truncated && strings.Contains(entry.Path, "/") {
+			continue // Truncated listings are incomplete, so nested directories are listed on demand
+		}
+
+		entryPath := path.Join(name, entry.Path)
+		parent := path.Dir(entryPath)
+		if parent == "." {
+			parent = ""
+		}
+		if entry.Type == "tree" {
+			if _, ok := t.dirs[entryPath]; !ok {
+				t.dirs[entryPath] = &githubTreeDir{sha: entry.SHA, listed: recursive && !tree.Truncated}
+			}
+		}
+
+		parentDir := dir
+		if parent != name {
+			parentDir = t.dirs[parent] // Trees are listed before their entries
+		}
+		parentDir.entries = append(parentDir.entries, githubTreeEntry{Path: path.Base(entryPath), Type: entry.Type, SHA: entry.SHA, Size: entry.Size})
+	}
+	dir.listed = true
+	return nil
+}
+
+// get requests the API, retrying when rate limited
+func (t *GitHubTreeFS) get(url, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(t.ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	resp, err := githubClient.Do(req)
+	return resp, networkError(err)
+}
@@ -0,0 +1,68 @@
+package filesystems
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// globReadDir implements Glob for filesystems without a native glob. Only the
+// directories a pattern's wildcards range over are listed, so on filesystems with
+// an index, like archives and API trees, it never touches unrelated paths.
+func globReadDir(filesystem FileSystem, pattern string) ([]string, error) {
+	// Match reports malformed patterns regardless of the name
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	segments := strings.Split(strings.Trim(strings.ReplaceAll(pattern, "\\", "/"), "/"), "/")
+	candidates := []string{"."}
+	for i, segment := range segments {
+		last := i == len(segments)-1
+
+		var next []string
+		for _, dir := range candidates {
+			if !strings.ContainsAny(segment, `*?[\`) {
+				// Literal segments are checked when they're listed, or at the end
+				if candidate := filesystem.Join(dir, segment); !last || filesystem.Exists(candidate) {
+					next = append(next, candidate)
+				}
+				continue
+			}
+
+			for entry, err := range filesystem.ReadDir(dir) {
+				if err != nil {
+					break
+				}
+				if !last && !entry.IsDir() {
+					continue
+				}
+				if matched, _ := path.Match(segment, entry.Name()); matched {
+					next = append(next, filesystem.Join(dir, entry.Name()))
+				}
+			}
+		}
+		candidates = next
+	}
+
+	slices.Sort(candidates)
+	return candidates, nil
+}
+
+// existsReadDir implements Exists by looking for a path in its parent's listing
+func existsReadDir(filesystem FileSystem, name string) bool {
+	name = strings.Trim(strings.ReplaceAll(name, "\\", "/"), "/")
+	if name == "" || name == "." {
+		return true
+	}
+	base := path.Base(name)
+	for entry, err := range filesystem.ReadDir(path.Dir(name)) {
+		if err != nil {
+			return false
+		}
+		if entry.Name() == base {
+			return true
+		}
+	}
+	return false
+}
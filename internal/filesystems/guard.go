@@ -0,0 +1,75 @@
+package filesystems
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MaxTextFileSize is the largest file OpenTextFile and ReadTextFile read, so a
+// checked-in database dump or build artifact isn't slurped and regex-scanned
+var MaxTextFileSize int64 = 2 << 20
+
+var (
+	ErrFileTooLarge = errors.New("file too large")
+	ErrBinaryFile   = errors.New("binary file")
+)
+
+// binarySniffSize is how much of a file is checked for binary content, like git does
+const binarySniffSize = 8000
+
+// IsBinary reports whether content looks binary, by a NUL byte near its start
+func IsBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binarySniffSize)], 0) != -1
+}
+
+// OpenTextFile opens a file for scanning, failing with ErrBinaryFile for binary
+// files, and with ErrFileTooLarge from Read once more than MaxTextFileSize bytes
+// are read
+func OpenTextFile(filesystem FileSystem, name string) (io.ReadCloser, error) {
+	file, err := filesystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReaderSize(file, binarySniffSize)
+	if head, _ := reader.Peek(binarySniffSize); IsBinary(head) {
+		file.Close()
+		return nil, fmt.Errorf("%w: %s", ErrBinaryFile, name)
+	}
+	return &textFile{Reader: reader, Closer: file, name: name, remaining: MaxTextFileSize}, nil
+}
+
+// ReadTextFile reads a text file, failing like OpenTextFile for binary and large files
+func ReadTextFile(filesystem FileSystem, name string) ([]byte, error) {
+	file, err := OpenTextFile(filesystem, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+type textFile struct {
+	io.Reader
+	io.Closer
+	name      string
+	remaining int64
+}
+
+func (f *textFile) Read(p []byte) (int, error) {
+	if f.remaining < 0 {
+		return 0, fmt.Errorf("%w: %s is over %d bytes", ErrFileTooLarge, f.name, MaxTextFileSize)
+	}
+	// Read one byte past the limit to tell a file of exactly the limit from a larger one
+	if int64(len(p)) > f.remaining+1 {
+		p = p[:f.remaining+1]
+	}
+	n, err := f.Reader.Read(p)
+	f.remaining -= int64(n)
+	if f.remaining < 0 {
+		return 0, fmt.Errorf("%w: %s is over %d bytes", ErrFileTooLarge, f.name, MaxTextFileSize)
+	}
+	return n, err
+}
@@ -0,0 +1,125 @@
+package filesystems
+
+import (
+	"io"
+	"io/fs"
+	"iter"
+	"path"
+	"strings"
+)
+
+// IOFS implements FileSystem for an io/fs filesystem, like embed.FS, fstest.MapFS
+// or os.DirFS over a testdata tree, so standard fixtures can be scanned directly
+type IOFS struct {
+	slashPaths
+	fsys fs.FS
+}
+
+// NewIOFS adapts an io/fs filesystem
+func NewIOFS(fsys fs.FS) *IOFS {
+	return &IOFS{fsys: fsys}
+}
+
+// resolve cleans a path into the unrooted form io/fs requires
+func (ifs *IOFS) resolve(name string) (string, error) {
+	if err := validatePath(name); err != nil {
+		return "", err
+	}
+	return path.Clean(strings.TrimPrefix(strings.ReplaceAll(name, "\\", "/"), "/")), nil
+}
+
+func (ifs *IOFS) ReadFile(name string) ([]byte, error) {
+	name, err := ifs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(ifs.fsys, name)
+}
+
+func (ifs *IOFS) Open(name string) (io.ReadCloser, error) {
+	name, err := ifs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := ifs.fsys.Open(name)
+	if err != nil {
+		return nil, notFoundError(err)
+	}
+	if stat, err := file.Stat(); err == nil && stat.IsDir() {
+		file.Close()
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	return file, nil
+}
+
+func (ifs *IOFS) ReadDir(name string) iter.Seq2[DirEntry, error] {
+	return func(yield func(DirEntry, error) bool) {
+		name, err := ifs.resolve(name)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		entries, err := fs.ReadDir(ifs.fsys, name)
+		if err != nil {
+			yield(nil, notFoundError(err))
+			return
+		}
+		for _, entry := range entries {
+			if !yield(ioDirEntry{entry}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (ifs *IOFS) Walk(root string, fn WalkFunc) error {
+	root, err := ifs.resolve(root)
+	if err != nil {
+		return err
+	}
+	return fs.WalkDir(ifs.fsys, root, func(path string, entry fs.DirEntry, err error) error {
+		var info FileInfo
+		if entry != nil {
+			if fileInfo, infoErr := entry.Info(); infoErr == nil {
+				info = fileInfo
+			} else if err == nil {
+				err = infoErr
+			}
+		}
+		return fn(path, info, err)
+	})
+}
+
+func (ifs *IOFS) Glob(pattern string) ([]string, error) {
+	return fs.Glob(ifs.fsys, strings.TrimPrefix(strings.ReplaceAll(pattern, "\\", "/"), "/"))
+}
+
+func (ifs *IOFS) Exists(name string) bool {
+	name, err := ifs.resolve(name)
+	if err != nil {
+		return false
+	}
+	_, err = fs.Stat(ifs.fsys, name)
+	return err == nil
+}
+
+// ioDirEntry adapts an fs.DirEntry, whose Info returns the standard FileInfo
+type ioDirEntry struct {
+	entry fs.DirEntry
+}
+
+func (e ioDirEntry) Name() string {
+	return e.entry.Name()
+}
+
+func (e ioDirEntry) IsDir() bool {
+	return e.entry.IsDir()
+}
+
+func (e ioDirEntry) Type() fs.FileMode {
+	return e.entry.Type()
+}
+
+func (e ioDirEntry) Info() (FileInfo, error) {
+	return e.entry.Info()
+}
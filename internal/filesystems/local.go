@@ -5,10 +5,22 @@ import (
 	"iter"
 	"os"
 	"path/filepath"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
 )
 
 // LocalFS implements FileSystem for local filesystem access
-type LocalFS struct{}
+type LocalFS struct {
+	mu    sync.Mutex
+	repos map[string]*localRepo // the repository each directory is in, nil if none
+}
+
+// localRepo is a git repository's root and the paths in its index
+type localRepo struct {
+	root    string
+	tracked map[string]bool
+}
 
 // NewLocalFS creates a new LocalFS instance
 func NewLocalFS() *LocalFS {
@@ -19,11 +31,19 @@ func (lfs *LocalFS) ReadFile(name string) ([]byte, error) {
 	return os.ReadFile(name)
 }
 
+func (lfs *LocalFS) Open(name string) (io.ReadCloser, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, notFoundError(err)
+	}
+	return file, nil
+}
+
 func (lfs *LocalFS) ReadDir(name string) iter.Seq2[DirEntry, error] {
 	return func(yield func(DirEntry, error) bool) {
 		dir, err := os.Open(name)
 		if err != nil {
-			yield(nil, err)
+			yield(nil, notFoundError(err))
 			return
 		}
 		defer dir.Close()
@@ -58,6 +78,15 @@ func (lfs *LocalFS) Walk(root string, fn WalkFunc) error {
 	})
 }
 
+func (lfs *LocalFS) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+func (lfs *LocalFS) Exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
 func (lfs *LocalFS) Join(elem ...string) string {
 	return filepath.Join(elem...)
 }
@@ -91,3 +120,49 @@ func (e *localDirEntry) Info() (FileInfo, error) {
 type localFileInfo struct {
 	os.FileInfo
 }
+
+// Tracked reports whether a file is in the index of the git repository it's in, if
+// it's in one
+func (lfs *LocalFS) Tracked(name string) bool {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+	repo := lfs.repo(filepath.Dir(abs))
+	if repo == nil {
+		return false
+	}
+	rel, err := filepath.Rel(repo.root, abs)
+	return err == nil && repo.tracked[filepath.ToSlash(rel)]
+}
+
+// repo reads the index of the repository a directory is in, once per directory
+func (lfs *LocalFS) repo(dir string) *localRepo {
+	lfs.mu.Lock()
+	defer lfs.mu.Unlock()
+	if repo, ok := lfs.repos[dir]; ok {
+		return repo
+	}
+	if lfs.repos == nil {
+		lfs.repos = make(map[string]*localRepo)
+	}
+
+	var repo *localRepo
+	if repository, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true}); err == nil {
+		if worktree, err := repository.Worktree(); err == nil {
+			// Directories in the same repository share its index
+			root := worktree.Filesystem.Root()
+			if known, ok := lfs.repos[root]; ok && known != nil {
+				repo = known
+			} else if index, err := repository.Storer.Index(); err == nil {
+				repo = &localRepo{root: root, tracked: make(map[string]bool, len(index.Entries))}
+				for _, entry := range index.Entries {
+					repo.tracked[entry.Name] = true
+				}
+				lfs.repos[root] = repo
+			}
+		}
+	}
+	lfs.repos[dir] = repo
+	return repo
+}
@@ -1,7 +1,9 @@
 package filesystems
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"io/fs"
 	"iter"
 	"path"
@@ -50,18 +52,26 @@ func (mfs *MemoryFS) ReadFile(name string) ([]byte, error) {
 	cleanName := path.Clean(name)
 	content, exists := mfs.files[cleanName]
 	if !exists {
-		return nil, fmt.Errorf("file not found: %s", name)
+		return nil, fmt.Errorf("file %w: %s", ErrNotFound, name)
 	}
 	return content, nil
 }
 
+func (mfs *MemoryFS) Open(name string) (io.ReadCloser, error) {
+	content, err := mfs.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
 func (mfs *MemoryFS) ReadDir(name string) iter.Seq2[DirEntry, error] {
 	return func(yield func(DirEntry, error) bool) {
 		cleanName := path.Clean(name)
 
 		// Check if directory exists
 		if cleanName != "." && !mfs.dirs[cleanName] {
-			yield(nil, fmt.Errorf("directory not found: %s", name))
+			yield(nil, fmt.Errorf("directory %w: %s", ErrNotFound, name))
 			return
 		}
 
@@ -194,6 +204,16 @@ func (mfs *MemoryFS) Walk(root string, fn WalkFunc) error {
 	return walkDir(cleanRoot)
 }
 
+func (mfs *MemoryFS) Glob(pattern string) ([]string, error) {
+	return globReadDir(mfs, pattern)
+}
+
+func (mfs *MemoryFS) Exists(name string) bool {
+	cleanName := path.Clean(name)
+	_, isFile := mfs.files[cleanName]
+	return isFile || mfs.dirs[cleanName] || cleanName == "."
+}
+
 func (mfs *MemoryFS) Join(elem ...string) string {
 	return path.Join(elem...)
 }
@@ -260,7 +280,7 @@ func (e *memoryDirEntry) Info() (FileInfo, error) {
 
 	content, exists := e.mfs.files[e.fullPath]
 	if !exists {
-		return nil, fmt.Errorf("file not found: %s", e.fullPath)
+		return nil, fmt.Errorf("file %w: %s", ErrNotFound, e.fullPath)
 	}
 
 	return &memoryFileInfo{
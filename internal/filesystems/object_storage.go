@@ -0,0 +1,195 @@
+package filesystems
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxObjectSize is the largest zip NewObjectStorageFS downloads
+var MaxObjectSize int64 = 1 << 30
+
+// NewObjectStorageFS reads an archive from S3 (s3://bucket/key.tar.gz) or Google
+// Cloud Storage (gs://bucket/key.zip). Like any ArchiveFS its files are held in
+// memory. Tarballs are read from the response as it downloads, but zips need random
+// access, so they're spooled to a temporary file first, up to MaxObjectSize.
+//
+// S3 requests are signed with AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY when set,
+// in AWS_REGION, against AWS_ENDPOINT_URL_S3 for S3-compatible stores. GCS requests
+// use GOOGLE_OAUTH_ACCESS_TOKEN when set. Without credentials objects must be public.
+func NewObjectStorageFS(ctx context.Context, uri string) (*ArchiveFS, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URI %s: %w", uri, err)
+	}
+	bucket, key := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid object URL format, expected: %s://bucket/key.tar.gz", u.Scheme)
+	}
+	if !IsArchive(key) {
+		return nil, fmt.Errorf("unsupported object %s, expected a .zip, .tar.gz or .tgz archive", key)
+	}
+
+	var req *http.Request
+	switch u.Scheme {
+	case "s3":
+		req, err = newS3Request(ctx, bucket, key)
+	case "gs":
+		req, err = newGCSRequest(ctx, bucket, key)
+	default:
+		return nil, fmt.Errorf("unsupported scheme: %s", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, networkError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %w", uri, &StatusError{resp.StatusCode})
+	}
+
+	if !strings.HasSuffix(strings.ToLower(key), ".zip") {
+		return ReadTarball(resp.Body)
+	}
+	spool, err := os.CreateTemp("", "turnout-object-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	size, err := io.Copy(spool, io.LimitReader(resp.Body, MaxObjectSize+1))
+	if err != nil {
+		return nil, networkError(err)
+	}
+	if size > MaxObjectSize {
+		return nil, fmt.Errorf("%w: %s is over %d bytes", ErrFileTooLarge, uri, MaxObjectSize)
+	}
+	return ReadZip(spool, size)
+}
+
+func newGCSRequest(ctx context.Context, bucket, key string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://storage.googleapis.com/%s/%s", bucket, awsURIEncode(key, false)), nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+func newS3Request(ctx context.Context, bucket, key string) (*http.Request, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	// Virtual-hosted buckets on AWS, path-style on S3-compatible endpoints like MinIO
+	objectURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, awsURIEncode(key, false))
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL_S3"); endpoint != "" {
+		objectURL = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(endpoint, "/"), bucket, awsURIEncode(key, false))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", objectURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); accessKey != "" && secretKey != "" {
+		signS3Request(req, region, accessKey, secretKey, os.Getenv("AWS_SESSION_TOKEN"), time.Now())
+	}
+	return req, nil
+}
+
+// emptySHA256 is the hex SHA-256 of an empty payload, which GET requests have
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// signS3Request signs a bodyless S3 request with AWS Signature Version 4
+func signS3Request(req *http.Request, region, accessKey, secretKey, sessionToken string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptySHA256)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptySHA256,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsURIEncode percent-encodes everything but unreserved characters, and slashes
+// unless encodeSlash is set, as Signature Version 4 requires
+func awsURIEncode(s string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, b := range []byte(s) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~':
+			encoded.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			encoded.WriteByte(b)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
@@ -0,0 +1,90 @@
+package filesystems
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long changes must settle before a batch is delivered, so saving
+// several files or a git checkout triggers one batch rather than dozens
+const watchDebounce = 250 * time.Millisecond
+
+// Watch reports changes under root in batches of changed paths, until ctx is done.
+// Directories skipDir returns true for, by base name, aren't watched.
+func (lfs *LocalFS) Watch(ctx context.Context, root string, skipDir func(name string) bool) (<-chan []string, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	addTree := func(dir string) error {
+		return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || !entry.IsDir() {
+				return nil // Vanished while walking, or a file
+			}
+			if path != dir && skipDir(entry.Name()) {
+				return filepath.SkipDir
+			}
+			return watcher.Add(path)
+		})
+	}
+	if err := addTree(root); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	batches := make(chan []string)
+	go func() {
+		defer close(batches)
+		defer watcher.Close()
+
+		var pending []string
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// New directories need watching too
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if skipDir(info.Name()) {
+							continue
+						}
+						_ = addTree(event.Name)
+					}
+				}
+				if !slices.Contains(pending, event.Name) {
+					pending = append(pending, event.Name)
+				}
+				timer.Reset(watchDebounce)
+
+			case <-timer.C:
+				select {
+				case batches <- pending:
+				case <-ctx.Done():
+					return
+				}
+				pending = nil
+
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return batches, nil
+}
@@ -0,0 +1,71 @@
+package railway
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Applied is the project apply created
+type Applied struct {
+	ProjectID     string
+	EnvironmentID string
+	Domains       map[string]string // service name -> generated domain
+}
+
+// Apply creates the planned project, reporting each step to progress
+func (c *Client) Apply(ctx context.Context, plan *Plan, progress func(step string)) (*Applied, error) {
+	project, err := c.CreateProject(ctx, plan.ProjectName, plan.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("create project: %w", err)
+	}
+	progress(fmt.Sprintf("Created project %s", project.Name))
+
+	environmentID, ok := project.Environments["production"]
+	if !ok {
+		for _, id := range project.Environments {
+			environmentID = id
+			break
+		}
+	}
+	if environmentID == "" {
+		return nil, fmt.Errorf("project %s has no environment", project.ID)
+	}
+
+	applied := &Applied{ProjectID: project.ID, EnvironmentID: environmentID, Domains: make(map[string]string)}
+	for _, service := range plan.Services {
+		serviceID, err := c.CreateService(ctx, project.ID, service.Name, service.Source)
+		if err != nil {
+			return applied, fmt.Errorf("create service %s: %w", service.Name, err)
+		}
+		progress(fmt.Sprintf("Created service %s", service.Name))
+
+		if !reflect.ValueOf(service.Instance).IsZero() {
+			if err := c.UpdateServiceInstance(ctx, serviceID, environmentID, service.Instance); err != nil {
+				return applied, fmt.Errorf("configure service %s: %w", service.Name, err)
+			}
+		}
+		for _, mountPath := range service.Volumes {
+			if err := c.CreateVolume(ctx, project.ID, serviceID, environmentID, mountPath); err != nil {
+				return applied, fmt.Errorf("create volume for %s: %w", service.Name, err)
+			}
+			progress(fmt.Sprintf("Created volume at %s for %s", mountPath, service.Name))
+		}
+		if len(service.Variables) > 0 {
+			if err := c.UpsertVariables(ctx, project.ID, serviceID, environmentID, service.Variables); err != nil {
+				return applied, fmt.Errorf("set variables for %s: %w", service.Name, err)
+			}
+			progress(fmt.Sprintf("Set %d variables for %s", len(service.Variables), service.Name))
+		}
+		if service.Public {
+			domain, err := c.CreateDomain(ctx, serviceID, environmentID, service.Port)
+			if err != nil {
+				return applied, fmt.Errorf("create domain for %s: %w", service.Name, err)
+			}
+			applied.Domains[service.Name] = domain
+			progress(fmt.Sprintf("Created domain %s for %s", domain, service.Name))
+		}
+	}
+
+	return applied, nil
+}
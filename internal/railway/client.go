@@ -0,0 +1,220 @@
+package railway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is Railway's public GraphQL API
+const DefaultEndpoint = "https://backboard.railway.com/graphql/v2"
+
+// Client talks to Railway's public GraphQL API with an account or workspace token
+type Client struct {
+	endpoint   string
+	token      string
+	httpClient *http.Client
+}
+
+func NewClient(token string) *Client {
+	return &Client{
+		endpoint:   DefaultEndpoint,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// WithEndpoint points the client at another API endpoint, e.g. a test server
+func (c *Client) WithEndpoint(endpoint string) *Client {
+	c.endpoint = endpoint
+	return c
+}
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Do runs a GraphQL query or mutation, decoding its data into out
+func (c *Client) Do(ctx context.Context, query string, variables map[string]any, out any) error {
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("railway API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("railway API returned %s: %s", resp.Status, strings.TrimSpace(string(content)))
+	}
+
+	var result graphQLResponse
+	if err := json.Unmarshal(content, &result); err != nil {
+		return fmt.Errorf("invalid railway API response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			messages[i] = e.Message
+		}
+		return errors.New("railway API: " + strings.Join(messages, "; "))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+// Project is a created Railway project and its environments
+type Project struct {
+	ID           string
+	Name         string
+	Environments map[string]string // environment name -> ID
+}
+
+func (c *Client) CreateProject(ctx context.Context, name, workspaceID string) (*Project, error) {
+	input := map[string]any{"name": name}
+	if workspaceID != "" {
+		input["workspaceId"] = workspaceID
+	}
+
+	var data struct {
+		ProjectCreate struct {
+			ID           string `json:"id"`
+			Name         string `json:"name"`
+			Environments struct {
+				Edges []struct {
+					Node struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"node"`
+				} `json:"edges"`
+			} `json:"environments"`
+		} `json:"projectCreate"`
+	}
+	err := c.Do(ctx, `mutation($input: ProjectCreateInput!) {
+  projectCreate(input: $input) { id name environments { edges { node { id name } } } }
+}`, map[string]any{"input": input}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &Project{ID: data.ProjectCreate.ID, Name: data.ProjectCreate.Name, Environments: make(map[string]string)}
+	for _, edge := range data.ProjectCreate.Environments.Edges {
+		project.Environments[edge.Node.Name] = edge.Node.ID
+	}
+	return project, nil
+}
+
+// ServiceSource is where a service deploys from: a GitHub repo or a Docker image
+type ServiceSource struct {
+	Repo  string `json:"repo,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+func (c *Client) CreateService(ctx context.Context, projectID, name string, source *ServiceSource) (string, error) {
+	input := map[string]any{"projectId": projectID, "name": name}
+	if source != nil {
+		input["source"] = source
+	}
+
+	var data struct {
+		ServiceCreate struct {
+			ID string `json:"id"`
+		} `json:"serviceCreate"`
+	}
+	err := c.Do(ctx, `mutation($input: ServiceCreateInput!) {
+  serviceCreate(input: $input) { id }
+}`, map[string]any{"input": input}, &data)
+	return data.ServiceCreate.ID, err
+}
+
+// ServiceInstance holds a service's settings in one environment
+type ServiceInstance struct {
+	RootDirectory     string   `json:"rootDirectory,omitempty"`
+	Builder           string   `json:"builder,omitempty"`
+	DockerfilePath    string   `json:"dockerfilePath,omitempty"`
+	StartCommand      string   `json:"startCommand,omitempty"`
+	PreDeployCommand  []string `json:"preDeployCommand,omitempty"`
+	HealthcheckPath   string   `json:"healthcheckPath,omitempty"`
+	CronSchedule      string   `json:"cronSchedule,omitempty"`
+	Region            string   `json:"region,omitempty"`
+	NumReplicas       int      `json:"numReplicas,omitempty"`
+	RestartPolicyType string   `json:"restartPolicyType,omitempty"`
+}
+
+func (c *Client) UpdateServiceInstance(ctx context.Context, serviceID, environmentID string, instance ServiceInstance) error {
+	return c.Do(ctx, `mutation($serviceId: String!, $environmentId: String!, $input: ServiceInstanceUpdateInput!) {
+  serviceInstanceUpdate(serviceId: $serviceId, environmentId: $environmentId, input: $input)
+}`, map[string]any{"serviceId": serviceID, "environmentId": environmentID, "input": instance}, nil)
+}
+
+func (c *Client) CreateVolume(ctx context.Context, projectID, serviceID, environmentID, mountPath string) error {
+	return c.Do(ctx, `mutation($input: VolumeCreateInput!) {
+  volumeCreate(input: $input) { id }
+}`, map[string]any{"input": map[string]any{
+		"projectId":     projectID,
+		"serviceId":     serviceID,
+		"environmentId": environmentID,
+		"mountPath":     mountPath,
+	}}, nil)
+}
+
+// UpsertVariables sets a service's variables without triggering a deploy for each one
+func (c *Client) UpsertVariables(ctx context.Context, projectID, serviceID, environmentID string, variables map[string]string) error {
+	return c.Do(ctx, `mutation($input: VariableCollectionUpsertInput!) {
+  variableCollectionUpsert(input: $input)
+}`, map[string]any{"input": map[string]any{
+		"projectId":     projectID,
+		"serviceId":     serviceID,
+		"environmentId": environmentID,
+		"variables":     variables,
+		"skipDeploys":   true,
+	}}, nil)
+}
+
+// CreateDomain generates a railway.app domain routing to the service's port
+func (c *Client) CreateDomain(ctx context.Context, serviceID, environmentID string, targetPort int) (string, error) {
+	input := map[string]any{"serviceId": serviceID, "environmentId": environmentID}
+	if targetPort != 0 {
+		input["targetPort"] = targetPort
+	}
+
+	var data struct {
+		ServiceDomainCreate struct {
+			Domain string `json:"domain"`
+		} `json:"serviceDomainCreate"`
+	}
+	err := c.Do(ctx, `mutation($input: ServiceDomainCreateInput!) {
+  serviceDomainCreate(input: $input) { domain }
+}`, map[string]any{"input": input}, &data)
+	return data.ServiceDomainCreate.Domain, err
+}
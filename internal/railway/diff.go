@@ -0,0 +1,193 @@
+package railway
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	envTypes "github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// ChangeKind is how a deployed project differs from the plan
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "+" // discovered but not deployed
+	ChangeRemoved ChangeKind = "-" // deployed but no longer discovered
+	ChangeChanged ChangeKind = "~" // deployed with a different value
+)
+
+// Change is one difference between a plan and a deployed project
+type Change struct {
+	Kind    ChangeKind
+	Service string
+	Field   string // e.g. "start command" or "variable PORT", empty for the service itself
+	Planned string // secret values are masked
+	Live    string
+}
+
+const maskedValue = "********"
+
+// Diff reports how a deployed project has drifted from the plan. Variables Railway
+// provides itself, like RAILWAY_PUBLIC_DOMAIN, are ignored.
+func Diff(plan *Plan, live *LiveProject) []Change {
+	var changes []Change
+	deployed := make(map[string]LiveService)
+	for _, service := range live.Services {
+		deployed[service.Name] = service
+	}
+
+	for _, service := range plan.Services {
+		liveService, ok := deployed[service.Name]
+		if !ok {
+			changes = append(changes, Change{Kind: ChangeAdded, Service: service.Name})
+			continue
+		}
+		delete(deployed, service.Name)
+		changes = append(changes, diffService(service, liveService)...)
+	}
+
+	for _, service := range live.Services {
+		if _, ok := deployed[service.Name]; ok {
+			changes = append(changes, Change{Kind: ChangeRemoved, Service: service.Name})
+		}
+	}
+	return changes
+}
+
+func diffService(planned ServicePlan, live LiveService) []Change {
+	var changes []Change
+	compare := func(field, plannedValue, liveValue string) {
+		switch {
+		case plannedValue == liveValue:
+		case liveValue == "":
+			changes = append(changes, Change{Kind: ChangeAdded, Service: planned.Name, Field: field, Planned: plannedValue})
+		case plannedValue == "":
+			changes = append(changes, Change{Kind: ChangeRemoved, Service: planned.Name, Field: field, Live: liveValue})
+		default:
+			changes = append(changes, Change{Kind: ChangeChanged, Service: planned.Name, Field: field, Planned: plannedValue, Live: liveValue})
+		}
+	}
+
+	if planned.Source != nil {
+		compare("image", planned.Source.Image, live.Source.Image)
+		compare("repo", planned.Source.Repo, live.Source.Repo)
+	}
+
+	instance, liveInstance := planned.Instance, live.Instance
+	compare("root directory", strings.Trim(instance.RootDirectory, "/"), strings.Trim(liveInstance.RootDirectory, "/"))
+	compare("start command", instance.StartCommand, liveInstance.StartCommand)
+	compare("pre-deploy command", strings.Join(instance.PreDeployCommand, " && "), strings.Join(liveInstance.PreDeployCommand, " && "))
+	compare("healthcheck", instance.HealthcheckPath, liveInstance.HealthcheckPath)
+	compare("cron schedule", instance.CronSchedule, liveInstance.CronSchedule)
+	compare("replicas", strconv.Itoa(max(instance.NumReplicas, 1)), strconv.Itoa(max(liveInstance.NumReplicas, 1)))
+
+	// Railway fills in defaults for these, so only settings the plan makes count
+	if instance.Builder != "" {
+		compare("builder", instance.Builder, liveInstance.Builder)
+	}
+	if instance.DockerfilePath != "" {
+		compare("dockerfile", instance.DockerfilePath, liveInstance.DockerfilePath)
+	}
+	if instance.Region != "" {
+		compare("region", instance.Region, liveInstance.Region)
+	}
+
+	for _, mountPath := range planned.Volumes {
+		if !slices.Contains(live.Volumes, mountPath) {
+			changes = append(changes, Change{Kind: ChangeAdded, Service: planned.Name, Field: "volume " + mountPath})
+		}
+	}
+	for _, mountPath := range live.Volumes {
+		if !slices.Contains(planned.Volumes, mountPath) {
+			changes = append(changes, Change{Kind: ChangeRemoved, Service: planned.Name, Field: "volume " + mountPath})
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(planned.Variables)) {
+		plannedValue, liveValue := planned.Variables[name], live.Variables[name]
+		if plannedValue == liveValue {
+			continue
+		}
+		if slices.Contains(planned.Secrets, name) {
+			// Masked values look alike, so a changed secret can't go through compare
+			if plannedValue != "" && liveValue != "" {
+				changes = append(changes, Change{Kind: ChangeChanged, Service: planned.Name, Field: "variable " + name, Planned: maskedValue, Live: maskedValue})
+				continue
+			}
+			plannedValue, liveValue = mask(plannedValue), mask(liveValue)
+		}
+		compare("variable "+name, plannedValue, liveValue)
+	}
+	for _, name := range planned.Unset {
+		if _, ok := live.Variables[name]; !ok {
+			changes = append(changes, Change{Kind: ChangeAdded, Service: planned.Name, Field: "variable " + name})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(live.Variables)) {
+		if _, ok := planned.Variables[name]; ok || slices.Contains(planned.Unset, name) || strings.HasPrefix(name, "RAILWAY_") {
+			continue
+		}
+		// Nothing in the plan says whether these are secret, so their names and values do
+		liveValue := live.Variables[name]
+		if _, sensitive := envTypes.ClassifyEnvVar(name, liveValue); sensitive {
+			liveValue = mask(liveValue)
+		}
+		changes = append(changes, Change{Kind: ChangeRemoved, Service: planned.Name, Field: "variable " + name, Live: liveValue})
+	}
+
+	return changes
+}
+
+func mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	return maskedValue
+}
+
+// WriteChanges prints a drift report, grouped by service
+func WriteChanges(w io.Writer, changes []Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No drift, the deployed project matches the discovered services")
+		return
+	}
+
+	service := ""
+	for _, change := range changes {
+		if change.Field == "" {
+			switch change.Kind {
+			case ChangeAdded:
+				fmt.Fprintf(w, "+ service %s is not deployed\n", change.Service)
+			case ChangeRemoved:
+				fmt.Fprintf(w, "- service %s is deployed but wasn't discovered\n", change.Service)
+			}
+			service = ""
+			continue
+		}
+
+		if change.Service != service {
+			fmt.Fprintf(w, "~ service %s\n", change.Service)
+			service = change.Service
+		}
+		switch change.Kind {
+		case ChangeAdded:
+			if change.Planned == "" {
+				fmt.Fprintf(w, "    + %s\n", change.Field)
+			} else {
+				fmt.Fprintf(w, "    + %s: %s\n", change.Field, change.Planned)
+			}
+		case ChangeRemoved:
+			if change.Live == "" {
+				fmt.Fprintf(w, "    - %s\n", change.Field)
+			} else {
+				fmt.Fprintf(w, "    - %s: %s\n", change.Field, change.Live)
+			}
+		case ChangeChanged:
+			fmt.Fprintf(w, "    ~ %s: %s -> %s\n", change.Field, change.Live, change.Planned)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package railway
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/export"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+// Plan is everything apply creates in Railway for a project
+type Plan struct {
+	ProjectName string
+	WorkspaceID string
+	Services    []ServicePlan
+	Topology    []schema.Link // who could reach whom on the old platform, empty if it didn't restrict it
+}
+
+// ServicePlan is a service to create and how to configure it
+type ServicePlan struct {
+	Name      string
+	Source    *ServiceSource // nil for services deployed later, e.g. with `railway up`
+	Instance  ServiceInstance
+	Volumes   []string          // mount paths
+	Variables map[string]string // variables with known values
+	Secrets   []string          // names of sensitive variables, whose values are never printed
+	Unset     []string          // variables the service reads that have no value to set
+	BuildArgs []string          // variables only read while building, which Railway passes as build args
+	Public    bool
+	Port      int
+	Template  string            // Railway template the service stands in for, e.g. "postgres"
+	Resources *schema.Resources // the suggested size, nil if the old platform didn't say
+}
+
+// NewPlan plans a Railway project for a normalized project. Services built from source
+// deploy from repo, an "owner/name" GitHub repository, when given.
+func NewPlan(project *schema.Project, repo, workspaceID string) *Plan {
+	plan := &Plan{ProjectName: project.Name, WorkspaceID: workspaceID, Topology: project.Topology}
+
+	for _, service := range project.Services {
+		config := export.NewRailwayConfig(service)
+		servicePlan := ServicePlan{Name: service.Name, Variables: make(map[string]string), Resources: service.Resources}
+		if service.Managed != nil {
+			servicePlan.Template = service.Managed.Template
+		}
+
+		switch {
+		case service.Image != "":
+			servicePlan.Source = &ServiceSource{Image: service.Image}
+		case repo != "":
+			servicePlan.Source = &ServiceSource{Repo: repo}
+		}
+		if service.Image == "" && service.SourcePath != "." {
+			servicePlan.Instance.RootDirectory = service.SourcePath
+		}
+		if config.Build != nil {
+			servicePlan.Instance.Builder = config.Build.Builder
+			servicePlan.Instance.DockerfilePath = config.Build.DockerfilePath
+		}
+		if deploy := config.Deploy; deploy != nil {
+			servicePlan.Instance.StartCommand = deploy.StartCommand
+			servicePlan.Instance.PreDeployCommand = deploy.PreDeployCommand
+			servicePlan.Instance.HealthcheckPath = deploy.HealthcheckPath
+			servicePlan.Instance.CronSchedule = deploy.CronSchedule
+			servicePlan.Instance.Region = deploy.Region
+			servicePlan.Instance.NumReplicas = deploy.NumReplicas
+			servicePlan.Instance.RestartPolicyType = deploy.RestartPolicyType
+		}
+
+		for _, volume := range service.Volumes {
+			servicePlan.Volumes = append(servicePlan.Volumes, volume.MountPath)
+		}
+		for _, port := range service.Ports {
+			servicePlan.Public = servicePlan.Public || port.IsPublic
+			if servicePlan.Port == 0 {
+				servicePlan.Port = port.Number
+			}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(service.Environment)) {
+			envVar := service.Environment[name]
+			if envVar.Sensitive {
+				servicePlan.Secrets = append(servicePlan.Secrets, name)
+			}
+			if envVar.BuildOnly {
+				servicePlan.BuildArgs = append(servicePlan.BuildArgs, name)
+			}
+			value := envVar.RailwayValue() // References to other services, like ${{db.DATABASE_URL}}
+			if value == "" {
+				servicePlan.Unset = append(servicePlan.Unset, name)
+				continue
+			}
+			servicePlan.Variables[name] = value
+		}
+
+		plan.Services = append(plan.Services, servicePlan)
+	}
+
+	return plan
+}
+
+// Write prints the plan for review, masking secret values
+func (p *Plan) Write(w io.Writer) {
+	fmt.Fprintf(w, "+ project %s\n", p.ProjectName)
+
+	for _, service := range p.Services {
+		switch {
+		case service.Source == nil:
+			fmt.Fprintf(w, "  + service %s (no source, deploy with `railway up`)\n", service.Name)
+		case service.Template != "":
+			fmt.Fprintf(w, "  + service %s from image %s (Railway %s)\n", service.Name, service.Source.Image, service.Template)
+		case service.Source.Image != "":
+			fmt.Fprintf(w, "  + service %s from image %s\n", service.Name, service.Source.Image)
+		default:
+			fmt.Fprintf(w, "  + service %s from repo %s\n", service.Name, service.Source.Repo)
+		}
+
+		instance := service.Instance
+		for _, setting := range []struct{ name, value string }{
+			{"root directory", instance.RootDirectory},
+			{"builder", instance.Builder},
+			{"dockerfile", instance.DockerfilePath},
+			{"start command", instance.StartCommand},
+			{"pre-deploy command", strings.Join(instance.PreDeployCommand, " && ")},
+			{"healthcheck", instance.HealthcheckPath},
+			{"cron schedule", instance.CronSchedule},
+			{"region", instance.Region},
+		} {
+			if setting.value != "" {
+				fmt.Fprintf(w, "      %s: %s\n", setting.name, setting.value)
+			}
+		}
+		if instance.NumReplicas > 0 {
+			fmt.Fprintf(w, "      replicas: %d\n", instance.NumReplicas)
+		}
+		if resources := service.Resources; resources != nil {
+			switch size := formatSize(resources); {
+			case size == "":
+			case resources.Plan == "":
+				fmt.Fprintf(w, "    ! needs %s, more than any Railway plan allows a service\n", size)
+			default:
+				fmt.Fprintf(w, "      size: %s (%s plan or above)\n", size, resources.Plan)
+			}
+		}
+
+		for _, mountPath := range service.Volumes {
+			fmt.Fprintf(w, "    + volume at %s\n", mountPath)
+		}
+		if service.Public {
+			if service.Port != 0 {
+				fmt.Fprintf(w, "    + public domain -> port %d\n", service.Port)
+			} else {
+				fmt.Fprintf(w, "    + public domain\n")
+			}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(service.Variables)) {
+			value := service.Variables[name]
+			if slices.Contains(service.Secrets, name) {
+				value = "******** (secret)"
+			}
+			if slices.Contains(service.BuildArgs, name) {
+				value += " (build arg)"
+			}
+			fmt.Fprintf(w, "    + variable %s = %s\n", name, value)
+		}
+		for _, name := range service.Unset {
+			marker := ""
+			if slices.Contains(service.Secrets, name) {
+				marker = " (secret)"
+			}
+			if slices.Contains(service.BuildArgs, name) {
+				marker += " (build arg)"
+			}
+			fmt.Fprintf(w, "    ! variable %s has no value, set it in Railway%s\n", name, marker)
+		}
+	}
+
+	p.writeTopology(w)
+}
+
+// writeTopology prints who could reach whom on the old platform, and the services it
+// kept others away from, since every service in a Railway project can reach every other
+// over the private network
+func (p *Plan) writeTopology(w io.Writer) {
+	if len(p.Topology) == 0 {
+		return
+	}
+
+	reaches := make(map[string][]string)
+	reachedBy := make(map[string][]string)
+	for _, link := range p.Topology {
+		reaches[link.From] = append(reaches[link.From], link.To)
+		reachedBy[link.To] = append(reachedBy[link.To], link.From)
+	}
+
+	fmt.Fprintf(w, "  private network, as the old platform allowed it:\n")
+	for _, service := range p.Services {
+		if targets := reaches[service.Name]; len(targets) > 0 {
+			fmt.Fprintf(w, "      %s -> %s\n", service.Name, strings.Join(targets, ", "))
+		}
+	}
+	for _, service := range p.Services {
+		switch sources := reachedBy[service.Name]; {
+		case len(sources) == 0:
+			fmt.Fprintf(w, "    ! %s was unreachable from other services, but every service in a Railway project can reach it\n", service.Name)
+		case len(sources) < len(p.Services)-1:
+			fmt.Fprintf(w, "    ! %s was only reachable from %s, but every service in a Railway project can reach it\n", service.Name, strings.Join(sources, ", "))
+		}
+	}
+}
+
+// formatSize describes a service's suggested resource limits, like 2 vCPU, 4 GB
+func formatSize(resources *schema.Resources) string {
+	var parts []string
+	if resources.VCPU > 0 {
+		parts = append(parts, fmt.Sprintf("%d vCPU", resources.VCPU))
+	}
+	if resources.MemoryGB > 0 {
+		parts = append(parts, fmt.Sprintf("%d GB", resources.MemoryGB))
+	}
+	return strings.Join(parts, ", ")
+}
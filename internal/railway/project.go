@@ -0,0 +1,141 @@
+package railway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// LiveProject is a deployed Railway project's services in one environment
+type LiveProject struct {
+	ID            string
+	Name          string
+	EnvironmentID string
+	Services      []LiveService
+}
+
+// LiveService is a deployed service, its settings and variables
+type LiveService struct {
+	ID        string
+	Name      string
+	Source    ServiceSource
+	Instance  ServiceInstance
+	Volumes   []string // mount paths
+	Variables map[string]string
+}
+
+// FetchProject loads a project's services, settings and variables in the named environment
+func (c *Client) FetchProject(ctx context.Context, projectID, environment string) (*LiveProject, error) {
+	var data struct {
+		Project struct {
+			ID           string `json:"id"`
+			Name         string `json:"name"`
+			Environments struct {
+				Edges []struct {
+					Node struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"node"`
+				} `json:"edges"`
+			} `json:"environments"`
+			Services struct {
+				Edges []struct {
+					Node struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"node"`
+				} `json:"edges"`
+			} `json:"services"`
+			Volumes struct {
+				Edges []struct {
+					Node struct {
+						VolumeInstances struct {
+							Edges []struct {
+								Node struct {
+									MountPath     string `json:"mountPath"`
+									ServiceID     string `json:"serviceId"`
+									EnvironmentID string `json:"environmentId"`
+								} `json:"node"`
+							} `json:"edges"`
+						} `json:"volumeInstances"`
+					} `json:"node"`
+				} `json:"edges"`
+			} `json:"volumes"`
+		} `json:"project"`
+	}
+	err := c.Do(ctx, `query($id: String!) {
+  project(id: $id) {
+    id name
+    environments { edges { node { id name } } }
+    services { edges { node { id name } } }
+    volumes { edges { node { volumeInstances { edges { node { mountPath serviceId environmentId } } } } } }
+  }
+}`, map[string]any{"id": projectID}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &LiveProject{ID: data.Project.ID, Name: data.Project.Name}
+	for _, edge := range data.Project.Environments.Edges {
+		if edge.Node.Name == environment {
+			project.EnvironmentID = edge.Node.ID
+		}
+	}
+	if project.EnvironmentID == "" {
+		return nil, fmt.Errorf("project %s has no %s environment", projectID, environment)
+	}
+
+	for _, edge := range data.Project.Services.Edges {
+		service, err := c.fetchService(ctx, project, edge.Node.ID, edge.Node.Name)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", edge.Node.Name, err)
+		}
+		for _, volume := range data.Project.Volumes.Edges {
+			for _, instance := range volume.Node.VolumeInstances.Edges {
+				if instance.Node.ServiceID == service.ID && instance.Node.EnvironmentID == project.EnvironmentID {
+					service.Volumes = append(service.Volumes, instance.Node.MountPath)
+				}
+			}
+		}
+		project.Services = append(project.Services, *service)
+	}
+	return project, nil
+}
+
+func (c *Client) fetchService(ctx context.Context, project *LiveProject, serviceID, name string) (*LiveService, error) {
+	var data struct {
+		ServiceInstance struct {
+			ServiceInstance
+			PreDeployCommand json.RawMessage `json:"preDeployCommand"`
+			Source           *ServiceSource  `json:"source"`
+		} `json:"serviceInstance"`
+		Variables map[string]string `json:"variables"`
+	}
+	err := c.Do(ctx, `query($projectId: String!, $environmentId: String!, $serviceId: String!) {
+  serviceInstance(environmentId: $environmentId, serviceId: $serviceId) {
+    rootDirectory builder dockerfilePath startCommand preDeployCommand healthcheckPath
+    cronSchedule region numReplicas restartPolicyType
+    source { image repo }
+  }
+  variables(projectId: $projectId, environmentId: $environmentId, serviceId: $serviceId)
+}`, map[string]any{"projectId": project.ID, "environmentId": project.EnvironmentID, "serviceId": serviceID}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	service := &LiveService{ID: serviceID, Name: name, Instance: data.ServiceInstance.ServiceInstance, Variables: data.Variables}
+	if data.ServiceInstance.Source != nil {
+		service.Source = *data.ServiceInstance.Source
+	}
+
+	// The pre-deploy command is a string or a list of commands
+	var commands []string
+	if err := json.Unmarshal(data.ServiceInstance.PreDeployCommand, &commands); err != nil {
+		var command string
+		if json.Unmarshal(data.ServiceInstance.PreDeployCommand, &command) == nil && command != "" {
+			commands = []string{command}
+		}
+	}
+	service.Instance.PreDeployCommand = commands
+	return service, nil
+}
@@ -0,0 +1,68 @@
+package schema
+
+import (
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// NewProjectFromServices normalizes discovered services into a project, with source
+// and Dockerfile paths relative to the project root
+func NewProjectFromServices(name string, filesystem filesystems.FileSystem, rootPath string, services []types.Service) *Project {
+	project := NewProject(name)
+	relative := func(path string) string {
+		rel, err := filesystem.Rel(rootPath, path)
+		if err != nil {
+			return path
+		}
+		return rel
+	}
+
+	for _, discovered := range services {
+		service := NewService(discovered.Name)
+		service.StartCommand = discovered.StartCommand
+		service.PreDeployCommand = discovered.PreDeployCommand
+		service.HealthcheckPath = discovered.HealthcheckPath
+		service.Schedule = discovered.Schedule
+		service.Replicas = discovered.Replicas
+		service.Region = discovered.Region
+		service.Derived = discovered.Derived
+		if resources := discovered.Resources; resources != nil {
+			service.Resources = &Resources{CPU: resources.CPU, MemoryMB: resources.MemoryMB}
+		}
+		service.Networks = discovered.Networks
+		if ingress := discovered.Ingress; ingress != nil {
+			service.Ingress = &IngressPolicy{From: ingress.From}
+		}
+		if api := discovered.API; api != nil {
+			service.API = &API{Type: api.Type, Version: api.Version, SpecPath: relative(api.SpecPath)}
+		}
+
+		if discovered.Build == types.BuildFromImage {
+			service.Image = discovered.Image
+		} else {
+			service.SourcePath = relative(discovered.BuildPath)
+			service.PackageManager = string(discovered.PackageManager)
+			if discovered.Build == types.BuildStatic {
+				service.Static, service.OutputDir = true, discovered.OutputDir
+			}
+			for _, config := range discovered.Configs {
+				if config.Type == "dockerfile" {
+					service.Dockerfile = relative(config.Path)
+					break
+				}
+			}
+		}
+
+		// Public services keep a port entry even when the number is unknown (0)
+		if discovered.Port != 0 || discovered.Network == types.NetworkPublic {
+			service.Ports = append(service.Ports, NewPort(discovered.Port, discovered.Network == types.NetworkPublic))
+		}
+		for _, volume := range discovered.Volumes {
+			service.Volumes = append(service.Volumes, Volume{Name: volume.Name, MountPath: volume.MountPath})
+		}
+
+		project.AddService(service)
+	}
+
+	return project
+}
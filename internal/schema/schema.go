@@ -4,6 +4,13 @@ package schema
 type Project struct {
 	Name     string    `json:"name"`
 	Services []Service `json:"services"`
+	Topology []Link    `json:"topology,omitempty"` // who can reach whom privately, when the old platform restricted it
+}
+
+// Link is a service that can connect to another over the private network
+type Link struct {
+	From string `json:"from"`
+	To   string `json:"to"`
 }
 
 // Service represents a deployable workload
@@ -11,15 +18,85 @@ type Service struct {
 	Name         string            `json:"name"`
 	Image        string            `json:"image,omitempty"`
 	SourcePath   string            `json:"sourcePath,omitempty"`
+	Dockerfile   string            `json:"dockerfile,omitempty"`
 	Environment  map[string]EnvVar `json:"environment,omitempty"`
 	Ports        []Port            `json:"ports,omitempty"`
 	Dependencies []string          `json:"dependencies,omitempty"`
+
+	PackageManager string `json:"packageManager,omitempty"` // like pnpm or uv, from the lockfile the source has
+	Static         bool   `json:"static,omitempty"`         // built to static files and served by a file server
+	OutputDir      string `json:"outputDir,omitempty"`      // where a static build writes its files, relative to SourcePath
+
+	StartCommand     string     `json:"startCommand,omitempty"`
+	PreDeployCommand string     `json:"preDeployCommand,omitempty"`
+	HealthcheckPath  string     `json:"healthcheckPath,omitempty"`
+	Schedule         string     `json:"schedule,omitempty"`
+	Replicas         int        `json:"replicas,omitempty"`
+	Region           string     `json:"region,omitempty"`
+	Volumes          []Volume   `json:"volumes,omitempty"`
+	Resources        *Resources `json:"resources,omitempty"` // per replica, when the old platform said
+
+	Networks []string       `json:"networks,omitempty"` // private networks the service joined on the old platform
+	Ingress  *IngressPolicy `json:"ingress,omitempty"`  // which services could connect to it, when something restricted them
+
+	Managed *Managed `json:"managed,omitempty"` // set when a Railway database replaces the image
+	API     *API     `json:"api,omitempty"`     // the API the service serves, when it ships a spec
+
+	Derived bool `json:"derived,omitempty"` // implied by evidence like migrations rather than declared in a config
+}
+
+// IngressPolicy lists the services allowed to connect to a service, like a Kubernetes
+// NetworkPolicy does
+type IngressPolicy struct {
+	From []string `json:"from"`
+}
+
+// Resources is the CPU and memory a service had on the platform it came from, and the
+// Railway resource limits suggested for it
+type Resources struct {
+	CPU      float64 `json:"cpu,omitempty"` // vCPUs
+	MemoryMB int     `json:"memoryMB,omitempty"`
+
+	VCPU     int    `json:"vcpu,omitempty"`     // suggested CPU limit
+	MemoryGB int    `json:"memoryGB,omitempty"` // suggested memory limit
+	Plan     string `json:"plan,omitempty"`     // the smallest Railway plan allowing the limits, empty if none does
+}
+
+// API is a spec for the API a service serves, for healthchecks and documentation
+type API struct {
+	Type     string `json:"type"`              // "openapi", "swagger" or "graphql"
+	Version  string `json:"version,omitempty"` // the spec's OpenAPI or Swagger version
+	SpecPath string `json:"specPath"`          // relative to the project root
 }
 
 // EnvVar represents an environment variable with metadata
 type EnvVar struct {
-	Value     string `json:"value"`
-	Sensitive bool   `json:"sensitive"`
+	Value     string        `json:"value"`
+	Sensitive bool          `json:"sensitive"`
+	Reference *EnvReference `json:"reference,omitempty"` // set when the value comes from another service
+	BuildOnly bool          `json:"buildOnly,omitempty"` // only read while building, like a Dockerfile ARG
+}
+
+// EnvReference points a variable at another service, so exports can emit a Railway
+// reference variable instead of a value that only resolved in the old deployment
+type EnvReference struct {
+	Service  string `json:"service"`
+	Variable string `json:"variable"`         // the other service's variable, like DATABASE_URL or RAILWAY_PRIVATE_DOMAIN
+	Prefix   string `json:"prefix,omitempty"` // text around the reference, like http:// and :8080 in a URL
+	Suffix   string `json:"suffix,omitempty"`
+}
+
+// String renders the reference as a Railway reference variable, like ${{api.RAILWAY_PRIVATE_DOMAIN}}
+func (r EnvReference) String() string {
+	return r.Prefix + "${{" + r.Service + "." + r.Variable + "}}" + r.Suffix
+}
+
+// RailwayValue is the value to set on Railway: the reference when there is one
+func (e EnvVar) RailwayValue() string {
+	if e.Reference != nil {
+		return e.Reference.String()
+	}
+	return e.Value
 }
 
 // Port represents a network port configuration
@@ -28,6 +105,19 @@ type Port struct {
 	IsPublic bool `json:"isPublic"`
 }
 
+// Volume represents persistent storage mounted into a service
+type Volume struct {
+	Name      string `json:"name,omitempty"`
+	MountPath string `json:"mountPath"`
+}
+
+// Managed identifies the Railway database or template a service is provisioned from
+type Managed struct {
+	Kind     string `json:"kind"`              // e.g. "postgres"
+	Template string `json:"template"`          // Railway template name
+	Version  string `json:"version,omitempty"` // version from the original image tag, if any
+}
+
 // Constructors
 
 func NewProject(name string) *Project {
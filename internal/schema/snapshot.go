@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// SnapshotVersion is the version of the snapshot format written by this build.
+// Bump it when a change to Project would be misread by older builds.
+const SnapshotVersion = 1
+
+// SnapshotFile is the conventional name for a snapshot
+const SnapshotFile = "turnout.plan.json"
+
+// Snapshot is a normalized project saved between pipeline stages, so discovery can
+// run once and export or apply later, or be reviewed in a pull request
+type Snapshot struct {
+	Version  int      `json:"version"`
+	Source   string   `json:"source"`             // the source path or URL the project was discovered from
+	Revision string   `json:"revision,omitempty"` // the commit SHA of the source, when known
+	Project  *Project `json:"project"`
+}
+
+// NewSnapshot snapshots a project. Sensitive values are dropped so snapshots are
+// safe to commit; they're listed with an empty value to be set at deploy time.
+func NewSnapshot(source string, project *Project) *Snapshot {
+	return &Snapshot{Version: SnapshotVersion, Source: source, Project: project.Redacted()}
+}
+
+// Write encodes the snapshot as indented JSON
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// ReadSnapshot decodes a snapshot, rejecting versions this build doesn't understand
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	switch {
+	case snapshot.Version == 0:
+		return nil, fmt.Errorf("invalid snapshot: missing version")
+	case snapshot.Version > SnapshotVersion:
+		return nil, fmt.Errorf("snapshot version %d is newer than this version of turnout supports (%d)", snapshot.Version, SnapshotVersion)
+	case snapshot.Project == nil:
+		return nil, fmt.Errorf("invalid snapshot: missing project")
+	}
+
+	for i := range snapshot.Project.Services {
+		if snapshot.Project.Services[i].Environment == nil {
+			snapshot.Project.Services[i].Environment = make(map[string]EnvVar)
+		}
+	}
+	return &snapshot, nil
+}
+
+// Redacted copies the project with sensitive values emptied, leaving the project
+// itself untouched. References to other services' variables are kept, since they
+// name a variable rather than hold its value.
+func (p *Project) Redacted() *Project {
+	redacted := *p
+	redacted.Services = make([]Service, len(p.Services))
+	for i, service := range p.Services {
+		service.Environment = maps.Clone(service.Environment)
+		for name, envVar := range service.Environment {
+			if envVar.Sensitive {
+				envVar.Value = ""
+				service.Environment[name] = envVar
+			}
+		}
+		service.Volumes = slices.Clone(service.Volumes)
+		redacted.Services[i] = service
+	}
+	return &redacted
+}
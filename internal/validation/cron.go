@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names for min, min+1, ...
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+var cronMacros = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+// ParseCron checks a five-field cron expression, like "*/5 * * * 1-5", or a macro like @daily
+func ParseCron(expression string) error {
+	fields := strings.Fields(expression)
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "@") {
+		for _, macro := range cronMacros {
+			if strings.EqualFold(fields[0], macro) {
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown schedule %s", fields[0])
+	}
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("expected %d fields, got %d", len(cronFields), len(fields))
+	}
+
+	for i, field := range fields {
+		if err := cronFields[i].parse(field); err != nil {
+			return fmt.Errorf("%s: %w", cronFields[i].name, err)
+		}
+	}
+	return nil
+}
+
+func (f cronField) parse(field string) error {
+	for _, item := range strings.Split(field, ",") {
+		rangePart, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n <= 0 {
+				return fmt.Errorf("invalid step %q", step)
+			}
+		}
+
+		if rangePart == "*" || rangePart == "?" {
+			continue
+		}
+		low, high, isRange := strings.Cut(rangePart, "-")
+		start, err := f.value(low)
+		if err != nil {
+			return err
+		}
+		if !isRange {
+			continue
+		}
+		end, err := f.value(high)
+		if err != nil {
+			return err
+		}
+		if start > end {
+			return fmt.Errorf("range %s is backwards", rangePart)
+		}
+	}
+	return nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("%d is out of range %d-%d", n, f.min, f.max)
+	}
+	return n, nil
+}
@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+)
+
+// UnsupportedPlatforms finds services that need an OS other than Linux, like those
+// built on Windows base images or targeting .NET Framework. Railway only runs Linux
+// containers, so these are errors: exporting them would plan deploys that can't start.
+func UnsupportedPlatforms(services []types.Service) []Issue {
+	var issues []Issue
+	for _, service := range services {
+		if service.Platform == "" {
+			continue
+		}
+
+		issue := Issue{
+			Severity:   SeverityError,
+			Code:       CodeUnsupportedPlatform,
+			Service:    service.Name,
+			Message:    fmt.Sprintf("needs %s containers, so it's not deployable as a Linux container", platformName(service.Platform)),
+			Suggestion: "rebuild it on a Linux base image",
+		}
+		if provenance, ok := service.ProvenanceOf("Platform"); ok {
+			kind, file, _ := strings.Cut(provenance.Source, ":")
+			issue.File = file
+			if kind == "package" {
+				issue.Message = "targets .NET Framework, which only runs on Windows, so it's not deployable as a Linux container"
+				issue.Suggestion = "port it to .NET 8 or later, which runs on Linux"
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// platformName capitalizes an OS the way people write it, like Windows
+func platformName(os string) string {
+	if os == "" {
+		return os
+	}
+	return strings.ToUpper(os[:1]) + os[1:]
+}
@@ -0,0 +1,207 @@
+package validation
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+)
+
+// publishedPortConflicts finds services publishing the same host port, as compose
+// files often do by mistake across overrides. Railway gives each service its own
+// domain, but the project won't run locally until one is remapped, so a free port is
+// suggested for each service after the first.
+func publishedPortConflicts(services []types.Service) []Issue {
+	taken := make(map[string]map[int]bool) // every host port published, by environment
+	for _, service := range services {
+		if taken[service.Environment] == nil {
+			taken[service.Environment] = make(map[int]bool)
+		}
+		for _, mapping := range service.PublishedPorts {
+			if low, high, ok := publishedRange(mapping.Published); ok {
+				for port := low; port <= high; port++ {
+					taken[service.Environment][port] = true
+				}
+			}
+		}
+	}
+
+	var issues []Issue
+	owners := make(map[string]map[int]string) // the first service to publish each port
+	for _, service := range services {
+		if owners[service.Environment] == nil {
+			owners[service.Environment] = make(map[int]string)
+		}
+		claimed := owners[service.Environment]
+
+		var conflicts, remaps []string
+		var others []string
+		for _, mapping := range service.PublishedPorts {
+			low, high, ok := publishedRange(mapping.Published)
+			if !ok {
+				continue
+			}
+			var owner string
+			for port := low; port <= high && owner == ""; port++ {
+				if claimed[port] != "" && claimed[port] != service.Name {
+					owner = claimed[port]
+				}
+			}
+			if owner == "" {
+				continue
+			}
+			conflicts = append(conflicts, mapping.Published)
+			if !slices.Contains(others, owner) {
+				others = append(others, owner)
+			}
+			start := freePortRange(taken[service.Environment], high+1, high-low+1)
+			remaps = append(remaps, fmt.Sprintf("%s:%d", formatRange(start, start+high-low), mapping.Target))
+		}
+		for _, mapping := range service.PublishedPorts {
+			if low, high, ok := publishedRange(mapping.Published); ok {
+				for port := low; port <= high; port++ {
+					if claimed[port] == "" {
+						claimed[port] = service.Name
+					}
+				}
+			}
+		}
+
+		if len(conflicts) > 0 {
+			issues = append(issues, Issue{
+				Severity:   SeverityWarning,
+				Code:       CodePublishedConflict,
+				Service:    service.Name,
+				Message:    fmt.Sprintf("publishes host port %s, which %s publishes too", strings.Join(conflicts, ", "), strings.Join(others, ", ")),
+				Suggestion: fmt.Sprintf("publish %s instead", strings.Join(remaps, ", ")),
+			})
+		}
+	}
+	return issues
+}
+
+// portRange warns about services listening on more than one port, as compose port
+// ranges like 8000-8010:8000-8010 do. A Railway domain routes to a single port, so
+// the rest need their own services or a TCP proxy.
+func portRange(service types.Service) []Issue {
+	targets := make(map[int]bool)
+	for _, mapping := range service.PublishedPorts {
+		if mapping.Target > 0 {
+			targets[mapping.Target] = true
+		}
+	}
+	if len(targets) < 2 {
+		return nil
+	}
+
+	ports := slices.Sorted(maps.Keys(targets))
+	primary := ports[0]
+	if targets[service.Port] {
+		primary = service.Port
+	}
+	return []Issue{{
+		Severity:   SeverityWarning,
+		Code:       CodePortRange,
+		Service:    service.Name,
+		Message:    fmt.Sprintf("publishes %d container ports (%s), but a Railway domain routes to one", len(ports), formatPorts(ports)),
+		Suggestion: fmt.Sprintf("serve on %d and move the other ports to their own services or a TCP proxy", primary),
+	}}
+}
+
+// privilegedPorts warns about services built from source listening below 1024, which
+// only root can bind. Images are left alone, since they're built to run as they are.
+func privilegedPorts(service types.Service) []Issue {
+	if service.Build == types.BuildFromImage || service.Derived {
+		return nil
+	}
+	ports := make(map[int]bool)
+	if service.Port > 0 && service.Port < 1024 {
+		ports[service.Port] = true
+	}
+	for _, mapping := range service.PublishedPorts {
+		if mapping.Target > 0 && mapping.Target < 1024 {
+			ports[mapping.Target] = true
+		}
+	}
+
+	var issues []Issue
+	for _, port := range slices.Sorted(maps.Keys(ports)) {
+		issues = append(issues, Issue{
+			Severity:   SeverityWarning,
+			Code:       CodePrivilegedPort,
+			Service:    service.Name,
+			Message:    fmt.Sprintf("listens on privileged port %d, which only root can bind in the container", port),
+			Suggestion: fmt.Sprintf("listen on %d and set PORT to it", unprivilegedPort(port)),
+		})
+	}
+	return issues
+}
+
+// unprivilegedPort is the conventional unprivileged stand-in for a port, like 8080 for 80
+func unprivilegedPort(port int) int {
+	switch port {
+	case 80:
+		return 8080
+	case 443:
+		return 8443
+	}
+	return port + 8000
+}
+
+// publishedRange parses a published host port, like 8080 or 9000-9005
+func publishedRange(published string) (low, high int, ok bool) {
+	first, last, isRange := strings.Cut(published, "-")
+	low, err := strconv.Atoi(first)
+	if err != nil || low <= 0 {
+		return 0, 0, false
+	}
+	if !isRange {
+		return low, low, true
+	}
+	high, err = strconv.Atoi(last)
+	if err != nil || high < low {
+		return 0, 0, false
+	}
+	return low, high, true
+}
+
+// freePortRange finds the first run of size ports from start that nothing publishes,
+// and takes it
+func freePortRange(taken map[int]bool, start, size int) int {
+	for port := start; ; port++ {
+		free := true
+		for offset := 0; offset < size && free; offset++ {
+			free = !taken[port+offset]
+		}
+		if free {
+			for offset := 0; offset < size; offset++ {
+				taken[port+offset] = true
+			}
+			return port
+		}
+	}
+}
+
+// formatPorts lists ports with consecutive runs collapsed, like 80, 8000-8002
+func formatPorts(ports []int) string {
+	var parts []string
+	for i := 0; i < len(ports); {
+		j := i
+		for j+1 < len(ports) && ports[j+1] == ports[j]+1 {
+			j++
+		}
+		parts = append(parts, formatRange(ports[i], ports[j]))
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatRange(low, high int) string {
+	if low == high {
+		return strconv.Itoa(low)
+	}
+	return fmt.Sprintf("%d-%d", low, high)
+}
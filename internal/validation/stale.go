@@ -0,0 +1,27 @@
+package validation
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+)
+
+// staleConfigs warns about platform configs naming paths that aren't in the repository,
+// like a render.yaml rootDir or a fly.toml Dockerfile that was moved or deleted. The
+// rest of such a config may be just as out of date, so discovery trusted it less than
+// it would a config that matches the repository.
+func staleConfigs(service types.Service) []Issue {
+	var issues []Issue
+	for _, missing := range service.Missing {
+		issues = append(issues, Issue{
+			Severity:   SeverityWarning,
+			Code:       CodeStaleConfig,
+			Service:    service.Name,
+			Message:    fmt.Sprintf("%s names %s %s, which isn't in the repository, so the config is likely stale", path.Base(missing.Config), missing.Setting, missing.Path),
+			File:       missing.Config,
+			Suggestion: fmt.Sprintf("update or remove %s, and check the service is still deployed", path.Base(missing.Config)),
+		})
+	}
+	return issues
+}
@@ -0,0 +1,226 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/environment"
+	envTypes "github.com/railwayapp/turnout/internal/environment/types"
+)
+
+// Severity is how much an issue matters for a deploy
+type Severity int
+
+const (
+	SeverityInfo    Severity = iota // worth knowing, nothing to fix
+	SeverityWarning                 // likely to need attention after migrating
+	SeverityError                   // the service won't deploy or run as discovered
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Issue codes
+const (
+	CodePortConflict        = "port-conflict"
+	CodePublishedConflict   = "published-port-conflict"
+	CodePortRange           = "port-range"
+	CodePrivilegedPort      = "privileged-port"
+	CodeInvalidPort         = "invalid-port"
+	CodeMissingStartCommand = "missing-start-command"
+	CodeUnreachablePublic   = "unreachable-public-service"
+	CodeUndeclaredEnvVar    = "undeclared-env-var"
+	CodeInvalidSchedule     = "invalid-schedule"
+	CodePublicSecret        = "public-secret"
+	CodeCommittedSecret     = "committed-secret"
+	CodeUnsupportedPlatform = "unsupported-platform"
+	CodeStaleConfig         = "stale-config"
+)
+
+// Issue is a problem found with the discovered services
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Service  string   `json:"service,omitempty"`
+	Message  string   `json:"message"`
+	File     string   `json:"file,omitempty"` // where the problem is, when it's in a file
+	Line     int      `json:"line,omitempty"`
+
+	Suggestion string `json:"suggestion,omitempty"` // a fix to make before exporting, like a port to remap to
+}
+
+// Validate checks discovered services for problems that would break or surprise a deploy.
+// envVars are every service's extracted variables, as from ExtractServiceResults, so
+// variables read in code can be told apart from those declared in config.
+func Validate(services []types.Service, envVars map[string][]envTypes.EnvResult) []Issue {
+	var issues []Issue
+	references := environment.CrossReference(services, envVars)
+	for _, service := range services {
+		issues = append(issues, validateService(service, envVars[service.Name], references[service.Name])...)
+	}
+	issues = append(issues, portConflicts(services)...)
+	issues = append(issues, publishedPortConflicts(services)...)
+	issues = append(issues, UnsupportedPlatforms(services)...)
+
+	// Most severe first, keeping the order services were discovered in
+	slices.SortStableFunc(issues, func(a, b Issue) int { return int(b.Severity - a.Severity) })
+	return issues
+}
+
+func validateService(service types.Service, envVars []envTypes.EnvResult, references []environment.Reference) []Issue {
+	var issues []Issue
+	add := func(severity Severity, code, format string, args ...any) {
+		issues = append(issues, Issue{Severity: severity, Code: code, Service: service.Name, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if service.Port < 0 || service.Port > 65535 {
+		add(SeverityError, CodeInvalidPort, "port %d is out of range", service.Port)
+	}
+
+	issues = append(issues, portRange(service)...)
+	issues = append(issues, privilegedPorts(service)...)
+	issues = append(issues, staleConfigs(service)...)
+
+	if service.Schedule != "" {
+		if err := ParseCron(service.Schedule); err != nil {
+			add(SeverityError, CodeInvalidSchedule, "schedule %q doesn't parse: %v", service.Schedule, err)
+		}
+	}
+
+	hasDockerfile := slices.ContainsFunc(service.Configs, func(config types.ConfigRef) bool { return config.Type == "dockerfile" })
+	if service.Build == types.BuildFromSource && service.StartCommand == "" && !hasDockerfile && !service.Derived {
+		add(SeverityWarning, CodeMissingStartCommand, "no start command found, the builder will have to infer one")
+	}
+
+	if service.Network == types.NetworkPublic {
+		switch {
+		case service.Runtime == types.RuntimeScheduled:
+			add(SeverityError, CodeUnreachablePublic, "marked public but runs on a schedule, so nothing is listening for traffic")
+		case service.Port == 0 && service.Build != types.BuildStatic && !readsVariable(envVars, "PORT"):
+			add(SeverityWarning, CodeUnreachablePublic, "marked public but no port is known and it doesn't read PORT, so the domain may have nothing to route to")
+		}
+	}
+
+	for _, name := range environment.Undeclared(references) {
+		add(SeverityWarning, CodeUndeclaredEnvVar, "%s is read in code but never declared in an env file or config", name)
+	}
+
+	for _, name := range publicSecrets(envVars) {
+		add(SeverityWarning, CodePublicSecret, "%s is named like a secret, but its %s prefix inlines it into the client bundle", name, envTypes.PublicEnvPrefix(name))
+	}
+
+	for _, envVar := range committedSecrets(envVars) {
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Code:     CodeCommittedSecret,
+			Service:  service.Name,
+			Message:  fmt.Sprintf("%s has a secret value committed to the repository, rotate it and set the new value on Railway", envVar.VarName),
+			File:     envVar.File(),
+			Line:     envVar.Span.Line,
+		})
+	}
+
+	return issues
+}
+
+// committedSecrets finds sensitive variables with real-looking values in files committed
+// to the repository, once per file. Values in example files, placeholders and values
+// made from other variables aren't secrets themselves.
+func committedSecrets(envVars []envTypes.EnvResult) []envTypes.EnvResult {
+	var secrets []envTypes.EnvResult
+	seen := make(map[string]bool)
+	for _, envVar := range envVars {
+		key := envVar.VarName + " " + envVar.Source
+		if !envVar.Committed || seen[key] || exampleFile(envVar.File()) || len(envTypes.VarReferences(envVar.Value)) > 0 {
+			continue
+		}
+		value := envVar.Value
+		if parsed, err := url.Parse(value); err == nil && parsed.User != nil {
+			value, _ = parsed.User.Password() // Only the password in a URL is secret
+		}
+		if envTypes.HighEntropy(value) {
+			seen[key] = true
+			secrets = append(secrets, envVar)
+		}
+	}
+	return secrets
+}
+
+// exampleFile reports whether a file documents variables rather than setting them,
+// like .env.example
+func exampleFile(path string) bool {
+	name := strings.ToLower(path)
+	for _, suffix := range []string{"example", "sample", "template", "dist", "defaults"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// publicSecrets lists variables named like secrets with a prefix that makes them public
+func publicSecrets(envVars []envTypes.EnvResult) []string {
+	var names []string
+	for _, envVar := range envVars {
+		if envTypes.IsPublicSecret(envVar.VarName) && !slices.Contains(names, envVar.VarName) {
+			names = append(names, envVar.VarName)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func readsVariable(envVars []envTypes.EnvResult, name string) bool {
+	return slices.ContainsFunc(envVars, func(envVar envTypes.EnvResult) bool { return envVar.VarName == name })
+}
+
+// portConflicts finds services built from the same source that listen on the same
+// port, which collide when the processes share a host
+func portConflicts(services []types.Service) []Issue {
+	type key struct {
+		buildPath, environment string
+		port                   int
+	}
+	claims := make(map[key][]string)
+	var order []key
+	for _, service := range services {
+		if service.Port <= 0 || service.BuildPath == "" || service.Network == types.NetworkNone {
+			continue
+		}
+		k := key{service.BuildPath, service.Environment, service.Port}
+		if _, ok := claims[k]; !ok {
+			order = append(order, k)
+		}
+		claims[k] = append(claims[k], service.Name)
+	}
+
+	var issues []Issue
+	for _, k := range order {
+		if names := claims[k]; len(names) > 1 {
+			issues = append(issues, Issue{
+				Severity: SeverityWarning,
+				Code:     CodePortConflict,
+				Service:  names[0],
+				Message:  fmt.Sprintf("%s all listen on port %d from %s", strings.Join(names, ", "), k.port, k.buildPath),
+			})
+		}
+	}
+	return issues
+}
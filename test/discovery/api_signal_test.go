@@ -0,0 +1,51 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestAPISignal_AttachesSpecs(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("web/Dockerfile", []byte("FROM node:20\nEXPOSE 3000\n"))
+	mfs.AddFile("web/docs/openapi.yaml", []byte(`openapi: 3.1.0
+servers:
+  - url: https://example.com/api/v1
+paths:
+  /users: {}
+  /healthz: {}
+`))
+	mfs.AddFile("graph/Dockerfile", []byte("FROM node:20\n"))
+	mfs.AddFile("graph/queries.graphql", []byte("query Me { me { id } }\n"))
+	mfs.AddFile("graph/schema.graphql", []byte("type Query {\n  me: User\n}\n"))
+
+	services, err := discovery.NewServiceDiscovery(mfs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	found := 0
+	for _, service := range services {
+		switch service.Name {
+		case "web":
+			found++
+			if service.API == nil || service.API.Type != "openapi" || service.API.Version != "3.1.0" || service.API.SpecPath != "web/docs/openapi.yaml" {
+				t.Errorf("Expected web's OpenAPI spec, got %+v", service.API)
+			}
+			if service.HealthcheckPath != "/api/v1/healthz" {
+				t.Errorf("Expected the spec's health endpoint under its server path, got %q", service.HealthcheckPath)
+			}
+		case "graph":
+			found++
+			if service.API == nil || service.API.Type != "graphql" || service.API.SpecPath != "graph/schema.graphql" {
+				t.Errorf("Expected graph's GraphQL schema rather than its queries, got %+v", service.API)
+			}
+		}
+	}
+	if found != 2 {
+		t.Fatalf("Expected web and graph services, got %+v", services)
+	}
+}
@@ -0,0 +1,111 @@
+package discovery_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestBakeSignal_HCL(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("docker-bake.hcl", []byte(`# Builds everything
+variable "NODE_VERSION" {
+  default = "20"
+}
+
+group "default" {
+  targets = ["api", "workers"]
+}
+
+group "workers" {
+  targets = ["worker"]
+}
+
+target "_common" {
+  args = {
+    NODE_VERSION = "${NODE_VERSION}"
+    GIT_SHA = null
+  }
+}
+
+target "api" {
+  inherits = ["_common"]
+  context = "./api"
+  args = {
+    PORT = 8080
+  }
+  tags = ["acme/api:${NODE_VERSION}", lower("LATEST")]
+}
+
+target "worker" {
+  inherits = ["_common"]
+  context = "worker"
+  dockerfile = "Dockerfile.worker"
+}
+
+target "docs" {
+  context = "https://github.com/acme/docs.git"
+}
+`))
+	mfs.AddFile("api/Dockerfile", []byte("FROM node:20\n"))
+	mfs.AddFile("worker/Dockerfile.worker", []byte("FROM node:20\n"))
+
+	services := make(map[string]types.Service)
+	for _, service := range observeAll(t, mfs, signals.NewBakeSignal(mfs)) {
+		services[service.Name] = service
+	}
+	if len(services) != 2 {
+		t.Fatalf("expected the default group's 2 targets, got %d: %+v", len(services), services)
+	}
+
+	api := services["api"]
+	if api.BuildPath != "api" || api.Build != types.BuildFromSource {
+		t.Errorf("expected api built from source in api, got %s from %q", api.Build, api.BuildPath)
+	}
+	if api.BuildArgs["NODE_VERSION"] != "20" || api.BuildArgs["PORT"] != "8080" {
+		t.Errorf("expected inherited and own args with variables resolved, got %v", api.BuildArgs)
+	}
+	if _, ok := api.BuildArgs["GIT_SHA"]; ok {
+		t.Error("expected args without a value to be left to the environment")
+	}
+	if len(api.Configs) != 2 || api.Configs[1].Path != "api/Dockerfile" {
+		t.Errorf("expected the bake file and api/Dockerfile as configs, got %+v", api.Configs)
+	}
+
+	worker, ok := services["worker"]
+	if !ok {
+		t.Fatal("expected a worker service from the nested group")
+	}
+	if len(worker.Configs) != 2 || worker.Configs[1].Path != "worker/Dockerfile.worker" {
+		t.Errorf("expected the target's dockerfile as a config, got %+v", worker.Configs)
+	}
+}
+
+func TestBakeSignal_JSONOverride(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("docker-bake.json", []byte(`{
+  "target": {
+    "base": {"args": {"RUBY_VERSION": "3.3"}},
+    "web": {"inherits": ["base"], "context": "."}
+  }
+}`))
+	mfs.AddFile("docker-bake.override.hcl", []byte(`target "web" {
+  args = {
+    RUBY_VERSION = "3.4"
+  }
+}
+`))
+
+	services := observeAll(t, mfs, signals.NewBakeSignal(mfs))
+	if len(services) != 1 || services[0].Name != "web" {
+		t.Fatalf("expected only web, since base is inherited, got %+v", services)
+	}
+	if services[0].BuildArgs["RUBY_VERSION"] != "3.4" {
+		t.Errorf("expected the override's arg, got %v", services[0].BuildArgs)
+	}
+	if services[0].Configs[0].Path != "docker-bake.json" {
+		t.Errorf("expected docker-bake.json as the config, got %+v", services[0].Configs)
+	}
+}
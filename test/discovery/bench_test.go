@@ -0,0 +1,82 @@
+package discovery_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// syntheticTree lays out a monorepo of about files files: services with manifests,
+// Dockerfiles and source, vendored dependencies, and a compose file at the root
+func syntheticTree(files int) map[string][]byte {
+	tree := map[string][]byte{
+		"repo/docker-compose.yml": []byte("services:\n  db:\n    image: postgres:16\n  cache:\n    image: redis:7\n"),
+	}
+	source := []byte("import express from 'express'\nconst port = process.env.PORT || 3000\nexport const db = process.env.DATABASE_URL\n")
+	for service := 0; len(tree) < files; service++ {
+		dir := fmt.Sprintf("repo/services/svc%d", service)
+		tree[dir+"/package.json"] = []byte(`{"name": "svc", "scripts": {"start": "node index.js"}, "dependencies": {"express": "^4.19.2"}}`)
+		tree[dir+"/Dockerfile"] = []byte("FROM node:20-alpine\nWORKDIR /app\nCOPY . .\nEXPOSE 3000\nCMD [\"node\", \"index.js\"]\n")
+		for i := 0; i < 60 && len(tree) < files; i++ {
+			tree[fmt.Sprintf("%s/src/module%d.js", dir, i)] = source
+		}
+		for i := 0; i < 40 && len(tree) < files; i++ {
+			tree[fmt.Sprintf("%s/node_modules/dep%d/index.js", dir, i)] = source
+		}
+	}
+	return tree
+}
+
+func benchmarkDiscover(b *testing.B, filesystem filesystems.FileSystem) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		services, err := discovery.NewServiceDiscovery(filesystem).Discover(ctx, "repo")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(services) == 0 {
+			b.Fatal("Expected services in the synthetic tree")
+		}
+	}
+}
+
+// BenchmarkDiscover_LargeTree walks a 50k-file tree through every default signal
+func BenchmarkDiscover_LargeTree(b *testing.B) {
+	mfs := filesystems.NewMemoryFS()
+	for path, content := range syntheticTree(50_000) {
+		mfs.AddFile(path, content)
+	}
+	benchmarkDiscover(b, mfs)
+}
+
+// BenchmarkDiscover_ZipArchive walks the same tree read from a zip upload, as archives
+// are scanned by every signal
+func BenchmarkDiscover_ZipArchive(b *testing.B) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for path, content := range syntheticTree(50_000) {
+		file, err := writer.Create(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := file.Write(content); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		b.Fatal(err)
+	}
+
+	archive, err := filesystems.ReadZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkDiscover(b, archive)
+}
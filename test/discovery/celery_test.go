@@ -0,0 +1,69 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestServiceDiscovery_DjangoCelerySplit(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("shop/manage.py", []byte("#!/usr/bin/env python\n"))
+	fs.AddFile("shop/requirements/base.txt", []byte("Django==5.0\ncelery[redis]==5.3\ndjango-celery-beat==2.6\n"))
+	fs.AddFile("shop/shop/settings.py", []byte("INSTALLED_APPS = []\n"))
+	fs.AddFile("shop/shop/celery.py", []byte("from celery import Celery\napp = Celery('shop')\n"))
+	fs.AddFile("shop/Dockerfile", []byte("FROM python:3.12\nEXPOSE 8000\nCMD [\"gunicorn\", \"shop.wsgi\"]\n"))
+
+	services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	web, ok := byName["shop"]
+	if !ok || web.Network != types.NetworkPublic {
+		t.Fatalf("Expected a public shop web service, got %+v", services)
+	}
+	for name, expected := range map[string]struct {
+		runtime types.Runtime
+		command string
+	}{
+		"shop-worker": {types.RuntimeContinuous, "celery -A shop worker --loglevel=info"},
+		"shop-beat":   {types.RuntimeScheduled, "celery -A shop beat --loglevel=info"},
+	} {
+		service, ok := byName[name]
+		if !ok {
+			t.Errorf("Expected a %s service, got %+v", name, services)
+			continue
+		}
+		if service.BuildPath != web.BuildPath || service.Network != types.NetworkNone || service.Runtime != expected.runtime {
+			t.Errorf("Expected %s built with web and unexposed, got %+v", name, service)
+		}
+		if service.StartCommand != expected.command {
+			t.Errorf("Expected %s to start with %q, got %q", name, expected.command, service.StartCommand)
+		}
+	}
+	if len(services) != 3 {
+		t.Errorf("Expected web, worker and beat, got %d services", len(services))
+	}
+}
+
+func TestServiceDiscovery_DjangoWithoutCeleryApp(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("manage.py", []byte("#!/usr/bin/env python\n"))
+	fs.AddFile("requirements.txt", []byte("Django==5.0\ncelery==5.3\n"))
+
+	services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(services) != 1 {
+		t.Errorf("Expected only the web service without a celery.py, got %+v", services)
+	}
+}
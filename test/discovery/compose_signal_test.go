@@ -0,0 +1,164 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestDockerComposeSignal_Overrides(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("shop/docker-compose.yml", []byte(`services:
+  web:
+    build: ./web
+    ports:
+      - "3000:3000"
+    labels:
+      turnout.network: private
+      railway.network: public
+  worker:
+    build: ./worker
+    labels:
+      railway.network: public
+    x-railway:
+      name: cleanup
+      network: none
+      schedule: "*/5 * * * *"
+`))
+	mfs.AddFile("shop/web/railway.json", []byte(`{"deploy": {"startCommand": "npm start"}}`))
+	mfs.AddFile("shop/worker/Dockerfile", []byte("FROM node:20\nCMD [\"node\", \"cleanup.js\"]\n"))
+
+	services, err := discovery.NewServiceDiscovery(mfs).Discover(context.Background(), "shop")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	web, ok := byName["web"]
+	if !ok || web.Network != types.NetworkPublic || !web.IsPinned("Network") {
+		t.Errorf("Expected the railway label to pin web public over railway.json, got %+v", web)
+	}
+	if web.StartCommand != "npm start" {
+		t.Errorf("Expected railway.json to still supply the start command, got %q", web.StartCommand)
+	}
+
+	cleanup, ok := byName["cleanup"]
+	if !ok {
+		t.Fatalf("Expected worker renamed to cleanup, got %v", services)
+	}
+	if cleanup.Network != types.NetworkNone || cleanup.Runtime != types.RuntimeScheduled || cleanup.Schedule != "*/5 * * * *" {
+		t.Errorf("Expected x-railway to win over labels and schedule the service, got %+v", cleanup)
+	}
+}
+
+func TestDockerComposeSignal_EnvironmentPolicy(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("shop/docker-compose.yml", []byte(`services:
+  web:
+    build: ./web
+    ports:
+      - "3000:3000"
+  db:
+    image: postgres:16
+`))
+	mfs.AddFile("shop/docker-compose.prod.yml", []byte(`services:
+  web:
+    ports:
+      - "80:3000"
+`))
+	mfs.AddFile("shop/docker-compose.override.yml", []byte(`services:
+  mailhog:
+    image: mailhog/mailhog
+`))
+
+	discover := func(environment string) map[string]types.Service {
+		signal := signals.NewDockerComposeSignal(mfs)
+		signal.Environment = environment
+		services := make(map[string]types.Service)
+		for _, service := range observeAll(t, mfs, signal) {
+			services[service.Name] = service
+		}
+		return services
+	}
+
+	production := discover(signals.ComposeProduction)
+	if len(production) != 2 || production["web"].Network != types.NetworkPublic {
+		t.Fatalf("Expected the production file layered over the base, got %v", production)
+	}
+	if configs := production["web"].Configs; len(configs) != 2 || configs[1].Environment != signals.ComposeProduction {
+		t.Errorf("Expected web's definition to come from the base and production files, got %v", configs)
+	}
+	if configs := production["db"].Configs; len(configs) != 1 || configs[0].Environment != "" {
+		t.Errorf("Expected db's definition to come from the base file only, got %v", configs)
+	}
+
+	development := discover(signals.ComposeDevelopment)
+	if _, ok := development["mailhog"]; !ok || development["web"].Network != types.NetworkPrivate {
+		t.Errorf("Expected the override file layered over the base, got %v", development)
+	}
+}
+
+func TestServiceDiscovery_DiscoverEnvironments(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("shop/docker-compose.yml", []byte(`services:
+  web:
+    build: ./web
+    ports:
+      - "3000:3000"
+`))
+	mfs.AddFile("shop/docker-compose.prod.yml", []byte(`services:
+  web:
+    ports:
+      - "80:3000"
+`))
+	mfs.AddFile("shop/compose.staging.yaml", []byte(`services:
+  web:
+    environment:
+      STAGE: "1"
+`))
+	mfs.AddFile("shop/.env.staging", []byte("REDIS_URL=redis://cache:6379\n"))
+
+	sd := discovery.NewServiceDiscovery(mfs)
+	services, err := sd.DiscoverEnvironments(context.Background(), "shop")
+	if err != nil {
+		t.Fatalf("DiscoverEnvironments failed: %v", err)
+	}
+
+	byEnvironment := make(map[string]map[string]types.Service)
+	for _, service := range services {
+		if byEnvironment[service.Environment] == nil {
+			byEnvironment[service.Environment] = make(map[string]types.Service)
+		}
+		byEnvironment[service.Environment][service.Name] = service
+	}
+
+	if len(byEnvironment) != 2 {
+		t.Fatalf("Expected production and staging services, got %v", services)
+	}
+	if web := byEnvironment["production"]["web"]; web.Network != types.NetworkPublic {
+		t.Errorf("Expected production web to be public, got %+v", web)
+	}
+	if web := byEnvironment["staging"]["web"]; web.Network != types.NetworkPrivate {
+		t.Errorf("Expected staging web to stay private, got %+v", web)
+	}
+	if _, ok := byEnvironment["staging"]["redis"]; !ok {
+		t.Errorf("Expected .env.staging to imply redis in staging, got %v", byEnvironment["staging"])
+	}
+	if _, ok := byEnvironment["production"]["redis"]; ok {
+		t.Errorf("Expected no redis in production, got %v", byEnvironment["production"])
+	}
+
+	// Plain discovery is unaffected
+	services, err = sd.Discover(context.Background(), "shop")
+	if err != nil || len(services) == 0 || services[0].Environment != "" {
+		t.Errorf("Expected untagged services from Discover, got %v %v", services, err)
+	}
+}
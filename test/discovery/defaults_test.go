@@ -0,0 +1,82 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestServiceDiscovery_FrameworkDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		port        int
+		healthcheck string
+		source      string // provenance of the port, empty when it's unknown
+	}{
+		{
+			name:        "Next.js",
+			files:       map[string]string{"package.json": `{"dependencies": {"next": "14.2.0", "react": "18.3.0"}}`},
+			port:        3000,
+			healthcheck: "/",
+			source:      "framework-default:Next.js",
+		},
+		{
+			name:        "Django",
+			files:       map[string]string{"manage.py": "import django\n", "requirements.txt": "django==5.0\n"},
+			port:        8000,
+			healthcheck: "/",
+			source:      "framework-default:Django",
+		},
+		{
+			name: "Dockerfile port beats the default",
+			files: map[string]string{
+				"package.json": `{"dependencies": {"express": "4.19.0"}}`,
+				"Dockerfile":   "FROM node:20\nEXPOSE 8080\nCMD [\"node\", \"server.js\"]\n",
+			},
+			port:        8080,
+			healthcheck: "/",
+			source:      "dockerfile:app/Dockerfile",
+		},
+		{
+			name:  "static site",
+			files: map[string]string{"package.json": `{"dependencies": {"gatsby": "5.13.0"}}`},
+		},
+		{
+			name:  "worker",
+			files: map[string]string{"package.json": `{"dependencies": {"@temporalio/worker": "1.10.0"}}`},
+		},
+		{
+			name:  "no framework",
+			files: map[string]string{"Dockerfile": "FROM alpine:3.20\nCMD [\"./run\"]\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := filesystems.NewMemoryFS()
+			for path, content := range tt.files {
+				fs.AddFile("app/"+path, []byte(content))
+			}
+
+			services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+			if err != nil {
+				t.Fatalf("Discover failed: %v", err)
+			}
+			if len(services) == 0 {
+				t.Fatal("Expected a service")
+			}
+			for _, service := range services {
+				if service.Port != tt.port || service.HealthcheckPath != tt.healthcheck {
+					t.Errorf("Expected %s on port %d with healthcheck %q, got port %d and %q", service.Name, tt.port, tt.healthcheck, service.Port, service.HealthcheckPath)
+				}
+				provenance, _ := service.ProvenanceOf("Port")
+				if provenance.Source != tt.source {
+					t.Errorf("Expected the port from %q, got %+v", tt.source, provenance)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package discovery_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestDigitalOceanAppSignal_Components(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		buildPath string // empty when the component is skipped
+		network   types.Network
+		build     types.Build
+	}{
+		{
+			name:      "service with source_dir",
+			spec:      "services:\n  - name: api\n    source_dir: /api\n    run_command: node server.js\n",
+			buildPath: "api",
+			network:   types.NetworkPublic,
+			build:     types.BuildFromSource,
+		},
+		{
+			name:      "service without source_dir",
+			spec:      "services:\n  - name: api\n    run_command: node server.js\n",
+			buildPath: ".",
+			network:   types.NetworkPublic,
+			build:     types.BuildFromSource,
+		},
+		{
+			name:      "static site with source_dir",
+			spec:      "static_sites:\n  - name: site\n    source_dir: web\n    output_dir: dist\n",
+			buildPath: "web",
+			network:   types.NetworkPublic,
+			build:     types.BuildStatic,
+		},
+		{
+			name:      "worker with source_dir",
+			spec:      "workers:\n  - name: queue\n    source_dir: workers/queue/\n",
+			buildPath: "workers/queue",
+			network:   types.NetworkNone,
+			build:     types.BuildFromSource,
+		},
+		{
+			name:      "job with dot source_dir",
+			spec:      "jobs:\n  - name: migrate\n    kind: PRE_DEPLOY\n    source_dir: .\n",
+			buildPath: ".",
+			network:   types.NetworkNone,
+			build:     types.BuildFromSource,
+		},
+		{
+			name:      "routed function",
+			spec:      "functions:\n  - name: hooks\n    source_dir: functions\n    routes:\n      - path: /hooks\n",
+			buildPath: "functions",
+			network:   types.NetworkPublic,
+			build:     types.BuildFromSource,
+		},
+		{
+			name:      "unrouted function",
+			spec:      "functions:\n  - name: hooks\n    source_dir: functions\n",
+			buildPath: "functions",
+			network:   types.NetworkPrivate,
+			build:     types.BuildFromSource,
+		},
+		{
+			name: "service with source_dir outside the repo",
+			spec: "services:\n  - name: api\n    source_dir: ../..\n    run_command: node server.js\n",
+		},
+		{
+			name: "static site with source_dir outside the repo",
+			spec: "static_sites:\n  - name: site\n    source_dir: /web/../../escape\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mfs := filesystems.NewMemoryFS()
+			mfs.AddFile("digitalocean-app.yaml", []byte("name: shop\n"+tt.spec))
+
+			signal := signals.NewDigitalOceanAppSignal(mfs)
+			services := observeAll(t, mfs, signal)
+			if tt.buildPath == "" {
+				if len(services) != 0 || len(signal.Warnings()) != 1 {
+					t.Fatalf("Expected the component skipped with a warning, got %+v and %+v", services, signal.Warnings())
+				}
+				return
+			}
+			if len(services) != 1 {
+				t.Fatalf("Expected 1 service, got %+v", services)
+			}
+			service := services[0]
+			if service.BuildPath != tt.buildPath || service.Network != tt.network || service.Build != tt.build {
+				t.Errorf("Expected %s built %v from %q on %v, got %v from %q on %v", service.Name, tt.build, tt.buildPath, tt.network, service.Build, service.BuildPath, service.Network)
+			}
+		})
+	}
+}
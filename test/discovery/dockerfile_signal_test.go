@@ -0,0 +1,160 @@
+package discovery_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestDockerfileSignal_MultiStage(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("api/Dockerfile", []byte(`ARG NODE_VERSION=20
+FROM node:${NODE_VERSION}-alpine AS build
+WORKDIR /app
+RUN npm ci && npm run build
+EXPOSE 9229
+
+FROM node:${NODE_VERSION}-alpine AS runtime
+COPY --from=build /app/dist ./dist
+EXPOSE 8080/tcp
+ENTRYPOINT ["node"]
+CMD ["dist/server.js"]
+`))
+
+	signal := signals.NewDockerfileSignal(mfs)
+	services := observeAll(t, mfs, signal)
+
+	if len(services) != 1 {
+		t.Fatalf("Expected 1 service, got %d", len(services))
+	}
+
+	service := services[0]
+	if service.BaseImage != "node" {
+		t.Errorf("Expected base image node, got %q", service.BaseImage)
+	}
+	if service.Port != 8080 {
+		t.Errorf("Expected port 8080 from final stage, got %d", service.Port)
+	}
+	if service.StartCommand != "node dist/server.js" {
+		t.Errorf("Expected start command from ENTRYPOINT + CMD, got %q", service.StartCommand)
+	}
+	if confidence := signal.ServiceConfidence(service); confidence <= signal.Confidence() {
+		t.Errorf("Expected conclusive Dockerfile to raise confidence, got %d", confidence)
+	}
+}
+
+func TestDockerfileSignal_WebServerImageIsPublic(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("web/Dockerfile", []byte(`FROM node:20 AS build
+RUN npm run build
+
+FROM nginx:1.27-alpine
+COPY --from=build /app/dist /usr/share/nginx/html
+`))
+
+	services := observeAll(t, mfs, signals.NewDockerfileSignal(mfs))
+
+	if len(services) != 1 {
+		t.Fatalf("Expected 1 service, got %d", len(services))
+	}
+	if services[0].BaseImage != "nginx" {
+		t.Errorf("Expected base image nginx, got %q", services[0].BaseImage)
+	}
+	if services[0].Network != types.NetworkPublic {
+		t.Errorf("Expected nginx service to be public, got %v", services[0].Network)
+	}
+}
+
+func TestDockerfileSignal_VariantsDeduped(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("app/Dockerfile.dev", []byte("FROM node:20\nCMD [\"npm\", \"run\", \"dev\"]\n"))
+	mfs.AddFile("app/Dockerfile", []byte("FROM node:20\nCMD [\"npm\", \"start\"]\n"))
+	mfs.AddFile("app/Dockerfile.prod", []byte("FROM node:20\nCMD [\"node\", \"server.js\"]\n"))
+
+	services := observeAll(t, mfs, signals.NewDockerfileSignal(mfs))
+
+	if len(services) != 1 {
+		t.Fatalf("Expected variants to collapse into 1 service, got %d", len(services))
+	}
+
+	service := services[0]
+	if service.StartCommand != "node server.js" {
+		t.Errorf("Expected production variant to win, got start command %q", service.StartCommand)
+	}
+
+	var paths []string
+	for _, config := range service.Configs {
+		paths = append(paths, config.Path)
+	}
+	expected := []string{"app/Dockerfile.prod", "app/Dockerfile", "app/Dockerfile.dev"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("Expected configs %v, got %v", expected, paths)
+	}
+}
+
+func TestDockerfileSignal_NamedDockerfilesSplit(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("app/Dockerfile.web", []byte("FROM node:20\nEXPOSE 3000\nCMD [\"node\", \"server.js\"]\n"))
+	mfs.AddFile("app/Dockerfile.worker", []byte("FROM node:20\nCMD [\"node\", \"worker.js\"]\n"))
+	mfs.AddFile("jobs/Dockerfile.worker", []byte("FROM python:3.12\nCMD [\"python\", \"jobs.py\"]\n"))
+
+	services := observeAll(t, mfs, signals.NewDockerfileSignal(mfs))
+
+	expected := map[string]struct{ buildPath, dockerfile, start string }{
+		"web":    {"app", "app/Dockerfile.web", "node server.js"},
+		"worker": {"app", "app/Dockerfile.worker", "node worker.js"},
+		"jobs":   {"jobs", "jobs/Dockerfile.worker", "python jobs.py"}, // Alone, it's just the directory's
+	}
+	if len(services) != len(expected) {
+		t.Fatalf("Expected %d services, got %+v", len(expected), services)
+	}
+	for _, service := range services {
+		want, ok := expected[service.Name]
+		if !ok {
+			t.Errorf("Unexpected service %q", service.Name)
+			continue
+		}
+		if service.BuildPath != want.buildPath || len(service.Configs) != 1 || service.Configs[0].Path != want.dockerfile || service.StartCommand != want.start {
+			t.Errorf("Expected %s built from %s with %q, got %+v", service.Name, want.dockerfile, want.start, service)
+		}
+	}
+}
+
+// observeAll feeds every entry of the filesystem to a single signal and returns its services
+func observeAll(t *testing.T, fs filesystems.FileSystem, signal interface {
+	ObserveEntry(context.Context, string, filesystems.DirEntry) error
+	GenerateServices(context.Context) ([]types.Service, error)
+	Reset()
+}) []types.Service {
+	t.Helper()
+	ctx := context.Background()
+	signal.Reset()
+
+	err := fs.Walk(".", func(path string, info filesystems.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		for entry, err := range fs.ReadDir(path) {
+			if err != nil {
+				return err
+			}
+			if err := signal.ObserveEntry(ctx, path, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	services, err := signal.GenerateServices(ctx)
+	if err != nil {
+		t.Fatalf("GenerateServices failed: %v", err)
+	}
+	return services
+}
@@ -0,0 +1,95 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestServiceDiscovery_ElixirUmbrella(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("mix.exs", []byte(`defmodule Shop.Umbrella.MixProject do
+  use Mix.Project
+
+  def project do
+    [
+      apps_path: "apps",
+      version: "0.1.0",
+      deps: deps(),
+      releases: releases()
+    ]
+  end
+
+  defp releases do
+    [
+      shop_web: [applications: [shop: :permanent, shop_web: :permanent]],
+      shop_jobs: [
+        applications: [shop: :permanent, shop_jobs: :permanent]
+      ]
+    ]
+  end
+
+  defp deps, do: []
+end
+`))
+	fs.AddFile("apps/shop/mix.exs", []byte(`defmodule Shop.MixProject do
+  use Mix.Project
+
+  def project do
+    [app: :shop, build_path: "../../_build", deps_path: "../../deps", deps: [{:ecto_sql, "~> 3.10"}]]
+  end
+end
+`))
+	fs.AddFile("apps/shop_web/mix.exs", []byte(`defmodule ShopWeb.MixProject do
+  use Mix.Project
+
+  def project do
+    [app: :shop_web, build_path: "../../_build", deps_path: "../../deps", deps: deps()]
+  end
+
+  defp deps do
+    [{:phoenix, "~> 1.7"}, {:shop, in_umbrella: true}]
+  end
+end
+`))
+	fs.AddFile("apps/shop_jobs/mix.exs", []byte(`defmodule ShopJobs.MixProject do
+  use Mix.Project
+
+  def project do
+    [app: :shop_jobs, build_path: "../../_build", deps_path: "../../deps", deps: [{:oban, "~> 2.17"}, {:shop, in_umbrella: true}]]
+  end
+end
+`))
+
+	services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+	if len(byName) != 2 {
+		t.Fatalf("Expected shop_web and shop_jobs, not the library or a service per mix.exs, got %+v", services)
+	}
+
+	web := byName["shop_web"]
+	if web.Network != types.NetworkPublic || web.BuildPath != "." || web.Port != 4000 {
+		t.Errorf("Expected a public web service built from the umbrella on 4000, got %+v", web)
+	}
+	if web.StartCommand != "_build/prod/rel/shop_web/bin/shop_web start" {
+		t.Errorf("Expected the shop_web release to start it, got %q", web.StartCommand)
+	}
+
+	jobs := byName["shop_jobs"]
+	if jobs.Network != types.NetworkNone || jobs.BuildPath != "." {
+		t.Errorf("Expected an unexposed worker built from the umbrella, got %+v", jobs)
+	}
+	if jobs.StartCommand != "_build/prod/rel/shop_jobs/bin/shop_jobs start" {
+		t.Errorf("Expected the shop_jobs release to start it, got %q", jobs.StartCommand)
+	}
+}
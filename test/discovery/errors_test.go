@@ -0,0 +1,87 @@
+package discovery_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// unreadableFS fails to list any directory with an error
+type unreadableFS struct {
+	*filesystems.MemoryFS
+	err error
+}
+
+func (u *unreadableFS) ReadDir(name string) iter.Seq2[filesystems.DirEntry, error] {
+	return func(yield func(filesystems.DirEntry, error) bool) {
+		yield(nil, fmt.Errorf("failed to list %s: %w", name, u.err))
+	}
+}
+
+func TestServiceDiscovery_CriticalErrors(t *testing.T) {
+	for _, test := range []struct {
+		err      error
+		critical bool
+	}{
+		{&filesystems.StatusError{StatusCode: 401}, true},
+		{&filesystems.RateLimitError{}, true},
+		{filesystems.ErrNetwork, true},
+		{&filesystems.StatusError{StatusCode: 404}, false},
+		// Messages alone don't make an error critical
+		{errors.New("invalid token in package.json"), false},
+	} {
+		fs := &unreadableFS{MemoryFS: filesystems.NewMemoryFS(), err: test.err}
+		_, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+		if test.critical && !errors.Is(err, test.err) {
+			t.Errorf("Expected %v to fail discovery, got %v", test.err, err)
+		}
+		if !test.critical && err != nil {
+			t.Errorf("Expected %v to be skipped, got %v", test.err, err)
+		}
+	}
+}
+
+func TestServiceDiscovery_Warnings(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("render.yaml", []byte("services:\n  - type: web\n    name: [oops\n"))
+	fs.AddFile("api/fly.toml", []byte("app = \"api\"\n\n[http_service]\ninternal_port = 8080\n"))
+
+	sd := discovery.NewServiceDiscovery(fs)
+	services, err := sd.Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(services) != 1 || services[0].Name != "api" {
+		t.Errorf("Expected the fly.toml's service despite the broken render.yaml, got %+v", services)
+	}
+
+	warnings := sd.Warnings()
+	if len(warnings) != 1 || warnings[0].Signal != "Render" || warnings[0].File != "render.yaml" || warnings[0].Message == "" {
+		t.Fatalf("Expected a warning for the broken render.yaml, got %+v", warnings)
+	}
+
+	fs.AddFile("render.yaml", []byte("services: []\n"))
+	if _, err := sd.Discover(context.Background(), "."); err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if warnings := sd.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected warnings to be cleared by the next discovery, got %+v", warnings)
+	}
+}
+
+func TestServiceDiscovery_Canceled(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("api/fly.toml", []byte("app = \"api\"\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	services, err := discovery.NewServiceDiscovery(fs).Discover(ctx, ".")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a canceled context to stop discovery, got %v and %+v", err, services)
+	}
+}
@@ -0,0 +1,63 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestEvidenceModels(t *testing.T) {
+	for _, test := range []struct {
+		model    discovery.EvidenceModel
+		weights  []int
+		expected int
+	}{
+		{discovery.AdditiveEvidence{}, []int{70, 85, 50}, 205},
+		{discovery.AdditiveEvidence{}, nil, 0},
+		{discovery.StrongestEvidence{}, []int{70, 85, 50}, 85},
+		{discovery.StrongestEvidence{}, nil, 0},
+	} {
+		if score := test.model.Score(test.weights); score != test.expected {
+			t.Errorf("Expected %T to score %v as %d, got %d", test.model, test.weights, test.expected, score)
+		}
+	}
+}
+
+// staleRenderFS has a render.yaml whose start command the Dockerfile, Procfile and
+// package.json all disagree with
+func staleRenderFS() *filesystems.MemoryFS {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("render.yaml", []byte("services:\n  - type: web\n    name: api\n    runtime: node\n    startCommand: node old.js\n"))
+	fs.AddFile("Procfile", []byte("web: node server.js\n"))
+	fs.AddFile("Dockerfile", []byte("FROM node:20\nCMD [\"node\", \"server.js\"]\n"))
+	fs.AddFile("package.json", []byte(`{"name": "api", "scripts": {"start": "node server.js"}}`))
+	return fs
+}
+
+func TestServiceDiscovery_AgreeingEvidenceOutweighsStaleConfig(t *testing.T) {
+	for _, test := range []struct {
+		model    discovery.EvidenceModel
+		expected string
+	}{
+		{discovery.AdditiveEvidence{}, "node server.js"},
+		{discovery.StrongestEvidence{}, "node old.js"},
+	} {
+		sd := discovery.NewServiceDiscovery(staleRenderFS())
+		sd.SetEvidenceModel(test.model)
+		services, err := sd.Discover(context.Background(), ".")
+		if err != nil {
+			t.Fatalf("Discover failed: %v", err)
+		}
+		if len(services) != 1 {
+			t.Fatalf("Expected one service, got %+v", services)
+		}
+		if services[0].StartCommand != test.expected {
+			t.Errorf("Expected %T to start the service with %q, got %q", test.model, test.expected, services[0].StartCommand)
+		}
+		if _, inferred := services[0].ProvenanceOf("StartCommand"); inferred {
+			t.Errorf("Expected a start command configs set outright to stay explicit, got %+v", services[0].Provenance)
+		}
+	}
+}
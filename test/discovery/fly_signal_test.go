@@ -0,0 +1,188 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestFlySignal_Processes(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("shop/fly.toml", []byte(`app = "shop"
+
+[processes]
+web = "bin/rails server"
+worker = "bundle exec sidekiq"
+
+[http_service]
+internal_port = 3000
+processes = ["web"]
+`))
+
+	services := observeAll(t, mfs, signals.NewFlySignal(mfs))
+
+	if len(services) != 2 {
+		t.Fatalf("Expected one service per process group, got %d", len(services))
+	}
+
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	web := byName["shop-web"]
+	if web.Network != types.NetworkPublic || web.Port != 3000 || web.StartCommand != "bin/rails server" {
+		t.Errorf("Expected public web process on 3000, got %+v", web)
+	}
+
+	worker := byName["shop-worker"]
+	if worker.Network != types.NetworkPrivate || worker.Port != 0 {
+		t.Errorf("Expected private worker process without a port, got %+v", worker)
+	}
+}
+
+func TestFlySignal_ReleaseCommandAndMounts(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("api/fly.toml", []byte(`app = "api"
+
+[build]
+dockerfile = "Dockerfile.fly"
+
+[deploy]
+release_command = "bin/migrate"
+
+[mounts]
+source = "data"
+destination = "/data"
+`))
+	mfs.AddFile("api/Dockerfile.fly", []byte("FROM alpine\n"))
+
+	services := observeAll(t, mfs, signals.NewFlySignal(mfs))
+
+	if len(services) != 1 {
+		t.Fatalf("Expected 1 service, got %d", len(services))
+	}
+
+	service := services[0]
+	if service.PreDeployCommand != "bin/migrate" {
+		t.Errorf("Expected release command as pre-deploy command, got %q", service.PreDeployCommand)
+	}
+	if len(service.Volumes) != 1 || service.Volumes[0] != (types.Volume{Name: "data", MountPath: "/data"}) {
+		t.Errorf("Expected data volume at /data, got %v", service.Volumes)
+	}
+	if len(service.Configs) != 2 || service.Configs[1].Path != "api/Dockerfile.fly" {
+		t.Errorf("Expected Dockerfile config ref, got %v", service.Configs)
+	}
+}
+
+func TestFlySignal_ReleaseCommandAndMountsOnPrimaryProcess(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("shop/fly.toml", []byte(`app = "shop"
+
+[deploy]
+release_command = "bin/rails db:migrate"
+
+[processes]
+web = "bin/rails server"
+worker = "bundle exec sidekiq"
+
+[http_service]
+internal_port = 3000
+processes = ["web"]
+
+[[mounts]]
+source = "storage"
+destination = "/rails/storage"
+
+[[mounts]]
+source = "queue"
+destination = "/queue"
+processes = ["worker"]
+`))
+
+	byName := make(map[string]types.Service)
+	for _, service := range observeAll(t, mfs, signals.NewFlySignal(mfs)) {
+		byName[service.Name] = service
+	}
+
+	web, worker := byName["shop-web"], byName["shop-worker"]
+	if web.PreDeployCommand != "bin/rails db:migrate" || worker.PreDeployCommand != "" {
+		t.Errorf("Expected the release command to run once, for web, got %q and %q", web.PreDeployCommand, worker.PreDeployCommand)
+	}
+	if len(web.Volumes) != 1 || web.Volumes[0].Name != "storage" {
+		t.Errorf("Expected the unscoped mount on web only, got %v", web.Volumes)
+	}
+	if len(worker.Volumes) != 1 || worker.Volumes[0].Name != "queue" {
+		t.Errorf("Expected the worker's own mount, got %v", worker.Volumes)
+	}
+}
+
+func TestServiceDiscovery_FlyWorkerWithoutGenericPort(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("shop/fly.toml", []byte(`app = "shop"
+
+[processes]
+web = "bin/rails server"
+worker = "bundle exec sidekiq"
+
+[http_service]
+internal_port = 3000
+processes = ["web"]
+`))
+	mfs.AddFile("shop/Gemfile", []byte("gem \"rails\"\n"))
+	mfs.AddFile("shop/config/application.rb", []byte("require \"rails/all\"\n"))
+	mfs.AddFile("shop/Dockerfile", []byte("FROM ruby:3.3\nEXPOSE 3000\n"))
+
+	services, err := discovery.NewServiceDiscovery(mfs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	if web := byName["shop-web"]; web.Port != 3000 || web.HealthcheckPath != "/up" {
+		t.Errorf("Expected web to keep what the Rails app listens on, got %+v", web)
+	}
+	if worker := byName["shop-worker"]; worker.Port != 0 || worker.HealthcheckPath != "" {
+		t.Errorf("Expected the unrouted worker without a port or healthcheck, got %+v", worker)
+	}
+}
+
+func TestFlySignal_VMResources(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("shop/fly.toml", []byte(`app = "shop"
+
+[processes]
+web = "bin/rails server"
+worker = "bundle exec sidekiq"
+
+[[vm]]
+size = "shared-cpu-2x"
+memory = "1gb"
+processes = ["web"]
+
+[[vm]]
+size = "performance-4x"
+processes = ["worker"]
+`))
+
+	services := observeAll(t, mfs, signals.NewFlySignal(mfs))
+
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	if web := byName["shop-web"].Resources; web == nil || web.CPU != 2 || web.MemoryMB != 1024 {
+		t.Errorf("Expected web to have 2 CPUs and its memory override, got %+v", web)
+	}
+	if worker := byName["shop-worker"].Resources; worker == nil || worker.CPU != 4 || worker.MemoryMB != 8192 {
+		t.Errorf("Expected worker to have the performance-4x preset, got %+v", worker)
+	}
+}
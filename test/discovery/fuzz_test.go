@@ -0,0 +1,91 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// fuzzSignal feeds a signal arbitrary contents for its config file, starting from
+// valid configs. Malformed configs may fail to parse, but must never panic.
+func fuzzSignal(f *testing.F, path string, newSignal func(filesystems.FileSystem) discovery.ServiceSignal, seeds ...string) {
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, content []byte) {
+		mfs := filesystems.NewMemoryFS()
+		mfs.AddFile(path, content)
+		signal := newSignal(mfs)
+		signal.Reset()
+
+		ctx := context.Background()
+		_ = mfs.Walk(".", func(dir string, info filesystems.FileInfo, err error) error {
+			if err != nil || !info.IsDir() {
+				return err
+			}
+			for entry, err := range mfs.ReadDir(dir) {
+				if err != nil {
+					return err
+				}
+				_ = signal.ObserveEntry(ctx, dir, entry)
+			}
+			return nil
+		})
+		services, _ := signal.GenerateServices(ctx)
+		if refiner, ok := signal.(discovery.ServiceRefiner); ok {
+			refiner.RefineServices(ctx, services)
+		}
+	})
+}
+
+func FuzzFlySignal(f *testing.F) {
+	fuzzSignal(f, "app/fly.toml", func(fs filesystems.FileSystem) discovery.ServiceSignal { return signals.NewFlySignal(fs) },
+		"app = \"shop\"\n\n[processes]\nweb = \"bin/rails server\"\nworker = \"sidekiq\"\n\n[http_service]\ninternal_port = 3000\nprocesses = [\"web\"]\n\n[[vm]]\nsize = \"shared-cpu-2x\"\nmemory = \"1gb\"\n",
+		"app = \"api\"\n[deploy]\nrelease_command = \"bin/migrate\"\n[mounts]\nsource = \"data\"\ndestination = \"/data\"\n[[services]]\ninternal_port = 8080\n[[services.ports]]\nport = 443\n",
+	)
+}
+
+func FuzzRenderSignal(f *testing.F) {
+	fuzzSignal(f, "app/render.yaml", func(fs filesystems.FileSystem) discovery.ServiceSignal { return signals.NewRenderSignal(fs) },
+		"services:\n  - type: web\n    name: web\n    runtime: node\n    rootDir: web\n    startCommand: npm start\n    envVars:\n      - key: DATABASE_URL\n        fromDatabase:\n          name: db\n          property: connectionString\n  - type: cron\n    name: digest\n    schedule: \"0 8 * * 1\"\ndatabases:\n  - name: db\n",
+	)
+}
+
+func FuzzDigitalOceanAppSignal(f *testing.F) {
+	fuzzSignal(f, "app/.do/app.yaml", func(fs filesystems.FileSystem) discovery.ServiceSignal { return signals.NewDigitalOceanAppSignal(fs) },
+		"name: shop\nservices:\n  - name: web\n    source_dir: web\n    http_port: 8080\n    instance_size_slug: apps-s-1vcpu-2gb\n    routes:\n      - path: /\nworkers:\n  - name: worker\n    run_command: npm run worker\njobs:\n  - name: migrate\n    kind: PRE_DEPLOY\n    run_command: npm run migrate\ndatabases:\n  - name: db\n    engine: PG\n",
+	)
+}
+
+func FuzzNetlifySignal(f *testing.F) {
+	fuzzSignal(f, "app/netlify.toml", func(fs filesystems.FileSystem) discovery.ServiceSignal { return signals.NewNetlifySignal(fs) },
+		"[build]\nbase = \"site\"\ncommand = \"npm run build\"\npublish = \"dist\"\n\n[[redirects]]\nfrom = \"/api/*\"\nto = \"/.netlify/functions/:splat\"\nstatus = 200\n",
+	)
+}
+
+func FuzzRailwaySignal(f *testing.F) {
+	fuzzSignal(f, "app/railway.json", func(fs filesystems.FileSystem) discovery.ServiceSignal { return signals.NewRailwaySignal(fs) },
+		`{"build": {"builder": "DOCKERFILE", "dockerfilePath": "Dockerfile"}, "deploy": {"startCommand": "npm start", "healthcheckPath": "/health", "cronSchedule": "0 * * * *"}}`,
+	)
+}
+
+func FuzzRailwayTomlSignal(f *testing.F) {
+	fuzzSignal(f, "app/railway.toml", func(fs filesystems.FileSystem) discovery.ServiceSignal { return signals.NewRailwaySignal(fs) },
+		"[build]\nbuilder = \"NIXPACKS\"\n\n[deploy]\nstartCommand = \"npm start\"\nnumReplicas = 2\n",
+	)
+}
+
+func FuzzHerokuAppJsonSignal(f *testing.F) {
+	fuzzSignal(f, "app/app.json", func(fs filesystems.FileSystem) discovery.ServiceSignal { return signals.NewHerokuAppJsonSignal(fs) },
+		`{"name": "shop", "scripts": {"postdeploy": "bin/setup"}, "formation": {"web": {"quantity": 2}}, "addons": ["heroku-postgresql"], "env": {"SECRET": {"generator": "secret"}}}`,
+	)
+}
+
+func FuzzHerokuProcfileSignal(f *testing.F) {
+	fuzzSignal(f, "app/Procfile", func(fs filesystems.FileSystem) discovery.ServiceSignal { return signals.NewHerokuProcfileSignal(fs) },
+		"web: gunicorn app:app -b 0.0.0.0:$PORT\nworker: celery -A tasks worker\nrelease: python manage.py migrate\n",
+	)
+}
@@ -0,0 +1,255 @@
+package discovery_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// addHelmChart adds a chart with a web Deployment behind an Ingress that's only enabled in production values
+func addHelmChart(mfs *filesystems.MemoryFS) {
+	mfs.AddFile("deploy/chart/Chart.yaml", []byte("apiVersion: v2\nname: shop\nversion: 0.1.0\n"))
+	mfs.AddFile("deploy/chart/values.yaml", []byte(`image:
+  repository: ghcr.io/acme/shop
+  tag: "1.0"
+port: 8080
+ingress:
+  enabled: false
+`))
+	mfs.AddFile("deploy/chart/values.prod.yaml", []byte("ingress:\n  enabled: true\n"))
+	mfs.AddFile("deploy/chart/templates/web.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+        - name: web
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+          args: ["serve"]
+          ports:
+            - containerPort: {{ .Values.port }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+  ports:
+    - port: 80
+      targetPort: {{ .Values.port }}
+{{- if .Values.ingress.enabled }}
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  defaultBackend:
+    service:
+      name: web
+{{- end }}
+`))
+}
+
+func TestHelmSignal_RendersChart(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	addHelmChart(mfs)
+
+	services := observeAll(t, mfs, signals.NewHelmSignal(mfs))
+
+	if len(services) != 1 {
+		t.Fatalf("Expected 1 service, got %d", len(services))
+	}
+
+	service := services[0]
+	if service.Image != "ghcr.io/acme/shop:1.0" {
+		t.Errorf("Expected image rendered from values, got %q", service.Image)
+	}
+	if service.Port != 8080 || service.StartCommand != "serve" {
+		t.Errorf("Expected port 8080 and command serve, got %d %q", service.Port, service.StartCommand)
+	}
+	if service.Network != types.NetworkPublic {
+		t.Errorf("Expected production values to enable the ingress, got network %v", service.Network)
+	}
+}
+
+func TestHelmSignal_ServicePerWorkload(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	addHelmChart(mfs)
+	mfs.AddFile("deploy/chart/templates/jobs.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-worker
+spec:
+  template:
+    spec:
+      containers:
+        - name: worker
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+          args: ["work"]
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: {{ .Release.Name }}-cleanup
+spec:
+  schedule: "0 3 * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: cleanup
+              image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+              args: ["cleanup"]
+`))
+
+	services := observeAll(t, mfs, signals.NewHelmSignal(mfs))
+
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+	if len(byName) != 3 {
+		t.Fatalf("Expected 3 services, got %v", services)
+	}
+
+	if web := byName["shop-web"]; web.Network != types.NetworkPublic || web.Runtime != types.RuntimeContinuous {
+		t.Errorf("Expected continuous public web service, got %+v", web)
+	}
+	if worker := byName["shop-worker"]; worker.Network != types.NetworkNone || worker.StartCommand != "work" {
+		t.Errorf("Expected unexposed worker running work, got %+v", worker)
+	}
+	cleanup := byName["shop-cleanup"]
+	if cleanup.Runtime != types.RuntimeScheduled || cleanup.Schedule != "0 3 * * *" {
+		t.Errorf("Expected scheduled cleanup service, got %+v", cleanup)
+	}
+}
+
+func TestHelmSignal_DependencyCharts(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	addHelmChart(mfs)
+	mfs.AddFile("deploy/chart/Chart.yaml", []byte(`apiVersion: v2
+name: shop
+version: 0.1.0
+dependencies:
+  - name: postgresql
+    version: 15.x.x
+    repository: oci://registry-1.docker.io/bitnamicharts
+  - name: redis
+    alias: cache
+    version: 19.x.x
+    repository: oci://registry-1.docker.io/bitnamicharts
+  - name: kafka
+    version: 28.x.x
+    repository: oci://registry-1.docker.io/bitnamicharts
+    condition: kafka.enabled
+`))
+	mfs.AddFile("deploy/chart/values.production.yaml", []byte("kafka:\n  enabled: false\n"))
+	mfs.AddFile("deploy/chart/charts/redis/Chart.yaml", []byte("apiVersion: v2\nname: redis\nversion: 19.0.0\n"))
+	mfs.AddFile("deploy/chart/charts/redis/templates/master.yaml", []byte(`apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: {{ .Release.Name }}-redis-master
+spec:
+  template:
+    spec:
+      containers:
+        - name: redis
+          image: docker.io/bitnami/redis:7.2
+`))
+
+	services := observeAll(t, mfs, signals.NewHelmSignal(mfs))
+
+	var names []string
+	for _, service := range services {
+		names = append(names, service.Name)
+	}
+	if len(services) != 3 || names[0] != "shop-web" || names[1] != "postgres" || names[2] != "redis" {
+		t.Fatalf("Expected web, postgres and redis services, got %v", names)
+	}
+	if services[1].Image != "postgres:16" || services[1].Network != types.NetworkPrivate {
+		t.Errorf("Expected private postgres image service, got %+v", services[1])
+	}
+}
+
+func TestHelmSignal_NetworkPolicies(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	addHelmChart(mfs)
+	mfs.AddFile("deploy/chart/templates/backend.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    metadata:
+      labels:
+        app: api
+    spec:
+      containers:
+        - name: api
+          image: ghcr.io/acme/api:1.0
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  template:
+    metadata:
+      labels:
+        app: db
+    spec:
+      containers:
+        - name: db
+          image: postgres:16
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: db-from-api
+spec:
+  podSelector:
+    matchLabels:
+      app: db
+  ingress:
+    - from:
+        - podSelector:
+            matchLabels:
+              app: api
+        - ipBlock:
+            cidr: 10.0.0.0/8
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: api-egress
+spec:
+  podSelector:
+    matchLabels:
+      app: api
+  policyTypes: [Egress]
+`))
+
+	services := observeAll(t, mfs, signals.NewHelmSignal(mfs))
+
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+	if db := byName["db"]; db.Ingress == nil || len(db.Ingress.From) != 1 || db.Ingress.From[0] != "api" {
+		t.Errorf("Expected the policy to only let api reach db, got %+v", db.Ingress)
+	}
+	if api := byName["api"]; api.Ingress != nil {
+		t.Errorf("Expected an egress-only policy to leave api's ingress alone, got %+v", api.Ingress)
+	}
+}
@@ -0,0 +1,89 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestMigrationSignal_ImpliesDatabase(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("package.json", []byte(`{"name": "app", "dependencies": {"express": "^4.0.0"}}`))
+	mfs.AddFile("prisma/schema.prisma", []byte(`datasource db {
+  provider = "mysql"
+  url      = env("DATABASE_URL")
+}
+`))
+
+	services, err := discovery.NewServiceDiscovery(mfs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	var found bool
+	for _, service := range services {
+		if service.Name == "mysql" {
+			found = true
+			if !service.Derived {
+				t.Error("Expected implied database to be marked derived")
+			}
+			if service.Image != "mysql:8" || service.Port != 3306 {
+				t.Errorf("Expected mysql:8 on 3306, got %s on %d", service.Image, service.Port)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("Expected an implied mysql service, got %+v", services)
+	}
+}
+
+func TestMigrationSignal_ExplicitDatabaseWins(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("shop/docker-compose.yml", []byte(`services:
+  db:
+    image: postgres:15
+`))
+	mfs.AddFile("shop/migrations/0001_init.up.sql", []byte("CREATE TABLE users (id serial);"))
+
+	services, err := discovery.NewServiceDiscovery(mfs).Discover(context.Background(), "shop")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	for _, service := range services {
+		if service.Derived {
+			t.Errorf("Expected no derived services when compose declares postgres, got %s", service.Name)
+		}
+	}
+}
+
+func TestDependencySignal_ImpliesBackingServices(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("shop/docker-compose.yml", []byte(`services:
+  cache:
+    image: redis:7-alpine
+`))
+	mfs.AddFile("shop/package.json", []byte(`{"name": "shop", "dependencies": {"ioredis": "^5.0.0", "kafkajs": "^2.0.0"}}`))
+	mfs.AddFile("shop/.env.example", []byte("MONGODB_URI=mongodb://localhost:27017/shop\n"))
+
+	services, err := discovery.NewServiceDiscovery(mfs).Discover(context.Background(), "shop")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	derived := make(map[string]bool)
+	for _, service := range services {
+		if service.Derived {
+			derived[service.Name] = true
+		}
+	}
+
+	if !derived["kafka"] || !derived["mongodb"] {
+		t.Errorf("Expected derived kafka and mongodb services, got %v", derived)
+	}
+	if derived["redis"] {
+		t.Error("Expected compose redis to satisfy the ioredis dependency")
+	}
+}
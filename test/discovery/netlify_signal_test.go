@@ -0,0 +1,108 @@
+package discovery_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestNetlifySignal_Configs(t *testing.T) {
+	type expected struct {
+		buildPath string
+		build     types.Build
+		outputDir string
+	}
+	tests := []struct {
+		name     string
+		files    map[string]string
+		services map[string]expected
+	}{
+		{
+			name:     "base and publish",
+			files:    map[string]string{"shop/netlify.toml": "[build]\nbase = \"/site/\"\npublish = \"dist\"\n"},
+			services: map[string]expected{"site": {"shop/site", types.BuildStatic, "dist"}},
+		},
+		{
+			name:     "no publish directory",
+			files:    map[string]string{"shop/netlify.toml": "[build]\ncommand = \"npm run build\"\n"},
+			services: map[string]expected{"shop": {"shop", types.BuildFromSource, ""}},
+		},
+		{
+			name: "default function directories",
+			files: map[string]string{
+				"shop/netlify.toml":                  "[build]\npublish = \"public\"\n",
+				"shop/netlify/functions/hello.js":    "export default () => new Response('hi')\n",
+				"shop/netlify/edge-functions/geo.ts": "export default () => {}\n",
+			},
+			services: map[string]expected{
+				"shop":                {"shop", types.BuildStatic, "public"},
+				"shop-functions":      {"shop/netlify/functions", types.BuildFromSource, ""},
+				"shop-edge-functions": {"shop/netlify/edge-functions", types.BuildFromSource, ""},
+			},
+		},
+		{
+			name: "configured function directory that isn't there",
+			files: map[string]string{
+				"shop/netlify.toml":           "[build]\npublish = \"public\"\nfunctions = \"api\"\n",
+				"shop/netlify/functions/a.js": "",
+			},
+			services: map[string]expected{"shop": {"shop", types.BuildStatic, "public"}},
+		},
+		{
+			name: "every netlify.toml",
+			files: map[string]string{
+				"apps/docs/netlify.toml": "[build]\npublish = \"build\"\n",
+				"apps/www/netlify.toml":  "[build]\npublish = \"out\"\n",
+			},
+			services: map[string]expected{
+				"docs": {"apps/docs", types.BuildStatic, "build"},
+				"www":  {"apps/www", types.BuildStatic, "out"},
+			},
+		},
+		{
+			name:     "base outside the repo",
+			files:    map[string]string{"shop/netlify.toml": "[build]\nbase = \"../..\"\npublish = \"dist\"\n"},
+			services: map[string]expected{},
+		},
+		{
+			name: "function directory outside the repo",
+			files: map[string]string{
+				"netlify.toml":   "[build]\npublish = \"public\"\nfunctions = \"../functions\"\n",
+				"functions/a.js": "",
+			},
+			services: map[string]expected{".": {".", types.BuildStatic, "public"}},
+		},
+		{
+			name:     "invalid config",
+			files:    map[string]string{"shop/netlify.toml": "[build\n"},
+			services: map[string]expected{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mfs := filesystems.NewMemoryFS()
+			for path, content := range tt.files {
+				mfs.AddFile(path, []byte(content))
+			}
+
+			services := observeAll(t, mfs, signals.NewNetlifySignal(mfs))
+			if len(services) != len(tt.services) {
+				t.Fatalf("Expected %d services, got %+v", len(tt.services), services)
+			}
+			for _, service := range services {
+				want, ok := tt.services[service.Name]
+				if !ok {
+					t.Errorf("Unexpected service %q", service.Name)
+					continue
+				}
+				got := expected{service.BuildPath, service.Build, service.OutputDir}
+				if got != want {
+					t.Errorf("Expected %s to be %+v, got %+v", service.Name, want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestOverrideSignal_TurnoutYAML(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("shop/turnout.yaml", []byte(`services:
+  web:
+    name: storefront
+    network: public
+    port: 8080
+    env:
+      LOG_LEVEL: debug
+  worker:
+    ignore: true
+  metrics:
+    image: prom/prometheus:v2.52.0
+    port: 9090
+`))
+	mfs.AddFile("shop/docker-compose.yml", []byte(`services:
+  web:
+    build: ./web
+    expose:
+      - "3000"
+  worker:
+    build: ./worker
+`))
+	mfs.AddFile("shop/web/Dockerfile", []byte("FROM node:20\nEXPOSE 3000\nCMD [\"node\", \"server.js\"]\n"))
+	mfs.AddFile("shop/worker/Dockerfile", []byte("FROM node:20\nCMD [\"node\", \"worker.js\"]\n"))
+
+	services, err := discovery.NewServiceDiscovery(mfs).Discover(context.Background(), "shop")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	web, ok := byName["storefront"]
+	if !ok {
+		t.Fatalf("Expected web renamed to storefront, got %v", services)
+	}
+	if web.Network != types.NetworkPublic || web.Port != 8080 || web.Variables["LOG_LEVEL"] != "debug" {
+		t.Errorf("Expected overrides to win, got %+v", web)
+	}
+	if provenance, ok := web.ProvenanceOf("Port"); !ok || provenance.Source != "override:shop/turnout.yaml" {
+		t.Errorf("Expected override provenance for the port, got %+v", web.Provenance)
+	}
+
+	if _, ok := byName["worker"]; ok {
+		t.Errorf("Expected the ignored worker to be dropped")
+	}
+	metrics, ok := byName["metrics"]
+	if !ok || metrics.Build != types.BuildFromImage || metrics.Network != types.NetworkPrivate {
+		t.Errorf("Expected metrics to be added as a private image service, got %+v", metrics)
+	}
+}
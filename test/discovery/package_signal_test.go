@@ -0,0 +1,42 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestPackageSignal_PackageManager(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("pnpm-lock.yaml", []byte("lockfileVersion: '9.0'"))
+	mfs.AddFile("apps/web/package.json", []byte(`{"dependencies": {"next": "14.0.0"}}`))
+	mfs.AddFile("apps/api/package.json", []byte(`{"packageManager": "yarn@4.1.0", "dependencies": {"express": "4.0.0"}}`))
+	mfs.AddFile("apps/worker/requirements.txt", []byte("celery==5.3.0"))
+	mfs.AddFile("apps/worker/uv.lock", []byte("version = 1"))
+
+	sd := discovery.NewServiceDiscovery(mfs, signals.NewPackageSignal(mfs))
+	services, err := sd.Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	expected := map[string]types.PackageManager{
+		"web":    types.PackageManagerPnpm,
+		"api":    types.PackageManagerYarn,
+		"worker": types.PackageManagerUv,
+	}
+
+	if len(services) != len(expected) {
+		t.Fatalf("Expected %d services, got %d", len(expected), len(services))
+	}
+
+	for _, service := range services {
+		if service.PackageManager != expected[service.Name] {
+			t.Errorf("Expected %s to use %q, got %q", service.Name, expected[service.Name], service.PackageManager)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/validation"
+)
+
+func TestServiceDiscovery_WindowsPlatforms(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("shop/docker-compose.yml", []byte(`services:
+  api:
+    build: ./api
+  iis:
+    image: mcr.microsoft.com/windows/servercore/iis:windowsservercore-ltsc2022
+  agent:
+    image: example/agent
+    platform: windows/amd64
+  proxy:
+    image: nginx
+    platform: linux/arm64
+`))
+	mfs.AddFile("shop/api/Dockerfile", []byte(`ARG OS=windows
+FROM --platform=${OS}/amd64 mcr.microsoft.com/dotnet/aspnet:8.0
+CMD ["dotnet", "api.dll"]
+`))
+	mfs.AddFile("shop/legacy/Legacy.csproj", []byte(`<Project ToolsVersion="15.0">
+  <PropertyGroup>
+    <TargetFrameworkVersion>v4.7.2</TargetFrameworkVersion>
+  </PropertyGroup>
+</Project>
+`))
+	mfs.AddFile("shop/modern/Modern.csproj", []byte(`<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFrameworks>net48;net8.0</TargetFrameworks>
+  </PropertyGroup>
+</Project>
+`))
+
+	services, err := discovery.NewServiceDiscovery(mfs).Discover(context.Background(), "shop")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	platforms := make(map[string]string)
+	for _, service := range services {
+		platforms[service.Name] = service.Platform
+	}
+	expected := map[string]string{"api": "windows", "iis": "windows", "agent": "windows", "legacy": "windows", "modern": "", "proxy": ""}
+	for name, platform := range expected {
+		if got, ok := platforms[name]; !ok || got != platform {
+			t.Errorf("Expected %s to need platform %q, got %q (found: %v)", name, platform, got, ok)
+		}
+	}
+
+	issues := validation.UnsupportedPlatforms(services)
+	if len(issues) != 4 {
+		t.Fatalf("Expected an unsupported-platform error per Windows service, got %+v", issues)
+	}
+	for _, issue := range issues {
+		if issue.Severity != validation.SeverityError || issue.File == "" {
+			t.Errorf("Expected an error pointing at the file that needs Windows, got %+v", issue)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package discovery_test
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestHerokuProcfileSignal_Processes(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		services map[string]string // name -> build path
+		releases map[string]string // build path -> pre-deploy command
+	}{
+		{
+			name:     "release becomes the pre-deploy command",
+			files:    map[string]string{"Procfile": "web: bundle exec puma\nworker: bundle exec sidekiq\nrelease: bin/rails db:migrate\n"},
+			services: map[string]string{"web": ".", "worker": "."},
+			releases: map[string]string{".": "bin/rails db:migrate"},
+		},
+		{
+			name:     "no release",
+			files:    map[string]string{"Procfile": "# processes\n\nweb: node server.js\n"},
+			services: map[string]string{"web": "."},
+		},
+		{
+			name: "nested Procfiles",
+			files: map[string]string{
+				"apps/api/Procfile": "web: node api.js\nrelease: npm run migrate\n",
+				"apps/www/Procfile": "web: node www.js\n",
+			},
+			services: map[string]string{"api-web": "apps/api", "www-web": "apps/www"},
+			releases: map[string]string{"apps/api": "npm run migrate"},
+		},
+		{
+			name:     "Procfile variants aren't deployed",
+			files:    map[string]string{"Procfile.dev": "web: npm run dev\n"},
+			services: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mfs := filesystems.NewMemoryFS()
+			for path, content := range tt.files {
+				mfs.AddFile(path, []byte(content))
+			}
+
+			services := observeAll(t, mfs, signals.NewHerokuProcfileSignal(mfs))
+			got := make(map[string]string)
+			for _, service := range services {
+				got[service.Name] = service.BuildPath
+				if release := tt.releases[service.BuildPath]; service.PreDeployCommand != release {
+					t.Errorf("Expected %s to run %q before deploying, got %q", service.Name, release, service.PreDeployCommand)
+				}
+			}
+			if !maps.Equal(got, tt.services) {
+				t.Errorf("Expected services %v, got %v", tt.services, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package discovery_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestServiceDiscovery_OnProgress(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("api/Dockerfile", []byte("FROM golang:1.25\nEXPOSE 8080\n"))
+	fs.AddFile("api/main.go", []byte("package main\n"))
+	fs.AddFile("README.md", []byte("# demo\n"))
+
+	sd := discovery.NewServiceDiscovery(fs)
+	var updates []discovery.Progress
+	sd.OnProgress(func(progress discovery.Progress) {
+		updates = append(updates, progress)
+	})
+
+	if _, err := sd.Discover(context.Background(), "."); err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(updates) == 0 {
+		t.Fatal("Expected progress updates")
+	}
+
+	last := updates[len(updates)-1]
+	if last.EntriesVisited != 4 {
+		t.Errorf("Expected 4 entries visited, got %d", last.EntriesVisited)
+	}
+	if !slices.ContainsFunc(updates, func(p discovery.Progress) bool { return p.Signal == "Dockerfile" }) {
+		t.Errorf("Expected the Dockerfile signal to be reported, got %+v", updates)
+	}
+}
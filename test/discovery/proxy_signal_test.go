@@ -0,0 +1,101 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestParseNginxConfig(t *testing.T) {
+	config := signals.ParseNginxConfig(`
+upstream backend {
+    server api:3000 weight=5;
+}
+
+server {
+    listen 0.0.0.0:8080 default_server; # public port
+    location /api/ {
+        proxy_pass http://backend/;
+    }
+    location / {
+        proxy_pass http://web:5173;
+    }
+    location /static/ {
+        proxy_pass http://127.0.0.1:9000;
+    }
+}
+`)
+
+	if len(config.ListenPorts) != 1 || config.ListenPorts[0] != 8080 {
+		t.Errorf("Expected listen port 8080, got %v", config.ListenPorts)
+	}
+
+	expected := []signals.ProxyUpstream{{Host: "api", Port: 3000}, {Host: "web", Port: 5173}}
+	if len(config.Upstreams) != len(expected) {
+		t.Fatalf("Expected upstreams %v, got %v", expected, config.Upstreams)
+	}
+	for i, upstream := range expected {
+		if config.Upstreams[i] != upstream {
+			t.Errorf("Expected upstream %v, got %v", upstream, config.Upstreams[i])
+		}
+	}
+}
+
+func TestParseCaddyfile(t *testing.T) {
+	config := signals.ParseCaddyfile(`{
+	email admin@example.com
+}
+
+example.com {
+	encode gzip
+	reverse_proxy /api/* api:4000
+	reverse_proxy web:3000
+}
+
+:8080 {
+	respond "ok"
+}
+`)
+
+	if len(config.ListenPorts) != 2 || config.ListenPorts[0] != 443 || config.ListenPorts[1] != 8080 {
+		t.Errorf("Expected listen ports [443 8080], got %v", config.ListenPorts)
+	}
+	if len(config.Upstreams) != 2 || config.Upstreams[0].Host != "api" || config.Upstreams[1].Port != 3000 {
+		t.Errorf("Expected api and web upstreams, got %v", config.Upstreams)
+	}
+}
+
+func TestProxySignal_MarksUpstreamsPrivate(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("docker-compose.yml", []byte(`services:
+  proxy:
+    build: ./proxy
+  api:
+    build: ./api
+`))
+	mfs.AddFile("proxy/Dockerfile", []byte("FROM nginx:alpine\nCOPY nginx.conf /etc/nginx/nginx.conf\n"))
+	mfs.AddFile("proxy/nginx.conf", []byte("server { listen 80; location / { proxy_pass http://api:3000; } }\n"))
+	mfs.AddFile("api/Dockerfile", []byte("FROM node:20\nEXPOSE 80\nCMD [\"node\", \"index.js\"]\n"))
+
+	sd := discovery.NewServiceDiscovery(mfs)
+	services, err := sd.Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	if proxy := byName["proxy"]; proxy.Network != types.NetworkPublic || proxy.Port != 80 {
+		t.Errorf("Expected public proxy on port 80, got network %v port %d", proxy.Network, proxy.Port)
+	}
+	if api := byName["api"]; api.Network != types.NetworkPrivate {
+		t.Errorf("Expected proxied api to be private, got %v", api.Network)
+	}
+}
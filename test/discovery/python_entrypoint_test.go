@@ -0,0 +1,79 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestServiceDiscovery_PythonEntrypoints(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		files   map[string]string
+		command string
+		port    int
+	}{
+		{
+			name: "fastapi module",
+			files: map[string]string{
+				"requirements.txt": "fastapi==0.110\nuvicorn[standard]==0.29\n",
+				"app/main.py":      "from fastapi import FastAPI\n\napi = FastAPI()\n",
+			},
+			command: "uvicorn app.main:api --host 0.0.0.0 --port 8000",
+			port:    8000,
+		},
+		{
+			name: "flask app",
+			files: map[string]string{
+				"requirements.txt": "flask==3.0\ngunicorn==21.2\n",
+				"app.py":           "from flask import Flask\napp = Flask(__name__)\n",
+			},
+			command: "gunicorn app:app --bind 0.0.0.0:8000",
+			port:    8000,
+		},
+		{
+			name: "django asgi with channels",
+			files: map[string]string{
+				"manage.py":        "",
+				"requirements.txt": "django==5.0\nchannels==4.0\ndaphne==4.1\n",
+				"chat/asgi.py":     "application = get_asgi_application()\n",
+				"chat/wsgi.py":     "application = get_wsgi_application()\n",
+			},
+			command: "daphne -b 0.0.0.0 -p 8000 chat.asgi:application",
+			port:    8000,
+		},
+		{
+			name: "django with gunicorn config",
+			files: map[string]string{
+				"manage.py":        "",
+				"pyproject.toml":   "[project]\ndependencies = [\"django>=5\", \"gunicorn\"]\n",
+				"shop/wsgi.py":     "application = get_wsgi_application()\n",
+				"gunicorn.conf.py": "bind = \"0.0.0.0:9000\"\nworkers = 4\n",
+			},
+			command: "gunicorn -c gunicorn.conf.py shop.wsgi:application",
+			port:    9000,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fs := filesystems.NewMemoryFS()
+			for path, content := range test.files {
+				fs.AddFile(path, []byte(content))
+			}
+			services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+			if err != nil {
+				t.Fatalf("Discover failed: %v", err)
+			}
+			if len(services) != 1 {
+				t.Fatalf("Expected one service, got %+v", services)
+			}
+			if services[0].StartCommand != test.command || services[0].Port != test.port {
+				t.Errorf("Expected %q on %d, got %q on %d", test.command, test.port, services[0].StartCommand, services[0].Port)
+			}
+			if provenance, ok := services[0].ProvenanceOf("StartCommand"); !ok || provenance.Source == "" {
+				t.Errorf("Expected the start command marked as inferred, got %+v", services[0].Provenance)
+			}
+		})
+	}
+}
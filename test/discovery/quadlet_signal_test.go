@@ -0,0 +1,100 @@
+package discovery_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestQuadletSignal_Units(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("deploy/web.container", []byte(`[Unit]
+Description=Web frontend
+
+[Container]
+Image=web.build
+PublishPort=80:3000
+Environment=NODE_ENV=production "GREETING=hello world"
+Volume=uploads.volume:/app/uploads:Z
+Volume=/etc/localtime:/etc/localtime:ro
+
+[Install]
+WantedBy=default.target
+`))
+	mfs.AddFile("deploy/web.build", []byte("[Build]\nImageTag=localhost/web\nFile=../Containerfile\nSetWorkingDirectory=file\n"))
+	mfs.AddFile("Containerfile", []byte("FROM node:20\n"))
+	mfs.AddFile("deploy/cache.container", []byte(`[Container]
+ContainerName=cache
+Image=docker.io/library/redis:7
+PublishPort=127.0.0.1:6379:6379/tcp
+Exec=redis-server --appendonly yes
+`))
+	mfs.AddFile("deploy/app.kube", []byte("[Kube]\nYaml=app.yaml\nPublishPort=8080:8000\n"))
+	mfs.AddFile("deploy/app.yaml", []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: api
+spec:
+  containers:
+    - name: api
+      image: ghcr.io/acme/api:1.2
+      ports:
+        - containerPort: 8000
+`))
+	mfs.AddFile("deploy/worker.service", []byte(`[Service]
+Environment=PODMAN_SYSTEMD_UNIT=%n
+ExecStartPre=-/usr/bin/podman rm -f worker
+ExecStart=/usr/bin/podman run --rm --name %N \
+  -e QUEUE=default -e SECRET \
+  ghcr.io/acme/worker:latest bundle exec sidekiq
+`))
+	mfs.AddFile("deploy/backup.service", []byte("[Service]\nExecStart=/usr/local/bin/backup.sh\n"))
+
+	services := make(map[string]types.Service)
+	for _, service := range observeAll(t, mfs, signals.NewQuadletSignal(mfs)) {
+		services[service.Name] = service
+	}
+	if len(services) != 4 {
+		t.Fatalf("expected web, cache, api and worker, got %d: %+v", len(services), services)
+	}
+
+	web := services["web"]
+	if web.Build != types.BuildFromSource || web.BuildPath != "." {
+		t.Errorf("expected web built from the .build unit's Containerfile directory, got %s from %q", web.Build, web.BuildPath)
+	}
+	if web.Network != types.NetworkPublic || web.Port != 3000 {
+		t.Errorf("expected web public on 3000, got %s on %d", web.Network, web.Port)
+	}
+	if web.Variables["NODE_ENV"] != "production" || web.Variables["GREETING"] != "hello world" {
+		t.Errorf("expected web's environment, got %v", web.Variables)
+	}
+	if len(web.Volumes) != 1 || web.Volumes[0] != (types.Volume{Name: "uploads", MountPath: "/app/uploads"}) {
+		t.Errorf("expected only the named volume, got %+v", web.Volumes)
+	}
+
+	cache := services["cache"]
+	if cache.Image != "docker.io/library/redis:7" || cache.Network != types.NetworkPrivate {
+		t.Errorf("expected a private redis image, got %+v", cache)
+	}
+	if len(cache.PublishedPorts) != 1 || cache.PublishedPorts[0] != (types.PortMapping{Published: "6379", Target: 6379}) {
+		t.Errorf("expected 6379 published, got %+v", cache.PublishedPorts)
+	}
+	if cache.StartCommand != "redis-server --appendonly yes" {
+		t.Errorf("expected Exec= as the start command, got %q", cache.StartCommand)
+	}
+
+	api := services["api"]
+	if api.Image != "ghcr.io/acme/api:1.2" || api.Port != 8000 || api.Network != types.NetworkPrivate {
+		t.Errorf("expected the kube pod published on 8000, got %+v", api)
+	}
+
+	worker := services["worker"]
+	if worker.Image != "ghcr.io/acme/worker:latest" || worker.StartCommand != "bundle exec sidekiq" {
+		t.Errorf("expected the podman run image and command, got %+v", worker)
+	}
+	if len(worker.Variables) != 1 || worker.Variables["QUEUE"] != "default" {
+		t.Errorf("expected only variables with values, got %v", worker.Variables)
+	}
+}
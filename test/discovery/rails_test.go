@@ -0,0 +1,74 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestServiceDiscovery_RailsProcessSplit(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("store/Gemfile", []byte("source \"https://rubygems.org\"\n\ngem \"rails\", \"~> 7.1\"\ngem \"sidekiq\"\ngem \"whenever\", require: false\n"))
+	fs.AddFile("store/config.ru", []byte("require_relative \"config/environment\"\nrun Rails.application\n"))
+	fs.AddFile("store/config/application.rb", []byte("require \"rails/all\"\n"))
+	fs.AddFile("store/config/sidekiq.yml", []byte(":concurrency: 5\n"))
+	fs.AddFile("store/config/schedule.rb", []byte("every 1.day, at: '4:30 am' do\n  runner \"Report.deliver\"\nend\n\nevery :monday, at: \"9pm\" do\n  rake \"orders:prune\"\nend\n"))
+
+	services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	web, ok := byName["store"]
+	if !ok || web.Network != types.NetworkPublic || web.Port != 3000 {
+		t.Fatalf("Expected a public Rails web service on 3000, got %+v", services)
+	}
+	for name, expected := range map[string]struct {
+		runtime  types.Runtime
+		command  string
+		schedule string
+	}{
+		"store-worker": {types.RuntimeContinuous, "bundle exec sidekiq -C config/sidekiq.yml", ""},
+		"store-cron-1": {types.RuntimeScheduled, `bin/rails runner "Report.deliver"`, "30 4 * * *"},
+		"store-cron-2": {types.RuntimeScheduled, "bundle exec rake orders:prune", "0 21 * * 1"},
+	} {
+		service, ok := byName[name]
+		if !ok {
+			t.Errorf("Expected a %s service, got %+v", name, services)
+			continue
+		}
+		if service.BuildPath != web.BuildPath || service.Network != types.NetworkNone || service.Runtime != expected.runtime {
+			t.Errorf("Expected %s built with web and unexposed, got %+v", name, service)
+		}
+		if service.StartCommand != expected.command || service.Schedule != expected.schedule {
+			t.Errorf("Expected %s to run %q on %q, got %q on %q", name, expected.command, expected.schedule, service.StartCommand, service.Schedule)
+		}
+	}
+	if len(services) != 5 {
+		t.Errorf("Expected web, worker, two cron services and Sidekiq's Redis, got %d services", len(services))
+	}
+}
+
+func TestServiceDiscovery_RackWithoutRails(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("Gemfile", []byte("source \"https://rubygems.org\"\n\ngem \"sinatra\"\ngem \"puma\"\n"))
+	fs.AddFile("config.ru", []byte("require \"./app\"\nrun Sinatra::Application\n"))
+
+	services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("Expected one service, got %+v", services)
+	}
+	if services[0].Port == 3000 {
+		t.Errorf("Expected a Rack app not to be taken for Rails, got %+v", services[0])
+	}
+}
@@ -0,0 +1,122 @@
+package discovery_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestRailwaySignal_ConfigPerDirectory(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("apps/web/railway.toml", []byte(`[deploy]
+startCommand = "npm start"
+`))
+	mfs.AddFile("apps/web/railway.json", []byte(`{"deploy": {"startCommand": "node server.js", "healthcheckPath": "/health"}}`))
+	mfs.AddFile("apps/jobs/railway.toml", []byte(`[build]
+dockerfilePath = "Dockerfile.jobs"
+
+[deploy]
+cronSchedule = "0 * * * *"
+preDeployCommand = ["bin/migrate", "bin/seed"]
+`))
+	mfs.AddFile("apps/jobs/Dockerfile.jobs", []byte("FROM alpine\n"))
+
+	services := observeAll(t, mfs, signals.NewRailwaySignal(mfs))
+
+	if len(services) != 2 {
+		t.Fatalf("Expected one service per directory, got %d", len(services))
+	}
+
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	web := byName["web"]
+	if web.Configs[0].Path != "apps/web/railway.json" || web.StartCommand != "node server.js" {
+		t.Errorf("Expected railway.json to take precedence in apps/web, got %v %q", web.Configs, web.StartCommand)
+	}
+	if web.HealthcheckPath != "/health" {
+		t.Errorf("Expected healthcheck /health, got %q", web.HealthcheckPath)
+	}
+
+	jobs := byName["jobs"]
+	if jobs.Runtime != types.RuntimeScheduled || jobs.Network != types.NetworkNone {
+		t.Errorf("Expected cron service to be scheduled without networking, got runtime %v network %v", jobs.Runtime, jobs.Network)
+	}
+	if len(jobs.Configs) != 2 || jobs.Configs[1].Path != "apps/jobs/Dockerfile.jobs" {
+		t.Errorf("Expected dockerfilePath config ref, got %v", jobs.Configs)
+	}
+}
+
+func TestRailwaySignal_Configs(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		configs   map[string]string // service -> the config it's read from
+		preDeploy string
+	}{
+		{
+			name:      "railway.json",
+			files:     map[string]string{"api/railway.json": `{"deploy": {"startCommand": "node api.js", "preDeployCommand": "npm run migrate"}}`},
+			configs:   map[string]string{"api": "api/railway.json"},
+			preDeploy: "npm run migrate",
+		},
+		{
+			name:      "railway.toml",
+			files:     map[string]string{"api/railway.toml": "[deploy]\nstartCommand = \"node api.js\"\npreDeployCommand = \"npm run migrate\"\n"},
+			configs:   map[string]string{"api": "api/railway.toml"},
+			preDeploy: "npm run migrate",
+		},
+		{
+			name: "JSON over TOML in one directory",
+			files: map[string]string{
+				"api/railway.toml": "[deploy]\nstartCommand = \"npm start\"\n",
+				"api/railway.json": `{"deploy": {"startCommand": "node api.js"}}`,
+			},
+			configs: map[string]string{"api": "api/railway.json"},
+		},
+		{
+			name: "a service per directory",
+			files: map[string]string{
+				"api/railway.json": `{"deploy": {"startCommand": "node api.js"}}`,
+				"web/railway.toml": "[deploy]\nstartCommand = \"npm start\"\n",
+			},
+			configs: map[string]string{"api": "api/railway.json", "web": "web/railway.toml"},
+		},
+		{
+			name:    "invalid config",
+			files:   map[string]string{"api/railway.json": `{"deploy": `},
+			configs: map[string]string{},
+		},
+		{
+			name:    "other JSON",
+			files:   map[string]string{"api/package.json": `{"name": "api"}`, "api/railway.example.json": `{"deploy": {}}`},
+			configs: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mfs := filesystems.NewMemoryFS()
+			for path, content := range tt.files {
+				mfs.AddFile(path, []byte(content))
+			}
+
+			services := observeAll(t, mfs, signals.NewRailwaySignal(mfs))
+			if len(services) != len(tt.configs) {
+				t.Fatalf("Expected %d services, got %+v", len(tt.configs), services)
+			}
+			for _, service := range services {
+				if service.Configs[0].Path != tt.configs[service.Name] {
+					t.Errorf("Expected %s from %s, got %v", service.Name, tt.configs[service.Name], service.Configs)
+				}
+				if service.PreDeployCommand != tt.preDeploy {
+					t.Errorf("Expected pre-deploy command %q, got %q", tt.preDeploy, service.PreDeployCommand)
+				}
+			}
+		})
+	}
+}
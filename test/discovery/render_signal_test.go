@@ -0,0 +1,53 @@
+package discovery_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestRenderSignal_RootDir(t *testing.T) {
+	tests := []struct {
+		name       string
+		configPath string
+		rootDir    string
+		buildPath  string // empty when the service is skipped
+	}{
+		{name: "rootDir", configPath: "render.yaml", rootDir: "services/api", buildPath: "services/api"},
+		{name: "rootDir with slashes", configPath: "render.yaml", rootDir: "/services/api/", buildPath: "services/api"},
+		{name: "rootDir under a nested blueprint", configPath: "deploy/render.yaml", rootDir: "api", buildPath: "deploy/api"},
+		{name: "dot rootDir", configPath: "render.yaml", rootDir: ".", buildPath: "."},
+		{name: "no rootDir", configPath: "render.yaml", buildPath: "."},
+		{name: "no rootDir in a nested blueprint", configPath: "deploy/render.yaml", buildPath: "deploy"},
+		{name: "rootDir climbing back into the repo", configPath: "render.yaml", rootDir: "web/../api", buildPath: "api"},
+		{name: "rootDir outside the repo", configPath: "render.yaml", rootDir: "../../escape"},
+		{name: "rootDir outside a nested blueprint", configPath: "deploy/render.yaml", rootDir: "../.."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blueprint := "services:\n  - type: web\n    name: api\n    runtime: node\n    startCommand: node server.js\n"
+			if tt.rootDir != "" {
+				blueprint += "    rootDir: " + tt.rootDir + "\n"
+			}
+			mfs := filesystems.NewMemoryFS()
+			mfs.AddFile(tt.configPath, []byte(blueprint))
+
+			signal := signals.NewRenderSignal(mfs)
+			services := observeAll(t, mfs, signal)
+			if tt.buildPath == "" {
+				if len(services) != 0 || len(signal.Warnings()) != 1 {
+					t.Fatalf("Expected the service skipped with a warning, got %+v and %+v", services, signal.Warnings())
+				}
+				return
+			}
+			if len(services) != 1 {
+				t.Fatalf("Expected 1 service, got %+v", services)
+			}
+			if services[0].BuildPath != tt.buildPath {
+				t.Errorf("Expected build path %q, got %q", tt.buildPath, services[0].BuildPath)
+			}
+		})
+	}
+}
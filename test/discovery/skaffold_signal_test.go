@@ -0,0 +1,103 @@
+package discovery_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestSkaffoldSignal_Manifests(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("skaffold.yaml", []byte(`apiVersion: skaffold/v2beta29
+kind: Config
+build:
+  artifacts:
+    - image: acme/web
+      context: ./web
+    - image: acme/worker
+      context: ./worker
+deploy:
+  kubectl:
+    manifests:
+      - k8s/*.yaml
+profiles:
+  - name: prod
+    deploy:
+      kustomize:
+        paths:
+          - k8s/overlays/prod
+`))
+	mfs.AddFile("k8s/web.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+        - name: web
+          image: acme/web
+          ports:
+            - containerPort: 3000
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+  ports:
+    - port: 80
+      targetPort: 3000
+`))
+	mfs.AddFile("k8s/worker.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  template:
+    spec:
+      containers:
+        - name: worker
+          image: acme/worker:latest
+`))
+	mfs.AddFile("k8s/overlays/prod/kustomization.yaml", []byte("resources:\n  - ../../web.yaml\n  - ingress.yaml\n"))
+	mfs.AddFile("k8s/overlays/prod/ingress.yaml", []byte(`apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  defaultBackend:
+    service:
+      name: web
+`))
+
+	discover := func(signal *signals.SkaffoldSignal) map[string]types.Service {
+		services := make(map[string]types.Service)
+		for _, service := range observeAll(t, mfs, signal) {
+			services[service.Name] = service
+		}
+		return services
+	}
+
+	services := discover(signals.NewSkaffoldSignal(mfs))
+	if web := services["web"]; web.Network != types.NetworkPrivate || web.Port != 3000 {
+		t.Errorf("Expected private web service on 3000, got %+v", web)
+	}
+	if worker := services["worker"]; worker.Network != types.NetworkNone {
+		t.Errorf("Expected unexposed worker, got %+v", worker)
+	}
+
+	prod := signals.NewSkaffoldSignal(mfs)
+	prod.Profiles = []string{"prod"}
+	services = discover(prod)
+	if web := services["web"]; web.Network != types.NetworkPublic {
+		t.Errorf("Expected the prod profile's ingress to make web public, got %+v", web)
+	}
+}
@@ -0,0 +1,145 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestServiceDiscovery_SpringBootModules(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("shop/pom.xml", []byte(`<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>3.2.0</version>
+  </parent>
+  <artifactId>shop</artifactId>
+  <version>1.0.0</version>
+  <packaging>pom</packaging>
+  <modules>
+    <module>common</module>
+    <module>api</module>
+    <module>worker</module>
+  </modules>
+</project>
+`))
+	fs.AddFile("shop/common/pom.xml", []byte(`<project>
+  <parent><artifactId>shop</artifactId><version>1.0.0</version></parent>
+  <artifactId>common</artifactId>
+  <dependencies>
+    <dependency><groupId>org.springframework.boot</groupId><artifactId>spring-boot-starter-data-jpa</artifactId></dependency>
+  </dependencies>
+</project>
+`))
+	fs.AddFile("shop/api/pom.xml", []byte(`<project>
+  <parent><artifactId>shop</artifactId><version>1.0.0</version></parent>
+  <artifactId>shop-api</artifactId>
+  <dependencies>
+    <dependency><groupId>org.springframework.boot</groupId><artifactId>spring-boot-starter-web</artifactId></dependency>
+    <dependency><groupId>org.springframework.boot</groupId><artifactId>spring-boot-starter-actuator</artifactId></dependency>
+  </dependencies>
+  <build>
+    <plugins>
+      <plugin><groupId>org.springframework.boot</groupId><artifactId>spring-boot-maven-plugin</artifactId></plugin>
+    </plugins>
+  </build>
+</project>
+`))
+	fs.AddFile("shop/api/src/main/resources/application.yml", []byte(`server:
+  port: ${PORT:8081}
+  servlet:
+    context-path: /api
+---
+spring:
+  config:
+    activate:
+      on-profile: dev
+server:
+  port: 9999
+`))
+	fs.AddFile("shop/api/src/main/resources/application-prod.yml", []byte("management:\n  endpoints:\n    web:\n      base-path: /manage\n"))
+	fs.AddFile("shop/worker/pom.xml", []byte(`<project>
+  <parent><artifactId>shop</artifactId><version>1.0.0</version></parent>
+  <artifactId>worker</artifactId>
+  <build>
+    <plugins>
+      <plugin><groupId>org.springframework.boot</groupId><artifactId>spring-boot-maven-plugin</artifactId></plugin>
+    </plugins>
+  </build>
+</project>
+`))
+	fs.AddFile("shop/worker/src/main/resources/application.properties", []byte("spring.main.web-application-type=none\n"))
+
+	services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+	if len(services) != 2 {
+		t.Fatalf("Expected the api and worker apps, but not the common library, got %+v", services)
+	}
+
+	api := byName["api"]
+	if api.BuildPath != "shop" || api.Network != types.NetworkPublic {
+		t.Errorf("Expected a public api built from the root of its build, got %+v", api)
+	}
+	if api.Port != 8081 {
+		t.Errorf("Expected server.port's default of 8081, got %d", api.Port)
+	}
+	if api.HealthcheckPath != "/api/manage/health" {
+		t.Errorf("Expected the prod profile's actuator base path under the context path, got %q", api.HealthcheckPath)
+	}
+	if api.StartCommand != "java -jar api/target/shop-api-1.0.0.jar" {
+		t.Errorf("Expected the api to start from its jar, got %q", api.StartCommand)
+	}
+	if api.Variables["SPRING_PROFILES_ACTIVE"] != "prod" {
+		t.Errorf("Expected the api to deploy with the prod profile, got %v", api.Variables)
+	}
+
+	worker := byName["worker"]
+	if worker.BuildPath != "shop" || worker.Network != types.NetworkNone || worker.HealthcheckPath != "" {
+		t.Errorf("Expected an unexposed worker built from the root of its build, got %+v", worker)
+	}
+	if worker.StartCommand != "java -jar worker/target/worker-1.0.0.jar" {
+		t.Errorf("Expected the worker to start from its jar, versioned like its parent, got %q", worker.StartCommand)
+	}
+}
+
+func TestServiceDiscovery_SpringBootWithoutActuator(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("build.gradle", []byte(`plugins {
+    id 'java'
+    id 'org.springframework.boot' version '3.2.0'
+}
+
+version = '0.0.1-SNAPSHOT'
+
+dependencies {
+    implementation 'org.springframework.boot:spring-boot-starter-web'
+}
+`))
+	fs.AddFile("settings.gradle", []byte("rootProject.name = 'demo'\n"))
+	fs.AddFile("src/main/resources/application.properties", []byte("server.port=7000\n"))
+
+	services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("Expected one service, got %+v", services)
+	}
+	service := services[0]
+	if service.Port != 7000 || service.HealthcheckPath != "" {
+		t.Errorf("Expected port 7000 and no actuator healthcheck, got %d and %q", service.Port, service.HealthcheckPath)
+	}
+	if service.StartCommand != "java -jar build/libs/demo-0.0.1-SNAPSHOT.jar" {
+		t.Errorf("Expected the boot jar as the start command, got %q", service.StartCommand)
+	}
+}
@@ -0,0 +1,57 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/validation"
+)
+
+func TestServiceDiscovery_StaleConfigs(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("render.yaml", []byte(`services:
+  - type: web
+    name: legacy
+    runtime: node
+    rootDir: services/legacy
+    startCommand: node index.js
+`))
+	fs.AddFile("api/fly.toml", []byte("app = \"api\"\n\n[build]\ndockerfile = \"Dockerfile.prod\"\n"))
+	fs.AddFile("api/Dockerfile", []byte("FROM node:20\nCMD [\"node\", \"server.js\"]\n"))
+	fs.AddFile("api/railway.json", []byte(`{"deploy": {"startCommand": "node server.js"}}`))
+
+	services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+	legacy, api := byName["legacy"], byName["api"]
+	if len(legacy.Missing) != 1 || legacy.Missing[0].Setting != "rootDir" || legacy.Missing[0].Path != "services/legacy" {
+		t.Errorf("Expected the missing rootDir recorded, got %+v", legacy.Missing)
+	}
+	if len(api.Missing) != 1 || api.Missing[0].Path != "api/Dockerfile.prod" {
+		t.Errorf("Expected the missing Dockerfile recorded, got %+v", api.Missing)
+	}
+	for _, config := range api.Configs {
+		if config.Path == "api/Dockerfile.prod" {
+			t.Errorf("Expected no config ref to the missing Dockerfile, got %+v", api.Configs)
+		}
+	}
+
+	stale := make(map[string]string)
+	for _, issue := range validation.Validate(services, nil) {
+		if issue.Code == validation.CodeStaleConfig {
+			stale[issue.Service] = issue.File
+		}
+	}
+	if stale["legacy"] != "render.yaml" || stale["api"] != "api/fly.toml" || len(stale) != 2 {
+		t.Errorf("Expected stale config warnings for render.yaml and fly.toml, got %v", stale)
+	}
+}
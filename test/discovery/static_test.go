@@ -0,0 +1,98 @@
+package discovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestServiceDiscovery_ViteApps(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		build     types.Build
+		outputDir string
+		port      int
+	}{
+		{
+			name: "plain SPA",
+			files: map[string]string{
+				"package.json":   `{"dependencies": {"react": "18.3.0", "react-router-dom": "6.26.0"}, "devDependencies": {"vite": "5.4.0", "@vitejs/plugin-react": "4.3.0"}}`,
+				"vite.config.ts": "import { defineConfig } from 'vite'\nimport react from '@vitejs/plugin-react'\n\nexport default defineConfig({ plugins: [react()] })\n",
+			},
+			build:     types.BuildStatic,
+			outputDir: "dist",
+		},
+		{
+			name: "SPA with outDir",
+			files: map[string]string{
+				"package.json":   `{"devDependencies": {"vite": "5.4.0"}}`,
+				"vite.config.js": "export default { build: { outDir: 'public/app' } }\n",
+			},
+			build:     types.BuildStatic,
+			outputDir: "public/app",
+		},
+		{
+			name: "Remix on Vite",
+			files: map[string]string{
+				"package.json":   `{"dependencies": {"@remix-run/node": "2.12.0", "@remix-run/react": "2.12.0", "@remix-run/serve": "2.12.0"}, "devDependencies": {"@remix-run/dev": "2.12.0", "vite": "5.4.0"}}`,
+				"vite.config.ts": "import { vitePlugin as remix } from '@remix-run/dev'\nimport { defineConfig } from 'vite'\n\nexport default defineConfig({ plugins: [remix()] })\n",
+			},
+			build: types.BuildFromSource,
+			port:  3000,
+		},
+		{
+			name: "Remix SPA mode",
+			files: map[string]string{
+				"package.json":   `{"dependencies": {"@remix-run/react": "2.12.0"}, "devDependencies": {"@remix-run/dev": "2.12.0", "vite": "5.4.0"}}`,
+				"vite.config.ts": "import { vitePlugin as remix } from '@remix-run/dev'\nimport { defineConfig } from 'vite'\n\nexport default defineConfig({ plugins: [remix({ ssr: false })] })\n",
+			},
+			build:     types.BuildStatic,
+			outputDir: "build/client",
+		},
+		{
+			name: "React Router 7",
+			files: map[string]string{
+				"package.json":           `{"dependencies": {"react-router": "7.1.0", "@react-router/node": "7.1.0", "@react-router/serve": "7.1.0"}, "devDependencies": {"@react-router/dev": "7.1.0", "vite": "5.4.0"}}`,
+				"vite.config.ts":         "import { reactRouter } from '@react-router/dev/vite'\nimport { defineConfig } from 'vite'\n\nexport default defineConfig({ plugins: [reactRouter()] })\n",
+				"react-router.config.ts": "import type { Config } from '@react-router/dev/config'\n\nexport default { ssr: true } satisfies Config\n",
+			},
+			build: types.BuildFromSource,
+			port:  3000,
+		},
+		{
+			name: "React Router 7 SPA mode",
+			files: map[string]string{
+				"package.json":           `{"dependencies": {"react-router": "7.1.0"}, "devDependencies": {"@react-router/dev": "7.1.0", "vite": "5.4.0"}}`,
+				"vite.config.ts":         "import { reactRouter } from '@react-router/dev/vite'\nimport { defineConfig } from 'vite'\n\nexport default defineConfig({ plugins: [reactRouter()] })\n",
+				"react-router.config.ts": "import type { Config } from '@react-router/dev/config'\n\nexport default { ssr: false } satisfies Config\n",
+			},
+			build:     types.BuildStatic,
+			outputDir: "build/client",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := filesystems.NewMemoryFS()
+			for path, content := range tt.files {
+				fs.AddFile("app/"+path, []byte(content))
+			}
+
+			services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+			if err != nil {
+				t.Fatalf("Discover failed: %v", err)
+			}
+			if len(services) != 1 {
+				t.Fatalf("Expected one service, got %+v", services)
+			}
+			service := services[0]
+			if service.Build != tt.build || service.OutputDir != tt.outputDir || service.Port != tt.port {
+				t.Errorf("Expected build %v to %q on port %d, got build %v to %q on port %d", tt.build, tt.outputDir, tt.port, service.Build, service.OutputDir, service.Port)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package discovery_test
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestServiceDiscovery_TestdataMonorepo(t *testing.T) {
+	fs := filesystems.NewIOFS(os.DirFS("testdata/monorepo"))
+
+	services, err := discovery.NewServiceDiscovery(fs).Discover(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	names := make([]string, 0, len(services))
+	for _, service := range services {
+		names = append(names, service.Name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"api", "web"}) {
+		t.Fatalf("Expected api and web services, got %v", names)
+	}
+	api := services[slices.IndexFunc(services, func(s types.Service) bool { return s.Name == "api" })]
+	if api.Port != 8080 {
+		t.Errorf("Expected the api's port from its Dockerfile, got %d", api.Port)
+	}
+}
@@ -0,0 +1,36 @@
+package discovery_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/signals"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestVercelSignal_FunctionsAndCrons(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("site/vercel.json", []byte(`{
+  "functions": {"api/**/*.ts": {"maxDuration": 30}},
+  "crons": [{"path": "/api/cleanup", "schedule": "0 5 * * *"}]
+}`))
+
+	services := observeAll(t, mfs, signals.NewVercelSignal(mfs))
+
+	byName := make(map[string]types.Service)
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	if len(services) != 3 {
+		t.Fatalf("Expected deployment, function and cron services, got %v", byName)
+	}
+	if _, ok := byName["site-api"]; !ok {
+		t.Errorf("Expected a site-api function service, got %v", byName)
+	}
+
+	cron := byName["site-cron-api-cleanup"]
+	if cron.Runtime != types.RuntimeScheduled || cron.Schedule != "0 5 * * *" {
+		t.Errorf("Expected scheduled cron service, got %+v", cron)
+	}
+}
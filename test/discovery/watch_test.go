@@ -0,0 +1,36 @@
+package discovery_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/discovery/types"
+)
+
+func TestDiffServices(t *testing.T) {
+	before := []types.Service{
+		{Name: "web", Port: 3000, Configs: []types.ConfigRef{{Type: "dockerfile", Path: "web/Dockerfile"}, {Type: "railway", Path: "web/railway.json"}}},
+		{Name: "worker"},
+	}
+	after := []types.Service{
+		{Name: "api"},
+		{Name: "web", Port: 8080, Configs: []types.ConfigRef{{Type: "railway", Path: "web/railway.json"}, {Type: "dockerfile", Path: "web/Dockerfile"}}},
+	}
+
+	changes := discovery.DiffServices(before, after)
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %+v", changes)
+	}
+	expected := []struct {
+		kind discovery.ServiceChangeKind
+		name string
+	}{{discovery.ServiceAdded, "api"}, {discovery.ServiceChanged, "web"}, {discovery.ServiceRemoved, "worker"}}
+	for i, e := range expected {
+		if changes[i].Kind != e.kind || changes[i].Service.Name != e.name {
+			t.Errorf("Expected %s %s, got %s %s", e.kind, e.name, changes[i].Kind, changes[i].Service.Name)
+		}
+	}
+	if fields := changes[1].Fields; len(fields) != 1 || fields[0] != "Port" {
+		t.Errorf("Expected only Port to change, config order aside, got %v", fields)
+	}
+}
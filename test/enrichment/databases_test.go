@@ -0,0 +1,57 @@
+package enrichment_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/enrichment"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+func TestManagedDatabases(t *testing.T) {
+	project := schema.NewProject("shop")
+	for name, image := range map[string]string{
+		"db":     "postgres:15.4-alpine",
+		"cache":  "docker.io/library/redis",
+		"geo":    "postgis/postgis:16-3.4",
+		"store":  "minio/minio:RELEASE.2024-05-01T01-11-10Z",
+		"search": "getmeili/meilisearch:v1.8",
+	} {
+		service := schema.NewService(name)
+		service.Image = image
+		project.AddService(service)
+	}
+	web := schema.NewService("web")
+	web.SourcePath = "."
+	project.AddService(web)
+
+	enrichment.ManagedDatabases(project)
+
+	services := make(map[string]schema.Service)
+	for _, service := range project.Services {
+		services[service.Name] = service
+	}
+
+	db := services["db"]
+	if db.Managed == nil || db.Managed.Kind != "postgres" || db.Managed.Version != "15.4" {
+		t.Fatalf("Expected db to be a managed Postgres 15.4, got %+v", db.Managed)
+	}
+	if db.Image != "ghcr.io/railwayapp-templates/postgres-ssl:15" {
+		t.Errorf("Expected Railway's Postgres image for the major version, got %s", db.Image)
+	}
+	if len(db.Volumes) != 1 || db.Volumes[0].MountPath != "/var/lib/postgresql/data" || db.Environment["PGDATA"].Value == "" {
+		t.Errorf("Expected a data volume and PGDATA, got %+v %+v", db.Volumes, db.Environment)
+	}
+
+	if cache := services["cache"]; cache.Managed == nil || cache.Image != "redis:7" || cache.Managed.Version != "" {
+		t.Errorf("Expected an untagged redis to get the default version, got %s %+v", cache.Image, cache.Managed)
+	}
+	if geo := services["geo"]; geo.Managed == nil || geo.Managed.Kind != "postgres" || geo.Image != "postgis/postgis:16-3.4" {
+		t.Errorf("Expected PostGIS to be marked but keep its image, got %s %+v", geo.Image, geo.Managed)
+	}
+	if store := services["store"]; store.Image != "minio/minio:latest" || store.StartCommand == "" {
+		t.Errorf("Expected MinIO with a start command, got %s %q", store.Image, store.StartCommand)
+	}
+	if services["search"].Managed != nil || services["web"].Managed != nil {
+		t.Errorf("Expected unrecognized images and source services to be left alone")
+	}
+}
@@ -0,0 +1,70 @@
+package enrichment_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/enrichment"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+func TestServiceReferences(t *testing.T) {
+	project := schema.NewProject("shop")
+
+	db := schema.NewService("db")
+	db.Image = "postgres:16"
+	project.AddService(db)
+
+	cache := schema.NewService("cache")
+	cache.Image = "redis:7"
+	project.AddService(cache)
+
+	api := schema.NewService("api")
+	api.SourcePath = "api"
+	api.Environment["DATABASE_URL"] = schema.NewEnvVar("postgres://app:secret@db:5432/shop", true)
+	api.Environment["REDIS_URL"] = schema.NewEnvVar("redis://localhost:6379", false)
+	api.Environment["APP_NAME"] = schema.NewEnvVar("web", false)
+	project.AddService(api)
+
+	web := schema.NewService("web")
+	web.SourcePath = "web"
+	web.Environment["API_URL"] = schema.NewEnvVar("http://api:8080/v1", false)
+	web.Environment["API_HOST"] = schema.NewEnvVar("api", false)
+	web.Environment["PUBLIC_URL"] = schema.NewEnvVar("https://shop.example.com", false)
+	project.AddService(web)
+
+	enrichment.ManagedDatabases(project)
+	enrichment.ServiceReferences(project)
+
+	services := make(map[string]schema.Service)
+	for _, service := range project.Services {
+		services[service.Name] = service
+	}
+
+	expected := map[string]map[string]string{
+		"api": {
+			"DATABASE_URL": "${{db.DATABASE_URL}}",
+			"REDIS_URL":    "${{cache.REDIS_URL}}",
+			"APP_NAME":     "web",
+		},
+		"web": {
+			"API_URL":    "http://${{api.RAILWAY_PRIVATE_DOMAIN}}:8080/v1",
+			"API_HOST":   "${{api.RAILWAY_PRIVATE_DOMAIN}}",
+			"PUBLIC_URL": "https://shop.example.com",
+		},
+	}
+	for service, variables := range expected {
+		for name, value := range variables {
+			if got := services[service].Environment[name].RailwayValue(); got != value {
+				t.Errorf("Expected %s's %s to be %q, got %q", service, name, value, got)
+			}
+		}
+	}
+
+	if deps := services["api"].Dependencies; !slices.Equal(deps, []string{"db", "cache"}) {
+		t.Errorf("Expected api to depend on db and cache, got %v", deps)
+	}
+	if deps := services["web"].Dependencies; !slices.Equal(deps, []string{"api"}) {
+		t.Errorf("Expected web to depend on api, got %v", deps)
+	}
+}
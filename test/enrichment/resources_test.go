@@ -0,0 +1,42 @@
+package enrichment_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/enrichment"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+func TestServiceSizes(t *testing.T) {
+	project := schema.NewProject("shop")
+	for name, resources := range map[string]*schema.Resources{
+		"web":    {CPU: 0.5, MemoryMB: 512},
+		"worker": {CPU: 16, MemoryMB: 4096},
+		"batch":  {CPU: 48, MemoryMB: 65536},
+		"cache":  nil,
+	} {
+		service := schema.NewService(name)
+		service.Resources = resources
+		project.AddService(service)
+	}
+
+	enrichment.ServiceSizes(project)
+
+	expected := map[string]schema.Resources{
+		"web":    {CPU: 0.5, MemoryMB: 512, VCPU: 1, MemoryGB: 1, Plan: "Hobby"},
+		"worker": {CPU: 16, MemoryMB: 4096, VCPU: 16, MemoryGB: 4, Plan: "Pro"},
+		"batch":  {CPU: 48, MemoryMB: 65536, VCPU: 48, MemoryGB: 64},
+	}
+	for _, service := range project.Services {
+		want, ok := expected[service.Name]
+		if !ok {
+			if service.Resources != nil {
+				t.Errorf("Expected %s to have no resources, got %+v", service.Name, service.Resources)
+			}
+			continue
+		}
+		if service.Resources == nil || *service.Resources != want {
+			t.Errorf("Expected %s to be sized %+v, got %+v", service.Name, want, service.Resources)
+		}
+	}
+}
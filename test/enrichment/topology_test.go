@@ -0,0 +1,49 @@
+package enrichment_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/enrichment"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+func TestNetworkTopology(t *testing.T) {
+	project := schema.NewProject("shop")
+	for _, service := range []struct {
+		name     string
+		networks []string
+		ingress  *schema.IngressPolicy
+	}{
+		{"web", []string{"shop_frontend"}, nil},
+		{"api", []string{"shop_frontend", "shop_backend"}, nil},
+		{"worker", []string{"shop_backend"}, nil},
+		{"db", []string{"shop_backend"}, &schema.IngressPolicy{From: []string{"api"}}},
+	} {
+		s := schema.NewService(service.name)
+		s.Networks = service.networks
+		s.Ingress = service.ingress
+		project.AddService(s)
+	}
+
+	enrichment.NetworkTopology(project)
+
+	var links []string
+	for _, link := range project.Topology {
+		links = append(links, link.From+"->"+link.To)
+	}
+	slices.Sort(links)
+	expected := []string{"api->web", "api->worker", "api->db", "db->api", "db->worker", "web->api", "worker->api"}
+	slices.Sort(expected)
+	if !slices.Equal(links, expected) {
+		t.Errorf("Expected links %v, got %v", expected, links)
+	}
+
+	unrestricted := schema.NewProject("flat")
+	unrestricted.AddService(schema.NewService("web"))
+	unrestricted.AddService(schema.NewService("api"))
+	enrichment.NetworkTopology(unrestricted)
+	if unrestricted.Topology != nil {
+		t.Errorf("Expected no topology without networks or policies, got %v", unrestricted.Topology)
+	}
+}
@@ -0,0 +1,73 @@
+package environment_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/environment"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func benchmarkExtract(b *testing.B, filename, content string) {
+	extractor := environment.NewExtractor(filesystems.NewMemoryFS())
+	ctx := context.Background()
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		found := 0
+		for range extractor.Extract(ctx, filename, []byte(content)) {
+			found++
+		}
+		if found == 0 {
+			b.Fatal("Expected variables")
+		}
+	}
+}
+
+// BenchmarkExtract_LargeSource extracts from a 1MB bundled JavaScript file
+func BenchmarkExtract_LargeSource(b *testing.B) {
+	var source strings.Builder
+	for i := 0; source.Len() < 1<<20; i++ {
+		fmt.Fprintf(&source, "export function handler%d(req, res) {\n  const url = process.env.SERVICE_%d_URL ?? 'http://localhost'\n  return fetch(url + req.path).then((r) => res.send(r))\n}\n", i, i%500)
+	}
+	benchmarkExtract(b, "dist/bundle.js", source.String())
+}
+
+// BenchmarkExtract_LargeDotenv extracts from a 20,000-line env file
+func BenchmarkExtract_LargeDotenv(b *testing.B) {
+	var dotenv strings.Builder
+	for i := range 20_000 {
+		fmt.Fprintf(&dotenv, "# setting %d\nVARIABLE_%d=\"value-%d with ${VARIABLE_%d}\"\n", i, i, i, max(i-1, 0))
+	}
+	benchmarkExtract(b, ".env", dotenv.String())
+}
+
+// BenchmarkExtractServices extracts every file of 50 services with shared env files
+func BenchmarkExtractServices(b *testing.B) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("repo/.env", []byte("DATABASE_URL=postgres://db:5432/app\nREDIS_URL=redis://cache:6379\n"))
+	var services []types.Service
+	for service := range 50 {
+		dir := fmt.Sprintf("repo/services/svc%d", service)
+		mfs.AddFile(dir+"/.env.example", []byte("PORT=3000\nAPI_KEY=\n"))
+		for i := range 100 {
+			mfs.AddFile(fmt.Sprintf("%s/src/module%d.py", dir, i), []byte("import os\n\nDEBUG = os.environ.get('DEBUG', 'false')\nSECRET = os.getenv('SECRET_KEY')\n"))
+		}
+		services = append(services, types.Service{Name: fmt.Sprintf("svc%d", service), BuildPath: dir})
+	}
+
+	extractor := environment.NewExtractor(mfs)
+	extractor.SetRoot("repo")
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		if results := extractor.ExtractServices(ctx, services); len(results) != len(services) {
+			b.Fatalf("Expected variables for every service, got %d", len(results))
+		}
+	}
+}
@@ -2,8 +2,11 @@ package environment_test
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"testing"
 
+	discoveryTypes "github.com/railwayapp/turnout/internal/discovery/types"
 	"github.com/railwayapp/turnout/internal/environment"
 	"github.com/railwayapp/turnout/internal/environment/extractors"
 	"github.com/railwayapp/turnout/internal/environment/types"
@@ -69,6 +72,44 @@ func TestExtractor_DockerCompose(t *testing.T) {
 	}
 }
 
+func TestExtractor_DockerComposeEnvFilesAndInterpolation(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("app/docker-compose.yml", []byte(`services:
+  web:
+    image: app:${TAG:-latest}
+    env_file:
+      - config/web.env
+      - path: missing.env
+        required: false
+    environment:
+      LOG_LEVEL: info
+      DATABASE_URL: postgres://${DB_USER}@db/app
+`))
+	fs.AddFile("app/config/web.env", []byte("SECRET_KEY=abc123\nLOG_LEVEL=debug\n"))
+
+	var results []types.EnvResult
+	for result := range environment.NewExtractor(fs).ExtractFile(context.Background(), "app/docker-compose.yml") {
+		results = append(results, result)
+	}
+	merged := map[string]types.EnvResult{}
+	for _, result := range environment.Merge(results) {
+		merged[result.VarName] = result
+	}
+
+	if secret := merged["SECRET_KEY"]; secret.Value != "abc123" || secret.Source != "dotenv:app/config/web.env" {
+		t.Errorf("Expected SECRET_KEY from the service's env_file, got %+v", secret)
+	}
+	if level := merged["LOG_LEVEL"]; level.Value != "info" {
+		t.Errorf("Expected environment to override env_file, got %+v", level)
+	}
+	if tag := merged["TAG"]; tag.Value != "latest" || tag.Source != "usage:app/docker-compose.yml" || tag.Span.Line != 3 || tag.Span.Column != 18 {
+		t.Errorf("Expected TAG to be required by interpolation with its default, got %+v", tag)
+	}
+	if user, ok := merged["DB_USER"]; !ok || user.Value != "" {
+		t.Errorf("Expected DB_USER to be required by interpolation without a default, got %+v", user)
+	}
+}
+
 func TestExtractor_DotEnv(t *testing.T) {
 	fs := filesystems.NewMemoryFS()
 	extractor := environment.NewExtractor(fs)
@@ -102,3 +143,573 @@ PORT=3000
 		t.Error("API_KEY should be classified as sensitive")
 	}
 }
+
+func TestExtractor_RenderEnvGroups(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	extractor := environment.NewExtractor(fs)
+	ctx := context.Background()
+
+	renderContent := `services:
+  - type: web
+    name: api
+    envVars:
+      - key: PORT
+        value: 8080
+      - key: DATABASE_URL
+        fromDatabase:
+          name: db
+          property: connectionString
+      - key: SEARCH_HOSTPORT
+        fromService:
+          name: search
+          type: pserv
+          property: hostport
+      - fromGroup: shared
+  - type: worker
+    name: jobs
+    envVars:
+      - fromGroup: shared
+envVarGroups:
+  - name: shared
+    envVars:
+      - key: SESSION_SECRET
+        generateValue: true
+      - key: LOG_LEVEL
+        value: info
+`
+
+	results := make(map[string]types.EnvResult)
+	for result := range extractor.Extract(ctx, "render.yaml", []byte(renderContent)) {
+		if _, ok := results[result.VarName]; ok {
+			t.Errorf("Expected group var %s to be reported once", result.VarName)
+		}
+		results[result.VarName] = result
+	}
+
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 env vars, got %d", len(results))
+	}
+	if results["PORT"].Value != "8080" {
+		t.Errorf("Expected PORT=8080, got %q", results["PORT"].Value)
+	}
+	if results["DATABASE_URL"].Type != types.EnvTypeDatabase {
+		t.Errorf("Expected DATABASE_URL to be a database var, got %v", results["DATABASE_URL"].Type)
+	}
+	if ref := results["DATABASE_URL"].Reference; ref == nil || *ref != (types.ServiceReference{Service: "db", Variable: "DATABASE_URL"}) {
+		t.Errorf("Expected DATABASE_URL to reference db's DATABASE_URL, got %+v", ref)
+	}
+	if ref := results["SEARCH_HOSTPORT"].Reference; ref == nil || ref.Variable != "RAILWAY_PRIVATE_DOMAIN" || ref.Suffix != ":${{search.PORT}}" {
+		t.Errorf("Expected SEARCH_HOSTPORT to reference search's private domain and port, got %+v", ref)
+	}
+	if session := results["SESSION_SECRET"]; session.Type != types.EnvTypeGenerated || session.Source != "render:render.yaml#shared" {
+		t.Errorf("Expected generated SESSION_SECRET from the shared group, got %+v", session)
+	}
+}
+
+func TestExtractor_MinifiedBundle(t *testing.T) {
+	// One long line, with a variable straddling the first chunk boundary
+	padding := strings.Repeat("a", 64<<10-35)
+	bundle := "var a=process.env.FIRST_URL;" + padding + "process.env.BOUNDARY_KEY;" + padding + padding + "process.env.LAST_TOKEN;\nx=process.env.NEXT_LINE"
+
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("dist/app.js", []byte(bundle))
+	extractor := environment.NewExtractor(fs)
+
+	found := make(map[string]types.Span)
+	for result := range extractor.ExtractFile(context.Background(), "dist/app.js") {
+		found[result.VarName] = result.Span
+	}
+	expected := map[string]types.Span{
+		"FIRST_URL":    {Line: 1, Column: 19, EndColumn: 28},
+		"BOUNDARY_KEY": {Line: 1, Column: strings.Index(bundle, "BOUNDARY_KEY") + 1, EndColumn: strings.Index(bundle, "BOUNDARY_KEY") + 13},
+		"LAST_TOKEN":   {Line: 1, Column: strings.Index(bundle, "LAST_TOKEN") + 1, EndColumn: strings.Index(bundle, "LAST_TOKEN") + 11},
+		"NEXT_LINE":    {Line: 2, Column: 15, EndColumn: 24},
+	}
+	for name, span := range expected {
+		if got, ok := found[name]; !ok {
+			t.Errorf("expected %s to be found, got %v", name, found)
+		} else if got != span {
+			t.Errorf("expected %s at %+v, got %+v", name, span, got)
+		}
+	}
+
+	// Files no extractor handles aren't read at all
+	for result := range extractor.ExtractFile(context.Background(), "missing.png") {
+		t.Errorf("expected no results, got %+v", result)
+	}
+}
+
+func TestExtractor_RailsConfig(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("config/database.yml", []byte(`default: &default
+  adapter: postgresql
+  pool: <%= ENV.fetch("RAILS_MAX_THREADS") { 5 } %>
+  host: <%= ENV.fetch("DB_HOST", "localhost") %>
+
+production:
+  <<: *default
+  url: <%= ENV['DATABASE_URL'] %>
+`))
+	fs.AddFile("config/credentials.yml.enc", []byte("bm90IHJlYWxseSBlbmNyeXB0ZWQ=--c2FsdA==--dGFn\n"))
+
+	results := map[string]types.EnvResult{}
+	for _, path := range []string{"config/database.yml", "config/credentials.yml.enc"} {
+		for result := range environment.NewExtractor(fs).ExtractFile(context.Background(), path) {
+			results[result.VarName] = result
+		}
+	}
+
+	for name, value := range map[string]string{"RAILS_MAX_THREADS": "5", "DB_HOST": "localhost", "DATABASE_URL": ""} {
+		result, ok := results[name]
+		if !ok {
+			t.Errorf("Expected %s from database.yml", name)
+		} else if result.Value != value {
+			t.Errorf("Expected %s to default to %q, got %q", name, value, result.Value)
+		}
+	}
+	if master, ok := results["RAILS_MASTER_KEY"]; !ok || !master.Sensitive {
+		t.Errorf("Expected encrypted credentials to require a sensitive RAILS_MASTER_KEY, got %+v", master)
+	}
+}
+
+func TestExtractor_LaravelConfig(t *testing.T) {
+	content := []byte(`<?php
+
+return [
+    'name' => env('APP_NAME', 'Laravel'),
+    'debug' => (bool) env('APP_DEBUG', false),
+    'key' => env("APP_KEY"),
+    'timezone' => getenv('TZ_OVERRIDE'),
+    'url' => env('APP_URL', null),
+];
+`)
+
+	results := map[string]types.EnvResult{}
+	for result := range environment.NewExtractor(filesystems.NewMemoryFS()).Extract(context.Background(), "config/app.php", content) {
+		if strings.HasPrefix(result.Source, "laravel:") {
+			results[result.VarName] = result
+		}
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 env() calls, got %v", results)
+	}
+	if results["APP_NAME"].Value != "Laravel" || results["APP_DEBUG"].Value != "false" || results["APP_URL"].Value != "" {
+		t.Errorf("Expected env() defaults as values, got %+v", results)
+	}
+	if !results["APP_KEY"].Sensitive {
+		t.Error("Expected APP_KEY to be sensitive")
+	}
+}
+
+func TestExtractor_SpringConfig(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("src/main/resources/application.yml", []byte(`spring:
+  datasource:
+    url: ${DATABASE_URL}
+    hikari:
+      maximum-pool-size: ${DB_POOL_SIZE:${POOL_SIZE:10}}
+---
+spring:
+  config:
+    activate:
+      on-profile: local
+  datasource:
+    password: ${LOCAL_DB_PASSWORD}
+`))
+	fs.AddFile("src/main/resources/application-prod.properties", []byte("server.port=${PORT:8080}\nstripe.key=${STRIPE_SECRET_KEY}\n"))
+	fs.AddFile("src/main/resources/application-dev.properties", []byte("debug.token=${DEV_TOKEN}\n"))
+
+	results := map[string]types.EnvResult{}
+	for _, path := range []string{
+		"src/main/resources/application.yml",
+		"src/main/resources/application-prod.properties",
+		"src/main/resources/application-dev.properties",
+	} {
+		for result := range environment.NewExtractor(fs).ExtractFile(context.Background(), path) {
+			if strings.HasPrefix(result.Source, "spring:") {
+				results[result.VarName] = result
+			}
+		}
+	}
+
+	for name, value := range map[string]string{"DATABASE_URL": "", "DB_POOL_SIZE": "", "POOL_SIZE": "10", "PORT": "8080", "STRIPE_SECRET_KEY": ""} {
+		result, ok := results[name]
+		if !ok {
+			t.Errorf("Expected %s from the application config", name)
+		} else if result.Value != value {
+			t.Errorf("Expected %s to default to %q, got %q", name, value, result.Value)
+		}
+	}
+	for _, name := range []string{"LOCAL_DB_PASSWORD", "DEV_TOKEN"} {
+		if _, ok := results[name]; ok {
+			t.Errorf("Expected %s, only needed by a local profile, to be skipped", name)
+		}
+	}
+}
+
+func TestExtractor_PublicPrefixes(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("web/.env", []byte("NEXT_PUBLIC_API_TOKEN=abc123\nAPI_TOKEN=def456\n"))
+	fs.AddFile("web/src/main.ts", []byte("fetch(import.meta.env.VITE_API_URL)\n"))
+
+	results := map[string]types.EnvResult{}
+	for _, path := range []string{"web/.env", "web/src/main.ts"} {
+		for result := range environment.NewExtractor(fs).ExtractFile(context.Background(), path) {
+			results[result.VarName] = result
+		}
+	}
+
+	if public := results["NEXT_PUBLIC_API_TOKEN"]; !public.BuildTime || public.Sensitive {
+		t.Errorf("Expected the public token to be build-time and not sensitive, got %+v", public)
+	}
+	if private := results["API_TOKEN"]; private.BuildTime || !private.Sensitive {
+		t.Errorf("Expected the private token to be sensitive, got %+v", private)
+	}
+	if vite, ok := results["VITE_API_URL"]; !ok || !vite.BuildTime {
+		t.Errorf("Expected import.meta.env usages to be build-time, got %+v", vite)
+	}
+}
+
+func TestExtractor_DockerfileArgs(t *testing.T) {
+	dockerfile := `ARG NODE_VERSION=20
+FROM node:${NODE_VERSION}
+ARG TARGETARCH
+ARG NPM_TOKEN
+ARG APP_ENV=production
+ENV APP_ENV=$APP_ENV PORT=3000
+`
+	var results []types.EnvResult
+	for result := range environment.NewExtractor(filesystems.NewMemoryFS()).Extract(context.Background(), "Dockerfile", []byte(dockerfile)) {
+		results = append(results, result)
+	}
+	merged := map[string]types.EnvResult{}
+	for _, result := range environment.Merge(results) {
+		merged[result.VarName] = result
+	}
+
+	if version := merged["NODE_VERSION"]; version.Value != "20" || !version.BuildOnly || version.Source != "dockerfile-arg:Dockerfile" {
+		t.Errorf("Expected NODE_VERSION to be a build arg defaulting to 20, got %+v", version)
+	}
+	if token := merged["NPM_TOKEN"]; !token.BuildOnly || !token.Sensitive || token.Value != "" {
+		t.Errorf("Expected NPM_TOKEN to be a sensitive build arg without a default, got %+v", token)
+	}
+	if _, ok := merged["TARGETARCH"]; ok {
+		t.Error("Expected BuildKit's automatic build args to be skipped")
+	}
+	if appEnv := merged["APP_ENV"]; appEnv.BuildOnly || !appEnv.BuildTime {
+		t.Errorf("Expected APP_ENV, carried into the image with ENV, to be needed at runtime too, got %+v", appEnv)
+	}
+	if port := merged["PORT"]; port.BuildOnly || port.BuildTime {
+		t.Errorf("Expected PORT to be a runtime variable, got %+v", port)
+	}
+}
+
+func TestExtractor_StartCommands(t *testing.T) {
+	ctx := context.Background()
+	extractor := environment.NewExtractor(filesystems.NewMemoryFS())
+
+	results := map[string]types.EnvResult{}
+	procfile := "web: NODE_ENV=production node server.js --port ${PORT:-3000}\nworker: env QUEUE=default node worker.js \"$REDIS_URL\" '$LITERAL'\n"
+	for result := range extractor.Extract(ctx, "Procfile", []byte(procfile)) {
+		results[result.Source+" "+result.VarName] = result
+	}
+	packageJSON := `{
+  "scripts": {
+    "build": "NEXT_TELEMETRY_DISABLED=1 next build",
+    "test": "NODE_ENV=test jest"
+  }
+}`
+	for result := range extractor.Extract(ctx, "package.json", []byte(packageJSON)) {
+		results[result.Source+" "+result.VarName] = result
+	}
+
+	if env := results["procfile:Procfile#web NODE_ENV"]; env.Value != "production" || env.Span.Line != 1 || env.Span.Column != 6 {
+		t.Errorf("Expected NODE_ENV set inline for the web process, got %+v", env)
+	}
+	if port := results["usage:Procfile#web PORT"]; port.Value != "3000" {
+		t.Errorf("Expected PORT used by the web process with its default, got %+v", port)
+	}
+	if queue := results["procfile:Procfile#worker QUEUE"]; queue.Value != "default" {
+		t.Errorf("Expected QUEUE set with env for the worker, got %+v", queue)
+	}
+	if _, ok := results["usage:Procfile#worker REDIS_URL"]; !ok {
+		t.Error("Expected REDIS_URL used by the worker in double quotes")
+	}
+	if _, ok := results["usage:Procfile#worker LITERAL"]; ok {
+		t.Error("Expected single-quoted references not to be expanded")
+	}
+	if telemetry := results["package-json:package.json#build NEXT_TELEMETRY_DISABLED"]; !telemetry.BuildTime || telemetry.Span.Line != 3 {
+		t.Errorf("Expected the build script's variable to be build-time, got %+v", telemetry)
+	}
+	if test := results["package-json:package.json#test NODE_ENV"]; test.Confidence >= results["procfile:Procfile#web NODE_ENV"].Confidence {
+		t.Errorf("Expected the test script to be trusted less than the Procfile, got %+v", test)
+	}
+}
+
+func TestExtractor_PlatformConfigs(t *testing.T) {
+	files := map[string]string{
+		"fly.toml": `app = "api"
+[env]
+  LOG_LEVEL = "info"
+[build.args]
+  NODE_VERSION = "20"
+`,
+		".do/app.yaml": `name: shop
+envs:
+  - key: APP_ENV
+    value: production
+services:
+  - name: api
+    envs:
+      - key: STRIPE_KEY
+        value: EV[1:abc:def]
+        type: SECRET
+        scope: RUN_TIME
+`,
+		"app.json":    `{"env": {"WEB_CONCURRENCY": "2", "SECRET_KEY_BASE": {"generator": "secret"}, "ADMIN_EMAIL": {"required": true}}}`,
+		"vercel.json": `{"env": {"API_TOKEN": "@api-token"}, "build": {"env": {"ANALYZE": "false"}}}`,
+		"netlify.toml": `[build.environment]
+  NODE_VERSION = "20"
+[context.production.environment]
+  API_URL = "https://api.example.com"
+`,
+	}
+
+	fs := filesystems.NewMemoryFS()
+	for path, content := range files {
+		fs.AddFile(path, []byte(content))
+	}
+	results := map[string]types.EnvResult{}
+	for path := range files {
+		for result := range environment.NewExtractor(fs).ExtractFile(context.Background(), path) {
+			results[result.Source+" "+result.VarName] = result
+		}
+	}
+
+	expected := map[string]struct {
+		value                string
+		sensitive, buildTime bool
+	}{
+		"fly:fly.toml LOG_LEVEL":                   {"info", false, false},
+		"fly:fly.toml NODE_VERSION":                {"20", false, true},
+		"digitalocean:.do/app.yaml APP_ENV":        {"production", false, true},
+		"digitalocean:.do/app.yaml#api STRIPE_KEY": {"", true, false},
+		"heroku:app.json WEB_CONCURRENCY":          {"2", false, false},
+		"heroku:app.json SECRET_KEY_BASE":          {"", true, false},
+		"heroku:app.json ADMIN_EMAIL":              {"", false, false},
+		"vercel:vercel.json API_TOKEN":             {"", true, false},
+		"vercel:vercel.json ANALYZE":               {"false", false, true},
+		"netlify:netlify.toml NODE_VERSION":        {"20", false, true},
+		"netlify:netlify.toml#production API_URL":  {"https://api.example.com", false, true},
+	}
+	for key, want := range expected {
+		got, ok := results[key]
+		if !ok {
+			t.Errorf("Expected %s, got %v", key, results)
+			continue
+		}
+		if got.Value != want.value || got.Sensitive != want.sensitive || got.BuildTime != want.buildTime {
+			t.Errorf("%s: expected value %q, sensitive %t and build-time %t, got %+v", key, want.value, want.sensitive, want.buildTime, got)
+		}
+	}
+	if len(results) != len(expected) {
+		t.Errorf("Expected %d variables, got %d", len(expected), len(results))
+	}
+}
+
+func TestExtractor_ShellExports(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile(".envrc", []byte(`source_up
+use nix
+export DATABASE_URL="postgres://localhost/dev"
+export LOG_LEVEL=debug # noisy
+export GREETING='hello $USER'
+export PATH="$PWD/bin:$PATH"
+export BIN_DIR="$(pwd)/bin"
+`))
+	fs.AddFile("scripts/deploy.sh", []byte("#!/bin/sh\nexport REGION=us-east-1\n"))
+	fs.AddFile("deploy.sh", []byte("export IGNORED=1\n"))
+
+	results := map[string]types.EnvResult{}
+	for _, path := range []string{".envrc", "scripts/deploy.sh", "deploy.sh"} {
+		for result := range environment.NewExtractor(fs).ExtractFile(context.Background(), path) {
+			if strings.HasPrefix(result.Source, "shell:") {
+				results[result.VarName] = result
+			}
+		}
+	}
+
+	expected := map[string]string{
+		"DATABASE_URL": "postgres://localhost/dev",
+		"LOG_LEVEL":    "debug",
+		"GREETING":     "hello $USER",
+		"BIN_DIR":      "",
+		"REGION":       "us-east-1",
+	}
+	if len(results) != len(expected) {
+		t.Errorf("Expected %d exports, got %v", len(expected), results)
+	}
+	for name, value := range expected {
+		if result, ok := results[name]; !ok || result.Value != value {
+			t.Errorf("Expected %s=%q, got %+v", name, value, result)
+		}
+	}
+}
+
+func TestLibraryCallExtractor_SourceUsages(t *testing.T) {
+	sources := map[string]string{
+		"src/server.ts": `import { env as processEnv } from "node:process"
+const { PORT = 3000, HOST: host, "API_URL": apiUrl, ...rest } = process.env
+const {
+  REDIS_URL,
+  QUEUE_NAME = "jobs",
+} = process.env
+const e = process.env
+const secret = e["JWT_SECRET"] ?? processEnv.SESSION_KEY
+// process.env.COMMENTED_OUT
+/* const { BLOCK_COMMENTED } = process.env */
+const message = "set process.env.IN_STRING first"
+const url = ` + "`${process.env.BASE_URL}/process.env.IN_TEMPLATE`" + `
+`,
+		"app/settings.py": `import os as system
+from os import environ as env, getenv
+DEBUG = system.environ.get("DEBUG", "false")
+WORKERS = getenv("WORKERS", 4)
+DATABASE = env["DATABASE_URL"]
+# os.getenv("COMMENTED_OUT")
+HELP = """
+os.getenv("IN_DOCSTRING")
+"""
+`,
+		"cmd/main.go": `package main
+
+import (
+	"fmt"
+	goos "os"
+)
+
+func main() {
+	// os.Getenv("COMMENTED_OUT")
+	fmt.Println("os.Getenv(\"IN_STRING\")", goos.Getenv("LISTEN_ADDR"))
+	_, _ = goos.LookupEnv("FEATURE_FLAGS")
+}
+`,
+		"config/app.rb": `=begin
+ENV["COMMENTED_OUT"]
+=end
+timeout = ENV.fetch("TIMEOUT", "30")
+key = ENV['STRIPE_KEY'] # ENV["ALSO_COMMENTED"]
+`,
+	}
+	expected := map[string]string{
+		"PORT": "3000", "HOST": "", "API_URL": "", "REDIS_URL": "", "QUEUE_NAME": "jobs",
+		"JWT_SECRET": "", "SESSION_KEY": "", "BASE_URL": "",
+		"DEBUG": "false", "WORKERS": "4", "DATABASE_URL": "",
+		"LISTEN_ADDR": "", "FEATURE_FLAGS": "",
+		"TIMEOUT": "30", "STRIPE_KEY": "",
+	}
+
+	extractor := extractors.NewLibraryCallExtractor()
+	results := map[string]types.EnvResult{}
+	for filename, source := range sources {
+		fileResults, err := extractor.Extract(context.Background(), filename, []byte(source))
+		if err != nil {
+			t.Fatalf("Extract %s failed: %v", filename, err)
+		}
+		for _, result := range fileResults {
+			results[result.VarName] = result
+		}
+	}
+
+	if len(results) != len(expected) {
+		t.Errorf("Expected %d usages, got %v", len(expected), results)
+	}
+	for name, value := range expected {
+		if result, ok := results[name]; !ok || result.Value != value {
+			t.Errorf("Expected %s=%q, got %+v", name, value, result)
+		}
+	}
+}
+
+func TestCrossReference(t *testing.T) {
+	services := []discoveryTypes.Service{
+		{Name: "api", Variables: map[string]string{"LOG_LEVEL": "info"}},
+		{Name: "worker"},
+	}
+	results := map[string][]types.EnvResult{
+		"api": {
+			{VarName: "DATABASE_URL", Source: "usage:api/db.ts"},
+			{VarName: "DATABASE_URL", Source: "dotenv:api/.env"},
+			{VarName: "STRIPE_KEY", Source: "usage:api/billing.ts"},
+			{VarName: "STRIPE_KEY", Source: "usage:api/webhooks.ts"},
+			{VarName: "LOG_LEVEL", Source: "usage:api/log.ts"},
+			{VarName: "PORT", Source: "usage:api/server.ts"},
+		},
+		"worker": {
+			{VarName: "DATABASE_URL", Source: "usage:worker/main.py"},
+			{VarName: "QUEUE_URL", Source: "usage:worker/main.py"},
+		},
+	}
+
+	references := environment.CrossReference(services, results)
+	if got := environment.Undeclared(references["api"]); !slices.Equal(got, []string{"STRIPE_KEY"}) {
+		t.Errorf("Expected api to leave STRIPE_KEY undeclared, got %v", got)
+	}
+	if got := environment.Undeclared(references["worker"]); !slices.Equal(got, []string{"QUEUE_URL"}) {
+		t.Errorf("Expected worker to leave QUEUE_URL undeclared, got %v", got)
+	}
+
+	for _, ref := range references["api"] {
+		switch ref.VarName {
+		case "STRIPE_KEY":
+			if !slices.Equal(ref.UsedIn, []string{"api/billing.ts", "api/webhooks.ts"}) {
+				t.Errorf("Expected STRIPE_KEY used in both files, got %v", ref.UsedIn)
+			}
+		case "LOG_LEVEL":
+			if !slices.Equal(ref.DeclaredIn, []string{"service:api"}) {
+				t.Errorf("Expected LOG_LEVEL declared by the service, got %v", ref.DeclaredIn)
+			}
+		case "PORT":
+			t.Errorf("Expected PORT, which Railway provides, to be left out")
+		}
+	}
+	// Declared for another service counts
+	for _, ref := range references["worker"] {
+		if ref.VarName == "DATABASE_URL" && !slices.Equal(ref.DeclaredIn, []string{"dotenv:api/.env"}) {
+			t.Errorf("Expected DATABASE_URL declared by api's .env, got %v", ref.DeclaredIn)
+		}
+	}
+}
+
+func TestExtractor_Spans(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("app/.env", []byte(`# API_KEY is issued by the dashboard
+LOG_LEVEL=debug
+export API_KEY=abc123
+`))
+	fs.AddFile("app/docker-compose.yml", []byte(`services:
+  app:
+    image: app
+    environment:
+      - REDIS_URL=redis://cache:6379
+`))
+
+	spans := make(map[string]types.Span)
+	for _, path := range []string{"app/.env", "app/docker-compose.yml"} {
+		for result := range environment.NewExtractor(fs).ExtractFile(context.Background(), path) {
+			spans[result.VarName] = result.Span
+		}
+	}
+
+	expected := map[string]types.Span{
+		"LOG_LEVEL": {Line: 2, Column: 1, EndColumn: 10},
+		"API_KEY":   {Line: 3, Column: 8, EndColumn: 15}, // the assignment, not the comment
+		"REDIS_URL": {Line: 5, Column: 9, EndColumn: 18},
+	}
+	for name, span := range expected {
+		if spans[name] != span {
+			t.Errorf("expected %s at %+v, got %+v", name, span, spans[name])
+		}
+	}
+}
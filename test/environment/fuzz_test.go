@@ -0,0 +1,49 @@
+package environment_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/environment"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// Config files whose extractors parse TOML, YAML or JSON, or Procfile lines
+var fuzzedConfigs = []string{
+	"fly.toml",
+	"render.yaml",
+	".do/app.yaml",
+	"netlify.toml",
+	"railway.json",
+	"railway.toml",
+	"app.json",
+	"Procfile",
+	"package.json",
+	"docker-compose.yml",
+}
+
+// FuzzExtractor feeds every config extractor arbitrary contents, picking the file by
+// index. Malformed configs may yield nothing, but must never panic.
+func FuzzExtractor(f *testing.F) {
+	for i, seed := range []string{
+		"app = \"shop\"\n[env]\nPORT = \"8080\"\n[processes]\nweb = \"NODE_ENV=production node server.js\"\n",
+		"services:\n  - type: web\n    name: web\n    envVars:\n      - key: SECRET\n        generateValue: true\nenvVarGroups:\n  - name: shared\n    envVars:\n      - key: REGION\n        value: us\n",
+		"services:\n  - name: web\n    envs:\n      - key: API_KEY\n        scope: RUN_AND_BUILD_TIME\n        type: SECRET\n",
+		"[build.environment]\nNODE_VERSION = \"20\"\n[context.production.environment]\nAPI_URL = \"https://api.example.com\"\n",
+		`{"deploy": {"startCommand": "PORT=3000 npm start"}}`,
+		"[deploy]\nstartCommand = \"npm start\"\n",
+		`{"env": {"SECRET_KEY": {"generator": "secret"}, "WEB_CONCURRENCY": {"value": "2"}}}`,
+		"web: env PORT=$PORT gunicorn app:app -b \"0.0.0.0:${PORT:-8000}\"\n",
+		`{"scripts": {"start": "cross-env NODE_ENV=production node server.js", "build": "VITE_API=$API_URL vite build"}}`,
+		"services:\n  web:\n    image: nginx\n    env_file: .env\n    environment:\n      URL: ${HOST:-localhost}:${PORT}\n",
+	} {
+		f.Add(uint8(i), []byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, config uint8, content []byte) {
+		extractor := environment.NewExtractor(filesystems.NewMemoryFS())
+		filename := fuzzedConfigs[int(config)%len(fuzzedConfigs)]
+		for range extractor.Extract(context.Background(), filename, content) {
+		}
+	})
+}
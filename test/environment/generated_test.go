@@ -0,0 +1,49 @@
+package environment_test
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+func TestDetectGenerated_Corpus(t *testing.T) {
+	file, err := os.Open("testdata/generated_values.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		label, value, _ := strings.Cut(line, " ")
+		if label == "-" {
+			label = ""
+		}
+		if got := types.DetectGenerated(value); got != label {
+			t.Errorf("DetectGenerated(%q) = %q, expected %q", value, got, label)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetectGenerated_Thresholds(t *testing.T) {
+	defer func(previous types.EntropyThresholds) { types.Entropy = previous }(types.Entropy)
+
+	value := "q7Xk2Lp9Zr4Wm8Tv" // 16 random characters
+	if !types.LooksGenerated(value) {
+		t.Fatalf("expected %s to look generated", value)
+	}
+	types.Entropy.MinLength = 24
+	if types.LooksGenerated(value) {
+		t.Errorf("expected %s to be too short with a higher minimum length", value)
+	}
+}
@@ -0,0 +1,35 @@
+package environment_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+func TestRailwayVariables(t *testing.T) {
+	for name, provided := range map[string]bool{
+		"PORT":                  true,
+		"RAILWAY_PUBLIC_DOMAIN": true,
+		"RAILWAY_ENVIRONMENT":   true,
+		"RENDER_EXTERNAL_URL":   false,
+		"DATABASE_URL":          false,
+	} {
+		if got := types.IsRailwayProvided(name); got != provided {
+			t.Errorf("IsRailwayProvided(%s) = %v, expected %v", name, got, provided)
+		}
+	}
+
+	expected := map[string]string{
+		"RENDER_EXTERNAL_URL": "https://${{RAILWAY_PUBLIC_DOMAIN}}",
+		"FLY_APP_NAME":        "${{RAILWAY_SERVICE_NAME}}",
+		"VERCEL_URL":          "${{RAILWAY_PUBLIC_DOMAIN}}",
+	}
+	for name, value := range expected {
+		if translation, ok := types.TranslateToRailway(name); !ok || translation.Value != value {
+			t.Errorf("Expected %s to translate to %s, got %+v", name, value, translation)
+		}
+	}
+	if translation, ok := types.TranslateToRailway("API_URL"); ok {
+		t.Errorf("Expected no translation for API_URL, got %+v", translation)
+	}
+}
@@ -0,0 +1,40 @@
+package environment_test
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/environment/types"
+)
+
+func TestSensitivityPolicy(t *testing.T) {
+	policy, err := types.NewSensitivityPolicy([]string{`^stripe_`, `_PIN$`}, []string{"PUBLIC_KEY_ID", "STRIPE_MODE"})
+	if err != nil {
+		t.Fatalf("NewSensitivityPolicy failed: %v", err)
+	}
+	defer func(previous types.SensitivityPolicy) { types.Sensitivity = previous }(types.Sensitivity)
+	types.Sensitivity = policy
+
+	for name, sensitive := range map[string]bool{
+		"STRIPE_ACCOUNT": true,  // matches a pattern, case-insensitively
+		"ATM_PIN":        true,  // matches a pattern
+		"STRIPE_MODE":    false, // safe names win over patterns
+		"PUBLIC_KEY_ID":  false, // safe names win over the built-in patterns
+		"API_SECRET":     true,  // the built-in patterns still apply
+		"LOG_LEVEL":      false,
+	} {
+		if _, got := types.ClassifyEnvVar(name, "value"); got != sensitive {
+			t.Errorf("ClassifyEnvVar(%s) sensitive = %v, expected %v", name, got, sensitive)
+		}
+	}
+
+	if _, err := types.NewSensitivityPolicy([]string{"("}, nil); err == nil {
+		t.Error("expected an invalid pattern to be rejected")
+	}
+
+	if got := types.Redact("hunter2", true); got != types.RedactedValue {
+		t.Errorf("expected a sensitive value to be masked, got %q", got)
+	}
+	if got := types.Redact("", true); got != "" {
+		t.Errorf("expected an empty value to stay empty, got %q", got)
+	}
+}
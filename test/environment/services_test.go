@@ -0,0 +1,147 @@
+package environment_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	discoveryTypes "github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/environment"
+	"github.com/railwayapp/turnout/internal/environment/types"
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestExtractServices_SharedEnvFiles(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile(".env", []byte("SHARED=root\nOVERRIDE=root\n"))
+	fs.AddFile("index.js", []byte("const key = process.env.ROOT_ONLY\n"))
+	fs.AddFile("services/.env", []byte("MIDDLE=services\nOVERRIDE=services\n"))
+	fs.AddFile("services/api/.env", []byte("OVERRIDE=api\n"))
+	fs.AddFile("services/api/.env.example", []byte("SHARED=example\n"))
+	fs.AddFile("web/package.json", []byte(`{"name": "web"}`))
+
+	services := []discoveryTypes.Service{
+		{Name: "api", BuildPath: "services/api"},
+		{Name: "web", BuildPath: "web"},
+	}
+	extractor := environment.NewExtractor(fs)
+	extractor.SetRoot(".")
+	envVars := extractor.ExtractServices(context.Background(), services)
+
+	expected := map[string]map[string]string{
+		"api": {"SHARED": "root", "MIDDLE": "services", "OVERRIDE": "api"},
+		"web": {"SHARED": "root", "OVERRIDE": "root"},
+	}
+	for service, want := range expected {
+		got := make(map[string]types.EnvResult)
+		for _, envVar := range envVars[service] {
+			got[envVar.VarName] = envVar
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s: expected %d variables, got %v", service, len(want), got)
+		}
+		for name, value := range want {
+			if got[name].Value != value {
+				t.Errorf("%s: expected %s=%q, got %q from %s", service, name, value, got[name].Value, got[name].Source)
+			}
+		}
+	}
+
+	for _, envVar := range envVars["api"] {
+		if envVar.VarName == "OVERRIDE" && len(envVar.Sources) != 3 {
+			t.Errorf("expected OVERRIDE to be found in 3 files, got %v", envVar.Sources)
+		}
+	}
+}
+
+func TestExtractServices_SharedEnvFilesWithoutRoot(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile(".env", []byte("SHARED=root\n"))
+	fs.AddFile("services/.env", []byte("MIDDLE=services\n"))
+	fs.AddFile("services/api/.env", []byte("OWN=api\n"))
+
+	// A single service is the deepest directory the services share, and the root's
+	// env files still apply to it
+	envVars := environment.NewExtractor(fs).ExtractServices(context.Background(), []discoveryTypes.Service{
+		{Name: "api", BuildPath: "services/api"},
+	})
+
+	got := make(map[string]string)
+	for _, envVar := range envVars["api"] {
+		got[envVar.VarName] = envVar.Value
+	}
+	for name, value := range map[string]string{"SHARED": "root", "MIDDLE": "services", "OWN": "api"} {
+		if got[name] != value {
+			t.Errorf("expected %s=%q, got %v", name, value, got)
+		}
+	}
+}
+
+func TestExtractServiceResults_Boundaries(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("app/.env.example", []byte("APP_KEY=\n"))
+	fs.AddFile("app/index.js", []byte("const url = process.env.APP_URL\n"))
+	fs.AddFile("app/worker/.env.example", []byte("QUEUE=\n"))
+	fs.AddFile("lib/.env.example", []byte("LIB_ONLY=\n"))
+
+	tests := []struct {
+		name     string
+		services []discoveryTypes.Service
+		expected map[string][]string // service -> variables, in walk order
+	}{
+		{
+			name:     "one service",
+			services: []discoveryTypes.Service{{Name: "app", BuildPath: "app"}},
+			expected: map[string][]string{"app": {"APP_KEY", "APP_URL", "QUEUE"}},
+		},
+		{
+			name:     "services sharing a build path",
+			services: []discoveryTypes.Service{{Name: "web", BuildPath: "app"}, {Name: "cron", BuildPath: "app"}},
+			expected: map[string][]string{"web": {"APP_KEY", "APP_URL", "QUEUE"}, "cron": {"APP_KEY", "APP_URL", "QUEUE"}},
+		},
+		{
+			name:     "nested service",
+			services: []discoveryTypes.Service{{Name: "app", BuildPath: "app"}, {Name: "worker", BuildPath: "app/worker"}},
+			// app's code stays out of worker, but app's env file is shared with it
+			expected: map[string][]string{"app": {"APP_KEY", "APP_URL"}, "worker": {"APP_KEY", "QUEUE"}},
+		},
+		{
+			name:     "service without a build path",
+			services: []discoveryTypes.Service{{Name: "db"}},
+			expected: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor := environment.NewExtractor(fs)
+			extractor.SetRoot("app") // Keeps lib's and the root's env files out of it
+			results := extractor.ExtractServiceResults(context.Background(), tt.services)
+
+			if len(results) != len(tt.expected) {
+				t.Errorf("Expected results for %d services, got %v", len(tt.expected), results)
+			}
+			for service, want := range tt.expected {
+				var got []string
+				for _, envVar := range results[service] {
+					got = append(got, envVar.VarName)
+				}
+				if !slices.Equal(got, want) {
+					t.Errorf("%s: expected %v, got %v", service, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractServiceResults_Canceled(t *testing.T) {
+	fs := filesystems.NewMemoryFS()
+	fs.AddFile("app/.env", []byte("APP_KEY=abc\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := environment.NewExtractor(fs).ExtractServiceResults(ctx, []discoveryTypes.Service{{Name: "app", BuildPath: "app"}})
+	if len(results["app"]) != 0 {
+		t.Errorf("Expected nothing extracted once canceled, got %v", results["app"])
+	}
+}
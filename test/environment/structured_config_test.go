@@ -5,6 +5,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/railwayapp/turnout/internal/environment"
 	"github.com/railwayapp/turnout/internal/environment/extractors"
 	"github.com/railwayapp/turnout/internal/environment/types"
 )
@@ -198,12 +199,8 @@ func TestDeduplication(t *testing.T) {
 	// Collect all results and deduplicate based on confidence
 	allResults := append(envResults, composeResults...)
 	deduplicated := make(map[string]types.EnvResult)
-
-	for _, result := range allResults {
-		existing, exists := deduplicated[result.VarName]
-		if !exists || result.Confidence > existing.Confidence {
-			deduplicated[result.VarName] = result
-		}
+	for _, result := range environment.Merge(allResults) {
+		deduplicated[result.VarName] = result
 	}
 
 	// Verify deduplication behavior
@@ -222,6 +219,9 @@ func TestDeduplication(t *testing.T) {
 	if portResult.Confidence != 85 {
 		t.Errorf("Expected confidence 85 for PORT, got %d", portResult.Confidence)
 	}
+	if len(portResult.Sources) != 2 || !portResult.Sources[0].Winner || portResult.Sources[1].Winner || portResult.Sources[1].Source != "docker-compose:docker-compose.yml" {
+		t.Errorf("Expected PORT's sources to list .env as the winner over compose, got %+v", portResult.Sources)
+	}
 
 	// API_KEY should come from .env (confidence 85) over docker-compose (confidence 80)
 	apiResult, exists := deduplicated["API_KEY"]
@@ -247,3 +247,47 @@ func TestDeduplication(t *testing.T) {
 		t.Errorf("Expected confidence 80 for DATABASE_URL, got %d", dbResult.Confidence)
 	}
 }
+
+func TestStructuredConfig_GoConfigLibraries(t *testing.T) {
+	content := []byte("package config\n\n" +
+		"import (\n\t\"github.com/knadh/koanf/providers/env\"\n\t\"github.com/spf13/viper\"\n)\n\n" +
+		"type Spec struct {\n" +
+		"\tPort    int    `envconfig:\"port\" default:\"8080\"`\n" +
+		"\tAPIKey  string `envconfig:\"API_KEY\" required:\"true\"`\n" +
+		"\tRegion  string `env:\"REGION,required\" envDefault:\"us-east-1\"`\n" +
+		"}\n\n" +
+		"func load() {\n" +
+		"\tviper.SetEnvPrefix(\"app\")\n" +
+		"\tviper.AutomaticEnv()\n" +
+		"\tviper.BindEnv(\"database.url\", \"DATABASE_URL\")\n" +
+		"\tviper.BindEnv(\"redis.host\")\n" +
+		"\tviper.SetDefault(\"redis.host\", \"localhost\")\n" +
+		"\tviper.SetDefault(\"workers\", 4)\n" +
+		"\tk.Load(env.Provider(\"SHOP_\", \".\", transform), nil)\n" +
+		"\t_ = k.String(\"smtp.host\")\n" +
+		"}\n")
+
+	results, err := extractors.NewStructuredConfigExtractor().Extract(context.Background(), "config/config.go", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, result := range results {
+		got[result.VarName] = result.Value
+	}
+
+	expected := map[string]string{
+		"PORT":           "8080",
+		"API_KEY":        "",
+		"REGION":         "us-east-1",
+		"DATABASE_URL":   "",
+		"APP_REDIS_HOST": "localhost",
+		"APP_WORKERS":    "4",
+		"SHOP_SMTP_HOST": "",
+	}
+	for name, value := range expected {
+		if actual, ok := got[name]; !ok || actual != value {
+			t.Errorf("Expected %s=%q, got %v", name, value, got)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package export_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/export"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+func TestDotenvExporter_ExportFiles(t *testing.T) {
+	project := schema.NewProject("shop")
+	api := schema.NewService("api")
+	api.Environment["LOG_LEVEL"] = schema.NewEnvVar("info", false)
+	api.Environment["GREETING"] = schema.NewEnvVar(`say "hi" # loudly`, false)
+	api.Environment["PRICE"] = schema.NewEnvVar("$5", false)
+	api.Environment["API_SECRET"] = schema.NewEnvVar("hunter2", true)
+	project.AddService(api)
+	project.AddService(schema.NewService("worker")) // nothing to write
+
+	files, err := export.NewDotenvExporter().ExportFiles(project)
+	if err != nil {
+		t.Fatalf("ExportFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != ".env.api" {
+		t.Fatalf("Expected only .env.api, got %+v", files)
+	}
+
+	expected := `# API_SECRET is sensitive, set it before use
+API_SECRET=
+GREETING="say \"hi\" # loudly"
+LOG_LEVEL=info
+PRICE='$5'
+`
+	if string(files[0].Content) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, files[0].Content)
+	}
+}
+
+func TestRailwayVariablesExporter_ExportFiles(t *testing.T) {
+	project := schema.NewProject("shop")
+	api := schema.NewService("api")
+	api.Environment["LOG_LEVEL"] = schema.NewEnvVar("info", false)
+	api.Environment["API_SECRET"] = schema.NewEnvVar("hunter2", true)
+	api.Environment["PORT"] = schema.NewEnvVar("3000", false)
+	api.Environment["RENDER_EXTERNAL_URL"] = schema.NewEnvVar("", false)
+	databaseURL := schema.NewEnvVar("postgres://db:5432/app", true)
+	databaseURL.Reference = &schema.EnvReference{Service: "db", Variable: "DATABASE_URL"}
+	api.Environment["DATABASE_URL"] = databaseURL
+	project.AddService(api)
+
+	files, err := export.NewRailwayVariablesExporter().ExportFiles(project)
+	if err != nil {
+		t.Fatalf("ExportFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "api.variables.json" {
+		t.Fatalf("Expected only api.variables.json, got %+v", files)
+	}
+
+	var variables map[string]string
+	if err := json.Unmarshal(files[0].Content, &variables); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"LOG_LEVEL":           "info",
+		"API_SECRET":          "",
+		"DATABASE_URL":        "${{db.DATABASE_URL}}",
+		"RENDER_EXTERNAL_URL": "https://${{RAILWAY_PUBLIC_DOMAIN}}",
+	}
+	if len(variables) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, variables)
+	}
+	for name, value := range expected {
+		if variables[name] != value {
+			t.Errorf("Expected %s=%q, got %q", name, value, variables[name])
+		}
+	}
+}
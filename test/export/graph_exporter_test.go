@@ -0,0 +1,78 @@
+package export_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/export"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+func graphProject() *schema.Project {
+	project := schema.NewProject("shop")
+	web := schema.NewService("web-app")
+	web.Dependencies = []string{"api"}
+	api := schema.NewService("api")
+	api.Environment["DATABASE_URL"] = schema.EnvVar{Reference: &schema.EnvReference{Service: "db", Variable: "DATABASE_URL"}}
+	api.Environment["DB_HOST"] = schema.EnvVar{Reference: &schema.EnvReference{Service: "db", Variable: "RAILWAY_PRIVATE_DOMAIN"}}
+	api.Dependencies = []string{"db"}
+	db := schema.NewService("db")
+	db.Managed = &schema.Managed{Kind: "postgres", Template: "postgres"}
+	db.Derived = true
+	project.AddService(web)
+	project.AddService(api)
+	project.AddService(db)
+	return project
+}
+
+func TestGraphExporter_DOT(t *testing.T) {
+	exporter, err := export.NewGraphExporter(export.GraphDOT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := exporter.Export(graphProject())
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	expected := `digraph "shop" {
+  rankdir=LR;
+  node [shape=box];
+  "api" [label="api"];
+  "db" [label="db\nRailway postgres\n(implied)", shape=cylinder, style=dashed];
+  "web-app" [label="web-app"];
+  "api" -> "db" [label="DATABASE_URL\nDB_HOST"];
+  "web-app" -> "api";
+}
+`
+	if string(output) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestGraphExporter_Mermaid(t *testing.T) {
+	exporter, err := export.NewGraphExporter(export.GraphMermaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := exporter.Export(graphProject())
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	for _, line := range []string{
+		`svc_db[("db<br/>Railway postgres<br/>(implied)")]`,
+		`svc_web_app["web-app"]`,
+		`svc_api -->|"DATABASE_URL<br/>DB_HOST"| svc_db`,
+		`svc_web_app --> svc_api`,
+		`class svc_db implied`,
+	} {
+		if !strings.Contains(string(output), line) {
+			t.Errorf("Expected %q in:\n%s", line, output)
+		}
+	}
+
+	if _, err := export.NewGraphExporter("svg"); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,138 @@
+package export_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery/types"
+	"github.com/railwayapp/turnout/internal/export"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+func TestRailwayExporter_ExportFiles(t *testing.T) {
+	project := schema.NewProject("shop")
+
+	web := schema.NewService("web")
+	web.SourcePath = "apps/web"
+	web.Dockerfile = "apps/web/Dockerfile.prod"
+	web.StartCommand = "node server.js"
+	web.HealthcheckPath = "/health"
+	web.Replicas = 2
+	web.Ports = append(web.Ports, schema.NewPort(3000, true))
+	project.AddService(web)
+
+	// Two services built from the same directory can't share its railway.json
+	for _, name := range []string{"api", "cleanup"} {
+		service := schema.NewService(name)
+		service.SourcePath = "."
+		if name == "cleanup" {
+			service.Schedule = "0 3 * * *"
+		}
+		project.AddService(service)
+	}
+
+	db := schema.NewService("db")
+	db.Image = "postgres:16"
+	db.Volumes = []schema.Volume{{MountPath: "/var/lib/postgresql/data"}}
+	project.AddService(db)
+
+	files, err := export.NewRailwayExporter().ExportFiles(project)
+	if err != nil {
+		t.Fatalf("ExportFiles failed: %v", err)
+	}
+
+	contents := make(map[string][]byte)
+	for _, file := range files {
+		contents[file.Path] = file.Content
+	}
+	for _, path := range []string{"apps/web/railway.json", "railway/api.json", "railway/cleanup.json", "railway.project.json"} {
+		if _, ok := contents[path]; !ok {
+			t.Errorf("Expected %s to be exported, got %d files", path, len(files))
+		}
+	}
+	if len(files) != 4 {
+		t.Errorf("Expected no config file for the image service, got %d files", len(files))
+	}
+
+	var config export.RailwayConfig
+	if err := json.Unmarshal(contents["apps/web/railway.json"], &config); err != nil {
+		t.Fatalf("Invalid railway.json: %v", err)
+	}
+	if config.Build.Builder != "DOCKERFILE" || config.Build.DockerfilePath != "Dockerfile.prod" {
+		t.Errorf("Expected Dockerfile build relative to the root directory, got %+v", config.Build)
+	}
+	if config.Deploy.HealthcheckPath != "/health" || config.Deploy.NumReplicas != 2 {
+		t.Errorf("Expected healthcheck and replicas, got %+v", config.Deploy)
+	}
+
+	if err := json.Unmarshal(contents["railway/cleanup.json"], &config); err != nil {
+		t.Fatalf("Invalid railway.json: %v", err)
+	}
+	if config.Deploy.CronSchedule != "0 3 * * *" || config.Deploy.RestartPolicyType != "NEVER" {
+		t.Errorf("Expected a cron schedule that doesn't restart, got %+v", config.Deploy)
+	}
+
+	var mapping export.RailwayProject
+	if err := json.Unmarshal(contents["railway.project.json"], &mapping); err != nil {
+		t.Fatalf("Invalid project mapping: %v", err)
+	}
+	if len(mapping.Services) != 4 || !mapping.Services[0].PublicDomain || mapping.Services[3].Image != "postgres:16" {
+		t.Errorf("Expected every service in the mapping, got %+v", mapping.Services)
+	}
+}
+
+func TestRailwayExporter_StaticSite(t *testing.T) {
+	project := schema.NewProjectFromServices("shop", filesystems.NewMemoryFS(), ".", []types.Service{
+		{Name: "site", Network: types.NetworkPublic, Build: types.BuildStatic, BuildPath: "site", OutputDir: "dist", PackageManager: types.PackageManagerPnpm},
+		{Name: "api", Network: types.NetworkPublic, Build: types.BuildFromSource, BuildPath: "api", Port: 8080, PackageManager: types.PackageManagerUv},
+	})
+	if site := project.Redacted().Services[0]; !site.Static || site.OutputDir != "dist" || site.PackageManager != "pnpm" {
+		t.Fatalf("Expected the static build and package manager in the plan, got %+v", site)
+	}
+
+	output, err := export.NewRailwayExporter().Export(project)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	var mapping export.RailwayProject
+	if err := json.Unmarshal(output, &mapping); err != nil {
+		t.Fatalf("Invalid project mapping: %v", err)
+	}
+	site, api := mapping.Services[0], mapping.Services[1]
+	if !site.Static || site.OutputDir != "dist" || site.PackageManager != "pnpm" {
+		t.Errorf("Expected a static pnpm build to dist, got %+v", site)
+	}
+	if api.Static || api.OutputDir != "" || api.PackageManager != "uv" {
+		t.Errorf("Expected a server built with uv, got %+v", api)
+	}
+
+	main, err := export.NewTerraformExporter("acme/shop").Export(project)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(string(main), "# Built with pnpm\n# Static site, served from dist\nresource \"railway_service\" \"site\"") {
+		t.Errorf("Expected main.tf to note how the site is built:\n%s", main)
+	}
+}
+
+func TestRailwayExporter_SourceOutsideRepo(t *testing.T) {
+	project := schema.NewProjectFromServices("shop", filesystems.NewMemoryFS(), ".", []types.Service{
+		{Name: "web", Network: types.NetworkPublic, Build: types.BuildFromSource, BuildPath: "../../escape", Port: 3000},
+	})
+
+	files, err := export.NewRailwayExporter().ExportFiles(project)
+	if err != nil {
+		t.Fatalf("ExportFiles failed: %v", err)
+	}
+	for _, file := range files {
+		if !filepath.IsLocal(file.Path) {
+			t.Errorf("Expected every file inside the export, got %s", file.Path)
+		}
+	}
+	if files[0].Path != "railway/web.json" {
+		t.Errorf("Expected the config under railway/, got %s", files[0].Path)
+	}
+}
@@ -0,0 +1,97 @@
+package export_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/export"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+func TestTerraformExporter_ExportFiles(t *testing.T) {
+	project := schema.NewProject("shop")
+
+	web := schema.NewService("web")
+	web.SourcePath = "apps/web"
+	web.StartCommand = "node server.js"
+	web.Ports = append(web.Ports, schema.NewPort(3000, true))
+	web.Environment["API_SECRET"] = schema.NewEnvVar("abc", true)
+	web.Environment["GREETING"] = schema.NewEnvVar("hi ${name}", false)
+	web.Environment["DATABASE_URL"] = schema.EnvVar{Sensitive: true, Reference: &schema.EnvReference{Service: "db", Variable: "DATABASE_URL"}}
+	project.AddService(web)
+
+	db := schema.NewService("db")
+	db.Image = "postgres:16"
+	db.Volumes = []schema.Volume{{MountPath: "/var/lib/postgresql/data"}}
+	project.AddService(db)
+
+	files, err := export.NewTerraformExporter("acme/shop").ExportFiles(project)
+	if err != nil {
+		t.Fatalf("ExportFiles failed: %v", err)
+	}
+
+	contents := make(map[string]string)
+	for _, file := range files {
+		contents[file.Path] = string(file.Content)
+	}
+	for _, path := range []string{"main.tf", "variables.tf", "apps/web/railway.json"} {
+		if _, ok := contents[path]; !ok {
+			t.Errorf("Expected %s to be exported", path)
+		}
+	}
+
+	main := contents["main.tf"]
+	for _, expected := range []string{
+		`source = "terraform-community-providers/railway"`,
+		`resource "railway_service" "web" {`,
+		`  source_repo    = var.source_repo`,
+		`  config_path    = "/apps/web/railway.json"`,
+		`  source_image = "postgres:16"`,
+		`    mount_path = "/var/lib/postgresql/data"`,
+		`resource "railway_service_domain" "web" {`,
+		`  value          = var.web_api_secret`,
+		`  value          = "hi $${name}"`,
+		`  value          = "$${{db.DATABASE_URL}}"`,
+	} {
+		if !strings.Contains(main, expected) {
+			t.Errorf("Expected main.tf to contain %q:\n%s", expected, main)
+		}
+	}
+	if strings.Contains(main, "abc") {
+		t.Errorf("Expected secret values to stay out of main.tf:\n%s", main)
+	}
+
+	variables := contents["variables.tf"]
+	if !strings.Contains(variables, `variable "web_api_secret" {`) || !strings.Contains(variables, `default     = "acme/shop"`) {
+		t.Errorf("Unexpected variables.tf:\n%s", variables)
+	}
+}
+
+func TestTerraformExporter_VariableIDs(t *testing.T) {
+	project := schema.NewProject("shop")
+	web := schema.NewService("web")
+	web.Environment["DB_URL"] = schema.NewEnvVar("postgres://web", true)
+	web.Environment["Token"] = schema.NewEnvVar("a", true)
+	web.Environment["TOKEN"] = schema.NewEnvVar("b", true)
+	project.AddService(web)
+	webDB := schema.NewService("web_db")
+	webDB.Environment["URL"] = schema.NewEnvVar("postgres://web_db", true)
+	project.AddService(webDB)
+
+	files, err := export.NewTerraformExporter("acme/shop").ExportFiles(project)
+	if err != nil {
+		t.Fatalf("ExportFiles failed: %v", err)
+	}
+	for _, file := range files {
+		seen := make(map[string]bool)
+		for _, line := range strings.Split(string(file.Content), "\n") {
+			if !strings.HasPrefix(line, "resource ") && !strings.HasPrefix(line, "variable ") {
+				continue
+			}
+			if seen[line] {
+				t.Errorf("Expected %s to be declared once in %s:\n%s", line, file.Path, file.Content)
+			}
+			seen[line] = true
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package filesystems
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestArchiveFS_Tarball(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "source.tar.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTarball(t, file, map[string]string{
+		"./":                 "",
+		"./api/":             "",
+		"./api/Dockerfile":   "FROM golang:1.25\n",
+		"../outside.txt":     "escaped",
+		"./web/package.json": "{}",
+	})
+	file.Close()
+
+	if !filesystems.IsArchive(path) || !filesystems.IsArchive("-") || filesystems.IsArchive("src") {
+		t.Error("expected .tar.gz and - to be archives and directories not to be")
+	}
+
+	fs, err := filesystems.NewFileSystem(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if base := filesystems.GetBasePath(path); base != "." {
+		t.Errorf("expected archives to be rooted at '.', got %q", base)
+	}
+
+	content, err := fs.ReadFile("api/Dockerfile")
+	if err != nil || string(content) != "FROM golang:1.25\n" {
+		t.Errorf("expected the Dockerfile, got %q (%v)", content, err)
+	}
+
+	var names []string
+	for entry, err := range fs.ReadDir(".") {
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		names = append(names, entry.Name())
+	}
+	if len(names) != 2 || names[0] != "api" || names[1] != "web" {
+		t.Errorf("expected api and web at the root without escaped entries, got %v", names)
+	}
+}
+
+// writeTarball writes a gzipped tarball of files, keyed by name; names ending in / are directories
+func writeTarball(t *testing.T, w io.Writer, files map[string]string) {
+	t.Helper()
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(name, "/") {
+			header.Typeflag, header.Mode, header.Size = tar.TypeDir, 0o755, 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,56 @@
+package filesystems
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestAuditFS(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("package.json", []byte(`{"name": "web"}`))
+	mfs.AddFile("web/Dockerfile", []byte("FROM node\n"))
+	audit := filesystems.NewAuditFS(mfs)
+	cfs := filesystems.NewCachedFS(audit)
+
+	cfs.ReadFile("package.json")
+	cfs.ReadFile("package.json") // cached, so it doesn't reach the source
+	reader, err := cfs.Open("web/Dockerfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.ReadAll(reader)
+	reader.Close()
+	for range cfs.ReadDir(".") {
+	}
+
+	reads := audit.Reads()
+	if len(reads) != 3 {
+		t.Fatalf("expected 3 reads, got %+v", reads)
+	}
+	for i, expected := range []filesystems.AuditRead{
+		{Op: "read", Path: "package.json", Bytes: 15},
+		{Op: "open", Path: "web/Dockerfile", Bytes: 10},
+		{Op: "readdir", Path: ".", Bytes: 2},
+	} {
+		read := reads[i]
+		if read.Op != expected.Op || read.Path != expected.Path || read.Bytes != expected.Bytes {
+			t.Errorf("expected %s %s of %d bytes, got %s %s of %d", expected.Op, expected.Path, expected.Bytes, read.Op, read.Path, read.Bytes)
+		}
+		// Reads through wrappers in the filesystems package are attributed to their caller
+		if read.Component != "filesystems.TestAuditFS" {
+			t.Errorf("expected the test to be the component, got %s", read.Component)
+		}
+	}
+
+	var report bytes.Buffer
+	if err := audit.WriteReport(&report); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(report.String(), "filesystems.TestAuditFS  3      25") {
+		t.Errorf("expected the component's totals in the report, got\n%s", report.String())
+	}
+}
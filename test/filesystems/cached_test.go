@@ -0,0 +1,62 @@
+package filesystems
+
+import (
+	"io"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+// countingFS counts the reads that reach the filesystem it wraps
+type countingFS struct {
+	*filesystems.MemoryFS
+	reads int
+}
+
+func (c *countingFS) ReadFile(name string) ([]byte, error) {
+	c.reads++
+	return c.MemoryFS.ReadFile(name)
+}
+
+func TestCachedFS(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("package.json", []byte(`{"name": "web"}`))
+	counting := &countingFS{MemoryFS: mfs}
+	cfs := filesystems.NewCachedFS(counting)
+
+	for range 3 {
+		if content, err := cfs.ReadFile("package.json"); err != nil || string(content) != `{"name": "web"}` {
+			t.Fatalf("expected package.json, got %q (%v)", content, err)
+		}
+	}
+	reader, err := cfs.Open("package.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := io.ReadAll(reader); string(content) != `{"name": "web"}` {
+		t.Errorf("expected Open to serve the cached file, got %q", content)
+	}
+	if counting.reads != 1 {
+		t.Errorf("expected 1 read, got %d", counting.reads)
+	}
+
+	// Missing files are remembered too
+	cfs.ReadFile("Dockerfile")
+	cfs.ReadFile("Dockerfile")
+	if counting.reads != 2 {
+		t.Errorf("expected 2 reads, got %d", counting.reads)
+	}
+
+	mfs.AddFile("Dockerfile", []byte("FROM node\n"))
+	if _, err := cfs.ReadFile("Dockerfile"); err == nil {
+		t.Error("expected the cached miss until reset")
+	}
+	cfs.Reset()
+	if content, err := cfs.ReadFile("Dockerfile"); err != nil || string(content) != "FROM node\n" {
+		t.Errorf("expected the new Dockerfile after reset, got %q (%v)", content, err)
+	}
+
+	if filesystems.Unwrap(cfs) != counting {
+		t.Error("expected Unwrap to return the wrapped filesystem")
+	}
+}
@@ -0,0 +1,26 @@
+package filesystems
+
+import (
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestNewFileSystem_Bitbucket(t *testing.T) {
+	for _, uri := range []string{"bitbucket://acme/api", "bitbucket://acme/api/src/develop/services/web"} {
+		fs, err := filesystems.NewFileSystem(uri)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", uri, err)
+		}
+		if _, ok := fs.(*filesystems.BitbucketFS); !ok {
+			t.Errorf("%s: expected a BitbucketFS, got %T", uri, fs)
+		}
+		if base := filesystems.GetBasePath(uri); base != "." {
+			t.Errorf("%s: expected base path '.', got %q", uri, base)
+		}
+	}
+
+	if _, err := filesystems.NewFileSystem("bitbucket://acme"); err == nil {
+		t.Error("expected an error for a URL without a repository")
+	}
+}
@@ -0,0 +1,255 @@
+package filesystems
+
+import (
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestGitFS_Local(t *testing.T) {
+	remote := t.TempDir()
+	first := gitCommit(t, remote, "Dockerfile", "FROM alpine\n")
+	head := gitCommit(t, remote, "web/Dockerfile", "FROM node\n")
+
+	// A token is only sent to HTTPS remotes, so it's harmless here
+	gfs, err := filesystems.NewGitFS(remote, "", filesystems.GitCredentials{Token: "secret"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer gfs.Cleanup()
+
+	if content, err := gfs.ReadFile("web/Dockerfile"); err != nil || string(content) != "FROM node\n" {
+		t.Errorf("expected the web Dockerfile, got %q (%v)", content, err)
+	}
+	var names []string
+	for entry, err := range gfs.ReadDir(".") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, ",") != "Dockerfile,web" {
+		t.Errorf("expected the root's entries, got %v", names)
+	}
+	if revision, err := gfs.Revision(); err != nil || revision != head {
+		t.Errorf("expected revision %s, got %q (%v)", head, revision, err)
+	}
+
+	// Earlier commits can be read by SHA
+	gfs, err = filesystems.NewGitFS(remote, first[:8], filesystems.GitCredentials{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := gfs.ReadFile("web/Dockerfile"); err == nil {
+		t.Error("expected the first commit not to have the web Dockerfile")
+	}
+}
+
+func TestGitFS_Remote(t *testing.T) {
+	backend, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Skip("git isn't installed to serve the test repository")
+	}
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "api")
+	first := gitCommit(t, remote, "Dockerfile", "FROM alpine\n")
+	head := gitCommit(t, remote, "web/Dockerfile", "FROM node\n")
+
+	server := httptest.NewServer(&cgi.Handler{
+		Path: filepath.Join(strings.TrimSpace(string(backend)), "git-http-backend"),
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	defer server.Close()
+
+	for ref, expected := range map[string]string{"": head, "main": head, first: first} {
+		gfs, err := filesystems.NewGitFS(server.URL+"/api", ref, filesystems.GitCredentials{})
+		if err != nil {
+			t.Fatalf("%q: expected no error, got %v", ref, err)
+		}
+		if content, err := gfs.ReadFile("Dockerfile"); err != nil || string(content) != "FROM alpine\n" {
+			t.Errorf("%q: expected the Dockerfile, got %q (%v)", ref, content, err)
+		}
+		if revision, _ := gfs.Revision(); revision != expected {
+			t.Errorf("%q: expected revision %s, got %s", ref, expected, revision)
+		}
+	}
+
+	if _, err := filesystems.NewGitFS(server.URL+"/api", "missing", filesystems.GitCredentials{}); err == nil || !strings.Contains(err.Error(), "no branch, tag or commit named missing") {
+		t.Errorf("expected an error for a missing ref, got %v", err)
+	}
+}
+
+func TestGitFS_Submodules(t *testing.T) {
+	root := t.TempDir()
+	gitCommit(t, filepath.Join(root, "billing"), "Dockerfile", "FROM python\n")
+	remote := filepath.Join(root, "platform")
+	gitCommit(t, remote, "README.md", "# Platform\n")
+	runGit(t, remote, "-c", "protocol.file.allow=always", "submodule", "add", "-q", "../billing", "services/billing")
+	runGit(t, remote, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-qm", "add billing")
+
+	gfs, err := filesystems.NewGitFS(remote, "", filesystems.GitCredentials{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := gfs.ReadFile("services/billing/Dockerfile"); err == nil {
+		t.Error("expected submodules to be empty unless enabled")
+	}
+
+	gfs.Submodules = true
+	if content, err := gfs.ReadFile("services/billing/Dockerfile"); err != nil || string(content) != "FROM python\n" {
+		t.Errorf("expected the submodule's Dockerfile, got %q (%v)", content, err)
+	}
+	var found bool
+	err = gfs.Walk(".", func(path string, info filesystems.FileInfo, err error) error {
+		found = found || path == "services/billing/Dockerfile"
+		return err
+	})
+	if err != nil || !found {
+		t.Errorf("expected walks to descend into submodules, got %v", err)
+	}
+}
+
+func TestGitFS_SubmoduleOnAnotherHost(t *testing.T) {
+	backend, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Skip("git isn't installed to serve the test repositories")
+	}
+
+	root := t.TempDir()
+	gitCommit(t, filepath.Join(root, "billing"), "Dockerfile", "FROM python\n")
+	platform, _ := gitHTTPServer(t, strings.TrimSpace(string(backend)), root)
+	other, authorizations := gitHTTPServer(t, strings.TrimSpace(string(backend)), root)
+
+	remote := filepath.Join(root, "platform")
+	gitCommit(t, remote, "README.md", "# Platform\n")
+	runGit(t, remote, "-c", "protocol.file.allow=always", "submodule", "add", "-q", "../billing", "services/billing")
+	runGit(t, remote, "config", "-f", ".gitmodules", "submodule.services/billing.url", other.URL+"/billing")
+	runGit(t, remote, "add", ".gitmodules")
+	runGit(t, remote, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-qm", "add billing")
+
+	gfs, err := filesystems.NewGitFS(platform.URL+"/platform", "", filesystems.GitCredentials{Token: "secret"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	gfs.Submodules = true
+	if content, err := gfs.ReadFile("services/billing/Dockerfile"); err != nil || string(content) != "FROM python\n" {
+		t.Errorf("expected the submodule's Dockerfile, got %q (%v)", content, err)
+	}
+	for _, authorization := range *authorizations {
+		if authorization != "" {
+			t.Errorf("expected no credentials sent to the submodule's host, got %q", authorization)
+		}
+	}
+}
+
+func TestGitFS_LocalSubmoduleOfRemote(t *testing.T) {
+	backend, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Skip("git isn't installed to serve the test repository")
+	}
+
+	root := t.TempDir()
+	private := filepath.Join(t.TempDir(), "private")
+	gitCommit(t, private, "secrets.env", "API_KEY=secret\n")
+	server, _ := gitHTTPServer(t, strings.TrimSpace(string(backend)), root)
+
+	remote := filepath.Join(root, "platform")
+	gitCommit(t, remote, "README.md", "# Platform\n")
+	runGit(t, remote, "-c", "protocol.file.allow=always", "submodule", "add", "-q", private, "vendor/private")
+	runGit(t, remote, "config", "-f", ".gitmodules", "submodule.vendor/private.url", "file://"+private)
+	runGit(t, remote, "add", ".gitmodules")
+	runGit(t, remote, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-qm", "add private")
+
+	gfs, err := filesystems.NewGitFS(server.URL+"/platform", "", filesystems.GitCredentials{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	gfs.Submodules = true
+	if content, err := gfs.ReadFile("vendor/private/secrets.env"); err == nil || !strings.Contains(err.Error(), "refusing to open local path") {
+		t.Errorf("expected a remote's local submodule to be refused, got %q (%v)", content, err)
+	}
+}
+
+func TestGitFS_CloneError(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	_, err := filesystems.NewGitFS(missing, "main", filesystems.GitCredentials{})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected the reason in the error, got %v", err)
+	}
+}
+
+func TestGetBasePath_SSHRemotes(t *testing.T) {
+	for _, uri := range []string{"git@github.com:acme/api.git", "ssh://git@github.com/acme/api", "git+ssh://git@example.com/acme/api#main"} {
+		if base := filesystems.GetBasePath(uri); base != "." {
+			t.Errorf("%s: expected base path '.', got %q", uri, base)
+		}
+	}
+}
+
+// gitCommit commits a file to a repository on main, creating the repository if
+// needed, and returns the commit's SHA
+func gitCommit(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		os.MkdirAll(dir, 0755)
+		runGit(t, dir, "init", "-q", "-b", "main")
+	}
+	os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+	os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-qm", "add "+name)
+	return strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+}
+
+// gitHTTPServer serves the repositories under root over HTTP, recording the
+// Authorization header of each request
+func gitHTTPServer(t *testing.T, execPath, root string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var authorizations []string
+	backend := &cgi.Handler{
+		Path: filepath.Join(execPath, "git-http-backend"),
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &authorizations
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+	return string(output)
+}
+
+func TestLocalFS_Tracked(t *testing.T) {
+	dir := t.TempDir()
+	gitCommit(t, dir, "api/.env", "SECRET=abc\n")
+	os.WriteFile(filepath.Join(dir, "api", ".env.local"), []byte("SECRET=def\n"), 0644)
+
+	lfs := filesystems.NewLocalFS()
+	if !filesystems.IsTracked(lfs, filepath.Join(dir, "api", ".env")) {
+		t.Error("expected the committed .env to be tracked")
+	}
+	if filesystems.IsTracked(lfs, filepath.Join(dir, "api", ".env.local")) {
+		t.Error("expected the untracked .env.local not to be tracked")
+	}
+	if filesystems.IsTracked(lfs, filepath.Join(t.TempDir(), ".env")) {
+		t.Error("expected files outside a repository not to be tracked")
+	}
+}
@@ -0,0 +1,73 @@
+package filesystems
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestGitHostingFS(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range map[string]string{
+		"api/":                    "",
+		"api/services/":           "",
+		"api/services/web/":       "",
+		"api/services/web/go.mod": "module web\n",
+		"api/README.md":           "# api\n",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/repos/acme/api":
+			w.Write([]byte(`{"default_branch": "trunk"}`))
+		case "/api/v1/repos/acme/api/archive/trunk.zip":
+			w.Write(archive.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	hfs := filesystems.NewGitHostingFS(server.URL, "acme", "api", "", "services", "secret")
+	defer hfs.Cleanup()
+
+	content, err := hfs.ReadFile("web/go.mod")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(content) != "module web\n" {
+		t.Errorf("expected go.mod contents, got %q", content)
+	}
+
+	var names []string
+	for entry, err := range hfs.ReadDir(".") {
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		names = append(names, entry.Name())
+	}
+	if len(names) != 1 || names[0] != "web" {
+		t.Errorf("expected the base path's entries, got %v", names)
+	}
+
+	unauthorized := filesystems.NewGitHostingFS(server.URL, "acme", "api", "trunk", "", "")
+	if _, err := unauthorized.ReadFile("README.md"); err == nil {
+		t.Error("expected an error without a token")
+	}
+}
@@ -0,0 +1,74 @@
+package filesystems
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestGitHubFS_RetriesSecondaryRateLimit(t *testing.T) {
+	t.Setenv("TURNOUT_NO_CACHE", "1")
+	archive := githubZipball(t)
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+			return
+		}
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	host := filesystems.GitHubHost{ServerURL: server.URL, APIURL: server.URL}
+	gfs := filesystems.NewGitHubFSWithHost(host, "acme", "api", "main", "", "secret")
+	defer gfs.Cleanup()
+
+	started := time.Now()
+	if _, err := gfs.ReadFile("Dockerfile"); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if attempts != 2 || time.Since(started) < time.Second {
+		t.Errorf("expected a retry after Retry-After, got %d attempts in %s", attempts, time.Since(started))
+	}
+}
+
+func TestGitHubFS_RateLimitError(t *testing.T) {
+	t.Setenv("TURNOUT_NO_CACHE", "1")
+
+	reset := time.Now().Add(time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	host := filesystems.GitHubHost{ServerURL: server.URL, APIURL: server.URL}
+	gfs := filesystems.NewGitHubFSWithHost(host, "acme", "api", "main", "", "")
+	defer gfs.Cleanup()
+
+	_, err := gfs.ReadFile("Dockerfile")
+	var rateLimitErr *filesystems.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("expected a RateLimitError, got %v", err)
+	}
+	if rateLimitErr.Secondary || rateLimitErr.Reset.Unix() != reset.Unix() {
+		t.Errorf("expected the primary limit's reset time, got %+v", rateLimitErr)
+	}
+	if !errors.Is(err, filesystems.ErrRateLimited) {
+		t.Errorf("expected the error to be ErrRateLimited, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "GITHUB_TOKEN") {
+		t.Errorf("expected the error to suggest a token, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package filesystems
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestListGitHubRepos(t *testing.T) {
+	// 150 repositories take two pages of 100
+	var orgRepos []filesystems.GitHubRepo
+	for i := range 150 {
+		orgRepos = append(orgRepos, filesystems.GitHubRepo{Name: fmt.Sprintf("repo-%d", i), FullName: fmt.Sprintf("acme/repo-%d", i)})
+	}
+	userRepos := []filesystems.GitHubRepo{{Name: "dotfiles", FullName: "jane/dotfiles", Fork: true}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		var repos []filesystems.GitHubRepo
+		switch r.URL.Path {
+		case "/orgs/acme/repos":
+			repos = orgRepos
+		case "/users/jane/repos":
+			repos = userRepos
+		case "/orgs/broken/repos":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		start := min((page-1)*perPage, len(repos))
+		_ = json.NewEncoder(w).Encode(repos[start:min(start+perPage, len(repos))])
+	}))
+	defer server.Close()
+	host := filesystems.GitHubHost{ServerURL: server.URL, APIURL: server.URL}
+
+	tests := []struct {
+		name     string
+		owner    string
+		repos    int
+		notFound bool
+	}{
+		{name: "organization across pages", owner: "acme", repos: 150},
+		{name: "user without an organization", owner: "jane", repos: 1},
+		{name: "unknown owner", owner: "nobody", notFound: true},
+		{name: "server error", owner: "broken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos, err := filesystems.ListGitHubRepos(context.Background(), host, tt.owner, "")
+			if errors.Is(err, filesystems.ErrNotFound) != tt.notFound {
+				t.Fatalf("Expected not found to be %v, got %v", tt.notFound, err)
+			}
+			if tt.repos == 0 {
+				if err == nil {
+					t.Errorf("Expected an error, got %d repositories", len(repos))
+				}
+				return
+			}
+			if err != nil || len(repos) != tt.repos {
+				t.Errorf("Expected %d repositories, got %d (%v)", tt.repos, len(repos), err)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package filesystems
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestGitHubFS_EnterpriseHost(t *testing.T) {
+	t.Setenv("TURNOUT_NO_CACHE", "1")
+	archive := githubZipball(t)
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v3/repos/acme/api/zipball/main":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write(archive.Bytes())
+		case "/acme/api/archive/main.zip":
+			w.Write(archive.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := filesystems.GitHubHost{ServerURL: server.URL, APIURL: server.URL + "/api/v3"}
+	for _, token := range []string{"secret", ""} {
+		gfs := filesystems.NewGitHubFSWithHost(host, "acme", "api", "main", "", token)
+		content, err := gfs.ReadFile("Dockerfile")
+		if err != nil || string(content) != "FROM alpine\n" {
+			t.Errorf("token %q: expected the Dockerfile, got %q (%v)", token, content, err)
+		}
+		gfs.Cleanup()
+	}
+	if len(requested) != 2 || requested[0] != "/api/v3/repos/acme/api/zipball/main" || requested[1] != "/acme/api/archive/main.zip" {
+		t.Errorf("expected an API download with a token and an archive link without, got %v", requested)
+	}
+}
+
+func TestGitHubEnterpriseHost(t *testing.T) {
+	host := filesystems.GitHubEnterpriseHost("ghe.example.com")
+	if host.ServerURL != "https://ghe.example.com" || host.APIURL != "https://ghe.example.com/api/v3" || host.CodeloadURL != "" {
+		t.Errorf("unexpected Enterprise Server endpoints: %+v", host)
+	}
+
+	t.Setenv("GITHUB_SERVER_URL", "https://ghe.example.com")
+	t.Setenv("GITHUB_API_URL", "https://ghe.example.com/api/v3/")
+	if host := filesystems.DefaultGitHubHost(); host != filesystems.GitHubEnterpriseHost("ghe.example.com") {
+		t.Errorf("expected the Actions environment to select the Enterprise Server, got %+v", host)
+	}
+
+	if _, err := filesystems.NewFileSystem("github://ghe.example.com/acme"); err == nil {
+		t.Error("expected an error for an Enterprise URL without a repository")
+	}
+}
+
+func TestGitHubFS_Cache(t *testing.T) {
+	archive := githubZipball(t)
+
+	var statuses []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"abc123"` {
+			statuses = append(statuses, http.StatusNotModified)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		statuses = append(statuses, http.StatusOK)
+		w.Header().Set("ETag", `"abc123"`)
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	host := filesystems.GitHubHost{ServerURL: server.URL, APIURL: server.URL}
+	cacheDir := t.TempDir()
+	for range 2 {
+		gfs := filesystems.NewGitHubFSWithHost(host, "acme", "api", "main", "", "")
+		gfs.CacheDir = cacheDir
+		content, err := gfs.ReadFile("Dockerfile")
+		if err != nil || string(content) != "FROM alpine\n" {
+			t.Errorf("expected the Dockerfile, got %q (%v)", content, err)
+		}
+		gfs.Cleanup()
+	}
+	if len(statuses) != 2 || statuses[0] != http.StatusOK || statuses[1] != http.StatusNotModified {
+		t.Errorf("expected a download then a revalidated cache hit, got %v", statuses)
+	}
+}
+
+// githubZipball builds a zipball with GitHub's top-level directory around the repository
+func githubZipball(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	if _, err := zw.Create("acme-api-abc123/"); err != nil {
+		t.Fatal(err)
+	}
+	w, err := zw.Create("acme-api-abc123/Dockerfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("FROM alpine\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &archive
+}
+
+func TestGitHubFS_FileInfo(t *testing.T) {
+	t.Setenv("TURNOUT_NO_CACHE", "1")
+	modified := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for _, header := range []*zip.FileHeader{
+		{Name: "acme-api-abc123/", Modified: modified},
+		{Name: "acme-api-abc123/empty/", Modified: modified},
+		{Name: "acme-api-abc123/Dockerfile", Modified: modified, Method: zip.Deflate},
+	} {
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(header.Name, "/") {
+			w.Write([]byte("FROM alpine\n"))
+		}
+	}
+	zw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	gfs := filesystems.NewGitHubFSWithHost(filesystems.GitHubHost{ServerURL: server.URL, APIURL: server.URL}, "acme", "api", "main", "", "")
+	defer gfs.Cleanup()
+
+	infos := make(map[string]filesystems.FileInfo)
+	for entry, err := range gfs.ReadDir(".") {
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		infos[entry.Name()] = info
+	}
+
+	if info := infos["Dockerfile"]; info == nil || info.Size() != int64(len("FROM alpine\n")) || !info.ModTime().Equal(modified) {
+		t.Errorf("expected the Dockerfile's uncompressed size and mod time, got %+v", info)
+	}
+	if info := infos["empty"]; info == nil || !info.IsDir() {
+		t.Errorf("expected empty directories to be directories, got %+v", info)
+	}
+}
+
+func TestGitHubFS_Revision(t *testing.T) {
+	t.Setenv("TURNOUT_NO_CACHE", "1")
+	const commented, resolved = "0123456789abcdef0123456789abcdef01234567", "89abcdef0123456789abcdef0123456789abcdef"
+
+	var withComment bytes.Buffer
+	zw := zip.NewWriter(&withComment)
+	zw.Create("acme-api-0123456/Dockerfile")
+	zw.SetComment(commented)
+	zw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/api/zipball/main":
+			w.Write(withComment.Bytes())
+		case "/repos/acme/api/zipball/dev":
+			w.Write(githubZipball(t).Bytes())
+		case "/repos/acme/api/commits/dev":
+			if r.Header.Get("Accept") != "application/vnd.github.sha" {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			w.Write([]byte(resolved))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := filesystems.GitHubHost{ServerURL: server.URL, APIURL: server.URL}
+	for ref, expected := range map[string]string{"main": commented, "dev": resolved} {
+		gfs := filesystems.NewGitHubFSWithHost(host, "acme", "api", ref, "", "token")
+		revision, err := gfs.Revision()
+		if err != nil || revision != expected {
+			t.Errorf("%s: expected revision %s, got %q (%v)", ref, expected, revision, err)
+		}
+		gfs.Cleanup()
+	}
+}
+
+func TestGitHubFS_DefaultBranch(t *testing.T) {
+	t.Setenv("TURNOUT_NO_CACHE", "1")
+	archive := githubZipball(t)
+
+	tests := []struct {
+		name   string
+		refs   string // the remote's ref advertisement, or "" when it isn't a repository
+		branch string // the branch downloaded, or "" when it fails
+	}{
+		{name: "master", refs: "symref=HEAD:refs/heads/master", branch: "master"},
+		{name: "trunk", refs: "symref=HEAD:refs/heads/trunk", branch: "trunk"},
+		{name: "not a repository"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var downloaded []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/acme/api/info/refs" && tt.refs != "":
+					w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+					sha := strings.Repeat("a", 40)
+					w.Write([]byte(pktLine("# service=git-upload-pack\n") + "0000" +
+						pktLine(sha+" HEAD\x00"+tt.refs+"\n") +
+						pktLine(sha+" refs/heads/"+tt.branch+"\n") + "0000"))
+				case strings.HasPrefix(r.URL.Path, "/acme/api/archive/"):
+					downloaded = append(downloaded, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/acme/api/archive/"), ".zip"))
+					w.Write(archive.Bytes())
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			gfs := filesystems.NewGitHubFSWithHost(filesystems.GitHubHost{ServerURL: server.URL, APIURL: server.URL}, "acme", "api", "", "", "")
+			defer gfs.Cleanup()
+			_, err := gfs.ReadFile("Dockerfile")
+			if tt.branch == "" {
+				if err == nil || len(downloaded) != 0 {
+					t.Errorf("Expected an error rather than a guessed branch, downloaded %v", downloaded)
+				}
+				return
+			}
+			if err != nil || len(downloaded) != 1 || downloaded[0] != tt.branch {
+				t.Errorf("Expected %s downloaded, got %v (%v)", tt.branch, downloaded, err)
+			}
+		})
+	}
+}
+
+// pktLine frames a line of the git protocol
+func pktLine(line string) string {
+	return fmt.Sprintf("%04x%s", len(line)+4, line)
+}
@@ -0,0 +1,104 @@
+package filesystems
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestGitHubTreeFS(t *testing.T) {
+	for _, truncated := range []bool{false, true} {
+		var requests []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.RequestURI())
+			switch r.URL.RequestURI() {
+			case "/repos/acme/monorepo/git/trees/HEAD?recursive=1":
+				if truncated {
+					w.Write([]byte(`{"truncated": true, "tree": [
+						{"path": "services", "type": "tree", "sha": "t1"},
+						{"path": "services/api", "type": "tree", "sha": "t2"},
+						{"path": "vendor", "type": "commit", "sha": "c1"}
+					]}`))
+					return
+				}
+				w.Write([]byte(`{"truncated": false, "tree": [
+					{"path": "services", "type": "tree", "sha": "t1"},
+					{"path": "services/api", "type": "tree", "sha": "t2"},
+					{"path": "services/api/Dockerfile", "type": "blob", "sha": "b1"},
+					{"path": "vendor", "type": "commit", "sha": "c1"}
+				]}`))
+			case "/repos/acme/monorepo/git/trees/t1":
+				w.Write([]byte(`{"tree": [{"path": "api", "type": "tree", "sha": "t2"}]}`))
+			case "/repos/acme/monorepo/git/trees/t2":
+				w.Write([]byte(`{"tree": [{"path": "Dockerfile", "type": "blob", "sha": "b1"}]}`))
+			case "/repos/acme/monorepo/contents/services/api/Dockerfile":
+				if r.Header.Get("Accept") != "application/vnd.github.raw" {
+					w.WriteHeader(http.StatusNotAcceptable)
+					return
+				}
+				w.Write([]byte("FROM node:20\n"))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		host := filesystems.GitHubHost{ServerURL: server.URL, APIURL: server.URL}
+		fs := filesystems.NewGitHubTreeFS(host, "acme", "monorepo", "", "services", "")
+
+		var names []string
+		for entry, err := range fs.ReadDir("api") {
+			if err != nil {
+				t.Fatalf("truncated=%v: expected no error, got %v", truncated, err)
+			}
+			names = append(names, entry.Name())
+		}
+		if !slices.Equal(names, []string{"Dockerfile"}) {
+			t.Errorf("truncated=%v: expected the Dockerfile, got %v", truncated, names)
+		}
+
+		content, err := fs.ReadFile("api/Dockerfile")
+		if err != nil || string(content) != "FROM node:20\n" {
+			t.Errorf("truncated=%v: expected the Dockerfile contents, got %q (%v)", truncated, content, err)
+		}
+		if _, err := fs.ReadFile("api/missing"); !errors.Is(err, filesystems.ErrNotFound) {
+			t.Errorf("truncated=%v: expected ErrNotFound for a missing file, got %v", truncated, err)
+		}
+
+		// A complete listing needs one request for the tree; a truncated one lists directories on demand
+		treeRequests := 0
+		for _, request := range requests {
+			if strings.HasPrefix(request, "/repos/acme/monorepo/git/trees/") {
+				treeRequests++
+			}
+		}
+		if expected := map[bool]int{false: 1, true: 3}[truncated]; treeRequests != expected {
+			t.Errorf("truncated=%v: expected %d tree requests, got %v", truncated, expected, requests)
+		}
+		server.Close()
+	}
+}
+
+func TestGitHubTreeFS_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	host := filesystems.GitHubHost{ServerURL: server.URL, APIURL: server.URL}
+	fs := filesystems.NewGitHubTreeFS(host, "acme", "private", "", "", "bad-token")
+	var err error
+	for _, err = range fs.ReadDir("") {
+		break
+	}
+	if !errors.Is(err, filesystems.ErrAuth) || errors.Is(err, filesystems.ErrNotFound) {
+		t.Fatalf("expected ErrAuth, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "HTTP 401 Unauthorized") {
+		t.Errorf("expected the status in the error, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package filesystems
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestReadTextFile(t *testing.T) {
+	defer func(size int64) { filesystems.MaxTextFileSize = size }(filesystems.MaxTextFileSize)
+	filesystems.MaxTextFileSize = 16
+
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile(".env", []byte("PORT=8080\n"))
+	mfs.AddFile("exact.txt", []byte(strings.Repeat("a", 16)))
+	mfs.AddFile("dump.sql", []byte(strings.Repeat("INSERT INTO t;\n", 100)))
+	mfs.AddFile("logo.png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+
+	if content, err := filesystems.ReadTextFile(mfs, ".env"); err != nil || string(content) != "PORT=8080\n" {
+		t.Errorf("expected .env, got %q (%v)", content, err)
+	}
+	if content, err := filesystems.ReadTextFile(mfs, "exact.txt"); err != nil || len(content) != 16 {
+		t.Errorf("expected a file of exactly the limit to be read, got %d bytes (%v)", len(content), err)
+	}
+	if _, err := filesystems.ReadTextFile(mfs, "dump.sql"); !errors.Is(err, filesystems.ErrFileTooLarge) {
+		t.Errorf("expected ErrFileTooLarge, got %v", err)
+	}
+	if _, err := filesystems.ReadTextFile(mfs, "logo.png"); !errors.Is(err, filesystems.ErrBinaryFile) {
+		t.Errorf("expected ErrBinaryFile, got %v", err)
+	}
+}
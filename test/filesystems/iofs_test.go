@@ -0,0 +1,65 @@
+package filesystems
+
+import (
+	"io"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestIOFS(t *testing.T) {
+	ifs := filesystems.NewIOFS(fstest.MapFS{
+		"compose.yaml":          {Data: []byte("services: {}\n")},
+		"services/api/app.py":   {Data: []byte("print('hi')\n")},
+		"services/web/index.js": {Data: []byte("console.log('hi')\n")},
+	})
+
+	if content, err := ifs.ReadFile("/compose.yaml"); err != nil || string(content) != "services: {}\n" {
+		t.Errorf("expected compose.yaml, got %q (%v)", content, err)
+	}
+	reader, err := ifs.Open("services/api/app.py")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := io.ReadAll(reader); string(content) != "print('hi')\n" {
+		t.Errorf("expected app.py, got %q", content)
+	}
+	reader.Close()
+	if _, err := ifs.Open("services"); err == nil {
+		t.Error("expected an error opening a directory")
+	}
+	if _, err := ifs.ReadFile("../etc/passwd"); err == nil {
+		t.Error("expected an error for a path outside the filesystem")
+	}
+
+	var names []string
+	for entry, err := range ifs.ReadDir("services") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, entry.Name())
+	}
+	if !slices.Equal(names, []string{"api", "web"}) {
+		t.Errorf("expected the services' directories, got %v", names)
+	}
+
+	var walked []string
+	err = ifs.Walk(".", func(path string, info filesystems.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			walked = append(walked, path)
+		}
+		return err
+	})
+	if err != nil || len(walked) != 3 {
+		t.Errorf("expected to walk 3 files, got %v (%v)", walked, err)
+	}
+
+	if matches, _ := ifs.Glob("services/*/*.js"); !slices.Equal(matches, []string{"services/web/index.js"}) {
+		t.Errorf("expected index.js to match, got %v", matches)
+	}
+	if !ifs.Exists("services/api") || ifs.Exists("services/worker") {
+		t.Error("expected Exists to report only existing paths")
+	}
+}
@@ -1,8 +1,11 @@
 package filesystems
 
 import (
-	"github.com/railwayapp/turnout/internal/filesystems"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
 )
 
 func TestMemoryFS_AddFile(t *testing.T) {
@@ -38,8 +41,8 @@ func TestMemoryFS_ReadFile_NotFound(t *testing.T) {
 	mfs := filesystems.NewMemoryFS()
 
 	_, err := mfs.ReadFile("nonexistent.txt")
-	if err == nil {
-		t.Fatal("expected error for nonexistent file")
+	if !errors.Is(err, filesystems.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for nonexistent file, got %v", err)
 	}
 }
 
@@ -226,3 +229,33 @@ func TestMemoryFS_DirEntry_Info(t *testing.T) {
 		}
 	}
 }
+
+func TestMemoryFS_Glob(t *testing.T) {
+	mfs := filesystems.NewMemoryFS()
+	mfs.AddFile("charts/api/values.yaml", nil)
+	mfs.AddFile("charts/api/values.prod.yaml", nil)
+	mfs.AddFile("charts/web/values-staging.yaml", nil)
+	mfs.AddFile("charts/web/templates/deployment.yaml", nil)
+
+	matches, err := mfs.Glob("charts/*/values.*")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(matches, ",") != "charts/api/values.prod.yaml,charts/api/values.yaml" {
+		t.Errorf("expected the values files, got %v", matches)
+	}
+
+	if matches, _ := mfs.Glob("charts/web/templates/deployment.yaml"); len(matches) != 1 {
+		t.Errorf("expected literal patterns to match existing files, got %v", matches)
+	}
+	if matches, _ := mfs.Glob("charts/db/*"); matches != nil {
+		t.Errorf("expected no matches under a missing directory, got %v", matches)
+	}
+	if _, err := mfs.Glob("charts/["); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+
+	if !mfs.Exists("charts/web") || !mfs.Exists("charts/api/values.yaml") || mfs.Exists("charts/db") {
+		t.Error("expected Exists to find files and directories only")
+	}
+}
@@ -0,0 +1,75 @@
+package filesystems
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/filesystems"
+)
+
+func TestObjectStorageFS_S3(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/artifacts/builds/app v2.tar.gz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeTarball(t, w, map[string]string{"web/Dockerfile": "FROM node:20\n"})
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ENDPOINT_URL_S3", server.URL)
+	t.Setenv("AWS_REGION", "us-west-2")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	fs, err := filesystems.NewObjectStorageFS(context.Background(), "s3://artifacts/builds/app%20v2.tar.gz")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	content, err := fs.ReadFile("web/Dockerfile")
+	if err != nil || string(content) != "FROM node:20\n" {
+		t.Errorf("expected the Dockerfile, got %q (%v)", content, err)
+	}
+
+	if _, err := filesystems.NewObjectStorageFS(context.Background(), "s3://artifacts/builds/app.jar"); err == nil {
+		t.Error("expected an error for objects that aren't archives")
+	}
+}
+
+func TestObjectStorageFS_Zip(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, _ := zw.Create("api/main.go")
+	_, _ = w.Write([]byte("package main\n"))
+	_ = zw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+	t.Setenv("AWS_ENDPOINT_URL_S3", server.URL)
+
+	fs, err := filesystems.NewObjectStorageFS(context.Background(), "s3://artifacts/app.zip")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if content, err := fs.ReadFile("api/main.go"); err != nil || string(content) != "package main\n" {
+		t.Errorf("expected main.go, got %q (%v)", content, err)
+	}
+
+	defer func(size int64) { filesystems.MaxObjectSize = size }(filesystems.MaxObjectSize)
+	filesystems.MaxObjectSize = int64(archive.Len()) - 1
+	if _, err := filesystems.NewObjectStorageFS(context.Background(), "s3://artifacts/app.zip"); !errors.Is(err, filesystems.ErrFileTooLarge) {
+		t.Errorf("expected zips over MaxObjectSize to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package golden_test
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/discovery"
+	"github.com/railwayapp/turnout/internal/enrichment"
+	"github.com/railwayapp/turnout/internal/filesystems"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+var update = flag.Bool("update", false, "rewrite the expected plans from what discovery finds now")
+
+const corpus = "testdata/repos"
+
+// TestGolden plans every repository in the corpus and compares the plans to the ones
+// committed next to them, like testdata/repos/compose.plan.json, so a change to a
+// signal shows every plan it changes in review. Run with -update to accept them.
+func TestGolden(t *testing.T) {
+	entries, err := os.ReadDir(corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		t.Run(name, func(t *testing.T) {
+			got := plan(t, name)
+			expectedPath := filepath.Join(corpus, name+".plan.json")
+			if *update {
+				if err := os.WriteFile(expectedPath, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(expectedPath)
+			if err != nil {
+				t.Fatalf("No expected plan, run go test ./test/golden -update to create it: %v", err)
+			}
+			if !bytes.Equal(got, expected) {
+				t.Errorf("Plan changed, run go test ./test/golden -update to accept it:\n%s", lineDiff(string(expected), string(got)))
+			}
+		})
+	}
+}
+
+// plan discovers a corpus repository and encodes its project as a plan snapshot, in a
+// stable order so discovery's own ordering doesn't churn the expected files
+func plan(t *testing.T, name string) []byte {
+	t.Helper()
+	ctx := context.Background()
+
+	// Copied into memory, since extractors skip anything under a path mentioning tests
+	filesystem := filesystems.NewMemoryFS()
+	err := fs.WalkDir(os.DirFS(corpus), name, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(filepath.Join(corpus, path))
+		if err != nil {
+			return err
+		}
+		filesystem.AddFile(path, content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	services, err := discovery.NewServiceDiscovery(filesystem).Discover(ctx, name)
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	project := enrichment.NewProject(ctx, filesystem, name, name, services)
+
+	slices.SortFunc(project.Services, func(a, b schema.Service) int { return cmp.Compare(a.Name, b.Name) })
+	for i := range project.Services {
+		slices.Sort(project.Services[i].Dependencies)
+	}
+	slices.SortFunc(project.Topology, func(a, b schema.Link) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To))
+	})
+
+	var buf bytes.Buffer
+	if err := schema.NewSnapshot(corpus+"/"+name, project).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// lineDiff shows the lines that differ between two texts, between their common first
+// and last lines
+func lineDiff(expected, got string) string {
+	before, after := strings.Split(expected, "\n"), strings.Split(got, "\n")
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "@@ line %d @@\n", prefix+1)
+	for _, line := range before[prefix : len(before)-suffix] {
+		fmt.Fprintf(&diff, "-%s\n", line)
+	}
+	for _, line := range after[prefix : len(after)-suffix] {
+		fmt.Fprintf(&diff, "+%s\n", line)
+	}
+	return diff.String()
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"net/http"
+	"os"
+)
+
+func main() {
+	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+	http.ListenAndServe(":"+os.Getenv("PORT"), nil)
+	_ = os.Getenv("DATABASE_URL")
+}
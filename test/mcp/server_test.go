@@ -0,0 +1,95 @@
+package mcp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/mcp"
+)
+
+func TestServer_Serve(t *testing.T) {
+	echo := mcp.Tool{
+		Name:        "echo",
+		Description: "Echo the message back",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"message":{"type":"string"}}}`),
+		Handler: func(ctx context.Context, arguments json.RawMessage) (any, error) {
+			var args struct {
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(arguments, &args); err != nil {
+				return nil, err
+			}
+			if args.Message == "" {
+				return nil, errors.New("message is required")
+			}
+			return map[string]string{"message": args.Message}, nil
+		},
+	}
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"message":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"missing"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"resources/list"}`,
+	}, "\n")
+
+	var output strings.Builder
+	if err := mcp.NewServer("turnout", "test", echo).Serve(context.Background(), strings.NewReader(input), &output); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	type message struct {
+		ID     int `json:"id"`
+		Result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+			Tools           []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	var messages []message
+	scanner := bufio.NewScanner(strings.NewReader(output.String()))
+	for scanner.Scan() {
+		var m message
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatalf("Invalid response %s: %v", scanner.Text(), err)
+		}
+		messages = append(messages, m)
+	}
+
+	if len(messages) != 6 {
+		t.Fatalf("Expected a response per request and none for the notification, got %d:\n%s", len(messages), output.String())
+	}
+	if messages[0].Result.ProtocolVersion != mcp.ProtocolVersion {
+		t.Errorf("Unexpected initialize result: %+v", messages[0].Result)
+	}
+	if len(messages[1].Result.Tools) != 1 || messages[1].Result.Tools[0].Name != "echo" {
+		t.Errorf("Unexpected tools: %+v", messages[1].Result.Tools)
+	}
+	if messages[2].Result.IsError || !strings.Contains(messages[2].Result.Content[0].Text, `"hi"`) {
+		t.Errorf("Unexpected call result: %+v", messages[2].Result)
+	}
+	if !messages[3].Result.IsError || messages[3].Result.Content[0].Text != "message is required" {
+		t.Errorf("Expected tool errors to be failed calls, got %+v", messages[3])
+	}
+	if messages[4].Error == nil || messages[4].Error.Code != -32602 {
+		t.Errorf("Expected unknown tools to be invalid params, got %+v", messages[4])
+	}
+	if messages[5].Error == nil || messages[5].Error.Code != -32601 {
+		t.Errorf("Expected unknown methods to be not found, got %+v", messages[5])
+	}
+}
@@ -0,0 +1,110 @@
+package railway_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/railway"
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+func TestClient_Apply(t *testing.T) {
+	var mutations []string
+	var variables map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Expected bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		var request struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		name := strings.Fields(strings.SplitN(request.Query, "{", 2)[1])[0]
+		name, _, _ = strings.Cut(name, "(")
+		mutations = append(mutations, name)
+
+		switch name {
+		case "projectCreate":
+			_, _ = w.Write([]byte(`{"data":{"projectCreate":{"id":"p1","name":"shop","environments":{"edges":[{"node":{"id":"e1","name":"production"}}]}}}}`))
+		case "serviceCreate":
+			_, _ = w.Write([]byte(`{"data":{"serviceCreate":{"id":"s1"}}}`))
+		case "serviceDomainCreate":
+			_, _ = w.Write([]byte(`{"data":{"serviceDomainCreate":{"domain":"web.up.railway.app"}}}`))
+		case "variableCollectionUpsert":
+			variables = request.Variables["input"].(map[string]any)["variables"].(map[string]any)
+			_, _ = w.Write([]byte(`{"data":{"variableCollectionUpsert":true}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		}
+	}))
+	defer server.Close()
+
+	project := schema.NewProject("shop")
+	web := schema.NewService("web")
+	web.SourcePath = "web"
+	web.StartCommand = "node server.js"
+	web.Ports = append(web.Ports, schema.NewPort(3000, true))
+	web.Environment["API_SECRET"] = schema.NewEnvVar("abc", true)
+	web.Environment["DATABASE_URL"] = schema.NewEnvVar("", true)
+	web.Volumes = []schema.Volume{{MountPath: "/data"}}
+	project.AddService(web)
+
+	plan := railway.NewPlan(project, "acme/shop", "")
+	if plan.Services[0].Source.Repo != "acme/shop" || len(plan.Services[0].Unset) != 1 {
+		t.Fatalf("Unexpected plan: %+v", plan.Services[0])
+	}
+
+	var output strings.Builder
+	plan.Write(&output)
+	if strings.Contains(output.String(), "abc") {
+		t.Errorf("Expected secret values to be masked in the plan:\n%s", output.String())
+	}
+
+	client := railway.NewClient("token").WithEndpoint(server.URL)
+	applied, err := client.Apply(context.Background(), plan, func(string) {})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	expected := []string{"projectCreate", "serviceCreate", "serviceInstanceUpdate", "volumeCreate", "variableCollectionUpsert", "serviceDomainCreate"}
+	if strings.Join(mutations, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected mutations %v, got %v", expected, mutations)
+	}
+	if len(variables) != 1 || variables["API_SECRET"] != "abc" {
+		t.Errorf("Expected only variables with values to be set, got %v", variables)
+	}
+	if applied.Domains["web"] != "web.up.railway.app" {
+		t.Errorf("Expected generated domain, got %v", applied.Domains)
+	}
+}
+
+func TestPlan_BuildArgs(t *testing.T) {
+	project := schema.NewProject("shop")
+	web := schema.NewService("web")
+	nodeVersion := schema.NewEnvVar("20", false)
+	nodeVersion.BuildOnly = true
+	web.Environment["NODE_VERSION"] = nodeVersion
+	npmToken := schema.NewEnvVar("", true)
+	npmToken.BuildOnly = true
+	web.Environment["NPM_TOKEN"] = npmToken
+	project.AddService(web)
+
+	plan := railway.NewPlan(project, "", "")
+	if build := plan.Services[0].BuildArgs; len(build) != 2 || plan.Services[0].Variables["NODE_VERSION"] != "20" {
+		t.Fatalf("Expected build-only variables to be set as build args, got %+v", plan.Services[0])
+	}
+
+	var output strings.Builder
+	plan.Write(&output)
+	for _, line := range []string{"+ variable NODE_VERSION = 20 (build arg)", "! variable NPM_TOKEN has no value, set it in Railway (secret) (build arg)"} {
+		if !strings.Contains(output.String(), line) {
+			t.Errorf("Expected %q in the plan:\n%s", line, output.String())
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package schema_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/railwayapp/turnout/internal/schema"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	project := schema.NewProject("shop")
+	web := schema.NewService("web")
+	web.SourcePath = "web"
+	web.Environment["API_SECRET"] = schema.NewEnvVar("abc", true)
+	web.Environment["LOG_LEVEL"] = schema.NewEnvVar("info", false)
+	project.AddService(web)
+
+	var buf bytes.Buffer
+	if err := schema.NewSnapshot("github://acme/shop", project).Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "abc") {
+		t.Errorf("Expected sensitive values to be dropped:\n%s", buf.String())
+	}
+	if project.Services[0].Environment["API_SECRET"].Value != "abc" {
+		t.Errorf("Expected snapshotting to leave the project untouched")
+	}
+
+	snapshot, err := schema.ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+	if snapshot.Source != "github://acme/shop" || len(snapshot.Project.Services) != 1 {
+		t.Fatalf("Unexpected snapshot: %+v", snapshot)
+	}
+	environment := snapshot.Project.Services[0].Environment
+	if environment["LOG_LEVEL"].Value != "info" || !environment["API_SECRET"].Sensitive {
+		t.Errorf("Unexpected environment: %+v", environment)
+	}
+}
+
+func TestReadSnapshot_Version(t *testing.T) {
+	for _, input := range []string{
+		`{"project":{"name":"shop"}}`,
+		`{"version":999,"project":{"name":"shop"}}`,
+	} {
+		if _, err := schema.ReadSnapshot(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected %s to be rejected", input)
+		}
+	}
+}